@@ -0,0 +1,84 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+/*
+Package ekadump provides a bounded reflection-based dumper of arbitrary values
+for debugging purposes. Unlike JSON encoders, it never panics on cyclic data
+and its output is limited by depth, number of items and size:
+
+	fmt.Println(ekadump.Dump(user))
+	// &main.User{Name: "Bob", Age: 42, Friends: []*main.User{<cycle *main.User>}}
+
+The output is Go-like: structs are "pkg.Type{Field: value}",
+maps are "map[K]V{key: value}" sorted by keys, pointers are "&value".
+*/
+package ekadump
+
+import (
+	"reflect"
+)
+
+type (
+	// Options are the limits and switches of the dumper.
+	// The zero value of each limit means its DefaultOptions' value.
+	Options struct {
+
+		// MaxDepth is how deep the nested values (of structs, maps, slices,
+		// arrays, pointers, interfaces) are rendered.
+		// The deeper ones are rendered as "<max depth>".
+		MaxDepth int
+
+		// MaxItems is how much elements of each map, slice, array
+		// (or fields of struct) are rendered. The rest are rendered as "...".
+		MaxItems int
+
+		// MaxSize is the maximum size of the output in bytes.
+		// The longer output is truncated and ends with "...<truncated>".
+		MaxSize int
+
+		// Unexported reports whether unexported fields of structs are rendered.
+		Unexported bool
+
+		// NoMethods disables using of String() and Error() methods
+		// (of fmt.Stringer and error interfaces) to render values.
+		NoMethods bool
+	}
+)
+
+var (
+	// DefaultOptions are the Options that are used by Dump()
+	// and fill the zero limits of other Options.
+	DefaultOptions = Options{
+		MaxDepth: 5,
+		MaxItems: 32,
+		MaxSize:  4096,
+	}
+)
+
+// Dump renders 'v' using DefaultOptions.
+func Dump(v any) string {
+	return DefaultOptions.Dump(v)
+}
+
+// Dump renders 'v' using the current Options.
+func (o Options) Dump(v any) string {
+	return string(o.AppendDump(nil, v))
+}
+
+// AppendDump renders 'v' using the current Options, appending the result
+// to 'to'. Returns an extended buffer.
+// MaxSize limits only the appended part.
+func (o Options) AppendDump(to []byte, v any) []byte {
+
+	d := dumper{
+		o:       o.withDefaults(),
+		b:       to,
+		start:   len(to),
+		visited: make(map[visitKey]struct{}),
+	}
+
+	d.dump(reflect.ValueOf(v), 0)
+	return d.finish()
+}
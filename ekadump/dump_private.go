@@ -0,0 +1,336 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekadump
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+)
+
+type (
+	// dumper is a state of one Options.AppendDump() call.
+	dumper struct {
+		o         Options
+		b         []byte
+		start     int // len(b) before the dump
+		truncated bool
+
+		// visited are the pointers (maps, slices) of the values that are
+		// being rendered now (the path from the root), to detect cycles.
+		visited map[visitKey]struct{}
+	}
+
+	// visitKey is a key of dumper's visited.
+	visitKey struct {
+		ptr uintptr
+		typ reflect.Type
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	_DUMP_NIL       = "nil"
+	_DUMP_MAX_DEPTH = "<max depth>"
+	_DUMP_MORE      = "..."
+	_DUMP_TRUNCATED = "...<truncated>"
+)
+
+var (
+	typeError    = reflect.TypeOf((*error)(nil)).Elem()
+	typeStringer = reflect.TypeOf((*fmt.Stringer)(nil)).Elem()
+)
+
+// withDefaults returns Options, the zero (or negative) limits of which
+// are replaced by DefaultOptions' ones.
+func (o Options) withDefaults() Options {
+	if o.MaxDepth <= 0 {
+		o.MaxDepth = DefaultOptions.MaxDepth
+	}
+	if o.MaxItems <= 0 {
+		o.MaxItems = DefaultOptions.MaxItems
+	}
+	if o.MaxSize <= 0 {
+		o.MaxSize = DefaultOptions.MaxSize
+	}
+	return o
+}
+
+// finish cuts the output by Options.MaxSize and returns it.
+func (d *dumper) finish() []byte {
+	if d.truncated {
+		d.b = append(d.b[:d.start+d.o.MaxSize], _DUMP_TRUNCATED...)
+	}
+	return d.b
+}
+
+// write appends 's' to the output, marking it truncated if it's too long.
+func (d *dumper) write(s string) {
+	d.b = append(d.b, s...)
+	d.checkSize()
+}
+
+// checkSize marks the output truncated if it's longer than Options.MaxSize.
+func (d *dumper) checkSize() {
+	if len(d.b)-d.start > d.o.MaxSize {
+		d.truncated = true
+	}
+}
+
+// dump renders 'v' at the nesting level 'depth'.
+func (d *dumper) dump(v reflect.Value, depth int) {
+
+	if d.truncated {
+		return
+	}
+
+	if !v.IsValid() {
+		d.write(_DUMP_NIL)
+		return
+	}
+
+	if d.dumpByMethod(v) {
+		return
+	}
+
+	switch v.Kind() {
+
+	case reflect.Bool:
+		d.b = strconv.AppendBool(d.b, v.Bool())
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		d.b = strconv.AppendInt(d.b, v.Int(), 10)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		d.b = strconv.AppendUint(d.b, v.Uint(), 10)
+
+	case reflect.Float32, reflect.Float64:
+		d.b = strconv.AppendFloat(d.b, v.Float(), 'g', -1, v.Type().Bits())
+
+	case reflect.Complex64, reflect.Complex128:
+		d.write(strconv.FormatComplex(v.Complex(), 'g', -1, v.Type().Bits()))
+
+	case reflect.String:
+		s := v.String()
+		if len(s) > d.o.MaxSize {
+			s = s[:d.o.MaxSize]
+		}
+		d.b = strconv.AppendQuote(d.b, s)
+
+	case reflect.Interface:
+		if v.IsNil() {
+			d.write(_DUMP_NIL)
+		} else {
+			d.dump(v.Elem(), depth)
+		}
+
+	case reflect.Ptr:
+		if v.IsNil() {
+			d.write(_DUMP_NIL)
+		} else if d.enter(v, depth) {
+			// The pointer is not a nesting level itself, its value is.
+			d.write("&")
+			d.dump(v.Elem(), depth)
+			d.leave(v)
+		}
+
+	case reflect.Struct:
+		d.dumpStruct(v, depth)
+
+	case reflect.Map:
+		if v.IsNil() {
+			d.write(_DUMP_NIL)
+		} else if d.enter(v, depth) {
+			d.dumpMap(v, depth)
+			d.leave(v)
+		}
+
+	case reflect.Slice:
+		if v.IsNil() {
+			d.write(_DUMP_NIL)
+		} else if v.Len() == 0 {
+			// Empty slices may share the same pointer, but can't be cyclic.
+			d.write(v.Type().String() + "{}")
+		} else if d.enter(v, depth) {
+			d.dumpList(v, depth)
+			d.leave(v)
+		}
+
+	case reflect.Array:
+		if depth < d.o.MaxDepth {
+			d.dumpList(v, depth)
+		} else {
+			d.write(_DUMP_MAX_DEPTH)
+		}
+
+	default:
+		// Chan, Func, UnsafePointer.
+		if v.IsNil() {
+			d.write(_DUMP_NIL)
+		} else {
+			d.write(v.Type().String() + "(0x" + strconv.FormatUint(uint64(v.Pointer()), 16) + ")")
+		}
+	}
+
+	d.checkSize()
+}
+
+// dumpByMethod renders 'v' using its Error() or String() method if it has any.
+// Returns false if it hasn't, methods are disabled or the method has panicked.
+func (d *dumper) dumpByMethod(v reflect.Value) (ok bool) {
+
+	if d.o.NoMethods || !v.CanInterface() {
+		return false
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface, reflect.Map, reflect.Slice:
+		if v.IsNil() {
+			return false
+		}
+	}
+
+	var s string
+
+	defer func() {
+		if recover() != nil {
+			ok = false
+		}
+	}()
+
+	switch {
+	case v.Type().Implements(typeError):
+		s = v.Interface().(error).Error()
+	case v.Type().Implements(typeStringer):
+		s = v.Interface().(fmt.Stringer).String()
+	default:
+		return false
+	}
+
+	d.write(s)
+	return true
+}
+
+// dumpStruct renders struct 'v'.
+func (d *dumper) dumpStruct(v reflect.Value, depth int) {
+
+	typ := v.Type()
+	d.write(typ.String() + "{")
+
+	if depth >= d.o.MaxDepth {
+		d.write(_DUMP_MAX_DEPTH + "}")
+		return
+	}
+
+	n := 0
+	for i := 0; i < v.NumField() && !d.truncated; i++ {
+
+		field := typ.Field(i)
+		if field.PkgPath != "" && !d.o.Unexported {
+			continue
+		}
+
+		if n > 0 {
+			d.write(", ")
+		}
+		if n == d.o.MaxItems {
+			d.write(_DUMP_MORE)
+			break
+		}
+
+		d.write(field.Name + ": ")
+		d.dump(v.Field(i), depth+1)
+		n++
+	}
+
+	d.write("}")
+}
+
+// dumpList renders slice or array 'v'.
+func (d *dumper) dumpList(v reflect.Value, depth int) {
+
+	d.write(v.Type().String() + "{")
+
+	for i, n := 0, v.Len(); i < n && !d.truncated; i++ {
+		if i > 0 {
+			d.write(", ")
+		}
+		if i == d.o.MaxItems {
+			d.write(_DUMP_MORE)
+			break
+		}
+		d.dump(v.Index(i), depth+1)
+	}
+
+	d.write("}")
+}
+
+// dumpMap renders map 'v' sorted by the rendered keys.
+func (d *dumper) dumpMap(v reflect.Value, depth int) {
+
+	type entry struct {
+		key string
+		val reflect.Value
+	}
+
+	// The keys are rendered by a separate dumper w/o size limit
+	// of the current one, but with the same visited values.
+	keyDumper := dumper{o: d.o, visited: d.visited}
+	entries := make([]entry, 0, v.Len())
+
+	iter := v.MapRange()
+	for iter.Next() {
+		keyDumper.b, keyDumper.truncated = keyDumper.b[:0], false
+		keyDumper.dump(iter.Key(), depth+1)
+		entries = append(entries, entry{string(keyDumper.finish()), iter.Value()})
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].key < entries[j].key
+	})
+
+	d.write(v.Type().String() + "{")
+
+	for i := 0; i < len(entries) && !d.truncated; i++ {
+		if i > 0 {
+			d.write(", ")
+		}
+		if i == d.o.MaxItems {
+			d.write(_DUMP_MORE)
+			break
+		}
+		d.write(entries[i].key + ": ")
+		d.dump(entries[i].val, depth+1)
+	}
+
+	d.write("}")
+}
+
+// enter checks whether pointer (map, slice) 'v' may be rendered
+// at the nesting level 'depth' and marks it visited.
+// Otherwise, renders why it can't be and returns false.
+func (d *dumper) enter(v reflect.Value, depth int) bool {
+
+	if depth >= d.o.MaxDepth {
+		d.write(_DUMP_MAX_DEPTH)
+		return false
+	}
+
+	key := visitKey{v.Pointer(), v.Type()}
+	if _, isCycle := d.visited[key]; isCycle {
+		d.write("<cycle " + v.Type().String() + ">")
+		return false
+	}
+
+	d.visited[key] = struct{}{}
+	return true
+}
+
+// leave unmarks visited pointer (map, slice) 'v'.
+func (d *dumper) leave(v reflect.Value) {
+	delete(d.visited, visitKey{v.Pointer(), v.Type()})
+}
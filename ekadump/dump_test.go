@@ -0,0 +1,92 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekadump_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekadump"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	tUser struct {
+		Name    string
+		Age     int
+		Friends []*tUser
+		secret  string
+	}
+	tNode struct {
+		Next *tNode
+	}
+)
+
+func TestDump(t *testing.T) {
+
+	tests := []struct {
+		v        any
+		expected string
+	}{
+		{nil, "nil"},
+		{42, "42"},
+		{"str", `"str"`},
+		{1.5, "1.5"},
+		{[]int{1, 2}, "[]int{1, 2}"},
+		{[]int{}, "[]int{}"},
+		{[2]bool{true}, "[2]bool{true, false}"},
+		{map[string]int{"b": 2, "a": 1}, `map[string]int{"a": 1, "b": 2}`},
+		{(*tUser)(nil), "nil"},
+		{time.Second, "1s"},
+		{errors.New("err"), "err"},
+		{
+			&tUser{Name: "Bob", Age: 42, secret: "x"},
+			`&ekadump_test.tUser{Name: "Bob", Age: 42, Friends: nil}`,
+		},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, ekadump.Dump(test.v))
+	}
+}
+
+func TestDump_Cycle(t *testing.T) {
+
+	u := &tUser{Name: "Bob"}
+	u.Friends = []*tUser{u}
+
+	assert.Equal(t,
+		`&ekadump_test.tUser{Name: "Bob", Age: 0, Friends: []*ekadump_test.tUser{<cycle *ekadump_test.tUser>}}`,
+		ekadump.Dump(u))
+
+	m := map[string]any{}
+	m["self"] = m
+	assert.Equal(t, `map[string]interface {}{"self": <cycle map[string]interface {}>}`, ekadump.Dump(m))
+}
+
+func TestOptions(t *testing.T) {
+
+	list := &tNode{&tNode{&tNode{&tNode{}}}}
+	assert.Equal(t,
+		"&ekadump_test.tNode{Next: &ekadump_test.tNode{Next: <max depth>}}",
+		ekadump.Options{MaxDepth: 2}.Dump(list))
+
+	assert.Equal(t, "[]int{1, 2, ...}", ekadump.Options{MaxItems: 2}.Dump([]int{1, 2, 3}))
+
+	out := ekadump.Options{MaxSize: 10}.Dump(strings.Repeat("a", 100))
+	assert.Equal(t, `"aaaaaaaaa...<truncated>`, out)
+
+	assert.Equal(t,
+		`ekadump_test.tUser{Name: "", Age: 0, Friends: nil, secret: "x"}`,
+		ekadump.Options{Unexported: true}.Dump(tUser{secret: "x"}))
+
+	assert.Equal(t, "1000000000", ekadump.Options{NoMethods: true}.Dump(time.Second))
+
+	assert.Equal(t, "prefix 42", string(ekadump.DefaultOptions.AppendDump([]byte("prefix "), 42)))
+}
@@ -0,0 +1,115 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaenc
+
+import (
+	"errors"
+
+	"github.com/qioalice/ekago/v3/ekastr"
+)
+
+// Base32 (Crockford's alphabet) encoding.
+//
+// Unlike encoding/base32 from std Golang lib it:
+//   - Never uses padding;
+//   - Has allocation-free AppendEncode/AppendDecode forms;
+//   - Decodes case insensitive, treats 'I', 'L' as '1' and 'O' as '0',
+//     skips hyphens ('-'), as Crockford's spec requires.
+//
+// Read more:
+// https://www.crockford.com/base32.html
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	BASE32_ALPHABET = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+)
+
+var (
+	ErrBase32InvalidChar = errors.New("ekaenc: invalid Base32 (Crockford) character")
+)
+
+// Base32EncodedLen returns the length in bytes of the Base32 encoding
+// of an input buffer of length 'n'.
+func Base32EncodedLen(n int) int {
+	return (n*8 + 4) / 5
+}
+
+// Base32DecodedLen returns the maximum length in bytes of the decoded data
+// corresponding to 'n' bytes of Base32-encoded data.
+func Base32DecodedLen(n int) int {
+	return n * 5 / 8
+}
+
+// AppendEncodeBase32 appends Base32 encoded 'src' to 'dst' and returns
+// the extended buffer. Allocates only if 'dst' has not enough capacity.
+func AppendEncodeBase32(dst, src []byte) []byte {
+
+	dst = growBytes(dst, Base32EncodedLen(len(src)))
+
+	var (
+		buf  uint32
+		bits uint8
+	)
+
+	for _, c := range src {
+		buf = buf<<8 | uint32(c)
+		bits += 8
+		for bits >= 5 {
+			bits -= 5
+			dst = append(dst, BASE32_ALPHABET[(buf>>bits)&0x1F])
+		}
+	}
+
+	if bits > 0 {
+		dst = append(dst, BASE32_ALPHABET[(buf<<(5-bits))&0x1F])
+	}
+
+	return dst
+}
+
+// EncodeBase32 returns Base32 encoded 'src'.
+func EncodeBase32(src []byte) string {
+	return string(AppendEncodeBase32(nil, src))
+}
+
+// AppendDecodeBase32 appends Base32 decoded 'src' to 'dst' and returns
+// the extended buffer. Allocates only if 'dst' has not enough capacity.
+//
+// If 'src' contains invalid character, ErrBase32InvalidChar is returned
+// along with the original 'dst'.
+func AppendDecodeBase32(dst, src []byte) ([]byte, error) {
+
+	var (
+		start = len(dst)
+		buf   uint32
+		bits  uint8
+	)
+
+	dst = growBytes(dst, Base32DecodedLen(len(src)))
+
+	for _, c := range src {
+		if c == '-' {
+			continue
+		}
+		v := base32DecodeMap[c]
+		if v == 0xFF {
+			return dst[:start], ErrBase32InvalidChar
+		}
+		buf = buf<<5 | uint32(v)
+		bits += 5
+		if bits >= 8 {
+			bits -= 8
+			dst = append(dst, byte(buf>>bits))
+		}
+	}
+
+	return dst, nil
+}
+
+// DecodeBase32 returns Base32 decoded 'src'.
+func DecodeBase32(src string) ([]byte, error) {
+	return AppendDecodeBase32(nil, ekastr.S2B(src))
+}
@@ -0,0 +1,82 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaenc
+
+import (
+	"errors"
+
+	"github.com/qioalice/ekago/v3/ekastr"
+)
+
+// Base58 (Bitcoin's alphabet) and Base62 encodings.
+//
+// Both of them treats the input as a big-endian big number and represent it
+// in the 58 or 62 radix correspondingly. Leading zero bytes are preserved
+// by encoding them as the first char of alphabet. So, there is no padding
+// and the length of encoded data depends on the data itself.
+//
+// Used by IDs like KSUID (Base62) and tokens, addresses (Base58).
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	BASE58_ALPHABET = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+	BASE62_ALPHABET = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+var (
+	ErrBase58InvalidChar = errors.New("ekaenc: invalid Base58 character")
+	ErrBase62InvalidChar = errors.New("ekaenc: invalid Base62 character")
+)
+
+// AppendEncodeBase58 appends Base58 encoded 'src' to 'dst' and returns
+// the extended buffer. Allocates only if 'dst' has not enough capacity.
+func AppendEncodeBase58(dst, src []byte) []byte {
+	return appendEncodeBaseX(dst, src, &base58)
+}
+
+// EncodeBase58 returns Base58 encoded 'src'.
+func EncodeBase58(src []byte) string {
+	return string(AppendEncodeBase58(nil, src))
+}
+
+// AppendDecodeBase58 appends Base58 decoded 'src' to 'dst' and returns
+// the extended buffer. Allocates only if 'dst' has not enough capacity.
+//
+// If 'src' contains invalid character, ErrBase58InvalidChar is returned
+// along with the original 'dst'.
+func AppendDecodeBase58(dst, src []byte) ([]byte, error) {
+	return appendDecodeBaseX(dst, src, &base58)
+}
+
+// DecodeBase58 returns Base58 decoded 'src'.
+func DecodeBase58(src string) ([]byte, error) {
+	return AppendDecodeBase58(nil, ekastr.S2B(src))
+}
+
+// AppendEncodeBase62 appends Base62 encoded 'src' to 'dst' and returns
+// the extended buffer. Allocates only if 'dst' has not enough capacity.
+func AppendEncodeBase62(dst, src []byte) []byte {
+	return appendEncodeBaseX(dst, src, &base62)
+}
+
+// EncodeBase62 returns Base62 encoded 'src'.
+func EncodeBase62(src []byte) string {
+	return string(AppendEncodeBase62(nil, src))
+}
+
+// AppendDecodeBase62 appends Base62 decoded 'src' to 'dst' and returns
+// the extended buffer. Allocates only if 'dst' has not enough capacity.
+//
+// If 'src' contains invalid character, ErrBase62InvalidChar is returned
+// along with the original 'dst'.
+func AppendDecodeBase62(dst, src []byte) ([]byte, error) {
+	return appendDecodeBaseX(dst, src, &base62)
+}
+
+// DecodeBase62 returns Base62 decoded 'src'.
+func DecodeBase62(src string) ([]byte, error) {
+	return AppendDecodeBase62(nil, ekastr.S2B(src))
+}
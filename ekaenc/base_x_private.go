@@ -0,0 +1,174 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaenc
+
+type (
+	// _BaseX is a radix encoding's descriptor.
+	_BaseX struct {
+		alphabet  string
+		decodeMap [256]byte
+		radix     int
+
+		// encRatio, decRatio are a multipliers (in 1/1000) that are used to
+		// calculate the max length of encoded/decoded data.
+		// They are log(256)/log(radix) and log(radix)/log(256) rounded up.
+		encRatio int
+		decRatio int
+
+		errInvalidChar error
+	}
+)
+
+var (
+	base32DecodeMap [256]byte
+
+	base58 = _BaseX{
+		alphabet: BASE58_ALPHABET, radix: 58,
+		encRatio: 1366, decRatio: 733,
+		errInvalidChar: ErrBase58InvalidChar,
+	}
+	base62 = _BaseX{
+		alphabet: BASE62_ALPHABET, radix: 62,
+		encRatio: 1344, decRatio: 745,
+		errInvalidChar: ErrBase62InvalidChar,
+	}
+)
+
+// growBytes guarantees that 'b' has enough capacity to append 'n' bytes
+// w/o reallocation.
+func growBytes(b []byte, n int) []byte {
+	if cap(b)-len(b) < n {
+		b2 := make([]byte, len(b), len(b)+n)
+		copy(b2, b)
+		b = b2
+	}
+	return b
+}
+
+// appendEncodeBaseX is AppendEncodeBase58(), AppendEncodeBase62() implementation.
+// Uses the tail of 'dst' as a scratch buffer, so no additional allocations.
+func appendEncodeBaseX(dst, src []byte, enc *_BaseX) []byte {
+
+	zeros := 0
+	for zeros < len(src) && src[zeros] == 0 {
+		zeros++
+	}
+
+	var (
+		start = len(dst)
+		size  = (len(src)-zeros)*enc.encRatio/1000 + 1
+	)
+
+	dst = growBytes(dst, zeros+size)
+	dst = dst[:start+zeros+size]
+
+	b := dst[start+zeros:]
+	for i := range b {
+		b[i] = 0
+	}
+
+	high := size - 1
+	for _, v := range src[zeros:] {
+		j := size - 1
+		for carry := int(v); j > high || carry != 0; j-- {
+			carry += 256 * int(b[j])
+			b[j] = byte(carry % enc.radix)
+			carry /= enc.radix
+		}
+		high = j
+	}
+
+	k := 0
+	for k < size && b[k] == 0 {
+		k++
+	}
+
+	for i := start; i < start+zeros; i++ {
+		dst[i] = enc.alphabet[0]
+	}
+	n := copy(b, b[k:])
+	for i := 0; i < n; i++ {
+		b[i] = enc.alphabet[b[i]]
+	}
+
+	return dst[:start+zeros+n]
+}
+
+// appendDecodeBaseX is AppendDecodeBase58(), AppendDecodeBase62() implementation.
+// Uses the tail of 'dst' as a scratch buffer, so no additional allocations.
+func appendDecodeBaseX(dst, src []byte, enc *_BaseX) ([]byte, error) {
+
+	zeros := 0
+	for zeros < len(src) && src[zeros] == enc.alphabet[0] {
+		zeros++
+	}
+
+	var (
+		start = len(dst)
+		size  = (len(src)-zeros)*enc.decRatio/1000 + 1
+	)
+
+	dst = growBytes(dst, zeros+size)
+	dst = dst[:start+zeros+size]
+
+	b := dst[start+zeros:]
+	for i := range b {
+		b[i] = 0
+	}
+
+	high := size - 1
+	for _, c := range src[zeros:] {
+		v := enc.decodeMap[c]
+		if v == 0xFF {
+			return dst[:start], enc.errInvalidChar
+		}
+		j := size - 1
+		for carry := int(v); j > high || carry != 0; j-- {
+			carry += enc.radix * int(b[j])
+			b[j] = byte(carry)
+			carry >>= 8
+		}
+		high = j
+	}
+
+	k := 0
+	for k < size && b[k] == 0 {
+		k++
+	}
+
+	for i := start; i < start+zeros; i++ {
+		dst[i] = 0
+	}
+	n := copy(b, b[k:])
+
+	return dst[:start+zeros+n], nil
+}
+
+// initDecodeMap fills 'm' by the reverse index of 'alphabet'.
+func initDecodeMap(m *[256]byte, alphabet string) {
+	for i := range m {
+		m[i] = 0xFF
+	}
+	for i := 0; i < len(alphabet); i++ {
+		m[alphabet[i]] = byte(i)
+	}
+}
+
+func init() {
+	initDecodeMap(&base58.decodeMap, base58.alphabet)
+	initDecodeMap(&base62.decodeMap, base62.alphabet)
+
+	initDecodeMap(&base32DecodeMap, BASE32_ALPHABET)
+	for i := 0; i < len(BASE32_ALPHABET); i++ {
+		c := BASE32_ALPHABET[i]
+		if c >= 'A' && c <= 'Z' {
+			base32DecodeMap[c+'a'-'A'] = byte(i)
+		}
+	}
+	base32DecodeMap['I'], base32DecodeMap['i'] = 1, 1
+	base32DecodeMap['L'], base32DecodeMap['l'] = 1, 1
+	base32DecodeMap['O'], base32DecodeMap['o'] = 0, 0
+}
@@ -0,0 +1,59 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaenc_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaenc"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBase32(t *testing.T) {
+	require.Equal(t, "CSQPYRK1E8", ekaenc.EncodeBase32([]byte("foobar")))
+
+	b, err := ekaenc.DecodeBase32("csqp-yrkle8")
+	require.NoError(t, err)
+	require.Equal(t, "foobar", string(b))
+
+	_, err = ekaenc.DecodeBase32("CSQPU")
+	require.Equal(t, ekaenc.ErrBase32InvalidChar, err)
+}
+
+func TestBase58(t *testing.T) {
+	require.Equal(t, "2NEpo7TZRRrLZSi2U", ekaenc.EncodeBase58([]byte("Hello World!")))
+	require.Equal(t, "11", ekaenc.EncodeBase58([]byte{0, 0}))
+
+	b, err := ekaenc.DecodeBase58("2NEpo7TZRRrLZSi2U")
+	require.NoError(t, err)
+	require.Equal(t, "Hello World!", string(b))
+
+	_, err = ekaenc.DecodeBase58("0OIl")
+	require.Equal(t, ekaenc.ErrBase58InvalidChar, err)
+}
+
+func TestBase62(t *testing.T) {
+	src := []byte("\x00\x00hello")
+	require.Equal(t, "007tQLFHz", ekaenc.EncodeBase62(src))
+
+	b, err := ekaenc.DecodeBase62("007tQLFHz")
+	require.NoError(t, err)
+	require.Equal(t, src, b)
+
+	dst := make([]byte, 0, 64)
+	dst = append(dst, "id:"...)
+	require.Equal(t, "id:007tQLFHz", string(ekaenc.AppendEncodeBase62(dst, src)))
+}
+
+func BenchmarkAppendEncodeBase62(b *testing.B) {
+	src := []byte("0123456789abcdef0123")
+	dst := make([]byte, 0, 64)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = ekaenc.AppendEncodeBase62(dst[:0], src)
+	}
+}
@@ -0,0 +1,188 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaenc
+
+import (
+	"errors"
+)
+
+// Binary primitives for building compact binary formats:
+//   - Varints (unsigned LEB128, compatible with encoding/binary's Uvarint);
+//   - Zigzag encoded signed varints (compatible with encoding/binary's Varint);
+//   - Fixed-width big-endian and little-endian unsigned integers.
+//
+// Append functions append encoded value to 'dst' and return the extended buffer.
+// They allocate only if 'dst' has not enough capacity.
+// Read functions decode the value at the start of 'src' and return it
+// along with the number of consumed bytes.
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// MAX_VARINT_LEN_64 is the max length of 64-bit varint.
+	MAX_VARINT_LEN_64 = 10
+)
+
+var (
+	ErrVarintTruncated = errors.New("ekaenc: varint is truncated")
+	ErrVarintOverflow  = errors.New("ekaenc: varint overflows 64-bit integer")
+	ErrShortBuffer     = errors.New("ekaenc: buffer is too short to read fixed-width integer")
+)
+
+// ZigZagEncode maps signed 'v' to unsigned one, so small by the absolute value
+// numbers have small encoded values: 0 -> 0, -1 -> 1, 1 -> 2, -2 -> 3, etc.
+func ZigZagEncode(v int64) uint64 {
+	return uint64(v<<1) ^ uint64(v>>63)
+}
+
+// ZigZagDecode is the reverse of ZigZagEncode().
+func ZigZagDecode(v uint64) int64 {
+	return int64(v>>1) ^ -int64(v&1)
+}
+
+// UvarintLen returns the number of bytes 'v' is encoded to by AppendUvarint().
+func UvarintLen(v uint64) int {
+	n := 1
+	for ; v >= 0x80; v >>= 7 {
+		n++
+	}
+	return n
+}
+
+// AppendUvarint appends varint encoded 'v' to 'dst'.
+func AppendUvarint(dst []byte, v uint64) []byte {
+	for v >= 0x80 {
+		dst = append(dst, byte(v)|0x80)
+		v >>= 7
+	}
+	return append(dst, byte(v))
+}
+
+// AppendVarint appends zigzag varint encoded 'v' to 'dst'.
+func AppendVarint(dst []byte, v int64) []byte {
+	return AppendUvarint(dst, ZigZagEncode(v))
+}
+
+// ReadUvarint decodes varint at the start of 'src'.
+// Returns ErrVarintTruncated if 'src' ends before the varint does,
+// or ErrVarintOverflow if varint doesn't fit to uint64.
+func ReadUvarint(src []byte) (v uint64, n int, err error) {
+
+	var shift uint
+	for i, b := range src {
+		if i == MAX_VARINT_LEN_64 {
+			return 0, 0, ErrVarintOverflow
+		}
+		if b < 0x80 {
+			if i == MAX_VARINT_LEN_64-1 && b > 1 {
+				return 0, 0, ErrVarintOverflow
+			}
+			return v | uint64(b)<<shift, i + 1, nil
+		}
+		v |= uint64(b&0x7F) << shift
+		shift += 7
+	}
+
+	return 0, 0, ErrVarintTruncated
+}
+
+// ReadVarint decodes zigzag varint at the start of 'src'.
+// Read more: ReadUvarint().
+func ReadVarint(src []byte) (int64, int, error) {
+	v, n, err := ReadUvarint(src)
+	return ZigZagDecode(v), n, err
+}
+
+// AppendUint16BE appends big-endian encoded 'v' to 'dst'.
+func AppendUint16BE(dst []byte, v uint16) []byte {
+	return append(dst, byte(v>>8), byte(v))
+}
+
+// AppendUint16LE appends little-endian encoded 'v' to 'dst'.
+func AppendUint16LE(dst []byte, v uint16) []byte {
+	return append(dst, byte(v), byte(v>>8))
+}
+
+// AppendUint32BE appends big-endian encoded 'v' to 'dst'.
+func AppendUint32BE(dst []byte, v uint32) []byte {
+	return append(dst, byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// AppendUint32LE appends little-endian encoded 'v' to 'dst'.
+func AppendUint32LE(dst []byte, v uint32) []byte {
+	return append(dst, byte(v), byte(v>>8), byte(v>>16), byte(v>>24))
+}
+
+// AppendUint64BE appends big-endian encoded 'v' to 'dst'.
+func AppendUint64BE(dst []byte, v uint64) []byte {
+	return append(dst,
+		byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32),
+		byte(v>>24), byte(v>>16), byte(v>>8), byte(v))
+}
+
+// AppendUint64LE appends little-endian encoded 'v' to 'dst'.
+func AppendUint64LE(dst []byte, v uint64) []byte {
+	return append(dst,
+		byte(v), byte(v>>8), byte(v>>16), byte(v>>24),
+		byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
+}
+
+// ReadUint16BE decodes big-endian uint16 at the start of 'src'.
+// Returns ErrShortBuffer if 'src' is shorter than 2 bytes.
+func ReadUint16BE(src []byte) (uint16, int, error) {
+	if len(src) < 2 {
+		return 0, 0, ErrShortBuffer
+	}
+	return uint16(src[0])<<8 | uint16(src[1]), 2, nil
+}
+
+// ReadUint16LE decodes little-endian uint16 at the start of 'src'.
+// Returns ErrShortBuffer if 'src' is shorter than 2 bytes.
+func ReadUint16LE(src []byte) (uint16, int, error) {
+	if len(src) < 2 {
+		return 0, 0, ErrShortBuffer
+	}
+	return uint16(src[0]) | uint16(src[1])<<8, 2, nil
+}
+
+// ReadUint32BE decodes big-endian uint32 at the start of 'src'.
+// Returns ErrShortBuffer if 'src' is shorter than 4 bytes.
+func ReadUint32BE(src []byte) (uint32, int, error) {
+	if len(src) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return uint32(src[0])<<24 | uint32(src[1])<<16 | uint32(src[2])<<8 | uint32(src[3]), 4, nil
+}
+
+// ReadUint32LE decodes little-endian uint32 at the start of 'src'.
+// Returns ErrShortBuffer if 'src' is shorter than 4 bytes.
+func ReadUint32LE(src []byte) (uint32, int, error) {
+	if len(src) < 4 {
+		return 0, 0, ErrShortBuffer
+	}
+	return uint32(src[0]) | uint32(src[1])<<8 | uint32(src[2])<<16 | uint32(src[3])<<24, 4, nil
+}
+
+// ReadUint64BE decodes big-endian uint64 at the start of 'src'.
+// Returns ErrShortBuffer if 'src' is shorter than 8 bytes.
+func ReadUint64BE(src []byte) (uint64, int, error) {
+	if len(src) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	hi, _, _ := ReadUint32BE(src)
+	lo, _, _ := ReadUint32BE(src[4:])
+	return uint64(hi)<<32 | uint64(lo), 8, nil
+}
+
+// ReadUint64LE decodes little-endian uint64 at the start of 'src'.
+// Returns ErrShortBuffer if 'src' is shorter than 8 bytes.
+func ReadUint64LE(src []byte) (uint64, int, error) {
+	if len(src) < 8 {
+		return 0, 0, ErrShortBuffer
+	}
+	lo, _, _ := ReadUint32LE(src)
+	hi, _, _ := ReadUint32LE(src[4:])
+	return uint64(hi)<<32 | uint64(lo), 8, nil
+}
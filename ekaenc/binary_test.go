@@ -0,0 +1,170 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaenc_test
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaenc"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVarint(t *testing.T) {
+
+	for _, v := range []uint64{0, 1, 127, 128, 300, 1 << 35, math.MaxUint64} {
+		b := ekaenc.AppendUvarint(nil, v)
+		require.Len(t, b, ekaenc.UvarintLen(v))
+
+		decoded, n, err := ekaenc.ReadUvarint(append(b, 0xFF))
+		require.NoError(t, err)
+		require.Equal(t, v, decoded)
+		require.Equal(t, len(b), n)
+	}
+
+	_, _, err := ekaenc.ReadUvarint(nil)
+	require.Equal(t, ekaenc.ErrVarintTruncated, err)
+
+	_, _, err = ekaenc.ReadUvarint([]byte{0x80, 0x80})
+	require.Equal(t, ekaenc.ErrVarintTruncated, err)
+
+	overflow := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x02}
+	_, _, err = ekaenc.ReadUvarint(overflow)
+	require.Equal(t, ekaenc.ErrVarintOverflow, err)
+
+	_, _, err = ekaenc.ReadUvarint(append(overflow[:9:9], 0x80, 0x00))
+	require.Equal(t, ekaenc.ErrVarintOverflow, err)
+
+	require.Equal(t, []uint64{0, 1, 2, 3, 4}, []uint64{
+		ekaenc.ZigZagEncode(0), ekaenc.ZigZagEncode(-1), ekaenc.ZigZagEncode(1),
+		ekaenc.ZigZagEncode(-2), ekaenc.ZigZagEncode(2),
+	})
+}
+
+func TestFixedWidth(t *testing.T) {
+
+	b := ekaenc.AppendUint16BE(nil, 0x0102)
+	b = ekaenc.AppendUint32LE(b, 0x03040506)
+	b = ekaenc.AppendUint64BE(b, 0x0708090A0B0C0D0E)
+	require.Equal(t, []byte{1, 2, 6, 5, 4, 3, 7, 8, 9, 10, 11, 12, 13, 14}, b)
+
+	v16, n, err := ekaenc.ReadUint16BE(b)
+	require.NoError(t, err)
+	require.EqualValues(t, 0x0102, v16)
+	require.Equal(t, 2, n)
+
+	v32, _, err := ekaenc.ReadUint32LE(b[2:])
+	require.NoError(t, err)
+	require.EqualValues(t, 0x03040506, v32)
+
+	v64, _, err := ekaenc.ReadUint64BE(b[6:])
+	require.NoError(t, err)
+	require.EqualValues(t, uint64(0x0708090A0B0C0D0E), v64)
+
+	_, _, err = ekaenc.ReadUint64LE(b[7:])
+	require.Equal(t, ekaenc.ErrShortBuffer, err)
+}
+
+func FuzzUvarint(f *testing.F) {
+
+	for _, seed := range [][]byte{
+		nil, {0}, {0x7F}, {0x80, 0x01}, {0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01},
+		{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x02}, {0x80, 0x80, 0x80},
+	} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+
+		v, n, err := ekaenc.ReadUvarint(data)
+		expected, expectedN := binary.Uvarint(data)
+
+		switch {
+		case expectedN > 0:
+			require.NoError(t, err)
+			require.Equal(t, expected, v)
+			require.Equal(t, expectedN, n)
+
+			// Non-canonical varints (like 0xB3 0x00) are decoded too,
+			// so only the round trip of the decoded value is checked.
+			reEncoded := ekaenc.AppendUvarint(nil, v)
+			reDecoded, _, _ := ekaenc.ReadUvarint(reEncoded)
+			require.Equal(t, v, reDecoded)
+			require.LessOrEqual(t, len(reEncoded), n)
+		case expectedN == 0:
+			require.Equal(t, ekaenc.ErrVarintTruncated, err)
+		default:
+			require.Equal(t, ekaenc.ErrVarintOverflow, err)
+		}
+
+		sv, _, _ := ekaenc.ReadVarint(data)
+		expectedSV, _ := binary.Varint(data)
+		require.Equal(t, expectedSV, sv)
+	})
+}
+
+func FuzzVarintRoundTrip(f *testing.F) {
+
+	for _, seed := range []int64{0, 1, -1, math.MaxInt64, math.MinInt64} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, v int64) {
+
+		var buf [binary.MaxVarintLen64]byte
+		b := ekaenc.AppendVarint(nil, v)
+		require.Equal(t, buf[:binary.PutVarint(buf[:], v)], b)
+
+		decoded, n, err := ekaenc.ReadVarint(b)
+		require.NoError(t, err)
+		require.Equal(t, v, decoded)
+		require.Equal(t, len(b), n)
+	})
+}
+
+func FuzzFixedWidth(f *testing.F) {
+
+	f.Add(uint64(0))
+	f.Add(uint64(math.MaxUint64))
+	f.Add(uint64(0x0102030405060708))
+
+	f.Fuzz(func(t *testing.T, v uint64) {
+
+		var buf [8]byte
+
+		binary.BigEndian.PutUint64(buf[:], v)
+		require.Equal(t, buf[:], ekaenc.AppendUint64BE(nil, v))
+		decoded, _, _ := ekaenc.ReadUint64BE(buf[:])
+		require.Equal(t, v, decoded)
+
+		binary.LittleEndian.PutUint64(buf[:], v)
+		require.Equal(t, buf[:], ekaenc.AppendUint64LE(nil, v))
+		decoded, _, _ = ekaenc.ReadUint64LE(buf[:])
+		require.Equal(t, v, decoded)
+
+		binary.BigEndian.PutUint32(buf[:], uint32(v))
+		require.Equal(t, buf[:4], ekaenc.AppendUint32BE(nil, uint32(v)))
+		v32, _, _ := ekaenc.ReadUint32BE(buf[:])
+		require.Equal(t, uint32(v), v32)
+
+		binary.LittleEndian.PutUint32(buf[:], uint32(v))
+		require.Equal(t, buf[:4], ekaenc.AppendUint32LE(nil, uint32(v)))
+		v32, _, _ = ekaenc.ReadUint32LE(buf[:])
+		require.Equal(t, uint32(v), v32)
+
+		binary.BigEndian.PutUint16(buf[:], uint16(v))
+		require.Equal(t, buf[:2], ekaenc.AppendUint16BE(nil, uint16(v)))
+		v16, _, _ := ekaenc.ReadUint16BE(buf[:])
+		require.Equal(t, uint16(v), v16)
+
+		binary.LittleEndian.PutUint16(buf[:], uint16(v))
+		require.Equal(t, buf[:2], ekaenc.AppendUint16LE(nil, uint16(v)))
+		v16, _, _ = ekaenc.ReadUint16LE(buf[:])
+		require.Equal(t, uint16(v), v16)
+	})
+}
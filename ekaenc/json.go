@@ -0,0 +1,52 @@
+// Copyright © 2020. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaenc
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	NULL_JSON = `null`
+)
+
+//goland:noinspection GoSnakeCaseUsage
+var (
+	NULL_JSON_BYTES_SLICE = []byte(NULL_JSON)
+)
+
+// IsNullJSON returns true if b == nil or b[:4] == "null" (case insensitive).
+func IsNullJSON(b []byte) bool {
+
+	if b == nil {
+		return true
+	}
+
+	z := len(b) == 4
+	z = z && b[0] == 'N' || b[0] == 'n'
+	z = z && (b[1] == 'U' || b[1] == 'u')
+	z = z && (b[2] == 'L' || b[2] == 'l')
+	z = z && (b[3] == 'L' || b[3] == 'l')
+
+	return z
+}
+
+// AppendJSONEscapedString appends 's' to 'dst' escaping it following
+// JSON string escaping rules (RFC 8259) and returns the extended buffer.
+// Surrounding quotes are not added.
+//
+// Escapes double quote, backslash, control chars (\n, \r, \t, \b, \f
+// or \u00XX form for others), U+2028, U+2029 (like encoding/json does)
+// and replaces invalid UTF-8 sequences by U+FFFD.
+//
+// Does not escape '<', '>', '&'. Use AppendJSONEscapedStringHTMLSafe() for that.
+func AppendJSONEscapedString(dst, s []byte) []byte {
+	return appendJSONEscapedString(dst, s, false)
+}
+
+// AppendJSONEscapedStringHTMLSafe is the same as AppendJSONEscapedString()
+// but also escapes '<', '>', '&' chars as \u003c, \u003e, \u0026 correspondingly,
+// making output safe to be embedded into HTML.
+func AppendJSONEscapedStringHTMLSafe(dst, s []byte) []byte {
+	return appendJSONEscapedString(dst, s, true)
+}
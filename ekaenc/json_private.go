@@ -0,0 +1,91 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaenc
+
+import (
+	"unicode/utf8"
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	_JSON_HEX = "0123456789abcdef"
+)
+
+var (
+	// jsonSafeSet, jsonHTMLSafeSet are tables, that for each ASCII char
+	// contains true if it may be written to JSON string as is.
+	jsonSafeSet     [utf8.RuneSelf]bool
+	jsonHTMLSafeSet [utf8.RuneSelf]bool
+)
+
+// appendJSONEscapedString is AppendJSONEscapedString(),
+// AppendJSONEscapedStringHTMLSafe() implementation.
+//
+// Safe chars are not copied one by one, but by the chunks between
+// the chars that must be escaped.
+func appendJSONEscapedString(dst, s []byte, htmlSafe bool) []byte {
+
+	safeSet := &jsonSafeSet
+	if htmlSafe {
+		safeSet = &jsonHTMLSafeSet
+	}
+
+	start := 0
+	for i := 0; i < len(s); {
+
+		if c := s[i]; c < utf8.RuneSelf {
+			if safeSet[c] {
+				i++
+				continue
+			}
+
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			case '\b':
+				dst = append(dst, '\\', 'b')
+			case '\f':
+				dst = append(dst, '\\', 'f')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', _JSON_HEX[c>>4], _JSON_HEX[c&0xF])
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRune(s[i:])
+		switch {
+		case r == utf8.RuneError && size == 1:
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+		case r == '\u2028' || r == '\u2029':
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', _JSON_HEX[r&0xF])
+		default:
+			i += size
+			continue
+		}
+		i += size
+		start = i
+	}
+
+	return append(dst, s[start:]...)
+}
+
+func init() {
+	for c := 0x20; c < utf8.RuneSelf; c++ {
+		jsonSafeSet[c] = c != '"' && c != '\\'
+		jsonHTMLSafeSet[c] = jsonSafeSet[c] && c != '<' && c != '>' && c != '&'
+	}
+}
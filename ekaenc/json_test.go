@@ -0,0 +1,44 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaenc_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaenc"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestAppendJSONEscapedString(t *testing.T) {
+	strs := []string{
+		"", "plain", `"quoted" \ back`, "new\nline\ttab\x01\x1f",
+		"<a href='x'>&</a>", "unicode: привет, 世界", "sep:  ", "bad:\xff\xfe",
+	}
+
+	for _, s := range strs {
+		expected, err := json.Marshal(s)
+		require.NoError(t, err)
+
+		got := ekaenc.AppendJSONEscapedStringHTMLSafe([]byte(`"`), []byte(s))
+		require.Equal(t, string(expected), string(got)+`"`)
+
+		var decoded string
+		got = ekaenc.AppendJSONEscapedString([]byte(`"`), []byte(s))
+		require.NoError(t, json.Unmarshal(append(got, '"'), &decoded))
+		require.Equal(t, string([]rune(s)), decoded)
+	}
+}
+
+func BenchmarkAppendJSONEscapedString(b *testing.B) {
+	s := []byte(`Some log message with "quotes" and a few fields, key=value`)
+	dst := make([]byte, 0, 128)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = ekaenc.AppendJSONEscapedString(dst[:0], s)
+	}
+}
@@ -0,0 +1,290 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync/atomic"
+)
+
+type (
+	// CatalogFormat is a format of the registered Classes catalog,
+	// that is rendered by ExportCatalog().
+	CatalogFormat uint8
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// CATALOG_FORMAT_MARKDOWN is a markdown table of Classes
+	// with their IDs, parents and namespaces.
+	CATALOG_FORMAT_MARKDOWN CatalogFormat = iota
+
+	// CATALOG_FORMAT_OPENAPI is an OpenAPI (YAML) "components" object
+	// with a string enum schema, the values of which are Classes' full names.
+	// The names of enum's values for the code generators
+	// are provided by "x-enum-varnames" extension.
+	CATALOG_FORMAT_OPENAPI
+
+	// CATALOG_FORMAT_PROTOBUF is a protobuf (proto3) file with an enum,
+	// the values of which are Classes' IDs. The zero value is "<NAME>_UNSPECIFIED".
+	CATALOG_FORMAT_PROTOBUF
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// CATALOG_DEFAULT_NAME is a default name of the rendered catalog:
+	// the title of markdown table, the name of OpenAPI schema or protobuf enum.
+	CATALOG_DEFAULT_NAME = "ErrorClass"
+)
+
+// Classes returns all registered (created) Classes, including builtin ones,
+// ordered by their IDs (thus in the order they have been created).
+func Classes() []Class {
+
+	n := atomic.LoadInt32(&classIDPrivateCounter)
+	classes := make([]Class, 0, n)
+
+	for id := ClassID(1); id <= n; id++ {
+		if c := classByID(id, true); c.IsValid() {
+			classes = append(classes, c)
+		}
+	}
+
+	return classes
+}
+
+// ExportCatalog renders all registered Classes (read more: Classes())
+// in the requested 'format' to 'w', so HTTP or gRPC error codes
+// are kept in sync with Go's Classes. Empty 'name' means CATALOG_DEFAULT_NAME.
+//
+// The Classes' full names are always rendered as "<Namespace>::<Class>.<SubClass>",
+// even if there are no custom namespaces (unlike Class.FullName()).
+//
+// Keep in mind, Class's ID depends on the order Classes are created in,
+// so the protobuf enum values are stable until that order is changed.
+// It's better to create all Classes at the package level of one package
+// and add new ones to the end.
+//
+// Returns an error if 'format' is unknown or the write to 'w' is failed.
+func ExportCatalog(w io.Writer, format CatalogFormat, name string) error {
+
+	if name == "" {
+		name = CATALOG_DEFAULT_NAME
+	}
+
+	var (
+		b       bytes.Buffer
+		entries = catalogEntries()
+	)
+
+	switch format {
+	case CATALOG_FORMAT_MARKDOWN:
+		catalogMarkdown(&b, entries, name)
+	case CATALOG_FORMAT_OPENAPI:
+		catalogOpenAPI(&b, entries, name)
+	case CATALOG_FORMAT_PROTOBUF:
+		catalogProtobuf(&b, entries, name)
+	default:
+		return fmt.Errorf("ekaerr: unknown catalog format %d", format)
+	}
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// ExportCatalogFile is the same as ExportCatalog() but writes the catalog
+// to the file by 'path' (it's created or truncated).
+// It's designed to be called by a small program from go:generate directive:
+//
+//	//go:generate go run ./cmd/errcatalog
+//
+//	func main() {
+//	    // All Classes are created at the package level of the "errs" package.
+//	    _ = errs.NotFound
+//	    err := ekaerr.ExportCatalogFile("api/errors.proto", ekaerr.CATALOG_FORMAT_PROTOBUF, "")
+//	    if err != nil {
+//	        log.Fatalln(err)
+//	    }
+//	}
+func ExportCatalogFile(path string, format CatalogFormat, name string) error {
+
+	var b bytes.Buffer
+	if err := ExportCatalog(&b, format, name); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, b.Bytes(), 0644)
+}
+
+// ---------------------------------------------------------------------------- //
+
+type (
+	// catalogEntry is a rendered Class's data.
+	catalogEntry struct {
+		id        ClassID
+		namespace string
+		chain     string // "<Class>.<SubClass>"
+		parent    string // full name of the parent Class
+		words     []string
+	}
+)
+
+// catalogEntries returns the catalogEntry of all registered Classes.
+func catalogEntries() []catalogEntry {
+
+	var (
+		classes = Classes()
+		entries = make([]catalogEntry, 0, len(classes))
+		chains  = make(map[ClassID]string, len(classes))
+	)
+
+	// Parent Class is always created before its subclasses,
+	// thus its chain is already known.
+	for _, c := range classes {
+
+		e := catalogEntry{
+			id:        c.id,
+			namespace: c.Namespace().Name(),
+			chain:     c.name,
+		}
+
+		if parentChain, ok := chains[c.parentID]; ok && isValidClassID(c.parentID) {
+			e.chain = parentChain + "." + c.name
+			e.parent = e.namespace + "::" + parentChain
+		}
+
+		chains[c.id] = e.chain
+		e.words = append(catalogWords(e.namespace), catalogWords(e.chain)...)
+		entries = append(entries, e)
+	}
+
+	return entries
+}
+
+// fullName returns Class's full name: "<Namespace>::<Class>.<SubClass>".
+func (e catalogEntry) fullName() string {
+	return e.namespace + "::" + e.chain
+}
+
+// catalogMarkdown renders 'entries' as a markdown table.
+func catalogMarkdown(b *bytes.Buffer, entries []catalogEntry, name string) {
+
+	b.WriteString("<!-- Code generated by ekaerr.ExportCatalog(). DO NOT EDIT. -->\n\n")
+	b.WriteString("# " + name + "\n\n")
+	b.WriteString("| ID | Class | Parent | Namespace |\n")
+	b.WriteString("|---:|-------|--------|-----------|\n")
+
+	for _, e := range entries {
+		parent := ""
+		if e.parent != "" {
+			parent = "`" + e.parent + "`"
+		}
+		fmt.Fprintf(b, "| %d | `%s` | %s | %s |\n", e.id, e.fullName(), parent, e.namespace)
+	}
+}
+
+// catalogOpenAPI renders 'entries' as an OpenAPI YAML components object.
+func catalogOpenAPI(b *bytes.Buffer, entries []catalogEntry, name string) {
+
+	b.WriteString("# Code generated by ekaerr.ExportCatalog(). DO NOT EDIT.\n\n")
+	b.WriteString("components:\n")
+	b.WriteString("  schemas:\n")
+	b.WriteString("    " + name + ":\n")
+	b.WriteString("      type: string\n")
+	b.WriteString("      enum:\n")
+
+	for _, e := range entries {
+		b.WriteString("        - " + strconv.Quote(e.fullName()) + "\n")
+	}
+
+	b.WriteString("      x-enum-varnames:\n")
+
+	for _, e := range entries {
+		b.WriteString("        - " + catalogJoin(e.words, false) + "\n")
+	}
+}
+
+// catalogProtobuf renders 'entries' as a protobuf file with an enum.
+func catalogProtobuf(b *bytes.Buffer, entries []catalogEntry, name string) {
+
+	prefix := catalogJoin(catalogWords(name), true) + "_"
+
+	b.WriteString("// Code generated by ekaerr.ExportCatalog(). DO NOT EDIT.\n\n")
+	b.WriteString("syntax = \"proto3\";\n\n")
+	b.WriteString("enum " + name + " {\n")
+	b.WriteString("  " + prefix + "UNSPECIFIED = 0;\n")
+
+	for _, e := range entries {
+		fmt.Fprintf(b, "  %s%s = %d; // %s\n",
+			prefix, catalogJoin(e.words, true), e.id, e.fullName())
+	}
+
+	b.WriteString("}\n")
+}
+
+// catalogWords splits 's' to the words by non-alphanumeric chars
+// and camel case boundaries: "HTTPServer.NotFound" -> "HTTP", "Server", "Not", "Found".
+func catalogWords(s string) []string {
+
+	var (
+		words []string
+		start = -1
+	)
+
+	isUpper := func(c byte) bool { return c >= 'A' && c <= 'Z' }
+	isLower := func(c byte) bool { return c >= 'a' && c <= 'z' || c >= '0' && c <= '9' }
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+
+		case !isUpper(c) && !isLower(c):
+			if start != -1 {
+				words = append(words, s[start:i])
+				start = -1
+			}
+
+		case start == -1:
+			start = i
+
+		case isUpper(c) && (isLower(s[i-1]) ||
+			i+1 < len(s) && isLower(s[i+1]) && isUpper(s[i-1])):
+			words = append(words, s[start:i])
+			start = i
+		}
+	}
+
+	if start != -1 {
+		words = append(words, s[start:])
+	}
+
+	return words
+}
+
+// catalogJoin joins 'words' as UPPER_SNAKE_CASE if 'upperSnake' is true,
+// or as CamelCase otherwise.
+func catalogJoin(words []string, upperSnake bool) string {
+
+	var b []byte
+	for i, w := range words {
+		switch {
+		case upperSnake && i > 0:
+			b = append(b, '_')
+			fallthrough
+		case upperSnake:
+			b = append(b, bytes.ToUpper([]byte(w))...)
+		default:
+			b = append(b, bytes.ToUpper([]byte(w[:1]))...)
+			b = append(b, w[1:]...)
+		}
+	}
+
+	return string(b)
+}
@@ -0,0 +1,50 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr_test
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExportCatalog(t *testing.T) {
+
+	httpClass := ekaerr.ServiceUnavailable.NewSubClass("HTTPServerDown")
+
+	classes := ekaerr.Classes()
+	require.NotEmpty(t, classes)
+	assert.Equal(t, ekaerr.NotFound, classes[0])
+	assert.Equal(t, httpClass, classes[len(classes)-1])
+	assert.Equal(t, "Common", httpClass.Namespace().Name())
+
+	var b bytes.Buffer
+
+	require.NoError(t, ekaerr.ExportCatalog(&b, ekaerr.CATALOG_FORMAT_PROTOBUF, ""))
+	assert.Contains(t, b.String(), "enum ErrorClass {\n  ERROR_CLASS_UNSPECIFIED = 0;\n")
+	assert.Contains(t, b.String(), fmt.Sprintf(
+		"  ERROR_CLASS_COMMON_SERVICE_UNAVAILABLE_HTTP_SERVER_DOWN = %d; "+
+			"// Common::ServiceUnavailable.HTTPServerDown\n", httpClass.ID()))
+
+	b.Reset()
+	require.NoError(t, ekaerr.ExportCatalog(&b, ekaerr.CATALOG_FORMAT_OPENAPI, "ErrorCode"))
+	assert.Contains(t, b.String(), "    ErrorCode:\n      type: string\n      enum:\n")
+	assert.Contains(t, b.String(), `        - "Common::NotFound"`+"\n")
+	assert.Contains(t, b.String(), "        - CommonServiceUnavailableHTTPServerDown\n")
+
+	b.Reset()
+	require.NoError(t, ekaerr.ExportCatalog(&b, ekaerr.CATALOG_FORMAT_MARKDOWN, ""))
+	assert.Contains(t, b.String(), fmt.Sprintf(
+		"| %d | `Common::ServiceUnavailable.HTTPServerDown` | `Common::ServiceUnavailable` | Common |\n",
+		httpClass.ID()))
+
+	assert.Error(t, ekaerr.ExportCatalog(&b, ekaerr.CatalogFormat(100), ""))
+}
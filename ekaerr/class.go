@@ -81,7 +81,7 @@ func (c Class) New(message string, args ...any) *Error {
 	if !isValidClassID(c.id) {
 		return nil
 	}
-	return newError(false, c.id, c.namespaceID, nil, message, args)
+	return newError(_ERR_STACK_MODE_FULL, c.id, c.namespaceID, nil, message, args)
 }
 
 // LightNew is the same as just New() but creates a lightweight Error instead.
@@ -90,7 +90,7 @@ func (c Class) LightNew(message string, args ...any) *Error {
 	if !isValidClassID(c.id) {
 		return nil
 	}
-	return newError(true, c.id, c.namespaceID, nil, message, args)
+	return newError(_ERR_STACK_MODE_LIGHTWEIGHT, c.id, c.namespaceID, nil, message, args)
 }
 
 // Wrap is an Error's constructor. Specify what legacy Golang error you need
@@ -105,7 +105,7 @@ func (c Class) Wrap(err error, message string, args ...any) *Error {
 	if !isValidClassID(c.id) || err == nil {
 		return nil
 	}
-	return newError(false, c.id, c.namespaceID, err, message, args)
+	return newError(_ERR_STACK_MODE_FULL, c.id, c.namespaceID, err, message, args)
 }
 
 // LightWrap is the same as just Wrap() but creates a lightweight Error instead.
@@ -114,7 +114,25 @@ func (c Class) LightWrap(err error, message string, args ...any) *Error {
 	if !isValidClassID(c.id) || err == nil {
 		return nil
 	}
-	return newError(true, c.id, c.namespaceID, err, message, args)
+	return newError(_ERR_STACK_MODE_LIGHTWEIGHT, c.id, c.namespaceID, err, message, args)
+}
+
+// NewLazy is the same as just New() but creates an Error with lazy stacktrace.
+// Read more what lazy error is in Error's doc.
+func (c Class) NewLazy(message string, args ...any) *Error {
+	if !isValidClassID(c.id) {
+		return nil
+	}
+	return newError(_ERR_STACK_MODE_LAZY, c.id, c.namespaceID, nil, message, args)
+}
+
+// WrapLazy is the same as just Wrap() but creates an Error with lazy stacktrace.
+// Read more what lazy error is in Error's doc.
+func (c Class) WrapLazy(err error, message string, args ...any) *Error {
+	if !isValidClassID(c.id) || err == nil {
+		return nil
+	}
+	return newError(_ERR_STACK_MODE_LAZY, c.id, c.namespaceID, err, message, args)
 }
 
 // IsValid reports whether c is valid Class object or not.
@@ -136,6 +154,25 @@ func (c Class) ParentClass() Class {
 	return classByID(c.parentID, true)
 }
 
+// ID returns a current Class's unique ID. IDs are assigned sequentially
+// in the order Classes are created, starting from 1.
+// Returns _ERR_INVALID_CLASS_ID (-1) if Class is invalid.
+func (c Class) ID() ClassID {
+	if !c.IsValid() {
+		return _ERR_INVALID_CLASS_ID
+	}
+	return c.id
+}
+
+// Namespace returns a Namespace the current Class belongs to
+// or an invalid Namespace if Class is invalid.
+func (c Class) Namespace() Namespace {
+	if !c.IsValid() {
+		return Namespace{id: _ERR_INVALID_NAMESPACE_ID}
+	}
+	return namespaceByID(c.namespaceID, true)
+}
+
 // Name returns a current Class's name that was used at the Class creation.
 // If you want to get a full name (without Namespace's and Base classes' use
 // c.FullName() instead).
@@ -59,4 +59,12 @@ var (
 
 	// UnsupportedVersion is a class for unsupported version error
 	UnsupportedVersion = UnsupportedOperation.NewSubClass("UnsupportedVersion")
+
+	// Aggregated is a class for an error that aggregates another errors.
+	// It's used by Aggregate().
+	Aggregated = CommonErrors.NewClass("Aggregated")
+
+	// Panic is a class for an error that a recovered panic is converted to.
+	// It's used by Group.
+	Panic = CommonErrors.NewClass("Panic")
 )
@@ -0,0 +1,219 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasql
+
+import (
+	"github.com/qioalice/ekago/v3/ekaerr"
+)
+
+type (
+	// Driver describes how to classify the errors of some database/sql driver
+	// (or of a native client, like pgx).
+	//
+	// Use Postgres() or MySQL() to get the predefined ones,
+	// or fill your own for other drivers.
+	Driver struct {
+
+		// Code returns the driver-specific code of 'err' (SQLSTATE for PostgreSQL,
+		// error number for MySQL) or "" if 'err' is not a driver's error.
+		// It's called for each error of the chain (errors.Unwrap()).
+		Code func(err error) string
+
+		// Constraint returns the name of the violated constraint of 'err'
+		// (for which Code() has returned a non-empty code) or "" if it's unknown.
+		// May be nil.
+		Constraint func(err error) string
+
+		// Codes maps the codes to the Classes. If there's no exact code,
+		// its first 2 chars are looked up (it's a SQLSTATE's class).
+		// The codes w/o Class are classified as DatabaseError.
+		Codes map[string]ekaerr.Class
+	}
+
+	// Classifier maps the errors of database/sql and its driver
+	// to the Classes of this package with the standard fields
+	// (read more: FIELD_KEY_CODE, FIELD_KEY_CONSTRAINT).
+	//
+	// Classifier MUST be created by NewClassifier().
+	// It's immutable and thread-safe once it's created.
+	Classifier struct {
+		driver Driver
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// FIELD_KEY_CODE is the key of the field, that holds the driver-specific
+	// code of the error (read more: Driver.Code).
+	FIELD_KEY_CODE = "sqlstate"
+
+	// FIELD_KEY_CONSTRAINT is the key of the field, that holds the name
+	// of the violated constraint (read more: Driver.Constraint).
+	FIELD_KEY_CONSTRAINT = "constraint"
+)
+
+var (
+	// DatabaseError is a class for a database error, that is not classified
+	// more precisely.
+	DatabaseError = ekaerr.ExternalError.NewSubClass("Database")
+
+	// NoRows is a class for sql.ErrNoRows.
+	NoRows = ekaerr.NotFound.NewSubClass("NoRows")
+
+	// UniqueViolation is a class for a unique constraint violation.
+	UniqueViolation = ekaerr.AlreadyExist.NewSubClass("UniqueViolation")
+
+	// IntegrityViolation is a class for an integrity constraint violation,
+	// except unique one.
+	IntegrityViolation = ekaerr.IllegalArgument.NewSubClass("IntegrityViolation")
+
+	// ForeignKeyViolation is a class for a foreign key constraint violation.
+	ForeignKeyViolation = IntegrityViolation.NewSubClass("ForeignKeyViolation")
+
+	// NotNullViolation is a class for a not null constraint violation.
+	NotNullViolation = IntegrityViolation.NewSubClass("NotNullViolation")
+
+	// CheckViolation is a class for a check constraint violation.
+	CheckViolation = IntegrityViolation.NewSubClass("CheckViolation")
+
+	// DataException is a class for an invalid data (out of range, bad format, etc.).
+	DataException = ekaerr.IllegalArgument.NewSubClass("DataException")
+
+	// SerializationFailure is a class for a transaction serialization failure.
+	// The transaction may be retried.
+	SerializationFailure = ekaerr.ConcurrentUpdate.NewSubClass("SerializationFailure")
+
+	// Deadlock is a class for a detected deadlock. The transaction may be retried.
+	Deadlock = ekaerr.ConcurrentUpdate.NewSubClass("Deadlock")
+
+	// ConnectionFailure is a class for a failed or lost connection.
+	ConnectionFailure = ekaerr.ServiceUnavailable.NewSubClass("ConnectionFailure")
+
+	// Timeout is a class for a query (statement, lock) timeout
+	// or the deadline exceeding of context.Context.
+	Timeout = ekaerr.TimeoutElapsed.NewSubClass("QueryTimeout")
+
+	// Canceled is a class for a query, canceled by context.Context.
+	Canceled = ekaerr.Interrupted.NewSubClass("QueryCanceled")
+)
+
+// NewClassifier creates a new Classifier of 'driver' errors.
+// Driver.Codes is copied.
+func NewClassifier(driver Driver) *Classifier {
+
+	codes := make(map[string]ekaerr.Class, len(driver.Codes))
+	for code, class := range driver.Codes {
+		codes[code] = class
+	}
+
+	driver.Codes = codes
+	return &Classifier{driver: driver}
+}
+
+// Classify returns the Class of 'err', its driver-specific code
+// and the name of the violated constraint (if they are known).
+// Returns an invalid Class if 'err' is nil.
+//
+// The errors are classified in this order:
+//
+//   - sql.ErrNoRows is NoRows;
+//   - the driver's errors are classified by Driver.Codes;
+//   - context.DeadlineExceeded and network timeouts are Timeout;
+//   - context.Canceled is Canceled;
+//   - driver.ErrBadConn, sql.ErrConnDone and network errors are ConnectionFailure;
+//   - sql.ErrTxDone is ekaerr.IllegalState;
+//   - all others are DatabaseError.
+func (c *Classifier) Classify(err error) (class ekaerr.Class, code, constraint string) {
+	return c.classify(err)
+}
+
+// Wrap wraps 'err' by a new *ekaerr.Error of the Class, 'err' is classified as
+// (read more: Classify()), adding FIELD_KEY_CODE, FIELD_KEY_CONSTRAINT fields
+// (if they are known). 'message' and 'args' are the same as ekaerr.Class.Wrap() has.
+// Returns nil if 'err' is nil.
+//
+//	row := db.QueryRowContext(ctx, "SELECT ...")
+//	if err := row.Scan(&user.Name); err != nil {
+//	    return classifier.Wrap(err, "Failed to get user", "user_id", id).
+//	        Throw()
+//	}
+func (c *Classifier) Wrap(err error, message string, args ...any) *ekaerr.Error {
+
+	class, code, constraint := c.classify(err)
+	if !class.IsValid() {
+		return nil
+	}
+
+	e := class.Wrap(err, message, args...)
+	if code != "" {
+		e = e.WithString(FIELD_KEY_CODE, code)
+	}
+	if constraint != "" {
+		e = e.WithString(FIELD_KEY_CONSTRAINT, constraint)
+	}
+
+	return e
+}
+
+// IsRetryable reports whether the transaction, that is failed with 'err',
+// may be retried: 'err' is of SerializationFailure, Deadlock
+// or ConnectionFailure Class.
+func IsRetryable(err *ekaerr.Error) bool {
+	return err.IsAny(SerializationFailure, Deadlock, ConnectionFailure)
+}
+
+// Postgres returns a Driver for PostgreSQL drivers (pgx, lib/pq),
+// the errors of which have SQLState() string method.
+// The name of the violated constraint is taken from
+// the ConstraintName (pgx) or Constraint (lib/pq) string field.
+func Postgres() Driver {
+	return Driver{
+		Code:       postgresCode,
+		Constraint: postgresConstraint,
+		Codes: map[string]ekaerr.Class{
+			"23505": UniqueViolation,
+			"23503": ForeignKeyViolation,
+			"23502": NotNullViolation,
+			"23514": CheckViolation,
+			"23":    IntegrityViolation,
+			"22":    DataException,
+			"40001": SerializationFailure,
+			"40P01": Deadlock,
+			"08":    ConnectionFailure,
+			"57P01": ConnectionFailure, // admin_shutdown
+			"57P02": ConnectionFailure, // crash_shutdown
+			"57P03": ConnectionFailure, // cannot_connect_now
+			"57014": Timeout,           // query_canceled (statement_timeout)
+			"55P03": Timeout,           // lock_not_available (lock_timeout)
+			"53":    ekaerr.ServiceUnavailable,
+		},
+	}
+}
+
+// MySQL returns a Driver for MySQL driver (go-sql-driver/mysql),
+// the errors of which have the Number uint16 field.
+// The codes are the error numbers in decimal.
+// The name of the violated constraint is not provided.
+func MySQL() Driver {
+	return Driver{
+		Code: mysqlCode,
+		Codes: map[string]ekaerr.Class{
+			"1062": UniqueViolation,     // ER_DUP_ENTRY
+			"1586": UniqueViolation,     // ER_DUP_ENTRY_WITH_KEY_NAME
+			"1451": ForeignKeyViolation, // ER_ROW_IS_REFERENCED_2
+			"1452": ForeignKeyViolation, // ER_NO_REFERENCED_ROW_2
+			"1048": NotNullViolation,    // ER_BAD_NULL_ERROR
+			"3819": CheckViolation,      // ER_CHECK_CONSTRAINT_VIOLATED
+			"1264": DataException,       // ER_WARN_DATA_OUT_OF_RANGE
+			"1406": DataException,       // ER_DATA_TOO_LONG
+			"1213": Deadlock,            // ER_LOCK_DEADLOCK
+			"1205": Timeout,             // ER_LOCK_WAIT_TIMEOUT
+			"3024": Timeout,             // ER_QUERY_TIMEOUT
+			"1040": ConnectionFailure,   // ER_CON_COUNT_ERROR
+			"1053": ConnectionFailure,   // ER_SERVER_SHUTDOWN
+		},
+	}
+}
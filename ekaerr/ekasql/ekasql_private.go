@@ -0,0 +1,121 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"net"
+	"reflect"
+	"strconv"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+)
+
+// classify is Classify() implementation.
+func (c *Classifier) classify(err error) (class ekaerr.Class, code, constraint string) {
+
+	if err == nil {
+		return ekaerr.Class{}, "", ""
+	}
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return NoRows, "", ""
+	}
+
+	if c.driver.Code != nil {
+		for e := err; e != nil; e = errors.Unwrap(e) {
+			if code = c.driver.Code(e); code == "" {
+				continue
+			}
+			if c.driver.Constraint != nil {
+				constraint = c.driver.Constraint(e)
+			}
+			return c.classOf(code), code, constraint
+		}
+	}
+
+	var netErr net.Error
+	isNetErr := errors.As(err, &netErr)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded) || isNetErr && netErr.Timeout():
+		return Timeout, "", ""
+	case errors.Is(err, context.Canceled):
+		return Canceled, "", ""
+	case errors.Is(err, driver.ErrBadConn) || errors.Is(err, sql.ErrConnDone) || isNetErr:
+		return ConnectionFailure, "", ""
+	case errors.Is(err, sql.ErrTxDone):
+		return ekaerr.IllegalState, "", ""
+	default:
+		return DatabaseError, "", ""
+	}
+}
+
+// classOf returns the Class of driver-specific 'code': the exact one,
+// the one of its first 2 chars or DatabaseError.
+func (c *Classifier) classOf(code string) ekaerr.Class {
+
+	if class, ok := c.driver.Codes[code]; ok {
+		return class
+	}
+
+	if len(code) > 2 {
+		if class, ok := c.driver.Codes[code[:2]]; ok {
+			return class
+		}
+	}
+
+	return DatabaseError
+}
+
+// postgresCode is Postgres() Driver.Code.
+func postgresCode(err error) string {
+	if e, ok := err.(interface{ SQLState() string }); ok {
+		return e.SQLState()
+	}
+	return ""
+}
+
+// postgresConstraint is Postgres() Driver.Constraint.
+func postgresConstraint(err error) string {
+	if s := structField(err, "ConstraintName"); s.Kind() == reflect.String {
+		return s.String()
+	}
+	if s := structField(err, "Constraint"); s.Kind() == reflect.String {
+		return s.String()
+	}
+	return ""
+}
+
+// mysqlCode is MySQL() Driver.Code.
+func mysqlCode(err error) string {
+	if n := structField(err, "Number"); n.Kind() == reflect.Uint16 && n.Uint() != 0 {
+		return strconv.FormatUint(n.Uint(), 10)
+	}
+	return ""
+}
+
+// structField returns the field 'name' of 'err', if it's a struct
+// or a pointer to struct, or an invalid reflect.Value otherwise.
+func structField(err error, name string) reflect.Value {
+
+	v := reflect.ValueOf(err)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return reflect.Value{}
+		}
+		v = v.Elem()
+	}
+
+	if v.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+
+	return v.FieldByName(name)
+}
@@ -0,0 +1,102 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasql_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekaerr/ekasql"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	tPgError struct {
+		Code, ConstraintName string
+	}
+	tMySQLError struct {
+		Number  uint16
+		Message string
+	}
+)
+
+func (e *tPgError) Error() string    { return "pg: " + e.Code }
+func (e *tPgError) SQLState() string { return e.Code }
+
+func (e *tMySQLError) Error() string { return "mysql: " + e.Message }
+
+func TestClassifier_Classify(t *testing.T) {
+
+	pg := ekasql.NewClassifier(ekasql.Postgres())
+	my := ekasql.NewClassifier(ekasql.MySQL())
+
+	tests := []struct {
+		c          *ekasql.Classifier
+		err        error
+		class      ekaerr.Class
+		code       string
+		constraint string
+	}{
+		{pg, sql.ErrNoRows, ekasql.NoRows, "", ""},
+		{pg, fmt.Errorf("query: %w", sql.ErrNoRows), ekasql.NoRows, "", ""},
+		{pg, &tPgError{"23505", "users_email_key"}, ekasql.UniqueViolation, "23505", "users_email_key"},
+		{pg, fmt.Errorf("insert: %w", &tPgError{"23503", "fk"}), ekasql.ForeignKeyViolation, "23503", "fk"},
+		{pg, &tPgError{"23P01", ""}, ekasql.IntegrityViolation, "23P01", ""},
+		{pg, &tPgError{"40001", ""}, ekasql.SerializationFailure, "40001", ""},
+		{pg, &tPgError{"08006", ""}, ekasql.ConnectionFailure, "08006", ""},
+		{pg, &tPgError{"42601", ""}, ekasql.DatabaseError, "42601", ""},
+		{my, &tMySQLError{1062, "Duplicate entry"}, ekasql.UniqueViolation, "1062", ""},
+		{my, &tMySQLError{1213, "Deadlock"}, ekasql.Deadlock, "1213", ""},
+		{my, &tPgError{"23505", ""}, ekasql.DatabaseError, "", ""},
+		{pg, context.DeadlineExceeded, ekasql.Timeout, "", ""},
+		{pg, context.Canceled, ekasql.Canceled, "", ""},
+		{pg, driver.ErrBadConn, ekasql.ConnectionFailure, "", ""},
+		{pg, sql.ErrTxDone, ekaerr.IllegalState, "", ""},
+		{pg, errors.New("unknown"), ekasql.DatabaseError, "", ""},
+	}
+
+	for i, test := range tests {
+		class, code, constraint := test.c.Classify(test.err)
+		assert.Equal(t, test.class.FullName(), class.FullName(), i)
+		assert.Equal(t, test.code, code, i)
+		assert.Equal(t, test.constraint, constraint, i)
+	}
+
+	class, _, _ := pg.Classify(nil)
+	assert.False(t, class.IsValid())
+}
+
+func TestClassifier_Wrap(t *testing.T) {
+
+	pg := ekasql.NewClassifier(ekasql.Postgres())
+
+	assert.Nil(t, pg.Wrap(nil, "no error"))
+
+	err := pg.Wrap(&tPgError{"40P01", "c"}, "Failed to update", "id", 42)
+	assert.True(t, err.Is(ekasql.Deadlock))
+	assert.True(t, err.IsAnyDeep(ekaerr.ConcurrentUpdate))
+	assert.True(t, ekasql.IsRetryable(err))
+
+	code, _ := err.FieldString(ekasql.FIELD_KEY_CODE)
+	constraint, _ := err.FieldString(ekasql.FIELD_KEY_CONSTRAINT)
+	id, _ := err.FieldInt64("id")
+	assert.Equal(t, "40P01", code)
+	assert.Equal(t, "c", constraint)
+	assert.Equal(t, int64(42), id)
+
+	err = pg.Wrap(sql.ErrNoRows, "Failed to get user")
+	assert.True(t, err.IsAnyDeep(ekaerr.NotFound))
+	assert.False(t, ekasql.IsRetryable(err))
+
+	_, hasCode := err.FieldString(ekasql.FIELD_KEY_CODE)
+	assert.False(t, hasCode)
+}
@@ -7,7 +7,9 @@ package ekaerr
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/qioalice/ekago/v3/internal/ekaletter"
@@ -26,6 +28,7 @@ type (
 	// - DO NOT CREATE ERROR OBJECTS MANUALLY, USE Class's CONSTRUCTORS INSTEAD.
 	// - DO NOT FORGET TO USE Throw().
 	// - IF YOU WANT TO DO SOMETHING WITH YOUR ERROR, DO IT BEFORE LOGGING.
+	// - IF YOU NEED YOUR ERROR AFTER LOGGING, USE Freeze() BEFORE LOGGING.
 	// - ALL ERROR OBJECTS ARE THREAD-UNSAFE. AVOID POTENTIAL DATA RACES!
 	// - NEVER USE ERROR OBJECT AS VALUE, ALWAYS USE BY REFERENCE.
 	//
@@ -76,6 +79,19 @@ type (
 	//
 	// -----
 	//
+	// Lazy errors.
+	//
+	// Lazy errors (Class.NewLazy(), Class.WrapLazy()) are something in between.
+	// Only the stacktrace's frame points are captured at the creation (that is cheap),
+	// and they're resolved to the full stacktrace (that is expensive) only when
+	// the Error is logged or Throw()-ed more times than it's set
+	// by SetLazyStackTraceThrowDepth().
+	// Until then messages and fields are linked to the stack frames as usual.
+	// Thus, you get the cheapness of lightweight errors for errors that are handled
+	// and the full stacktrace for errors that matter.
+	//
+	// -----
+	//
 	// Error's ID.
 	//
 	// Each Error object (even lightweight) has its own ID.
@@ -104,6 +120,14 @@ type (
 		// the Class that has been used to create this object, belongs to.
 		namespaceID NamespaceID
 
+		// children are Error objects that are aggregated by this Error
+		// using Append() or Aggregate().
+		// They are released along with this Error.
+		children []*Error
+
+		// parent is Error, that aggregates this Error (nil if there's no one).
+		parent *Error
+
 		needSetFinalizer bool
 	}
 )
@@ -153,6 +177,12 @@ func (e *Error) IsNil() bool {
 func (e *Error) Throw() *Error {
 	if e.IsValid() {
 		ekaletter.LIncStackIdx(e.letter)
+		if ekaletter.LIsLazyStackTrace(e.letter) {
+			depth := atomic.LoadInt32(&lazyStackTraceThrowDepth)
+			if depth > 0 && int32(ekaletter.LGetStackIdx(e.letter)) >= depth {
+				ekaletter.LResolveStackTrace(e.letter)
+			}
+		}
 	}
 	return e
 }
@@ -168,6 +198,30 @@ func (e *Error) AddMessage(message string) *Error {
 	return e
 }
 
+// Boundary marks your current Error's stack frame as the outermost one
+// of the logical layer 'name' (e.g. "transport", "service", "repo").
+// The stack frames since the previous Boundary() call (or from the first one)
+// up to the current one belong to that layer, and the encoders render them
+// (along with their messages and fields) as a named section.
+//
+// Call it at the layer's entry point, before Throw():
+//
+//	if err := repo.GetUser(id); err != nil {
+//	    return err.Boundary("repo").AddMessage("failed to get user").Throw()
+//	}
+//
+// Calling it twice for the same stack frame overwrites the name.
+// Empty 'name' is ignored.
+// Nil safe. Returns this.
+func (e *Error) Boundary(name string) *Error {
+	if e.IsValid() {
+		if name = strings.TrimSpace(name); name != "" {
+			ekaletter.LAddBoundary(e.letter, name)
+		}
+	}
+	return e
+}
+
 // With adds provided ekaletter.LetterField to your current Error stack frame.
 // Nil safe. Returns this.
 func (e *Error) With(f ekaletter.LetterField) *Error { return e.addField(f) }
@@ -322,6 +376,46 @@ func (e *Error) WithDescription(description string) *Error {
 	return e.WithString("description", description)
 }
 
+// WithFieldsOverwrite enables or disables the fields overwrite mode.
+// If it's enabled, adding a field with the key of existed field overwrites
+// (removes) the existed one instead of adding a duplicate. It's disabled by default.
+// Use WithDuplicate() to add a duplicate explicitly anyway.
+// Nil safe. Returns this.
+func (e *Error) WithFieldsOverwrite(enabled bool) *Error {
+	if e.IsValid() {
+		ekaletter.LSetFieldsOverwrite(e.letter, enabled)
+	}
+	return e
+}
+
+// WithUnnamedKeys sets the policy of the unnamed fields' keys generation
+// and the prefix of generated keys (UNNAMED_KEYS_DEFAULT_PREFIX if it's empty).
+// By default, the unnamed fields are kept unnamed and their keys are generated
+// by ekalog's encoders for each entry separately ("unnamed_01", ...),
+// thus they collide across the log entries.
+// Only the fields that are added after this call are affected.
+// Read more: UNNAMED_KEYS_POLICY_PADDED, UNNAMED_KEYS_POLICY_PLAIN,
+// UNNAMED_KEYS_POLICY_REJECT.
+// Nil safe. Returns this.
+func (e *Error) WithUnnamedKeys(policy UnnamedKeysPolicy, prefix string) *Error {
+	if e.IsValid() {
+		ekaletter.LSetUnnamedKeys(e.letter, policy, prefix)
+	}
+	return e
+}
+
+// WithDuplicate is the same as With() but adds provided ekaletter.LetterField
+// even if the fields overwrite mode is enabled and there is a field
+// with the same key already. Nil safe. Returns this.
+func (e *Error) WithDuplicate(f ekaletter.LetterField) *Error {
+	if e.IsValid() {
+		n := len(e.letter.Fields)
+		ekaletter.LAddFieldWithCheck(e.letter, f)
+		ekaletter.LApplyUnnamedKeys(e.letter, n)
+	}
+	return e
+}
+
 // Apply calls f callback passing the current Error object into and returning
 // the Error object, callback is return what.
 // Nil safe.
@@ -424,6 +518,97 @@ func (e *Error) ID() string {
 	return e.letter.SystemFields[_ERR_SYS_FIELD_IDX_ERROR_ID].SValue
 }
 
+// Append aggregates provided errors making them children of the current Error.
+// Invalid (nil) errors are skipped.
+// Nil safe.
+//
+// Each aggregated Error keeps its own stacktrace, messages and fields,
+// and both of ekalog.CI_ConsoleEncoder, ekalog.CI_JSONEncoder render them
+// as a numbered list after the current Error's stacktrace.
+//
+// It's useful when you have a several independent failures (e.g. from fan-out
+// workers) and you want to return them as one Error.
+//
+// WARNING!
+// Aggregated errors belong to the current Error since they're passed.
+// You MUST NOT use them after, they will be released along with the current Error.
+//
+// Errors that are aggregated already (by the current or another Error),
+// and the current Error itself or the errors that aggregate it
+// (that would be a cycle) are skipped too.
+func (e *Error) Append(errs ...*Error) *Error {
+	if e.IsValid() {
+		for i, n := 0, len(errs); i < n; i++ {
+			if e.canAppend(errs[i]) {
+				errs[i].parent = e
+				e.children = append(e.children, errs[i])
+				e.letter.Children = append(e.letter.Children, errs[i].letter)
+			}
+		}
+	}
+	return e
+}
+
+// Aggregate returns a new Error of Aggregated Class that aggregates
+// provided errors (using Append()). Invalid (nil) errors are skipped,
+// as well as the ones Append() skips.
+// Returns nil if there is no valid error.
+//
+// If you want an aggregating Error of your own Class,
+// just create it and then call Append().
+//
+// WARNING!
+// Aggregated errors belong to the returned Error. Read more: Error.Append().
+func Aggregate(errs ...*Error) *Error {
+
+	n := 0
+	for i, l := 0, len(errs); i < l; i++ {
+		if errs[i].IsValid() && errs[i].parent == nil && !errIsIn(errs[i], errs[:i]) {
+			n++
+		}
+	}
+
+	if n == 0 {
+		return nil
+	}
+
+	message := strconv.Itoa(n) + " errors occurred"
+	return newError(_ERR_STACK_MODE_FULL, Aggregated.id, Aggregated.namespaceID, nil, message, nil).
+		Append(errs...)
+}
+
+// Len returns how much errors are aggregated by the current Error.
+// Returns 0 if Error is not valid.
+// Nil safe.
+func (e *Error) Len() int {
+	if !e.IsValid() {
+		return 0
+	}
+	return len(e.children)
+}
+
+// Errors returns errors that are aggregated by the current Error
+// in order they have been added.
+// Returns nil if Error is not valid or it has no aggregated errors.
+// Nil safe.
+//
+// WARNING!
+// You MUST NOT modify returned slice.
+func (e *Error) Errors() []*Error {
+	if !e.IsValid() || len(e.children) == 0 {
+		return nil
+	}
+	return e.children
+}
+
+// FirstOf returns the first aggregated Error that belongs to the provided Class
+// or to any of its subclasses. Nested aggregated errors are also checked
+// (depth-first). Returns nil if there is no such Error or Error is not valid.
+// Nil safe.
+func (e *Error) FirstOf(cls Class) *Error {
+	return e.firstOf(cls)
+}
+
 // ReleaseError prepares Error for being reused in the future and releases
 // its internal parts (returning them to the pool).
 //
@@ -434,3 +619,28 @@ func ReleaseError(err *Error) {
 		releaseError(err)
 	}
 }
+
+// ReleaseErrors is the same as ReleaseError() but for each Error of 'errs'.
+// Released errors are replaced by nil in 'errs'. Nil and invalid Error are skipped.
+//
+// THE SAME ERROR MUST NOT BE PRESENTED IN 'errs' TWICE.
+// YOU MUST NOT USE ERROR OBJECTS AFTER PASSING THEM INTO THIS FUNCTION.
+func ReleaseErrors(errs []*Error) {
+	for i := range errs {
+		ReleaseError(errs[i])
+		errs[i] = nil
+	}
+}
+
+// SetLazyStackTraceThrowDepth sets the number of Throw() calls, after which
+// the lazy stacktrace of Error (see Class.NewLazy(), Class.WrapLazy()) is resolved
+// (if it's not resolved yet by logging). Thread-safe.
+//
+// Non-positive 'depth' (the default) means that lazy stacktrace is resolved
+// only when Error is logged.
+func SetLazyStackTraceThrowDepth(depth int) {
+	if depth < 0 {
+		depth = 0
+	}
+	atomic.StoreInt32(&lazyStackTraceThrowDepth, int32(depth))
+}
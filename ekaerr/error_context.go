@@ -0,0 +1,124 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// ctxOperation is an operation, its name and start time,
+	// that is saved to context.Context by WithOperation().
+	ctxOperation struct {
+		name  string
+		start time.Time
+	}
+
+	// ctxOperationKey is a context.Context's key of ctxOperation.
+	ctxOperationKey struct{}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// CTX_FIELD_KEY_OPERATION is a key of the field, added by Class.WrapCtx(),
+	// with the name of operation. Read more: WithOperation().
+	CTX_FIELD_KEY_OPERATION = "ctx.operation"
+
+	// CTX_FIELD_KEY_DEADLINE is a key of the field, added by Class.WrapCtx(),
+	// with context.Context's deadline.
+	CTX_FIELD_KEY_DEADLINE = "ctx.deadline"
+
+	// CTX_FIELD_KEY_TIMEOUT is a key of the field, added by Class.WrapCtx(),
+	// with the time the operation was given: from its start till the deadline.
+	CTX_FIELD_KEY_TIMEOUT = "ctx.timeout"
+
+	// CTX_FIELD_KEY_ELAPSED is a key of the field, added by Class.WrapCtx(),
+	// with the time elapsed since the operation is started.
+	CTX_FIELD_KEY_ELAPSED = "ctx.elapsed"
+)
+
+// WithOperation returns a copy of 'ctx' with the operation 'name'
+// started at the current time. When context.DeadlineExceeded or context.Canceled
+// is wrapped by Class.WrapCtx() with this (or derived) context.Context,
+// the operation's name and elapsed time are attached to Error:
+//
+//	ctx, cancel := context.WithTimeout(ekaerr.WithOperation(ctx, "db.GetUser"), time.Second)
+//	defer cancel()
+//	if err := row.Scan(ctx, &user); err != nil {
+//	    return ekaerr.ExternalError.WrapCtx(ctx, err, "Failed to get user")
+//	}
+//
+// Nested operation overrides the parent one. Nil 'ctx' means context.Background().
+func WithOperation(ctx context.Context, name string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, ctxOperationKey{}, ctxOperation{name, time.Now()})
+}
+
+// OperationFromContext returns the name and the start time of the operation,
+// saved to 'ctx' by WithOperation(), or false if there's no one.
+func OperationFromContext(ctx context.Context) (name string, start time.Time, ok bool) {
+	if ctx == nil {
+		return "", time.Time{}, false
+	}
+	op, ok := ctx.Value(ctxOperationKey{}).(ctxOperation)
+	return op.name, op.start, ok
+}
+
+// WrapCtx is the same as just Wrap() but if 'err' is (or wraps)
+// context.DeadlineExceeded or context.Canceled, the fields of 'ctx'
+// are attached to Error, making the timeout errors actionable:
+//   - CTX_FIELD_KEY_OPERATION: the operation's name (read more: WithOperation());
+//   - CTX_FIELD_KEY_DEADLINE: the deadline of 'ctx';
+//   - CTX_FIELD_KEY_TIMEOUT: the duration from the operation's start till the deadline;
+//   - CTX_FIELD_KEY_ELAPSED: the duration since the operation's start.
+//
+// The fields, which values are unknown (e.g. 'ctx' has no deadline), are skipped.
+func (c Class) WrapCtx(ctx context.Context, err error, message string, args ...any) *Error {
+	if !isValidClassID(c.id) || err == nil {
+		return nil
+	}
+	return newError(_ERR_STACK_MODE_FULL, c.id, c.namespaceID, err, message, args).
+		addContextFields(ctx, err)
+}
+
+// ---------------------------------------------------------------------------- //
+
+// addContextFields is a part of Class.WrapCtx(). Adds the fields of 'ctx'
+// if 'legacyErr' is context.DeadlineExceeded or context.Canceled.
+func (e *Error) addContextFields(ctx context.Context, legacyErr error) *Error {
+
+	if !e.IsValid() || ctx == nil ||
+		!errors.Is(legacyErr, context.DeadlineExceeded) && !errors.Is(legacyErr, context.Canceled) {
+		return e
+	}
+
+	var (
+		fs                = make([]ekaletter.LetterField, 0, 4)
+		name, start, isOp = OperationFromContext(ctx)
+		deadline, isDl    = ctx.Deadline()
+	)
+
+	if isOp {
+		fs = append(fs, ekaletter.FString(CTX_FIELD_KEY_OPERATION, name))
+	}
+	if isDl {
+		fs = append(fs, ekaletter.FUnixNanoFromStd(CTX_FIELD_KEY_DEADLINE, deadline))
+	}
+	if isOp && isDl {
+		fs = append(fs, ekaletter.FDuration(CTX_FIELD_KEY_TIMEOUT, deadline.Sub(start)))
+	}
+	if isOp {
+		fs = append(fs, ekaletter.FDuration(CTX_FIELD_KEY_ELAPSED, time.Since(start)))
+	}
+
+	return e.addFields(fs)
+}
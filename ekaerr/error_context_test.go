@@ -0,0 +1,66 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithOperation(t *testing.T) {
+
+	_, _, ok := ekaerr.OperationFromContext(context.Background())
+	assert.False(t, ok)
+
+	ctx := ekaerr.WithOperation(context.Background(), "db.GetUser")
+	name, start, ok := ekaerr.OperationFromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, "db.GetUser", name)
+	assert.WithinDuration(t, time.Now(), start, time.Second)
+}
+
+func TestClass_WrapCtx(t *testing.T) {
+
+	ctx, cancel := context.WithTimeout(ekaerr.WithOperation(context.Background(), "db.GetUser"), 10*time.Millisecond)
+	defer cancel()
+	<-ctx.Done()
+
+	err := ekaerr.ExternalError.WrapCtx(ctx, fmt.Errorf("query: %w", ctx.Err()), "Failed", "id", 1)
+	require.NotNil(t, err)
+
+	fs := wrapExtractTestFields(err)
+	assert.Equal(t, "db.GetUser", fs[ekaerr.CTX_FIELD_KEY_OPERATION].SValue)
+	assert.Contains(t, fs, ekaerr.CTX_FIELD_KEY_DEADLINE)
+	assert.Equal(t, int64(1), fs["id"].IValue)
+
+	timeout := time.Duration(fs[ekaerr.CTX_FIELD_KEY_TIMEOUT].IValue)
+	assert.InDelta(t, float64(10*time.Millisecond), float64(timeout), float64(5*time.Millisecond))
+	assert.GreaterOrEqual(t, fs[ekaerr.CTX_FIELD_KEY_ELAPSED].IValue, int64(10*time.Millisecond))
+
+	// Canceled context w/o deadline.
+	ctx, cancel = context.WithCancel(ekaerr.WithOperation(context.Background(), "upload"))
+	cancel()
+
+	fs = wrapExtractTestFields(ekaerr.ExternalError.WrapCtx(ctx, ctx.Err(), "Failed"))
+	assert.Equal(t, "upload", fs[ekaerr.CTX_FIELD_KEY_OPERATION].SValue)
+	assert.Contains(t, fs, ekaerr.CTX_FIELD_KEY_ELAPSED)
+	assert.NotContains(t, fs, ekaerr.CTX_FIELD_KEY_DEADLINE)
+	assert.NotContains(t, fs, ekaerr.CTX_FIELD_KEY_TIMEOUT)
+
+	// Not a context's error.
+	fs = wrapExtractTestFields(ekaerr.ExternalError.WrapCtx(ctx, errors.New("other"), "Failed"))
+	assert.NotContains(t, fs, ekaerr.CTX_FIELD_KEY_OPERATION)
+
+	assert.Nil(t, ekaerr.ExternalError.WrapCtx(ctx, nil, "Failed"))
+}
@@ -0,0 +1,31 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr
+
+// CorrelationID returns Error's correlation ID, that links Error
+// with the log records of the same request (operation), or "" if it's not set.
+//
+// The correlation ID is set either manually using WithCorrelationID()
+// or automatically when Error is logged by ekalog.Logger, that has one
+// (see ekalog.Logger.WithNewCorrelationID()).
+// Returns "" if Error is not valid.
+// Nil safe.
+func (e *Error) CorrelationID() string {
+	if !e.IsValid() {
+		return ""
+	}
+	return e.letter.SystemFields[_ERR_SYS_FIELD_IDX_CORRELATION].SValue
+}
+
+// WithCorrelationID sets Error's correlation ID, overwriting the previous one.
+// It's emitted as "correlation_id" field when Error is logged.
+// Nil safe. Returns this.
+func (e *Error) WithCorrelationID(id string) *Error {
+	if e.IsValid() {
+		e.letter.SystemFields[_ERR_SYS_FIELD_IDX_CORRELATION].SValue = id
+	}
+	return e
+}
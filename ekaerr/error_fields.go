@@ -0,0 +1,186 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr
+
+import (
+	"math"
+	"time"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// UnnamedKeysPolicy is a policy of the unnamed fields' keys generation.
+	// Read more: Error.WithUnnamedKeys().
+	UnnamedKeysPolicy = ekaletter.UnnamedKeysPolicy
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// UNNAMED_KEYS_POLICY_DEFAULT keeps the unnamed fields unnamed.
+	// Their keys are generated by ekalog's encoders for each entry separately:
+	// "unnamed_01", "unnamed_02", etc.
+	UNNAMED_KEYS_POLICY_DEFAULT = ekaletter.UNNAMED_KEYS_POLICY_DEFAULT
+
+	// UNNAMED_KEYS_POLICY_PADDED generates the keys for the unnamed fields
+	// when they're added: "<prefix>01", "<prefix>02", ..., "<prefix>100", etc.
+	UNNAMED_KEYS_POLICY_PADDED = ekaletter.UNNAMED_KEYS_POLICY_PADDED
+
+	// UNNAMED_KEYS_POLICY_PLAIN is the same as UNNAMED_KEYS_POLICY_PADDED,
+	// but the numbers are not padded: "<prefix>1", "<prefix>2", etc.
+	UNNAMED_KEYS_POLICY_PLAIN = ekaletter.UNNAMED_KEYS_POLICY_PLAIN
+
+	// UNNAMED_KEYS_POLICY_REJECT drops the unnamed fields when they're added.
+	// Instead, the field "<prefix>rejected" is added (once), the value of which
+	// is the number of dropped fields, so the mistake is visible.
+	UNNAMED_KEYS_POLICY_REJECT = ekaletter.UNNAMED_KEYS_POLICY_REJECT
+
+	// UNNAMED_KEYS_DEFAULT_PREFIX is a prefix of the generated keys,
+	// that is used if there's no one.
+	UNNAMED_KEYS_DEFAULT_PREFIX = ekaletter.UNNAMED_KEYS_DEFAULT_PREFIX
+)
+
+// FieldString returns the value of Error's field with 'key' and true
+// if there is such field and its value is a non-nil string.
+// If there are many fields with 'key' (e.g. added at the different stack frames),
+// the last added one is used. Aggregated errors (see Append()) are not inspected.
+// Returns "", false if Error is not valid.
+// Nil safe.
+func (e *Error) FieldString(key string) (string, bool) {
+	f := e.lookupField(key)
+	if f == nil || f.Kind.IsNil() || f.BaseType() != ekaletter.KIND_TYPE_STRING {
+		return "", false
+	}
+	return f.SValue, true
+}
+
+// FieldInt64 returns the value of Error's field with 'key' and true
+// if there is such field and its value is a non-nil signed integer,
+// an unsigned integer that fits int64 or time.Duration (as nanoseconds).
+// Read more about duplicates: FieldString().
+// Returns 0, false if Error is not valid.
+// Nil safe.
+func (e *Error) FieldInt64(key string) (int64, bool) {
+
+	f := e.lookupField(key)
+	if f == nil || f.Kind.IsNil() {
+		return 0, false
+	}
+
+	switch baseType := f.BaseType(); {
+	case baseType >= ekaletter.KIND_TYPE_INT && baseType <= ekaletter.KIND_TYPE_INT_64,
+		baseType == ekaletter.KIND_TYPE_DURATION:
+		return f.IValue, true
+
+	case baseType >= ekaletter.KIND_TYPE_UINT && baseType <= ekaletter.KIND_TYPE_UINT_64:
+		if uint64(f.IValue) > math.MaxInt64 {
+			return 0, false
+		}
+		return f.IValue, true
+
+	default:
+		return 0, false
+	}
+}
+
+// FieldAny returns the value of Error's field with 'key' and true
+// if there is such field. The value is of the most natural Go type:
+// bool, int64, uint64, float64, complex128, string, time.Time, time.Duration
+// or the original value for arrays, maps and structs.
+// Nil value is returned as nil (with true).
+// Read more about duplicates: FieldString().
+// Returns nil, false if Error is not valid.
+// Nil safe.
+func (e *Error) FieldAny(key string) (any, bool) {
+	f := e.lookupField(key)
+	if f == nil {
+		return nil, false
+	}
+	return fieldValue(f), true
+}
+
+// RangeFields calls 'cb' for each Error's field in the order they have been added,
+// passing field's key (empty for unnamed fields) and value (read more: FieldAny()).
+// Stops if 'cb' returns false. Aggregated errors (see Append()) are not inspected.
+// Does nothing if Error is not valid or 'cb' is nil.
+// Nil safe.
+//
+// WARNING!
+// You MUST NOT modify Error inside 'cb'.
+func (e *Error) RangeFields(cb func(key string, value any) bool) {
+	if !e.IsValid() || cb == nil {
+		return
+	}
+	fs := e.letter.Fields
+	for i, n := 0, len(fs); i < n; i++ {
+		if !fs[i].Kind.IsInvalid() && !cb(fs[i].Key, fieldValue(&fs[i])) {
+			return
+		}
+	}
+}
+
+// lookupField returns the last added Error's field with 'key'
+// or nil if there's no such field or Error is not valid.
+func (e *Error) lookupField(key string) *ekaletter.LetterField {
+	if !e.IsValid() || key == "" {
+		return nil
+	}
+	fs := e.letter.Fields
+	for i := len(fs) - 1; i >= 0; i-- {
+		if fs[i].Key == key && !fs[i].Kind.IsInvalid() {
+			return &fs[i]
+		}
+	}
+	return nil
+}
+
+// fieldValue returns the value of 'f' of the most natural Go type.
+// Read more: Error.FieldAny().
+func fieldValue(f *ekaletter.LetterField) any {
+
+	if f.Kind.IsNil() {
+		return nil
+	}
+
+	switch baseType := f.BaseType(); {
+	case baseType == ekaletter.KIND_TYPE_BOOL:
+		return f.IValue != 0
+
+	case baseType >= ekaletter.KIND_TYPE_INT && baseType <= ekaletter.KIND_TYPE_INT_64:
+		return f.IValue
+
+	case baseType >= ekaletter.KIND_TYPE_UINT && baseType <= ekaletter.KIND_TYPE_UINTPTR,
+		baseType == ekaletter.KIND_TYPE_ADDR:
+		return uint64(f.IValue)
+
+	case baseType == ekaletter.KIND_TYPE_FLOAT_32:
+		return float64(math.Float32frombits(uint32(f.IValue)))
+
+	case baseType == ekaletter.KIND_TYPE_FLOAT_64:
+		return math.Float64frombits(uint64(f.IValue))
+
+	case baseType == ekaletter.KIND_TYPE_COMPLEX_64:
+		r := math.Float32frombits(uint32(f.IValue >> 32))
+		i := math.Float32frombits(uint32(f.IValue))
+		return complex128(complex(r, i))
+
+	case baseType == ekaletter.KIND_TYPE_STRING:
+		return f.SValue
+
+	case baseType == ekaletter.KIND_TYPE_UNIX:
+		return time.Unix(f.IValue, 0)
+
+	case baseType == ekaletter.KIND_TYPE_UNIX_NANO:
+		return time.Unix(0, f.IValue)
+
+	case baseType == ekaletter.KIND_TYPE_DURATION:
+		return time.Duration(f.IValue)
+
+	default:
+		// KIND_TYPE_COMPLEX_128, arrays, maps, structs.
+		return f.Value
+	}
+}
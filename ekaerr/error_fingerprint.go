@@ -0,0 +1,135 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr
+
+import (
+	"strings"
+	"sync/atomic"
+
+	"github.com/qioalice/ekago/v3/ekatyp/ekahash"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// FingerprintNormalizer is a function that normalizes Error's message
+	// before it's used to compute Error's fingerprint.
+	// It must remove all variable parts of the message (IDs, numbers, etc).
+	FingerprintNormalizer func(message string) string
+)
+
+// FINGERPRINT_DEFAULT_FRAMES is a default number of the top stack frames
+// those are used to compute Error's fingerprint.
+//
+//goland:noinspection GoSnakeCaseUsage
+const FINGERPRINT_DEFAULT_FRAMES = 5
+
+var (
+	// fingerprintFrames is the number of the top stack frames
+	// those are used to compute Error's fingerprint.
+	// Read more: SetFingerprintFrames().
+	fingerprintFrames int32 = FINGERPRINT_DEFAULT_FRAMES
+
+	// fingerprintNormalizer contains FingerprintNormalizer
+	// that is used to compute Error's fingerprint.
+	// Read more: SetFingerprintNormalizer().
+	fingerprintNormalizer atomic.Value
+)
+
+// Fingerprint returns a stable hash of Error, that is the same for errors
+// of the same Class, created at the same place with the same message,
+// up to the normalization (see SetFingerprintNormalizer()).
+// So, the log backends may group identical errors by fingerprint.
+//
+// Fingerprint is computed using:
+//   - Class's full name;
+//   - the function names of the top N stack frames (see SetFingerprintFrames());
+//   - the normalized first (the deepest) Error's message.
+//
+// It's computed once and it's cached. It's also emitted as a system field
+// "error_fingerprint" when Error is logged (computed by the ekalog's encoders
+// then, only if they write it).
+// Returns "" if Error is not valid.
+// Nil safe.
+func (e *Error) Fingerprint() string {
+
+	if !e.IsValid() {
+		return ""
+	}
+
+	return ekaletter.LFingerprint(e.letter)
+}
+
+// SetFingerprintFrames sets the number of the top stack frames, those are used
+// to compute Error's fingerprint. Thread-safe.
+// Non-positive 'n' means FINGERPRINT_DEFAULT_FRAMES.
+func SetFingerprintFrames(n int) {
+	if n <= 0 {
+		n = FINGERPRINT_DEFAULT_FRAMES
+	}
+	atomic.StoreInt32(&fingerprintFrames, int32(n))
+}
+
+// SetFingerprintNormalizer sets FingerprintNormalizer that normalizes
+// Error's message before it's used to compute Error's fingerprint. Thread-safe.
+// Nil 'normalizer' means the default one, that removes all ASCII digits.
+func SetFingerprintNormalizer(normalizer FingerprintNormalizer) {
+	if normalizer == nil {
+		normalizer = fingerprintNormalizerDefault
+	}
+	fingerprintNormalizer.Store(normalizer)
+}
+
+// fingerprintOf computes the fingerprint of Error's Letter 'l'.
+// Read more: Error.Fingerprint().
+func fingerprintOf(l *ekaletter.Letter) string {
+
+	// Error with lazy stacktrace may be not resolved yet.
+	ekaletter.LResolveStackTrace(l)
+
+	var className string
+	for i, n := 0, len(l.SystemFields); i < n; i++ {
+		if l.SystemFields[i].BaseType() == ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME {
+			className = l.SystemFields[i].SValue
+			break
+		}
+	}
+
+	// Zero byte is a separator between the parts.
+	h := ekahash.FNV1aUpdateString(ekahash.FNV1A_OFFSET, className)
+	h = ekahash.FNV1aUpdate(h, []byte{0})
+
+	trace := l.StackTrace
+	if n := int(atomic.LoadInt32(&fingerprintFrames)); len(trace) > n {
+		trace = trace[:n]
+	}
+
+	for i := range trace {
+		h = ekahash.FNV1aUpdateString(h, trace[i].Function)
+		h = ekahash.FNV1aUpdate(h, []byte{0})
+	}
+
+	if len(l.Messages) > 0 {
+		normalizer, _ := fingerprintNormalizer.Load().(FingerprintNormalizer)
+		if normalizer == nil {
+			normalizer = fingerprintNormalizerDefault
+		}
+		h = ekahash.FNV1aUpdateString(h, normalizer(l.Messages[0].Body))
+	}
+
+	var buf [16]byte
+	return string(ekahash.AppendHex(buf[:0], h))
+}
+
+// fingerprintNormalizerDefault is the default FingerprintNormalizer.
+// It removes all ASCII digits from 'message'.
+func fingerprintNormalizerDefault(message string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return -1
+		}
+		return r
+	}, message)
+}
@@ -0,0 +1,48 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fingerprintFoo(id int) *ekaerr.Error {
+	return ekaerr.IllegalArgument.New("user " + string(rune('0'+id)) + " not found")
+}
+
+func TestError_Fingerprint(t *testing.T) {
+
+	err1, err2 := fingerprintFoo(1), fingerprintFoo(2)
+	assert.Len(t, err1.Fingerprint(), 16)
+	assert.Equal(t, err1.Fingerprint(), err2.Fingerprint())
+	assert.NotEqual(t, err1.ID(), err2.ID())
+
+	err3 := ekaerr.IllegalState.New("user 1 not found")
+	assert.NotEqual(t, err1.Fingerprint(), err3.Fingerprint())
+
+	lazy1 := ekaerr.IllegalArgument.NewLazy("lazy 1")
+	lazy2 := ekaerr.IllegalArgument.NewLazy("lazy 2")
+	assert.Len(t, lazy1.Fingerprint(), 16)
+	assert.Equal(t, lazy1.Fingerprint(), lazy2.Fingerprint())
+
+	var nilErr *ekaerr.Error
+	assert.Equal(t, "", nilErr.Fingerprint())
+}
+
+func TestSetFingerprintNormalizer(t *testing.T) {
+
+	defer ekaerr.SetFingerprintNormalizer(nil)
+	ekaerr.SetFingerprintNormalizer(strings.ToLower)
+
+	err1 := fingerprintFoo(1)
+	err2 := fingerprintFoo(2)
+	assert.NotEqual(t, err1.Fingerprint(), err2.Fingerprint())
+}
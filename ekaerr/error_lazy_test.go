@@ -0,0 +1,65 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr_test
+
+import (
+	"testing"
+	"unsafe"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func lazyFoo() *ekaerr.Error {
+	return lazyFoo1().AddMessage("lazyFoo bad").WithInt("lazy_foo_arg", 1).Throw()
+}
+
+func lazyFoo1() *ekaerr.Error {
+	return ekaerr.IllegalState.NewLazy("what??").Throw()
+}
+
+func TestClass_NewLazy(t *testing.T) {
+
+	err := lazyFoo()
+	require.True(t, err.IsNotNil())
+	require.True(t, ekaletter.LIsLazyStackTrace(ekaletter.BridgeErrorGetLetter(unsafe.Pointer(err))))
+
+	l := ekaunsafe.ErrorGetLetter(err)
+	require.NotNil(t, l)
+	require.GreaterOrEqual(t, len(l.StackTrace), 3)
+
+	assert.Contains(t, l.StackTrace[0].Function, "lazyFoo1")
+	assert.Contains(t, l.StackTrace[1].Function, "lazyFoo")
+	assert.Contains(t, l.StackTrace[2].Function, "TestClass_NewLazy")
+
+	require.Len(t, l.Messages, 2)
+	assert.Equal(t, "what??", l.Messages[0].Body)
+	assert.Equal(t, int16(0), l.Messages[0].StackFrameIdx)
+	assert.Equal(t, "lazyFoo bad", l.Messages[1].Body)
+	assert.Equal(t, int16(1), l.Messages[1].StackFrameIdx)
+
+	require.Len(t, l.Fields, 1)
+	assert.Equal(t, int16(1), l.Fields[0].StackFrameIdx)
+}
+
+func TestSetLazyStackTraceThrowDepth(t *testing.T) {
+
+	ekaerr.SetLazyStackTraceThrowDepth(1)
+	defer ekaerr.SetLazyStackTraceThrowDepth(0)
+
+	// Stacktrace must be resolved at the first Throw() inside lazyFoo1().
+	err := lazyFoo()
+	l := ekaletter.BridgeErrorGetLetter(unsafe.Pointer(err))
+	require.NotNil(t, l)
+	require.False(t, ekaletter.LIsLazyStackTrace(l))
+	require.GreaterOrEqual(t, len(l.StackTrace), 2)
+	assert.Contains(t, l.StackTrace[0].Function, "lazyFoo1")
+	assert.Contains(t, l.StackTrace[1].Function, "lazyFoo")
+}
@@ -0,0 +1,78 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr
+
+import (
+	"fmt"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type (
+	// LeakedError is a report about Error, that has been neither logged
+	// nor released (see ReleaseError()) for too long.
+	// Read more: SetLeakDetector().
+	LeakedError struct {
+		ID        string    // Error's ID (see Error.ID())
+		ClassName string    // full name of Error's Class (see Class.FullName())
+		Caller    string    // "<func> <file>:<line>" Error has been created at
+		CreatedAt time.Time // time Error has been created at
+	}
+)
+
+// SetLeakDetector enables the detector of Error objects, that are neither logged
+// nor released (see ReleaseError()) within 'threshold' since they're created.
+// Each such Error is passed to 'report' once (it's printed to os.Stderr
+// if 'report' is nil). The detector checks the errors each 'threshold' / 2.
+// Non-positive 'threshold' disables the detector.
+//
+// It's a debug tool for finding the code paths, that lose errors
+// (e.g. early returns or panics), keeping them from being returned to the pool.
+// The detector keeps all errors from being collected by GC until they're reported,
+// so it's compiled only with "ekadebug" build tag:
+//
+//	go test -tags ekadebug ./...
+//
+// W/o it, SetLeakDetector() does nothing and returns false.
+// Use EPS() to check the pool's usage in the production builds.
+// Thread-safe.
+func SetLeakDetector(threshold time.Duration, report func(leaked LeakedError)) (enabled bool) {
+	if report == nil {
+		report = leakReportToStderr
+	}
+	return setLeakDetector(threshold, report)
+}
+
+// leakReportToStderr prints 'leaked' to os.Stderr.
+// It's a default report function of the leak detector.
+func leakReportToStderr(leaked LeakedError) {
+	_, _ = fmt.Fprintf(os.Stderr,
+		"ekaerr: leaked Error %s of %q created at %s %s ago\n",
+		leaked.ID, leaked.ClassName, leaked.Caller, time.Since(leaked.CreatedAt))
+}
+
+// leakCaller returns "<func> <file>:<line>" of the first caller
+// outside of ekaerr package or an empty string.
+func leakCaller() string {
+
+	const pkgPrefix = "github.com/qioalice/ekago/v3/ekaerr."
+
+	var pcs [16]uintptr
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs[:])])
+
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, pkgPrefix) {
+			return frame.Function + " " + frame.File + ":" + strconv.Itoa(frame.Line)
+		}
+		if !more {
+			return ""
+		}
+	}
+}
@@ -0,0 +1,133 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build ekadebug
+
+package ekaerr
+
+import (
+	"sync"
+	"time"
+)
+
+type (
+	// leakDetector tracks created Error objects until they're released
+	// and reports the ones, that are tracked for too long.
+	// Read more: SetLeakDetector().
+	leakDetector struct {
+		mu        sync.Mutex
+		threshold time.Duration
+		report    func(leaked LeakedError)
+		tracked   map[*Error]LeakedError
+		stop      chan struct{}
+	}
+)
+
+var (
+	// leaks is the current leak detector or nil if it's disabled.
+	leaks *leakDetector
+
+	// leaksMu protects leaks.
+	leaksMu sync.RWMutex
+)
+
+// setLeakDetector is SetLeakDetector() implementation.
+func setLeakDetector(threshold time.Duration, report func(leaked LeakedError)) bool {
+
+	leaksMu.Lock()
+	defer leaksMu.Unlock()
+
+	if leaks != nil {
+		close(leaks.stop)
+		leaks = nil
+	}
+
+	if threshold <= 0 {
+		return false
+	}
+
+	leaks = &leakDetector{
+		threshold: threshold,
+		report:    report,
+		tracked:   make(map[*Error]LeakedError),
+		stop:      make(chan struct{}),
+	}
+
+	go leaks.run()
+	return true
+}
+
+// leakTrack starts tracking of 'e' if the leak detector is enabled.
+func leakTrack(e *Error) {
+
+	leaksMu.RLock()
+	ld := leaks
+	leaksMu.RUnlock()
+
+	if ld == nil || !e.IsValid() {
+		return
+	}
+
+	leaked := LeakedError{
+		ID:        e.ID(),
+		ClassName: e.Class().FullName(),
+		Caller:    leakCaller(),
+		CreatedAt: time.Now(),
+	}
+
+	ld.mu.Lock()
+	ld.tracked[e] = leaked
+	ld.mu.Unlock()
+}
+
+// leakUntrack stops tracking of 'e' (if it's tracked).
+func leakUntrack(e *Error) {
+
+	leaksMu.RLock()
+	ld := leaks
+	leaksMu.RUnlock()
+
+	if ld != nil {
+		ld.mu.Lock()
+		delete(ld.tracked, e)
+		ld.mu.Unlock()
+	}
+}
+
+// run checks tracked errors each threshold / 2 until the detector is stopped.
+func (ld *leakDetector) run() {
+
+	ticker := time.NewTicker(ld.threshold / 2)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ld.stop:
+			return
+		case now := <-ticker.C:
+			ld.check(now)
+		}
+	}
+}
+
+// check reports and stops tracking of the errors,
+// that are tracked longer than threshold since 'now'.
+func (ld *leakDetector) check(now time.Time) {
+
+	var reports []LeakedError
+
+	ld.mu.Lock()
+	for e, leaked := range ld.tracked {
+		if now.Sub(leaked.CreatedAt) >= ld.threshold {
+			reports = append(reports, leaked)
+			delete(ld.tracked, e)
+		}
+	}
+	ld.mu.Unlock()
+
+	for _, leaked := range reports {
+		ld.report(leaked)
+	}
+}
@@ -0,0 +1,24 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build !ekadebug
+
+package ekaerr
+
+import (
+	"time"
+)
+
+// setLeakDetector does nothing w/o "ekadebug" build tag.
+// Read more: SetLeakDetector().
+func setLeakDetector(_ time.Duration, _ func(leaked LeakedError)) bool {
+	return false
+}
+
+// leakTrack does nothing w/o "ekadebug" build tag.
+func leakTrack(_ *Error) {}
+
+// leakUntrack does nothing w/o "ekadebug" build tag.
+func leakUntrack(_ *Error) {}
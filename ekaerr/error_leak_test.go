@@ -0,0 +1,52 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build ekadebug
+
+package ekaerr_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func leakFoo() *ekaerr.Error {
+	return ekaerr.IllegalState.New("leaked")
+}
+
+func TestSetLeakDetector(t *testing.T) {
+
+	reports := make(chan ekaerr.LeakedError, 4)
+	require.True(t, ekaerr.SetLeakDetector(20*time.Millisecond, func(leaked ekaerr.LeakedError) {
+		reports <- leaked
+	}))
+	defer ekaerr.SetLeakDetector(0, nil)
+
+	released := leakFoo()
+	leaked := leakFoo()
+	ekaerr.ReleaseError(released)
+
+	select {
+	case report := <-reports:
+		assert.Equal(t, leaked.ID(), report.ID)
+		assert.Equal(t, ekaerr.IllegalState.FullName(), report.ClassName)
+		assert.True(t, strings.HasPrefix(report.Caller, "github.com/qioalice/ekago/v3/ekaerr_test.leakFoo "),
+			report.Caller)
+	case <-time.After(time.Second):
+		t.Fatal("leaked Error is not reported")
+	}
+
+	select {
+	case report := <-reports:
+		t.Fatalf("unexpected report: %+v", report)
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -32,24 +32,48 @@ type (
 
 		// ReleaseCalls is how much Error objects were returned to its pool
 		// and prepared for being reused.
+		//
+		// It contains FinalizerCalls.
 		ReleaseCalls uint64
+
+		// FinalizerCalls is how much Error objects were returned to its pool
+		// by the garbage collector, because they have been neither logged
+		// nor released using ReleaseError() (ReleaseErrors()).
+		// It's a good sign of leaks, read more: SetLeakDetector().
+		FinalizerCalls uint64
 	}
 )
 
-// EPS returns an EntryPoolStat object that contains an info about utilizing
-// Logger's Entry pool. Using that info you can figure out how often
-// a new Logger's Entry objects are created and how often the oldest ones
+// EPS returns an ErrorPoolStat object that contains an info about utilizing
+// Error's pool. Using that info you can figure out how often
+// a new Error objects are created and how often the oldest ones
 // are reused.
 //
 // In 99% cases you don't need to know that stat,
 // and you should not to worry about that.
-func EPS() (eps ErrorPoolStat) {
-	eps.AllocCalls = atomic.LoadUint64(&eps.AllocCalls)
-	eps.NewCalls = atomic.LoadUint64(&eps.NewCalls)
-	eps.ReleaseCalls = atomic.LoadUint64(&eps.ReleaseCalls)
+func EPS() (stat ErrorPoolStat) {
+	stat.AllocCalls = atomic.LoadUint64(&eps.AllocCalls)
+	stat.NewCalls = atomic.LoadUint64(&eps.NewCalls)
+	stat.ReleaseCalls = atomic.LoadUint64(&eps.ReleaseCalls)
+	stat.FinalizerCalls = atomic.LoadUint64(&eps.FinalizerCalls)
 	return
 }
 
+// ReusedCalls returns how much Error objects were popped from its pool
+// instead of being allocated.
+func (stat ErrorPoolStat) ReusedCalls() uint64 {
+	if stat.NewCalls < stat.AllocCalls {
+		return 0
+	}
+	return stat.NewCalls - stat.AllocCalls
+}
+
+// InUse returns how much Error objects are not returned to its pool yet.
+// The aggregated errors (see Error.Append()) are counted too.
+func (stat ErrorPoolStat) InUse() int64 {
+	return int64(stat.NewCalls) - int64(stat.ReleaseCalls)
+}
+
 var (
 	// errorPool is the pool of Error (with allocated ekaletter.Letter) objects
 	// for being reused.
@@ -74,7 +98,7 @@ func allocError() any {
 
 	// SystemFields is used for saving Error's meta data.
 
-	e.letter.SystemFields = make([]ekaletter.LetterField, 3)
+	e.letter.SystemFields = make([]ekaletter.LetterField, 5)
 
 	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_CLASS_ID].Key = "error_class_id"
 	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_CLASS_ID].Kind |=
@@ -88,6 +112,14 @@ func allocError() any {
 	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_ERROR_ID].Kind |=
 		ekaletter.KIND_FLAG_SYSTEM | ekaletter.KIND_SYS_TYPE_EKAERR_UUID
 
+	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_FINGERPRINT].Key = "error_fingerprint"
+	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_FINGERPRINT].Kind |=
+		ekaletter.KIND_FLAG_SYSTEM | ekaletter.KIND_SYS_TYPE_EKAERR_FINGERPRINT
+
+	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_CORRELATION].Key = "correlation_id"
+	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_CORRELATION].Kind |=
+		ekaletter.KIND_FLAG_SYSTEM | ekaletter.KIND_SYS_TYPE_CORRELATION_ID
+
 	atomic.AddUint64(&eps.AllocCalls, 1)
 	return e
 }
@@ -102,6 +134,7 @@ func acquireError() *Error {
 // and that Error could be obtained later using acquireError().
 func releaseError(e *Error) {
 	atomic.AddUint64(&eps.ReleaseCalls, 1)
+	leakUntrack(e)
 	errorPool.Put(e.cleanup())
 }
 
@@ -110,5 +143,6 @@ func releaseError(e *Error) {
 // without automatic returning to its pool by any ekalog.Logger's finisher.
 func releaseErrorForFinalizer(e *Error) {
 	e.needSetFinalizer = true
+	atomic.AddUint64(&eps.FinalizerCalls, 1)
 	releaseError(e)
 }
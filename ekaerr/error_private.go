@@ -21,9 +21,33 @@ const (
 	// en embedded Letter's SystemFields array.
 	// See https://github.com/qioalice/ekago/internal/letter/letter.go for more details.
 
-	_ERR_SYS_FIELD_IDX_CLASS_ID   = 0
-	_ERR_SYS_FIELD_IDX_CLASS_NAME = 1
-	_ERR_SYS_FIELD_IDX_ERROR_ID   = 2
+	_ERR_SYS_FIELD_IDX_CLASS_ID    = 0
+	_ERR_SYS_FIELD_IDX_CLASS_NAME  = 1
+	_ERR_SYS_FIELD_IDX_ERROR_ID    = 2
+	_ERR_SYS_FIELD_IDX_FINGERPRINT = 3
+	_ERR_SYS_FIELD_IDX_CORRELATION = 4
+)
+
+// noinspection GoSnakeCaseUsage
+const (
+	// These constants are the modes of Error's stacktrace generating.
+	// See Error's doc for more details.
+
+	_ERR_STACK_MODE_FULL        _ErrorStackMode = iota
+	_ERR_STACK_MODE_LIGHTWEIGHT                 // w/o stacktrace
+	_ERR_STACK_MODE_LAZY                        // frame points only, resolved later
+)
+
+type (
+	// _ErrorStackMode is a mode of Error's stacktrace generating.
+	_ErrorStackMode uint8
+)
+
+var (
+	// lazyStackTraceThrowDepth is the number of Throw() calls after which
+	// the lazy stacktrace is resolved. 0 means never (only at the logging).
+	// Read more: SetLazyStackTraceThrowDepth().
+	lazyStackTraceThrowDepth int32
 )
 
 // prepare prepares current Error for being used assuming that Error has been
@@ -50,6 +74,13 @@ func (e *Error) cleanup() *Error {
 
 	e.letter.StackTrace = nil
 
+	for i, n := 0, len(e.children); i < n; i++ {
+		releaseError(e.children[i])
+		e.children[i] = nil
+	}
+	e.children = e.children[:0]
+	e.parent = nil
+
 	ekaletter.LReset(e.letter)
 	return e
 }
@@ -58,7 +89,10 @@ func (e *Error) cleanup() *Error {
 // to current Error, if field is addable.
 func (e *Error) addField(f ekaletter.LetterField) *Error {
 	if e.IsValid() {
+		n := len(e.letter.Fields)
 		ekaletter.LAddFieldWithCheck(e.letter, f)
+		ekaletter.LApplyUnnamedKeys(e.letter, n)
+		ekaletter.LDedupFields(e.letter, n)
 	}
 	return e
 }
@@ -66,9 +100,12 @@ func (e *Error) addField(f ekaletter.LetterField) *Error {
 // addFields is the same as addField() but works with an array of ekaletter.LetterField.
 func (e *Error) addFields(fs []ekaletter.LetterField) *Error {
 	if e.IsValid() {
-		for i, n := 0, len(fs); i < n; i++ {
+		n := len(e.letter.Fields)
+		for i, m := 0, len(fs); i < m; i++ {
 			ekaletter.LAddFieldWithCheck(e.letter, fs[i])
 		}
+		ekaletter.LApplyUnnamedKeys(e.letter, n)
+		ekaletter.LDedupFields(e.letter, n)
 	}
 	return e
 }
@@ -78,7 +115,10 @@ func (e *Error) addFields(fs []ekaletter.LetterField) *Error {
 // Then adds generated ekaletter.LetterField to the Error only if those fields are addable.
 func (e *Error) addFieldsParse(fs []any, onlyFields bool) *Error {
 	if e.IsValid() && len(fs) > 0 {
+		n := len(e.letter.Fields)
 		ekaletter.LParseTo(e.letter, fs, onlyFields)
+		ekaletter.LApplyUnnamedKeys(e.letter, n)
+		ekaletter.LDedupFields(e.letter, n)
 	}
 	return e
 }
@@ -117,6 +157,54 @@ func (e *Error) is(cls []Class, deep bool) bool {
 	return false
 }
 
+// canAppend reports whether 'child' may be aggregated by the current Error.
+// Read more: Error.Append().
+func (e *Error) canAppend(child *Error) bool {
+
+	if !child.IsValid() || child.parent != nil {
+		return false
+	}
+
+	for ancestor := e; ancestor != nil; ancestor = ancestor.parent {
+		if ancestor == child {
+			return false
+		}
+	}
+
+	return true
+}
+
+// errIsIn reports whether 'e' is one of 'errs'.
+func errIsIn(e *Error, errs []*Error) bool {
+	for i, n := 0, len(errs); i < n; i++ {
+		if errs[i] == e {
+			return true
+		}
+	}
+	return false
+}
+
+// firstOf returns the first aggregated Error (depth-first) that belongs
+// to the 'cls' Class or to any of its subclasses.
+func (e *Error) firstOf(cls Class) *Error {
+
+	if !e.IsValid() || !isValidClassID(cls.id) {
+		return nil
+	}
+
+	classes := []Class{cls}
+	for i, n := 0, len(e.children); i < n; i++ {
+		if e.children[i].is(classes, true) {
+			return e.children[i]
+		}
+		if child := e.children[i].firstOf(cls); child != nil {
+			return child
+		}
+	}
+
+	return nil
+}
+
 // of reports whether Error belongs to at least one of passed nss Namespace.
 func (e *Error) of(nss []Namespace) bool {
 
@@ -136,12 +224,15 @@ func (e *Error) of(nss []Namespace) bool {
 // init is a part of newError() func (Error's constructor).
 // Generates the stacktrace and an unique error's ID (ULID) saving it along with
 // classID and namespaceID to the Error and then returns it.
-func (e *Error) init(classID ClassID, namespaceID NamespaceID, lightweight bool) *Error {
+func (e *Error) init(classID ClassID, namespaceID NamespaceID, stackMode _ErrorStackMode) *Error {
 
-	skip := 3 // init(), newError(), [Class.New(), Class.Wrap(), Class.LightNew(), Class.LightWrap()]
+	skip := 3 // init(), newError(), [Class.New(), Class.Wrap(), Class.LightNew(), ...]
 
-	if !lightweight {
+	switch stackMode {
+	case _ERR_STACK_MODE_FULL:
 		e.letter.StackTrace = ekasys.GetStackTrace(skip, -1).ExcludeInternal()
+	case _ERR_STACK_MODE_LAZY:
+		ekaletter.LSetLazyStackTrace(e.letter, ekasys.GetStackFramePoints(skip, -1))
 	}
 
 	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_CLASS_ID].IValue = int64(classID)
@@ -149,6 +240,8 @@ func (e *Error) init(classID ClassID, namespaceID NamespaceID, lightweight bool)
 		classByID(classID, true).fullName
 	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_ERROR_ID].SValue =
 		ekatyp.ULID_New_OrNil().String()
+	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_FINGERPRINT].SValue = ""
+	e.letter.SystemFields[_ERR_SYS_FIELD_IDX_CORRELATION].SValue = ""
 
 	e.classID = classID
 	e.namespaceID = namespaceID
@@ -254,14 +347,18 @@ func (e *Error) construct(baseMessage string, legacyErr error) *Error {
 //  5. Mark first stack frame if generated message (p.3) is not empty.
 func newError(
 
-	lightweight bool,
+	stackMode _ErrorStackMode,
 	classID ClassID, namespaceID NamespaceID,
 	legacyErr error, message string, args []any,
 
 ) *Error {
 
-	return acquireError().
-		init(classID, namespaceID, lightweight).
+	e := acquireError().
+		init(classID, namespaceID, stackMode).
 		construct(message, legacyErr).
+		addWrapExtractedFields(legacyErr).
 		addFieldsParse(args, false)
+
+	leakTrack(e)
+	return e
 }
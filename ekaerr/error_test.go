@@ -6,14 +6,19 @@
 package ekaerr_test
 
 import (
+	"bytes"
 	"fmt"
+	"math"
 	"runtime"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/qioalice/ekago/v3/ekaerr"
 	"github.com/qioalice/ekago/v3/ekalog"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type T struct{}
@@ -93,3 +98,180 @@ func TestError_IsAnyDeep(t *testing.T) {
 	assert.True(t, err.IsAnyDeep(ekaerr.AlreadyExist))
 	assert.False(t, err.IsAnyDeep(ekaerr.NotFound))
 }
+
+func TestAggregate(t *testing.T) {
+
+	assert.Nil(t, ekaerr.Aggregate())
+	assert.Nil(t, ekaerr.Aggregate(nil, nil))
+
+	errNotImplemented := ekaerr.NotImplemented.New("not implemented")
+	errNotFound := ekaerr.NotFound.LightNew("not found", "id", 42)
+
+	err := ekaerr.Aggregate(errNotImplemented, nil, errNotFound)
+
+	assert.True(t, err.Is(ekaerr.Aggregated))
+	assert.Equal(t, 2, err.Len())
+	assert.Equal(t, []*ekaerr.Error{errNotImplemented, errNotFound}, err.Errors())
+
+	assert.Same(t, errNotFound, err.FirstOf(ekaerr.NotFound))
+	assert.Same(t, errNotImplemented, err.FirstOf(ekaerr.UnsupportedOperation))
+	assert.Nil(t, err.FirstOf(ekaerr.IllegalState))
+
+	errOuter := ekaerr.IllegalState.New("outer").Append(err)
+	assert.Equal(t, 1, errOuter.Len())
+	assert.Same(t, errNotFound, errOuter.FirstOf(ekaerr.NotFound))
+
+	// Already aggregated errors, the Error itself and its ancestors are skipped.
+	errOuter.Append(errNotFound, errOuter)
+	err.Append(errOuter, errNotImplemented)
+	assert.Equal(t, 1, errOuter.Len())
+	assert.Equal(t, 2, err.Len())
+	assert.Nil(t, errOuter.FirstOf(ekaerr.IllegalState))
+
+	errDuplicate := ekaerr.NotFound.New("duplicate")
+	errAggregated := ekaerr.Aggregate(errDuplicate, errDuplicate, errNotFound)
+	assert.Equal(t, 1, errAggregated.Len())
+	assert.Equal(t, "1 errors occurred", errAggregated.Freeze().Error())
+	ekaerr.ReleaseError(errAggregated)
+
+	b := bytes.NewBuffer(nil)
+	ekalog.WithIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_JSONEncoder)).
+		WriteTo(b)).
+		Errore("", errOuter)
+
+	out := b.String()
+	assert.Equal(t, 2, strings.Count(out, `"errors":[`))
+	assert.Contains(t, out, `"2 errors occurred"`)
+	assert.Contains(t, out, `"not implemented"`)
+	assert.Contains(t, out, `"not found"`)
+}
+
+func TestError_CorrelationID(t *testing.T) {
+
+	err := ekaerr.IllegalState.New("boom")
+	assert.Equal(t, "", err.CorrelationID())
+	assert.Equal(t, "corr-1", err.WithCorrelationID("corr-1").CorrelationID())
+
+	var nilErr *ekaerr.Error
+	assert.Equal(t, "", nilErr.WithCorrelationID("corr-1").CorrelationID())
+}
+
+func freezeFoo() *ekaerr.Error {
+	return ekaerr.IllegalState.New("inner").WithInt("code", 42)
+}
+
+func TestError_Freeze(t *testing.T) {
+
+	child := ekaerr.NotFound.New("child not found")
+	err := freezeFoo().
+		Throw().
+		AddMessage("outer").
+		WithCorrelationID("corr-1").
+		Append(child)
+
+	ev := err.Freeze()
+	require.NotNil(t, ev)
+
+	assert.True(t, ev.IsClass(ekaerr.IllegalState))
+	assert.False(t, ev.IsClass(ekaerr.NotFound))
+	assert.Equal(t, err.ID(), ev.ID())
+	assert.Equal(t, err.Fingerprint(), ev.Fingerprint())
+	assert.Equal(t, "corr-1", ev.CorrelationID())
+	assert.Equal(t, []string{"inner", "outer"}, ev.Messages())
+	assert.Equal(t, "outer: inner", ev.Error())
+	require.Len(t, ev.Fields(), 1)
+	assert.Equal(t, "code", ev.Fields()[0].Key)
+	require.NotEmpty(t, ev.StackTrace())
+	assert.Contains(t, ev.StackTrace()[0], "freezeFoo")
+	require.Len(t, ev.Errors(), 1)
+	assert.Equal(t, "child not found", ev.Errors()[0].Error())
+
+	ekaerr.ReleaseError(err)
+	assert.Equal(t, "outer: inner", ev.Error())
+	assert.True(t, ev.IsClass(ekaerr.IllegalState))
+
+	var nilErr *ekaerr.Error
+	assert.Nil(t, nilErr.Freeze())
+	assert.Equal(t, "", nilErr.Freeze().Error())
+}
+
+func fieldsFoo() *ekaerr.Error {
+	return ekaerr.NotFound.New("entity not found").
+		WithInt64("entity_id", 42).
+		WithString("entity", "user")
+}
+
+func TestError_Fields(t *testing.T) {
+
+	err := fieldsFoo().
+		Throw().
+		WithDuration("retry_after", 3*time.Second).
+		WithUint64("big", math.MaxUint64).
+		WithString("entity", "account")
+
+	s, ok := err.FieldString("entity")
+	assert.True(t, ok)
+	assert.Equal(t, "account", s)
+
+	_, ok = err.FieldString("entity_id")
+	assert.False(t, ok)
+
+	i, ok := err.FieldInt64("entity_id")
+	assert.True(t, ok)
+	assert.Equal(t, int64(42), i)
+
+	i, ok = err.FieldInt64("retry_after")
+	assert.True(t, ok)
+	assert.Equal(t, int64(3*time.Second), i)
+
+	_, ok = err.FieldInt64("big")
+	assert.False(t, ok)
+
+	v, ok := err.FieldAny("retry_after")
+	assert.True(t, ok)
+	assert.Equal(t, 3*time.Second, v)
+
+	v, ok = err.FieldAny("big")
+	assert.True(t, ok)
+	assert.Equal(t, uint64(math.MaxUint64), v)
+
+	_, ok = err.FieldAny("missing")
+	assert.False(t, ok)
+
+	var keys []string
+	err.RangeFields(func(key string, value any) bool {
+		keys = append(keys, key)
+		return key != "retry_after"
+	})
+	assert.Equal(t, []string{"entity_id", "entity", "retry_after"}, keys)
+
+	ekaerr.ReleaseError(err)
+
+	var nilErr *ekaerr.Error
+	_, ok = nilErr.FieldAny("entity")
+	assert.False(t, ok)
+	nilErr.RangeFields(func(string, any) bool { panic("must not be called") })
+}
+
+func TestReleaseErrors(t *testing.T) {
+
+	before := ekaerr.EPS()
+
+	errs := []*ekaerr.Error{
+		ekaerr.IllegalState.New("first"),
+		nil,
+		ekaerr.IllegalArgument.New("second").Append(ekaerr.NotFound.New("child")),
+	}
+
+	afterNew := ekaerr.EPS()
+	assert.EqualValues(t, 3, afterNew.NewCalls-before.NewCalls)
+	assert.EqualValues(t, afterNew.NewCalls-afterNew.AllocCalls, afterNew.ReusedCalls())
+
+	ekaerr.ReleaseErrors(errs)
+	assert.Equal(t, []*ekaerr.Error{nil, nil, nil}, errs)
+
+	after := ekaerr.EPS()
+	assert.EqualValues(t, 3, after.ReleaseCalls-afterNew.ReleaseCalls)
+	assert.Equal(t, before.InUse(), after.InUse())
+}
@@ -0,0 +1,225 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr
+
+import (
+	"strings"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// ErrorView is a slim immutable snapshot of Error, that is created by
+	// Error.Freeze(). Unlike Error, it's not linked to the Error's pool,
+	// so it remains valid after Error is logged (and released)
+	// and may be stored in caches or returned to the callers.
+	//
+	// ErrorView contains Error's Class, ID, fingerprint, correlation ID,
+	// messages, fields and the stacktrace's summary (w/o stack frames' PCs).
+	// It also implements Golang error interface.
+	//
+	// ErrorView is safe for concurrent use (it's never modified).
+	// Nil ErrorView is valid, all its methods return zero values.
+	ErrorView struct {
+		classID       ClassID
+		namespaceID   NamespaceID
+		id            string
+		fingerprint   string
+		correlationID string
+		messages      []string
+		fields        []ekaletter.LetterField
+		stackTrace    []string
+		children      []*ErrorView
+	}
+)
+
+// Freeze returns an ErrorView, that is the immutable snapshot of the current Error.
+// Error is not changed and still must be logged or released as usual,
+// but returned ErrorView remains valid after that.
+//
+// Lazy stacktrace (if any) is resolved and the fingerprint is computed.
+// Aggregated errors (see Append()) are frozen too.
+// Returns nil if Error is not valid.
+// Nil safe.
+func (e *Error) Freeze() *ErrorView {
+
+	if !e.IsValid() {
+		return nil
+	}
+
+	ekaletter.LResolveStackTrace(e.letter)
+
+	ev := &ErrorView{
+		classID:       e.classID,
+		namespaceID:   e.namespaceID,
+		id:            e.ID(),
+		fingerprint:   e.Fingerprint(),
+		correlationID: e.CorrelationID(),
+	}
+
+	for i, n := 0, len(e.letter.Messages); i < n; i++ {
+		if body := e.letter.Messages[i].Body; body != "" {
+			ev.messages = append(ev.messages, body)
+		}
+	}
+
+	if n := len(e.letter.Fields); n > 0 {
+		ev.fields = make([]ekaletter.LetterField, n)
+		copy(ev.fields, e.letter.Fields)
+	}
+
+	if n := len(e.letter.StackTrace); n > 0 {
+		ev.stackTrace = make([]string, n)
+		for i := 0; i < n; i++ {
+			frame := &e.letter.StackTrace[i]
+			summary := frame.DoFormat()
+			if frame.FormatFullPathOffset > 0 {
+				summary = summary[:frame.FormatFullPathOffset]
+			}
+			ev.stackTrace[i] = strings.TrimSpace(summary)
+		}
+	}
+
+	if n := len(e.children); n > 0 {
+		ev.children = make([]*ErrorView, n)
+		for i := 0; i < n; i++ {
+			ev.children[i] = e.children[i].Freeze()
+		}
+	}
+
+	return ev
+}
+
+// Error returns the ErrorView's messages from the last to the first one,
+// separated by ": " (like wrapped Golang errors do)
+// or Class's name if there is no message.
+// Nil safe.
+func (ev *ErrorView) Error() string {
+
+	if ev == nil {
+		return ""
+	}
+
+	if len(ev.messages) == 0 {
+		return ev.Class().FullName()
+	}
+
+	var sb strings.Builder
+	for i := len(ev.messages) - 1; i >= 0; i-- {
+		sb.WriteString(ev.messages[i])
+		if i > 0 {
+			sb.WriteString(": ")
+		}
+	}
+
+	return sb.String()
+}
+
+// Class returns the Class of the frozen Error
+// or invalid Class if ErrorView is nil.
+// Nil safe.
+func (ev *ErrorView) Class() Class {
+	if ev == nil {
+		return invalidClass
+	}
+	return classByID(ev.classID, true)
+}
+
+// IsClass reports whether the frozen Error has been instantiated by the provided Class.
+// Unlike Error.Is() it's named differently, because ErrorView is Golang error,
+// and Is(error) bool has a special meaning for errors.Is().
+// Nil safe.
+func (ev *ErrorView) IsClass(cls Class) bool {
+	return ev != nil && isValidClassID(cls.id) && ev.classID == cls.id
+}
+
+// Of reports whether the frozen Error has been instantiated by some Class
+// that belongs to the provided Namespace.
+// Nil safe.
+func (ev *ErrorView) Of(ns Namespace) bool {
+	return ev != nil && isValidNamespaceID(ns.id) && ev.namespaceID == ns.id
+}
+
+// ID returns the ID of the frozen Error. Read more: Error.ID().
+// Nil safe.
+func (ev *ErrorView) ID() string {
+	if ev == nil {
+		return ""
+	}
+	return ev.id
+}
+
+// Fingerprint returns the fingerprint of the frozen Error.
+// Read more: Error.Fingerprint().
+// Nil safe.
+func (ev *ErrorView) Fingerprint() string {
+	if ev == nil {
+		return ""
+	}
+	return ev.fingerprint
+}
+
+// CorrelationID returns the correlation ID of the frozen Error or "".
+// Read more: Error.CorrelationID().
+// Nil safe.
+func (ev *ErrorView) CorrelationID() string {
+	if ev == nil {
+		return ""
+	}
+	return ev.correlationID
+}
+
+// Messages returns the non-empty messages of the frozen Error
+// in order they have been added.
+// Nil safe.
+//
+// WARNING!
+// You MUST NOT modify returned slice.
+func (ev *ErrorView) Messages() []string {
+	if ev == nil {
+		return nil
+	}
+	return ev.messages
+}
+
+// Fields returns the copy of the frozen Error's fields
+// in order they have been added.
+// Nil safe.
+//
+// WARNING!
+// You MUST NOT modify returned slice.
+func (ev *ErrorView) Fields() []ekaletter.LetterField {
+	if ev == nil {
+		return nil
+	}
+	return ev.fields
+}
+
+// StackTrace returns the summary of the frozen Error's stacktrace:
+// one "<package>/<func> (<short_file>:<file_line>)" string per stack frame.
+// Returns nil if the frozen Error is lightweight.
+// Nil safe.
+//
+// WARNING!
+// You MUST NOT modify returned slice.
+func (ev *ErrorView) StackTrace() []string {
+	if ev == nil {
+		return nil
+	}
+	return ev.stackTrace
+}
+
+// Errors returns the frozen aggregated errors. Read more: Error.Errors().
+// Nil safe.
+//
+// WARNING!
+// You MUST NOT modify returned slice.
+func (ev *ErrorView) Errors() []*ErrorView {
+	if ev == nil {
+		return nil
+	}
+	return ev.children
+}
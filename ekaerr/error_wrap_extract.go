@@ -0,0 +1,131 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr
+
+import (
+	"errors"
+	"sort"
+	"sync"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+
+	"github.com/modern-go/reflect2"
+)
+
+type (
+	// WrapExtractor is a function that extracts the fields from the wrapped
+	// std error, that has the type WrapExtractor is registered for.
+	// Read more: RegisterWrapExtractor().
+	WrapExtractor func(err error) []ekaletter.LetterField
+
+	// errorWithFields is an interface of the error that provides its fields
+	// by itself. Such fields are extracted automatically w/o registration.
+	errorWithFields interface {
+		error
+		Fields() map[string]any
+	}
+)
+
+var (
+	// wrapExtractors is a registry of WrapExtractor, RType of error to WrapExtractor.
+	wrapExtractors struct {
+		sync.RWMutex
+		m map[uintptr]WrapExtractor
+	}
+)
+
+/*
+RegisterWrapExtractor registers 'fn' that will be called when the std error
+of the type with 'rtype' is wrapped (by Class.Wrap(), Class.LightWrap(),
+Class.WrapLazy()), and the returned fields will be added to the created *Error.
+'rtype' is an RType of the error, the one that reflect2.RTypeOf() returns.
+Nil 'fn' unregisters WrapExtractor. The last registered WrapExtractor
+for the same 'rtype' is used.
+
+The whole chain of wrapped errors (errors.Unwrap()) is checked, so it works
+for the errors wrapped by fmt.Errorf("%w") too.
+
+The errors, that have Fields() map[string]any method, do not need
+a registration: their fields are extracted automatically
+(if there's no registered WrapExtractor for them).
+
+For example, to get the details of PostgreSQL errors:
+
+	ekaerr.RegisterWrapExtractor(reflect2.RTypeOf((*pgconn.PgError)(nil)),
+		func(err error) []ekaletter.LetterField {
+			pgErr := err.(*pgconn.PgError)
+			return []ekaletter.LetterField{
+				ekaletter.FString("sqlstate", pgErr.Code),
+				ekaletter.FString("constraint", pgErr.ConstraintName),
+				ekaletter.FString("table", pgErr.TableName),
+			}
+		})
+
+The extracted fields are added before the fields passed to the Wrap() call.
+*/
+func RegisterWrapExtractor(rtype uintptr, fn WrapExtractor) {
+
+	wrapExtractors.Lock()
+	defer wrapExtractors.Unlock()
+
+	if fn == nil {
+		delete(wrapExtractors.m, rtype)
+		return
+	}
+
+	if wrapExtractors.m == nil {
+		wrapExtractors.m = make(map[uintptr]WrapExtractor)
+	}
+	wrapExtractors.m[rtype] = fn
+}
+
+// addWrapExtractedFields is a part of newError() func (Error's constructor).
+// Adds the fields, extracted from each error of 'legacyErr' chain
+// either by the registered WrapExtractor or by its Fields() method.
+// Read more: RegisterWrapExtractor().
+func (e *Error) addWrapExtractedFields(legacyErr error) *Error {
+
+	if !e.IsValid() {
+		return e
+	}
+
+	for err := legacyErr; err != nil; err = errors.Unwrap(err) {
+
+		wrapExtractors.RLock()
+		fn := wrapExtractors.m[reflect2.RTypeOf(err)]
+		wrapExtractors.RUnlock()
+
+		switch errTyped, ok := err.(errorWithFields); {
+		case fn != nil:
+			e.addFields(fn(err))
+		case ok:
+			e.addFields(wrapExtractFields(errTyped.Fields()))
+		}
+	}
+
+	return e
+}
+
+// wrapExtractFields returns the fields of 'm' sorted by their keys.
+func wrapExtractFields(m map[string]any) []ekaletter.LetterField {
+
+	if len(m) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	fs := make([]ekaletter.LetterField, len(keys))
+	for i, key := range keys {
+		fs[i] = ekaletter.FAny(key, m[key])
+	}
+
+	return fs
+}
@@ -0,0 +1,74 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr_test
+
+import (
+	"fmt"
+	"testing"
+	"unsafe"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+
+	"github.com/modern-go/reflect2"
+	"github.com/stretchr/testify/assert"
+)
+
+type (
+	wrapExtractTestPgError struct {
+		Code, ConstraintName string
+	}
+	wrapExtractTestFieldsError struct{}
+)
+
+func (e *wrapExtractTestPgError) Error() string { return "pg: " + e.Code }
+
+func (wrapExtractTestFieldsError) Error() string { return "with fields" }
+func (wrapExtractTestFieldsError) Fields() map[string]any {
+	return map[string]any{"table": "users", "attempt": 2}
+}
+
+func wrapExtractTestFields(err *ekaerr.Error) map[string]ekaletter.LetterField {
+	m := make(map[string]ekaletter.LetterField)
+	for _, f := range ekaletter.BridgeErrorGetLetter(unsafe.Pointer(err)).Fields {
+		m[f.Key] = f
+	}
+	return m
+}
+
+func TestRegisterWrapExtractor(t *testing.T) {
+
+	rtype := reflect2.RTypeOf((*wrapExtractTestPgError)(nil))
+	ekaerr.RegisterWrapExtractor(rtype, func(err error) []ekaletter.LetterField {
+		pgErr := err.(*wrapExtractTestPgError)
+		return []ekaletter.LetterField{
+			ekaletter.FString("sqlstate", pgErr.Code),
+			ekaletter.FString("constraint", pgErr.ConstraintName),
+		}
+	})
+	defer ekaerr.RegisterWrapExtractor(rtype, nil)
+
+	pgErr := &wrapExtractTestPgError{Code: "23505", ConstraintName: "users_pkey"}
+	err := ekaerr.IllegalState.Wrap(fmt.Errorf("insert: %w", pgErr), "Failed", "id", 1)
+
+	fs := wrapExtractTestFields(err)
+	assert.Equal(t, "23505", fs["sqlstate"].SValue)
+	assert.Equal(t, "users_pkey", fs["constraint"].SValue)
+	assert.Equal(t, int64(1), fs["id"].IValue)
+
+	ekaerr.RegisterWrapExtractor(rtype, nil)
+	err = ekaerr.IllegalState.Wrap(pgErr, "Failed")
+	assert.NotContains(t, wrapExtractTestFields(err), "sqlstate")
+}
+
+func TestError_Wrap_Fields(t *testing.T) {
+
+	err := ekaerr.IllegalState.LightWrap(wrapExtractTestFieldsError{}, "Failed")
+
+	fs := wrapExtractTestFields(err)
+	assert.Equal(t, "users", fs["table"].SValue)
+	assert.Equal(t, int64(2), fs["attempt"].IValue)
+}
@@ -0,0 +1,210 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+type (
+	// Group is a collection of goroutines working on subtasks of the same task,
+	// like golang.org/x/sync/errgroup.Group, but for the functions
+	// that return *Error.
+	//
+	// Unlike errgroup.Group, Wait() returns all errors (not only the first one)
+	// aggregated by one Error of Aggregated Class (read more: Aggregate()).
+	// Each of them has a field with the index of goroutine it's returned by
+	// (read more: GROUP_FIELD_GOROUTINE_IDX). The panics of goroutines are recovered
+	// and converted to the errors of Panic Class.
+	//
+	// A zero Group is valid, has no limit on the number of active goroutines
+	// and does not cancel anything on error. Use GroupWithContext() to get
+	// a Group, whose context.Context is canceled on the first error.
+	//
+	// A Group MUST NOT be copied after the first use.
+	Group struct {
+		wg     sync.WaitGroup
+		mu     sync.Mutex
+		sem    chan struct{}
+		cancel context.CancelFunc
+
+		idx  int          // index of the next goroutine
+		errs []groupError // errors of finished goroutines
+	}
+
+	// groupError is an Error returned by the goroutine with idx index.
+	groupError struct {
+		idx int
+		err *Error
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// GROUP_FIELD_GOROUTINE_IDX is the key of the field, that holds the index
+	// of goroutine (in order of Group.Go() calls starting from 0),
+	// the Error is returned by.
+	GROUP_FIELD_GOROUTINE_IDX = "goroutine_idx"
+
+	// GROUP_MESSAGE_PANIC is the message of the Error of Panic Class,
+	// a goroutine's panic is converted to.
+	GROUP_MESSAGE_PANIC = "goroutine panicked"
+
+	// GROUP_FIELD_PANIC is the key of the field, that holds the panic's value
+	// formatted by fmt.Sprint(), if it's not an error.
+	// Errors are wrapped instead.
+	GROUP_FIELD_PANIC = "panic"
+)
+
+// GroupWithContext returns a new Group and an associated context.Context
+// derived from 'ctx'. The derived context.Context is canceled the first time
+// a function passed to Group.Go() returns a non-nil Error (or panics)
+// or the first time Group.Wait() returns, whichever occurs first.
+func GroupWithContext(ctx context.Context) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Group{cancel: cancel}, ctx
+}
+
+// SetLimit limits the number of active goroutines in Group to at most 'n'.
+// A negative value indicates no limit.
+//
+// Any subsequent call to Go() will block until it can add an active goroutine
+// without exceeding the configured limit.
+// The limit MUST NOT be changed while any goroutines in Group are active.
+func (g *Group) SetLimit(n int) {
+	if n < 0 {
+		g.sem = nil
+		return
+	}
+	if len(g.sem) != 0 {
+		panic(fmt.Errorf("ekaerr: modify limit while %d goroutines in the group are still active", len(g.sem)))
+	}
+	g.sem = make(chan struct{}, n)
+}
+
+// Go calls 'f' in a new goroutine.
+// It blocks until the new goroutine can be added without the number of active
+// goroutines in Group exceeding the configured limit (read more: SetLimit()).
+//
+// The returned Error (if any) is saved with the goroutine's index
+// and will be returned by Wait(). If 'f' panics, the panic is recovered
+// and converted to the Error of Panic Class.
+func (g *Group) Go(f func() *Error) {
+	if g.sem != nil {
+		g.sem <- struct{}{}
+	}
+	g.do(f)
+}
+
+// TryGo calls 'f' in a new goroutine only if the number of active goroutines
+// in Group is currently below the configured limit (read more: SetLimit()).
+// The return value reports whether the goroutine was started.
+func (g *Group) TryGo(f func() *Error) bool {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		default:
+			return false
+		}
+	}
+	g.do(f)
+	return true
+}
+
+// Wait blocks until all goroutines started by Go() or TryGo() have returned,
+// then returns their errors (in order of goroutines' indexes) aggregated
+// by one Error of Aggregated Class, or nil if there were none.
+// Read more: Aggregate(), Error.Errors().
+func (g *Group) Wait() *Error {
+
+	g.wg.Wait()
+	if g.cancel != nil {
+		g.cancel()
+	}
+
+	g.mu.Lock()
+	errs := g.errs
+	g.errs = nil
+	g.mu.Unlock()
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	sort.Slice(errs, func(i, j int) bool {
+		return errs[i].idx < errs[j].idx
+	})
+
+	aggregated := make([]*Error, len(errs))
+	for i := range errs {
+		aggregated[i] = errs[i].err
+	}
+
+	return Aggregate(aggregated...)
+}
+
+// do starts 'f' in a new goroutine, that has an index from the Group's counter.
+func (g *Group) do(f func() *Error) {
+
+	g.mu.Lock()
+	idx := g.idx
+	g.idx++
+	g.mu.Unlock()
+
+	g.wg.Add(1)
+	go func() {
+		defer g.done()
+		g.addError(idx, groupCall(f))
+	}()
+}
+
+// done marks the goroutine as finished releasing the limit's slot.
+func (g *Group) done() {
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// addError saves 'err' of goroutine with 'idx' index if it's valid,
+// canceling the Group's context.Context.
+func (g *Group) addError(idx int, err *Error) {
+
+	if !err.IsValid() {
+		return
+	}
+
+	err.WithInt(GROUP_FIELD_GOROUTINE_IDX, idx)
+
+	g.mu.Lock()
+	g.errs = append(g.errs, groupError{idx: idx, err: err})
+	g.mu.Unlock()
+
+	if g.cancel != nil {
+		g.cancel()
+	}
+}
+
+// groupCall calls 'f' and returns its Error, or the Error of Panic Class
+// if 'f' panics.
+func groupCall(f func() *Error) (err *Error) {
+
+	defer func() {
+		if panicObj := recover(); panicObj != nil {
+			if panicErr, ok := panicObj.(error); ok {
+				err = Panic.Wrap(panicErr, GROUP_MESSAGE_PANIC)
+			} else {
+				err = Panic.New(GROUP_MESSAGE_PANIC).
+					WithString(GROUP_FIELD_PANIC, fmt.Sprint(panicObj))
+			}
+		}
+	}()
+
+	return f()
+}
@@ -0,0 +1,82 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaerr_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGroup(t *testing.T) {
+
+	var g ekaerr.Group
+
+	g.Go(func() *ekaerr.Error { return nil })
+	g.Go(func() *ekaerr.Error { panic("boom") })
+	g.Go(func() *ekaerr.Error { return ekaerr.NotFound.New("not found") })
+	g.Go(func() *ekaerr.Error { panic(errors.New("legacy")) })
+
+	err := g.Wait()
+	require.True(t, err.IsValid())
+	assert.True(t, err.Is(ekaerr.Aggregated))
+
+	errs := err.Errors()
+	require.Len(t, errs, 3)
+
+	for i, expectedIdx := range []int64{1, 2, 3} {
+		idx, ok := errs[i].FieldInt64(ekaerr.GROUP_FIELD_GOROUTINE_IDX)
+		assert.True(t, ok)
+		assert.Equal(t, expectedIdx, idx)
+	}
+
+	assert.True(t, errs[0].Is(ekaerr.Panic))
+	panicValue, _ := errs[0].FieldString(ekaerr.GROUP_FIELD_PANIC)
+	assert.Equal(t, "boom", panicValue)
+
+	assert.True(t, errs[1].Is(ekaerr.NotFound))
+	assert.True(t, errs[2].Is(ekaerr.Panic))
+
+	ekaerr.ReleaseError(err)
+
+	assert.Nil(t, g.Wait())
+}
+
+func TestGroupWithContext(t *testing.T) {
+
+	g, ctx := ekaerr.GroupWithContext(context.Background())
+	g.SetLimit(1)
+
+	g.Go(func() *ekaerr.Error { return ekaerr.IllegalState.New("failed") })
+	g.Go(func() *ekaerr.Error {
+		<-ctx.Done() // canceled by the previous goroutine's error
+		return nil
+	})
+
+	err := g.Wait()
+	require.Equal(t, 1, err.Len())
+	assert.True(t, err.Errors()[0].Is(ekaerr.IllegalState))
+	assert.Error(t, ctx.Err())
+
+	ekaerr.ReleaseError(err)
+
+	g = new(ekaerr.Group)
+	g.SetLimit(1)
+
+	release := make(chan struct{})
+	g.Go(func() *ekaerr.Error { <-release; return nil })
+	assert.False(t, g.TryGo(func() *ekaerr.Error { return nil }))
+
+	close(release)
+	assert.Nil(t, g.Wait())
+	assert.True(t, g.TryGo(func() *ekaerr.Error { return nil }))
+	assert.Nil(t, g.Wait())
+}
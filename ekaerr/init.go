@@ -19,6 +19,8 @@ func init() {
 	ekaletter.BridgeErrorGetStackIdx = bridgeGetStackIdx
 	ekaletter.BridgeErrorSetStackIdx = bridgeSetStackIdx
 
+	ekaletter.BridgeLetterFingerprint = fingerprintOf
+
 	// It's prohibited to use some types as Error's fields.
 	ignoredTypes := []uintptr{
 		reflect2.RTypeOf(Class{}), reflect2.RTypeOf((*Class)(nil)),
@@ -38,6 +38,21 @@ type (
 	}
 )
 
+// IsValid reports whether n represents valid Namespace.
+// It returns false if n has not been initialized properly (instantiated manually
+// instead of NewNamespace() calling).
+func (n Namespace) IsValid() bool {
+	return isValidNamespaceID(n.id)
+}
+
+// Name returns a current Namespace's name that was used at the Namespace creation.
+func (n Namespace) Name() string {
+	if !n.IsValid() {
+		return ""
+	}
+	return n.name
+}
+
 // NewClass is a Class's constructor. Specify the Class's name 'name' and that is!
 // A new Class will be created and its copy is returned.
 //
@@ -88,7 +88,7 @@ func newNamespace(name string, custom bool) Namespace {
 		defer registeredNamespacesMap.Unlock()
 		registeredNamespacesMap.m[n.id] = n
 	} else {
-		registeredNamespacesMap.m[n.id] = n
+		registeredNamespacesArr[n.id] = n
 	}
 
 	return n
@@ -0,0 +1,59 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaext
+
+// Keys returns a new slice of keys of 'm' in unspecified order.
+// Allocates exactly once (a slice of len(m)). Returns nil for empty 'm'.
+func Keys[K comparable, V any](m map[K]V) []K {
+	if len(m) == 0 {
+		return nil
+	}
+	return KeysTo(make([]K, 0, len(m)), m)
+}
+
+// KeysTo is the same as Keys() but appends keys to 'dst' and returns it.
+// Allocates only if 'dst' has not enough capacity.
+func KeysTo[K comparable, V any](dst []K, m map[K]V) []K {
+	for k := range m {
+		dst = append(dst, k)
+	}
+	return dst
+}
+
+// Values returns a new slice of values of 'm' in unspecified order.
+// Allocates exactly once (a slice of len(m)). Returns nil for empty 'm'.
+func Values[K comparable, V any](m map[K]V) []V {
+	if len(m) == 0 {
+		return nil
+	}
+	return ValuesTo(make([]V, 0, len(m)), m)
+}
+
+// ValuesTo is the same as Values() but appends values to 'dst' and returns it.
+// Allocates only if 'dst' has not enough capacity.
+func ValuesTo[K comparable, V any](dst []V, m map[K]V) []V {
+	for _, v := range m {
+		dst = append(dst, v)
+	}
+	return dst
+}
+
+// Invert returns a new map, where keys of 'm' are values and vice-versa.
+// If 'm' has the same values for different keys, it's unspecified
+// which key will be used. Allocates exactly once (a map of len(m)).
+func Invert[K, V comparable](m map[K]V) map[V]K {
+	return InvertTo(make(map[V]K, len(m)), m)
+}
+
+// InvertTo is the same as Invert() but writes inverted pairs to 'dst'
+// and returns it. Never allocates if 'dst' is big enough.
+// 'dst' MUST NOT be nil. Panic otherwise.
+func InvertTo[K, V comparable](dst map[V]K, m map[K]V) map[V]K {
+	for k, v := range m {
+		dst[v] = k
+	}
+	return dst
+}
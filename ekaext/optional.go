@@ -0,0 +1,77 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaext
+
+import (
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// Optional is a container of a value that may be absent.
+// Optional is an immutable value type, the zero Optional has no value.
+type Optional[T any] struct {
+	value T
+	isSet bool
+}
+
+// Some returns an Optional with 'value'.
+func Some[T any](value T) Optional[T] {
+	return Optional[T]{value: value, isSet: true}
+}
+
+// None returns an Optional without value.
+func None[T any]() Optional[T] {
+	return Optional[T]{}
+}
+
+// OptionalOf returns an Optional of (T, bool) pair,
+// so it may be used to wrap a call or a map lookup: OptionalOf(m[key]).
+func OptionalOf[T any](value T, ok bool) Optional[T] {
+	if !ok {
+		return Optional[T]{}
+	}
+	return Optional[T]{value: value, isSet: true}
+}
+
+// IsSome reports whether Optional has a value.
+func (o Optional[T]) IsSome() bool {
+	return o.isSet
+}
+
+// IsNone reports whether Optional has no value.
+func (o Optional[T]) IsNone() bool {
+	return !o.isSet
+}
+
+// Get returns (T, bool) pair. T is zero if Optional has no value.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.isSet
+}
+
+// Must returns the contained value. Panics if Optional has no value.
+func (o Optional[T]) Must() T {
+	if !o.isSet {
+		panic("ekaext: Optional.Must: no value")
+	}
+	return o.value
+}
+
+// OrElse returns the contained value or 'fallback' if Optional has no value.
+func (o Optional[T]) OrElse(fallback T) T {
+	if !o.isSet {
+		return fallback
+	}
+	return o.value
+}
+
+// Field returns ekaletter.LetterField with 'key' for the Optional:
+// the contained value's one (see ekaletter.FAny()) or a nil field
+// if Optional has no value.
+func (o Optional[T]) Field(key string) ekaletter.LetterField {
+	if !o.isSet {
+		return ekaletter.FNil(key, 0)
+	}
+	return ekaletter.FAny(key, o.value)
+}
@@ -0,0 +1,110 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaext
+
+import (
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// Result is a container of either a value or an *ekaerr.Error.
+// It replaces (T, *ekaerr.Error) pairs where it's convenient.
+// Result is an immutable value type, the zero Result is an Ok with zero value.
+type Result[T any] struct {
+	value T
+	err   *ekaerr.Error
+}
+
+// Ok returns a Result with 'value'.
+func Ok[T any](value T) Result[T] {
+	return Result[T]{value: value}
+}
+
+// Err returns a failed Result with 'err'.
+// If 'err' is nil, the zero Ok Result is returned.
+func Err[T any](err *ekaerr.Error) Result[T] {
+	return Result[T]{err: err}
+}
+
+// ResultOf returns a Result of (T, *ekaerr.Error) pair,
+// so it may be used to wrap a call: ResultOf(foo()).
+func ResultOf[T any](value T, err *ekaerr.Error) Result[T] {
+	if err.IsNotNil() {
+		return Result[T]{err: err}
+	}
+	return Result[T]{value: value}
+}
+
+// IsOk reports whether Result contains a value (not an error).
+func (r Result[T]) IsOk() bool {
+	return r.err.IsNil()
+}
+
+// IsErr reports whether Result contains an error.
+func (r Result[T]) IsErr() bool {
+	return r.err.IsNotNil()
+}
+
+// Unwrap returns (T, *ekaerr.Error) pair. T is zero if Result contains an error.
+func (r Result[T]) Unwrap() (T, *ekaerr.Error) {
+	return r.value, r.err
+}
+
+// Err returns the contained error or nil.
+func (r Result[T]) Err() *ekaerr.Error {
+	return r.err
+}
+
+// Must returns the contained value. Panics with the contained *ekaerr.Error
+// if Result is failed.
+func (r Result[T]) Must() T {
+	if r.err.IsNotNil() {
+		panic(r.err)
+	}
+	return r.value
+}
+
+// OrElse returns the contained value or 'fallback' if Result is failed.
+func (r Result[T]) OrElse(fallback T) T {
+	if r.err.IsNotNil() {
+		return fallback
+	}
+	return r.value
+}
+
+// MapErr returns a new Result with the error transformed by 'cb'
+// if Result is failed, or the same Result otherwise.
+// It's useful to add messages or fields to the error, like:
+//
+//	r.MapErr(func(err *ekaerr.Error) *ekaerr.Error {
+//	    return err.AddMessage("Failed to load user").WithInt("user_id", id)
+//	})
+func (r Result[T]) MapErr(cb func(err *ekaerr.Error) *ekaerr.Error) Result[T] {
+	if r.err.IsNotNil() {
+		return Err[T](cb(r.err))
+	}
+	return r
+}
+
+// Field returns ekaletter.LetterField with 'key' for the Result:
+// the contained value's one (see ekaletter.FAny()) for Ok Result,
+// or a string "<error_class_name> (<error_id>)" for failed one.
+func (r Result[T]) Field(key string) ekaletter.LetterField {
+	if r.err.IsNotNil() {
+		return ekaletter.FString(key, r.err.Class().Name()+" ("+r.err.ID()+")")
+	}
+	return ekaletter.FAny(key, r.value)
+}
+
+// MapResult returns a new Result with the value transformed by 'cb'
+// if Result is Ok, or a failed Result with the same error otherwise.
+// It's a function, not a method, because Go methods can't have type parameters.
+func MapResult[T, R any](r Result[T], cb func(T) R) Result[R] {
+	if r.err.IsNotNil() {
+		return Err[R](r.err)
+	}
+	return Ok(cb(r.value))
+}
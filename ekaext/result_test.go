@@ -0,0 +1,55 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaext_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekaext"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResult(t *testing.T) {
+
+	ok := ekaext.ResultOf(42, nil)
+	assert.True(t, ok.IsOk())
+	assert.Equal(t, 42, ok.Must())
+	assert.Equal(t, "42", ekaext.MapResult(ok, strconv.Itoa).Must())
+	assert.Equal(t, "k", ok.Field("k").Key)
+	assert.Equal(t, int64(42), ok.Field("k").IValue)
+
+	err := ekaerr.IllegalArgument.New("bad")
+	failed := ekaext.ResultOf(42, err)
+	assert.True(t, failed.IsErr())
+	assert.Equal(t, 0, failed.OrElse(0))
+	assert.Equal(t, err.Class().Name()+" ("+err.ID()+")", failed.Field("k").SValue)
+	assert.Panics(t, func() { failed.Must() })
+
+	mapped := failed.MapErr(func(err *ekaerr.Error) *ekaerr.Error {
+		return err.ReplaceClass(ekaerr.IllegalState)
+	})
+	assert.True(t, mapped.Err().Is(ekaerr.IllegalState))
+	assert.True(t, ekaext.MapResult(mapped, strconv.Itoa).IsErr())
+}
+
+func TestOptional(t *testing.T) {
+
+	m := map[string]int{"a": 1}
+
+	some := ekaext.OptionalOf(m["a"], true)
+	assert.True(t, some.IsSome())
+	assert.Equal(t, 1, some.Must())
+	assert.Equal(t, int64(1), some.Field("a").IValue)
+
+	none := ekaext.None[int]()
+	assert.True(t, none.IsNone())
+	assert.Equal(t, 2, none.OrElse(2))
+	assert.True(t, none.Field("b").Kind.IsNil())
+	assert.Panics(t, func() { none.Must() })
+}
@@ -0,0 +1,116 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaext
+
+// Map returns a new slice of 'cb' results for each element of 's'.
+// Allocates exactly once (a slice of len(s)). Returns nil for empty 's'.
+func Map[T, R any](s []T, cb func(T) R) []R {
+	if len(s) == 0 {
+		return nil
+	}
+	return MapTo(make([]R, 0, len(s)), s, cb)
+}
+
+// MapTo is the same as Map() but appends results to 'dst' and returns it.
+// Allocates only if 'dst' has not enough capacity.
+func MapTo[T, R any](dst []R, s []T, cb func(T) R) []R {
+	for i := range s {
+		dst = append(dst, cb(s[i]))
+	}
+	return dst
+}
+
+// Filter returns a new slice of elements of 's' for which 'cb' returns true.
+// Allocates at most once (a slice of len(s) capacity),
+// doesn't allocate if no element passes the filter. 's' is not modified.
+func Filter[T any](s []T, cb func(T) bool) []T {
+
+	var ret []T
+	for i := range s {
+		if cb(s[i]) {
+			if ret == nil {
+				ret = make([]T, 0, len(s)-i)
+			}
+			ret = append(ret, s[i])
+		}
+	}
+
+	return ret
+}
+
+// FilterInPlace is the same as Filter() but reuses the memory of 's'.
+// Never allocates. 's' MUST NOT be used after the call, only returned slice.
+// The elements after the len of returned slice are zeroed (to help GC).
+func FilterInPlace[T any](s []T, cb func(T) bool) []T {
+
+	n := 0
+	for i := range s {
+		if cb(s[i]) {
+			s[n] = s[i]
+			n++
+		}
+	}
+
+	var zero T
+	for i := n; i < len(s); i++ {
+		s[i] = zero
+	}
+
+	return s[:n]
+}
+
+// Reduce applies 'cb' to the accumulator (starting from 'initial')
+// and each element of 's', returning the final accumulator's value.
+// Never allocates by itself.
+func Reduce[T, A any](s []T, initial A, cb func(acc A, v T) A) A {
+	for i := range s {
+		initial = cb(initial, s[i])
+	}
+	return initial
+}
+
+// Unique returns a new slice of unique elements of 's' keeping the order
+// of their first occurrences. 's' is not modified.
+// Allocates a slice and a map (for len(s) > 1), both of len(s) capacity.
+func Unique[T comparable](s []T) []T {
+
+	if len(s) < 2 {
+		return append([]T(nil), s...)
+	}
+
+	var (
+		ret  = make([]T, 0, len(s))
+		seen = make(map[T]struct{}, len(s))
+	)
+
+	for i := range s {
+		if _, ok := seen[s[i]]; !ok {
+			seen[s[i]] = struct{}{}
+			ret = append(ret, s[i])
+		}
+	}
+
+	return ret
+}
+
+// Chunk splits 's' to the chunks of 'size' elements (the last one may be smaller).
+// Chunks share the memory of 's' (they're its sub-slices with limited capacity),
+// so the only allocation is a slice of chunks.
+// Returns nil if 's' is empty or 'size' <= 0.
+func Chunk[T any](s []T, size int) [][]T {
+
+	if len(s) == 0 || size <= 0 {
+		return nil
+	}
+
+	ret := make([][]T, 0, (len(s)+size-1)/size)
+	for size < len(s) {
+		ret = append(ret, s[:size:size])
+		s = s[size:]
+	}
+
+	return append(ret, s)
+}
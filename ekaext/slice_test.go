@@ -0,0 +1,59 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaext_test
+
+import (
+	"sort"
+	"strconv"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaext"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSliceHelpers(t *testing.T) {
+
+	s := []int{1, 2, 3, 2, 4, 1}
+	isEven := func(v int) bool { return v%2 == 0 }
+
+	assert.Equal(t, []string{"1", "2", "3", "2", "4", "1"}, ekaext.Map(s, strconv.Itoa))
+	assert.Nil(t, ekaext.Map([]int(nil), strconv.Itoa))
+	assert.Equal(t, []int{2, 2, 4}, ekaext.Filter(s, isEven))
+	assert.Nil(t, ekaext.Filter([]int{1, 3}, isEven))
+	assert.Equal(t, 13, ekaext.Reduce(s, 0, func(acc, v int) int { return acc + v }))
+	assert.Equal(t, []int{1, 2, 3, 4}, ekaext.Unique(s))
+	assert.Equal(t, [][]int{{1, 2, 3, 2}, {4, 1}}, ekaext.Chunk(s, 4))
+
+	chunks := ekaext.Chunk(s, 4)
+	chunks[0] = append(chunks[0], 100) // must not overwrite the next chunk
+	assert.Equal(t, []int{4, 1}, chunks[1])
+
+	s2 := []int{1, 2, 3, 4}
+	assert.Equal(t, []int{2, 4}, ekaext.FilterInPlace(s2, isEven))
+	assert.Equal(t, []int{2, 4, 0, 0}, s2)
+
+	allocs := testing.AllocsPerRun(10, func() {
+		_ = ekaext.MapTo(s2[:0], []int{1, 2}, func(v int) int { return v * 2 })
+	})
+	assert.Zero(t, allocs)
+}
+
+func TestMapHelpers(t *testing.T) {
+
+	m := map[string]int{"a": 1, "b": 2}
+
+	keys := ekaext.Keys(m)
+	sort.Strings(keys)
+	assert.Equal(t, []string{"a", "b"}, keys)
+
+	values := ekaext.Values(m)
+	sort.Ints(values)
+	assert.Equal(t, []int{1, 2}, values)
+
+	assert.Equal(t, map[int]string{1: "a", 2: "b"}, ekaext.Invert(m))
+	assert.Nil(t, ekaext.Keys(map[string]int(nil)))
+}
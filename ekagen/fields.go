@@ -0,0 +1,65 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekagen
+
+import (
+	"reflect"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// Fields extracts log fields from the struct 'v' (or a pointer to the struct)
+// using its `ekalog` struct tags.
+//
+// Only tagged fields are extracted. Tag format is the same as for encoding/json:
+//
+//	type User struct {
+//	    ID       int    `ekalog:"id"`
+//	    Name     string `ekalog:"name,omitempty"`
+//	    Password string `ekalog:"-"`
+//	    Internal int    // not tagged, ignored
+//	}
+//
+//   - The first part of tag is a key of field. Field's name is used if it's empty;
+//   - "omitempty" option means field is skipped if it has zero value;
+//   - "-" tag means field is skipped always.
+//
+// The extraction rules are built once for each type using reflection and cached,
+// so the next calls are cheap: no tag parsing, no whole struct encoding
+// (unlike logging the struct as a one field using ekaletter.FAny()).
+//
+// Returns nil if 'v' is nil, nil pointer or not a struct.
+// Use Logger.WithStruct() or Logger.WithMany() to attach extracted fields.
+func Fields(v any) []ekaletter.LetterField {
+	return AppendFields(nil, v)
+}
+
+// AppendFields is the same as Fields() but appends extracted fields to 'dst'
+// and returns the extended slice.
+func AppendFields(dst []ekaletter.LetterField, v any) []ekaletter.LetterField {
+
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return dst
+		}
+		rv = rv.Elem()
+	}
+
+	if rv.Kind() != reflect.Struct {
+		return dst
+	}
+
+	for _, fe := range extractorsFor(rv.Type()) {
+		fv := rv.Field(fe.idx)
+		if fe.omitEmpty && fv.IsZero() {
+			continue
+		}
+		dst = append(dst, fe.cb(fe.key, fv))
+	}
+
+	return dst
+}
@@ -0,0 +1,122 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekagen
+
+import (
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// _FieldExtractor is a rule of extracting one struct's field
+	// as ekaletter.LetterField.
+	_FieldExtractor struct {
+		idx       int
+		key       string
+		omitEmpty bool
+		cb        func(key string, v reflect.Value) ekaletter.LetterField
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	_TAG_NAME = "ekalog"
+)
+
+var (
+	// extractorsCache is a map of reflect.Type -> []_FieldExtractor.
+	extractorsCache sync.Map
+
+	typeTime     = reflect.TypeOf(time.Time{})
+	typeDuration = reflect.TypeOf(time.Duration(0))
+)
+
+// extractorsFor returns (building if it's required) extraction rules
+// for the struct type 't'.
+func extractorsFor(t reflect.Type) []_FieldExtractor {
+
+	if cached, ok := extractorsCache.Load(t); ok {
+		return cached.([]_FieldExtractor)
+	}
+
+	fes := make([]_FieldExtractor, 0, t.NumField())
+	for i, n := 0, t.NumField(); i < n; i++ {
+
+		sf := t.Field(i)
+		tag, ok := sf.Tag.Lookup(_TAG_NAME)
+		if !ok || tag == "-" || !sf.IsExported() {
+			continue
+		}
+
+		key, opts, _ := strings.Cut(tag, ",")
+		if key == "" {
+			key = sf.Name
+		}
+
+		fes = append(fes, _FieldExtractor{
+			idx:       i,
+			key:       key,
+			omitEmpty: strings.Contains(","+opts+",", ",omitempty,"),
+			cb:        extractorFor(sf.Type),
+		})
+	}
+
+	cached, _ := extractorsCache.LoadOrStore(t, fes)
+	return cached.([]_FieldExtractor)
+}
+
+// extractorFor returns a function that constructs ekaletter.LetterField
+// with the most suitable kind for the values of type 't'.
+func extractorFor(t reflect.Type) func(key string, v reflect.Value) ekaletter.LetterField {
+
+	switch t {
+	case typeTime:
+		return func(key string, v reflect.Value) ekaletter.LetterField {
+			return ekaletter.FUnixNanoFromStd(key, v.Interface().(time.Time))
+		}
+	case typeDuration:
+		return func(key string, v reflect.Value) ekaletter.LetterField {
+			return ekaletter.FDuration(key, time.Duration(v.Int()))
+		}
+	}
+
+	switch t.Kind() {
+
+	case reflect.Bool:
+		return func(key string, v reflect.Value) ekaletter.LetterField {
+			return ekaletter.FBool(key, v.Bool())
+		}
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return func(key string, v reflect.Value) ekaletter.LetterField {
+			return ekaletter.FInt64(key, v.Int())
+		}
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return func(key string, v reflect.Value) ekaletter.LetterField {
+			return ekaletter.FUint64(key, v.Uint())
+		}
+
+	case reflect.Float32, reflect.Float64:
+		return func(key string, v reflect.Value) ekaletter.LetterField {
+			return ekaletter.FFloat64(key, v.Float())
+		}
+
+	case reflect.String:
+		return func(key string, v reflect.Value) ekaletter.LetterField {
+			return ekaletter.FString(key, v.String())
+		}
+
+	default:
+		return func(key string, v reflect.Value) ekaletter.LetterField {
+			return ekaletter.FAny(key, v.Interface())
+		}
+	}
+}
@@ -0,0 +1,50 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekagen_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekagen"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tUser struct {
+	ID       int     `ekalog:"id"`
+	Name     string  `ekalog:"name,omitempty"`
+	Balance  float64 `ekalog:",omitempty"`
+	Password string  `ekalog:"-"`
+	Internal int
+}
+
+func TestFields(t *testing.T) {
+
+	fs := ekagen.Fields(&tUser{ID: 42, Name: "John", Password: "secret", Internal: 1})
+	if assert.Len(t, fs, 2) {
+		assert.Equal(t, "id", fs[0].Key)
+		assert.EqualValues(t, 42, fs[0].IValue)
+		assert.Equal(t, "name", fs[1].Key)
+		assert.Equal(t, "John", fs[1].SValue)
+	}
+
+	fs = ekagen.Fields(tUser{Balance: 1})
+	if assert.Len(t, fs, 2) {
+		assert.Equal(t, "id", fs[0].Key)
+		assert.Equal(t, "Balance", fs[1].Key)
+	}
+
+	assert.Nil(t, ekagen.Fields((*tUser)(nil)))
+	assert.Nil(t, ekagen.Fields(42))
+}
+
+func BenchmarkFields(b *testing.B) {
+	u := tUser{ID: 42, Name: "John"}
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_ = ekagen.Fields(&u)
+	}
+}
@@ -0,0 +1,352 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package alert
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Rule is a condition an ekalog.Entry must match to trigger an alert.
+	//
+	// Entry matches Rule if its level is MinLevel or more important
+	// and (if Classes are not empty) it has an attached ekaerr.Error
+	// of any of Classes or their subclasses.
+	// The alert is triggered only if more than Threshold entries have matched
+	// Rule within Window.
+	Rule struct {
+
+		// Name is a Rule's name, that is included to the alert.
+		Name string
+
+		// MinLevel is the least important level of matched entries,
+		// like ekalog.LEVEL_ERROR.
+		MinLevel ekalog.Level
+
+		// Classes are the classes of ekaerr.Error (with their subclasses)
+		// the matched entries must have attached. Any entry matches if it's empty.
+		Classes []ekaerr.Class
+
+		// Threshold is how much entries must match Rule within Window
+		// to trigger an alert (the alert is triggered by the next one).
+		// Each matched entry triggers an alert if it's 0.
+		Threshold int
+
+		// Window is a sliding time window, the matched entries are counted within.
+		// By default, it's a minute (ENCODER_DEFAULT_WINDOW).
+		Window time.Duration
+	}
+
+	// Format is a webhook's payload format. Read more: Encoder.SetFormat().
+	Format uint8
+
+	// Encoder is an ekalog.CI_Encoder, that matches ekalog.Entry against
+	// its Rule's and encodes the one that triggers an alert as a compact
+	// webhook payload of Slack, Microsoft Teams or PagerDuty (see SetFormat()).
+	//
+	// Alerts are deduplicated: the alert with the same Rule, ekaerr.Error's class
+	// and message as the last sent one is suppressed within cooldown
+	// (see SetCooldown()). The number of suppressed alerts is reported
+	// by the next sent one. The entries' time is used as the current one.
+	//
+	// The log entries, that trigger no alert, are encoded as an empty data,
+	// that is ignored by Writer.
+	//
+	// Use it with Writer as a part of ekalog.CommonIntegrator:
+	//
+	//	ci := new(ekalog.CommonIntegrator).
+	//	    WithEncoder(new(ekalog.CI_ConsoleEncoder)).WriteTo(os.Stdout).
+	//	    WithEncoder(new(alert.Encoder).SetFormat(alert.FORMAT_SLACK).
+	//	        AddRule(alert.Rule{Name: "db", MinLevel: ekalog.LEVEL_ERROR, Threshold: 10})).
+	//	    WriteTo(alert.NewWriter(slackWebhookURL))
+	Encoder struct {
+		rules      []Rule
+		format     Format
+		routingKey string
+		source     string
+
+		cooldown    time.Duration
+		cooldownSet bool
+
+		mu      sync.Mutex
+		matched [][]time.Time        // the time of matched entries within Window per rule
+		sent    map[string]time.Time // the time of the last sent alert per dedup key
+		dropped map[string]int       // how much alerts are suppressed per dedup key
+	}
+
+	// Writer is an io.Writer, that posts alerts (encoded by Encoder)
+	// to the webhook's URL.
+	//
+	// Alerts are sent asynchronously, one by one, by the background goroutine.
+	// If the queue of pending alerts is full, new alerts are dropped.
+	// The failed requests (network errors, 429 and 5xx responses) are retried
+	// with exponential backoff (see SetRetry()).
+	// Writer implements ekalog.CI_WriterFlusher and ekalog.CI_WriterCloser,
+	// so ekalog.CommonIntegrator.Flush(), ekalog.CommonIntegrator.Close()
+	// waits until all pending alerts are sent.
+	//
+	// Writer MUST be created by NewWriter().
+	Writer struct {
+		url     string
+		client  *http.Client
+		retries int
+		backoff time.Duration
+
+		mu       sync.RWMutex
+		isClosed bool
+		queue    chan []byte
+		pending  sync.WaitGroup
+		lastErr  error
+		lastErrM sync.Mutex
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// FORMAT_SLACK is a Slack's incoming webhook payload: {"text": "..."}.
+	FORMAT_SLACK Format = iota
+
+	// FORMAT_TEAMS is a Microsoft Teams' incoming webhook payload (MessageCard).
+	FORMAT_TEAMS
+
+	// FORMAT_PAGERDUTY is a PagerDuty's Events API v2 "trigger" event.
+	// The routing key must be set by Encoder.SetRoutingKey().
+	FORMAT_PAGERDUTY
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// ENCODER_DEFAULT_WINDOW is a default Rule's window.
+	ENCODER_DEFAULT_WINDOW = time.Minute
+
+	// ENCODER_DEFAULT_COOLDOWN is a default Encoder's cooldown of duplicated alerts.
+	ENCODER_DEFAULT_COOLDOWN = 5 * time.Minute
+
+	// WRITER_DEFAULT_QUEUE_SIZE is a default size of Writer's pending alerts queue.
+	WRITER_DEFAULT_QUEUE_SIZE = 64
+
+	// WRITER_DEFAULT_TIMEOUT is a default timeout of Writer's HTTP requests.
+	WRITER_DEFAULT_TIMEOUT = 10 * time.Second
+
+	// WRITER_DEFAULT_RETRIES is a default number of Writer's retries of failed request.
+	WRITER_DEFAULT_RETRIES = 3
+
+	// WRITER_DEFAULT_BACKOFF is a default delay before the first Writer's retry.
+	// Each next delay is twice as long.
+	WRITER_DEFAULT_BACKOFF = time.Second
+)
+
+var (
+	// Make sure we won't break API.
+	_ ekalog.CI_Encoder       = (*Encoder)(nil)
+	_ ekalog.CI_WriterFlusher = (*Writer)(nil)
+	_ ekalog.CI_WriterCloser  = (*Writer)(nil)
+	_ ekalog.CI_WriterRemote  = (*Writer)(nil)
+)
+
+// AddRule adds Rule the log entries are matched against.
+// The first Rule that triggers an alert is used for it.
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (ae *Encoder) AddRule(rule Rule) *Encoder {
+	if rule.Window <= 0 {
+		rule.Window = ENCODER_DEFAULT_WINDOW
+	}
+	ae.rules = append(ae.rules, rule)
+	ae.matched = append(ae.matched, nil)
+	return ae
+}
+
+// SetFormat sets the webhook's payload format. By default, it's FORMAT_SLACK.
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (ae *Encoder) SetFormat(format Format) *Encoder {
+	ae.format = format
+	return ae
+}
+
+// SetRoutingKey sets PagerDuty's integration (routing) key.
+// It's required for FORMAT_PAGERDUTY and ignored by other formats.
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (ae *Encoder) SetRoutingKey(routingKey string) *Encoder {
+	ae.routingKey = routingKey
+	return ae
+}
+
+// SetSource sets the alert's source. By default, it's a hostname.
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (ae *Encoder) SetSource(source string) *Encoder {
+	ae.source = source
+	return ae
+}
+
+// SetCooldown sets the duration, the duplicates of the sent alert are suppressed
+// within. By default, it's ENCODER_DEFAULT_COOLDOWN.
+// Zero (or negative) disables deduplication.
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (ae *Encoder) SetCooldown(cooldown time.Duration) *Encoder {
+	ae.cooldown, ae.cooldownSet = cooldown, true
+	return ae
+}
+
+// PreEncodeField does nothing. Alerts are compact and contain no fields,
+// except PagerDuty's custom details, that contain log entry's ones.
+//
+// PreEncodeField is for internal purposes only and MUST NOT be called directly.
+func (_ *Encoder) PreEncodeField(_ ekaletter.LetterField) {}
+
+// EncodeEntry encodes passed ekalog.Entry as an alert's webhook payload.
+// Returns nil if ekalog.Entry triggers no alert or the alert is a suppressed
+// duplicate.
+//
+// EncodeEntry is for internal purposes only and MUST NOT be called directly.
+// UB otherwise, may panic.
+func (ae *Encoder) EncodeEntry(e *ekalog.Entry) []byte {
+
+	a := ae.trigger(e)
+	if a == nil {
+		return nil
+	}
+
+	return ae.encode(a)
+}
+
+// NewWriter creates a new Writer for the webhook's 'url',
+// starting its background goroutine.
+func NewWriter(url string) *Writer {
+
+	w := &Writer{
+		url:     url,
+		client:  &http.Client{Timeout: WRITER_DEFAULT_TIMEOUT},
+		retries: WRITER_DEFAULT_RETRIES,
+		backoff: WRITER_DEFAULT_BACKOFF,
+		queue:   make(chan []byte, WRITER_DEFAULT_QUEUE_SIZE),
+	}
+
+	go w.worker()
+	return w
+}
+
+// SetHTTPClient replaces the http.Client, that is used to send alerts.
+// Nil http.Client is ignored.
+//
+// This method MUST NOT be called after the first Write() call.
+func (w *Writer) SetHTTPClient(client *http.Client) *Writer {
+	if client != nil {
+		w.client = client
+	}
+	return w
+}
+
+// SetRetry sets how much times the failed request is retried
+// and the delay before the first retry (each next one is twice as long).
+// By default, they are WRITER_DEFAULT_RETRIES, WRITER_DEFAULT_BACKOFF.
+// Negative values are ignored.
+//
+// This method MUST NOT be called after the first Write() call.
+func (w *Writer) SetRetry(retries int, backoff time.Duration) *Writer {
+	if retries >= 0 {
+		w.retries = retries
+	}
+	if backoff >= 0 {
+		w.backoff = backoff
+	}
+	return w
+}
+
+// IsRemote reports that Writer sends the data out of the host.
+// It implements ekalog.CI_WriterRemote, so the entries marked
+// by ekalog.Logger.Local() never trigger an alert.
+func (_ *Writer) IsRemote() bool {
+	return true
+}
+
+// Write enqueues alert 'p' (encoded by Encoder) to be sent.
+// Empty 'p' is ignored. Never returns an error, but drops the alert
+// if Writer is closed or its queue is full.
+func (w *Writer) Write(p []byte) (int, error) {
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.isClosed {
+		return len(p), nil
+	}
+
+	payload := make([]byte, len(p))
+	copy(payload, p)
+
+	w.pending.Add(1)
+	select {
+	case w.queue <- payload:
+	default:
+		w.pending.Done()
+	}
+
+	return len(p), nil
+}
+
+// Flush waits until all enqueued alerts are sent or 'ctx' is done.
+// Returns the last error of alerts sending (if any) or ctx.Err().
+func (w *Writer) Flush(ctx context.Context) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.lastErrM.Lock()
+		defer w.lastErrM.Unlock()
+		err := w.lastErr
+		w.lastErr = nil
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting new alerts and waits until all enqueued alerts
+// are sent or 'ctx' is done. See Flush() for more details.
+// The next calls of Close() are no-op.
+func (w *Writer) Close(ctx context.Context) error {
+
+	w.mu.Lock()
+	if w.isClosed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.isClosed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	return w.Flush(ctx)
+}
@@ -0,0 +1,429 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package alert
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// alert is a triggered alert, that is encoded to the webhook's payload.
+	alert struct {
+		rule       *Rule
+		entry      *ekalog.Entry
+		message    string
+		class      string
+		errorID    string
+		dedupKey   string
+		suppressed int
+	}
+
+	// slackPayload is a Slack's incoming webhook payload.
+	// Read more: https://api.slack.com/messaging/webhooks
+	slackPayload struct {
+		Text string `json:"text"`
+	}
+
+	// teamsPayload is a Microsoft Teams' incoming webhook MessageCard payload.
+	// Read more: https://learn.microsoft.com/en-us/outlook/actionable-messages/message-card-reference
+	teamsPayload struct {
+		Type       string `json:"@type"`
+		Context    string `json:"@context"`
+		ThemeColor string `json:"themeColor,omitempty"`
+		Summary    string `json:"summary"`
+		Title      string `json:"title"`
+		Text       string `json:"text"`
+	}
+
+	// pagerDutyPayload is a PagerDuty's Events API v2 event.
+	// Read more: https://developer.pagerduty.com/docs/events-api-v2/trigger-events/
+	pagerDutyPayload struct {
+		RoutingKey  string `json:"routing_key"`
+		EventAction string `json:"event_action"`
+		DedupKey    string `json:"dedup_key,omitempty"`
+		Payload     struct {
+			Summary       string         `json:"summary"`
+			Source        string         `json:"source"`
+			Severity      string         `json:"severity"`
+			Timestamp     string         `json:"timestamp,omitempty"`
+			Component     string         `json:"component,omitempty"`
+			Class         string         `json:"class,omitempty"`
+			CustomDetails map[string]any `json:"custom_details,omitempty"`
+		} `json:"payload"`
+	}
+)
+
+var (
+	hostname, _ = os.Hostname()
+)
+
+// trigger matches 'e' against Encoder's rules and returns the triggered alert
+// or nil if there's none or it's a suppressed duplicate.
+func (ae *Encoder) trigger(e *ekalog.Entry) *alert {
+
+	var class string
+	if e.ErrLetter != nil {
+		class = systemField(e.ErrLetter, ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME)
+	}
+
+	ae.mu.Lock()
+	defer ae.mu.Unlock()
+
+	for i := range ae.rules {
+		rule := &ae.rules[i]
+		if !e.Level.IsEnabledFor(rule.MinLevel) || !ruleMatchesClass(rule, class) {
+			continue
+		}
+
+		// Sliding window: forget the entries that are out of it.
+		matched := append(ae.matched[i], e.Time)
+		from := 0
+		for from < len(matched) && e.Time.Sub(matched[from]) >= rule.Window {
+			from++
+		}
+		matched = matched[from:]
+
+		if len(matched) <= rule.Threshold {
+			ae.matched[i] = matched
+			continue
+		}
+
+		// The alert is triggered, the counting starts anew.
+		ae.matched[i] = matched[:0]
+
+		a := &alert{rule: rule, entry: e, class: class, message: entryMessage(e)}
+		if e.ErrLetter != nil {
+			a.errorID = systemField(e.ErrLetter, ekaletter.KIND_SYS_TYPE_EKAERR_UUID)
+		}
+		a.dedupKey = rule.Name + "\x00" + class + "\x00" + a.message
+
+		if !ae.dedup(a) {
+			return nil
+		}
+
+		return a
+	}
+
+	return nil
+}
+
+// dedup reports whether alert 'a' must be sent, saving the number of suppressed
+// duplicates to it, or counts it as a suppressed duplicate.
+// Requires Encoder to be locked.
+func (ae *Encoder) dedup(a *alert) bool {
+
+	cooldown := ENCODER_DEFAULT_COOLDOWN
+	if ae.cooldownSet {
+		cooldown = ae.cooldown
+	}
+
+	if cooldown <= 0 {
+		return true
+	}
+
+	if ae.sent == nil {
+		ae.sent = make(map[string]time.Time)
+		ae.dropped = make(map[string]int)
+	}
+
+	now := a.entry.Time
+	if last, ok := ae.sent[a.dedupKey]; ok && now.Sub(last) < cooldown {
+		ae.dropped[a.dedupKey]++
+		return false
+	}
+
+	// Forget the expired keys, so they won't be accumulated forever.
+	for key, last := range ae.sent {
+		if now.Sub(last) >= cooldown {
+			delete(ae.sent, key)
+			if key != a.dedupKey {
+				delete(ae.dropped, key)
+			}
+		}
+	}
+
+	ae.sent[a.dedupKey] = now
+	a.suppressed = ae.dropped[a.dedupKey]
+	delete(ae.dropped, a.dedupKey)
+
+	return true
+}
+
+// encode returns the webhook's payload of alert 'a' of the Encoder's format.
+func (ae *Encoder) encode(a *alert) []byte {
+
+	source := ae.source
+	if source == "" {
+		source = hostname
+	}
+
+	title := "[" + strings.ToUpper(a.entry.Level.String()) + "] " + a.rule.Name
+	if a.class != "" {
+		title += ": " + a.class
+	}
+
+	var text strings.Builder
+	text.WriteString(a.message)
+	if a.errorID != "" {
+		text.WriteString(" (error ID: " + a.errorID + ")")
+	}
+	text.WriteString("; source: " + source)
+	if a.suppressed > 0 {
+		text.WriteString("; " + strconv.Itoa(a.suppressed) + " similar alert(s) suppressed")
+	}
+
+	var payload any
+
+	switch ae.format {
+
+	case FORMAT_TEAMS:
+		payload = teamsPayload{
+			Type:       "MessageCard",
+			Context:    "https://schema.org/extensions",
+			ThemeColor: themeColor(a.entry.Level),
+			Summary:    title,
+			Title:      title,
+			Text:       text.String(),
+		}
+
+	case FORMAT_PAGERDUTY:
+		pd := pagerDutyPayload{
+			RoutingKey:  ae.routingKey,
+			EventAction: "trigger",
+		}
+		sum := sha1.Sum([]byte(a.dedupKey))
+		pd.DedupKey = hex.EncodeToString(sum[:])
+		pd.Payload.Summary = title + ": " + a.message
+		pd.Payload.Source = source
+		pd.Payload.Severity = severity(a.entry.Level)
+		pd.Payload.Timestamp = a.entry.Time.UTC().Format(time.RFC3339Nano)
+		pd.Payload.Component = a.rule.Name
+		pd.Payload.Class = a.class
+		pd.Payload.CustomDetails = customDetails(a)
+		payload = pd
+
+	default:
+		payload = slackPayload{Text: "*" + title + "*\n" + text.String()}
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil
+	}
+
+	return encoded
+}
+
+// ruleMatchesClass reports whether ekaerr.Error's class with 'className'
+// (full name) is any of Rule's classes or their subclasses.
+func ruleMatchesClass(rule *Rule, className string) bool {
+
+	if len(rule.Classes) == 0 {
+		return true
+	}
+
+	for _, cls := range rule.Classes {
+		fullName := cls.FullName()
+		if fullName != "" && (className == fullName || strings.HasPrefix(className, fullName+".")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// entryMessage returns log entry's message or (if it's empty)
+// the last message of attached ekaerr.Error.
+func entryMessage(e *ekalog.Entry) string {
+
+	if message := e.LogLetter.Messages[0].Body; message != "" || e.ErrLetter == nil {
+		return message
+	}
+
+	for i := len(e.ErrLetter.Messages) - 1; i >= 0; i-- {
+		if e.ErrLetter.Messages[i].Body != "" {
+			return e.ErrLetter.Messages[i].Body
+		}
+	}
+
+	return ""
+}
+
+// customDetails returns the fields of alert's log entry (and of attached
+// ekaerr.Error) as PagerDuty's event custom details.
+func customDetails(a *alert) map[string]any {
+
+	details := map[string]any{"rule": a.rule.Name}
+	if a.errorID != "" {
+		details["error_id"] = a.errorID
+	}
+	if a.suppressed > 0 {
+		details["suppressed"] = a.suppressed
+	}
+
+	add := func(fields []ekaletter.LetterField) {
+		var unnamedFieldIdx int16
+		for i, n := 0, len(fields); i < n; i++ {
+			if f := &fields[i]; !f.IsSystem() && !f.IsInvalid() {
+				details[f.KeyOrUnnamed(&unnamedFieldIdx)] = fieldValue(f)
+			}
+		}
+	}
+
+	if a.entry.ErrLetter != nil {
+		add(a.entry.ErrLetter.Fields)
+	}
+	add(a.entry.LogLetter.Fields)
+
+	return details
+}
+
+// systemField returns a string value of ekaletter.Letter's system field
+// with provided 'baseType' or an empty string if there is no such field.
+func systemField(l *ekaletter.Letter, baseType ekaletter.LetterFieldKind) string {
+	for i, n := 0, len(l.SystemFields); i < n; i++ {
+		if l.SystemFields[i].BaseType() == baseType {
+			return l.SystemFields[i].SValue
+		}
+	}
+	return ""
+}
+
+// fieldValue returns a value of ekaletter.LetterField, that can be encoded
+// to JSON by the most natural way.
+func fieldValue(f *ekaletter.LetterField) any {
+
+	if f.Kind.IsNil() {
+		return nil
+	}
+
+	switch f.Kind.BaseType() {
+
+	case ekaletter.KIND_TYPE_BOOL:
+		return f.IValue != 0
+
+	case ekaletter.KIND_TYPE_INT,
+		ekaletter.KIND_TYPE_INT_8, ekaletter.KIND_TYPE_INT_16,
+		ekaletter.KIND_TYPE_INT_32, ekaletter.KIND_TYPE_INT_64:
+		return f.IValue
+
+	case ekaletter.KIND_TYPE_UINT,
+		ekaletter.KIND_TYPE_UINT_8, ekaletter.KIND_TYPE_UINT_16,
+		ekaletter.KIND_TYPE_UINT_32, ekaletter.KIND_TYPE_UINT_64:
+		return uint64(f.IValue)
+
+	case ekaletter.KIND_TYPE_FLOAT_32:
+		return math.Float32frombits(uint32(f.IValue))
+
+	case ekaletter.KIND_TYPE_FLOAT_64:
+		return math.Float64frombits(uint64(f.IValue))
+
+	case ekaletter.KIND_TYPE_STRING:
+		return f.SValue
+
+	case ekaletter.KIND_TYPE_DURATION:
+		return time.Duration(f.IValue).String()
+
+	default:
+		return fmt.Sprint(f.Value)
+	}
+}
+
+// severity returns PagerDuty's severity of ekalog.Level.
+func severity(l ekalog.Level) string {
+	switch l = l.Base(); {
+	case l <= ekalog.LEVEL_CRITICAL:
+		return "critical"
+	case l == ekalog.LEVEL_ERROR:
+		return "error"
+	case l == ekalog.LEVEL_WARNING:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// themeColor returns Microsoft Teams' MessageCard theme color of ekalog.Level.
+func themeColor(l ekalog.Level) string {
+	switch l = l.Base(); {
+	case l <= ekalog.LEVEL_CRITICAL:
+		return "8B0000"
+	case l == ekalog.LEVEL_ERROR:
+		return "FF0000"
+	case l == ekalog.LEVEL_WARNING:
+		return "FFA500"
+	default:
+		return "808080"
+	}
+}
+
+// worker sends enqueued alerts until Writer is closed.
+func (w *Writer) worker() {
+	for payload := range w.queue {
+		if err := w.sendWithRetry(payload); err != nil {
+			w.lastErrM.Lock()
+			w.lastErr = err
+			w.lastErrM.Unlock()
+		}
+		w.pending.Done()
+	}
+}
+
+// sendWithRetry sends alert 'payload', retrying the failed request
+// with exponential backoff. Returns the last error.
+func (w *Writer) sendWithRetry(payload []byte) error {
+
+	backoff := w.backoff
+	for attempt := 0; ; attempt++ {
+		retryable, err := w.send(payload)
+		if err == nil || !retryable || attempt >= w.retries {
+			return err
+		}
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// send sends alert 'payload' to the webhook's URL.
+// Reports whether the failed request may be retried.
+func (w *Writer) send(payload []byte) (retryable bool, err error) {
+
+	req, err := http.NewRequest(http.MethodPost, w.url, bytes.NewReader(payload))
+	if err != nil {
+		return false, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return true, err
+	}
+
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		retryable = resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+		return retryable, fmt.Errorf("ekalog/alert: unexpected response status %q", resp.Status)
+	}
+
+	return false, nil
+}
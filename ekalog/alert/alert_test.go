@@ -0,0 +1,140 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package alert_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekalog/alert"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testAlerts(t *testing.T, ae *alert.Encoder, cb func()) []map[string]any {
+
+	var (
+		mu       sync.Mutex
+		payloads []map[string]any
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]any
+		_ = json.Unmarshal(body, &payload)
+
+		mu.Lock()
+		payloads = append(payloads, payload)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(ae).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(alert.NewWriter(srv.URL))
+
+	ekalog.ReplaceIntegrator(ci)
+	cb()
+
+	require.NoError(t, ci.Close(context.Background()))
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return payloads
+}
+
+func TestEncoder_Slack(t *testing.T) {
+
+	ae := new(alert.Encoder).
+		SetSource("test").
+		SetCooldown(100 * time.Millisecond).
+		AddRule(alert.Rule{
+			Name:      "args",
+			MinLevel:  ekalog.LEVEL_ERROR,
+			Classes:   []ekaerr.Class{ekaerr.IllegalArgument},
+			Threshold: 2,
+		})
+
+	payloads := testAlerts(t, ae, func() {
+		ekalog.Warne("not enough important", ekaerr.IllegalArgument.New("bad value"))
+		ekalog.Errore("another class", ekaerr.NotFound.New("not found"))
+
+		ekalog.Errore("", ekaerr.IllegalArgument.New("bad value"))
+		ekalog.Errore("", ekaerr.IllegalArgument.New("bad value"))
+		ekalog.Errore("", ekaerr.IllegalArgument.New("bad value")) // triggers
+
+		for i := 0; i < 3*3; i++ {
+			ekalog.Errore("", ekaerr.IllegalArgument.New("bad value")) // suppressed
+		}
+
+		time.Sleep(150 * time.Millisecond)
+		for i := 0; i < 3; i++ {
+			ekalog.Errore("", ekaerr.IllegalArgument.New("bad value")) // triggers
+		}
+	})
+
+	require.Len(t, payloads, 2)
+
+	className := ekaerr.IllegalArgument.FullName()
+	assert.Contains(t, payloads[0]["text"], "*[ERROR] args: "+className+"*\nbad value (error ID: ")
+	assert.Contains(t, payloads[0]["text"], "; source: test")
+	assert.NotContains(t, payloads[0]["text"], "suppressed")
+	assert.Contains(t, payloads[1]["text"], "; 3 similar alert(s) suppressed")
+}
+
+func TestEncoder_PagerDuty(t *testing.T) {
+
+	ae := new(alert.Encoder).
+		SetFormat(alert.FORMAT_PAGERDUTY).
+		SetRoutingKey("key").
+		SetSource("test").
+		AddRule(alert.Rule{Name: "critical", MinLevel: ekalog.LEVEL_CRITICAL})
+
+	payloads := testAlerts(t, ae, func() {
+		ekalog.Error("not enough important")
+		ekalog.Crit("disk is full", "free", 0)
+	})
+
+	require.Len(t, payloads, 1)
+	assert.Equal(t, "key", payloads[0]["routing_key"])
+	assert.Equal(t, "trigger", payloads[0]["event_action"])
+	assert.NotEmpty(t, payloads[0]["dedup_key"])
+
+	payload := payloads[0]["payload"].(map[string]any)
+	assert.Equal(t, "[CRITICAL] critical: disk is full", payload["summary"])
+	assert.Equal(t, "critical", payload["severity"])
+	assert.Equal(t, "test", payload["source"])
+	assert.Equal(t, map[string]any{"rule": "critical", "free": float64(0)}, payload["custom_details"])
+}
+
+func TestWriter_Retry(t *testing.T) {
+
+	var calls int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	w := alert.NewWriter(srv.URL).SetRetry(2, time.Millisecond)
+	_, _ = w.Write([]byte(`{"text":"alert"}`))
+
+	require.NoError(t, w.Close(context.Background()))
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
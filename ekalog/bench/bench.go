@@ -0,0 +1,306 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+/*
+Package bench provides a harness, that measures encode+write throughput
+of ekalog on the current machine and suggests the sizes of buffers
+of ekalog.BufferedWriter and ekalog.ChannelIntegrator,
+so the services may auto-tune at startup:
+
+	r := bench.Run(bench.Config{
+	    Encoder:  new(ekalog.CI_JSONEncoder),
+	    Duration: 200 * time.Millisecond,
+	})
+	w := ekalog.NewBufferedWriter(file, r.SuggestedBufferSize, bench.CONFIG_DEFAULT_FLUSH_INTERVAL)
+*/
+package bench
+
+import (
+	"fmt"
+	"io"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Config is a configuration of the benchmark. Read more: Run().
+	// The zero values mean the defaults.
+	Config struct {
+
+		// Integrator is the measured Integrator. If it's set, Encoder and Writer
+		// are ignored and the written bytes are not counted
+		// (Result.Bytes and the suggestions based on them are 0).
+		// CommonIntegrator MUST NOT be registered with another Logger.
+		Integrator ekalog.Integrator
+
+		// Encoder is an encoder of CommonIntegrator, that is created
+		// if Integrator is not set. By default, it's ekalog.CI_JSONEncoder.
+		// It MUST NOT be shared with another Integrator.
+		Encoder ekalog.CI_Encoder
+
+		// Writer is an io.Writer of CommonIntegrator, that is created
+		// if Integrator is not set. By default, it's io.Discard.
+		Writer io.Writer
+
+		// Duration is how long the benchmark runs.
+		// By default, it's CONFIG_DEFAULT_DURATION.
+		Duration time.Duration
+
+		// Goroutines is how much goroutines log concurrently. By default, it's 1.
+		Goroutines int
+
+		// Fields is how much fields each log entry has.
+		// By default, it's CONFIG_DEFAULT_FIELDS. Negative means no fields.
+		Fields int
+
+		// FlushInterval is the desired interval of BufferedWriter's flushes
+		// (and of ChannelIntegrator's consumer's reads) under the maximum load.
+		// The suggestions are based on it.
+		// By default, it's CONFIG_DEFAULT_FLUSH_INTERVAL.
+		FlushInterval time.Duration
+	}
+
+	// Result is a result of the benchmark.
+	Result struct {
+		Goroutines int
+		Elapsed    time.Duration
+
+		// Entries is how much log entries are written.
+		Entries uint64
+
+		// Bytes is how much bytes are written. It's 0 if Config.Integrator is set.
+		Bytes uint64
+
+		EntriesPerSec float64
+		BytesPerSec   float64
+
+		// NsPerEntry is an average time of one log entry per goroutine.
+		NsPerEntry float64
+
+		// AvgEntrySize is an average size of the encoded log entry in bytes.
+		AvgEntrySize int
+
+		// AllocsPerEntry, AllocBytesPerEntry are the average number
+		// and size of heap allocations per log entry.
+		AllocsPerEntry     float64
+		AllocBytesPerEntry float64
+
+		// SuggestedBufferSize is a suggested size of ekalog.BufferedWriter,
+		// that is filled in Config.FlushInterval under the measured load,
+		// rounded up to the power of 2 within [4 KiB, 4 MiB].
+		// It's 0 if Bytes is 0.
+		SuggestedBufferSize int
+
+		// SuggestedChannelBuffer is a suggested buffer's size of
+		// ekalog.ChannelIntegrator, that holds all entries of
+		// Config.FlushInterval under the measured load,
+		// rounded up to the power of 2 within [64, 65536].
+		SuggestedChannelBuffer int
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// CONFIG_DEFAULT_DURATION is a default Config.Duration.
+	CONFIG_DEFAULT_DURATION = time.Second
+
+	// CONFIG_DEFAULT_FIELDS is a default Config.Fields.
+	CONFIG_DEFAULT_FIELDS = 8
+
+	// CONFIG_DEFAULT_FLUSH_INTERVAL is a default Config.FlushInterval.
+	CONFIG_DEFAULT_FLUSH_INTERVAL = 100 * time.Millisecond
+)
+
+// Run runs the benchmark: Config.Goroutines goroutines log the entries
+// of LEVEL_INFO with Config.Fields fields through Config.Integrator
+// (or CommonIntegrator of Config.Encoder and Config.Writer)
+// as fast as they can during Config.Duration.
+//
+// The benchmark doesn't affect the package-level Logger.
+// Run() blocks for Config.Duration.
+func Run(cfg Config) Result {
+
+	cfg = cfg.withDefaults()
+
+	var cw *countingWriter
+	integrator := cfg.Integrator
+
+	if integrator == nil {
+		cw = &countingWriter{w: cfg.Writer}
+		integrator = new(ekalog.CommonIntegrator).
+			WithEncoder(cfg.Encoder).
+			WithMinLevel(ekalog.LEVEL_DEBUG).
+			WriteTo(cw)
+	}
+
+	var (
+		l       = ekalog.WithIntegrator(integrator)
+		entries uint64
+		stop    uint32
+		wg      sync.WaitGroup
+		before  runtime.MemStats
+		after   runtime.MemStats
+	)
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+
+	for i := 0; i < cfg.Goroutines; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var (
+				n      = uint64(0)
+				fields []ekaletter.LetterField
+			)
+			for atomic.LoadUint32(&stop) == 0 {
+				// Logger takes the ownership of explicit fields
+				// and resets them when Entry is released,
+				// so they're built for each entry, the same way the callers do.
+				fields = appendBenchFields(fields[:0], cfg.Fields)
+				l.Logw(ekalog.LEVEL_INFO, "benchmark log entry", fields...)
+				n++
+			}
+			atomic.AddUint64(&entries, n)
+		}()
+	}
+
+	time.Sleep(cfg.Duration)
+	atomic.StoreUint32(&stop, 1)
+	wg.Wait()
+
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	_ = l.Sync()
+
+	r := Result{
+		Goroutines: cfg.Goroutines,
+		Elapsed:    elapsed,
+		Entries:    entries,
+	}
+
+	if cw != nil {
+		r.Bytes = atomic.LoadUint64(&cw.n)
+	}
+
+	r.calculate(cfg, after.Mallocs-before.Mallocs, after.TotalAlloc-before.TotalAlloc)
+	return r
+}
+
+// String returns a human-readable summary of Result.
+func (r Result) String() string {
+	return fmt.Sprintf(
+		"%d entries in %s by %d goroutine(s): %.0f entries/s, %.0f B/s, "+
+			"%.0f ns/entry, %d B/entry, %.1f allocs/entry, %.0f alloc B/entry; "+
+			"suggested buffer: %d B, suggested channel buffer: %d",
+		r.Entries, r.Elapsed, r.Goroutines, r.EntriesPerSec, r.BytesPerSec,
+		r.NsPerEntry, r.AvgEntrySize, r.AllocsPerEntry, r.AllocBytesPerEntry,
+		r.SuggestedBufferSize, r.SuggestedChannelBuffer)
+}
+
+// ---------------------------------------------------------------------------- //
+
+type (
+	// countingWriter is an io.Writer wrapper, that counts written bytes.
+	countingWriter struct {
+		w io.Writer
+		n uint64 // atomic access only
+	}
+)
+
+// Write writes 'p' to the wrapped io.Writer, counting written bytes.
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.w.Write(p)
+	atomic.AddUint64(&cw.n, uint64(n))
+	return n, err
+}
+
+// withDefaults returns Config, the zero values of which are replaced
+// by the default ones.
+func (cfg Config) withDefaults() Config {
+	if cfg.Encoder == nil {
+		cfg.Encoder = new(ekalog.CI_JSONEncoder)
+	}
+	if cfg.Writer == nil {
+		cfg.Writer = io.Discard
+	}
+	if cfg.Duration <= 0 {
+		cfg.Duration = CONFIG_DEFAULT_DURATION
+	}
+	if cfg.Goroutines <= 0 {
+		cfg.Goroutines = 1
+	}
+	if cfg.Fields == 0 {
+		cfg.Fields = CONFIG_DEFAULT_FIELDS
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = CONFIG_DEFAULT_FLUSH_INTERVAL
+	}
+	return cfg
+}
+
+// calculate fills the derived values of Result.
+func (r *Result) calculate(cfg Config, allocs, allocBytes uint64) {
+
+	if r.Entries == 0 || r.Elapsed <= 0 {
+		return
+	}
+
+	var (
+		seconds = r.Elapsed.Seconds()
+		entries = float64(r.Entries)
+	)
+
+	r.EntriesPerSec = entries / seconds
+	r.BytesPerSec = float64(r.Bytes) / seconds
+	r.NsPerEntry = float64(r.Elapsed.Nanoseconds()) * float64(r.Goroutines) / entries
+	r.AvgEntrySize = int(float64(r.Bytes) / entries)
+	r.AllocsPerEntry = float64(allocs) / entries
+	r.AllocBytesPerEntry = float64(allocBytes) / entries
+
+	if r.Bytes > 0 {
+		r.SuggestedBufferSize = roundUpPow2(
+			int(r.BytesPerSec*cfg.FlushInterval.Seconds()), 4<<10, 4<<20)
+	}
+
+	r.SuggestedChannelBuffer = roundUpPow2(
+		int(r.EntriesPerSec*cfg.FlushInterval.Seconds()), 64, 65536)
+}
+
+// roundUpPow2 returns 'n' rounded up to the power of 2 within [min, max].
+// 'min' and 'max' must be the powers of 2.
+func roundUpPow2(n, min, max int) int {
+	v := min
+	for v < n && v < max {
+		v <<= 1
+	}
+	return v
+}
+
+// appendBenchFields appends 'n' fields of different types to 'fields'
+// and returns the extended slice.
+func appendBenchFields(fields []ekaletter.LetterField, n int) []ekaletter.LetterField {
+	for i := 0; i < n; i++ {
+		key := "field_" + string(rune('a'+i%26))
+		switch i % 4 {
+		case 0:
+			fields = append(fields, ekaletter.FString(key, "some string value"))
+		case 1:
+			fields = append(fields, ekaletter.FInt64(key, int64(i)*1000003))
+		case 2:
+			fields = append(fields, ekaletter.FDuration(key, time.Duration(i)*time.Millisecond))
+		case 3:
+			fields = append(fields, ekaletter.FBool(key, i%2 == 0))
+		}
+	}
+
+	return fields
+}
@@ -0,0 +1,87 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package bench_test
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekalog/bench"
+)
+
+type syncBuffer struct {
+	mu sync.Mutex
+	b  bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.b.Write(p)
+}
+
+func (sb *syncBuffer) Len() int {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.b.Len()
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.b.String()
+}
+
+func TestRun(t *testing.T) {
+	var w syncBuffer
+
+	r := bench.Run(bench.Config{
+		Writer:     &w,
+		Duration:   50 * time.Millisecond,
+		Goroutines: 2,
+	})
+
+	require.NotZero(t, r.Entries)
+	require.EqualValues(t, w.Len(), r.Bytes)
+	require.NotZero(t, r.EntriesPerSec)
+	require.NotZero(t, r.AvgEntrySize)
+	require.GreaterOrEqual(t, r.SuggestedBufferSize, 4<<10)
+	require.LessOrEqual(t, r.SuggestedBufferSize, 4<<20)
+	require.GreaterOrEqual(t, r.SuggestedChannelBuffer, 64)
+	require.LessOrEqual(t, r.SuggestedChannelBuffer, 65536)
+	require.Zero(t, r.SuggestedBufferSize&(r.SuggestedBufferSize-1))
+	require.Zero(t, r.SuggestedChannelBuffer&(r.SuggestedChannelBuffer-1))
+
+	// Each entry has its own fields, not the reset ones.
+	out := w.String()
+	require.EqualValues(t, r.Entries, strings.Count(out, `"field_a"`))
+	require.EqualValues(t, r.Entries, strings.Count(out, `"field_h"`))
+	require.NotContains(t, out, `""`)
+
+	t.Log(r)
+}
+
+func TestRun_Integrator(t *testing.T) {
+	ci := ekalog.NewChannelIntegrator(1 << 16)
+	defer ci.Close()
+
+	r := bench.Run(bench.Config{
+		Integrator: ci,
+		Duration:   20 * time.Millisecond,
+		Fields:     -1,
+	})
+
+	require.NotZero(t, r.Entries)
+	require.Zero(t, r.Bytes)
+	require.Zero(t, r.SuggestedBufferSize)
+	require.NotZero(t, r.SuggestedChannelBuffer)
+}
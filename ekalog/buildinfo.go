@@ -0,0 +1,71 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"github.com/qioalice/ekago/v3/ekasys"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// FieldPreEncoder is an interface of all, that may pre-encode fields,
+	// like Integrator, CI_Encoder (CI_ConsoleEncoder, CI_JSONEncoder), etc.
+	FieldPreEncoder interface {
+		PreEncodeField(f ekaletter.LetterField)
+	}
+)
+
+// PreEncodeBuildInfo pre-encodes the build information and the environment
+// (see ekasys.BuildInfo()) as fields by 'to', so they're attached to each Entry:
+//   - "build.module", "build.version" (if the binary is built with module support);
+//   - "build.revision", "build.time", "build.modified" (if the binary has VCS stamping);
+//   - "build.go", "build.os", "build.arch";
+//   - "host.name" (if it's known), "container.id" (if it's a container).
+//
+// Does nothing if 'to' is nil. The same restrictions as for PreEncodeField()
+// of 'to' are applied (e.g. CI_Encoder must be registered in CommonIntegrator).
+func PreEncodeBuildInfo(to FieldPreEncoder) {
+	if to != nil {
+		for _, f := range buildInfoFields(ekasys.BuildInfo()) {
+			to.PreEncodeField(f)
+		}
+	}
+}
+
+// buildInfoFields returns fields for 'be'. Read more: PreEncodeBuildInfo().
+func buildInfoFields(be ekasys.BuildEnvironment) []ekaletter.LetterField {
+
+	fs := make([]ekaletter.LetterField, 0, 10)
+
+	if be.ModulePath != "" {
+		fs = append(fs,
+			ekaletter.FString("build.module", be.ModulePath),
+			ekaletter.FString("build.version", be.ModuleVersion),
+		)
+	}
+	if be.VCSRevision != "" {
+		fs = append(fs,
+			ekaletter.FString("build.revision", be.VCSRevision),
+			ekaletter.FUnixFromStd("build.time", be.VCSTime),
+			ekaletter.FBool("build.modified", be.VCSModified),
+		)
+	}
+
+	fs = append(fs,
+		ekaletter.FString("build.go", be.GoVersion),
+		ekaletter.FString("build.os", be.GOOS),
+		ekaletter.FString("build.arch", be.GOARCH),
+	)
+
+	if be.Hostname != "" {
+		fs = append(fs, ekaletter.FString("host.name", be.Hostname))
+	}
+	if be.ContainerID != "" {
+		fs = append(fs, ekaletter.FString("container.id", be.ContainerID))
+	}
+
+	return fs
+}
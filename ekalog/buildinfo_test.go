@@ -0,0 +1,43 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"runtime"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPreEncodeBuildInfo(t *testing.T) {
+
+	b := bytes.NewBuffer(nil)
+	je := new(ekalog.CI_JSONEncoder)
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(je).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(b)
+
+	ekalog.ReplaceIntegrator(ci)
+	ekalog.PreEncodeBuildInfo(je)
+	ekalog.PreEncodeBuildInfo(nil)
+
+	ekalog.Info("started")
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(b.Bytes(), &out))
+	require.IsType(t, map[string]any{}, out["fields"])
+
+	fields := out["fields"].(map[string]any)
+	assert.Equal(t, runtime.GOOS, fields["build.os"])
+	assert.Equal(t, runtime.GOARCH, fields["build.arch"])
+	assert.Contains(t, fields, "build.go")
+}
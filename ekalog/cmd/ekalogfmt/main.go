@@ -0,0 +1,87 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+// Command ekalogfmt pretty-prints JSON logs, encoded by ekalog.CI_JSONEncoder.
+// It reads JSON encoded entries (one per line) from the files passed as arguments
+// (or from stdin if there are none) and writes them to stdout re-encoded
+// by ekalog.CI_ConsoleEncoder. The lines, that are not JSON encoded entries,
+// are written "as is".
+//
+// Usage:
+//
+//	ekalogfmt [-format <format>] [-theme <name>] [-colors <colors>] [file ...]
+//
+// Read more about the format string: ekalog.CI_ConsoleEncoder.SetFormat().
+// Colors are: "auto" (detected by ekalog.CICE_DetectColors(), the default one),
+// "none", "ascii", "x256", "truecolor".
+//
+// Example:
+//
+//	./service 2>&1 | ekalogfmt -theme default
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+)
+
+func main() {
+
+	format := flag.String("format", "", "format string of console encoder (default is console encoder's one)")
+	theme := flag.String("theme", "", "name of console encoder's theme")
+	colors := flag.String("colors", "auto", "colors: auto, none, ascii, x256, truecolor")
+	flag.Parse()
+
+	ce := new(ekalog.CI_ConsoleEncoder).SetFormat(*format).SetTheme(*theme)
+
+	switch strings.ToLower(*colors) {
+	case "auto":
+		ce.SetColors(ekalog.CICE_DetectColors())
+	case "none":
+		ce.SetColors(ekalog.CICE_COLORS_NONE)
+	case "ascii":
+		ce.SetColors(ekalog.CICE_COLORS_ASCII)
+	case "x256":
+		ce.SetColors(ekalog.CICE_COLORS_X256)
+	case "truecolor":
+		ce.SetColors(ekalog.CICE_COLORS_TRUECOLOR)
+	default:
+		fmt.Fprintf(os.Stderr, "ekalogfmt: unknown colors %q\n", *colors)
+		os.Exit(2)
+	}
+
+	jd := new(ekalog.CI_JSONDecoder)
+
+	if flag.NArg() == 0 {
+		exitIfError(jd.ReencodeStream(os.Stdin, os.Stdout, ce))
+		return
+	}
+
+	for _, path := range flag.Args() {
+		f, err := os.Open(path)
+		exitIfError(err)
+		exitIfError(reencodeFile(jd, f, os.Stdout, ce))
+	}
+}
+
+// reencodeFile is the same as ekalog.CI_JSONDecoder.ReencodeStream()
+// but closes 'f' when it's done.
+func reencodeFile(jd *ekalog.CI_JSONDecoder, f *os.File, w io.Writer, ce ekalog.CI_Encoder) error {
+	defer f.Close()
+	return jd.ReencodeStream(f, w, ce)
+}
+
+// exitIfError prints 'err' to stderr and exits with code 1 if it's not nil.
+func exitIfError(err error) {
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "ekalogfmt:", err)
+		os.Exit(1)
+	}
+}
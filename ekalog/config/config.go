@@ -0,0 +1,260 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package config
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"gopkg.in/yaml.v3"
+)
+
+type (
+	// Config is a declarative description of ekalog.CommonIntegrator.
+	//
+	// It may be parsed from YAML or JSON using Parse(), ParseFile()
+	// or constructed from environment variables using FromEnv().
+	// Example (YAML):
+	//
+	//	deduplication: 5s
+	//	outputs:
+	//	  - encoder:
+	//	      type: console
+	//	      format: "{{l}} {{t}} {{m}}\n"
+	//	      colors:
+	//	        error: "fg:red/b"
+	//	    level: debug
+	//	    writers: [stdout]
+	//	  - encoder:
+	//	      type: json
+	//	      indent: 2
+	//	    level: warning
+	//	    stacktrace_level: error
+	//	    writers: ["file://${LOG_DIR:-/var/log}/app.log"]
+	//
+	// Each Output is an encoder + writers pair, like
+	// CommonIntegrator.WithEncoder() ... WriteTo() calls chain is.
+	Config struct {
+
+		// Outputs is a set of encoders and their writers.
+		// At least one Output is required.
+		Outputs []Output `yaml:"outputs" json:"outputs"`
+
+		// Deduplication is a window of CommonIntegrator.WithDeduplication()
+		// in time.ParseDuration() format. Empty means disabled.
+		Deduplication string `yaml:"deduplication" json:"deduplication"`
+	}
+
+	// Output is a part of Config that describes one encoder and its writers.
+	Output struct {
+
+		// Encoder describes an encoder all Writers are used with.
+		Encoder Encoder `yaml:"encoder" json:"encoder"`
+
+		// Level is a minimum level of log entries being written (ekalog.ParseLevel()).
+		// Empty means "debug".
+		Level string `yaml:"level" json:"level"`
+
+		// StacktraceLevel is a minimum level of log entries stacktrace is added to.
+		// Empty means "warning".
+		StacktraceLevel string `yaml:"stacktrace_level" json:"stacktrace_level"`
+
+		// Writers are the destinations of encoded log entries. Allowed values:
+		//   - "stdout", "stderr";
+		//   - "file:///path/to/file" or just "/path/to/file" (opened for appending,
+		//     created if not exist).
+		// Empty means "stdout".
+		Writers []string `yaml:"writers" json:"writers"`
+	}
+
+	// Encoder is a part of Output that describes its encoder.
+	Encoder struct {
+
+		// Type is "console" (default) or "json".
+		Type string `yaml:"type" json:"type"`
+
+		// Format is a CI_ConsoleEncoder's format. Console encoder only.
+		Format string `yaml:"format" json:"format"`
+
+		// Colors are CI_ConsoleEncoder's colors for levels. Console encoder only.
+		// Keys are levels' names, values are colors (see CI_ConsoleEncoder.SetColorFor()).
+		Colors map[string]string `yaml:"colors" json:"colors"`
+
+		// Indent is CI_JSONEncoder's indent. JSON encoder only.
+		Indent int `yaml:"indent" json:"indent"`
+
+		// OneDepthLevel is CI_JSONEncoder's one depth level mode. JSON encoder only.
+		OneDepthLevel bool `yaml:"one_depth_level" json:"one_depth_level"`
+
+		// HTMLSafe is CI_JSONEncoder's HTML safe mode. JSON encoder only.
+		HTMLSafe bool `yaml:"html_safe" json:"html_safe"`
+
+		// NestedKeys is CI_JSONEncoder's nested keys mode. JSON encoder only.
+		NestedKeys bool `yaml:"nested_keys" json:"nested_keys"`
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	ENCODER_TYPE_CONSOLE = "console"
+	ENCODER_TYPE_JSON    = "json"
+
+	WRITER_STDOUT = "stdout"
+	WRITER_STDERR = "stderr"
+)
+
+// Parse parses Config from YAML or JSON (which is a YAML subset) 'data',
+// expanding environment variables before. See ExpandEnv() for more details.
+// Parsed Config is validated (see Config.Validate()).
+func Parse(data []byte) (*Config, error) {
+
+	cfg := new(Config)
+	if err := yaml.Unmarshal([]byte(ExpandEnv(string(data))), cfg); err != nil {
+		return nil, &Error{Err: err}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// ParseFile is the same as Parse() but reads data from the file at 'path'.
+func ParseFile(path string) (*Config, error) {
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, &Error{Err: err}
+	}
+
+	return Parse(data)
+}
+
+// FromEnv constructs a Config with the one Output from environment variables
+// with the provided 'prefix' (e.g. "EKALOG"):
+//
+//   - <prefix>_ENCODER: encoder's type;
+//   - <prefix>_FORMAT: console encoder's format;
+//   - <prefix>_INDENT: JSON encoder's indent;
+//   - <prefix>_LEVEL: minimum level;
+//   - <prefix>_STACKTRACE_LEVEL: minimum level for stacktrace;
+//   - <prefix>_WRITERS: comma separated writers;
+//   - <prefix>_DEDUPLICATION: deduplication window.
+//
+// Constructed Config is validated (see Config.Validate()).
+func FromEnv(prefix string) (*Config, error) {
+
+	env := func(name string) string {
+		return os.Getenv(prefix + "_" + name)
+	}
+
+	cfg := &Config{
+		Outputs: []Output{{
+			Encoder: Encoder{
+				Type:   env("ENCODER"),
+				Format: env("FORMAT"),
+			},
+			Level:           env("LEVEL"),
+			StacktraceLevel: env("STACKTRACE_LEVEL"),
+		}},
+		Deduplication: env("DEDUPLICATION"),
+	}
+
+	if indent := env("INDENT"); indent != "" {
+		var err error
+		if cfg.Outputs[0].Encoder.Indent, err = strconv.Atoi(indent); err != nil {
+			return nil, &Error{Key: prefix + "_INDENT", Err: err}
+		}
+	}
+
+	for _, writer := range strings.Split(env("WRITERS"), ",") {
+		if writer = strings.TrimSpace(writer); writer != "" {
+			cfg.Outputs[0].Writers = append(cfg.Outputs[0].Writers, writer)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
+
+// ExpandEnv replaces ${VAR}, $VAR in the 's' by the values
+// of the corresponding environment variables.
+// ${VAR:-default} form is also supported: "default" is used if VAR is empty.
+func ExpandEnv(s string) string {
+	return os.Expand(s, func(name string) string {
+		name, defaultValue, hasDefault := strings.Cut(name, ":-")
+		if value := os.Getenv(name); value != "" || !hasDefault {
+			return value
+		}
+		return defaultValue
+	})
+}
+
+// Validate reports whether Config is valid.
+// Returned error is *Error that points to the invalid Config's key.
+func (c *Config) Validate() error {
+
+	if len(c.Outputs) == 0 {
+		return &Error{Key: "outputs", Err: errNoOutputs}
+	}
+
+	if c.Deduplication != "" {
+		if _, err := time.ParseDuration(c.Deduplication); err != nil {
+			return &Error{Key: "deduplication", Err: err}
+		}
+	}
+
+	for i := range c.Outputs {
+		if err := c.Outputs[i].validate(outputKey(i)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Build validates Config and builds a new ekalog.CommonIntegrator using it.
+// The returned ekalog.CommonIntegrator is not registered yet,
+// use ekalog.ReplaceIntegrator() or Logger.ReplaceIntegrator() to do that.
+//
+// If some error occurred, all already opened files are closed.
+func (c *Config) Build() (*ekalog.CommonIntegrator, error) {
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	var (
+		ci     = new(ekalog.CommonIntegrator)
+		opened []*os.File
+	)
+
+	for i := range c.Outputs {
+		writers, err := c.Outputs[i].build(ci, outputKey(i))
+		opened = append(opened, writers...)
+		if err != nil {
+			for _, f := range opened {
+				_ = f.Close()
+			}
+			return nil, err
+		}
+	}
+
+	if c.Deduplication != "" {
+		window, _ := time.ParseDuration(c.Deduplication)
+		ci = ci.WithDeduplication(window)
+	}
+
+	return ci, nil
+}
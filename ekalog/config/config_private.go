@@ -0,0 +1,172 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+)
+
+var (
+	errNoOutputs      = errors.New("at least one output is required")
+	errNoFilePath     = errors.New("file path is empty")
+	errNegativeIndent = errors.New("must not be negative")
+)
+
+// outputKey returns Config's key of the Output with index 'i'.
+func outputKey(i int) string {
+	return "outputs[" + strconv.Itoa(i) + "]"
+}
+
+// validate reports whether Output is valid.
+// 'key' is the Output's key in the Config.
+func (o *Output) validate(key string) error {
+
+	switch o.Encoder.Type {
+	case "", ENCODER_TYPE_CONSOLE, ENCODER_TYPE_JSON:
+	default:
+		return &Error{Key: key + ".encoder.type",
+			Err: fmt.Errorf("unknown encoder type %q", o.Encoder.Type)}
+	}
+
+	if o.Encoder.Indent < 0 {
+		return &Error{Key: key + ".encoder.indent", Err: errNegativeIndent}
+	}
+
+	for levelName := range o.Encoder.Colors {
+		if _, ok := ekalog.ParseLevel(levelName); !ok {
+			return &Error{Key: key + ".encoder.colors." + levelName,
+				Err: fmt.Errorf("unknown level %q", levelName)}
+		}
+	}
+
+	if _, err := parseLevel(o.Level, ekalog.LEVEL_DEBUG, key+".level"); err != nil {
+		return err
+	}
+	if _, err := parseLevel(o.StacktraceLevel, ekalog.LEVEL_WARNING, key+".stacktrace_level"); err != nil {
+		return err
+	}
+
+	for i, writer := range o.Writers {
+		if _, err := parseWriter(writer); err != nil {
+			return &Error{Key: key + ".writers[" + strconv.Itoa(i) + "]", Err: err}
+		}
+	}
+
+	return nil
+}
+
+// build registers Output's encoder and writers in the 'ci'.
+// Returns opened files even if error is occurred.
+func (o *Output) build(ci *ekalog.CommonIntegrator, key string) ([]*os.File, error) {
+
+	var encoder ekalog.CI_Encoder
+	switch o.Encoder.Type {
+
+	case ENCODER_TYPE_JSON:
+		encoder = new(ekalog.CI_JSONEncoder).
+			SetIndent(o.Encoder.Indent).
+			SetOneDepthLevel(o.Encoder.OneDepthLevel).
+			SetHTMLSafe(o.Encoder.HTMLSafe).
+			SetNestedKeys(o.Encoder.NestedKeys)
+
+	default:
+		ce := new(ekalog.CI_ConsoleEncoder)
+		if o.Encoder.Format != "" {
+			ce.SetFormat(o.Encoder.Format)
+		}
+		for levelName, color := range o.Encoder.Colors {
+			lvl, _ := ekalog.ParseLevel(levelName)
+			ce.SetColorFor(lvl, color)
+		}
+		encoder = ce
+	}
+
+	minLevel, _ := parseLevel(o.Level, ekalog.LEVEL_DEBUG, "")
+	stacktraceMinLevel, _ := parseLevel(o.StacktraceLevel, ekalog.LEVEL_WARNING, "")
+
+	var (
+		writers = make([]io.Writer, 0, len(o.Writers))
+		opened  []*os.File
+	)
+
+	for i, writerDesc := range o.Writers {
+		path, _ := parseWriter(writerDesc)
+
+		switch path {
+		case WRITER_STDOUT:
+			writers = append(writers, os.Stdout)
+		case WRITER_STDERR:
+			writers = append(writers, os.Stderr)
+		default:
+			f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0644)
+			if err != nil {
+				return opened, &Error{Key: key + ".writers[" + strconv.Itoa(i) + "]", Err: err}
+			}
+			opened = append(opened, f)
+			writers = append(writers, f)
+		}
+	}
+
+	if len(writers) == 0 {
+		writers = append(writers, os.Stdout)
+	}
+
+	ci.WithEncoder(encoder).
+		WithMinLevel(minLevel).
+		WithMinLevelForStackTrace(stacktraceMinLevel).
+		WriteTo(writers...)
+
+	return opened, nil
+}
+
+// parseLevel returns ekalog.Level by its name or 'defaultLevel' if it's empty.
+func parseLevel(levelName string, defaultLevel ekalog.Level, key string) (ekalog.Level, error) {
+
+	if levelName == "" {
+		return defaultLevel, nil
+	}
+
+	lvl, ok := ekalog.ParseLevel(levelName)
+	if !ok {
+		return 0, &Error{Key: key, Err: fmt.Errorf("unknown level %q", levelName)}
+	}
+
+	return lvl, nil
+}
+
+// parseWriter returns WRITER_STDOUT, WRITER_STDERR or a file path
+// the writer's description 'writer' points to.
+func parseWriter(writer string) (string, error) {
+
+	switch writer = strings.TrimSpace(writer); {
+
+	case writer == WRITER_STDOUT, writer == WRITER_STDERR:
+		return writer, nil
+
+	case strings.HasPrefix(writer, "file://"):
+		writer = strings.TrimPrefix(writer, "file://")
+		if writer == "" {
+			return "", errNoFilePath
+		}
+		return writer, nil
+
+	case strings.Contains(writer, "://"):
+		return "", fmt.Errorf("unsupported writer %q", writer)
+
+	case writer == "":
+		return "", errNoFilePath
+
+	default:
+		return writer, nil
+	}
+}
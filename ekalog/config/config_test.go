@@ -0,0 +1,88 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+
+	dir := t.TempDir()
+	require.NoError(t, os.Setenv("EKALOG_CONFIG_TEST_DIR", dir))
+	defer os.Unsetenv("EKALOG_CONFIG_TEST_DIR")
+
+	cfg, err := config.Parse([]byte(`
+deduplication: 5s
+outputs:
+  - encoder:
+      type: console
+      format: "{{m}}"
+      colors:
+        error: "fg:red"
+    level: info
+    writers: [stdout]
+  - encoder:
+      type: json
+      indent: 2
+    level: ${EKALOG_CONFIG_TEST_LEVEL:-warn}
+    writers: ["file://${EKALOG_CONFIG_TEST_DIR}/app.log"]
+`))
+	require.NoError(t, err)
+	require.Len(t, cfg.Outputs, 2)
+	assert.Equal(t, "warn", cfg.Outputs[1].Level)
+	assert.Equal(t, []string{"file://" + dir + "/app.log"}, cfg.Outputs[1].Writers)
+
+	ci, err := cfg.Build()
+	require.NoError(t, err)
+	require.NotNil(t, ci)
+	assert.FileExists(t, filepath.Join(dir, "app.log"))
+
+	cfg, err = config.Parse([]byte(`{"outputs": [{"encoder": {"type": "json"}, "level": "debug"}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, "json", cfg.Outputs[0].Encoder.Type)
+}
+
+func TestConfig_Validate(t *testing.T) {
+
+	_, err := config.Parse([]byte(`
+outputs:
+  - level: debug
+  - level: verbose
+`))
+
+	var cfgErr *config.Error
+	require.True(t, errors.As(err, &cfgErr))
+	assert.Equal(t, "outputs[1].level", cfgErr.Key)
+
+	_, err = config.Parse([]byte(`
+outputs:
+  - writers: [stdout, "http://localhost"]
+`))
+	require.True(t, errors.As(err, &cfgErr))
+	assert.Equal(t, "outputs[0].writers[1]", cfgErr.Key)
+}
+
+func TestFromEnv(t *testing.T) {
+
+	require.NoError(t, os.Setenv("EKALOG_TEST_ENCODER", "json"))
+	require.NoError(t, os.Setenv("EKALOG_TEST_WRITERS", "stdout, stderr"))
+	defer os.Unsetenv("EKALOG_TEST_ENCODER")
+	defer os.Unsetenv("EKALOG_TEST_WRITERS")
+
+	cfg, err := config.FromEnv("EKALOG_TEST")
+	require.NoError(t, err)
+	assert.Equal(t, "json", cfg.Outputs[0].Encoder.Type)
+	assert.Equal(t, []string{"stdout", "stderr"}, cfg.Outputs[0].Writers)
+}
@@ -0,0 +1,29 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package config
+
+type (
+	// Error is an error of Config's parsing, validation or building.
+	// Key points to the Config's key the error is related to (if any),
+	// like "outputs[1].encoder.type".
+	Error struct {
+		Key string
+		Err error
+	}
+)
+
+// Error implements error interface.
+func (e *Error) Error() string {
+	if e.Key == "" {
+		return "ekalog/config: " + e.Err.Error()
+	}
+	return "ekalog/config: " + e.Key + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
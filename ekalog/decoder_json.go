@@ -0,0 +1,135 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+
+	"github.com/json-iterator/go"
+)
+
+//goland:noinspection GoSnakeCaseUsage
+type (
+	// CI_JSONDecoder is a reverse of CI_JSONEncoder. It decodes the entries,
+	// that are encoded by CI_JSONEncoder, back to the Entry and re-encodes them
+	// using another CI_Encoder (e.g. CI_ConsoleEncoder to pretty-print JSON logs).
+	// The zero CI_JSONDecoder is ready to use with the default names of JSON fields.
+	//
+	// Decoded Entry contains:
+	//
+	//  - Level (from the level's value or its name, LEVEL_INFO if there's none);
+	//  - Time (RFC3339 with any sub-second precision or UNIX timestamp in seconds);
+	//  - Message, caller, trace's parts, ekaerr.Error's header;
+	//  - Stacktrace (any of CI_JSONEncoder's formats) with messages and fields;
+	//  - Aggregated ekaerr.Error's (read more: CI_JSON_ENCODER_FIELD_ERRORS);
+	//  - Fields. The nested JSON objects (read more: CI_JSONEncoder.SetNestedKeys())
+	//    are flattened to the fields with dot-separated keys,
+	//    the JSON arrays are decoded as []any. The top-level JSON values
+	//    with unknown keys are decoded as fields too.
+	//
+	// The exact Go types of fields' values are lost, of course: only bool,
+	// int64, float64, string, nil and []any are possible.
+	// Entry's Logger and the pre-encoded fields are lost either
+	// (the latter ones are decoded as usual fields).
+	CI_JSONDecoder struct {
+		fieldNames map[CI_JSONEncoder_Field]string
+
+		// keys is the reversed fieldNames, that contains the names of
+		// JSON fields (also the default ones) as keys. Built by doBuild().
+		keys map[string]CI_JSONEncoder_Field
+
+		api jsoniter.API
+	}
+)
+
+var (
+	// ErrJSONDecoderNotObject is returned by CI_JSONDecoder.Reencode()
+	// if the input is not a JSON object.
+	ErrJSONDecoderNotObject = errors.New("ekalog: JSON encoded entry must be an object")
+)
+
+// SetNameForField sets the name of the JSON field, the value of which
+// must be decoded as 'fieldType'. It's the same as CI_JSONEncoder.SetNameForField()
+// and must be used if the decoded entries have been encoded
+// by CI_JSONEncoder with changed names of JSON fields.
+//
+// This method MUST NOT be called after Reencode() or ReencodeStream() is called.
+func (jd *CI_JSONDecoder) SetNameForField(fieldType CI_JSONEncoder_Field, name string) *CI_JSONDecoder {
+
+	if jd.fieldNames == nil {
+		jd.fieldNames = make(map[CI_JSONEncoder_Field]string)
+	}
+	jd.fieldNames[fieldType] = name
+	return jd
+}
+
+// Reencode decodes JSON encoded entry 'data' and returns it encoded by 'enc'.
+// CI_ConsoleEncoder and CI_JSONEncoder are built if they are not
+// (the same as CommonIntegrator.WithEncoder() does).
+//
+// Returns ErrJSONDecoderNotObject if 'data' is not a JSON object
+// or an error of JSON parsing if 'data' is a malformed JSON.
+// Read more about what is decoded: CI_JSONDecoder.
+func (jd *CI_JSONDecoder) Reencode(data []byte, enc CI_Encoder) ([]byte, error) {
+
+	jd.doBuild()
+
+	switch encTyped := enc.(type) {
+	case *CI_ConsoleEncoder:
+		encTyped.doBuild()
+	case *CI_JSONEncoder:
+		encTyped.doBuild()
+	}
+
+	e := acquireEntry()
+	defer releaseEntry(e)
+
+	if err := jd.decodeEntry(e, data); err != nil {
+		return nil, err
+	}
+
+	return enc.EncodeEntry(e), nil
+}
+
+// ReencodeStream reads JSON encoded entries from 'r' line by line (one entry
+// per line, as CI_JSONEncoder w/o indentation does), re-encodes them by 'enc'
+// (read more: Reencode()) and writes them to 'w'.
+// Empty lines are skipped, the lines that are not JSON encoded entries
+// are written "as is".
+//
+// Returns the first error of reading or writing (except io.EOF of reading),
+// or nil when 'r' is exhausted.
+func (jd *CI_JSONDecoder) ReencodeStream(r io.Reader, w io.Writer, enc CI_Encoder) error {
+
+	br := bufio.NewReader(r)
+
+	for {
+		line, err := br.ReadBytes('\n')
+
+		if trimmed := bytes.TrimSpace(line); len(trimmed) > 0 {
+			encoded, decodeErr := jd.Reencode(trimmed, enc)
+			if decodeErr != nil {
+				encoded = line
+				if line[len(line)-1] != '\n' {
+					encoded = append(encoded, '\n')
+				}
+			}
+			if _, writeErr := w.Write(encoded); writeErr != nil {
+				return writeErr
+			}
+		}
+
+		switch {
+		case err == io.EOF:
+			return nil
+		case err != nil:
+			return err
+		}
+	}
+}
@@ -0,0 +1,553 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekasys"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+
+	"github.com/json-iterator/go"
+)
+
+// doBuild builds the current CI_JSONDecoder only if it has not built yet.
+// There is no-op if decoder already built.
+func (jd *CI_JSONDecoder) doBuild() *CI_JSONDecoder {
+
+	if jd.keys != nil {
+		return jd
+	}
+
+	// CI_JSONEncoder knows the default names of JSON fields.
+	je := new(CI_JSONEncoder)
+	for fieldType, name := range jd.fieldNames {
+		je.SetNameForField(fieldType, name)
+	}
+	je.doBuild()
+
+	jd.keys = make(map[string]CI_JSONEncoder_Field, len(je.fieldNames))
+	for fieldType, name := range je.fieldNames {
+		jd.keys[name] = fieldType
+	}
+
+	jd.api = jsoniter.ConfigDefault
+	return jd
+}
+
+// decodeEntry decodes JSON encoded entry 'data' to 'e'.
+// Read more: CI_JSONDecoder.
+func (jd *CI_JSONDecoder) decodeEntry(e *Entry, data []byte) error {
+
+	iter := jd.api.BorrowIterator(data)
+	defer jd.api.ReturnIterator(iter)
+
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		return ErrJSONDecoderNotObject
+	}
+
+	var (
+		message       string
+		levelByValue  bool
+		errLetter     = new(ekaletter.Letter)
+		logPrefix     = jd.fieldNameOf(CI_JSON_ENCODER_FIELD_1DL_LOG_FIELDS_PREFIX)
+		stackPrefix   = jd.fieldNameOf(CI_JSON_ENCODER_FIELD_1DL_STACKTRACE_FIELDS_PREFIX)
+		stackPrefixes = strings.SplitN(stackPrefix, "{{num}}", 2)
+	)
+
+	e.Level = LEVEL_INFO
+
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, key string) bool {
+		switch fieldType := jd.keys[key]; fieldType {
+
+		case CI_JSON_ENCODER_FIELD_LEVEL:
+			if level, ok := ParseLevel(jd.readString(iter)); ok && !levelByValue {
+				e.Level = level
+			}
+
+		case CI_JSON_ENCODER_FIELD_LEVEL_VALUE:
+			if iter.WhatIsNext() == jsoniter.NumberValue {
+				e.Level, levelByValue = Level(iter.ReadUint8()), true
+			} else {
+				iter.Skip()
+			}
+
+		case CI_JSON_ENCODER_FIELD_TIME:
+			e.Time = jd.readTime(iter)
+
+		case CI_JSON_ENCODER_FIELD_MESSAGE:
+			message = jd.readString(iter)
+
+		case CI_JSON_ENCODER_FIELD_CALLER:
+			if frame, ok := stackFrameFromFormat(jd.readString(iter)); ok {
+				e.caller = ekasys.StackTrace{frame}
+			}
+
+		case CI_JSON_ENCODER_FIELD_TRACE_ID:
+			e.setSystemField(key, jd.readString(iter), ekaletter.KIND_SYS_TYPE_TRACE_ID)
+		case CI_JSON_ENCODER_FIELD_SPAN_ID:
+			e.setSystemField(key, jd.readString(iter), ekaletter.KIND_SYS_TYPE_SPAN_ID)
+		case CI_JSON_ENCODER_FIELD_TRACE_FLAGS:
+			e.setSystemField(key, jd.readString(iter), ekaletter.KIND_SYS_TYPE_TRACE_FLAGS)
+		case CI_JSON_ENCODER_FIELD_TRACE_STATE:
+			e.setSystemField(key, jd.readString(iter), ekaletter.KIND_SYS_TYPE_TRACE_STATE)
+
+		case CI_JSON_ENCODER_FIELD_FIELDS:
+			e.LogLetter.Fields = jd.decodeFields(iter, "", 0, e.LogLetter.Fields)
+
+		case CI_JSON_ENCODER_FIELD_1DL_STACKTRACE_MESSAGES:
+			jd.decodeStackTraceMessages(iter, errLetter)
+
+		case CI_JSON_ENCODER_FIELD_1DL_STACKTRACE_SECTIONS:
+			jd.decodeStackTraceSections(iter, errLetter)
+
+		case CI_JSON_ENCODER_FIELD_SCHEMA_VERSION, CI_JSON_ENCODER_FIELD_SCHEMA_ERRORS:
+			iter.Skip()
+
+		default:
+			if jd.decodeErrorPart(iter, fieldType, key, errLetter) {
+				break
+			}
+			if idx, fieldKey, ok := stackFieldKey(key, stackPrefixes); ok {
+				errLetter.Fields = jd.decodeField(iter, fieldKey, idx, errLetter.Fields)
+				break
+			}
+			key = strings.TrimPrefix(key, logPrefix)
+			e.LogLetter.Fields = jd.decodeField(iter, key, 0, e.LogLetter.Fields)
+		}
+		return true
+	})
+
+	if iter.Error != nil && iter.Error != io.EOF {
+		return iter.Error
+	}
+
+	ekaletter.LSetMessage(e.LogLetter, message, false)
+
+	if len(errLetter.SystemFields) > 0 || len(errLetter.Messages) > 0 ||
+		len(errLetter.Fields) > 0 || len(errLetter.Children) > 0 ||
+		len(errLetter.Boundaries) > 0 {
+		e.ErrLetter = errLetter
+	} else {
+		e.LogLetter.StackTrace = errLetter.StackTrace
+	}
+
+	return nil
+}
+
+// decodeErrorPart decodes the part of ekaerr.Error (its header, stacktrace
+// or aggregated errors) the JSON value of which 'iter' points to,
+// saving it to 'l'. Returns false (and reads nothing) if 'fieldType'
+// is not a part of ekaerr.Error.
+func (jd *CI_JSONDecoder) decodeErrorPart(
+
+	iter *jsoniter.Iterator,
+	fieldType CI_JSONEncoder_Field,
+	key string,
+	l *ekaletter.Letter,
+
+) bool {
+
+	var sysType ekaletter.LetterFieldKind
+
+	switch fieldType {
+	case CI_JSON_ENCODER_FIELD_ERROR_ID:
+		sysType = ekaletter.KIND_SYS_TYPE_EKAERR_UUID
+	case CI_JSON_ENCODER_FIELD_ERROR_CLASS_NAME:
+		sysType = ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME
+	case CI_JSON_ENCODER_FIELD_ERROR_FINGERPRINT:
+		sysType = ekaletter.KIND_SYS_TYPE_EKAERR_FINGERPRINT
+	case CI_JSON_ENCODER_FIELD_ERROR_CORRELATION_ID:
+		sysType = ekaletter.KIND_SYS_TYPE_CORRELATION_ID
+
+	case CI_JSON_ENCODER_FIELD_ERROR_CLASS_ID:
+		f := ekaletter.LetterField{
+			Key:  key,
+			Kind: ekaletter.KIND_FLAG_SYSTEM | ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_ID,
+		}
+		if iter.WhatIsNext() == jsoniter.NumberValue {
+			f.IValue = iter.ReadInt64()
+		} else {
+			iter.Skip()
+		}
+		l.SystemFields = append(l.SystemFields, f)
+		return true
+
+	case CI_JSON_ENCODER_FIELD_STACKTRACE:
+		jd.decodeStackTrace(iter, l)
+		return true
+
+	case CI_JSON_ENCODER_FIELD_ERRORS:
+		iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+			if child := jd.decodeError(iter); child != nil {
+				l.Children = append(l.Children, child)
+			}
+			return true
+		})
+		return true
+
+	default:
+		return false
+	}
+
+	l.SystemFields = append(l.SystemFields, ekaletter.LetterField{
+		Key:    key,
+		SValue: jd.readString(iter),
+		Kind:   ekaletter.KIND_FLAG_SYSTEM | sysType,
+	})
+	return true
+}
+
+// decodeError decodes the aggregated ekaerr.Error's JSON object
+// (read more: CI_JSON_ENCODER_FIELD_ERRORS) the 'iter' points to.
+// Returns nil if it's not a JSON object.
+func (jd *CI_JSONDecoder) decodeError(iter *jsoniter.Iterator) *ekaletter.Letter {
+
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return nil
+	}
+
+	l := new(ekaletter.Letter)
+
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, key string) bool {
+		switch fieldType := jd.keys[key]; {
+
+		case fieldType == CI_JSON_ENCODER_FIELD_MESSAGE:
+			l.Messages = append(l.Messages, ekaletter.LetterMessage{Body: jd.readString(iter)})
+
+		case fieldType == CI_JSON_ENCODER_FIELD_FIELDS:
+			l.Fields = jd.decodeFields(iter, "", 0, l.Fields)
+
+		case !jd.decodeErrorPart(iter, fieldType, key, l):
+			iter.Skip()
+		}
+		return true
+	})
+
+	return l
+}
+
+// decodeStackTrace decodes the stacktrace the 'iter' points to as an array
+// of stack frames (either JSON objects or formatted strings of one depth level
+// mode), saving it along with messages and fields of stack frames to 'l'.
+func (jd *CI_JSONDecoder) decodeStackTrace(iter *jsoniter.Iterator, l *ekaletter.Letter) {
+
+	iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+		idx := int16(len(l.StackTrace))
+
+		switch iter.WhatIsNext() {
+
+		case jsoniter.StringValue:
+			// "[<idx>]: <full_package_path>/<package>.<func> (<short_file>:<file_line>)"
+			s := iter.ReadString()
+			if i := strings.Index(s, "]: "); i != -1 {
+				s = s[i+3:]
+			}
+
+			i := strings.Index(s, " (")
+			if i == -1 {
+				i = len(s)
+			}
+
+			fullPath, fn := "", s[:i]
+			if slash := strings.LastIndexByte(fn, '/'); slash != -1 {
+				fullPath, fn = fn[:slash], fn[slash+1:]
+			}
+
+			frame, _ := stackFrameFromFormat(fn + s[i:] + " " + fullPath)
+			l.StackTrace = append(l.StackTrace, frame)
+
+		case jsoniter.ObjectValue:
+			var (
+				frame                 ekasys.StackFrame
+				fn, file, fullPath    string
+				isStructured, hasLine bool
+			)
+
+			iter.ReadObjectCB(func(iter *jsoniter.Iterator, key string) bool {
+				switch key {
+				case "func":
+					fn = jd.readString(iter)
+				case "file":
+					file = jd.readString(iter)
+				case "package":
+					fullPath = jd.readString(iter)
+				case "pkg":
+					isStructured = true
+					iter.Skip()
+				case "line":
+					if hasLine = iter.WhatIsNext() == jsoniter.NumberValue; hasLine {
+						frame.Line = iter.ReadInt()
+					} else {
+						iter.Skip()
+					}
+				case "message":
+					l.Messages = append(l.Messages, ekaletter.LetterMessage{
+						Body:          jd.readString(iter),
+						StackFrameIdx: idx,
+					})
+				case "fields":
+					l.Fields = jd.decodeFields(iter, "", idx, l.Fields)
+				case "section":
+					decodeStackFrameSection(l, jd.readString(iter), idx)
+				default:
+					iter.Skip()
+				}
+				return true
+			})
+
+			if isStructured || hasLine {
+				frame.Function, frame.File = fn, file
+				frame.DoFormat()
+			} else {
+				frame, _ = stackFrameFromFormat(fn + " (" + file + ") " + fullPath)
+			}
+
+			l.StackTrace = append(l.StackTrace, frame)
+
+		default:
+			iter.Skip()
+		}
+
+		return true
+	})
+}
+
+// decodeStackTraceMessages decodes the messages of stack frames of one depth
+// level mode ("[<idx>]: <message>" strings) the 'iter' points to, saving them to 'l'.
+func (jd *CI_JSONDecoder) decodeStackTraceMessages(iter *jsoniter.Iterator, l *ekaletter.Letter) {
+
+	iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+		s := jd.readString(iter)
+
+		if i := strings.Index(s, "]: "); strings.HasPrefix(s, "[") && i != -1 {
+			if idx, err := strconv.ParseInt(s[1:i], 10, 16); err == nil {
+				l.Messages = append(l.Messages, ekaletter.LetterMessage{
+					Body:          s[i+3:],
+					StackFrameIdx: int16(idx),
+				})
+			}
+		}
+
+		return true
+	})
+}
+
+// decodeStackTraceSections decodes the boundaries of logical layers of one depth
+// level mode ("[<idx>]: <name>" strings) the 'iter' points to, saving them to 'l'.
+func (jd *CI_JSONDecoder) decodeStackTraceSections(iter *jsoniter.Iterator, l *ekaletter.Letter) {
+
+	iter.ReadArrayCB(func(iter *jsoniter.Iterator) bool {
+		s := jd.readString(iter)
+
+		if i := strings.Index(s, "]: "); strings.HasPrefix(s, "[") && i != -1 && s[i+3:] != "" {
+			if idx, err := strconv.ParseInt(s[1:i], 10, 16); err == nil {
+				l.Boundaries = append(l.Boundaries, ekaletter.LetterBoundary{
+					Name:          s[i+3:],
+					StackFrameIdx: int16(idx),
+				})
+			}
+		}
+
+		return true
+	})
+}
+
+// decodeStackFrameSection saves the name of logical layer 'section'
+// the stack frame 'idx' belongs to, to 'l', extending the boundary
+// of the previous stack frame if it's the same layer.
+func decodeStackFrameSection(l *ekaletter.Letter, section string, idx int16) {
+
+	if section == "" {
+		return
+	}
+
+	if n := len(l.Boundaries); n > 0 && l.Boundaries[n-1].Name == section &&
+		l.Boundaries[n-1].StackFrameIdx == idx-1 {
+
+		l.Boundaries[n-1].StackFrameIdx = idx
+		return
+	}
+
+	l.Boundaries = append(l.Boundaries, ekaletter.LetterBoundary{
+		Name:          section,
+		StackFrameIdx: idx,
+	})
+}
+
+// decodeFields decodes the JSON object the 'iter' points to as fields
+// that belong to the stack frame with 'stackFrameIdx', appending them to 'to'.
+// The keys of fields are prefixed by 'prefix'.
+func (jd *CI_JSONDecoder) decodeFields(
+
+	iter *jsoniter.Iterator,
+	prefix string,
+	stackFrameIdx int16,
+	to []ekaletter.LetterField,
+
+) []ekaletter.LetterField {
+
+	if iter.WhatIsNext() != jsoniter.ObjectValue {
+		iter.Skip()
+		return to
+	}
+
+	iter.ReadObjectCB(func(iter *jsoniter.Iterator, key string) bool {
+		to = jd.decodeField(iter, prefix+key, stackFrameIdx, to)
+		return true
+	})
+
+	return to
+}
+
+// decodeField decodes the JSON value the 'iter' points to as a field with 'key'
+// that belongs to the stack frame with 'stackFrameIdx', appending it to 'to'.
+// JSON object is flattened (read more: decodeFields()).
+func (jd *CI_JSONDecoder) decodeField(
+
+	iter *jsoniter.Iterator,
+	key string,
+	stackFrameIdx int16,
+	to []ekaletter.LetterField,
+
+) []ekaletter.LetterField {
+
+	var f ekaletter.LetterField
+
+	switch iter.WhatIsNext() {
+
+	case jsoniter.ObjectValue:
+		return jd.decodeFields(iter, key+".", stackFrameIdx, to)
+
+	case jsoniter.StringValue:
+		f = ekaletter.FString(key, iter.ReadString())
+
+	case jsoniter.NumberValue:
+		n := iter.ReadNumber()
+		if i, err := n.Int64(); err == nil {
+			f = ekaletter.FInt64(key, i)
+		} else {
+			fl, _ := n.Float64()
+			f = ekaletter.FFloat64(key, fl)
+		}
+
+	case jsoniter.BoolValue:
+		f = ekaletter.FBool(key, iter.ReadBool())
+
+	case jsoniter.NilValue:
+		iter.Skip()
+		f = ekaletter.FNil(key, 0)
+
+	case jsoniter.ArrayValue:
+		f = ekaletter.FAny(key, iter.Read())
+
+	default:
+		iter.Skip()
+		return to
+	}
+
+	f.StackFrameIdx = stackFrameIdx
+	return append(to, f)
+}
+
+// readString returns the JSON string the 'iter' points to,
+// or an empty string (skipping the JSON value) if it's not a string.
+func (_ *CI_JSONDecoder) readString(iter *jsoniter.Iterator) string {
+	if iter.WhatIsNext() != jsoniter.StringValue {
+		iter.Skip()
+		return ""
+	}
+	return iter.ReadString()
+}
+
+// readTime returns the time the 'iter' points to. It's either RFC3339 string
+// (with any sub-second precision) or UNIX timestamp in seconds.
+// Returns zero time.Time (skipping the JSON value) if it's not.
+func (jd *CI_JSONDecoder) readTime(iter *jsoniter.Iterator) time.Time {
+
+	switch iter.WhatIsNext() {
+	case jsoniter.StringValue:
+		t, _ := time.Parse(time.RFC3339Nano, iter.ReadString())
+		return t
+
+	case jsoniter.NumberValue:
+		return time.Unix(iter.ReadInt64(), 0)
+	}
+
+	iter.Skip()
+	return time.Time{}
+}
+
+// fieldNameOf returns the name of JSON field of 'fieldType'.
+// Requires CI_JSONDecoder to be built.
+func (jd *CI_JSONDecoder) fieldNameOf(fieldType CI_JSONEncoder_Field) string {
+	for name, typ := range jd.keys {
+		if typ == fieldType {
+			return name
+		}
+	}
+	return ""
+}
+
+// stackFieldKey reports whether 'key' is the key of stack frame's field
+// of one depth level mode ("<prefix><idx><suffix><key>", where 'prefixes'
+// are the prefix and the suffix), returning the index of stack frame
+// and the key of field.
+func stackFieldKey(key string, prefixes []string) (idx int16, fieldKey string, ok bool) {
+
+	if len(prefixes) != 2 || !strings.HasPrefix(key, prefixes[0]) {
+		return 0, "", false
+	}
+
+	key = key[len(prefixes[0]):]
+
+	i := 0
+	for i < len(key) && key[i] >= '0' && key[i] <= '9' {
+		i++
+	}
+
+	parsed, err := strconv.ParseInt(key[:i], 10, 16)
+	if err != nil || !strings.HasPrefix(key[i:], prefixes[1]) {
+		return 0, "", false
+	}
+
+	return int16(parsed), key[i+len(prefixes[1]):], true
+}
+
+// stackFrameFromFormat returns ekasys.StackFrame, that has 'format'
+// as its formatted representation (read more: ekasys.StackFrame.Format),
+// or false if 'format' has unexpected format.
+// Only the line is restored from 'format'.
+func stackFrameFromFormat(format string) (frame ekasys.StackFrame, ok bool) {
+
+	i, j := strings.Index(format, " ("), -1
+	if i != -1 {
+		j = strings.Index(format[i:], ") ")
+	}
+
+	if ok = j != -1; ok {
+		j += i
+	} else {
+		// Keep the offsets valid, the encoders rely on them.
+		i, j = len(format), len(format)+2
+		format += " () "
+	}
+
+	frame.Format = format
+	frame.FormatFileOffset = i + 1
+	frame.FormatFullPathOffset = j + 2
+
+	file := format[i+2 : j]
+	if k := strings.LastIndexByte(file, ':'); k != -1 {
+		frame.Line, _ = strconv.Atoi(file[k+1:])
+	}
+
+	return frame, ok
+}
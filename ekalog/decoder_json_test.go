@@ -0,0 +1,103 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCI_JSONDecoder_Reencode(t *testing.T) {
+
+	const format = "{{l}} {{m/?$ }}{{f/v=/e }}|"
+
+	cb := func() {
+		ekalog.Warn("first", "user", "john", "ratio", 0.5, "ok", true, "n", nil)
+	}
+
+	expected := testConsoleEncoderOutput(format, cb)
+
+	b := bytes.NewBuffer(nil)
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_JSONEncoder)).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(b)
+
+	ekalog.ReplaceIntegrator(ci)
+	cb()
+
+	ce := new(ekalog.CI_ConsoleEncoder).SetFormat(format)
+	out, err := new(ekalog.CI_JSONDecoder).Reencode(b.Bytes(), ce)
+	require.NoError(t, err)
+	assert.Equal(t, expected, string(out))
+
+	_, err = new(ekalog.CI_JSONDecoder).Reencode([]byte(`"text"`), ce)
+	assert.Equal(t, ekalog.ErrJSONDecoderNotObject, err)
+}
+
+func TestCI_JSONDecoder_Error(t *testing.T) {
+
+	const in = `{"level":"Error","level_value":3,"time":"2022-03-04T05:06:07Z",` +
+		`"message":"failed","error_id":"ID","error_class_id":5,"error_class_name":"IllegalArgument",` +
+		`"fields":{"user":"john"},"stacktrace":[` +
+		`{"func":"pkg.Do","file":"do.go:25","package":"github.com/user","message":"bad value","fields":{"arg":"x"}},` +
+		`{"func":"github.com/user/pkg.Main","file":"/src/main.go","line":10,"pkg":"github.com/user"}]}`
+
+	ce := new(ekalog.CI_ConsoleEncoder).SetFormat("{{e/?^[/?$] }}{{m/?$ }}|{{s}}")
+	out, err := new(ekalog.CI_JSONDecoder).Reencode([]byte(in), ce)
+	require.NoError(t, err)
+
+	assert.Equal(t, "[IllegalArgument (ID): bad value] failed |"+
+		"pkg.Do (do.go:25) github.com/user\nbad value\narg\"x\"\n"+
+		"user.pkg.Main (main.go:10) github.com", string(out))
+}
+
+func TestCI_JSONDecoder_ReencodeStream(t *testing.T) {
+
+	in := strings.Join([]string{
+		`{"severity":"Warning","level_value":4,"time":"2022-03-04T05:06:07Z","message":"first","fields":{"http":{"status":200}}}`,
+		`not a json`,
+		``,
+		`{"severity":"error","time":"2022-03-04T05:06:08Z","message":"second","fields":{"k":[1,2]}}`,
+	}, "\n")
+
+	jd := new(ekalog.CI_JSONDecoder).
+		SetNameForField(ekalog.CI_JSON_ENCODER_FIELD_LEVEL, "severity")
+
+	ce := new(ekalog.CI_ConsoleEncoder).
+		SetFormat("{{l}} {{t/15:04:05}} {{m/?$ }}{{f/v=/e }}#")
+
+	b := bytes.NewBuffer(nil)
+	require.NoError(t, jd.ReencodeStream(strings.NewReader(in), b, ce))
+
+	assert.Equal(t,
+		"Warning 05:06:07 first http.status=200#"+
+			"not a json\n"+
+			"Error 05:06:08 second k=[1,2]#",
+		b.String())
+}
+
+func TestCI_JSONDecoder_StackSections(t *testing.T) {
+
+	const in = `{"level":"Error","message":"failed","error_class_name":"IllegalState","stacktrace":[` +
+		`{"func":"pkg.Get","file":"repo.go:10","package":"github.com/user","section":"repo"},` +
+		`{"func":"pkg.Do","file":"service.go:20","package":"github.com/user","section":"service"},` +
+		`{"func":"pkg.Main","file":"main.go:30","package":"github.com/user"}]}`
+
+	ce := new(ekalog.CI_ConsoleEncoder).SetFormat("{{s}}")
+	out, err := new(ekalog.CI_JSONDecoder).Reencode([]byte(in), ce)
+	require.NoError(t, err)
+
+	assert.Equal(t, "── repo ──\npkg.Get (repo.go:10) github.com/user\n"+
+		"── service ──\npkg.Do (service.go:20) github.com/user\n"+
+		"── … ──\npkg.Main (main.go:30) github.com/user", string(out))
+}
@@ -63,6 +63,14 @@ type (
 		colorMap    map[Level]string // map of default colors for each level
 		colorMapMax int              // max used len of ASCII color encoded seq.
 
+		// Colors are resolved when CI_ConsoleEncoder is built.
+		// Read more: SetColorFor(), SetTheme(), SetColors().
+
+		colorVerbs map[Level]string // colors verbs set by SetColorFor()
+		theme      CI_ConsoleTheme  // theme set by SetTheme()
+		colors     CI_ConsoleColors // colors capability set by SetColors()
+		palette    map[string]string
+
 		// Sum of: len of just text parts + predicted len of log's parts.
 		minimumBufferLen int
 
@@ -164,8 +172,9 @@ var (
 //    Default time format is: "Mon, Jan 02 15:04:05".
 //
 //    Parameters:
-//    (Only first parameter is supported. 2nd and next will be ignored.
-//    Incorrect parameter will be ignored.)
+//    (Only first parameter is used as a time format, it may be followed
+//    by the time zone selector (or the selector may be the only parameter).
+//    Next parameters will be ignored.)
 //
 //    - "UNIX", "TIMESTAMP": Write time.Time as unix timestamp in seconds.
 //      Example: 1619549194 for Tue Apr 27 2021 18:46:34 GMT+0000
@@ -185,11 +194,28 @@ var (
 //      Example: "Mon, 02 Jan 2006 15:04:05 MST".
 //    - "RFC1123Z": Write time.Time as time.RFC1123Z format.
 //      Example: "Mon, 02 Jan 2006 15:04:05 -0700".
-//    - "RFC3339": Write time.Time as time.RFC3339 format.
+//    - "RFC3339", "ISO8601": Write time.Time as time.RFC3339 format.
 //      Example: "2006-01-02T15:04:05Z07:00".
+//    - "RFC3339MILLI", "RFC3339_MILLI": Write time.Time as time.RFC3339 format
+//      with milliseconds. Example: "2006-01-02T15:04:05.000Z07:00".
+//    - "RFC3339MICRO", "RFC3339_MICRO": The same, but with microseconds.
+//      Example: "2006-01-02T15:04:05.000000Z07:00".
+//    - "RFC3339NANO", "RFC3339_NANO": The same, but with nanoseconds.
+//      Example: "2006-01-02T15:04:05.000000000Z07:00".
+//      Unlike time.RFC3339Nano, trailing zeros are kept.
 //    - "<your_own_time_format>: Uses string as time format.
 //      time.Time.Format() will be called with that format string.
 //
+//    Time zone selectors:
+//    - "UTC": Convert time.Time to UTC before formatting.
+//    - "LOCAL": Convert time.Time to the local time zone before formatting.
+//    W/o selector time.Time is formatted in its own time zone.
+//    Example: "{{t/RFC3339MILLI/UTC}}".
+//
+//    The formatted time is cached and reused for the next entries
+//    with the same timestamp (up to the second or millisecond precision
+//    of the format). Micro and nanosecond precision is never cached.
+//
 // 3. Entry's log body verb.
 //    Names: "message", "body", "m", "b".
 //
@@ -252,6 +278,18 @@ var (
 //
 //    - "?^<text>": Places <text> before stacktrace if stacktrace is presented.
 //    - "?$<text>": Places <text> after stacktrace if stacktrace is presented.
+//    - "n<N>": Encodes at most <N> stack frames. The rest ones are replaced
+//      by the "… +<K> more" line. 0 means no limit (default).
+//    - "c": Collapses consecutive identical stack frames (the same function
+//      and line, e.g. a recursion) to the one followed by
+//      the "… repeated <K> more time(s)" line.
+//    - "x<prefix>": Skips stack frames which full function name
+//      (like "github.com/user/pkg.Func") starts with <prefix>.
+//      May be specified many times. Since "/" is a verb separator,
+//      use "|" instead of it: "xgithub.com|user|pkg" -> "github.com/user/pkg".
+//
+//    Stack frames that have attached ekaerr.Error's messages or fields
+//    are never skipped, collapsed or omitted.
 //
 // 6. Entry's fields verb.
 //    Names: "fields", "f".
@@ -275,6 +313,11 @@ var (
 //    - "le<text>": Places <text> at the each new line of attached ekaerr.Error fields part.
 //    - "*<number>": <number> is how much fields are placed at the one line.
 //      (By default: 4. Use <= 0 value to place all fields at the one line).
+//    - "a<number>": Pads EACH field's key by spaces to <number> chars,
+//      so fields' values are lined up vertically.
+//      Keys that are longer than <number> are truncated and "…" is placed at the end.
+//    - "a": The same as "a<number>" but <number> is computed for each log Entry
+//      as the length of its longest key (but no more than 32 chars).
 //
 // 7. TTY coloring verb.
 //    Names: "color", "c".
@@ -292,7 +335,8 @@ var (
 //    There's "default" colors for each log's Level.
 //    If you don't present a color using verb's parameters,
 //    the default log's level color will be used.
-//    You can overwrite default colors for level using SetColorFor() method.
+//    You can overwrite default colors for level using SetColorFor() method
+//    or all of them at once using SetTheme() method.
 //
 //    How parameters works.
 //    All parameters are split to its groups:
@@ -316,13 +360,15 @@ var (
 //      (Most likely ASCII affiliation of color will be used).
 //    - X256 colors: Use "<color>" syntax. Allowable colors: [0..255].
 //      Read more using the link above.
-//    - HEX colors: Use "#<color>" format. Will be transformed to X256 colors.
+//    - HEX colors: Use "#<color>" format. 24-bit color, that is transformed
+//      to X256 color unless truecolor is enabled (see SetColors()).
 //      Read more: https://en.wikipedia.org/wiki/Web_colors
 //    - RGB colors: Use "rgb:<red>,<green>,<blue>" or "rgb(<red>,<green>,<blue>)"
 //      or "rgb,<red>,<green>,<blue>" syntax.
 //      All of <red>, <green>, <blue> must be in range [0..255].
-//      Will be transformed to X256 colors.
+//      The same as HEX colors.
 //      Read more using link above.
+//    - Theme's named colors: Use "@<name>" syntax. Read more: SetTheme().
 //    - "-1": Disable coloring for desired type (background/foreground).
 //
 //    Parameters:
@@ -342,11 +388,36 @@ var (
 //   Make sure your terminal supports X256 colors or use ASCII colors otherwise.
 //   If your terminal doesn't support X256 colors and you will try to use it,
 //   you may get an ugly escape sequences in your output.
+//   Use SetColors() to downgrade all colors to those your terminal supports.
 //
 //   Dropping colors for specific io.Writer.
 //   You may want to disable coloring for specific io.Writer leaving it for another.
 //   See CICE_DropColors() for more details.
 //
+// 8. Attached ekaerr.Error's header verb.
+//    Names: "error", "e".
+//
+//    The verb will be replaced by the header of attached ekaerr.Error,
+//    if it's presented. The header is "<class_name> (<error_id>): <message>",
+//    where <message> is the top (last added) message of ekaerr.Error.
+//    <message> is omitted if it's used as log Entry's message
+//    (when log's message is empty).
+//    It doesn't depend on stacktrace's verb, so you may place error's header
+//    wherever you want.
+//    You can include this verb only once.
+//    2nd and next these verbs will be treated as just a text.
+//
+//    Parameters:
+//    (The same key parameters will be overwritten by the last one.
+//    The parsing of parameters will be stopped when an incorrect parameter found).
+//
+//    - "?^<text>": Places <text> before error's header if ekaerr.Error is attached.
+//    - "?$<text>": Places <text> after error's header if ekaerr.Error is attached.
+//    - "c:<color_part>": Colors error's header. <color_part> is the same as
+//      color verb's parameter. Use many "c:" parameters to combine them.
+//      Example: "{{e/c:fg:#ff0000/c:b}}" for bold red error's header.
+//      The coloring is reset after error's header.
+//
 // -----
 //
 // If you won't set any format string, the default one will be used.
@@ -372,22 +443,46 @@ func (ce *CI_ConsoleEncoder) SetFormat(newFormat string) *CI_ConsoleEncoder {
 }
 
 // SetColorFor sets color what will be used as a replace for level-depended
-// color verb from the 'format' string that is set by SetFormat() func
+// color verb from the 'format' string that is set by SetFormat() func.
+// It overwrites the level's color of the theme (see SetTheme()).
 //
-// Example: "c/fg:ascii:31/b", "c/fg:#123456/bg:rgb,50,50,50/i/u".
+// Example: "c/fg:ascii:31/b", "c/fg:#123456/bg:rgb,50,50,50/i/u", "c/fg:@accent".
+//
+// As the format string, the color is applied only when CI_ConsoleEncoder
+// is registered with CommonIntegrator. The invalid color is ignored then.
 func (ce *CI_ConsoleEncoder) SetColorFor(level Level, color string) *CI_ConsoleEncoder {
 
-	if ce.colorMap == nil {
-		ce.colorMap = make(map[Level]string)
+	if ce.colorVerbs == nil {
+		ce.colorVerbs = make(map[Level]string)
 	}
 
-	if encodedColor := ce.rvColorHelper(color); encodedColor != "" {
-		ce.colorMap[level] = encodedColor
-		if l := len(encodedColor); ce.colorMapMax < l {
-			ce.colorMapMax = l
-		}
+	ce.colorVerbs[level] = color
+	return ce
+}
+
+// SetTheme sets the registered CI_ConsoleTheme with 'name' (see CICE_RegisterTheme()),
+// whose colors will be used for levels (unless they are set by SetColorFor())
+// and whose named colors may be referenced from the color verbs as "@<name>".
+// Does nothing if there's no theme with 'name'.
+//
+// As the format string, the theme is applied only when CI_ConsoleEncoder
+// is registered with CommonIntegrator.
+func (ce *CI_ConsoleEncoder) SetTheme(name string) *CI_ConsoleEncoder {
+	if theme, ok := ceThemeGet(name); ok {
+		ce.theme = theme
 	}
+	return ce
+}
 
+// SetColors sets the colors capability of the terminal the output is written to.
+// The colors, that are not supported, are downgraded to the closest supported
+// ones: 24-bit -> X256 -> ASCII. By default, it's CICE_COLORS_X256.
+// Use CICE_DetectColors() to get the current terminal's capability.
+//
+// As the format string, the capability is applied only when CI_ConsoleEncoder
+// is registered with CommonIntegrator.
+func (ce *CI_ConsoleEncoder) SetColors(colors CI_ConsoleColors) *CI_ConsoleEncoder {
+	ce.colors = colors
 	return ce
 }
 
@@ -411,14 +506,48 @@ func (ce *CI_ConsoleEncoder) PreEncodeField(f ekaletter.LetterField) {
 		return
 	}
 
+	// Auto alignment can't be applied to pre-encoded fields,
+	// because they are encoded once and the keys of Entry's fields are unknown yet.
+	alignWidth := ce.ff.alignWidth
+	if alignWidth == _CICE_FIELDS_ALIGN_AUTO {
+		alignWidth = 0
+	}
+
 	preEncodedFieldsLenBak := len(ce.preEncodedFields)
-	ce.preEncodedFields = ce.encodeField(ce.preEncodedFields, f, false, ce.preEncodedFieldsWritten)
+	ce.preEncodedFields = ce.encodeField(ce.preEncodedFields, f, false, ce.preEncodedFieldsWritten, alignWidth)
 
 	if len(ce.preEncodedFields) != preEncodedFieldsLenBak {
 		ce.preEncodedFieldsWritten++
 	}
 }
 
+// PreEncodeRawJSON is the same as PreEncodeField() but for the field with 'key',
+// which value is already encoded JSON 'raw' (an object, an array, etc).
+// It's useful for static blobs like k8s pod metadata.
+//
+// 'raw' is validated and compacted once and then it's written as field's value
+// of each Entry as is (w/o quotes), like: pod={"name":"api-0","ns":"prod"}.
+// Returns an error if 'key' is empty or 'raw' is not a valid JSON.
+//
+// WARNING!
+// PreEncodeRawJSON() MUST BE USED ONLY IF CI_ConsoleEncoder HAS BEEN REGISTERED
+// WITH SOME CommonIntegrator ALREADY. UB OTHERWISE, MAY PANIC!
+func (ce *CI_ConsoleEncoder) PreEncodeRawJSON(key string, raw []byte) error {
+
+	compacted, err := compactRawJSON(key, raw)
+	if err != nil {
+		return err
+	}
+
+	ce.PreEncodeField(ekaletter.LetterField{
+		Key:    key,
+		SValue: compacted,
+		Kind:   ekaletter.KIND_FLAG_SYSTEM | ekaletter.KIND_SYS_TYPE_RAW_JSON,
+	})
+
+	return nil
+}
+
 // EncodeEntry encodes passed Entry as text using provided (and parsed)
 // format string that is set by SetFormat() method, returning a RAW encoded data.
 //
@@ -460,13 +589,17 @@ func (ce *CI_ConsoleEncoder) EncodeEntry(e *Entry) []byte {
 			to = ce.encodeStacktrace(to, e)
 		case _CICE_FPT_VERB_CALLER:
 			to = ce.encodeCaller(to, e)
+		case _CICE_FPT_VERB_ERROR:
+			to = ce.encodeError(to, e)
 
 		case _CICE_FPT_VERB_FIELDS:
 			errLetterSystemFields := []ekaletter.LetterField(nil)
 			if e.ErrLetter != nil {
+				ekaletter.LFingerprint(e.ErrLetter)
 				errLetterSystemFields = e.ErrLetter.SystemFields
 			}
-			to = ce.encodeFields(to, e.LogLetter.SystemFields, errLetterSystemFields, false, false)
+			correlationID, _ := e.correlationIDField()
+			to = ce.encodeFields(to, e.LogLetter.SystemFields, errLetterSystemFields, false, false, correlationID)
 
 			// Handle special case when ekaerr.Error's ekaletter.Letter has a fields
 			// but has no stacktrace. It means that lightweight error has been created.
@@ -474,7 +607,7 @@ func (ce *CI_ConsoleEncoder) EncodeEntry(e *Entry) []byte {
 			if e.ErrLetter != nil && len(e.ErrLetter.StackTrace) == 0 && len(e.ErrLetter.Fields) > 0 {
 				lightweightErrorFields = e.ErrLetter.Fields
 			}
-			to = ce.encodeFields(to, e.LogLetter.Fields, lightweightErrorFields, false, true)
+			to = ce.encodeFields(to, e.LogLetter.Fields, lightweightErrorFields, false, true, "")
 		}
 	}
 
@@ -502,6 +635,9 @@ func (ce *CI_ConsoleEncoder) EncodeEntry(e *Entry) []byte {
 //
 // Keep in mind, it's not "free" operation. It allocates RAM buffer,
 // writes raw data to, parses it, droppings colors and rewrites cleared raw data.
+//
+// The same may be achieved by registering CI_PostProcessorDropColors
+// with CommonIntegrator.WithPostProcessors().
 func CICE_DropColors(dest io.Writer) io.Writer {
 	return &_CICE_DropColors{dest: dest}
 }
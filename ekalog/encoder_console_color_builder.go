@@ -28,9 +28,11 @@ type (
 		// no one next verb's part will be processed
 
 		// [0..255] - xterm256 ANSI SGR color code
+		// ASCII SGR color code | _CB_ASCII_FLAG - 16 ASCII system colors
+		// 0xRRGGBB | _CB_TRUECOLOR_FLAG - 24-bit color
 		// -1 if 'do cleanup color to terminal default' ( "\033[39m" or "\033[49m" )
 		// -2 if 'not set, use those one that was used' (not included to SGR)
-		bg, fg int16
+		bg, fg int32
 
 		// 0 - 'not set, use those one that was used' (not included to SGR)
 		// 1 - enable (included to SGR (01/03/04))
@@ -39,9 +41,19 @@ type (
 
 		// Yes, there is no support blinking text.
 		// I think it's disgusting. It will never be added.
+
+		// palette is the named colors of CI_ConsoleTheme (upper cased names
+		// to colors), that may be referenced as "@name". May be nil.
+		palette map[string]string
 	}
 )
 
+//goland:noinspection GoSnakeCaseUsage
+const (
+	_CB_ASCII_FLAG     int32 = 1 << 14
+	_CB_TRUECOLOR_FLAG int32 = 1 << 24
+)
+
 func (cb *colorBuilder) init() {
 	cb.bg, cb.fg = -2, -2
 	cb.bold, cb.italic, cb.underline = 0, 0, 0
@@ -92,7 +104,7 @@ func (cb *colorBuilder) parseEntity(verbPart string) (parsed bool) {
 	// TODO: Add supporting of color's literals like "red", "pink", "blue", etc.
 
 	// what's kind of color? default is fg
-	var colorDestination *int16
+	var colorDestination *int32
 	switch {
 
 	case strings.HasPrefix(verbPart, "BG:"):
@@ -107,6 +119,13 @@ func (cb *colorBuilder) parseEntity(verbPart string) (parsed bool) {
 		colorDestination = &cb.fg
 	}
 
+	return cb.parseColorTo(verbPart, colorDestination)
+}
+
+// parseColorTo parses color (w/o "fg:", "bg:" prefixes) and saves it to 'destination'.
+func (cb *colorBuilder) parseColorTo(verbPart string, destination *int32) (parsed bool) {
+	// --- REMINDER! 1ST ARGUMENT IS ALWAYS UPPER CASED! ---
+
 	// handle special easy cases cases
 	switch {
 	case len(verbPart) == 0:
@@ -115,48 +134,52 @@ func (cb *colorBuilder) parseEntity(verbPart string) (parsed bool) {
 
 	case verbPart == "-1":
 		// color cleanup (to default in term)
-		*colorDestination = -1
+		*destination = -1
 		return true
 
 	case verbPart[0] == '#':
 		// easy case if it's explicit hex
-		return cb.parseHexTo(verbPart[1:], colorDestination)
+		return cb.parseHexTo(verbPart[1:], destination)
+
+	case verbPart[0] == '@':
+		// theme's named color
+		return cb.parsePaletteTo(verbPart[1:], destination)
 	}
 
 	// maybe default ASCII seq?
 	switch {
 	case strings.HasPrefix(verbPart, "ASCII:"):
-		return cb.parseBaseASCIITo(verbPart[6:], colorDestination)
+		return cb.parseBaseASCIITo(verbPart[6:], destination)
 
 	case strings.HasPrefix(verbPart, "ASCII(") && verbPart[len(verbPart)-1] == ')':
-		return cb.parseBaseASCIITo(verbPart[6:len(verbPart)-1], colorDestination)
+		return cb.parseBaseASCIITo(verbPart[6:len(verbPart)-1], destination)
 	}
 
 	// okay, maybe easy rgb/rgba?
 	switch {
 	case strings.HasPrefix(verbPart, "RGB:"):
-		return cb.parseRgbTo(verbPart[4:], colorDestination)
+		return cb.parseRgbTo(verbPart[4:], destination)
 
 	case strings.HasPrefix(verbPart, "RGBA:"):
-		return cb.parseRgbTo(verbPart[5:], colorDestination)
+		return cb.parseRgbTo(verbPart[5:], destination)
 
 	case strings.HasPrefix(verbPart, "RGB(") && verbPart[len(verbPart)-1] == ')':
-		return cb.parseRgbTo(verbPart[4:len(verbPart)-1], colorDestination)
+		return cb.parseRgbTo(verbPart[4:len(verbPart)-1], destination)
 
 	case strings.HasPrefix(verbPart, "RGBA(") && verbPart[len(verbPart)-1] == ')':
-		return cb.parseRgbTo(verbPart[4:len(verbPart)-1], colorDestination)
+		return cb.parseRgbTo(verbPart[4:len(verbPart)-1], destination)
 	}
 
 	// okay maybe rgb by comma?
 	if commas := strings.Count(verbPart, ","); commas >= 3 && commas <= 4 {
-		return cb.parseRgbTo(verbPart, colorDestination)
+		return cb.parseRgbTo(verbPart, destination)
 	}
 
 	// believe it's just XTerm 256 colors code
-	return cb.parseX256To(verbPart, colorDestination)
+	return cb.parseX256To(verbPart, destination)
 }
 
-func (_ *colorBuilder) parseBaseASCIITo(verbPart string, destination *int16) (parsed bool) {
+func (_ *colorBuilder) parseBaseASCIITo(verbPart string, destination *int32) (parsed bool) {
 	// --- REMINDER! 1ST ARGUMENT IS ALWAYS UPPER CASED! ---
 
 	asciiColor, _ := strconv.Atoi(verbPart)
@@ -164,25 +187,38 @@ func (_ *colorBuilder) parseBaseASCIITo(verbPart string, destination *int16) (pa
 	if (asciiColor >= 30 && asciiColor <= 37) || (asciiColor >= 40 && asciiColor <= 47) ||
 		(asciiColor >= 90 && asciiColor <= 97) || (asciiColor >= 100 && asciiColor <= 107) {
 
-		*destination = int16(asciiColor) | (1 << 14)
+		*destination = int32(asciiColor) | _CB_ASCII_FLAG
 		return true
 	}
 
 	return false
 }
 
-func (_ *colorBuilder) parseX256To(verbPart string, destination *int16) (parsed bool) {
+func (_ *colorBuilder) parseX256To(verbPart string, destination *int32) (parsed bool) {
 
 	xterm256color, err := strconv.Atoi(verbPart)
 	if err != nil || xterm256color < 0 || xterm256color > 255 {
 		return false
 	}
 
-	*destination = int16(xterm256color)
+	*destination = int32(xterm256color)
 	return true
 }
 
-func (_ *colorBuilder) parseHexTo(verbPart string, destination *int16) (parsed bool) {
+func (cb *colorBuilder) parsePaletteTo(verbPart string, destination *int32) (parsed bool) {
+	// --- REMINDER! 1ST ARGUMENT IS ALWAYS UPPER CASED! ---
+
+	// Named color is only a color, w/o "fg:", "bg:" prefixes,
+	// and it can't reference another named color (avoid cycles).
+	namedColor := strings.ToUpper(strings.TrimSpace(cb.palette[verbPart]))
+	if namedColor == "" || namedColor[0] == '@' {
+		return false
+	}
+
+	return cb.parseColorTo(namedColor, destination)
+}
+
+func (_ *colorBuilder) parseHexTo(verbPart string, destination *int32) (parsed bool) {
 	// --- REMINDER! 1ST ARGUMENT IS ALWAYS UPPER CASED! ---
 
 	switch verbPart = strings.TrimSpace(verbPart); len(verbPart) {
@@ -206,17 +242,20 @@ func (_ *colorBuilder) parseHexTo(verbPart string, destination *int16) (parsed b
 		return false
 	}
 
-	termColor, err := xtermcolor.FromHexStr(verbPart)
-	*destination = int16(termColor)
+	rgb, err := strconv.ParseUint(verbPart, 16, 24)
+	if err != nil {
+		return false
+	}
 
-	return err == nil
+	*destination = int32(rgb) | _CB_TRUECOLOR_FLAG
+	return true
 }
 
-func (_ *colorBuilder) parseRgbTo(verbPart string, destination *int16) (parsed bool) {
+func (_ *colorBuilder) parseRgbTo(verbPart string, destination *int32) (parsed bool) {
 	// --- REMINDER! 1ST ARGUMENT IS ALWAYS UPPER CASED! ---
 
 	rgbParts := strings.Split(strings.TrimSpace(verbPart), ",")
-	if l := len(rgbParts); l < 3 && l > 4 {
+	if l := len(rgbParts); l < 3 || l > 4 {
 		return false
 	}
 
@@ -234,15 +273,18 @@ func (_ *colorBuilder) parseRgbTo(verbPart string, destination *int16) (parsed b
 		return false
 	}
 
-	rgb := color.RGBA{R: uint8(r), G: uint8(g), B: uint8(b), A: 255}
-	*destination = int16(xtermcolor.FromColor(rgb))
-
+	*destination = int32(r<<16|g<<8|b) | _CB_TRUECOLOR_FLAG
 	return true
 }
 
-func (cb *colorBuilder) encode() string {
+// encode returns SGR escape sequence of the parsed color verb,
+// downgrading the colors the terminal doesn't support according to 'colors'.
+func (cb *colorBuilder) encode(colors CI_ConsoleColors) string {
 
-	if cb.bg == 100 {
+	switch {
+	case colors == CICE_COLORS_NONE:
+		return ""
+	case cb.bg == 100:
 		return "\033[0m"
 	}
 
@@ -279,15 +321,8 @@ func (cb *colorBuilder) encode() string {
 	case cb.fg == -1:
 		// set foreground to term default
 		out += "39;"
-	case cb.fg&(1<<14) != 0:
-		// first 16 ASCII sys colors
-		cb.fg &^= 1 << 14
-		if cb.fg >= 40 && cb.fg <= 47 || cb.fg >= 100 {
-			cb.fg -= 10
-		}
-		out += strconv.Itoa(int(cb.fg)) + ";"
 	default:
-		out += "38;5;" + strconv.Itoa(int(cb.fg)) + ";"
+		out += cb.encodeColor(cb.fg, false, colors) + ";"
 	}
 
 	switch /* BACKGROUND COLOR */ {
@@ -296,15 +331,8 @@ func (cb *colorBuilder) encode() string {
 	case cb.bg == -1:
 		// set background to term default
 		out += "49;"
-	case cb.bg&(1<<14) != 0:
-		// first 16 ASCII sys colors
-		cb.bg &^= 1 << 14
-		if cb.bg < 40 || cb.bg >= 90 && cb.bg <= 97 {
-			cb.bg += 10
-		}
-		out += strconv.Itoa(int(cb.fg)) + ";"
 	default:
-		out += "48;5;" + strconv.Itoa(int(cb.bg)) + ";"
+		out += cb.encodeColor(cb.bg, true, colors) + ";"
 	}
 
 	if out[len(out)-1] != ';' {
@@ -314,3 +342,60 @@ func (cb *colorBuilder) encode() string {
 	out = out[:len(out)-1] + "m"
 	return out
 }
+
+// encodeColor returns SGR parameters of foreground or background 'c' color,
+// downgrading it to X256 or ASCII color if it's required by 'colors'.
+func (_ *colorBuilder) encodeColor(c int32, isBg bool, colors CI_ConsoleColors) string {
+
+	if c&_CB_TRUECOLOR_FLAG != 0 {
+		rgb := color.RGBA{R: uint8(c >> 16), G: uint8(c >> 8), B: uint8(c), A: 255}
+		switch colors {
+
+		case CICE_COLORS_TRUECOLOR:
+			out := "38;2;"
+			if isBg {
+				out = "48;2;"
+			}
+			return out + strconv.Itoa(int(rgb.R)) + ";" +
+				strconv.Itoa(int(rgb.G)) + ";" + strconv.Itoa(int(rgb.B))
+
+		case CICE_COLORS_ASCII:
+			c = colorToASCII(rgb)
+
+		default:
+			c = int32(xtermcolor.FromColor(rgb))
+		}
+	}
+
+	if c&_CB_ASCII_FLAG == 0 && colors == CICE_COLORS_ASCII {
+		c = colorToASCII(xtermcolor.Colors[c])
+	}
+
+	if c&_CB_ASCII_FLAG == 0 {
+		if isBg {
+			return "48;5;" + strconv.Itoa(int(c))
+		}
+		return "38;5;" + strconv.Itoa(int(c))
+	}
+
+	// first 16 ASCII sys colors
+	c &^= _CB_ASCII_FLAG
+	switch {
+	case isBg && (c < 40 || c >= 90 && c <= 97):
+		c += 10
+	case !isBg && (c >= 40 && c <= 47 || c >= 100):
+		c -= 10
+	}
+
+	return strconv.Itoa(int(c))
+}
+
+// colorToASCII returns the closest to 'c' ASCII system color
+// (as a foreground one) with _CB_ASCII_FLAG.
+func colorToASCII(c color.Color) int32 {
+	idx := int32(color.Palette(xtermcolor.Colors[:16]).Index(c))
+	if idx < 8 {
+		return (30 + idx) | _CB_ASCII_FLAG
+	}
+	return (90 + idx - 8) | _CB_ASCII_FLAG
+}
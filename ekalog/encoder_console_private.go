@@ -6,14 +6,17 @@
 package ekalog
 
 import (
-	"bytes"
 	"io"
 	"math"
 	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
+	"github.com/qioalice/ekago/v3/ekaenc"
 	"github.com/qioalice/ekago/v3/ekamath"
+	"github.com/qioalice/ekago/v3/ekastr"
 	"github.com/qioalice/ekago/v3/ekasys"
 	"github.com/qioalice/ekago/v3/internal/ekaletter"
 
@@ -31,6 +34,25 @@ type (
 	_CICE_FormatPart struct {
 		typ   _CICE_FormatPartType
 		value string
+
+		// timeCache is the last formatted Entry.Time for '_CICE_FPT_VERB_TIME'.
+		// It's nil if formatted time is not cached (read more: rvTime()).
+		timeCache *_CICE_TimeCache
+	}
+
+	// _CICE_TimeCache is a cache of the last formatted Entry.Time,
+	// that allows not to format the same timestamp again for the bursts of entries.
+	// The timestamp is truncated to 'resolution' to be compared.
+	_CICE_TimeCache struct {
+		resolution int64        // nanoseconds
+		last       atomic.Value // *_CICE_TimeCacheEntry
+	}
+
+	// _CICE_TimeCacheEntry is an entry of _CICE_TimeCache.
+	_CICE_TimeCacheEntry struct {
+		key       int64 // unix nanoseconds truncated to resolution
+		loc       *time.Location
+		formatted string
 	}
 
 	// _CICE_FormatPartType is a special type of _CICE_FormatPart's field 'typ' that contains
@@ -48,6 +70,7 @@ type (
 		afterNewLine         string
 		afterNewLineForError string
 		itemsPerLine         int16
+		alignWidth           int16 // 0 - no alignment, -1 - auto, >0 - fixed
 	}
 
 	_CICE_BodyFormat struct {
@@ -66,19 +89,23 @@ type (
 	}
 
 	_CICE_StacktraceFormat struct {
-		isSet       bool
-		beforeStack string
-		afterStack  string
+		isSet            bool
+		beforeStack      string
+		afterStack       string
+		maxFrames        int16    // 0 means no limit
+		collapseRepeated bool     // collapse consecutive identical frames
+		skipPrefixes     []string // skip frames which function has one of these prefixes
 	}
 
 	_CICE_ErrorFormat struct {
 		isSet       bool
 		beforeError string
 		afterError  string
+		color       string // encoded TTY color, "" if coloring is disabled
+		colorReset  string // encoded TTY color reset sequence
 	}
 
 	_CICE_DropColors struct {
-		buf  bytes.Buffer
 		dest io.Writer
 	}
 )
@@ -100,6 +127,7 @@ const (
 	_CICE_FPT_VERB_STACKTRACE      _CICE_FormatPartType = 0x1A
 	_CICE_FPT_VERB_FIELDS          _CICE_FormatPartType = 0x2A
 	_CICE_FPT_VERB_CALLER          _CICE_FormatPartType = 0x3A
+	_CICE_FPT_VERB_ERROR           _CICE_FormatPartType = 0x4A
 
 	// Common Integrator Console Encoder Level Format (CICE LF)
 	// type constants.
@@ -124,6 +152,23 @@ const (
 	_CICE_TF_RFC1123_Z _CICE_FormatPartType = 9
 	_CICE_TF_RFC3339   _CICE_FormatPartType = 10
 
+	_CICE_TF_RFC3339_MILLI _CICE_FormatPartType = 11
+	_CICE_TF_RFC3339_MICRO _CICE_FormatPartType = 12
+	_CICE_TF_RFC3339_NANO  _CICE_FormatPartType = 13
+
+	// Time zone selectors, that are OR'ed with CICE TF.
+
+	_CICE_TF_MASK_FORMAT _CICE_FormatPartType = 0x3F
+	_CICE_TF_TZ_UTC      _CICE_FormatPartType = 0x40
+	_CICE_TF_TZ_LOCAL    _CICE_FormatPartType = 0x80
+
+	// Time layouts of _CICE_TF_RFC3339_MILLI, _CICE_TF_RFC3339_MICRO,
+	// _CICE_TF_RFC3339_NANO. Fraction is fixed width, unlike time.RFC3339Nano.
+
+	_CICE_TIME_LAYOUT_RFC3339_MILLI = "2006-01-02T15:04:05.000Z07:00"
+	_CICE_TIME_LAYOUT_RFC3339_MICRO = "2006-01-02T15:04:05.000000Z07:00"
+	_CICE_TIME_LAYOUT_RFC3339_NANO  = "2006-01-02T15:04:05.000000000Z07:00"
+
 	// Common Integrator Console Encoder Caller Format (CICE CF)
 	// type constants.
 
@@ -164,6 +209,18 @@ const (
 		"\n"
 
 	_CICE_DEFAULT_TIME_FORMAT string = "Mon Jan 02 15:04:05"
+
+	// _CICE_FIELDS_ALIGN_AUTO is a special value of _CICE_FieldsFormat.alignWidth
+	// that means the width of keys must be computed for each Entry independently.
+	_CICE_FIELDS_ALIGN_AUTO int16 = -1
+
+	// _CICE_FIELDS_ALIGN_AUTO_MAX is the max width that could be computed
+	// in auto alignment mode. Longer keys will be truncated with ellipsis.
+	_CICE_FIELDS_ALIGN_AUTO_MAX int16 = 32
+
+	// _CICE_FIELDS_ALIGN_ELLIPSIS is the string that is written instead of
+	// the truncated part of too long key if alignment is enabled.
+	_CICE_FIELDS_ALIGN_ELLIPSIS string = "…"
 )
 
 // CommonIntegrator CI_ConsoleEncoder Verb Types (CICE VT)
@@ -176,6 +233,7 @@ var (
 	cevtMessage    = []string{"message", "body", "m", "b"}
 	cevtFields     = []string{"fields", "f"}
 	cevtStacktrace = []string{"stacktrace", "s"}
+	cevtError      = []string{"error", "e"}
 )
 
 var (
@@ -217,6 +275,8 @@ func (ce *CI_ConsoleEncoder) doBuild() *CI_ConsoleEncoder {
 	// all parsing loops are for-range based (because there is UTF-8 support)
 	// (yes, you can use not only ASCII parts in your format string,
 	// and yes if you do it, you are mad. stop it!).
+	ce.resolveColors()
+
 	for rest := ce.format; rest != ""; rest = ce.parseFirstVerb(rest) {
 	}
 
@@ -266,39 +326,47 @@ func (ce *CI_ConsoleEncoder) uniteJustTextVerbs() *CI_ConsoleEncoder {
 	return ce
 }
 
-// setStandardParts saves standard colors for standard log levels
-// if they has not been set yet.
-func (ce *CI_ConsoleEncoder) setStandardParts() *CI_ConsoleEncoder {
+// resolveColors resolves theme's palette and the colors for levels
+// (SetColorFor() ones, then theme's ones, then "default" theme's ones).
+func (ce *CI_ConsoleEncoder) resolveColors() *CI_ConsoleEncoder {
 
-	if ce.colorMap == nil {
-		ce.colorMap = make(map[Level]string)
+	ce.palette = make(map[string]string, len(ce.theme.Colors))
+	for name, color := range ce.theme.Colors {
+		ce.palette[strings.ToUpper(name)] = color
 	}
 
-	if ce.colorMap[LEVEL_DEBUG] == "" {
-		ce.colorMap[LEVEL_DEBUG] = ce.rvColorHelper(_CICE_SC_DEBUG)
-	}
-	if ce.colorMap[LEVEL_INFO] == "" {
-		ce.colorMap[LEVEL_INFO] = ce.rvColorHelper(_CICE_SC_INFO)
-	}
-	if ce.colorMap[LEVEL_NOTICE] == "" {
-		ce.colorMap[LEVEL_NOTICE] = ce.rvColorHelper(_CICE_SC_NOTICE)
-	}
-	if ce.colorMap[LEVEL_WARNING] == "" {
-		ce.colorMap[LEVEL_WARNING] = ce.rvColorHelper(_CICE_SC_WARNING)
+	ce.colorMap = make(map[Level]string)
+	ce.colorMapMax = 0
+
+	setColor := func(level Level, colorVerb string) {
+		if encodedColor := ce.rvColorHelper(colorVerb); encodedColor != "" {
+			ce.colorMap[level] = encodedColor
+			if l := len(encodedColor); ce.colorMapMax < l {
+				ce.colorMapMax = l
+			}
+		}
 	}
-	if ce.colorMap[LEVEL_ERROR] == "" {
-		ce.colorMap[LEVEL_ERROR] = ce.rvColorHelper(_CICE_SC_ERROR)
+
+	defaultTheme, _ := ceThemeGet("default")
+	for level, colorVerb := range defaultTheme.Levels {
+		setColor(level, colorVerb)
 	}
-	if ce.colorMap[LEVEL_CRITICAL] == "" {
-		ce.colorMap[LEVEL_CRITICAL] = ce.rvColorHelper(_CICE_SC_CRITICAL)
+	for level, colorVerb := range levelCustomColors() {
+		setColor(level, colorVerb)
 	}
-	if ce.colorMap[LEVEL_ALERT] == "" {
-		ce.colorMap[LEVEL_ALERT] = ce.rvColorHelper(_CICE_SC_ALERT)
+	for level, colorVerb := range ce.theme.Levels {
+		setColor(level, colorVerb)
 	}
-	if ce.colorMap[LEVEL_EMERGENCY] == "" {
-		ce.colorMap[LEVEL_EMERGENCY] = ce.rvColorHelper(_CICE_SC_EMERGENCY)
+	for level, colorVerb := range ce.colorVerbs {
+		setColor(level, colorVerb)
 	}
 
+	return ce
+}
+
+// setStandardParts sets standard parts if they has not been set yet.
+func (ce *CI_ConsoleEncoder) setStandardParts() *CI_ConsoleEncoder {
+
 	if !ce.cf.isSet {
 		ce.cf.isDefault = true
 	}
@@ -405,6 +473,9 @@ func (ce *CI_ConsoleEncoder) rv(verb string) (predictedLen int) {
 	case hpm(verb, cevtStacktrace):
 		return applyOnce(&ce.sf.isSet, ce.rvJustText, ce.rvStacktrace, verb)
 
+	case hpm(verb, cevtError):
+		return applyOnce(&ce.ef.isSet, ce.rvJustText, ce.rvError, verb)
+
 	default:
 		// incorrect verb, treat it as "just text" verb
 		return ce.rvJustText(verb)
@@ -501,40 +572,82 @@ func (ce *CI_ConsoleEncoder) rvTime(verb string) (predictedLen int) {
 
 	format := _CICE_DEFAULT_TIME_FORMAT
 	formattedTime := _CICE_FormatPartType(0)
+	timeZone := _CICE_FormatPartType(0)
+	isFormatParsed := false
 
 	(*CI_ConsoleEncoder)(nil).rvHelper(verb, func(verbPart string) (continue_ bool) {
-		if verbPart = strings.TrimSpace(format); verbPart != "" {
-			switch predefined := strings.ToUpper(verbPart); predefined {
-			case "UNIX", "TIMESTAMP":
-				formattedTime = _CICE_TF_TIMESTAMP
-			case "ANSIC":
-				formattedTime = _CICE_TF_ANSIC
-			case "UNIXDATE", "UNIX_DATE":
-				formattedTime = _CICE_TF_UNIXDATE
-			case "RUBYDATE", "RUBY_DATE":
-				formattedTime = _CICE_TF_RUBYDATE
-			case "RFC822":
-				formattedTime = _CICE_TF_RFC822
-			case "RFC822Z":
-				formattedTime = _CICE_TF_RFC822_Z
-			case "RFC850":
-				formattedTime = _CICE_TF_RFC850
-			case "RFC1123":
-				formattedTime = _CICE_TF_RFC1123
-			case "RFC1123Z":
-				formattedTime = _CICE_TF_RFC1123_Z
-			case "RFC3339":
-				formattedTime = _CICE_TF_RFC3339
-			default:
-				format = verbPart
-			}
+		verbPart = strings.TrimSpace(verbPart)
+
+		// Time zone selector may follow the format or be the only parameter.
+		switch strings.ToUpper(verbPart) {
+		case "UTC":
+			timeZone = _CICE_TF_TZ_UTC
+			return !isFormatParsed
+		case "LOCAL":
+			timeZone = _CICE_TF_TZ_LOCAL
+			return !isFormatParsed
 		}
-		return false // only first time verb is allowed and will be parsed
+
+		if isFormatParsed || verbPart == "" {
+			return false
+		}
+		isFormatParsed = true
+
+		switch predefined := strings.ToUpper(verbPart); predefined {
+		case "UNIX", "TIMESTAMP":
+			formattedTime = _CICE_TF_TIMESTAMP
+		case "ANSIC":
+			formattedTime, format = _CICE_TF_ANSIC, time.ANSIC
+		case "UNIXDATE", "UNIX_DATE":
+			formattedTime, format = _CICE_TF_UNIXDATE, time.UnixDate
+		case "RUBYDATE", "RUBY_DATE":
+			formattedTime, format = _CICE_TF_RUBYDATE, time.RubyDate
+		case "RFC822":
+			formattedTime, format = _CICE_TF_RFC822, time.RFC822
+		case "RFC822Z":
+			formattedTime, format = _CICE_TF_RFC822_Z, time.RFC822Z
+		case "RFC850":
+			formattedTime, format = _CICE_TF_RFC850, time.RFC850
+		case "RFC1123":
+			formattedTime, format = _CICE_TF_RFC1123, time.RFC1123
+		case "RFC1123Z":
+			formattedTime, format = _CICE_TF_RFC1123_Z, time.RFC1123Z
+		case "RFC3339", "ISO8601":
+			formattedTime, format = _CICE_TF_RFC3339, time.RFC3339
+		case "RFC3339MILLI", "RFC3339_MILLI":
+			formattedTime, format = _CICE_TF_RFC3339_MILLI, _CICE_TIME_LAYOUT_RFC3339_MILLI
+		case "RFC3339MICRO", "RFC3339_MICRO":
+			formattedTime, format = _CICE_TF_RFC3339_MICRO, _CICE_TIME_LAYOUT_RFC3339_MICRO
+		case "RFC3339NANO", "RFC3339_NANO":
+			formattedTime, format = _CICE_TF_RFC3339_NANO, _CICE_TIME_LAYOUT_RFC3339_NANO
+		default:
+			format = verbPart
+		}
+		return true // maybe there's a time zone selector
 	})
 
+	// Formatted time is cached until the timestamp is changed
+	// with the precision of the format. Fractional seconds of the custom formats
+	// and the timestamps of micro and nano precision are not cached,
+	// since they're changed for each Entry.
+
+	var timeCache *_CICE_TimeCache
+
+	switch formattedTime {
+	case _CICE_TF_TIMESTAMP, _CICE_TF_RFC3339_MICRO, _CICE_TF_RFC3339_NANO:
+	case _CICE_TF_RFC3339_MILLI:
+		timeCache = &_CICE_TimeCache{resolution: int64(time.Millisecond)}
+	default:
+		if !strings.Contains(format, ".0") && !strings.Contains(format, ".9") &&
+			!strings.Contains(format, ",0") && !strings.Contains(format, ",9") {
+			timeCache = &_CICE_TimeCache{resolution: int64(time.Second)}
+		}
+	}
+
 	ce.formatParts = append(ce.formatParts, _CICE_FormatPart{
-		typ:   _CICE_FPT_VERB_TIME | (formattedTime << 8),
-		value: format,
+		typ:       _CICE_FPT_VERB_TIME | ((formattedTime | timeZone) << 8),
+		value:     format,
+		timeCache: timeCache,
 	})
 
 	return len(time.Now().Format(format)) + 10 // stock for some weekdays
@@ -542,6 +655,10 @@ func (ce *CI_ConsoleEncoder) rvTime(verb string) (predictedLen int) {
 
 func (ce *CI_ConsoleEncoder) rvColor(verb string) (predictedLen int) {
 
+	if ce.colors == CICE_COLORS_NONE {
+		return 0 // all colors are ignored
+	}
+
 	if idx := strings.IndexByte(verb, _CICE_VERB_SEPARATOR); idx == -1 {
 		ce.formatParts = append(ce.formatParts, _CICE_FormatPart{
 			typ: _CICE_FPT_VERB_COLOR_FOR_LEVEL,
@@ -560,16 +677,16 @@ func (ce *CI_ConsoleEncoder) rvColor(verb string) (predictedLen int) {
 	}
 }
 
-func (_ *CI_ConsoleEncoder) rvColorHelper(colorVerb string) string {
+func (ce *CI_ConsoleEncoder) rvColorHelper(colorVerb string) string {
 
-	cb := colorBuilder{}
+	cb := colorBuilder{palette: ce.palette}
 	cb.init()
 
 	(*CI_ConsoleEncoder)(nil).rvHelper(colorVerb, func(verbPart string) (continue_ bool) {
 		return cb.parseEntity(verbPart)
 	})
 
-	return cb.encode()
+	return cb.encode(ce.colors)
 }
 
 // rvBody is a part of "resolve verb" functions.
@@ -700,6 +817,8 @@ func (ce *CI_ConsoleEncoder) rvCallerFormat(f string) (predictedLen int) {
 //   - "l<text>": <text> will be written at the each new line of fields' part set.
 //   - "*<int>": <int> is how much fields are placed at the one line
 //     (by default: 4. Use <= 0 value to place all fields at the one line).
+//   - "a<int>": <int> is the width keys will be padded to. Longer keys are truncated
+//     with ellipsis. Use "a" w/o <int> to compute width for each Entry automatically.
 func (ce *CI_ConsoleEncoder) rvFields(verb string) (predictedLen int) {
 
 	ce.ff.itemsPerLine = 4
@@ -722,6 +841,15 @@ func (ce *CI_ConsoleEncoder) rvFields(verb string) (predictedLen int) {
 		case upperCased[0] == 'E':
 			ce.ff.afterValue = verbPart[1:]
 
+		case upperCased[0] == 'A':
+			if verbPart = verbPart[1:]; verbPart == "" {
+				ce.ff.alignWidth = _CICE_FIELDS_ALIGN_AUTO
+			} else if width, err := strconv.Atoi(verbPart); err == nil && width > 0 {
+				ce.ff.alignWidth = int16(ekamath.Min(width, math.MaxInt16))
+			} else {
+				return false
+			}
+
 		case verbPart[0] == '*':
 			if perLine_, err := strconv.Atoi(verbPart[1:]); err == nil {
 				if perLine_ < 0 {
@@ -753,6 +881,20 @@ func (ce *CI_ConsoleEncoder) rvStacktrace(verb string) (predictedLen int) {
 			ce.sf.beforeStack = verbPart[2:]
 		case strings.HasPrefix(verbPart, "?$"):
 			ce.sf.afterStack = verbPart[2:]
+		case verbPart == "":
+			return false
+		case verbPart == "c":
+			ce.sf.collapseRepeated = true
+		case verbPart[0] == 'n':
+			n, err := strconv.ParseInt(verbPart[1:], 10, 16)
+			if err != nil || n < 0 {
+				return false
+			}
+			ce.sf.maxFrames = int16(n)
+		case verbPart[0] == 'x' && len(verbPart) > 1:
+			// '/' is a verb separator, so '|' is used instead of it.
+			prefix := strings.ReplaceAll(verbPart[1:], "|", "/")
+			ce.sf.skipPrefixes = append(ce.sf.skipPrefixes, prefix)
 		default:
 			return false
 		}
@@ -766,6 +908,49 @@ func (ce *CI_ConsoleEncoder) rvStacktrace(verb string) (predictedLen int) {
 	return 2048
 }
 
+// rvError is a part of "resolve verb" functions.
+// rvError tries to parse 'verb' as attached ekaerr.Error's header verb and anyway
+// indicates that here will be stored the header of attached ekaerr.Error.
+//
+// Verb's arguments:
+// - For attached ekaerr.Error:
+//   - "?^<text>": <text> will be prepended to the error's header at the runtime.
+//   - "?$<text>": <text> will be appended to the error's header at the runtime.
+//   - "c:<color_part>": Colors error's header using <color_part>.
+//     Each <color_part> is the same as color verb's parameter
+//     (like "c:fg:#ff0000/c:b" for bold red color).
+func (ce *CI_ConsoleEncoder) rvError(verb string) (predictedLen int) {
+
+	colorVerb := "c"
+
+	(*CI_ConsoleEncoder)(nil).rvHelper(verb, func(verbPart string) (continue_ bool) {
+		switch {
+		case strings.HasPrefix(verbPart, "?^"):
+			ce.ef.beforeError = verbPart[2:]
+		case strings.HasPrefix(verbPart, "?$"):
+			ce.ef.afterError = verbPart[2:]
+		case strings.HasPrefix(verbPart, "c:") && len(verbPart) > 2:
+			colorVerb += string(_CICE_VERB_SEPARATOR) + verbPart[2:]
+		default:
+			return false
+		}
+		return true
+	})
+
+	if colorVerb != "c" {
+		if ce.ef.color = ce.rvColorHelper(colorVerb); ce.ef.color != "" {
+			ce.ef.colorReset = ce.rvColorHelper("c/0")
+		}
+	}
+
+	ce.formatParts = append(ce.formatParts, _CICE_FormatPart{
+		typ: _CICE_FPT_VERB_ERROR,
+	})
+
+	return 128 + len(ce.ef.beforeError) + len(ce.ef.afterError) +
+		len(ce.ef.color) + len(ce.ef.colorReset)
+}
+
 func (ce *CI_ConsoleEncoder) encodeJustText(to []byte, fp _CICE_FormatPart) []byte {
 	return bufw(to, fp.value)
 }
@@ -784,7 +969,7 @@ func (ce *CI_ConsoleEncoder) encodeLevel(to []byte, fp _CICE_FormatPart, e *Entr
 	case _CICE_LF_FULL_NORMAL:
 		formattedLevel = e.Level.String()
 	case _CICE_LF_FULL_UPPER_CASE:
-		formattedLevel = strings.ToUpper(e.Level.String())
+		formattedLevel = e.Level.ToUpper()
 	}
 
 	return bufw(to, formattedLevel)
@@ -792,34 +977,37 @@ func (ce *CI_ConsoleEncoder) encodeLevel(to []byte, fp _CICE_FormatPart, e *Entr
 
 func (ce *CI_ConsoleEncoder) encodeTime(e *Entry, fp _CICE_FormatPart, to []byte) []byte {
 
-	formattedTime := ""
+	t := e.Time
 
-	switch fp.typ.Data() {
-	case _CICE_TF_TIMESTAMP:
-		formattedTime = strconv.FormatInt(e.Time.Unix(), 10)
-	case _CICE_TF_ANSIC:
-		formattedTime = e.Time.Format(time.ANSIC)
-	case _CICE_TF_UNIXDATE:
-		formattedTime = e.Time.Format(time.UnixDate)
-	case _CICE_TF_RUBYDATE:
-		formattedTime = e.Time.Format(time.RubyDate)
-	case _CICE_TF_RFC822:
-		formattedTime = e.Time.Format(time.RFC822)
-	case _CICE_TF_RFC822_Z:
-		formattedTime = e.Time.Format(time.RFC822Z)
-	case _CICE_TF_RFC850:
-		formattedTime = e.Time.Format(time.RFC850)
-	case _CICE_TF_RFC1123:
-		formattedTime = e.Time.Format(time.RFC1123)
-	case _CICE_TF_RFC1123_Z:
-		formattedTime = e.Time.Format(time.RFC1123Z)
-	case _CICE_TF_RFC3339:
-		formattedTime = e.Time.Format(time.RFC3339)
-	default:
-		formattedTime = e.Time.Format(fp.value)
+	switch fp.typ.Data() &^ _CICE_TF_MASK_FORMAT {
+	case _CICE_TF_TZ_UTC:
+		t = t.UTC()
+	case _CICE_TF_TZ_LOCAL:
+		t = t.Local()
+	}
+
+	if fp.typ.Data()&_CICE_TF_MASK_FORMAT == _CICE_TF_TIMESTAMP {
+		return strconv.AppendInt(to, t.Unix(), 10)
+	}
+
+	if fp.timeCache == nil {
+		return t.AppendFormat(to, fp.value)
 	}
 
-	return bufw(to, formattedTime)
+	key := t.UnixNano()
+	if r := key % fp.timeCache.resolution; r < 0 {
+		key -= r + fp.timeCache.resolution
+	} else {
+		key -= r
+	}
+
+	cached, _ := fp.timeCache.last.Load().(*_CICE_TimeCacheEntry)
+	if cached == nil || cached.key != key || cached.loc != t.Location() {
+		cached = &_CICE_TimeCacheEntry{key: key, loc: t.Location(), formatted: t.Format(fp.value)}
+		fp.timeCache.last.Store(cached)
+	}
+
+	return bufw(to, cached.formatted)
 }
 
 func (ce *CI_ConsoleEncoder) encodeColor(to []byte, fp _CICE_FormatPart) []byte {
@@ -827,7 +1015,12 @@ func (ce *CI_ConsoleEncoder) encodeColor(to []byte, fp _CICE_FormatPart) []byte
 }
 
 func (ce *CI_ConsoleEncoder) encodeColorForLevel(to []byte, e *Entry) []byte {
-	if color := ce.colorMap[e.Level]; color != "" {
+	color := ce.colorMap[e.Level]
+	if color == "" && e.Level.IsCustom() {
+		// Custom level w/o color or registered after CI_ConsoleEncoder is built.
+		color = ce.colorMap[e.Level.Base()]
+	}
+	if color != "" {
 		return bufw(to, color)
 	}
 	return to
@@ -853,25 +1046,84 @@ func (ce *CI_ConsoleEncoder) encodeBody(to []byte, e *Entry) []byte {
 	return to
 }
 
-func (ce *CI_ConsoleEncoder) encodeCaller(to []byte, e *Entry) []byte {
+// encodeError encodes the header of attached ekaerr.Error:
+// its class name, its ID and its top message (if it's not used as Entry's body), like:
+//
+//   "<class_name> (<error_id>): <message>".
+//
+// Does nothing if there is no attached ekaerr.Error.
+func (ce *CI_ConsoleEncoder) encodeError(to []byte, e *Entry) []byte {
 
-	var frame *ekasys.StackFrame
+	if e.ErrLetter == nil {
+		return to
+	}
 
-	switch {
-	case len(e.LogLetter.StackTrace) > 0:
-		frame = &e.LogLetter.StackTrace[0]
+	var className, errorID, errMessage string
+	for i, n := 0, len(e.ErrLetter.SystemFields); i < n; i++ {
+		switch f := &e.ErrLetter.SystemFields[i]; f.BaseType() {
+		case ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME:
+			className = f.SValue
+		case ekaletter.KIND_SYS_TYPE_EKAERR_UUID:
+			errorID = f.SValue
+		}
+	}
 
-	case e.ErrLetter != nil:
-		frame = &e.ErrLetter.StackTrace[0]
+	for i := len(e.ErrLetter.Messages) - 1; i >= 0 && errMessage == ""; i-- {
+		errMessage = e.ErrLetter.Messages[i].Body
+	}
 
-	default:
+	if className == "" && errorID == "" && errMessage == "" {
+		return to
+	}
+
+	if ce.ef.beforeError != "" {
+		to = bufw(to, ce.ef.beforeError)
+	}
+	if ce.ef.color != "" {
+		to = bufw(to, ce.ef.color)
+	}
+
+	to = bufw(to, className)
+	if errorID != "" {
+		if className != "" {
+			to = bufw(to, " ")
+		}
+		to = bufw(to, "(")
+		to = bufw(to, errorID)
+		to = bufw(to, ")")
+	}
+	if errMessage != "" {
+		if className != "" || errorID != "" {
+			to = bufw(to, ": ")
+		}
+		to = bufw(to, errMessage)
+	}
+
+	if ce.ef.colorReset != "" {
+		to = bufw(to, ce.ef.colorReset)
+	}
+	if ce.ef.afterError != "" {
+		to = bufw(to, ce.ef.afterError)
+	}
+
+	return to
+}
+
+func (ce *CI_ConsoleEncoder) encodeCaller(to []byte, e *Entry) []byte {
+
+	frame := e.Caller()
+	if frame == nil {
 		return to
 	}
 
 	return ce.encodeStackFrame(to, frame, nil, ekaletter.LetterMessage{})
 }
 
-func (ce *CI_ConsoleEncoder) encodeFields(to []byte, fs, addFs []ekaletter.LetterField, isErrors, addPreEncoded bool) []byte {
+// encodeFields writes 'fs' and then 'addFs' fields to 'to'.
+// The system correlation ID field with 'dupCorrelationID' value is skipped,
+// since it's already written as Entry's field.
+func (ce *CI_ConsoleEncoder) encodeFields(
+	to []byte, fs, addFs []ekaletter.LetterField, isErrors, addPreEncoded bool, dupCorrelationID string) []byte {
 
 	if len(fs) == 0 && len(addFs) == 0 {
 		return to
@@ -883,12 +1135,21 @@ func (ce *CI_ConsoleEncoder) encodeFields(to []byte, fs, addFs []ekaletter.Lette
 
 	var (
 		unnamedFieldIdx, writtenFields int16
+		alignWidth                     = ce.ff.alignWidth
 	)
 
+	if alignWidth == _CICE_FIELDS_ALIGN_AUTO {
+		alignWidth = ce.fieldsKeyAlignWidth(fs, addFs)
+	}
+
 	addField := func(to []byte, f *ekaletter.LetterField, isErrors bool, unnamedFieldIex, writtenFields *int16) []byte {
 		if strings.HasPrefix(f.Key, "sys.") {
 			return to
 		}
+		if dupCorrelationID != "" && f.IsSystem() &&
+			f.BaseType() == ekaletter.KIND_SYS_TYPE_CORRELATION_ID && f.SValue == dupCorrelationID {
+			return to
+		}
 
 		keyBak := f.Key
 
@@ -897,7 +1158,7 @@ func (ce *CI_ConsoleEncoder) encodeFields(to []byte, fs, addFs []ekaletter.Lette
 		}
 
 		toLenBak := len(to)
-		to = ce.encodeField(to, *f, isErrors, *writtenFields)
+		to = ce.encodeField(to, *f, isErrors, *writtenFields, alignWidth)
 		if len(to) != toLenBak {
 			*writtenFields++
 		}
@@ -944,10 +1205,81 @@ func (ce *CI_ConsoleEncoder) encodeFields(to []byte, fs, addFs []ekaletter.Lette
 	return to
 }
 
-func (ce *CI_ConsoleEncoder) encodeField(to []byte, f ekaletter.LetterField, isErrors bool, fieldNum int16) []byte {
+// fieldsKeyAlignWidth returns the width the keys of 'fs' and 'addFs' must be
+// aligned to in the auto alignment mode. It's the width of the longest key
+// that will be written but not greater than _CICE_FIELDS_ALIGN_AUTO_MAX.
+func (ce *CI_ConsoleEncoder) fieldsKeyAlignWidth(fs, addFs []ekaletter.LetterField) int16 {
 
-	if f.IsSystem() && f.BaseType() == ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_ID {
-		return to
+	var unnamedFieldIdx, width int16
+
+	keyWidth := func(f *ekaletter.LetterField) {
+		if strings.HasPrefix(f.Key, "sys.") || f.IsSystem() {
+			return
+		}
+		key := strings.TrimSpace(f.Key)
+		if key == "" {
+			key = f.KeyOrUnnamed(&unnamedFieldIdx)
+		}
+		if n := int16(ekamath.Min(utf8.RuneCountInString(key), math.MaxInt16)); n > width {
+			width = n
+		}
+	}
+
+	for i, n := 0, len(fs); i < n; i++ {
+		keyWidth(&fs[i])
+	}
+	for i, n := 0, len(addFs); i < n; i++ {
+		keyWidth(&addFs[i])
+	}
+
+	return ekamath.Min(width, _CICE_FIELDS_ALIGN_AUTO_MAX)
+}
+
+// encodeFieldKey writes 'key' to 'to' padding it by spaces to the 'alignWidth'
+// or truncating it with ellipsis if it's longer than 'alignWidth'.
+// If 'alignWidth' <= 0, 'key' is written as is.
+func (ce *CI_ConsoleEncoder) encodeFieldKey(to []byte, key string, alignWidth int16) []byte {
+
+	if alignWidth <= 0 {
+		return bufw(to, key)
+	}
+
+	keyWidth := utf8.RuneCountInString(key)
+	switch width := int(alignWidth); {
+
+	case keyWidth > width:
+		// Truncate the key (by runes, not bytes) and write an ellipsis
+		// instead of the cut part.
+		cut := 0
+		for i := 0; i < width-1; i++ {
+			_, runeLen := utf8.DecodeRuneInString(key[cut:])
+			cut += runeLen
+		}
+		to = bufw(to, key[:cut])
+		to = bufw(to, _CICE_FIELDS_ALIGN_ELLIPSIS)
+
+	default:
+		to = bufw(to, key)
+		to = bufgr(to, width-keyWidth)
+		for i := keyWidth; i < width; i++ {
+			to = append(to, ' ')
+		}
+	}
+
+	return to
+}
+
+func (ce *CI_ConsoleEncoder) encodeField(to []byte, f ekaletter.LetterField, isErrors bool, fieldNum, alignWidth int16) []byte {
+
+	if f.IsSystem() {
+		switch f.BaseType() {
+		case ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_ID:
+			return to
+		case ekaletter.KIND_SYS_TYPE_EKAERR_FINGERPRINT, ekaletter.KIND_SYS_TYPE_CORRELATION_ID:
+			if f.SValue == "" {
+				return to
+			}
+		}
 	}
 
 	// Maybe field wants to be started with new line?
@@ -961,7 +1293,7 @@ func (ce *CI_ConsoleEncoder) encodeField(to []byte, f ekaletter.LetterField, isE
 		to = bufw(to, "\n")
 	}
 
-	if wasNewLine := to[len(to)-1] == '\n'; wasNewLine && !isErrors && len(ce.ff.afterNewLine) > 0 {
+	if wasNewLine := len(to) > 0 && to[len(to)-1] == '\n'; wasNewLine && !isErrors && len(ce.ff.afterNewLine) > 0 {
 		to = bufw(to, ce.ff.afterNewLine)
 	} else if wasNewLine && isErrors && len(ce.ff.afterNewLineForError) > 0 {
 		to = bufw(to, ce.ff.afterNewLineForError)
@@ -972,7 +1304,7 @@ func (ce *CI_ConsoleEncoder) encodeField(to []byte, f ekaletter.LetterField, isE
 	if ce.ff.beforeKey != "" {
 		to = bufw(to, ce.ff.beforeKey)
 	}
-	to = bufw(to, f.Key)
+	to = ce.encodeFieldKey(to, f.Key, alignWidth)
 	if ce.ff.afterKey != "" {
 		to = bufw(to, ce.ff.afterKey)
 	}
@@ -989,11 +1321,17 @@ func (ce *CI_ConsoleEncoder) encodeFieldValue(to []byte, f ekaletter.LetterField
 	if f.Kind.IsSystem() {
 		switch f.Kind.BaseType() {
 
-		case ekaletter.KIND_SYS_TYPE_EKAERR_UUID, ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME:
+		case ekaletter.KIND_SYS_TYPE_EKAERR_UUID, ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME,
+			ekaletter.KIND_SYS_TYPE_TRACE_ID, ekaletter.KIND_SYS_TYPE_SPAN_ID,
+			ekaletter.KIND_SYS_TYPE_TRACE_FLAGS, ekaletter.KIND_SYS_TYPE_TRACE_STATE,
+			ekaletter.KIND_SYS_TYPE_EKAERR_FINGERPRINT, ekaletter.KIND_SYS_TYPE_CORRELATION_ID:
 			to = bufw(to, `"`)
 			to = bufw(to, f.SValue)
 			to = bufw(to, `"`)
 
+		case ekaletter.KIND_SYS_TYPE_RAW_JSON:
+			to = bufw(to, f.SValue)
+
 		case ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_ID:
 			to = strconv.AppendInt(to, f.IValue, 10)
 
@@ -1036,7 +1374,9 @@ func (ce *CI_ConsoleEncoder) encodeFieldValue(to []byte, f ekaletter.LetterField
 			to = strconv.AppendInt(to, f.IValue, 16)
 
 		case ekaletter.KIND_TYPE_STRING:
-			to = strconv.AppendQuote(to, f.SValue)
+			to = append(to, '"')
+			to = ekaenc.AppendJSONEscapedString(to, ekastr.S2B(f.SValue))
+			to = append(to, '"')
 
 		case ekaletter.KIND_TYPE_COMPLEX_64:
 			r := math.Float32frombits(uint32(f.IValue >> 32))
@@ -1099,8 +1439,7 @@ func (ce *CI_ConsoleEncoder) encodeStacktrace(to []byte, e *Entry) []byte {
 		isLightweightError = len(trace) == 0
 	}
 
-	n := int16(len(trace))
-	if n == 0 && !isLightweightError {
+	if len(trace) == 0 && !isLightweightError {
 		return to
 	}
 
@@ -1109,18 +1448,19 @@ func (ce *CI_ConsoleEncoder) encodeStacktrace(to []byte, e *Entry) []byte {
 	}
 
 	var (
-		fi = 0 // fi for fields' index
-		mi = 0 // mi for messages' index
-
-		fields   []ekaletter.LetterField   // We don't use log's fields and messages here
-		messages []ekaletter.LetterMessage // 'cause they will be written in a different place.
+		fields     []ekaletter.LetterField   // We don't use log's fields and messages here
+		messages   []ekaletter.LetterMessage // 'cause they will be written in a different place.
+		boundaries []ekaletter.LetterBoundary
 	)
 
 	if e.ErrLetter != nil {
 		fields = e.ErrLetter.Fields
 		messages = e.ErrLetter.Messages
+		boundaries = e.ErrLetter.Boundaries
 	}
 
+	n := int16(len(trace))
+
 	// Simulate stacktrace's length if it's a lightweight error.
 
 	if isLightweightError {
@@ -1138,6 +1478,53 @@ func (ce *CI_ConsoleEncoder) encodeStacktrace(to []byte, e *Entry) []byte {
 		n = ekamath.Max(fieldGreatestFrameIdx, messageGreatestFrameIdx)
 	}
 
+	to = ce.encodeStackFrames(to, trace, fields, messages, boundaries, n, isLightweightError)
+
+	if e.ErrLetter != nil && len(e.ErrLetter.Children) > 0 {
+		to = ce.encodeErrorChildren(to, e.ErrLetter.Children, "")
+	}
+
+	if nt := len(to) - 1; to[nt] == '\n' {
+		to = to[:nt]
+	}
+
+	if ce.sf.afterStack != "" {
+		to = bufw(to, ce.sf.afterStack)
+	}
+
+	return to
+}
+
+// encodeStackFrames encodes first 'n' stack frames of 'trace' along with
+// their messages and fields. If it's a lightweight error, there is no 'trace',
+// and only messages and fields of first 'n' (simulated) frames are encoded.
+// If there are 'boundaries', the frames are grouped to the sections
+// of logical layers, each of which starts with its header.
+func (ce *CI_ConsoleEncoder) encodeStackFrames(
+
+	to []byte,
+	trace ekasys.StackTrace,
+	fields []ekaletter.LetterField,
+	messages []ekaletter.LetterMessage,
+	boundaries []ekaletter.LetterBoundary,
+	n int16,
+	isLightweightError bool,
+
+) []byte {
+
+	var (
+		fi = 0 // fi for fields' index
+		mi = 0 // mi for messages' index
+
+		section        = ""    // name of the layer of last encoded frame
+		sectionStarted = false // whether any section's header is encoded
+
+		encoded      int16 = 0  // number of encoded frames
+		lastShownIdx int16 = -2 // index of last encoded or collapsed frame
+		repeated           = 0  // number of collapsed frames since last encoded one
+		omitted            = 0  // number of frames omitted because of frames limit
+	)
+
 	for i := int16(0); i < n; i++ {
 		messageForFrame := ekaletter.LetterMessage{}
 		fieldsForFrame := []ekaletter.LetterField(nil)
@@ -1158,6 +1545,7 @@ func (ce *CI_ConsoleEncoder) encodeStacktrace(to []byte, e *Entry) []byte {
 
 		if fiEnd != 0 {
 			fieldsForFrame = fields[fi:fiEnd]
+			fi = fiEnd
 		}
 
 		var frame *ekasys.StackFrame = nil
@@ -1165,15 +1553,132 @@ func (ce *CI_ConsoleEncoder) encodeStacktrace(to []byte, e *Entry) []byte {
 			frame = &trace[i]
 		}
 
+		// Frames that have attached messages or fields are always encoded,
+		// thus no error's data is lost.
+		if frame != nil && messageForFrame.Body == "" && len(fieldsForFrame) == 0 {
+			switch {
+			case ce.sf.collapseRepeated && lastShownIdx == i-1 &&
+				trace[i-1].Function == frame.Function && trace[i-1].Line == frame.Line:
+				repeated++
+				lastShownIdx = i
+				continue
+
+			case ce.isStackFrameSkipped(frame):
+				continue
+
+			case ce.sf.maxFrames > 0 && encoded >= ce.sf.maxFrames:
+				omitted++
+				continue
+			}
+		}
+
+		to = ce.encodeRepeatedStackFrames(to, repeated)
+		repeated = 0
+
+		if len(boundaries) > 0 {
+			if name := ekaletter.LBoundaryOf(boundaries, i); !sectionStarted || name != section {
+				to = ce.encodeStackSectionHeader(to, name)
+				section, sectionStarted = name, true
+			}
+		}
+
 		to = ce.encodeStackFrame(to, frame, fieldsForFrame, messageForFrame)
+		lastShownIdx = i
+		encoded++
 	}
 
-	if nt := len(to) - 1; to[nt] == '\n' {
-		to = to[:nt]
+	to = ce.encodeRepeatedStackFrames(to, repeated)
+
+	if omitted > 0 {
+		to = bufw(to, "… +")
+		to = bufw(to, strconv.Itoa(omitted))
+		to = bufw(to, " more\n")
 	}
 
-	if ce.sf.afterStack != "" {
-		to = bufw(to, ce.sf.afterStack)
+	return to
+}
+
+// isStackFrameSkipped reports whether 'frame' must not be encoded,
+// because its function has one of prefixes, set by "x<prefix>" stacktrace's verb.
+func (ce *CI_ConsoleEncoder) isStackFrameSkipped(frame *ekasys.StackFrame) bool {
+	for i, n := 0, len(ce.sf.skipPrefixes); i < n; i++ {
+		if strings.HasPrefix(frame.Function, ce.sf.skipPrefixes[i]) {
+			return true
+		}
+	}
+	return false
+}
+
+// encodeRepeatedStackFrames writes a marker of 'repeated' number of collapsed
+// stack frames, if it's not zero.
+func (ce *CI_ConsoleEncoder) encodeRepeatedStackFrames(to []byte, repeated int) []byte {
+	if repeated > 0 {
+		to = bufw(to, "… repeated ")
+		to = bufw(to, strconv.Itoa(repeated))
+		to = bufw(to, " more time(s)\n")
+	}
+	return to
+}
+
+// encodeStackSectionHeader writes a header of the section of stack frames,
+// that belong to the logical layer 'name'
+// (or don't belong to any layer if 'name' is empty).
+// Read more: ekaerr.Error.Boundary().
+func (ce *CI_ConsoleEncoder) encodeStackSectionHeader(to []byte, name string) []byte {
+	if name == "" {
+		name = "…"
+	}
+	to = bufw(to, "── ")
+	to = bufw(to, name)
+	to = bufw(to, " ──\n")
+	return to
+}
+
+// encodeErrorChildren encodes ekaletter.Letter of errors that are aggregated
+// by some ekaerr.Error as a numbered list. Each item starts with its number
+// (prepended by 'numPrefix' for nested aggregated errors) and class name
+// followed by its stacktrace (with messages and fields).
+func (ce *CI_ConsoleEncoder) encodeErrorChildren(
+
+	to []byte,
+	children []*ekaletter.Letter,
+	numPrefix string,
+
+) []byte {
+
+	for i, n := 0, len(children); i < n; i++ {
+		child := children[i]
+
+		if l := len(to); l > 0 && to[l-1] != '\n' {
+			to = bufwc(to, '\n')
+		}
+
+		num := numPrefix + strconv.Itoa(i+1)
+
+		to = bufwc(to, '[')
+		to = bufw(to, num)
+		to = bufw(to, "] ")
+
+		for j, m := 0, len(child.SystemFields); j < m; j++ {
+			if child.SystemFields[j].BaseType() == ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME {
+				to = bufw(to, child.SystemFields[j].SValue)
+				break
+			}
+		}
+
+		to = bufwc(to, '\n')
+
+		// Lightweight errors have only one (simulated) stack frame,
+		// because Throw() does nothing for them.
+		if n := int16(len(child.StackTrace)); n > 0 {
+			to = ce.encodeStackFrames(to, child.StackTrace, child.Fields, child.Messages, child.Boundaries, n, false)
+		} else {
+			to = ce.encodeStackFrames(to, nil, child.Fields, child.Messages, child.Boundaries, 1, true)
+		}
+
+		if len(child.Children) > 0 {
+			to = ce.encodeErrorChildren(to, child.Children, num+".")
+		}
 	}
 
 	return to
@@ -1231,6 +1736,10 @@ func (ce *CI_ConsoleEncoder) encodeStackFrame(
 		to = bufw(to, frame.DoFormat())
 	}
 
+	if frame != nil {
+		to = ce.encodeSourceSnippet(to, frame)
+	}
+
 	if message.Body != "" || len(fields) > 0 {
 
 		if frame != nil {
@@ -1247,7 +1756,7 @@ func (ce *CI_ConsoleEncoder) encodeStackFrame(
 		}
 
 		lToBefore := len(to)
-		to = ce.encodeFields(to, fields, nil, true, false)
+		to = ce.encodeFields(to, fields, nil, true, false, "")
 
 		// ce.encodeFields may write no fields. Then we must clear last "\n"
 		if len(to) == lToBefore {
@@ -1262,22 +1771,40 @@ func (ce *CI_ConsoleEncoder) encodeStackFrame(
 	return to
 }
 
-func (dc *_CICE_DropColors) Write(p []byte) (n int, err error) {
-	if n, err = dc.buf.Write(p); err != nil && err != io.EOF {
-		return n, err
-	}
-	b := dc.buf.Bytes()
-	j := 0
-	for i, n, write := 0, len(b), true; i < n; i++ {
-		if write && b[i] == '\033' {
-			write = false
-			i += 2
-		} else if !write && b[i] == 'm' {
-			write = true
-		} else if write {
-			b[j] = b[i]
-			j++
+// encodeSourceSnippet encodes the source snippet of 'frame' (if any),
+// each line of which is preceded by new line and its number
+// (the frame's one is marked by '>'). Read more: ekasys.EnableSourceSnippets().
+func (ce *CI_ConsoleEncoder) encodeSourceSnippet(to []byte, frame *ekasys.StackFrame) []byte {
+
+	snippet := frame.SourceSnippet()
+	if len(snippet) == 0 {
+		return to
+	}
+
+	lineNumWidth := len(strconv.Itoa(snippet[len(snippet)-1].Line))
+
+	for i := range snippet {
+		to = bufw(to, "\n    ")
+		if snippet[i].Line == frame.Line {
+			to = bufw(to, "> ")
+		} else {
+			to = bufw(to, "  ")
 		}
+		lineNum := strconv.Itoa(snippet[i].Line)
+		for j := len(lineNum); j < lineNumWidth; j++ {
+			to = bufwc(to, ' ')
+		}
+		to = bufw(to, lineNum)
+		to = bufw(to, " | ")
+		to = bufw(to, snippet[i].Text)
+	}
+
+	return to
+}
+
+func (dc *_CICE_DropColors) Write(p []byte) (n int, err error) {
+	if _, err = dc.dest.Write(CI_PostProcessorDropColors(p)); err != nil {
+		return 0, err
 	}
-	return dc.dest.Write(b[:j])
+	return len(p), nil
 }
@@ -0,0 +1,247 @@
+// Copyright © 2020-2021. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekasys"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConsoleEncoderOutput(format string, cb func()) string {
+
+	b := bytes.NewBuffer(nil)
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat(format)).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(b)
+
+	ekalog.ReplaceIntegrator(ci)
+	cb()
+
+	return b.String()
+}
+
+func TestCI_ConsoleEncoder_FieldsAlignment(t *testing.T) {
+
+	out := testConsoleEncoderOutput(">{{f/*1/a6/v = }}", func() {
+		ekalog.Info("", "k", 1, "key", 2, "very_long_key", 3)
+	})
+	assert.Equal(t, ">k      = 1\nkey    = 2\nvery_… = 3", out)
+
+	out = testConsoleEncoderOutput(">{{f/*1/a/v = }}", func() {
+		ekalog.Info("", "k", 1, "key", 2)
+	})
+	assert.Equal(t, ">k   = 1\nkey = 2", out)
+}
+
+func TestCI_ConsoleEncoder_Error(t *testing.T) {
+
+	err := ekaerr.IllegalArgument.New("bad value")
+	errID := err.ID()
+
+	out := testConsoleEncoderOutput(">{{e/?^[/?$] }}{{m/?$ }}", func() {
+		ekalog.Errore("", err)
+	})
+	assert.Equal(t, ">["+err.Class().Name()+" ("+errID+")] bad value ", out)
+
+	err = ekaerr.IllegalArgument.New("bad value")
+	errID = err.ID()
+
+	out = testConsoleEncoderOutput(">{{e/?^[/?$] }}{{m/?$ }}", func() {
+		ekalog.Errore("failed", err)
+	})
+	assert.Equal(t, ">["+err.Class().Name()+" ("+errID+"): bad value] failed ", out)
+
+	out = testConsoleEncoderOutput(">{{e/?^[/?$] }}{{m/?$ }}", func() {
+		ekalog.Info("no error")
+	})
+	assert.Equal(t, ">no error ", out)
+}
+
+func TestCI_ConsoleEncoder_ErrorCorrelationID(t *testing.T) {
+
+	out := testConsoleEncoderOutput("{{f}}", func() {
+		ekalog.Errore("", ekaerr.IllegalArgument.New("bad value"))
+	})
+	assert.NotContains(t, out, "correlation_id")
+
+	out = testConsoleEncoderOutput("{{f}}", func() {
+		ekalog.WithCorrelationID("corr-1").Errore("", ekaerr.IllegalArgument.New("bad value"))
+	})
+	assert.Equal(t, 1, strings.Count(out, "correlation_id"))
+	assert.Contains(t, out, `"corr-1"`)
+
+	out = testConsoleEncoderOutput("{{f}}", func() {
+		err := ekaerr.IllegalArgument.New("bad value").WithCorrelationID("corr-2")
+		ekalog.WithCorrelationID("corr-1").Errore("", err)
+	})
+	assert.Equal(t, 2, strings.Count(out, "correlation_id"))
+	assert.Contains(t, out, `"corr-1"`)
+	assert.Contains(t, out, `"corr-2"`)
+}
+
+func testConsoleEncoderRecursiveError(depth int) *ekaerr.Error {
+	if depth == 0 {
+		return ekaerr.IllegalArgument.New("bad value")
+	}
+	return testConsoleEncoderRecursiveError(depth - 1)
+}
+
+func TestCI_ConsoleEncoder_StacktraceLimits(t *testing.T) {
+
+	err := testConsoleEncoderRecursiveError(4)
+	out := testConsoleEncoderOutput(">{{s/c/xtesting./xruntime.}}", func() {
+		ekalog.Errore("", err)
+	})
+	assert.Equal(t, 2, strings.Count(out, "testConsoleEncoderRecursiveError"))
+	assert.Contains(t, out, "… repeated 3 more time(s)")
+	assert.NotContains(t, out, "tRunner")
+	assert.NotContains(t, out, "goexit")
+
+	err = testConsoleEncoderRecursiveError(4)
+	out = testConsoleEncoderOutput(">{{s/n2}}", func() {
+		ekalog.Errore("", err)
+	})
+	assert.Equal(t, 2, strings.Count(out, "testConsoleEncoderRecursiveError"))
+	assert.Contains(t, out, "… +4 more")
+}
+
+func TestCI_ConsoleEncoder_SourceSnippet(t *testing.T) {
+
+	ekasys.EnableSourceSnippets(1)
+	defer ekasys.EnableSourceSnippets(0)
+
+	out := testConsoleEncoderOutput("{{m}}{{s}}", func() {
+		ekalog.Errore("", ekaerr.IllegalArgument.New("snippet")) // snippet marker
+	})
+
+	assert.Regexp(t, `\n    > \d+ \| \t\tekalog\.Errore\(.*// snippet marker\n`, out)
+}
+
+func TestCI_ConsoleEncoder_Colors(t *testing.T) {
+
+	out := func(ce *ekalog.CI_ConsoleEncoder, format string) string {
+		b := bytes.NewBuffer(nil)
+		ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+			WithEncoder(ce.SetFormat(format)).
+			WithMinLevel(ekalog.LEVEL_DEBUG).
+			WriteTo(b))
+		ekalog.Info("")
+		return b.String()
+	}
+
+	const format = "{{c/fg:#87d7ff}}a{{c/bg:#ff0000/b}}b{{c/bg:ascii:31}}c{{c/0}}"
+
+	assert.Equal(t, "\033[38;5;117ma\033[01;48;5;9mb\033[41mc\033[0m",
+		out(new(ekalog.CI_ConsoleEncoder), format))
+
+	assert.Equal(t, "\033[38;2;135;215;255ma\033[01;48;2;255;0;0mb\033[41mc\033[0m",
+		out(new(ekalog.CI_ConsoleEncoder).SetColors(ekalog.CICE_COLORS_TRUECOLOR), format))
+
+	assert.Equal(t, "\033[37ma\033[01;101mb\033[41mc\033[0m",
+		out(new(ekalog.CI_ConsoleEncoder).SetColors(ekalog.CICE_COLORS_ASCII), format))
+
+	assert.Equal(t, "abc",
+		out(new(ekalog.CI_ConsoleEncoder).SetColors(ekalog.CICE_COLORS_NONE), format))
+
+	// Themes.
+
+	assert.Equal(t, "\033[38;2;38;139;210mx\033[38;2;42;161;152my",
+		out(new(ekalog.CI_ConsoleEncoder).
+			SetTheme("solarized-dark").
+			SetColors(ekalog.CICE_COLORS_TRUECOLOR), "{{c}}x{{c/fg:@Accent}}y"))
+
+	ekalog.CICE_RegisterTheme("test", ekalog.CI_ConsoleTheme{
+		Levels: map[ekalog.Level]string{
+			ekalog.LEVEL_INFO:  "c/fg:@info/u",
+			ekalog.LEVEL_ERROR: "c/fg:#ff0000",
+		},
+		Colors: map[string]string{
+			"info": "ascii:34",
+			"loop": "@info",
+		},
+	})
+	assert.Contains(t, ekalog.CICE_Themes(), "test")
+
+	assert.Equal(t, "\033[04;34mxc/@loop",
+		out(new(ekalog.CI_ConsoleEncoder).SetTheme("test"), "{{c}}x{{c/@loop}}"))
+
+	// SetColorFor() overwrites theme, the order of calls doesn't matter.
+	assert.Equal(t, "\033[38;2;1;2;3mx",
+		out(new(ekalog.CI_ConsoleEncoder).
+			SetColorFor(ekalog.LEVEL_INFO, "c/fg:rgb(1,2,3)").
+			SetTheme("test").
+			SetColors(ekalog.CICE_COLORS_TRUECOLOR), "{{c}}x"))
+}
+
+func TestCI_ConsoleEncoder_Time(t *testing.T) {
+
+	before := time.Now().Truncate(time.Second)
+
+	out := testConsoleEncoderOutput("{{t/RFC3339MILLI/UTC}}|{{t/RFC3339_NANO}}|{{t/utc}}|{{t/UNIX/LOCAL}}#", func() {
+		ekalog.Info("first")
+		ekalog.Info("second")
+	})
+
+	lines := strings.Split(strings.TrimSuffix(out, "#"), "#")
+	require.Len(t, lines, 2)
+
+	for _, line := range lines {
+		parts := strings.Split(line, "|")
+		require.Len(t, parts, 4, line)
+
+		assert.Len(t, parts[0], len("2006-01-02T15:04:05.000Z"), parts[0])
+		assert.True(t, strings.HasSuffix(parts[0], "Z"), parts[0])
+		milli, err := time.Parse(time.RFC3339Nano, parts[0])
+		require.NoError(t, err)
+		assert.False(t, milli.Before(before))
+
+		nano, err := time.Parse(time.RFC3339Nano, parts[1])
+		require.NoError(t, err)
+		assert.Regexp(t, `\.\d{9}`, parts[1])
+		assert.Equal(t, milli, nano.Truncate(time.Millisecond).UTC())
+
+		def, err := time.ParseInLocation("Mon Jan 02 15:04:05", parts[2], time.UTC)
+		require.NoError(t, err)
+		assert.Equal(t, milli.Truncate(time.Second).Format("01-02 15:04:05"), def.Format("01-02 15:04:05"))
+
+		assert.Equal(t, strconv.FormatInt(milli.Unix(), 10), parts[3])
+	}
+}
+
+func testConsoleEncoderRepo() *ekaerr.Error {
+	return ekaerr.IllegalState.New("no rows").Boundary("repo").Throw()
+}
+
+func testConsoleEncoderService() *ekaerr.Error {
+	return testConsoleEncoderRepo().AddMessage("get user").Boundary("service").Throw()
+}
+
+func TestCI_ConsoleEncoder_StackSections(t *testing.T) {
+
+	out := testConsoleEncoderOutput("{{s/xtesting./xruntime.}}", func() {
+		ekalog.Errore("failed", testConsoleEncoderService())
+	})
+
+	assert.Regexp(t, `(?s)^── repo ──\n[^\n]*testConsoleEncoderRepo .*`+
+		`── service ──\n[^\n]*testConsoleEncoderService [^\n]*\nget user\n`+
+		`── … ──\n[^\n]*TestCI_ConsoleEncoder_StackSections`, out)
+
+	out = testConsoleEncoderOutput("{{s/xtesting./xruntime.}}", func() {
+		ekalog.Errore("failed", testConsoleEncoderRecursiveError(0))
+	})
+	assert.NotContains(t, out, "──")
+}
@@ -0,0 +1,221 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type (
+	// CI_ConsoleTheme is a named set of CI_ConsoleEncoder's colors.
+	// Register it using CICE_RegisterTheme() and apply using
+	// CI_ConsoleEncoder.SetTheme().
+	CI_ConsoleTheme struct {
+
+		// Levels are the default colors of levels (used by "{{c}}" verb).
+		// The values are the same as CI_ConsoleEncoder.SetColorFor() accepts,
+		// like "c/fg:#268bd2/b". The level w/o color gets the "default" theme's one.
+		Levels map[Level]string
+
+		// Colors are the named colors, that may be referenced from the color verbs
+		// as "@<name>" like "{{c/fg:@muted/b}}" or "{{c/bg:@accent}}".
+		// The values are the colors w/o "fg:" or "bg:" prefixes,
+		// like "#586e75", "rgb(88,110,117)", "ascii:90" or "244".
+		// The names are case-insensitive.
+		//
+		// All built-in themes have "muted", "accent" and "highlight" colors.
+		Colors map[string]string
+	}
+
+	// CI_ConsoleColors is a colors capability of the terminal CI_ConsoleEncoder
+	// writes to. The colors the terminal doesn't support are downgraded
+	// to the closest supported ones. Read more: CI_ConsoleEncoder.SetColors().
+	CI_ConsoleColors uint8
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// CICE_COLORS_X256 means xterm 256 colors are supported. It's the default:
+	// 24-bit colors ("#RRGGBB", "rgb(r,g,b)") are downgraded to X256 ones.
+	CICE_COLORS_X256 CI_ConsoleColors = iota
+
+	// CICE_COLORS_ASCII means only 16 ASCII system colors are supported.
+	// Both of X256 and 24-bit colors are downgraded to them.
+	CICE_COLORS_ASCII
+
+	// CICE_COLORS_TRUECOLOR means 24-bit colors are supported, so they are
+	// written as is ("38;2;r;g;b" SGR sequence).
+	CICE_COLORS_TRUECOLOR
+
+	// CICE_COLORS_NONE means colors are not supported. All color verbs
+	// are ignored (the same as CICE_DropColors() but w/o runtime overhead).
+	CICE_COLORS_NONE
+)
+
+var (
+	// ceThemes is a registry of CI_ConsoleTheme s, their names to themes.
+	ceThemes = struct {
+		sync.RWMutex
+		m map[string]CI_ConsoleTheme
+	}{
+		m: map[string]CI_ConsoleTheme{
+			"default": {
+				Levels: map[Level]string{
+					LEVEL_DEBUG:     _CICE_SC_DEBUG,
+					LEVEL_INFO:      _CICE_SC_INFO,
+					LEVEL_NOTICE:    _CICE_SC_NOTICE,
+					LEVEL_WARNING:   _CICE_SC_WARNING,
+					LEVEL_ERROR:     _CICE_SC_ERROR,
+					LEVEL_CRITICAL:  _CICE_SC_CRITICAL,
+					LEVEL_ALERT:     _CICE_SC_ALERT,
+					LEVEL_EMERGENCY: _CICE_SC_EMERGENCY,
+				},
+				Colors: map[string]string{
+					"muted":     "#808080",
+					"accent":    "#87d7ff",
+					"highlight": "#ffffff",
+				},
+			},
+			"solarized-dark": {
+				Levels: map[Level]string{
+					LEVEL_DEBUG:     "c/fg:#586e75",
+					LEVEL_INFO:      "c/fg:#268bd2",
+					LEVEL_NOTICE:    "c/fg:#859900",
+					LEVEL_WARNING:   "c/fg:#b58900",
+					LEVEL_ERROR:     "c/fg:#dc322f",
+					LEVEL_CRITICAL:  "c/fg:#cb4b16/b",
+					LEVEL_ALERT:     "c/fg:#d33682/b/u",
+					LEVEL_EMERGENCY: "c/fg:#fdf6e3/bg:#dc322f/b/u",
+				},
+				Colors: map[string]string{
+					"muted":     "#586e75",
+					"accent":    "#2aa198",
+					"highlight": "#93a1a1",
+				},
+			},
+			"solarized-light": {
+				Levels: map[Level]string{
+					LEVEL_DEBUG:     "c/fg:#93a1a1",
+					LEVEL_INFO:      "c/fg:#268bd2",
+					LEVEL_NOTICE:    "c/fg:#859900",
+					LEVEL_WARNING:   "c/fg:#b58900",
+					LEVEL_ERROR:     "c/fg:#dc322f",
+					LEVEL_CRITICAL:  "c/fg:#cb4b16/b",
+					LEVEL_ALERT:     "c/fg:#d33682/b/u",
+					LEVEL_EMERGENCY: "c/fg:#fdf6e3/bg:#dc322f/b/u",
+				},
+				Colors: map[string]string{
+					"muted":     "#93a1a1",
+					"accent":    "#2aa198",
+					"highlight": "#586e75",
+				},
+			},
+			"gruvbox-dark": {
+				Levels: map[Level]string{
+					LEVEL_DEBUG:     "c/fg:#928374",
+					LEVEL_INFO:      "c/fg:#83a598",
+					LEVEL_NOTICE:    "c/fg:#b8bb26",
+					LEVEL_WARNING:   "c/fg:#fabd2f",
+					LEVEL_ERROR:     "c/fg:#fb4934",
+					LEVEL_CRITICAL:  "c/fg:#fe8019/b",
+					LEVEL_ALERT:     "c/fg:#d3869b/b/u",
+					LEVEL_EMERGENCY: "c/fg:#282828/bg:#fb4934/b/u",
+				},
+				Colors: map[string]string{
+					"muted":     "#928374",
+					"accent":    "#8ec07c",
+					"highlight": "#ebdbb2",
+				},
+			},
+		},
+	}
+)
+
+// CICE_RegisterTheme registers (or replaces) CI_ConsoleTheme with 'name',
+// so it may be applied by CI_ConsoleEncoder.SetTheme().
+// Built-in themes are "default", "solarized-dark", "solarized-light"
+// and "gruvbox-dark". Does nothing if 'name' is empty.
+//
+//goland:noinspection GoSnakeCaseUsage
+func CICE_RegisterTheme(name string, theme CI_ConsoleTheme) {
+
+	if name = strings.TrimSpace(name); name == "" {
+		return
+	}
+
+	themeCopy := CI_ConsoleTheme{
+		Levels: make(map[Level]string, len(theme.Levels)),
+		Colors: make(map[string]string, len(theme.Colors)),
+	}
+	for lvl, color := range theme.Levels {
+		themeCopy.Levels[lvl] = color
+	}
+	for colorName, color := range theme.Colors {
+		themeCopy.Colors[colorName] = color
+	}
+
+	ceThemes.Lock()
+	defer ceThemes.Unlock()
+
+	ceThemes.m[name] = themeCopy
+}
+
+// CICE_Themes returns the sorted names of all registered CI_ConsoleTheme s.
+//
+//goland:noinspection GoSnakeCaseUsage
+func CICE_Themes() []string {
+
+	ceThemes.RLock()
+	names := make([]string, 0, len(ceThemes.m))
+	for name := range ceThemes.m {
+		names = append(names, name)
+	}
+	ceThemes.RUnlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// CICE_DetectColors returns CI_ConsoleColors of the current terminal
+// using the environment variables:
+//   - "NO_COLOR" is set or "TERM" is "dumb": CICE_COLORS_NONE;
+//   - "COLORTERM" is "truecolor" or "24bit": CICE_COLORS_TRUECOLOR;
+//   - "TERM" contains "256color": CICE_COLORS_X256;
+//   - otherwise: CICE_COLORS_ASCII.
+//
+// It doesn't check whether the output is a terminal.
+//
+//goland:noinspection GoSnakeCaseUsage
+func CICE_DetectColors() CI_ConsoleColors {
+
+	term := os.Getenv("TERM")
+	if _, noColor := os.LookupEnv("NO_COLOR"); noColor || term == "dumb" {
+		return CICE_COLORS_NONE
+	}
+
+	switch strings.ToLower(os.Getenv("COLORTERM")) {
+	case "truecolor", "24bit":
+		return CICE_COLORS_TRUECOLOR
+	}
+
+	if strings.Contains(term, "256color") {
+		return CICE_COLORS_X256
+	}
+
+	return CICE_COLORS_ASCII
+}
+
+// ceThemeGet returns the registered CI_ConsoleTheme with 'name'
+// and true, or false if there's no such theme.
+func ceThemeGet(name string) (CI_ConsoleTheme, bool) {
+	ceThemes.RLock()
+	defer ceThemes.RUnlock()
+	theme, ok := ceThemes.m[name]
+	return theme, ok
+}
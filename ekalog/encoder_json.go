@@ -8,6 +8,7 @@ package ekalog
 import (
 	"time"
 
+	"github.com/qioalice/ekago/v3/ekastr"
 	"github.com/qioalice/ekago/v3/internal/ekaletter"
 
 	"github.com/json-iterator/go"
@@ -51,6 +52,24 @@ type (
 
 		oneDepthLevel bool
 
+		// htmlSafe reports whether '<', '>', '&' chars must be escaped
+		// in JSON strings. You may set this value using SetHTMLSafe() method.
+		htmlSafe bool
+
+		// structuredStackTrace reports whether each stack frame must be encoded
+		// as an object with separated function, file, line and package.
+		// You may set this value using SetStructuredStackTrace() method.
+		structuredStackTrace bool
+
+		// nestedKeys reports whether fields' keys containing dots must be
+		// encoded as nested JSON objects. You may set this value using
+		// SetNestedKeys() method.
+		nestedKeys bool
+
+		// keyCase is a conversion function of fields' keys (with cached results).
+		// You may set this value using SetKeyCase() method.
+		keyCase func(key string) string
+
 		// api is jsoniter's API object.
 		// Created at the first doBuild() call for object.
 		api jsoniter.API
@@ -61,6 +80,19 @@ type (
 		fieldNames map[CI_JSONEncoder_Field]string
 
 		timeFormatter func(t time.Time) string
+
+		// schema is an expected kinds of fields' values by their keys,
+		// schemaVersion is its version and schemaMode is how mismatches are handled.
+		// You may set these values using SetSchema() method.
+		schema        map[string]CI_JSONEncoder_SchemaKind
+		schemaVersion string
+		schemaMode    CI_JSONEncoder_SchemaMode
+
+		// preset is a cloud logging service the output is adapted to
+		// and presetParam is its parameter.
+		// You may set these values using SetPreset() method.
+		preset      CI_JSONEncoder_Preset
+		presetParam string
 	}
 
 	// CI_JSONEncoder_Field is a special type that represents a type of CI_JSONEncoder
@@ -68,6 +100,14 @@ type (
 	// This type exist to declare corresponding constants and be able to change
 	// default field's names to their user-defined alternatives.
 	CI_JSONEncoder_Field uint8
+
+	// CI_JSONEncoder_SchemaKind is an expected kind of JSON value of the field.
+	// Read more: CI_JSONEncoder.SetSchema().
+	CI_JSONEncoder_SchemaKind uint8
+
+	// CI_JSONEncoder_SchemaMode is the way the fields, which values
+	// do not match the schema, are handled. Read more: CI_JSONEncoder.SetSchema().
+	CI_JSONEncoder_SchemaMode uint8
 )
 
 //noinspection GoSnakeCaseUsage
@@ -84,6 +124,17 @@ const (
 	CI_JSON_ENCODER_FIELD_FIELDS
 	CI_JSON_ENCODER_FIELD_1DL_LOG_FIELDS_PREFIX
 	CI_JSON_ENCODER_FIELD_1DL_STACKTRACE_FIELDS_PREFIX
+	CI_JSON_ENCODER_FIELD_ERRORS
+	CI_JSON_ENCODER_FIELD_TRACE_ID
+	CI_JSON_ENCODER_FIELD_SPAN_ID
+	CI_JSON_ENCODER_FIELD_TRACE_FLAGS
+	CI_JSON_ENCODER_FIELD_TRACE_STATE
+	CI_JSON_ENCODER_FIELD_ERROR_FINGERPRINT
+	CI_JSON_ENCODER_FIELD_CALLER
+	CI_JSON_ENCODER_FIELD_SCHEMA_VERSION
+	CI_JSON_ENCODER_FIELD_SCHEMA_ERRORS
+	CI_JSON_ENCODER_FIELD_1DL_STACKTRACE_SECTIONS
+	CI_JSON_ENCODER_FIELD_ERROR_CORRELATION_ID
 )
 
 //noinspection GoSnakeCaseUsage
@@ -100,8 +151,51 @@ const (
 	CI_JSON_ENCODER_FIELD_DEFAULT_FIELDS                       = "fields"
 	CI_JSON_ENCODER_FIELD_DEFAULT_1DL_LOG_FIELDS_PREFIX        = "field_"
 	CI_JSON_ENCODER_FIELD_DEFAULT_1DL_STACKTRACE_FIELDS_PREFIX = "field_stacktrace_{{num}}_"
+	CI_JSON_ENCODER_FIELD_DEFAULT_ERRORS                       = "errors"
+	CI_JSON_ENCODER_FIELD_DEFAULT_TRACE_ID                     = TRACE_FIELD_KEY_TRACE_ID
+	CI_JSON_ENCODER_FIELD_DEFAULT_SPAN_ID                      = TRACE_FIELD_KEY_SPAN_ID
+	CI_JSON_ENCODER_FIELD_DEFAULT_TRACE_FLAGS                  = TRACE_FIELD_KEY_TRACE_FLAGS
+	CI_JSON_ENCODER_FIELD_DEFAULT_TRACE_STATE                  = TRACE_FIELD_KEY_TRACE_STATE
+	CI_JSON_ENCODER_FIELD_DEFAULT_ERROR_FINGERPRINT            = "error_fingerprint"
+	CI_JSON_ENCODER_FIELD_DEFAULT_CALLER                       = "caller"
+	CI_JSON_ENCODER_FIELD_DEFAULT_SCHEMA_VERSION               = "schema_version"
+	CI_JSON_ENCODER_FIELD_DEFAULT_SCHEMA_ERRORS                = "schema_errors"
+	CI_JSON_ENCODER_FIELD_DEFAULT_1DL_STACKTRACE_SECTIONS      = "stacktrace_sections"
+	CI_JSON_ENCODER_FIELD_DEFAULT_ERROR_CORRELATION_ID         = "correlation_id"
+)
+
+//noinspection GoSnakeCaseUsage
+const (
+	CI_JSON_ENCODER_SCHEMA_KIND_STRING CI_JSONEncoder_SchemaKind = 1 + iota
+	CI_JSON_ENCODER_SCHEMA_KIND_INT
+	CI_JSON_ENCODER_SCHEMA_KIND_UINT
+	CI_JSON_ENCODER_SCHEMA_KIND_FLOAT
+	CI_JSON_ENCODER_SCHEMA_KIND_BOOL
+	CI_JSON_ENCODER_SCHEMA_KIND_OBJECT
+	CI_JSON_ENCODER_SCHEMA_KIND_ARRAY
+)
+
+//noinspection GoSnakeCaseUsage
+const (
+	// CI_JSON_ENCODER_SCHEMA_MODE_DROP means the mismatched field is dropped.
+	CI_JSON_ENCODER_SCHEMA_MODE_DROP CI_JSONEncoder_SchemaMode = iota
+
+	// CI_JSON_ENCODER_SCHEMA_MODE_COERCE means the mismatched field's value
+	// is converted to the expected kind (e.g. "42" -> 42, 42 -> "42"),
+	// or it's dropped if it's impossible.
+	CI_JSON_ENCODER_SCHEMA_MODE_COERCE
+
+	// CI_JSON_ENCODER_SCHEMA_MODE_ERROR means the mismatched field is dropped
+	// and the description of mismatch is added to the "schema_errors" array.
+	CI_JSON_ENCODER_SCHEMA_MODE_ERROR
 )
 
+// CI_JSON_ENCODER_KEY_CASE_CACHE_CAPACITY is a number of fields' keys
+// the results of conversion of are cached. Read more: CI_JSONEncoder.SetKeyCase().
+//
+//goland:noinspection GoSnakeCaseUsage
+const CI_JSON_ENCODER_KEY_CASE_CACHE_CAPACITY = 1024
+
 var (
 	// Make sure we won't break API.
 	_ CI_Encoder = (*CI_JSONEncoder)(nil)
@@ -163,6 +257,111 @@ func (je *CI_JSONEncoder) SetIndent(num int) *CI_JSONEncoder {
 	return je
 }
 
+// SetHTMLSafe enables or disables escaping of '<', '>', '&' chars
+// in all JSON strings (messages, field values, etc). Disabled by default.
+//
+// Calling this method many times will overwrite previous value.
+//
+// This method MUST NOT be called after CI_JSONEncoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (je *CI_JSONEncoder) SetHTMLSafe(enable bool) *CI_JSONEncoder {
+
+	je.htmlSafe = enable
+	return je
+}
+
+// SetStructuredStackTrace enables or disables encoding of each stack frame
+// as an object with the separated function's full name, file's full path,
+// line number and package's full path, so the log backends may index them:
+//
+// 		"stacktrace": [{
+// 		    "func": "github.com/qioalice/ekago/v3/ekalog_test.foo",
+// 		    "file": "/home/user/ekago/ekalog/logger_test.go",
+// 		    "line": 22,
+// 		    "pkg": "github.com/qioalice/ekago/v3",
+// 		    "message": "Error message",
+// 		    "fields": {
+// 		        "test": 42
+// 		    }
+// 		}]
+//
+// It's applied to the stacktrace of aggregated errors too.
+// It overrides the stacktrace's encoding of one depth level mode
+// (see SetOneDepthLevel()): the stacktrace is still an array of objects
+// and its messages and fields are encoded inside the frames.
+// Disabled by default.
+//
+// Calling this method many times will overwrite previous value.
+//
+// This method MUST NOT be called after CI_JSONEncoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (je *CI_JSONEncoder) SetStructuredStackTrace(enable bool) *CI_JSONEncoder {
+
+	je.structuredStackTrace = enable
+	return je
+}
+
+// SetNestedKeys enables or disables encoding of fields, which keys contain dots,
+// as nested JSON objects. Disabled by default (keys are written as is).
+//
+// So, with enabled nested keys, fields "http.request.method" and "http.status"
+// will be encoded as:
+//
+// 		"fields": {
+// 		    "http": {
+// 		        "request": {
+// 		            "method": "GET"
+// 		        },
+// 		        "status": 200
+// 		    }
+// 		}
+//
+// Conflicts are resolved by the rule "first wins": the field, that conflicts
+// with already encoded one (e.g. "http" after "http.status" or "http.status.code"
+// after "http.status"), is written flat with its full key
+// (at the same level where its first key's part would be).
+// The keys with empty parts (like "a..b", ".a", "a.") are always written flat.
+//
+// It has no effect for one depth level mode (see SetOneDepthLevel())
+// and for pre-encoded fields (see PreEncodeField()).
+//
+// Calling this method many times will overwrite previous value.
+//
+// This method MUST NOT be called after CI_JSONEncoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (je *CI_JSONEncoder) SetNestedKeys(enable bool) *CI_JSONEncoder {
+
+	je.nestedKeys = enable
+	return je
+}
+
+// SetKeyCase sets a conversion function of fields' keys, e.g. ekastr.ToSnakeCase,
+// so fields' keys have the same casing regardless of how they're named in code:
+//
+// 		new(CI_JSONEncoder).SetKeyCase(ekastr.ToSnakeCase)
+//
+// Fields "userId", "UserID" and "user-id" are written as "user_id" then.
+// The results of conversion are cached (see ekastr.CaseCache),
+// so the conversion of each key is done at most once in the most cases.
+//
+// It's applied to the keys of all user's fields (log's, error's, pre-encoded ones),
+// but not to the prefixes of one depth level mode (see SetOneDepthLevel())
+// and not to the names of system fields (see SetNameForField()).
+// Pass nil to disable conversion (it's disabled by default).
+//
+// Calling this method many times will overwrite previous value.
+//
+// This method MUST NOT be called after CI_JSONEncoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (je *CI_JSONEncoder) SetKeyCase(conv func(key string) string) *CI_JSONEncoder {
+
+	je.keyCase = nil
+	if conv != nil {
+		je.keyCase = ekastr.NewCaseCache(CI_JSON_ENCODER_KEY_CASE_CACHE_CAPACITY, conv).Convert
+	}
+	return je
+}
+
 // SetNameForField allows you to rename default name for some fields.
 //
 // Keep in mind, using this method you can overwrite SYSTEM field's names
@@ -198,6 +397,64 @@ func (je *CI_JSONEncoder) SetTimeFormatter(formatter func(t time.Time) string) *
 	return je
 }
 
+// SetSchema enables strict mode, in which the values of fields with the keys
+// presented in 'schema' are validated against the expected kinds,
+// so the type of field can't be changed silently, breaking the downstream
+// ingestion (e.g. ClickHouse's columns):
+//
+// 		new(CI_JSONEncoder).SetSchema("2", map[string]CI_JSONEncoder_SchemaKind{
+// 		    "user_id": CI_JSON_ENCODER_SCHEMA_KIND_INT,
+// 		    "email":   CI_JSON_ENCODER_SCHEMA_KIND_STRING,
+// 		}, CI_JSON_ENCODER_SCHEMA_MODE_COERCE)
+//
+// The mismatched fields are dropped, coerced or reported depending on 'mode'
+// (read more: CI_JSON_ENCODER_SCHEMA_MODE_<...> constants).
+// The fields with keys that are not in 'schema' and null values are always allowed.
+// The keys are matched after the conversion (see SetKeyCase()),
+// w/o prefixes of one depth level mode (see SetOneDepthLevel()).
+//
+// Non-empty 'version' is written as "schema_version" field of each Entry.
+// Pass nil 'schema' to disable strict mode (it's disabled by default).
+//
+// Calling this method many times will overwrite previous values.
+//
+// This method MUST NOT be called after CI_JSONEncoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (je *CI_JSONEncoder) SetSchema(
+	version string, schema map[string]CI_JSONEncoder_SchemaKind, mode CI_JSONEncoder_SchemaMode) *CI_JSONEncoder {
+
+	je.schemaVersion, je.schemaMode, je.schema = version, mode, nil
+	if len(schema) > 0 {
+		je.schema = make(map[string]CI_JSONEncoder_SchemaKind, len(schema))
+		for key, kind := range schema {
+			je.schema[key] = kind
+		}
+	}
+	return je
+}
+
+// String returns the name of CI_JSONEncoder_SchemaKind, like "int".
+func (k CI_JSONEncoder_SchemaKind) String() string {
+	switch k {
+	case CI_JSON_ENCODER_SCHEMA_KIND_STRING:
+		return "string"
+	case CI_JSON_ENCODER_SCHEMA_KIND_INT:
+		return "int"
+	case CI_JSON_ENCODER_SCHEMA_KIND_UINT:
+		return "uint"
+	case CI_JSON_ENCODER_SCHEMA_KIND_FLOAT:
+		return "float"
+	case CI_JSON_ENCODER_SCHEMA_KIND_BOOL:
+		return "bool"
+	case CI_JSON_ENCODER_SCHEMA_KIND_OBJECT:
+		return "object"
+	case CI_JSON_ENCODER_SCHEMA_KIND_ARRAY:
+		return "array"
+	default:
+		return "unknown"
+	}
+}
+
 // PreEncodeField allows you to pre-encode some ekaletter.LetterField,
 // that is must be used with EACH Entry that will be encoded using this CI_JSONEncoder.
 //
@@ -227,11 +484,46 @@ func (je *CI_JSONEncoder) PreEncodeField(f ekaletter.LetterField) {
 		stream = je.preEncodedFieldsStreamIndentX1
 	}
 
+	f.Key = je.fieldKey(f.Key)
+
+	var ok bool
+	if f, ok = je.schemaCheck(nil, f); !ok {
+		return
+	}
+
 	if wasAdded := je.encodeField(stream, f); wasAdded {
 		stream.WriteMore()
 	}
 }
 
+// PreEncodeRawJSON is the same as PreEncodeField() but for the field with 'key',
+// which value is already encoded JSON 'raw' (an object, an array, etc).
+// It's useful for static blobs like k8s pod metadata.
+//
+// 'raw' is validated and compacted once and then it's injected
+// to the "fields" section of each Entry as is. The key is converted
+// the same way as keys of other fields (see SetKeyCase()).
+// Returns an error if 'key' is empty or 'raw' is not a valid JSON.
+//
+// WARNING!
+// PreEncodeRawJSON() MUST BE USED ONLY IF CI_JSONEncoder HAS BEEN REGISTERED
+// WITH SOME CommonIntegrator ALREADY. UB OTHERWISE, MAY PANIC!
+func (je *CI_JSONEncoder) PreEncodeRawJSON(key string, raw []byte) error {
+
+	compacted, err := compactRawJSON(key, raw)
+	if err != nil {
+		return err
+	}
+
+	je.PreEncodeField(ekaletter.LetterField{
+		Key:    key,
+		SValue: compacted,
+		Kind:   ekaletter.KIND_FLAG_SYSTEM | ekaletter.KIND_SYS_TYPE_RAW_JSON,
+	})
+
+	return nil
+}
+
 // EncodeEntry encodes passed Entry in JSON format using provided indentation.
 //
 // EncodeEntry is for internal purposes only and MUST NOT be called directly.
@@ -267,7 +559,11 @@ func (je *CI_JSONEncoder) EncodeEntry(e *Entry) []byte {
 		lightweightErrorFields = e.ErrLetter.Fields
 	}
 
-	if wasAdded := je.encodeFields(s, e.LogLetter.Fields, lightweightErrorFields, true); wasAdded {
+	if wasAdded := je.encodeFields(s, e.LogLetter.Fields, lightweightErrorFields, true, je.oneDepthLevel); wasAdded {
+		s.WriteMore()
+	}
+
+	if wasAdded := je.encodeAWSEmbeddedMetrics(s, e); wasAdded {
 		s.WriteMore()
 	}
 
@@ -275,6 +571,16 @@ func (je *CI_JSONEncoder) EncodeEntry(e *Entry) []byte {
 		s.WriteMore()
 	}
 
+	if e.ErrLetter != nil {
+		if wasAdded := je.encodeErrorChildren(s, e.ErrLetter.Children); wasAdded {
+			s.WriteMore()
+		}
+	}
+
+	if wasAdded := je.encodeSchemaErrors(s); wasAdded {
+		s.WriteMore()
+	}
+
 	// ------------ Add new sections here ------------ //
 
 	// We writing the JSON's comma at the each section, expecting that the next
@@ -0,0 +1,262 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekasys"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+
+	"github.com/json-iterator/go"
+)
+
+type (
+	// CI_JSONEncoder_Preset is a cloud logging service, the output of CI_JSONEncoder
+	// may be adapted to. Read more: CI_JSONEncoder.SetPreset().
+	CI_JSONEncoder_Preset uint8
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// CI_JSON_ENCODER_PRESET_DEFAULT means the output is not adapted
+	// to any cloud logging service.
+	CI_JSON_ENCODER_PRESET_DEFAULT CI_JSONEncoder_Preset = iota
+
+	// CI_JSON_ENCODER_PRESET_GOOGLE_CLOUD adapts the output to Google Cloud Logging
+	// structured logs:
+	//
+	//  - Level is written as "severity" with Cloud Logging's names
+	//    ("DEBUG", "INFO", "NOTICE", "WARNING", "ERROR", "CRITICAL", "ALERT", "EMERGENCY"),
+	//  - Time is written as "timestamp" protobuf Timestamp object
+	//    ({"seconds": <unix>, "nanos": <nanos>}),
+	//  - Trace ID is written as "logging.googleapis.com/trace"
+	//    ("projects/<param>/traces/<trace_id>" if the project ID 'param' is set),
+	//  - Span ID is written as "logging.googleapis.com/spanId",
+	//  - Trace flags are written as "logging.googleapis.com/trace_sampled" bool,
+	//  - Caller is written as "logging.googleapis.com/sourceLocation" object
+	//    ({"file": <file>, "line": "<line>", "function": <func>}).
+	CI_JSON_ENCODER_PRESET_GOOGLE_CLOUD
+
+	// CI_JSON_ENCODER_PRESET_AWS_CLOUDWATCH_EMF adapts the output
+	// to AWS CloudWatch Embedded Metric Format:
+	// the fields are written at the top level (one depth level w/o prefix),
+	// and each Entry, that has numeric (int, uint, float) fields,
+	// gets "_aws" metadata object, that declares them as metrics
+	// of the CloudWatch namespace 'param' (w/o dimensions), so CloudWatch
+	// extracts the metrics from the logs:
+	//
+	// 		{"level": "Info", ..., "latency_ms": 42, "_aws": {
+	// 		    "Timestamp": 1646370367000,
+	// 		    "CloudWatchMetrics": [{
+	// 		        "Namespace": "<param>",
+	// 		        "Dimensions": [[]],
+	// 		        "Metrics": [{"Name": "latency_ms"}]
+	// 		    }]
+	// 		}}
+	CI_JSON_ENCODER_PRESET_AWS_CLOUDWATCH_EMF
+)
+
+// SetPreset adapts the output of CI_JSONEncoder to the picky requirements
+// of cloud logging service at once, renaming the fields and changing
+// the format of their values. 'param' depends on 'preset'
+// (read more: CI_JSON_ENCODER_PRESET_<...> constants).
+//
+// The names of fields, set by this method, may be overwritten
+// by SetNameForField() after it.
+// Calling this method many times will overwrite previous values,
+// but the names of fields and one depth level mode are not restored.
+//
+// This method MUST NOT be called after CI_JSONEncoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (je *CI_JSONEncoder) SetPreset(preset CI_JSONEncoder_Preset, param string) *CI_JSONEncoder {
+
+	je.preset, je.presetParam = preset, param
+
+	switch preset {
+
+	case CI_JSON_ENCODER_PRESET_GOOGLE_CLOUD:
+		je.SetNameForField(CI_JSON_ENCODER_FIELD_LEVEL, "severity")
+		je.SetNameForField(CI_JSON_ENCODER_FIELD_TIME, "timestamp")
+		je.SetNameForField(CI_JSON_ENCODER_FIELD_TRACE_ID, "logging.googleapis.com/trace")
+		je.SetNameForField(CI_JSON_ENCODER_FIELD_SPAN_ID, "logging.googleapis.com/spanId")
+		je.SetNameForField(CI_JSON_ENCODER_FIELD_TRACE_FLAGS, "logging.googleapis.com/trace_sampled")
+		je.SetNameForField(CI_JSON_ENCODER_FIELD_CALLER, "logging.googleapis.com/sourceLocation")
+
+	case CI_JSON_ENCODER_PRESET_AWS_CLOUDWATCH_EMF:
+		je.SetOneDepthLevel(true)
+		je.SetNameForField(CI_JSON_ENCODER_FIELD_1DL_LOG_FIELDS_PREFIX, "")
+	}
+
+	return je
+}
+
+// ---------------------------------------------------------------------------- //
+
+// googleSeverity returns Cloud Logging's LogSeverity name of 'level'.
+// Custom levels are mapped to their base ones (read more: Level.Base()).
+func googleSeverity(level Level) string {
+	switch level.Base() {
+	case LEVEL_EMERGENCY:
+		return "EMERGENCY"
+	case LEVEL_ALERT:
+		return "ALERT"
+	case LEVEL_CRITICAL:
+		return "CRITICAL"
+	case LEVEL_ERROR:
+		return "ERROR"
+	case LEVEL_WARNING:
+		return "WARNING"
+	case LEVEL_NOTICE:
+		return "NOTICE"
+	case LEVEL_INFO:
+		return "INFO"
+	case LEVEL_DEBUG:
+		return "DEBUG"
+	default:
+		return "DEFAULT"
+	}
+}
+
+// encodeGoogleTimestamp writes 't' as protobuf Timestamp JSON object.
+func (je *CI_JSONEncoder) encodeGoogleTimestamp(s *jsoniter.Stream, t time.Time) {
+
+	s.WriteObjectStart()
+
+	s.WriteObjectField("seconds")
+	s.WriteInt64(t.Unix())
+	s.WriteMore()
+
+	s.WriteObjectField("nanos")
+	s.WriteInt(t.Nanosecond())
+
+	s.WriteObjectEnd()
+}
+
+// encodeGoogleSourceLocation writes 'frame' as Cloud Logging's
+// LogEntrySourceLocation JSON object.
+func (je *CI_JSONEncoder) encodeGoogleSourceLocation(s *jsoniter.Stream, frame *ekasys.StackFrame) {
+
+	s.WriteObjectStart()
+
+	s.WriteObjectField("file")
+	je.writeString(s, frame.File)
+	s.WriteMore()
+
+	// It's int64 in protobuf, that is a string in JSON.
+	s.WriteObjectField("line")
+	je.writeString(s, strconv.Itoa(frame.Line))
+	s.WriteMore()
+
+	s.WriteObjectField("function")
+	je.writeString(s, frame.Function)
+
+	s.WriteObjectEnd()
+}
+
+// encodeGoogleTracePart writes the value 'v' of the part 'field' of TraceContext
+// in Cloud Logging's format.
+func (je *CI_JSONEncoder) encodeGoogleTracePart(s *jsoniter.Stream, field CI_JSONEncoder_Field, v string) {
+
+	switch {
+	case field == CI_JSON_ENCODER_FIELD_TRACE_ID && je.presetParam != "":
+		je.writeString(s, "projects/"+je.presetParam+"/traces/"+v)
+
+	case field == CI_JSON_ENCODER_FIELD_TRACE_FLAGS:
+		flags, _ := strconv.ParseUint(v, 16, 8)
+		s.WriteBool(flags&1 != 0)
+
+	default:
+		je.writeString(s, v)
+	}
+}
+
+// encodeAWSEmbeddedMetrics writes "_aws" metadata object of AWS CloudWatch
+// Embedded Metric Format, that declares the numeric log fields of 'e' as metrics.
+// Does nothing if the current preset is not CI_JSON_ENCODER_PRESET_AWS_CLOUDWATCH_EMF
+// or there are no numeric log fields.
+func (je *CI_JSONEncoder) encodeAWSEmbeddedMetrics(s *jsoniter.Stream, e *Entry) (wasAdded bool) {
+
+	if je.preset != CI_JSON_ENCODER_PRESET_AWS_CLOUDWATCH_EMF {
+		return false
+	}
+
+	prefix := je.fieldNames[CI_JSON_ENCODER_FIELD_1DL_LOG_FIELDS_PREFIX]
+	fields := e.LogLetter.Fields
+
+	for i, n := 0, len(fields); i < n; i++ {
+		f := &fields[i]
+
+		if f.Key == "" || strings.HasPrefix(f.Key, "sys.") || !isNumericField(f) {
+			continue
+		}
+
+		if !wasAdded {
+			s.WriteObjectField("_aws")
+			s.WriteObjectStart()
+
+			s.WriteObjectField("Timestamp")
+			s.WriteInt64(e.Time.UnixNano() / int64(time.Millisecond))
+			s.WriteMore()
+
+			s.WriteObjectField("CloudWatchMetrics")
+			s.WriteArrayStart()
+			s.WriteObjectStart()
+
+			s.WriteObjectField("Namespace")
+			je.writeString(s, je.presetParam)
+			s.WriteMore()
+
+			s.WriteObjectField("Dimensions")
+			s.SetBuffer(bufw(s.Buffer(), "[[]]"))
+			s.WriteMore()
+
+			s.WriteObjectField("Metrics")
+			s.WriteArrayStart()
+
+			wasAdded = true
+		} else {
+			s.WriteMore()
+		}
+
+		s.WriteObjectStart()
+		s.WriteObjectField("Name")
+		je.writeString(s, prefix+je.fieldKey(f.Key))
+		s.WriteObjectEnd()
+	}
+
+	if wasAdded {
+		s.WriteArrayEnd()
+		s.WriteObjectEnd()
+		s.WriteArrayEnd()
+		s.WriteObjectEnd()
+	}
+
+	return wasAdded
+}
+
+// isNumericField reports whether 'f' is encoded as JSON number.
+func isNumericField(f *ekaletter.LetterField) bool {
+
+	if f.Kind.IsSystem() || f.Kind.IsNil() || f.Kind.IsInvalid() {
+		return false
+	}
+
+	switch f.Kind.BaseType() {
+	case ekaletter.KIND_TYPE_INT,
+		ekaletter.KIND_TYPE_INT_8, ekaletter.KIND_TYPE_INT_16,
+		ekaletter.KIND_TYPE_INT_32, ekaletter.KIND_TYPE_INT_64,
+		ekaletter.KIND_TYPE_UINT,
+		ekaletter.KIND_TYPE_UINT_8, ekaletter.KIND_TYPE_UINT_16,
+		ekaletter.KIND_TYPE_UINT_32, ekaletter.KIND_TYPE_UINT_64,
+		ekaletter.KIND_TYPE_FLOAT_32, ekaletter.KIND_TYPE_FLOAT_64:
+		return true
+	default:
+		return false
+	}
+}
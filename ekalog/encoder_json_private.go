@@ -6,17 +6,35 @@
 package ekalog
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"math"
 	"strconv"
 	"strings"
 	"time"
 
+	"github.com/qioalice/ekago/v3/ekaenc"
+	"github.com/qioalice/ekago/v3/ekastr"
 	"github.com/qioalice/ekago/v3/ekasys"
 	"github.com/qioalice/ekago/v3/internal/ekaletter"
 
 	"github.com/json-iterator/go"
 )
 
+//noinspection GoSnakeCaseUsage
+type (
+	// _CIJE_FieldNode is a node of fields' keys tree, that is used to encode
+	// fields with dot separated keys as nested JSON objects.
+	// It's either a field (a leaf) or a JSON object (has children).
+	_CIJE_FieldNode struct {
+		key      string
+		f        *ekaletter.LetterField // nil for JSON objects
+		children []*_CIJE_FieldNode
+	}
+)
+
 var (
 	// Make sure we won't break API by declaring package's console encoder
 	defaultJSONEncoder CI_Encoder
@@ -96,6 +114,39 @@ func (je *CI_JSONEncoder) doBuild() *CI_JSONEncoder {
 	dvn(je, CI_JSON_ENCODER_FIELD_1DL_STACKTRACE_FIELDS_PREFIX,
 		CI_JSON_ENCODER_FIELD_DEFAULT_1DL_STACKTRACE_FIELDS_PREFIX)
 
+	dvn(je, CI_JSON_ENCODER_FIELD_ERRORS,
+		CI_JSON_ENCODER_FIELD_DEFAULT_ERRORS)
+
+	dvn(je, CI_JSON_ENCODER_FIELD_TRACE_ID,
+		CI_JSON_ENCODER_FIELD_DEFAULT_TRACE_ID)
+
+	dvn(je, CI_JSON_ENCODER_FIELD_SPAN_ID,
+		CI_JSON_ENCODER_FIELD_DEFAULT_SPAN_ID)
+
+	dvn(je, CI_JSON_ENCODER_FIELD_TRACE_FLAGS,
+		CI_JSON_ENCODER_FIELD_DEFAULT_TRACE_FLAGS)
+
+	dvn(je, CI_JSON_ENCODER_FIELD_TRACE_STATE,
+		CI_JSON_ENCODER_FIELD_DEFAULT_TRACE_STATE)
+
+	dvn(je, CI_JSON_ENCODER_FIELD_ERROR_FINGERPRINT,
+		CI_JSON_ENCODER_FIELD_DEFAULT_ERROR_FINGERPRINT)
+
+	dvn(je, CI_JSON_ENCODER_FIELD_CALLER,
+		CI_JSON_ENCODER_FIELD_DEFAULT_CALLER)
+
+	dvn(je, CI_JSON_ENCODER_FIELD_SCHEMA_VERSION,
+		CI_JSON_ENCODER_FIELD_DEFAULT_SCHEMA_VERSION)
+
+	dvn(je, CI_JSON_ENCODER_FIELD_SCHEMA_ERRORS,
+		CI_JSON_ENCODER_FIELD_DEFAULT_SCHEMA_ERRORS)
+
+	dvn(je, CI_JSON_ENCODER_FIELD_1DL_STACKTRACE_SECTIONS,
+		CI_JSON_ENCODER_FIELD_DEFAULT_1DL_STACKTRACE_SECTIONS)
+
+	dvn(je, CI_JSON_ENCODER_FIELD_ERROR_CORRELATION_ID,
+		CI_JSON_ENCODER_FIELD_DEFAULT_ERROR_CORRELATION_ID)
+
 	if je.timeFormatter == nil {
 		je.timeFormatter = je.timeFormatterDefault
 	}
@@ -107,11 +158,30 @@ func (_ *CI_JSONEncoder) timeFormatterDefault(t time.Time) string {
 	return t.Format(time.RFC3339)
 }
 
+// writeString writes 'str' to 's' as JSON string using ekaenc's escaper,
+// respecting HTML-safe mode of the current CI_JSONEncoder.
+func (je *CI_JSONEncoder) writeString(s *jsoniter.Stream, str string) {
+
+	b := append(s.Buffer(), '"')
+	if je.htmlSafe {
+		b = ekaenc.AppendJSONEscapedStringHTMLSafe(b, ekastr.S2B(str))
+	} else {
+		b = ekaenc.AppendJSONEscapedString(b, ekastr.S2B(str))
+	}
+	s.SetBuffer(append(b, '"'))
+}
+
 // encodeBase encodes Entry's level, timestamp, message to s.
 func (je *CI_JSONEncoder) encodeBase(s *jsoniter.Stream, e *Entry) {
 
+	isGoogleCloud := je.preset == CI_JSON_ENCODER_PRESET_GOOGLE_CLOUD
+
 	s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_LEVEL])
-	s.WriteString(e.Level.String())
+	if isGoogleCloud {
+		je.writeString(s, googleSeverity(e.Level))
+	} else {
+		je.writeString(s, e.Level.String())
+	}
 	s.WriteMore()
 
 	s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_LEVEL_VALUE])
@@ -119,15 +189,72 @@ func (je *CI_JSONEncoder) encodeBase(s *jsoniter.Stream, e *Entry) {
 	s.WriteMore()
 
 	s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_TIME])
-	s.WriteString(je.timeFormatter(e.Time))
+	if isGoogleCloud {
+		je.encodeGoogleTimestamp(s, e.Time)
+	} else {
+		je.writeString(s, je.timeFormatter(e.Time))
+	}
 
 	s.WriteMore()
 	s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_MESSAGE])
-	s.WriteString(e.LogLetter.Messages[0].Body)
+	je.writeString(s, e.LogLetter.Messages[0].Body)
+
+	je.encodeTrace(s, e.LogLetter)
+
+	// Caller is written only if there's no stacktrace it might be taken from.
+	if e.callerPCs[0] != 0 && len(e.LogLetter.StackTrace) == 0 {
+		if frame := e.Caller(); frame != nil {
+			s.WriteMore()
+			s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_CALLER])
+			if isGoogleCloud {
+				je.encodeGoogleSourceLocation(s, frame)
+			} else {
+				je.writeString(s, frame.DoFormat())
+			}
+		}
+	}
+
+	if je.schemaVersion != "" {
+		s.WriteMore()
+		s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_SCHEMA_VERSION])
+		je.writeString(s, je.schemaVersion)
+	}
 
 	if e.ErrLetter != nil {
+		correlationID, _ := e.correlationIDField()
+		s.WriteMore()
+		je.encodeErrorHeader(s, e.ErrLetter, correlationID)
+	}
+}
+
+// encodeTrace writes TraceContext's parts (if any) that are stored as system fields
+// of the provided Entry's ekaletter.Letter.
+func (je *CI_JSONEncoder) encodeTrace(s *jsoniter.Stream, logLetter *ekaletter.Letter) {
+
+	for i, n := 0, len(logLetter.SystemFields); i < n; i++ {
+		var field CI_JSONEncoder_Field
+
+		switch logLetter.SystemFields[i].BaseType() {
+		case ekaletter.KIND_SYS_TYPE_TRACE_ID:
+			field = CI_JSON_ENCODER_FIELD_TRACE_ID
+		case ekaletter.KIND_SYS_TYPE_SPAN_ID:
+			field = CI_JSON_ENCODER_FIELD_SPAN_ID
+		case ekaletter.KIND_SYS_TYPE_TRACE_FLAGS:
+			field = CI_JSON_ENCODER_FIELD_TRACE_FLAGS
+		case ekaletter.KIND_SYS_TYPE_TRACE_STATE:
+			field = CI_JSON_ENCODER_FIELD_TRACE_STATE
+		default:
+			continue
+		}
+
 		s.WriteMore()
-		je.encodeErrorHeader(s, e.ErrLetter)
+		s.WriteObjectField(je.fieldNames[field])
+
+		if je.preset == CI_JSON_ENCODER_PRESET_GOOGLE_CLOUD {
+			je.encodeGoogleTracePart(s, field, logLetter.SystemFields[i].SValue)
+		} else {
+			je.writeString(s, logLetter.SystemFields[i].SValue)
+		}
 	}
 }
 
@@ -136,14 +263,17 @@ func (je *CI_JSONEncoder) encodeBase(s *jsoniter.Stream, e *Entry) {
 //
 // It won't encode stacktrace, neither its messages nor fields.
 // encodeStackFrame() does that.
-func (je *CI_JSONEncoder) encodeErrorHeader(s *jsoniter.Stream, errLetter *ekaletter.Letter) {
+//
+// The correlation ID is skipped if it's the same as 'entryCorrelationID',
+// that is already written as Entry's field.
+func (je *CI_JSONEncoder) encodeErrorHeader(s *jsoniter.Stream, errLetter *ekaletter.Letter, entryCorrelationID string) {
 
 	for i, n := 0, len(errLetter.SystemFields); i < n; i++ {
 		switch errLetter.SystemFields[i].BaseType() {
 
 		case ekaletter.KIND_SYS_TYPE_EKAERR_UUID:
 			s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_ERROR_ID])
-			s.WriteString(errLetter.SystemFields[i].SValue)
+			je.writeString(s, errLetter.SystemFields[i].SValue)
 
 		case ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_ID:
 			s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_ERROR_CLASS_ID])
@@ -151,7 +281,21 @@ func (je *CI_JSONEncoder) encodeErrorHeader(s *jsoniter.Stream, errLetter *ekale
 
 		case ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME:
 			s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_ERROR_CLASS_NAME])
-			s.WriteString(errLetter.SystemFields[i].SValue)
+			je.writeString(s, errLetter.SystemFields[i].SValue)
+
+		case ekaletter.KIND_SYS_TYPE_EKAERR_FINGERPRINT:
+			if ekaletter.LFingerprint(errLetter) == "" {
+				continue
+			}
+			s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_ERROR_FINGERPRINT])
+			je.writeString(s, errLetter.SystemFields[i].SValue)
+
+		case ekaletter.KIND_SYS_TYPE_CORRELATION_ID:
+			if v := errLetter.SystemFields[i].SValue; v == "" || v == entryCorrelationID {
+				continue
+			}
+			s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_ERROR_CORRELATION_ID])
+			je.writeString(s, errLetter.SystemFields[i].SValue)
 
 		default:
 			continue
@@ -181,16 +325,18 @@ func (je *CI_JSONEncoder) encodeStacktrace(s *jsoniter.Stream, e *Entry) (wasAdd
 	}
 
 	var (
-		fields   []ekaletter.LetterField
-		messages []ekaletter.LetterMessage
+		fields     []ekaletter.LetterField
+		messages   []ekaletter.LetterMessage
+		boundaries []ekaletter.LetterBoundary
 	)
 
 	if e.ErrLetter != nil {
 		fields = e.ErrLetter.Fields
 		messages = e.ErrLetter.Messages
+		boundaries = e.ErrLetter.Boundaries
 	}
 
-	if je.oneDepthLevel {
+	if je.oneDepthLevel && !je.structuredStackTrace {
 		var sb strings.Builder
 
 		s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_STACKTRACE])
@@ -213,7 +359,7 @@ func (je *CI_JSONEncoder) encodeStacktrace(s *jsoniter.Stream, e *Entry) (wasAdd
 			sb.WriteByte(' ')
 			sb.WriteString(frame.Format[frame.FormatFileOffset : frame.FormatFullPathOffset-1])
 
-			s.WriteString(sb.String())
+			je.writeString(s, sb.String())
 
 			if i < n-1 {
 				s.WriteMore()
@@ -239,7 +385,7 @@ func (je *CI_JSONEncoder) encodeStacktrace(s *jsoniter.Stream, e *Entry) (wasAdd
 					sb.WriteString("]: ")
 					sb.WriteString(messages[mi].Body)
 
-					s.WriteString(sb.String())
+					je.writeString(s, sb.String())
 					mi++
 
 					s.WriteMore()
@@ -253,23 +399,49 @@ func (je *CI_JSONEncoder) encodeStacktrace(s *jsoniter.Stream, e *Entry) (wasAdd
 			s.WriteArrayEnd()
 		}
 
+		if len(boundaries) > 0 {
+
+			s.WriteMore()
+			s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_1DL_STACKTRACE_SECTIONS])
+			s.WriteArrayStart()
+
+			for i, n := 0, len(boundaries); i < n; i++ {
+				sb.Reset()
+				sb.Grow(len(boundaries[i].Name) + 10)
+
+				sb.WriteByte('[')
+				sb.WriteString(strconv.Itoa(int(boundaries[i].StackFrameIdx)))
+				sb.WriteString("]: ")
+				sb.WriteString(boundaries[i].Name)
+
+				je.writeString(s, sb.String())
+
+				if i < n-1 {
+					s.WriteMore()
+				}
+			}
+
+			s.WriteArrayEnd()
+		}
+
 		if len(fields) > 0 {
 			s.WriteMore()
 
 			for i, n := 0, len(fields); i < n; i++ {
-				keyBak := fields[i].Key
+				f, ok := fields[i], true
+				f.Key = je.fieldKey(f.Key)
 
-				key := je.fieldNames[CI_JSON_ENCODER_FIELD_1DL_STACKTRACE_FIELDS_PREFIX]
-				key = strings.Replace(key, "{{num}}", strconv.Itoa(int(fields[i].StackFrameIdx)), 1)
-				key += fields[i].Key
+				if f, ok = je.schemaCheck(s, f); !ok {
+					continue
+				}
 
-				fields[i].Key = key
+				key := je.fieldNames[CI_JSON_ENCODER_FIELD_1DL_STACKTRACE_FIELDS_PREFIX]
+				key = strings.Replace(key, "{{num}}", strconv.Itoa(int(f.StackFrameIdx)), 1)
+				f.Key = key + f.Key
 
-				if wasAdded := je.encodeField(s, fields[i]); wasAdded {
+				if wasAdded := je.encodeField(s, f); wasAdded {
 					s.WriteMore()
 				}
-
-				fields[i].Key = keyBak
 			}
 
 			to := s.Buffer()
@@ -279,46 +451,125 @@ func (je *CI_JSONEncoder) encodeStacktrace(s *jsoniter.Stream, e *Entry) (wasAdd
 		}
 
 	} else {
-		fi := 0 // fi for fields' index
-		mi := 0 // mi for messages' index
-
 		s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_STACKTRACE])
-		s.WriteArrayStart()
+		je.encodeStackFrames(s, stacktrace, fields, messages, boundaries)
+	}
 
-		for i := int16(0); i < n; i++ {
-			frame := &stacktrace[i]
+	return true
+}
 
-			messageForStackFrame := ekaletter.LetterMessage{}
-			fieldsForStackFrame := []ekaletter.LetterField(nil)
-			fiEnd := 0
+// encodeStackFrames writes an array of stack frames of 'stacktrace'
+// along with their messages, fields and the names of logical layers
+// they belong to according to 'boundaries'.
+func (je *CI_JSONEncoder) encodeStackFrames(
 
-			//goland:noinspection GoNilness
-			if mi < len(messages) && messages[mi].StackFrameIdx == i {
-				messageForStackFrame = messages[mi]
-				mi++
-			}
+	s *jsoniter.Stream,
+	stacktrace ekasys.StackTrace,
+	fields []ekaletter.LetterField,
+	messages []ekaletter.LetterMessage,
+	boundaries []ekaletter.LetterBoundary,
 
-			if fi < len(fields) && fields[fi].StackFrameIdx == i {
-				fiEnd = fi + 1
-				for fiEnd < len(fields) && fields[fiEnd].StackFrameIdx == i {
-					fiEnd++
-				}
-			}
+) {
+	fi := 0 // fi for fields' index
+	mi := 0 // mi for messages' index
+
+	s.WriteArrayStart()
+
+	for i, n := int16(0), int16(len(stacktrace)); i < n; i++ {
+		frame := &stacktrace[i]
 
-			if fiEnd != 0 {
-				fieldsForStackFrame = fields[fi:fiEnd]
+		messageForStackFrame := ekaletter.LetterMessage{}
+		fieldsForStackFrame := []ekaletter.LetterField(nil)
+		fiEnd := 0
+
+		//goland:noinspection GoNilness
+		if mi < len(messages) && messages[mi].StackFrameIdx == i {
+			messageForStackFrame = messages[mi]
+			mi++
+		}
+
+		if fi < len(fields) && fields[fi].StackFrameIdx == i {
+			fiEnd = fi + 1
+			for fiEnd < len(fields) && fields[fiEnd].StackFrameIdx == i {
+				fiEnd++
 			}
+		}
 
-			je.encodeStackFrame(s, frame, fieldsForStackFrame, messageForStackFrame)
+		if fiEnd != 0 {
+			fieldsForStackFrame = fields[fi:fiEnd]
+			fi = fiEnd
+		}
 
-			if i < n-1 {
+		section := ekaletter.LBoundaryOf(boundaries, i)
+		je.encodeStackFrame(s, frame, fieldsForStackFrame, messageForStackFrame, section)
+
+		if i < n-1 {
+			s.WriteMore()
+		}
+	}
+
+	s.WriteArrayEnd()
+}
+
+// encodeErrorChildren writes an array of errors that are aggregated
+// by some ekaerr.Error. Each error is an object that contains its header
+// (ID, class ID, class name) and either its stacktrace (with messages and fields)
+// or its last message and fields if it's a lightweight error.
+// Nested aggregated errors are written the same way.
+//
+// The stacktrace of aggregated errors is always written as an array of objects
+// regardless of one depth level is enabled.
+func (je *CI_JSONEncoder) encodeErrorChildren(s *jsoniter.Stream, children []*ekaletter.Letter) (wasAdded bool) {
+
+	if len(children) == 0 {
+		return false
+	}
+
+	s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_ERRORS])
+	s.WriteArrayStart()
+
+	for i, n := 0, len(children); i < n; i++ {
+		child := children[i]
+
+		s.WriteObjectStart()
+		je.encodeErrorHeader(s, child, "")
+
+		if len(child.StackTrace) > 0 {
+			s.WriteMore()
+			s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_STACKTRACE])
+			je.encodeStackFrames(s, child.StackTrace, child.Fields, child.Messages, child.Boundaries)
+
+		} else {
+			for j := len(child.Messages) - 1; j >= 0; j-- {
+				if child.Messages[j].Body != "" {
+					s.WriteMore()
+					s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_MESSAGE])
+					je.writeString(s, child.Messages[j].Body)
+					break
+				}
+			}
+			if len(child.Fields) > 0 {
 				s.WriteMore()
+				if wasAdded := je.encodeFields(s, child.Fields, nil, false, je.oneDepthLevel); !wasAdded {
+					b := s.Buffer()
+					s.SetBuffer(b[:len(b)-1])
+				}
 			}
 		}
 
-		s.WriteArrayEnd()
+		if len(child.Children) > 0 {
+			s.WriteMore()
+			je.encodeErrorChildren(s, child.Children)
+		}
+
+		s.WriteObjectEnd()
+
+		if i < n-1 {
+			s.WriteMore()
+		}
 	}
 
+	s.WriteArrayEnd()
 	return true
 }
 
@@ -328,32 +579,77 @@ func (je *CI_JSONEncoder) encodeStackFrame(
 	frame *ekasys.StackFrame,
 	fields []ekaletter.LetterField,
 	message ekaletter.LetterMessage,
+	section string,
 
 ) {
 	frame.DoFormat()
 
 	s.WriteObjectStart()
 
-	s.WriteObjectField("func")
-	s.WriteString(frame.Format[:frame.FormatFileOffset-1])
-	s.WriteMore()
+	if je.structuredStackTrace {
+		s.WriteObjectField("func")
+		je.writeString(s, frame.Function)
+		s.WriteMore()
 
-	s.WriteObjectField("file")
-	s.WriteString(frame.Format[frame.FormatFileOffset+1 : frame.FormatFullPathOffset-2])
-	s.WriteMore()
+		s.WriteObjectField("file")
+		je.writeString(s, frame.File)
+		s.WriteMore()
+
+		s.WriteObjectField("line")
+		s.WriteInt(frame.Line)
+		s.WriteMore()
+
+		s.WriteObjectField("pkg")
+		je.writeString(s, frame.Format[frame.FormatFullPathOffset:])
+
+	} else {
+		s.WriteObjectField("func")
+		je.writeString(s, frame.Format[:frame.FormatFileOffset-1])
+		s.WriteMore()
+
+		s.WriteObjectField("file")
+		je.writeString(s, frame.Format[frame.FormatFileOffset+1:frame.FormatFullPathOffset-2])
+		s.WriteMore()
+
+		s.WriteObjectField("package")
+		je.writeString(s, frame.Format[frame.FormatFullPathOffset:])
+	}
+
+	if section != "" {
+		s.WriteMore()
+		s.WriteObjectField("section")
+		je.writeString(s, section)
+	}
 
-	s.WriteObjectField("package")
-	s.WriteString(frame.Format[frame.FormatFullPathOffset:])
+	if snippet := frame.SourceSnippet(); len(snippet) > 0 {
+		s.WriteMore()
+		s.WriteObjectField("source")
+		s.WriteArrayStart()
+		for i := range snippet {
+			if i > 0 {
+				s.WriteMore()
+			}
+			s.WriteObjectStart()
+			s.WriteObjectField("line")
+			s.WriteInt(snippet[i].Line)
+			s.WriteMore()
+			s.WriteObjectField("text")
+			je.writeString(s, snippet[i].Text)
+			s.WriteObjectEnd()
+		}
+		s.WriteArrayEnd()
+	}
 
 	if message.Body != "" {
 		s.WriteMore()
 		s.WriteObjectField("message")
-		s.WriteString(message.Body)
+		je.writeString(s, message.Body)
 	}
 
 	if len(fields) > 0 {
 		s.WriteMore()
-		if wasAdded := je.encodeFields(s, fields, nil, false); !wasAdded {
+		flat := je.oneDepthLevel && !je.structuredStackTrace
+		if wasAdded := je.encodeFields(s, fields, nil, false, flat); !wasAdded {
 			b := s.Buffer()
 			s.SetBuffer(b[:len(b)-1])
 		}
@@ -362,9 +658,13 @@ func (je *CI_JSONEncoder) encodeStackFrame(
 	s.WriteObjectEnd()
 }
 
-func (je *CI_JSONEncoder) encodeFields(s *jsoniter.Stream, fs, addFs []ekaletter.LetterField, addPreEncoded bool) (wasAdded bool) {
+// encodeFields encodes 'fs', 'addFs' (and pre-encoded fields if 'addPreEncoded')
+// either as "fields" JSON object or (if 'flat') as prefixed top-level fields.
+func (je *CI_JSONEncoder) encodeFields(s *jsoniter.Stream, fs, addFs []ekaletter.LetterField, addPreEncoded, flat bool) (wasAdded bool) {
+
+	addPreEncoded = addPreEncoded && len(je.preEncodedFieldsStreamIndentX2.Buffer()) > 0
 
-	if len(fs) == 0 && len(addFs) == 0 {
+	if len(fs) == 0 && len(addFs) == 0 && !addPreEncoded {
 		return false
 	}
 
@@ -373,7 +673,7 @@ func (je *CI_JSONEncoder) encodeFields(s *jsoniter.Stream, fs, addFs []ekaletter
 		prefix                         string
 	)
 
-	if je.oneDepthLevel {
+	if flat {
 		prefix = je.fieldNames[CI_JSON_ENCODER_FIELD_1DL_LOG_FIELDS_PREFIX]
 	} else {
 		s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_FIELDS])
@@ -385,32 +685,36 @@ func (je *CI_JSONEncoder) encodeFields(s *jsoniter.Stream, fs, addFs []ekaletter
 			return
 		}
 
-		keyBak := f.Key
+		fc, ok := *f, true
+		if fc.Key == "" && !fc.IsSystem() {
+			fc.Key = fc.KeyOrUnnamed(unnamedFieldIdx)
+		} else {
+			fc.Key = je.fieldKey(fc.Key)
+		}
 
-		var sb strings.Builder
-		sb.Grow(len(prefix) + len(f.Key) + 10)
-		sb.WriteString(prefix)
+		if fc, ok = je.schemaCheck(s, fc); !ok {
+			return
+		}
 
-		if f.Key == "" && !f.IsSystem() {
-			sb.WriteString(f.KeyOrUnnamed(unnamedFieldIdx))
-		} else {
-			sb.WriteString(f.Key)
+		if prefix != "" {
+			fc.Key = prefix + fc.Key
 		}
-		f.Key = sb.String()
 
-		if wasAdded = je.encodeField(s, *f); wasAdded {
+		if wasAdded = je.encodeField(s, fc); wasAdded {
 			s.WriteMore()
 			*writtenFields++
 		}
-
-		f.Key = keyBak
 	}
 
-	for i, n := int16(0), int16(len(fs)); i < n; i++ {
-		addField(s, &fs[i], prefix, &unnamedFieldIdx, &writtenFields)
-	}
-	for i, n := int16(0), int16(len(addFs)); i < n; i++ {
-		addField(s, &addFs[i], prefix, &unnamedFieldIdx, &writtenFields)
+	if je.nestedKeys && !flat {
+		writtenFields = je.encodeNestedFields(s, fs, addFs, &unnamedFieldIdx)
+	} else {
+		for i, n := int16(0), int16(len(fs)); i < n; i++ {
+			addField(s, &fs[i], prefix, &unnamedFieldIdx, &writtenFields)
+		}
+		for i, n := int16(0), int16(len(addFs)); i < n; i++ {
+			addField(s, &addFs[i], prefix, &unnamedFieldIdx, &writtenFields)
+		}
 	}
 
 	to := s.Buffer()
@@ -418,6 +722,7 @@ func (je *CI_JSONEncoder) encodeFields(s *jsoniter.Stream, fs, addFs []ekaletter
 	// Write pre-encoded fields in "fields" section
 	if addPreEncoded {
 		to = bufw2(to, je.preEncodedFieldsStreamIndentX2.Buffer())
+		writtenFields++
 	}
 
 	i := len(to) - 1
@@ -427,7 +732,7 @@ func (je *CI_JSONEncoder) encodeFields(s *jsoniter.Stream, fs, addFs []ekaletter
 		i--
 	}
 
-	if !je.oneDepthLevel && writtenFields == 0 {
+	if !flat && writtenFields == 0 {
 		// Maybe no fields were added?
 		for i >= 0 && to[i] != 'f' { // start of "fields"
 			i--
@@ -437,13 +742,139 @@ func (je *CI_JSONEncoder) encodeFields(s *jsoniter.Stream, fs, addFs []ekaletter
 
 	s.SetBuffer(to[:i+1])
 
-	if !je.oneDepthLevel && writtenFields > 0 {
+	if !flat && writtenFields > 0 {
 		s.WriteObjectEnd()
 	}
 
 	return writtenFields > 0
 }
 
+// encodeNestedFields encodes 'fs', 'addFs' as nested JSON objects
+// splitting their keys by dots. Each encoded field is followed by a comma.
+// Returns a number of written fields.
+// Read more: SetNestedKeys().
+func (je *CI_JSONEncoder) encodeNestedFields(
+	s *jsoniter.Stream, fs, addFs []ekaletter.LetterField, unnamedFieldIdx *int16) (writtenFields int16) {
+
+	root := new(_CIJE_FieldNode)
+
+	add := func(f *ekaletter.LetterField) {
+		if strings.HasPrefix(f.Key, "sys.") {
+			return
+		}
+		key := je.fieldKey(f.Key)
+		if key == "" && !f.IsSystem() {
+			key = f.KeyOrUnnamed(unnamedFieldIdx)
+		}
+		if je.schema != nil {
+			fc := *f
+			fc.Key = key
+			var ok bool
+			if fc, ok = je.schemaCheck(s, fc); !ok {
+				return
+			}
+			f = &fc
+		}
+		root.add(key, f)
+	}
+
+	for i, n := 0, len(fs); i < n; i++ {
+		add(&fs[i])
+	}
+	for i, n := 0, len(addFs); i < n; i++ {
+		add(&addFs[i])
+	}
+
+	return je.encodeFieldNodes(s, root.children)
+}
+
+// encodeFieldNodes encodes 'nodes' of fields' keys tree.
+// Each encoded node is followed by a comma. Returns a number of written fields.
+func (je *CI_JSONEncoder) encodeFieldNodes(s *jsoniter.Stream, nodes []*_CIJE_FieldNode) (writtenFields int16) {
+
+	for _, node := range nodes {
+		if node.f != nil {
+			f := *node.f
+			f.Key = node.key
+			if wasAdded := je.encodeField(s, f); wasAdded {
+				s.WriteMore()
+				writtenFields++
+			}
+			continue
+		}
+
+		s.WriteObjectField(node.key)
+		s.WriteObjectStart()
+
+		if n := je.encodeFieldNodes(s, node.children); n > 0 {
+			b := s.Buffer()
+			s.SetBuffer(b[:len(b)-1]) // remove last comma
+			writtenFields += n
+		}
+
+		s.WriteObjectEnd()
+		s.WriteMore()
+	}
+
+	return writtenFields
+}
+
+// add adds a field 'f' with 'key' to the current _CIJE_FieldNode,
+// splitting 'key' by dots and creating nested nodes if it's necessary.
+// Read more about conflicts resolving: CI_JSONEncoder.SetNestedKeys().
+func (n *_CIJE_FieldNode) add(key string, f *ekaletter.LetterField) {
+
+	isValidKey := !strings.HasPrefix(key, ".") &&
+		!strings.HasSuffix(key, ".") && !strings.Contains(key, "..")
+
+	node, path := n, key
+	for isValidKey {
+		head, tail, isNested := strings.Cut(path, ".")
+		child := node.child(head)
+
+		switch {
+		case !isNested && child == nil:
+			node.children = append(node.children, &_CIJE_FieldNode{key: head, f: f})
+			return
+
+		case !isNested, child != nil && child.f != nil:
+			// Conflict: either a field with the same key already exists,
+			// or the path goes through the field.
+			isValidKey = false
+
+		case child == nil:
+			child = &_CIJE_FieldNode{key: head}
+			node.children = append(node.children, child)
+			fallthrough
+
+		default:
+			node, path = child, tail
+		}
+	}
+
+	n.children = append(n.children, &_CIJE_FieldNode{key: key, f: f})
+}
+
+// child returns a direct child of the current _CIJE_FieldNode with 'key'
+// or nil if there is no such child.
+func (n *_CIJE_FieldNode) child(key string) *_CIJE_FieldNode {
+	for _, child := range n.children {
+		if child.key == key {
+			return child
+		}
+	}
+	return nil
+}
+
+// fieldKey returns user's field's 'key' converted by the function
+// set by SetKeyCase() (if any).
+func (je *CI_JSONEncoder) fieldKey(key string) string {
+	if je.keyCase == nil || key == "" {
+		return key
+	}
+	return je.keyCase(key)
+}
+
 func (je *CI_JSONEncoder) encodeField(s *jsoniter.Stream, f ekaletter.LetterField) (wasAdded bool) {
 	s.WriteObjectField(f.Key)
 	je.encodeFieldValue(s, f)
@@ -455,8 +886,14 @@ func (je *CI_JSONEncoder) encodeFieldValue(s *jsoniter.Stream, f ekaletter.Lette
 	if f.Kind.IsSystem() {
 		switch f.Kind.BaseType() {
 
-		case ekaletter.KIND_SYS_TYPE_EKAERR_UUID, ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME:
-			s.WriteString(f.SValue)
+		case ekaletter.KIND_SYS_TYPE_EKAERR_UUID, ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME,
+			ekaletter.KIND_SYS_TYPE_TRACE_ID, ekaletter.KIND_SYS_TYPE_SPAN_ID,
+			ekaletter.KIND_SYS_TYPE_TRACE_FLAGS, ekaletter.KIND_SYS_TYPE_TRACE_STATE,
+			ekaletter.KIND_SYS_TYPE_EKAERR_FINGERPRINT, ekaletter.KIND_SYS_TYPE_CORRELATION_ID:
+			je.writeString(s, f.SValue)
+
+		case ekaletter.KIND_SYS_TYPE_RAW_JSON:
+			s.SetBuffer(bufw(s.Buffer(), f.SValue))
 
 		case ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_ID:
 			b := s.Buffer()
@@ -464,14 +901,14 @@ func (je *CI_JSONEncoder) encodeFieldValue(s *jsoniter.Stream, f ekaletter.Lette
 			s.SetBuffer(b)
 
 		default:
-			s.WriteString("<unsupported system field>")
+			je.writeString(s, "<unsupported system field>")
 		}
 
 	} else if f.Kind.IsNil() {
 		s.WriteNil()
 
 	} else if f.Kind.IsInvalid() {
-		s.WriteString("<invalid_field>")
+		je.writeString(s, "<invalid_field>")
 
 	} else {
 		switch f.Kind.BaseType() {
@@ -514,7 +951,7 @@ func (je *CI_JSONEncoder) encodeFieldValue(s *jsoniter.Stream, f ekaletter.Lette
 			s.SetBuffer(b)
 
 		case ekaletter.KIND_TYPE_STRING:
-			s.WriteString(f.SValue)
+			je.writeString(s, f.SValue)
 
 		case ekaletter.KIND_TYPE_COMPLEX_64:
 			b := s.Buffer()
@@ -533,13 +970,13 @@ func (je *CI_JSONEncoder) encodeFieldValue(s *jsoniter.Stream, f ekaletter.Lette
 			s.SetBuffer(b)
 
 		case ekaletter.KIND_TYPE_UNIX:
-			s.WriteString(time.Unix(f.IValue, 0).Format("Jan 2 15:04:05"))
+			je.writeString(s, time.Unix(f.IValue, 0).Format("Jan 2 15:04:05"))
 
 		case ekaletter.KIND_TYPE_UNIX_NANO:
-			s.WriteString(time.Unix(0, f.IValue).Format("Jan 2 15:04:05.000000000"))
+			je.writeString(s, time.Unix(0, f.IValue).Format("Jan 2 15:04:05.000000000"))
 
 		case ekaletter.KIND_TYPE_DURATION:
-			s.WriteString(time.Duration(f.IValue).String())
+			je.writeString(s, time.Duration(f.IValue).String())
 
 		case ekaletter.KIND_TYPE_MAP, ekaletter.KIND_TYPE_EXTMAP,
 			ekaletter.KIND_TYPE_STRUCT, ekaletter.KIND_TYPE_ARRAY:
@@ -547,7 +984,253 @@ func (je *CI_JSONEncoder) encodeFieldValue(s *jsoniter.Stream, f ekaletter.Lette
 			s.WriteVal(f.Value)
 
 		default:
-			s.WriteString("<unsupported_field>")
+			je.writeString(s, "<unsupported_field>")
 		}
 	}
 }
+
+// schemaCheck checks the value of 'f' (which key is converted already)
+// against the schema set by SetSchema(). Returns 'f' (or its coerced copy)
+// and true if it may be encoded, or false if it must be dropped.
+// The mismatch is recorded to 's' (if it's not nil) in the error mode.
+func (je *CI_JSONEncoder) schemaCheck(s *jsoniter.Stream, f ekaletter.LetterField) (ekaletter.LetterField, bool) {
+
+	if je.schema == nil || f.IsSystem() || f.Kind.IsNil() {
+		return f, true
+	}
+
+	expected, ok := je.schema[f.Key]
+	if !ok {
+		return f, true
+	}
+
+	actual := schemaKindOf(f)
+	if actual == expected {
+		return f, true
+	}
+
+	switch je.schemaMode {
+	case CI_JSON_ENCODER_SCHEMA_MODE_COERCE:
+		return je.schemaCoerce(f, actual, expected)
+
+	case CI_JSON_ENCODER_SCHEMA_MODE_ERROR:
+		if s != nil {
+			errs, _ := s.Attachment.([]string)
+			s.Attachment = append(errs, fmt.Sprintf("%s: expected %s, got %s", f.Key, expected, actual))
+		}
+	}
+
+	return f, false
+}
+
+// schemaCoerce converts the value of 'f' of 'actual' schema kind
+// to the 'expected' one. Returns false if it's impossible.
+func (je *CI_JSONEncoder) schemaCoerce(
+	f ekaletter.LetterField, actual, expected CI_JSONEncoder_SchemaKind) (ekaletter.LetterField, bool) {
+
+	var (
+		coerced ekaletter.LetterField
+		err     error
+	)
+
+	switch expected {
+	case CI_JSON_ENCODER_SCHEMA_KIND_STRING:
+		var str string
+		if str, err = je.schemaStringOf(f, actual); err == nil {
+			coerced = ekaletter.FString(f.Key, str)
+		}
+
+	case CI_JSON_ENCODER_SCHEMA_KIND_INT:
+		var i int64
+		switch actual {
+		case CI_JSON_ENCODER_SCHEMA_KIND_UINT:
+			if i = f.IValue; i < 0 {
+				err = strconv.ErrRange
+			}
+		case CI_JSON_ENCODER_SCHEMA_KIND_FLOAT:
+			fv := schemaFloatOf(f)
+			if i = int64(fv); float64(i) != fv {
+				err = strconv.ErrRange
+			}
+		case CI_JSON_ENCODER_SCHEMA_KIND_BOOL:
+			i = f.IValue
+		case CI_JSON_ENCODER_SCHEMA_KIND_STRING:
+			i, err = strconv.ParseInt(f.SValue, 10, 64)
+		default:
+			err = strconv.ErrSyntax
+		}
+		if err == nil {
+			coerced = ekaletter.FInt64(f.Key, i)
+		}
+
+	case CI_JSON_ENCODER_SCHEMA_KIND_UINT:
+		var u uint64
+		switch actual {
+		case CI_JSON_ENCODER_SCHEMA_KIND_INT:
+			if u = uint64(f.IValue); f.IValue < 0 {
+				err = strconv.ErrRange
+			}
+		case CI_JSON_ENCODER_SCHEMA_KIND_FLOAT:
+			fv := schemaFloatOf(f)
+			if u = uint64(fv); fv < 0 || float64(u) != fv {
+				err = strconv.ErrRange
+			}
+		case CI_JSON_ENCODER_SCHEMA_KIND_BOOL:
+			u = uint64(f.IValue)
+		case CI_JSON_ENCODER_SCHEMA_KIND_STRING:
+			u, err = strconv.ParseUint(f.SValue, 10, 64)
+		default:
+			err = strconv.ErrSyntax
+		}
+		if err == nil {
+			coerced = ekaletter.FUint64(f.Key, u)
+		}
+
+	case CI_JSON_ENCODER_SCHEMA_KIND_FLOAT:
+		var fv float64
+		switch actual {
+		case CI_JSON_ENCODER_SCHEMA_KIND_INT:
+			fv = float64(f.IValue)
+		case CI_JSON_ENCODER_SCHEMA_KIND_UINT:
+			fv = float64(uint64(f.IValue))
+		case CI_JSON_ENCODER_SCHEMA_KIND_STRING:
+			fv, err = strconv.ParseFloat(f.SValue, 64)
+		default:
+			err = strconv.ErrSyntax
+		}
+		if err == nil {
+			coerced = ekaletter.FFloat64(f.Key, fv)
+		}
+
+	case CI_JSON_ENCODER_SCHEMA_KIND_BOOL:
+		var b bool
+		switch actual {
+		case CI_JSON_ENCODER_SCHEMA_KIND_INT, CI_JSON_ENCODER_SCHEMA_KIND_UINT:
+			if b = f.IValue == 1; f.IValue != 0 && f.IValue != 1 {
+				err = strconv.ErrRange
+			}
+		case CI_JSON_ENCODER_SCHEMA_KIND_STRING:
+			b, err = strconv.ParseBool(f.SValue)
+		default:
+			err = strconv.ErrSyntax
+		}
+		if err == nil {
+			coerced = ekaletter.FBool(f.Key, b)
+		}
+
+	default:
+		err = strconv.ErrSyntax
+	}
+
+	if err != nil {
+		return f, false
+	}
+
+	coerced.StackFrameIdx = f.StackFrameIdx
+	return coerced, true
+}
+
+// schemaStringOf returns the string representation of 'f' value
+// of 'actual' schema kind. Objects and arrays are encoded as JSON.
+func (je *CI_JSONEncoder) schemaStringOf(f ekaletter.LetterField, actual CI_JSONEncoder_SchemaKind) (string, error) {
+
+	switch actual {
+	case CI_JSON_ENCODER_SCHEMA_KIND_INT:
+		return strconv.FormatInt(f.IValue, 10), nil
+	case CI_JSON_ENCODER_SCHEMA_KIND_UINT:
+		return strconv.FormatUint(uint64(f.IValue), 10), nil
+	case CI_JSON_ENCODER_SCHEMA_KIND_FLOAT:
+		return strconv.FormatFloat(schemaFloatOf(f), 'g', -1, 64), nil
+	case CI_JSON_ENCODER_SCHEMA_KIND_BOOL:
+		return strconv.FormatBool(f.IValue != 0), nil
+	case CI_JSON_ENCODER_SCHEMA_KIND_OBJECT, CI_JSON_ENCODER_SCHEMA_KIND_ARRAY:
+		return je.api.MarshalToString(f.Value)
+	default:
+		return "", strconv.ErrSyntax
+	}
+}
+
+// schemaKindOf returns the kind of JSON value 'f' is encoded as
+// or 0 if it has no corresponding CI_JSONEncoder_SchemaKind.
+func schemaKindOf(f ekaletter.LetterField) CI_JSONEncoder_SchemaKind {
+
+	switch f.Kind.BaseType() {
+
+	case ekaletter.KIND_TYPE_BOOL:
+		return CI_JSON_ENCODER_SCHEMA_KIND_BOOL
+
+	case ekaletter.KIND_TYPE_INT,
+		ekaletter.KIND_TYPE_INT_8, ekaletter.KIND_TYPE_INT_16,
+		ekaletter.KIND_TYPE_INT_32, ekaletter.KIND_TYPE_INT_64:
+		return CI_JSON_ENCODER_SCHEMA_KIND_INT
+
+	case ekaletter.KIND_TYPE_UINT,
+		ekaletter.KIND_TYPE_UINT_8, ekaletter.KIND_TYPE_UINT_16,
+		ekaletter.KIND_TYPE_UINT_32, ekaletter.KIND_TYPE_UINT_64:
+		return CI_JSON_ENCODER_SCHEMA_KIND_UINT
+
+	case ekaletter.KIND_TYPE_FLOAT_32, ekaletter.KIND_TYPE_FLOAT_64:
+		return CI_JSON_ENCODER_SCHEMA_KIND_FLOAT
+
+	case ekaletter.KIND_TYPE_STRING, ekaletter.KIND_TYPE_UNIX,
+		ekaletter.KIND_TYPE_UNIX_NANO, ekaletter.KIND_TYPE_DURATION:
+		return CI_JSON_ENCODER_SCHEMA_KIND_STRING
+
+	case ekaletter.KIND_TYPE_MAP, ekaletter.KIND_TYPE_EXTMAP, ekaletter.KIND_TYPE_STRUCT:
+		return CI_JSON_ENCODER_SCHEMA_KIND_OBJECT
+
+	case ekaletter.KIND_TYPE_ARRAY:
+		return CI_JSON_ENCODER_SCHEMA_KIND_ARRAY
+
+	default:
+		return 0
+	}
+}
+
+// schemaFloatOf returns the value of 'f' of KIND_TYPE_FLOAT_32
+// or KIND_TYPE_FLOAT_64 as float64.
+func schemaFloatOf(f ekaletter.LetterField) float64 {
+	if f.Kind.BaseType() == ekaletter.KIND_TYPE_FLOAT_32 {
+		return float64(math.Float32frombits(uint32(f.IValue)))
+	}
+	return math.Float64frombits(uint64(f.IValue))
+}
+
+// encodeSchemaErrors writes the mismatches of fields with the schema,
+// recorded by schemaCheck() in the error mode (if any).
+func (je *CI_JSONEncoder) encodeSchemaErrors(s *jsoniter.Stream) (wasAdded bool) {
+
+	errs, _ := s.Attachment.([]string)
+	if len(errs) == 0 {
+		return false
+	}
+
+	s.WriteObjectField(je.fieldNames[CI_JSON_ENCODER_FIELD_SCHEMA_ERRORS])
+	s.WriteArrayStart()
+
+	for i, err := range errs {
+		if i > 0 {
+			s.WriteMore()
+		}
+		je.writeString(s, err)
+	}
+
+	s.WriteArrayEnd()
+	return true
+}
+
+// compactRawJSON validates 'raw' JSON and returns it compacted
+// (w/o insignificant whitespaces). Read more: CI_JSONEncoder.PreEncodeRawJSON().
+func compactRawJSON(key string, raw []byte) (string, error) {
+
+	if key == "" {
+		return "", errors.New("ekalog: PreEncodeRawJSON: key is empty")
+	}
+
+	var b bytes.Buffer
+	if err := json.Compact(&b, raw); err != nil {
+		return "", fmt.Errorf("ekalog: PreEncodeRawJSON: invalid JSON of %q: %w", key, err)
+	}
+
+	return b.String(), nil
+}
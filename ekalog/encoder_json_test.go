@@ -0,0 +1,394 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekastr"
+	"github.com/qioalice/ekago/v3/ekasys"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testJSONEncoderOutput(je *ekalog.CI_JSONEncoder, cb func()) map[string]any {
+
+	b := bytes.NewBuffer(nil)
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(je).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(b)
+
+	ekalog.ReplaceIntegrator(ci)
+	cb()
+
+	var out map[string]any
+	_ = json.Unmarshal(b.Bytes(), &out)
+	return out
+}
+
+func TestCI_JSONEncoder_Trace(t *testing.T) {
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+	tc, ok := ekalog.ParseTraceparent(traceparent)
+	require.True(t, ok)
+	assert.Equal(t, traceparent, tc.Traceparent())
+
+	_, ok = ekalog.ParseTraceparent("00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+	assert.False(t, ok)
+
+	je := new(ekalog.CI_JSONEncoder).
+		SetNameForField(ekalog.CI_JSON_ENCODER_FIELD_TRACE_ID, "trace.id")
+
+	out := testJSONEncoderOutput(je, func() {
+		ctx := ekalog.ContextWithTrace(context.Background(), tc)
+		ekalog.Copy().WithTraceFromContext(ctx).Info("traced")
+	})
+
+	assert.Equal(t, "traced", out["message"])
+	assert.Equal(t, tc.TraceID, out["trace.id"])
+	assert.Equal(t, tc.SpanID, out["span_id"])
+	assert.Equal(t, "01", out["trace_flags"])
+	assert.NotContains(t, out, "tracestate")
+}
+
+func TestCI_JSONEncoder_NestedKeys(t *testing.T) {
+
+	je := new(ekalog.CI_JSONEncoder).SetNestedKeys(true)
+
+	out := testJSONEncoderOutput(je, func() {
+		ekalog.Info("nested",
+			"http.request.method", "GET",
+			"http.status", 200,
+			"http.status.code", 201, // conflicts with "http.status", written flat
+			"a..b", 1, // empty key's part, written flat
+			"plain", true,
+		)
+	})
+
+	require.Contains(t, out, "fields")
+	assert.Equal(t, map[string]any{
+		"http": map[string]any{
+			"request": map[string]any{
+				"method": "GET",
+			},
+			"status": float64(200),
+		},
+		"http.status.code": float64(201),
+		"a..b":             float64(1),
+		"plain":            true,
+	}, out["fields"])
+
+	out = testJSONEncoderOutput(new(ekalog.CI_JSONEncoder), func() {
+		ekalog.Info("flat", "http.status", 200)
+	})
+
+	assert.Equal(t, map[string]any{"http.status": float64(200)}, out["fields"])
+}
+
+func TestCI_JSONEncoder_KeyCase(t *testing.T) {
+
+	je := new(ekalog.CI_JSONEncoder).SetKeyCase(ekastr.ToSnakeCase)
+
+	out := testJSONEncoderOutput(je, func() {
+		ekalog.Info("keys", "userId", 1, "HTTPStatus", 200, "request-id", "x")
+	})
+
+	assert.Equal(t, map[string]any{
+		"user_id":     float64(1),
+		"http_status": float64(200),
+		"request_id":  "x",
+	}, out["fields"])
+
+	je = new(ekalog.CI_JSONEncoder).SetKeyCase(ekastr.ToCamelCase).SetNestedKeys(true)
+
+	out = testJSONEncoderOutput(je, func() {
+		ekalog.Info("keys", "http.status_code", 200)
+	})
+
+	assert.Equal(t, map[string]any{
+		"http": map[string]any{"statusCode": float64(200)},
+	}, out["fields"])
+}
+
+func TestCI_JSONEncoder_ErrorFingerprint(t *testing.T) {
+
+	err := ekaerr.IllegalArgument.New("user 42 not found")
+	fingerprint := err.Fingerprint()
+
+	out := testJSONEncoderOutput(new(ekalog.CI_JSONEncoder), func() {
+		ekalog.Errore("", err)
+	})
+
+	assert.Equal(t, fingerprint, out["error_fingerprint"])
+
+	// Fingerprint is computed only if it's written.
+	var normalized int
+	ekaerr.SetFingerprintNormalizer(func(message string) string {
+		normalized++
+		return message
+	})
+	defer ekaerr.SetFingerprintNormalizer(nil)
+
+	text := testConsoleEncoderOutput("{{m}}", func() {
+		ekalog.Errore("", ekaerr.IllegalArgument.New("user 42 not found"))
+	})
+	assert.Equal(t, "user 42 not found", text)
+	assert.Equal(t, 0, normalized)
+
+	out = testJSONEncoderOutput(new(ekalog.CI_JSONEncoder), func() {
+		ekalog.Errore("", ekaerr.IllegalArgument.New("user 42 not found"))
+	})
+	assert.NotEmpty(t, out["error_fingerprint"])
+	assert.Equal(t, 1, normalized)
+}
+
+func TestCI_JSONEncoder_ErrorCorrelationID(t *testing.T) {
+
+	je := new(ekalog.CI_JSONEncoder)
+
+	out := testJSONEncoderOutput(je, func() {
+		ekalog.Errore("", ekaerr.IllegalArgument.New("bad value"))
+	})
+	assert.NotContains(t, out, "correlation_id")
+	assert.NotContains(t, out, "fields")
+
+	out = testJSONEncoderOutput(je, func() {
+		ekalog.WithCorrelationID("corr-1").Errore("", ekaerr.IllegalArgument.New("bad value"))
+	})
+	assert.NotContains(t, out, "correlation_id")
+	assert.Equal(t, map[string]any{"correlation_id": "corr-1"}, out["fields"])
+
+	out = testJSONEncoderOutput(je, func() {
+		err := ekaerr.IllegalArgument.New("bad value").WithCorrelationID("corr-2")
+		ekalog.WithCorrelationID("corr-1").Errore("", err)
+	})
+	assert.Equal(t, "corr-2", out["correlation_id"])
+	assert.Equal(t, map[string]any{"correlation_id": "corr-1"}, out["fields"])
+}
+
+func TestCI_JSONEncoder_PreEncodeRawJSON(t *testing.T) {
+
+	var (
+		b  = bytes.NewBuffer(nil)
+		je = new(ekalog.CI_JSONEncoder)
+		ce = new(ekalog.CI_ConsoleEncoder).SetFormat("{{m}}{{f/?^ /v=}}|")
+	)
+
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(je).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(b).
+		WithEncoder(ce).
+		WriteTo(b)
+
+	ekalog.ReplaceIntegrator(ci)
+
+	const pod = `{ "name": "api-0",
+		"labels": ["a", "b"] }`
+
+	require.NoError(t, je.PreEncodeRawJSON("pod", []byte(pod)))
+	require.NoError(t, ce.PreEncodeRawJSON("pod", []byte(pod)))
+	assert.Error(t, je.PreEncodeRawJSON("bad", []byte(`{"a":`)))
+	assert.Error(t, ce.PreEncodeRawJSON("", []byte(`1`)))
+
+	ekalog.Info("msg", "k", 1)
+
+	jsonPart, consolePart, _ := bytes.Cut(b.Bytes(), []byte("\n"))
+
+	var out map[string]any
+	require.NoError(t, json.Unmarshal(jsonPart, &out))
+	assert.Equal(t, map[string]any{
+		"k":   float64(1),
+		"pod": map[string]any{"name": "api-0", "labels": []any{"a", "b"}},
+	}, out["fields"])
+
+	// Pre-encoded fields are written from the new line by CI_ConsoleEncoder.
+	assert.Equal(t, "msg k=1\n"+`pod={"name":"api-0","labels":["a","b"]}|`, string(consolePart))
+}
+
+func TestCI_JSONEncoder_Schema(t *testing.T) {
+
+	schema := map[string]ekalog.CI_JSONEncoder_SchemaKind{
+		"user_id": ekalog.CI_JSON_ENCODER_SCHEMA_KIND_INT,
+		"email":   ekalog.CI_JSON_ENCODER_SCHEMA_KIND_STRING,
+		"ok":      ekalog.CI_JSON_ENCODER_SCHEMA_KIND_BOOL,
+		"tags":    ekalog.CI_JSON_ENCODER_SCHEMA_KIND_ARRAY,
+	}
+
+	log := func() {
+		ekalog.Info("schema", "user_id", "42", "email", 7, "ok", "yes",
+			"tags", []string{"a"}, "other", 1.5)
+	}
+
+	je := new(ekalog.CI_JSONEncoder).
+		SetSchema("", schema, ekalog.CI_JSON_ENCODER_SCHEMA_MODE_DROP)
+
+	out := testJSONEncoderOutput(je, log)
+	assert.NotContains(t, out, "schema_version")
+	assert.Equal(t, map[string]any{
+		"tags":  []any{"a"},
+		"other": 1.5,
+	}, out["fields"])
+
+	je = new(ekalog.CI_JSONEncoder).
+		SetSchema("2", schema, ekalog.CI_JSON_ENCODER_SCHEMA_MODE_COERCE)
+
+	out = testJSONEncoderOutput(je, log)
+	assert.Equal(t, "2", out["schema_version"])
+	assert.Equal(t, map[string]any{
+		"user_id": float64(42),
+		"email":   "7",
+		"tags":    []any{"a"},
+		"other":   1.5,
+	}, out["fields"])
+
+	je = new(ekalog.CI_JSONEncoder).
+		SetSchema("2", schema, ekalog.CI_JSON_ENCODER_SCHEMA_MODE_ERROR)
+
+	out = testJSONEncoderOutput(je, log)
+	assert.Equal(t, []any{
+		"user_id: expected int, got string",
+		"email: expected string, got int",
+		"ok: expected bool, got string",
+	}, out["schema_errors"])
+	assert.Len(t, out["fields"], 2)
+}
+
+func TestCI_JSONEncoder_SourceSnippet(t *testing.T) {
+
+	ekasys.EnableSourceSnippets(1)
+	defer ekasys.EnableSourceSnippets(0)
+
+	out := testJSONEncoderOutput(new(ekalog.CI_JSONEncoder), func() {
+		ekalog.Errore("", ekaerr.IllegalArgument.New("snippet")) // snippet marker
+	})
+
+	stacktrace, _ := out["stacktrace"].([]any)
+	require.NotEmpty(t, stacktrace)
+
+	source := stacktrace[0].(map[string]any)["source"].([]any)
+	require.Len(t, source, 3)
+
+	line := source[1].(map[string]any)
+	assert.Contains(t, line["text"], "// snippet marker")
+	assert.Contains(t, stacktrace[0].(map[string]any)["file"], fmt.Sprintf(":%v", line["line"]))
+}
+
+func jsonStructuredStackFoo() *ekaerr.Error {
+	return ekaerr.IllegalState.New("inner").WithInt("test", 42)
+}
+
+func TestCI_JSONEncoder_StructuredStackTrace(t *testing.T) {
+
+	je := new(ekalog.CI_JSONEncoder).
+		SetOneDepthLevel(true).
+		SetStructuredStackTrace(true)
+
+	out := testJSONEncoderOutput(je, func() {
+		ekalog.Errore("", jsonStructuredStackFoo().Throw())
+	})
+
+	stacktrace, _ := out["stacktrace"].([]any)
+	require.NotEmpty(t, stacktrace)
+
+	frame := stacktrace[0].(map[string]any)
+	assert.True(t, strings.HasSuffix(frame["func"].(string), "ekalog_test.jsonStructuredStackFoo"))
+	assert.True(t, strings.HasSuffix(frame["file"].(string), "encoder_json_test.go"))
+	assert.Greater(t, frame["line"], float64(0))
+	assert.Contains(t, frame, "pkg")
+	assert.Equal(t, map[string]any{"test": float64(42)}, frame["fields"])
+
+	assert.NotContains(t, out, "stacktrace_messages")
+	assert.NotContains(t, out, "field_stacktrace_0_test")
+}
+
+func jsonSectionsRepo() *ekaerr.Error {
+	return ekaerr.IllegalState.New("no rows").Boundary("repo").Throw()
+}
+
+func jsonSectionsService() *ekaerr.Error {
+	return jsonSectionsRepo().Boundary("service").Throw()
+}
+
+func TestCI_JSONEncoder_StackSections(t *testing.T) {
+
+	out := testJSONEncoderOutput(new(ekalog.CI_JSONEncoder), func() {
+		ekalog.Errore("", jsonSectionsService())
+	})
+
+	stacktrace, _ := out["stacktrace"].([]any)
+	require.Greater(t, len(stacktrace), 2)
+
+	assert.Equal(t, "repo", stacktrace[0].(map[string]any)["section"])
+	assert.Equal(t, "service", stacktrace[1].(map[string]any)["section"])
+	assert.NotContains(t, stacktrace[2], "section")
+
+	out = testJSONEncoderOutput(new(ekalog.CI_JSONEncoder).SetOneDepthLevel(true), func() {
+		ekalog.Errore("", jsonSectionsService())
+	})
+
+	assert.Equal(t, []any{"[0]: repo", "[1]: service"}, out["stacktrace_sections"])
+}
+
+func TestCI_JSONEncoder_Preset(t *testing.T) {
+
+	tc, ok := ekalog.ParseTraceparent("00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	require.True(t, ok)
+
+	je := new(ekalog.CI_JSONEncoder).
+		SetPreset(ekalog.CI_JSON_ENCODER_PRESET_GOOGLE_CLOUD, "my-project")
+
+	out := testJSONEncoderOutput(je, func() {
+		ctx := ekalog.ContextWithTrace(context.Background(), tc)
+		ekalog.Copy().WithTraceFromContext(ctx).Warn("traced")
+	})
+
+	assert.Equal(t, "WARNING", out["severity"])
+	assert.Contains(t, out["timestamp"], "seconds")
+	assert.Contains(t, out["timestamp"], "nanos")
+	assert.Equal(t, "projects/my-project/traces/"+tc.TraceID, out["logging.googleapis.com/trace"])
+	assert.Equal(t, tc.SpanID, out["logging.googleapis.com/spanId"])
+	assert.Equal(t, true, out["logging.googleapis.com/trace_sampled"])
+	assert.NotContains(t, out, "level")
+
+	je = new(ekalog.CI_JSONEncoder).
+		SetPreset(ekalog.CI_JSON_ENCODER_PRESET_AWS_CLOUDWATCH_EMF, "MyService")
+
+	out = testJSONEncoderOutput(je, func() {
+		ekalog.Info("request", "latency_ms", 42, "path", "/api", "ratio", 0.5)
+	})
+
+	assert.Equal(t, float64(42), out["latency_ms"])
+	assert.Equal(t, "/api", out["path"])
+	require.Contains(t, out, "_aws")
+
+	aws := out["_aws"].(map[string]any)
+	assert.Greater(t, aws["Timestamp"], float64(0))
+	assert.Equal(t, []any{map[string]any{
+		"Namespace":  "MyService",
+		"Dimensions": []any{[]any{}},
+		"Metrics": []any{
+			map[string]any{"Name": "latency_ms"},
+			map[string]any{"Name": "ratio"},
+		},
+	}}, aws["CloudWatchMetrics"])
+
+	out = testJSONEncoderOutput(je, func() {
+		ekalog.Info("no metrics", "path", "/api")
+	})
+	assert.Equal(t, "no metrics", out["message"])
+	assert.NotContains(t, out, "_aws")
+}
@@ -8,6 +8,7 @@ package ekalog
 import (
 	"time"
 
+	"github.com/qioalice/ekago/v3/ekasys"
 	"github.com/qioalice/ekago/v3/internal/ekaletter"
 )
 
@@ -34,9 +35,73 @@ type (
 		Level Level
 
 		// Time contains the time when an event occurred this log entry represents.
-		// Generated automatically by time.Now() call in log finisher.
+		// Generated automatically by time.Now() call in log finisher
+		// (or by the time source, see Logger.WithClock(), CommonIntegrator.WithClock()).
 		Time time.Time
 
+		// group is a namespace, the keys of all subsequently added fields
+		// are prefixed by. Read more: Logger.WithGroup().
+		group string
+
+		// callerPCs contains the PC of the caller of Logger's finisher,
+		// if only it (not a whole stacktrace) has been captured.
+		// Read more: CommonIntegrator.WithMinLevelForCaller().
+		callerPCs [1]uintptr
+
+		// caller is a lazily resolved StackFrame of callerPCs. Read more: Caller().
+		caller ekasys.StackTrace
+
+		// flags is a bitmask of _ENTRY_FLAG_... options, the Entry is routed by.
+		// Read more: IsLocal().
+		flags uint8
+
 		needSetFinalizer bool
 	}
 )
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// _ENTRY_FLAG_LOCAL marks Entry that must not leave the host.
+	// Read more: Logger.Local().
+	_ENTRY_FLAG_LOCAL uint8 = 1 << iota
+)
+
+// IsLocal reports whether Entry must be written only to the local writers
+// (console, files, etc.) and must not leave the host.
+// Read more: Logger.Local().
+//
+// Custom Integrator must respect it, skipping the network destinations.
+func (e *Entry) IsLocal() bool {
+	return e.flags&_ENTRY_FLAG_LOCAL != 0
+}
+
+// Caller returns the stack frame of the function that called a log finisher.
+// It's the first frame of Entry's stacktrace (or attached ekaerr.Error's one),
+// or the frame of the caller's PC, that is resolved lazily at the first call,
+// if only the caller's PC has been captured (read more:
+// CommonIntegrator.WithMinLevelForCaller()), or the decoded one
+// (read more: CI_JSONDecoder).
+//
+// Returns nil if there's no info about caller.
+func (e *Entry) Caller() *ekasys.StackFrame {
+
+	switch {
+	case len(e.LogLetter.StackTrace) > 0:
+		return &e.LogLetter.StackTrace[0]
+
+	case e.ErrLetter != nil && len(e.ErrLetter.StackTrace) > 0:
+		return &e.ErrLetter.StackTrace[0]
+
+	case e.callerPCs[0] == 0 && e.caller == nil:
+		return nil
+	}
+
+	if e.caller == nil {
+		e.caller = ekasys.StackTraceFromFramePoints(e.callerPCs[:])
+	}
+	if len(e.caller) == 0 {
+		return nil
+	}
+
+	return &e.caller[0]
+}
@@ -34,8 +34,14 @@ func (e *Entry) cleanup() (this *Entry) {
 	e.l = nil
 	e.LogLetter.StackTrace = nil
 	e.ErrLetter = nil
+	e.group = ""
+	e.callerPCs[0] = 0
+	e.caller = nil
+	e.flags = 0
 
 	ekaletter.LReset(e.LogLetter)
+	e.LogLetter.SystemFields = e.LogLetter.SystemFields[:0]
+
 	return e
 }
 
@@ -60,17 +66,70 @@ func (e *Entry) clone() *Entry {
 		}
 	}
 
+	if lFrom := len(e.LogLetter.SystemFields); lFrom > 0 {
+		clonedEntry.LogLetter.SystemFields =
+			append(clonedEntry.LogLetter.SystemFields[:0], e.LogLetter.SystemFields...)
+	}
+
+	clonedEntry.group = e.group
+	clonedEntry.flags = e.flags
+	ekaletter.LSetFieldsOverwrite(clonedEntry.LogLetter,
+		ekaletter.LIsFieldsOverwrite(e.LogLetter))
+	ekaletter.LCopyUnnamedKeys(clonedEntry.LogLetter, e.LogLetter)
+
 	// There is no need to zero Time, Level, LetterMessage fields
 	// because they used only in one place and will be overwritten anyway.
 
 	return clonedEntry
 }
 
+// setSystemField adds a system ekaletter.LetterField with string value
+// of 'baseType' to the Entry's ekaletter.Letter, replacing the existed one
+// of the same 'baseType'. Removes the existed one if 'value' is empty.
+func (e *Entry) setSystemField(key, value string, baseType ekaletter.LetterFieldKind) {
+
+	fs := e.LogLetter.SystemFields
+	for i, n := 0, len(fs); i < n; i++ {
+		if fs[i].BaseType() == baseType {
+			if value == "" {
+				e.LogLetter.SystemFields = append(fs[:i], fs[i+1:]...)
+			} else {
+				fs[i].SValue = value
+			}
+			return
+		}
+	}
+
+	if value != "" {
+		e.LogLetter.SystemFields = append(fs, ekaletter.LetterField{
+			Key:    key,
+			SValue: value,
+			Kind:   ekaletter.KIND_FLAG_SYSTEM | baseType,
+		})
+	}
+}
+
 // addStacktraceIfNotPresented generates and adds stacktrace
 // (if it's not presented by ErrLetter's field).
-func (e *Entry) addStacktraceIfNotPresented() (this *Entry) {
+// 'skip' is the same as Logger.logSkip() has.
+func (e *Entry) addStacktraceIfNotPresented(skip int) (this *Entry) {
+	if e.ErrLetter == nil {
+		e.LogLetter.StackTrace = ekasys.GetStackTrace(3+skip, -1).ExcludeInternal()
+	}
+	return e
+}
+
+// addCallerIfNotPresented saves the PC of the caller of Logger's finisher
+// (if there's no attached ekaerr.Error, that has its own stacktrace).
+// It's much cheaper than addStacktraceIfNotPresented(),
+// because the PC is resolved only when it's encoded. Read more: Caller().
+// 'skip' is the same as Logger.logSkip() has.
+func (e *Entry) addCallerIfNotPresented(skip int) (this *Entry) {
 	if e.ErrLetter == nil {
-		e.LogLetter.StackTrace = ekasys.GetStackTrace(3, -1).ExcludeInternal()
+		// 0 - runtime.Callers, 1 - addCallerIfNotPresented, 2 - logSkip,
+		// 3..2+skip - log() or logEvery(), etc., 3+skip - finisher,
+		// 4+skip - caller of finisher.
+		runtime.Callers(4+skip, e.callerPCs[:])
 	}
 	return e
 }
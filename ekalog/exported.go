@@ -10,6 +10,7 @@ import (
 	"io"
 	"time"
 
+	"github.com/qioalice/ekago/v3/ekagen"
 	"github.com/qioalice/ekago/v3/internal/ekaletter"
 )
 
@@ -177,6 +178,12 @@ func WithManyAny(fields ...any) *Logger {
 	return baseLogger.addFieldsParse(fields)
 }
 
+// WithStruct adds fields extracted from the struct 'value' using its `ekalog` tags.
+// See ekagen.Fields() for more details.
+func WithStruct(value any) *Logger {
+	return baseLogger.addFields(ekagen.Fields(value))
+}
+
 // ------------------------ CONDITIONAL LOGGING METHODS ----------------------- //
 // ---------------------------------------------------------------------------- //
 
@@ -199,6 +206,8 @@ func If(cond bool) (defaultLogger *Logger) {
 //   - If Integrator is CommonIntegrator
 //     it must have at least 1 registered io.Writer, panic otherwise.
 //
+// Read more about the calls from the logging callbacks: Logger.ReplaceIntegrator().
+//
 // WARNING.
 // Replacing Integrator will drop all pre-encoded ekaletter.LetterField fields
 // that are might be added already to the current Integrator.
@@ -206,6 +215,13 @@ func ReplaceIntegrator(newIntegrator Integrator) {
 	baseLogger.ReplaceIntegrator(newIntegrator)
 }
 
+// WithIntegrator returns a copy of the package-level Logger, that uses passed
+// Integrator. The package-level Logger is not affected.
+// See Logger.WithIntegrator() for more details.
+func WithIntegrator(newIntegrator Integrator) *Logger {
+	return baseLogger.WithIntegrator(newIntegrator)
+}
+
 // ReplaceEncoder is an alias for creating a new CommonIntegrator,
 // setting provided CI_Encoder for them and register it as a new integrator.
 // The synced stdout is used as writer if writer's set is empty.
@@ -6,9 +6,8 @@
 package ekalog
 
 import (
-	"fmt"
-
 	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekastr"
 	"github.com/qioalice/ekago/v3/internal/ekaletter"
 )
 
@@ -34,13 +33,13 @@ func Log(level Level, args ...any) (this *Logger) {
 }
 
 // Logf writes log message with desired 'level', generating log message using
-// fmt.Sprintf(format, args...) if 'format' != "" or fmt.Sprint(args...) otherwise.
+// ekastr.Sprintf(format, args...) if 'format' != "" or fmt.Sprint(args...) otherwise.
 //
 // NOTICE!
 // You can NOT add explicit/implicit fields using this method. And thus there is
 // no reflections (usage of Golang 'reflect' package).
 func Logf(level Level, format string, args ...any) (this *Logger) {
-	return baseLogger.log(level, fmt.Sprintf(format, args...), nil, nil, nil)
+	return baseLogger.log(level, ekastr.Sprintf(format, args...), nil, nil, nil)
 }
 
 func Logw(level Level, msg string, fields ...ekaletter.LetterField) (this *Logger) {
@@ -50,6 +49,13 @@ func Logww(level Level, msg string, fields []ekaletter.LetterField) (this *Logge
 	return baseLogger.log(level, msg, nil, nil, fields)
 }
 
+// Logkv writes log message with desired 'level' and fields,
+// parsed from 'kv' string like "a=1 b=\"x y\" c=true d=5s".
+// Types of values are auto-detected. Read more: ekaunsafe.ParseFields().
+func Logkv(level Level, msg string, kv string) (this *Logger) {
+	return baseLogger.log(level, msg, nil, nil, ekaletter.ParseFields(kv))
+}
+
 // ---------------------------------------------------------------------------- //
 
 // Debug is the same as Log(LEVEL_DEBUG, args...).
@@ -61,7 +67,7 @@ func Debug(args ...any) (this *Logger) {
 // Debugf is the same as Logf(LEVEL_DEBUG, format, args...).
 // Read more: Logger.Logf().
 func Debugf(format string, args ...any) (this *Logger) {
-	return baseLogger.log(LEVEL_DEBUG, fmt.Sprintf(format, args...), nil, nil, nil)
+	return baseLogger.log(LEVEL_DEBUG, ekastr.Sprintf(format, args...), nil, nil, nil)
 }
 
 func Debugw(msg string, fields ...ekaletter.LetterField) (this *Logger) {
@@ -82,7 +88,7 @@ func Info(args ...any) (this *Logger) {
 // Infof is the same as Logf(LEVEL_INFO, format, args...).
 // Read more: Logger.Logf().
 func Infof(format string, args ...any) (this *Logger) {
-	return baseLogger.log(LEVEL_INFO, fmt.Sprintf(format, args...), nil, nil, nil)
+	return baseLogger.log(LEVEL_INFO, ekastr.Sprintf(format, args...), nil, nil, nil)
 }
 
 func Infow(msg string, fields ...ekaletter.LetterField) (this *Logger) {
@@ -103,7 +109,7 @@ func Notice(args ...any) (this *Logger) {
 // Noticef is the same as Logf(LEVEL_NOTICE, format, args...).
 // Read more: Logger.Logf().
 func Noticef(format string, args ...any) (this *Logger) {
-	return baseLogger.log(LEVEL_NOTICE, fmt.Sprintf(format, args...), nil, nil, nil)
+	return baseLogger.log(LEVEL_NOTICE, ekastr.Sprintf(format, args...), nil, nil, nil)
 }
 
 func Noticew(msg string, fields ...ekaletter.LetterField) (this *Logger) {
@@ -124,7 +130,7 @@ func Warn(args ...any) (this *Logger) {
 // Warnf is the same as Logf(LEVEL_WARNING, format, args...).
 // Read more: Logger.Logf().
 func Warnf(format string, args ...any) (this *Logger) {
-	return baseLogger.log(LEVEL_WARNING, fmt.Sprintf(format, args...), nil, nil, nil)
+	return baseLogger.log(LEVEL_WARNING, ekastr.Sprintf(format, args...), nil, nil, nil)
 }
 
 func Warnw(msg string, fields ...ekaletter.LetterField) (this *Logger) {
@@ -155,7 +161,7 @@ func Error(args ...any) (this *Logger) {
 // Errorf is the same as Logf(LEVEL_ERROR, format, args...).
 // Read more: Logger.Logf().
 func Errorf(format string, args ...any) (this *Logger) {
-	return baseLogger.log(LEVEL_ERROR, fmt.Sprintf(format, args...), nil, nil, nil)
+	return baseLogger.log(LEVEL_ERROR, ekastr.Sprintf(format, args...), nil, nil, nil)
 }
 
 func Errorw(msg string, fields ...ekaletter.LetterField) (this *Logger) {
@@ -186,7 +192,7 @@ func Crit(args ...any) (this *Logger) {
 // Critf is the same as Logf(LEVEL_CRITICAL, format, args...).
 // Read more: Logger.Logf().
 func Critf(format string, args ...any) (this *Logger) {
-	return baseLogger.log(LEVEL_CRITICAL, fmt.Sprintf(format, args...), nil, nil, nil)
+	return baseLogger.log(LEVEL_CRITICAL, ekastr.Sprintf(format, args...), nil, nil, nil)
 }
 
 func Critw(msg string, fields ...ekaletter.LetterField) (this *Logger) {
@@ -217,7 +223,7 @@ func Alert(args ...any) (this *Logger) {
 // Alertf is the same as Logf(LEVEL_ALERT, format, args...).
 // Read more: Logger.Logf().
 func Alertf(format string, args ...any) (this *Logger) {
-	return baseLogger.log(LEVEL_ALERT, fmt.Sprintf(format, args...), nil, nil, nil)
+	return baseLogger.log(LEVEL_ALERT, ekastr.Sprintf(format, args...), nil, nil, nil)
 }
 
 func Alertw(msg string, fields ...ekaletter.LetterField) (this *Logger) {
@@ -240,17 +246,17 @@ func Alerteww(msg string, err *ekaerr.Error, fields []ekaletter.LetterField) (th
 // ---------------------------------------------------------------------------- //
 
 // Emerg is the same as Log(LEVEL_EMERGENCY, args...),
-// but also then calls ekadeath.Die(1).
+// but also then calls DeathHandler (ekadeath.Die(1) by default).
 // Read more: Logger.Log().
 func Emerg(args ...any) (this *Logger) {
 	return baseLogger.log(LEVEL_EMERGENCY, "", nil, args, nil)
 }
 
 // Emergf is the same as Logf(LEVEL_EMERGENCY, format, args...),
-// but also then calls death.Die(1).
+// but also then calls DeathHandler (ekadeath.Die(1) by default).
 // Read more: Logger.Logf().
 func Emergf(format string, args ...any) (this *Logger) {
-	return baseLogger.log(LEVEL_EMERGENCY, fmt.Sprintf(format, args...), nil, nil, nil)
+	return baseLogger.log(LEVEL_EMERGENCY, ekastr.Sprintf(format, args...), nil, nil, nil)
 }
 
 func Emergw(msg string, fields ...ekaletter.LetterField) (this *Logger) {
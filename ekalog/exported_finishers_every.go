@@ -0,0 +1,56 @@
+// Copyright © 2021. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"time"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// LogwEvery is the same as Logw(level, msg, fields...) but writes log message
+// not often than once per 'd' for each callsite (a place in your code
+// this method is called from).
+//
+// All log messages that are issued from the same callsite during 'd'
+// since the last written one are dropped. The number of dropped messages
+// is added to the next written message as "suppressed" field (if it's > 0).
+//
+// It's useful for the noisy places like retry loops, hot paths, etc.
+// 'd' <= 0 means no rate limiting, the same as just Logw() call.
+func LogwEvery(level Level, d time.Duration, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return baseLogger.logEvery(level, d, msg, fields)
+}
+
+// DebugwEvery is the same as LogwEvery(LEVEL_DEBUG, d, msg, fields...).
+// Read more: LogwEvery().
+func DebugwEvery(d time.Duration, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return baseLogger.logEvery(LEVEL_DEBUG, d, msg, fields)
+}
+
+// InfowEvery is the same as LogwEvery(LEVEL_INFO, d, msg, fields...).
+// Read more: LogwEvery().
+func InfowEvery(d time.Duration, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return baseLogger.logEvery(LEVEL_INFO, d, msg, fields)
+}
+
+// NoticewEvery is the same as LogwEvery(LEVEL_NOTICE, d, msg, fields...).
+// Read more: LogwEvery().
+func NoticewEvery(d time.Duration, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return baseLogger.logEvery(LEVEL_NOTICE, d, msg, fields)
+}
+
+// WarnwEvery is the same as LogwEvery(LEVEL_WARNING, d, msg, fields...).
+// Read more: LogwEvery().
+func WarnwEvery(d time.Duration, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return baseLogger.logEvery(LEVEL_WARNING, d, msg, fields)
+}
+
+// ErrorwEvery is the same as LogwEvery(LEVEL_ERROR, d, msg, fields...).
+// Read more: LogwEvery().
+func ErrorwEvery(d time.Duration, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return baseLogger.logEvery(LEVEL_ERROR, d, msg, fields)
+}
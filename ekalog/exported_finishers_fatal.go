@@ -0,0 +1,60 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekastr"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// Fatal is the same as Log(LEVEL_FATAL, args...).
+// After the log message is written, all writers are flushed
+// and then the death handler is called (see SetDeathHandler()).
+// Read more: Log().
+func Fatal(args ...any) (this *Logger) {
+	return baseLogger.log(LEVEL_FATAL, "", nil, args, nil)
+}
+
+// Fatalf is the same as Logf(LEVEL_FATAL, format, args...).
+// Read more: Fatal().
+func Fatalf(format string, args ...any) (this *Logger) {
+	return baseLogger.log(LEVEL_FATAL, ekastr.Sprintf(format, args...), nil, nil, nil)
+}
+
+// Fatalw is the same as Logw(LEVEL_FATAL, msg, fields...).
+// Read more: Fatal().
+func Fatalw(msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return baseLogger.log(LEVEL_FATAL, msg, nil, nil, fields)
+}
+
+// Fatale is the same as Emerge(msg, err, kvFields...).
+// Read more: Fatal().
+func Fatale(msg string, err *ekaerr.Error, kvFields ...any) (this *Logger) {
+	return baseLogger.log(LEVEL_FATAL, msg, err, kvFields, nil)
+}
+
+// Fatalew is the same as Emergew(msg, err, fields...).
+// Read more: Fatal().
+func Fatalew(msg string, err *ekaerr.Error, fields ...ekaletter.LetterField) (this *Logger) {
+	return baseLogger.log(LEVEL_FATAL, msg, err, nil, fields)
+}
+
+// Panicw is the same as Logw(LEVEL_CRITICAL, msg, fields...),
+// but then panics with 'msg'. It panics even if LEVEL_CRITICAL is disabled.
+func Panicw(msg string, fields ...ekaletter.LetterField) {
+	baseLogger.log(LEVEL_CRITICAL, msg, nil, nil, fields)
+	panic(msg)
+}
+
+// Panicf is the same as Logf(LEVEL_CRITICAL, format, args...),
+// but then panics with the formatted message.
+// It panics even if LEVEL_CRITICAL is disabled.
+func Panicf(format string, args ...any) {
+	msg := ekastr.Sprintf(format, args...)
+	baseLogger.log(LEVEL_CRITICAL, msg, nil, nil, nil)
+	panic(msg)
+}
@@ -0,0 +1,176 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package httpmw
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// AccessLogger is a net/http middleware, that writes one canonical log entry
+	// per request with the request's and response's details as fields:
+	// FIELD_KEY_METHOD, FIELD_KEY_PATH, FIELD_KEY_REMOTE_ADDR,
+	// FIELD_KEY_REQUEST_ID (if it's presented), FIELD_KEY_STATUS,
+	// FIELD_KEY_LATENCY (as ekaletter.KIND_TYPE_DURATION), FIELD_KEY_BYTES
+	// (response's body size) and FIELD_KEY_USER_AGENT.
+	//
+	// The requests are logged with LEVEL_INFO, LEVEL_WARNING (4xx)
+	// and LEVEL_ERROR (5xx) by default (see SetLevels()).
+	// The successful requests may be sampled (see SetSampling()),
+	// the failed ones (see SetMinErrorStatus()) are always logged.
+	//
+	// Wrap Recoverer by AccessLogger to get the panicked requests logged as 500:
+	//
+	//	h := httpmw.NewAccessLogger().SetSampling(100).Wrap(httpmw.Recover(mux))
+	//
+	// Use NewAccessLogger() to create it or AccessLog() for default behaviour.
+	AccessLogger struct {
+		logger          *ekalog.Logger
+		requestIDHeader string
+
+		levelSuccess     ekalog.Level
+		levelClientError ekalog.Level
+		levelServerError ekalog.Level
+
+		minErrorStatus int
+		sampling       uint32
+		counter        uint32 // atomic access only
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// ACCESS_LOG_MESSAGE is a message of the log entries AccessLogger writes.
+	ACCESS_LOG_MESSAGE = "HTTP request"
+)
+
+// NewAccessLogger returns a new AccessLogger, that logs all requests using
+// the package-level ekalog's Logger and takes the request ID
+// from DEFAULT_REQUEST_ID_HEADER.
+func NewAccessLogger() *AccessLogger {
+	return &AccessLogger{
+		requestIDHeader:  DEFAULT_REQUEST_ID_HEADER,
+		levelSuccess:     ekalog.LEVEL_INFO,
+		levelClientError: ekalog.LEVEL_WARNING,
+		levelServerError: ekalog.LEVEL_ERROR,
+		minErrorStatus:   http.StatusBadRequest,
+		sampling:         1,
+	}
+}
+
+// AccessLog is the same as NewAccessLogger().Wrap(next).
+func AccessLog(next http.Handler) http.Handler {
+	return NewAccessLogger().Wrap(next)
+}
+
+// SetLogger sets the Logger the requests will be logged by.
+// If 'logger' is nil, the package-level ekalog's Logger is used.
+func (al *AccessLogger) SetLogger(logger *ekalog.Logger) *AccessLogger {
+	al.logger = logger
+	return al
+}
+
+// SetRequestIDHeader sets the request's header the request ID is taken from.
+// An empty 'header' disables FIELD_KEY_REQUEST_ID field.
+func (al *AccessLogger) SetRequestIDHeader(header string) *AccessLogger {
+	al.requestIDHeader = header
+	return al
+}
+
+// SetLevels sets the levels the requests with 1xx-3xx, 4xx and 5xx
+// response's status are logged with.
+func (al *AccessLogger) SetLevels(success, clientError, serverError ekalog.Level) *AccessLogger {
+	al.levelSuccess = success
+	al.levelClientError = clientError
+	al.levelServerError = serverError
+	return al
+}
+
+// SetSampling makes AccessLogger to log only each 'every'th successful request
+// (the requests whose response's status is less than the min error status).
+// 'every' <= 1 means all requests are logged (the default).
+func (al *AccessLogger) SetSampling(every uint32) *AccessLogger {
+	if every == 0 {
+		every = 1
+	}
+	al.sampling = every
+	return al
+}
+
+// SetMinErrorStatus sets the min response's status the request is treated
+// as failed one with (and thus is never sampled out).
+// By default, it's 400 (http.StatusBadRequest).
+func (al *AccessLogger) SetMinErrorStatus(status int) *AccessLogger {
+	al.minErrorStatus = status
+	return al
+}
+
+// Wrap returns http.Handler, that calls 'next' logging its requests.
+// Panics if 'next' is nil.
+func (al *AccessLogger) Wrap(next http.Handler) http.Handler {
+
+	if next == nil {
+		panic("ekalog/httpmw: AccessLogger.Wrap: next http.Handler is nil")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := wrapResponseWriter(w)
+		start := time.Now()
+		next.ServeHTTP(rw, r)
+		al.log(rw, r, time.Since(start))
+	})
+}
+
+// log writes the log entry of the handled request if it's not sampled out.
+func (al *AccessLogger) log(rw *responseWriter, r *http.Request, latency time.Duration) {
+
+	status := rw.status
+	if status == 0 {
+		status = http.StatusOK // net/http writes it if handler writes nothing
+	}
+
+	if status < al.minErrorStatus && al.sampling > 1 &&
+		atomic.AddUint32(&al.counter, 1)%al.sampling != 1 {
+		return
+	}
+
+	var level ekalog.Level
+	switch {
+	case status >= http.StatusInternalServerError:
+		level = al.levelServerError
+	case status >= http.StatusBadRequest:
+		level = al.levelClientError
+	default:
+		level = al.levelSuccess
+	}
+
+	fields := make([]ekaletter.LetterField, 0, 8)
+	fields = append(fields,
+		ekaletter.FString(FIELD_KEY_METHOD, r.Method),
+		ekaletter.FString(FIELD_KEY_PATH, r.URL.Path),
+		ekaletter.FString(FIELD_KEY_REMOTE_ADDR, r.RemoteAddr),
+	)
+	if requestID := requestIDOf(r, al.requestIDHeader); requestID != "" {
+		fields = append(fields, ekaletter.FString(FIELD_KEY_REQUEST_ID, requestID))
+	}
+	fields = append(fields,
+		ekaletter.FInt(FIELD_KEY_STATUS, status),
+		ekaletter.FDuration(FIELD_KEY_LATENCY, latency),
+		ekaletter.FInt64(FIELD_KEY_BYTES, rw.bytes),
+		ekaletter.FString(FIELD_KEY_USER_AGENT, r.UserAgent()),
+	)
+
+	if al.logger != nil {
+		al.logger.LogwCtx(r.Context(), level, ACCESS_LOG_MESSAGE, fields...)
+	} else {
+		ekalog.LogwCtx(r.Context(), level, ACCESS_LOG_MESSAGE, fields...)
+	}
+}
@@ -0,0 +1,137 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package httpmw
+
+import (
+	"net/http"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+)
+
+type (
+	// Recoverer is a net/http middleware, that recovers the panics
+	// of the wrapped http.Handler.
+	//
+	// Recovered panic is turned into ekaerr.Error of ekaerr.InternalError class
+	// (or wraps the panic's value if it's an error) with the request's details
+	// as fields (see FIELD_KEY_ constants), which is logged at LEVEL_ERROR.
+	// The client gets 500 Internal Server Error response,
+	// that may be changed using SetResponder() or SetResponse().
+	//
+	// http.ErrAbortHandler panics are not logged and are re-panicked,
+	// as net/http expects.
+	//
+	// Use NewRecoverer() to create it or Recover() for default behaviour:
+	//
+	//	mux := http.NewServeMux()
+	//	srv := &http.Server{Handler: httpmw.NewRecoverer().
+	//	    SetLogger(ekalog.Named("http")).
+	//	    SetRequestIDHeader("X-Correlation-ID").
+	//	    Wrap(mux)}
+	Recoverer struct {
+		logger          *ekalog.Logger
+		requestIDHeader string
+		responder       Responder
+	}
+
+	// Responder is a function, that writes the response to the client,
+	// whose request's handling is failed by 'err'.
+	// 'err' is logged and released after Responder returns,
+	// so it MUST NOT be kept.
+	Responder func(w http.ResponseWriter, r *http.Request, err *ekaerr.Error)
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// Keys of request's fields, that are attached to ekaerr.Error
+	// by Recoverer and to the log entries by AccessLogger.
+
+	FIELD_KEY_METHOD      = "method"
+	FIELD_KEY_PATH        = "path"
+	FIELD_KEY_REMOTE_ADDR = "remote_addr"
+	FIELD_KEY_REQUEST_ID  = "request_id"
+	FIELD_KEY_PANIC       = "panic"
+	FIELD_KEY_STATUS      = "status"
+	FIELD_KEY_LATENCY     = "latency"
+	FIELD_KEY_BYTES       = "bytes"
+	FIELD_KEY_USER_AGENT  = "user_agent"
+
+	// DEFAULT_REQUEST_ID_HEADER is a default request's header
+	// the request ID is taken from.
+	DEFAULT_REQUEST_ID_HEADER = "X-Request-ID"
+)
+
+// NewRecoverer returns a new Recoverer, that logs using the package-level
+// ekalog's Logger, takes the request ID from DEFAULT_REQUEST_ID_HEADER
+// and responds with a plain text 500 Internal Server Error.
+func NewRecoverer() *Recoverer {
+	return &Recoverer{
+		requestIDHeader: DEFAULT_REQUEST_ID_HEADER,
+		responder:       defaultResponder,
+	}
+}
+
+// Recover is the same as NewRecoverer().Wrap(next).
+func Recover(next http.Handler) http.Handler {
+	return NewRecoverer().Wrap(next)
+}
+
+// SetLogger sets the Logger the recovered panics will be logged by.
+// If 'logger' is nil, the package-level ekalog's Logger is used.
+func (rc *Recoverer) SetLogger(logger *ekalog.Logger) *Recoverer {
+	rc.logger = logger
+	return rc
+}
+
+// SetRequestIDHeader sets the request's header the request ID is taken from.
+// An empty 'header' disables FIELD_KEY_REQUEST_ID field.
+func (rc *Recoverer) SetRequestIDHeader(header string) *Recoverer {
+	rc.requestIDHeader = header
+	return rc
+}
+
+// SetResponder sets the function, that writes the response to the client
+// after the panic is recovered.
+// If 'responder' is nil, the default plain text 500 Internal Server Error is used.
+//
+// The response is not written if the wrapped http.Handler has written
+// the headers already.
+func (rc *Recoverer) SetResponder(responder Responder) *Recoverer {
+	if responder == nil {
+		responder = defaultResponder
+	}
+	rc.responder = responder
+	return rc
+}
+
+// SetResponse is the same as SetResponder() but with the responder,
+// that writes 500 Internal Server Error with 'body' of 'contentType'.
+func (rc *Recoverer) SetResponse(contentType string, body []byte) *Recoverer {
+	body = append([]byte(nil), body...)
+	return rc.SetResponder(func(w http.ResponseWriter, _ *http.Request, _ *ekaerr.Error) {
+		if contentType != "" {
+			w.Header().Set("Content-Type", contentType)
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = w.Write(body)
+	})
+}
+
+// Wrap returns http.Handler, that calls 'next' recovering its panics.
+// Panics if 'next' is nil.
+func (rc *Recoverer) Wrap(next http.Handler) http.Handler {
+
+	if next == nil {
+		panic("ekalog/httpmw: Recoverer.Wrap: next http.Handler is nil")
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rw := wrapResponseWriter(w)
+		defer rc.recover(rw, r)
+		next.ServeHTTP(rw, r)
+	})
+}
@@ -0,0 +1,142 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package httpmw
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"net/http"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+)
+
+type (
+	// responseWriter is an http.ResponseWriter wrapper, that tracks
+	// the response's status code and the number of written body bytes.
+	responseWriter struct {
+		http.ResponseWriter
+		status int // 0 if the headers are not written yet
+		bytes  int64
+	}
+)
+
+// wrapResponseWriter returns 'w' if it's responseWriter already,
+// or wraps it otherwise.
+func wrapResponseWriter(w http.ResponseWriter) *responseWriter {
+	if rw, ok := w.(*responseWriter); ok {
+		return rw
+	}
+	return &responseWriter{ResponseWriter: w}
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// Flush implements http.Flusher if the wrapped http.ResponseWriter does.
+func (rw *responseWriter) Flush() {
+	if f, ok := rw.ResponseWriter.(http.Flusher); ok {
+		if rw.status == 0 {
+			rw.status = http.StatusOK
+		}
+		f.Flush()
+	}
+}
+
+// Hijack implements http.Hijacker if the wrapped http.ResponseWriter does.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	if h, ok := rw.ResponseWriter.(http.Hijacker); ok {
+		return h.Hijack()
+	}
+	return nil, nil, http.ErrNotSupported
+}
+
+// Unwrap returns the wrapped http.ResponseWriter (used by http.ResponseController).
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+// headerWritten reports whether the response's headers are written already.
+func (rw *responseWriter) headerWritten() bool {
+	return rw.status != 0
+}
+
+// recover must be deferred. If there's a panic, writes the response
+// using Recoverer's Responder and logs the panic as ekaerr.Error.
+func (rc *Recoverer) recover(rw *responseWriter, r *http.Request) {
+
+	v := recover()
+	if v == nil {
+		return
+	}
+	if v == http.ErrAbortHandler {
+		panic(v)
+	}
+
+	const msg = "HTTP handler panicked"
+
+	var err *ekaerr.Error
+	if vErr, ok := v.(error); ok {
+		err = ekaerr.InternalError.Wrap(vErr, msg)
+	} else {
+		err = ekaerr.InternalError.New(msg)
+	}
+
+	err.
+		WithString(FIELD_KEY_PANIC, panicToString(v)).
+		WithString(FIELD_KEY_METHOD, r.Method).
+		WithString(FIELD_KEY_PATH, r.URL.Path).
+		WithString(FIELD_KEY_REMOTE_ADDR, r.RemoteAddr)
+
+	if requestID := requestIDOf(r, rc.requestIDHeader); requestID != "" {
+		err.WithString(FIELD_KEY_REQUEST_ID, requestID)
+	}
+
+	// Logging releases ekaerr.Error, so the response must be written before.
+	if !rw.headerWritten() {
+		rc.responder(rw, r, err)
+	}
+
+	if rc.logger != nil {
+		rc.logger.ErrorewCtx(r.Context(), "", err)
+	} else {
+		ekalog.ErrorewCtx(r.Context(), "", err)
+	}
+}
+
+// defaultResponder is a Responder, that writes plain text
+// 500 Internal Server Error.
+func defaultResponder(w http.ResponseWriter, _ *http.Request, _ *ekaerr.Error) {
+	http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+}
+
+// requestIDOf returns the value of request's 'header'
+// or an empty string if 'header' is empty.
+func requestIDOf(r *http.Request, header string) string {
+	if header == "" {
+		return ""
+	}
+	return r.Header.Get(header)
+}
+
+// panicToString returns a string representation of the panic's value.
+func panicToString(v any) string {
+	return fmt.Sprint(v)
+}
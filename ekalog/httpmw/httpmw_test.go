@@ -0,0 +1,172 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package httpmw_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekalog/httpmw"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testReplaceIntegrator() *bytes.Buffer {
+	var buf bytes.Buffer
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_JSONEncoder)).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(&buf))
+	return &buf
+}
+
+func TestRecoverer(t *testing.T) {
+
+	buf := testReplaceIntegrator()
+
+	h := httpmw.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	r := httptest.NewRequest(http.MethodPost, "/api/users?id=1", nil)
+	r.Header.Set(httpmw.DEFAULT_REQUEST_ID_HEADER, "req-42")
+	w := httptest.NewRecorder()
+
+	require.NotPanics(t, func() { h.ServeHTTP(w, r) })
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "Internal Server Error\n", w.Body.String())
+
+	out := buf.String()
+	assert.Contains(t, out, `"error_class_name":"InternalError"`)
+	assert.Contains(t, out, `"panic":"boom"`)
+	assert.Contains(t, out, `"method":"POST"`)
+	assert.Contains(t, out, `"path":"/api/users"`)
+	assert.Contains(t, out, `"remote_addr":"192.0.2.1:1234"`)
+	assert.Contains(t, out, `"request_id":"req-42"`)
+}
+
+func TestRecoverer_Custom(t *testing.T) {
+
+	_ = testReplaceIntegrator()
+
+	var gotErr *ekaerr.Error
+	cause := errors.New("db is down")
+
+	h := httpmw.NewRecoverer().
+		SetRequestIDHeader("").
+		SetResponder(func(w http.ResponseWriter, r *http.Request, err *ekaerr.Error) {
+			gotErr = err
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_, _ = w.Write([]byte(`{"error":"unavailable"}`))
+		}).
+		Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			panic(cause)
+		}))
+
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, `{"error":"unavailable"}`, w.Body.String())
+	require.True(t, gotErr.IsNotNil())
+	assert.True(t, gotErr.Is(ekaerr.InternalError))
+
+	// Headers are written already, response must not be overwritten.
+	h = httpmw.NewRecoverer().
+		SetResponse("text/plain", []byte("oops")).
+		Wrap(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("late")
+		}))
+
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	assert.Equal(t, http.StatusAccepted, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	// http.ErrAbortHandler must be re-panicked.
+	h = httpmw.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	}))
+
+	assert.PanicsWithValue(t, http.ErrAbortHandler, func() {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	})
+}
+
+func TestAccessLogger(t *testing.T) {
+
+	buf := testReplaceIntegrator()
+
+	h := httpmw.NewAccessLogger().
+		SetSampling(3).
+		Wrap(httpmw.Recover(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/missing":
+				http.NotFound(w, r)
+			case "/panic":
+				panic("boom")
+			default:
+				_, _ = w.Write([]byte("hello"))
+			}
+		})))
+
+	serve := func(path string) map[string]any {
+		buf.Reset()
+		r := httptest.NewRequest(http.MethodGet, path, nil)
+		r.Header.Set("User-Agent", "test-agent")
+		h.ServeHTTP(httptest.NewRecorder(), r)
+
+		lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte{'\n'})
+		if len(lines[0]) == 0 {
+			return nil
+		}
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal(lines[len(lines)-1], &entry))
+		return entry
+	}
+
+	entry := serve("/ok")
+	require.NotNil(t, entry)
+	fields := entry["fields"].(map[string]any)
+	assert.Equal(t, httpmw.ACCESS_LOG_MESSAGE, entry["message"])
+	assert.Equal(t, "Info", entry["level"])
+	assert.Equal(t, float64(200), fields["status"])
+	assert.Equal(t, float64(5), fields["bytes"])
+	assert.Equal(t, "/ok", fields["path"])
+	assert.Equal(t, "test-agent", fields["user_agent"])
+	assert.Contains(t, fields, "latency")
+
+	// 2xx are sampled: only each 3rd is logged.
+	assert.Nil(t, serve("/ok"))
+	assert.Nil(t, serve("/ok"))
+	assert.NotNil(t, serve("/ok"))
+
+	// Errors are never sampled.
+	for i := 0; i < 3; i++ {
+		entry = serve("/missing")
+		require.NotNil(t, entry)
+		fields = entry["fields"].(map[string]any)
+		assert.Equal(t, "Warning", entry["level"])
+		assert.Equal(t, float64(404), fields["status"])
+	}
+
+	entry = serve("/panic")
+	require.NotNil(t, entry)
+	fields = entry["fields"].(map[string]any)
+	assert.Equal(t, "Error", entry["level"])
+	assert.Equal(t, float64(500), fields["status"])
+}
@@ -11,6 +11,7 @@ import (
 
 func init() {
 	entryPool.New = allocEntry
+	correlationIDGenerator.Store(defaultCorrelationIDGenerator)
 
 	defaultConsoleEncoder = new(CI_ConsoleEncoder).doBuild()
 	defaultJSONEncoder = new(CI_JSONEncoder).doBuild()
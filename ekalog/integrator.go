@@ -6,6 +6,9 @@
 package ekalog
 
 import (
+	"time"
+
+	"github.com/qioalice/ekago/v3/internal/ekaclike"
 	"github.com/qioalice/ekago/v3/internal/ekaletter"
 )
 
@@ -53,3 +56,94 @@ type Integrator interface {
 	// Logger type has the same name's method that just calls this method.
 	Sync() error
 }
+
+// IntegratorWrapper is an Integrator that wraps another one and passes Entry
+// to it. Implement it by your own Integrator wrappers, so Logger may reach
+// the wrapped Integrator (e.g. to prepare CommonIntegrator at the
+// Logger.ReplaceIntegrator() call or to flush it before the death).
+type IntegratorWrapper interface {
+	Integrator
+	Unwrap() Integrator
+}
+
+// integratorWrapper is the same as IntegratorWrapper
+// but for this package's wrappers (e.g. ResourceUsageIntegrator).
+type integratorWrapper interface {
+	Integrator
+	unwrap() Integrator
+}
+
+// unwrapIntegrator returns the innermost Integrator wrapped by 'integrator'
+// (or 'integrator' itself if it's not a wrapper).
+func unwrapIntegrator(integrator Integrator) Integrator {
+	for {
+		wrapped, ok := unwrapIntegratorOnce(integrator)
+		if !ok {
+			return integrator
+		}
+		integrator = wrapped
+	}
+}
+
+// unwrapIntegratorOnce returns the Integrator wrapped by 'integrator' and true
+// if it's an IntegratorWrapper (with non-nil wrapped one) or integratorWrapper,
+// or false otherwise.
+func unwrapIntegratorOnce(integrator Integrator) (Integrator, bool) {
+	switch wrapper := integrator.(type) {
+	case integratorWrapper:
+		return wrapper.unwrap(), true
+	case IntegratorWrapper:
+		wrapped := wrapper.Unwrap()
+		return wrapped, ekaclike.TakeRealAddr(wrapped) != nil
+	default:
+		return nil, false
+	}
+}
+
+// TriggeredIntegrator is an Integrator, that may write Entry flushed
+// by the trigger scope (read more: Logger.BeginTriggerScope()), even if its Level
+// is less severe than MinLevelEnabled(). The buffered Entry of a trigger scope
+// are written only if Integrator is TriggeredIntegrator or their Level
+// is enabled by Integrator.
+//
+// EncodeAndWriteTriggered must write Entry the same way the Entry of 'triggerLevel'
+// (the Level of Entry that has triggered the flush) is written.
+// The same restrictions as for EncodeAndWrite() are applied.
+type TriggeredIntegrator interface {
+	Integrator
+	EncodeAndWriteTriggered(entry *Entry, triggerLevel Level)
+}
+
+// integratorCallerLeveler is an Integrator that may require only caller
+// (w/o the whole stacktrace) for the Entry of some levels
+// (e.g. CommonIntegrator, read more: CommonIntegrator.WithMinLevelForCaller()).
+type integratorCallerLeveler interface {
+	MinLevelForCaller() Level
+}
+
+// integratorClock is an Integrator that provides the time source
+// of Entry's timestamp (e.g. CommonIntegrator, read more: CommonIntegrator.WithClock()).
+type integratorClock interface {
+	Clock() func() time.Time
+}
+
+// clockOf returns the time source of Entry's timestamp provided by 'integrator'
+// (or by the Integrator it wraps). Returns time.Now if it's not an integratorClock.
+func clockOf(integrator Integrator) func() time.Time {
+	if clocker, ok := unwrapIntegrator(integrator).(integratorClock); ok {
+		if clock := clocker.Clock(); clock != nil {
+			return clock
+		}
+	}
+	return time.Now
+}
+
+// minLevelForCaller returns a minimum level starting with only caller's PC
+// must be captured for Entry by 'integrator' (or by the Integrator it wraps).
+// Returns integrator.MinLevelForStackTrace() if it's not an integratorCallerLeveler.
+func minLevelForCaller(integrator Integrator) Level {
+	if leveler, ok := unwrapIntegrator(integrator).(integratorCallerLeveler); ok {
+		return leveler.MinLevelForCaller()
+	}
+	return integrator.MinLevelForStackTrace()
+}
@@ -0,0 +1,270 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// ChannelIntegrator is an Integrator, that delivers Entry snapshots
+	// (see ChannelEntry) to the Golang channel, so the applications may build
+	// their own in-process consumers (TUI viewers, test harnesses,
+	// websocket streamers, etc) w/o implementing Integrator from scratch:
+	//
+	//	ci := ekalog.NewChannelIntegrator(1024).SetEncoder(new(ekalog.CI_JSONEncoder))
+	//	ekalog.ReplaceIntegrator(ci)
+	//	go func() {
+	//	    for e := range ci.C() {
+	//	        // e.Level, e.Message, e.Fields, e.Encoded, ...
+	//	    }
+	//	}()
+	//
+	// What happens when the channel's buffer is full is determined
+	// by ChannelOverflowPolicy (see SetOverflowPolicy()).
+	//
+	// Use NewChannelIntegrator() to create it.
+	ChannelIntegrator struct {
+		mu      sync.Mutex
+		ch      chan ChannelEntry
+		closed  bool
+		encoder CI_Encoder
+		policy  ChannelOverflowPolicy
+
+		minLevel              Level
+		minLevelForStackTrace Level
+
+		dropped uint64 // atomic access only
+	}
+
+	// ChannelEntry is a snapshot of Entry, that ChannelIntegrator delivers.
+	// It's detached from Entry, so it may be kept as long as it's needed.
+	ChannelEntry struct {
+		Level   Level
+		Time    time.Time
+		Message string
+
+		// Fields is a copy of Entry's fields (w/o attached ekaerr.Error's ones).
+		Fields []ekaletter.LetterField
+
+		// ErrorClassName, ErrorID, ErrorMessage are the class name, the ID
+		// and the last message of attached ekaerr.Error or empty strings
+		// if there's no attached ekaerr.Error.
+		ErrorClassName string
+		ErrorID        string
+		ErrorMessage   string
+
+		// Encoded is Entry encoded by ChannelIntegrator's encoder
+		// or nil if there's no encoder (see ChannelIntegrator.SetEncoder()).
+		Encoded []byte
+	}
+
+	// ChannelOverflowPolicy is the way ChannelIntegrator handles Entry,
+	// when the channel's buffer is full.
+	ChannelOverflowPolicy uint8
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// CHANNEL_OVERFLOW_DROP_NEWEST drops the Entry that is being delivered.
+	// It's the default policy. Logging never blocks.
+	CHANNEL_OVERFLOW_DROP_NEWEST ChannelOverflowPolicy = iota
+
+	// CHANNEL_OVERFLOW_DROP_OLDEST drops the oldest buffered Entry
+	// to deliver the new one. Logging never blocks.
+	CHANNEL_OVERFLOW_DROP_OLDEST
+
+	// CHANNEL_OVERFLOW_BLOCK blocks logging until the consumer
+	// reads from the channel. No Entry is dropped.
+	CHANNEL_OVERFLOW_BLOCK
+)
+
+// CHANNEL_INTEGRATOR_DEFAULT_BUFFER is a default buffer's size
+// of ChannelIntegrator's channel if a non-positive size is passed.
+//
+//goland:noinspection GoSnakeCaseUsage
+const CHANNEL_INTEGRATOR_DEFAULT_BUFFER = 256
+
+// NewChannelIntegrator returns a new ChannelIntegrator, whose channel
+// has 'bufferSize' buffer, that handles Entry of all levels
+// and generates stacktrace starting from LEVEL_WARNING.
+func NewChannelIntegrator(bufferSize int) *ChannelIntegrator {
+	if bufferSize <= 0 {
+		bufferSize = CHANNEL_INTEGRATOR_DEFAULT_BUFFER
+	}
+	return &ChannelIntegrator{
+		ch:                    make(chan ChannelEntry, bufferSize),
+		minLevel:              LEVEL_DEBUG,
+		minLevelForStackTrace: LEVEL_WARNING,
+	}
+}
+
+// SetEncoder sets the encoder, Entry is encoded by to ChannelEntry.Encoded.
+// Nil 'encoder' (the default) disables encoding.
+// The encoder MUST NOT be shared with another Integrator.
+func (ci *ChannelIntegrator) SetEncoder(encoder CI_Encoder) *ChannelIntegrator {
+
+	switch encTyped := encoder.(type) {
+	case *CI_ConsoleEncoder:
+		encTyped.doBuild()
+	case *CI_JSONEncoder:
+		encTyped.doBuild()
+	}
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	ci.encoder = encoder
+	return ci
+}
+
+// SetOverflowPolicy sets the way the Entry is handled when the channel's
+// buffer is full. CHANNEL_OVERFLOW_DROP_NEWEST by default.
+func (ci *ChannelIntegrator) SetOverflowPolicy(policy ChannelOverflowPolicy) *ChannelIntegrator {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.policy = policy
+	return ci
+}
+
+// SetMinLevel sets the least severe Level, the Entry of which is delivered.
+// LEVEL_DEBUG by default.
+func (ci *ChannelIntegrator) SetMinLevel(level Level) *ChannelIntegrator {
+	ci.minLevel = level
+	return ci
+}
+
+// SetMinLevelForStackTrace sets the least severe Level, the Entry of which
+// gets the stacktrace. LEVEL_WARNING by default.
+func (ci *ChannelIntegrator) SetMinLevelForStackTrace(level Level) *ChannelIntegrator {
+	ci.minLevelForStackTrace = level
+	return ci
+}
+
+// C returns the channel ChannelEntry are delivered to.
+// It's closed by Close().
+func (ci *ChannelIntegrator) C() <-chan ChannelEntry {
+	return ci.ch
+}
+
+// Dropped returns the number of Entry, that have been dropped
+// because of the channel's buffer overflow or because of ChannelIntegrator
+// has been closed.
+func (ci *ChannelIntegrator) Dropped() uint64 {
+	return atomic.LoadUint64(&ci.dropped)
+}
+
+// Close closes the channel. All next Entry are dropped.
+// If CHANNEL_OVERFLOW_BLOCK is used, it waits until the blocked delivery is done.
+// Next calls do nothing.
+func (ci *ChannelIntegrator) Close() {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if !ci.closed {
+		ci.closed = true
+		close(ci.ch)
+	}
+}
+
+// PreEncodeField pre-encodes 'f' by ChannelIntegrator's encoder (if any).
+func (ci *ChannelIntegrator) PreEncodeField(f ekaletter.LetterField) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	if ci.encoder != nil {
+		ci.encoder.PreEncodeField(f)
+	}
+}
+
+// EncodeAndWrite makes a snapshot of 'entry' and delivers it to the channel
+// according to the ChannelOverflowPolicy.
+func (ci *ChannelIntegrator) EncodeAndWrite(entry *Entry) {
+
+	ce := ChannelEntry{
+		Level:   entry.Level,
+		Time:    entry.Time,
+		Message: ekaletter.LGetMessage(entry.LogLetter),
+	}
+
+	if n := len(entry.LogLetter.Fields); n > 0 {
+		ce.Fields = make([]ekaletter.LetterField, n)
+		copy(ce.Fields, entry.LogLetter.Fields)
+	}
+
+	if errLetter := entry.ErrLetter; errLetter != nil {
+		for i, n := 0, len(errLetter.SystemFields); i < n; i++ {
+			switch f := &errLetter.SystemFields[i]; f.BaseType() {
+			case ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME:
+				ce.ErrorClassName = f.SValue
+			case ekaletter.KIND_SYS_TYPE_EKAERR_UUID:
+				ce.ErrorID = f.SValue
+			}
+		}
+		for i := len(errLetter.Messages) - 1; i >= 0 && ce.ErrorMessage == ""; i-- {
+			ce.ErrorMessage = errLetter.Messages[i].Body
+		}
+	}
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	if ci.closed {
+		atomic.AddUint64(&ci.dropped, 1)
+		return
+	}
+
+	if ci.encoder != nil {
+		if encoded := ci.encoder.EncodeEntry(entry); len(encoded) > 0 {
+			ce.Encoded = append([]byte(nil), encoded...)
+		}
+	}
+
+	switch ci.policy {
+
+	case CHANNEL_OVERFLOW_BLOCK:
+		ci.ch <- ce
+
+	case CHANNEL_OVERFLOW_DROP_OLDEST:
+		for {
+			select {
+			case ci.ch <- ce:
+				return
+			default:
+			}
+			select {
+			case <-ci.ch:
+				atomic.AddUint64(&ci.dropped, 1)
+			default:
+			}
+		}
+
+	default:
+		select {
+		case ci.ch <- ce:
+		default:
+			atomic.AddUint64(&ci.dropped, 1)
+		}
+	}
+}
+
+// MinLevelEnabled returns the least severe Level, the Entry of which is delivered.
+func (ci *ChannelIntegrator) MinLevelEnabled() Level {
+	return ci.minLevel
+}
+
+// MinLevelForStackTrace returns the least severe Level, the Entry of which
+// gets the stacktrace.
+func (ci *ChannelIntegrator) MinLevelForStackTrace() Level {
+	return ci.minLevelForStackTrace
+}
+
+// Sync does nothing, since ChannelIntegrator delivers Entry synchronously.
+func (ci *ChannelIntegrator) Sync() error {
+	return nil
+}
@@ -0,0 +1,60 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChannelIntegrator(t *testing.T) {
+
+	ci := ekalog.NewChannelIntegrator(2).
+		SetEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{l}} {{m}}"))
+	ekalog.ReplaceIntegrator(ci)
+
+	ekalog.Info("first", "key", "value")
+	ekalog.Errore("failed", ekaerr.IllegalState.New("boom"))
+	ekalog.Info("dropped")
+
+	assert.Equal(t, uint64(1), ci.Dropped())
+
+	e := <-ci.C()
+	assert.Equal(t, ekalog.LEVEL_INFO, e.Level)
+	assert.Equal(t, "first", e.Message)
+	require.Len(t, e.Fields, 1)
+	assert.Equal(t, "key", e.Fields[0].Key)
+	assert.Contains(t, string(e.Encoded), "first")
+	assert.False(t, e.Time.IsZero())
+
+	e = <-ci.C()
+	assert.Equal(t, ekalog.LEVEL_ERROR, e.Level)
+	assert.Equal(t, "failed", e.Message)
+	assert.Equal(t, ekaerr.IllegalState.FullName(), e.ErrorClassName)
+	assert.Equal(t, "boom", e.ErrorMessage)
+	assert.Len(t, e.ErrorID, 26)
+
+	ci.SetOverflowPolicy(ekalog.CHANNEL_OVERFLOW_DROP_OLDEST)
+	ekalog.Info("1")
+	ekalog.Info("2")
+	ekalog.Info("3")
+
+	assert.Equal(t, uint64(2), ci.Dropped())
+	assert.Equal(t, "2", (<-ci.C()).Message)
+	assert.Equal(t, "3", (<-ci.C()).Message)
+
+	ci.Close()
+	ekalog.Info("closed")
+
+	_, ok := <-ci.C()
+	assert.False(t, ok)
+	assert.Equal(t, uint64(3), ci.Dropped())
+}
@@ -6,8 +6,11 @@
 package ekalog
 
 import (
+	"context"
 	"io"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/qioalice/ekago/v3/ekatyp"
 	"github.com/qioalice/ekago/v3/internal/ekaclike"
@@ -35,6 +38,13 @@ type (
 	//
 	// And all of these things can be done using only one CommonIntegrator object!
 	//
+	// The same io.Writer may be registered many times with different encoders
+	// (e.g. JSON for the LEVEL_ERROR and more dangerous entries and plain text
+	// for all of them), and the same encoder may be registered many times
+	// with different min levels. Entry is encoded once per encoder when it's possible.
+	// If WithMinLevel() is not called for writers, they inherit the min level
+	// of the previously registered ones (LEVEL_WARNING for the first ones).
+	//
 	// How? Look:
 	// 		ig := new(CommonIntegrator)
 	// 		ig = ig.WithEncoder(encoder1).      // there is 1st writer registration begin
@@ -62,6 +72,13 @@ type (
 	// WILL BE DONE WITH THE SAME GOROUTINE, THE CALLER REQUEST LOGGING
 	// IS RUNNING IN.
 	//
+	// If io.Writer logs through ekalog while it's writing (directly
+	// or e.g. using ekaerr.Error.LogAsError()), such Entry is written
+	// to the os.Stderr using the same encoder instead of deadlocking
+	// or infinite recursion. It's tracked per goroutine and costs ~1µs per Entry,
+	// so it's enabled only if some of io.Writer is not a file (*os.File),
+	// an in-memory buffer (*bytes.Buffer, *strings.Builder) or io.Discard.
+	//
 	// WARNING.
 	// CommonIntegrator is thread-unsafety at the initialization*
 	// and unavailable to be modified after it is registered** with some Logger.
@@ -116,6 +133,10 @@ type (
 		// for stacktrace being generated for.
 		stll Level
 
+		// cll is the lowest level among all output's minimum Level
+		// for caller being captured for (if stacktrace is not generated).
+		cll Level
+
 		// output contains an outputs that are under registration
 		// or already approved.
 		output []_CI_Output
@@ -123,8 +144,76 @@ type (
 		// idx is an index of output to object that is under initialization
 		// right now.
 		idx int
+
+		// dd is a duplicates suppressor.
+		// It's nil if deduplication is disabled (by default).
+		dd *_CI_Deduper
+
+		// rg is a reentrancy guard, that routes the Entry logged from
+		// the io.Writer (while it's writing) to os.Stderr.
+		// It's nil if it's not enabled by WithReentrancyGuard()
+		// or no one of io.Writer may log (read more: ciWriterMayLog()).
+		rg *_CI_ReentrancyGuard
+
+		// isReentrancyGuarded is true if reentrancy guard is enabled
+		// by WithReentrancyGuard(). Read more: rg.
+		isReentrancyGuarded bool
+
+		// clock is the time source of Entry's timestamp
+		// or nil if it's time.Now. Read more: WithClock().
+		clock func() time.Time
+
+		// metrics are the metrics, derived from the log entries.
+		// It's nil if there are no ones (read more: CountEntries()).
+		metrics *_CI_Metrics
+
+		// isClosed is 1 if Close() has been called.
+		// All next entries are dropped then. Atomic access only.
+		isClosed uint32
+	}
+
+	// CI_WriterFlusher is an interface that writers of CommonIntegrator
+	// may implement to be flushed by CommonIntegrator.Flush(),
+	// honoring context.Context's deadline.
+	//
+	// Writers that implement only ekatyp.Syncer are flushed too,
+	// but their Sync() calls cannot be interrupted.
+	CI_WriterFlusher interface {
+		Flush(ctx context.Context) error
 	}
 
+	// CI_WriterCloser is an interface that writers of CommonIntegrator
+	// may implement to be closed by CommonIntegrator.Close(),
+	// honoring context.Context's deadline.
+	//
+	// Writers that implement only io.Closer are closed too,
+	// but their Close() calls cannot be interrupted.
+	CI_WriterCloser interface {
+		Close(ctx context.Context) error
+	}
+
+	// CI_WriterRemote is an interface that writers of CommonIntegrator
+	// may implement to report whether they send the data out of the host
+	// (to the network). The Entry marked by Logger.Local() are not written
+	// to the remote writers.
+	//
+	// net.Conn is always remote. The wrappers (BufferedWriter, TimeoutWriter
+	// and the chains of WriteToWithFallback()) are remote if any of
+	// the wrapped writers is.
+	CI_WriterRemote interface {
+		IsRemote() bool
+	}
+
+	// CI_PostProcessor is a transform of encoded Entry, that is applied
+	// after the Entry is encoded and before it's written to the writers
+	// (e.g. injecting a tenant prefix, wrapping in a syslog frame,
+	// length-prefix framing for TCP).
+	//
+	// It MUST NOT modify provided data, since the same encoded Entry may be
+	// written to many outputs, but it may return it as is, if it's not changed.
+	// Post-processors are registered by CommonIntegrator.WithPostProcessors().
+	CI_PostProcessor func(encoded []byte) []byte
+
 	// CI_Encoder is an interface that types must implement to be allowed
 	// for being register with CommonIntegrator as one of encoders.
 	CI_Encoder interface {
@@ -141,6 +230,29 @@ type (
 	}
 )
 
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// CI_FALLBACK_MESSAGE_FAILOVER is a message of the self-diagnostic Entry,
+	// that is written when the writer of the fallback chain has failed
+	// and the next one is used. Read more: CommonIntegrator.WriteToWithFallback().
+	CI_FALLBACK_MESSAGE_FAILOVER = "ekalog: writer failover"
+
+	// CI_FALLBACK_MESSAGE_RECOVERY is a message of the self-diagnostic Entry,
+	// that is written when the previous writer of the fallback chain
+	// (e.g. the primary one) is used again.
+	// Read more: CommonIntegrator.WriteToWithFallback().
+	CI_FALLBACK_MESSAGE_RECOVERY = "ekalog: writer recovered"
+
+	// CI_FALLBACK_FIELD_ERROR is a key of the field of the self-diagnostic Entry
+	// the error of the failed writer is stored by (if any).
+	CI_FALLBACK_FIELD_ERROR = "fallback_error"
+
+	// CI_FALLBACK_FIELD_WRITER_IDX is a key of the field of the self-diagnostic Entry
+	// the index of the writer, that is used now, is stored by.
+	// 0 is the primary writer, 1 is the first fallback, etc.
+	CI_FALLBACK_FIELD_WRITER_IDX = "fallback_writer_idx"
+)
+
 // --------------------- IMPLEMENT Integrator INTERFACE ----------------------- //
 // ---------------------------------------------------------------------------- //
 
@@ -174,6 +286,29 @@ func (ci *CommonIntegrator) MinLevelForStackTrace() Level {
 	return ci.stll
 }
 
+// MinLevelForCaller returns a minimum level starting with an Entry
+// must capture the caller's PC (that is resolved lazily at the encoding)
+// if stacktrace is not generated for it (see MinLevelForStackTrace()).
+//
+// This method is used by internal Logger's part and this level may be set by
+// WithMinLevelForCaller().
+func (ci *CommonIntegrator) MinLevelForCaller() Level {
+	ci.assertNil()
+	return ci.cll
+}
+
+// Clock returns the time source of Entry's timestamp,
+// that is set by WithClock() or time.Now by default.
+//
+// This method is used by internal Logger's part.
+func (ci *CommonIntegrator) Clock() func() time.Time {
+	ci.assertNil()
+	if ci.clock == nil {
+		return time.Now
+	}
+	return ci.clock
+}
+
 // PreEncodeField passes presented ekaletter.LetterField to all registered
 // CI_Encoder objects, saving it as encoded RAW data inside them to attach them later
 // to each Entry that must be logged.
@@ -187,8 +322,17 @@ func (ci *CommonIntegrator) PreEncodeField(f ekaletter.LetterField) {
 
 	ci.assertNil()
 
+	// The same encoder may be registered for many outputs,
+	// but field must be pre-encoded once.
 	for i, n := 0, len(ci.output); i < n; i++ {
-		ci.output[i].encoder.PreEncodeField(f)
+		isDuplicate := false
+		for j := 0; j < i && !isDuplicate; j++ {
+			isDuplicate = ekaclike.TakeRealAddr(ci.output[j].encoder) ==
+				ekaclike.TakeRealAddr(ci.output[i].encoder)
+		}
+		if !isDuplicate {
+			ci.output[i].encoder.PreEncodeField(f)
+		}
 	}
 }
 
@@ -203,26 +347,65 @@ func (ci *CommonIntegrator) EncodeAndWrite(entry *Entry) {
 
 	ci.assertNil()
 
-	// it guarantees that ci.output is not empty,
-	// because each CommonIntegrator object is checked by tryToBuild().
+	if atomic.LoadUint32(&ci.isClosed) != 0 {
+		return
+	}
 
-	for _, output := range ci.output {
+	if ci.metrics != nil {
+		ci.metrics.observe(entry)
+	}
 
-		// maybe we must remove stacktrace?
-		logStacktraceBak := entry.LogLetter.StackTrace
-		if output.stacktraceMinLevel > entry.Level {
-			entry.LogLetter.StackTrace = nil
+	if ci.rg != nil {
+		gid, reentrant := ci.rg.enter()
+		if reentrant {
+			ci.encodeAndWriteReentrant(entry, entry.Level)
+			return
 		}
+		defer ci.rg.leave(gid)
+	}
+
+	if ci.dd != nil {
+		suppress, repeated, repeatedLevel := ci.dd.check(entry)
+		if repeated > 0 {
+			ci.encodeAndWriteRepeated(repeated, repeatedLevel)
+		}
+		if suppress {
+			return
+		}
+	}
+
+	ci.encodeAndWrite(entry, entry.Level)
+}
+
+// EncodeAndWriteTriggered is the same as EncodeAndWrite(), but Entry is written
+// to the same io.Writer objects the Entry of 'triggerLevel' is written to,
+// regardless of Entry's Level. Deduplication is not applied.
+//
+// It's used by trigger scope to flush buffered Entry (read more:
+// Logger.BeginTriggerScope()) and MUST NOT be called directly.
+// Implements TriggeredIntegrator.
+func (ci *CommonIntegrator) EncodeAndWriteTriggered(entry *Entry, triggerLevel Level) {
 
-		encodedEntry := output.encoder.EncodeEntry(entry)
+	ci.assertNil()
 
-		// restore stacktrace
-		entry.LogLetter.StackTrace = logStacktraceBak
+	if atomic.LoadUint32(&ci.isClosed) != 0 {
+		return
+	}
 
-		for _, destination := range output.writers {
-			_, _ = destination.Write(encodedEntry)
+	if ci.metrics != nil {
+		ci.metrics.observe(entry)
+	}
+
+	if ci.rg != nil {
+		gid, reentrant := ci.rg.enter()
+		if reentrant {
+			ci.encodeAndWriteReentrant(entry, triggerLevel)
+			return
 		}
+		defer ci.rg.leave(gid)
 	}
+
+	ci.encodeAndWrite(entry, triggerLevel)
 }
 
 // Sync flushes all pending log entries to all registered destinations,
@@ -239,8 +422,15 @@ func (ci *CommonIntegrator) Sync() error {
 		return nil
 	}
 
+	// There could be suppressed duplicates that are not reported yet.
+	if ci.dd != nil {
+		if repeated, repeatedLevel := ci.dd.flush(); repeated > 0 {
+			ci.encodeAndWriteRepeated(repeated, repeatedLevel)
+		}
+	}
+
 	for _, output := range ci.output {
-		for _, destination := range output.writers {
+		for _, destination := range output.allWriters() {
 			if syncer, ok := destination.(ekatyp.Syncer); ok {
 				if err := syncer.Sync(); err != nil {
 					return err
@@ -252,6 +442,57 @@ func (ci *CommonIntegrator) Sync() error {
 	return nil
 }
 
+// Flush flushes all pending log entries to all registered writers,
+// that implement CI_WriterFlusher or ekatyp.Syncer interface.
+//
+// Unlike Sync(), Flush() does not stop at the first error, but tries to flush
+// all writers and returns the first occurred error.
+// If 'ctx' is done before all writers are flushed, ctx.Err() is returned
+// (a writer that is in progress keeps flushing in background).
+//
+// Nil 'ctx' means context.Background().
+func (ci *CommonIntegrator) Flush(ctx context.Context) error {
+
+	ci.assertNil()
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	if !ci.isRegistered {
+		return nil
+	}
+
+	return ci.flush(ctx)
+}
+
+// Close flushes (see Flush()) and then closes all registered writers,
+// that implement CI_WriterCloser or io.Closer interface.
+// Each writer is closed once even if it's registered many times.
+//
+// After Close() is called, all next log entries are dropped,
+// so it's safe to close an integrator that is being replaced by another one.
+// The next calls of Close() are no-op.
+//
+// Returns the first occurred error. Honors 'ctx' the same way Flush() does.
+func (ci *CommonIntegrator) Close(ctx context.Context) error {
+
+	ci.assertNil()
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	if !atomic.CompareAndSwapUint32(&ci.isClosed, 0, 1) || !ci.isRegistered {
+		return nil
+	}
+
+	err := ci.flush(ctx)
+	if err2 := ci.close(ctx); err == nil {
+		err = err2
+	}
+
+	return err
+}
+
 // -------------------- CommonIntegrator BUILDING METHODS --------------------- //
 // ---------------------------------------------------------------------------- //
 
@@ -327,6 +568,7 @@ func (ci *CommonIntegrator) WithMinLevel(minLevel Level) *CommonIntegrator {
 	}
 
 	ci.output[ci.idx].minLevel = minLevel
+	ci.output[ci.idx].isMinLevelSet = true
 	return ci
 }
 
@@ -351,6 +593,188 @@ func (ci *CommonIntegrator) WithMinLevelForStackTrace(minLevel Level) *CommonInt
 	}
 
 	ci.output[ci.idx].stacktraceMinLevel = minLevel
+	ci.output[ci.idx].isStacktraceMinLevelSet = true
+	return ci
+}
+
+// WithMinLevelForCaller changes a minimum level log's Entry caller's PC being
+// captured for and saves it for next registered writers by WriteTo() method.
+//
+// Capturing only the caller's PC is much cheaper than generating a stacktrace,
+// because the PC is resolved to the caller's function, file and line only
+// when it's encoded (see Entry.Caller()).
+// So, it's the way to keep caller's info for the high-rate LEVEL_INFO logs
+// w/o paying for the stacktrace:
+//
+//	ig := new(CommonIntegrator).
+//	        WithEncoder(encoder).
+//	        WithMinLevel(LEVEL_DEBUG).
+//	        WithMinLevelForStackTrace(LEVEL_ERROR).
+//	        WithMinLevelForCaller(LEVEL_INFO).
+//	        WriteTo(os.Stdout)
+//
+// Here LEVEL_ERROR and more dangerous entries have a stacktrace,
+// LEVEL_WARNING, LEVEL_NOTICE and LEVEL_INFO ones have only a caller,
+// and LEVEL_DEBUG ones have neither a caller nor a stacktrace.
+//
+// If it's less than level, registered by WithMinLevelForStackTrace(),
+// it has no effect.
+func (ci *CommonIntegrator) WithMinLevelForCaller(minLevel Level) *CommonIntegrator {
+
+	ci.assertWithLock()
+	defer ci.mu.Unlock()
+
+	if len(ci.output) == 0 {
+		// only in that case ci.idx == 0,
+		// it was a direct call WithMinLevelForCaller(), even w/o WithEncoder() before.
+		ci.output = append(ci.output, _CI_Output{
+			encoder: defaultConsoleEncoder,
+		})
+	}
+
+	ci.output[ci.idx].callerMinLevel = minLevel
+	ci.output[ci.idx].isCallerMinLevelSet = true
+	return ci
+}
+
+// WithDeduplication enables suppressing of identical consecutive log entries.
+//
+// Entries are considered identical if they have the same Level, message
+// (both of log's and attached ekaerr.Error's) and fields (keys and values).
+// Only the first entry of such sequence is written. The others are dropped
+// until either a different entry is arrived or 'window' is elapsed since
+// the first entry of the sequence has been written.
+// Then a one summary entry "last message repeated N times" is written
+// (with the same Level as suppressed entries have and with "repeated" field).
+//
+// Because the summary entry is written only when the next entry arrives,
+// the pending summary is also written by Sync().
+//
+// Deduplication affects all outputs of CommonIntegrator.
+// Pass 'window' <= 0 to disable deduplication (it's disabled by default).
+func (ci *CommonIntegrator) WithDeduplication(window time.Duration) *CommonIntegrator {
+
+	ci.assertWithLock()
+	defer ci.mu.Unlock()
+
+	if window <= 0 {
+		ci.dd = nil
+	} else {
+		ci.dd = &_CI_Deduper{window: window}
+	}
+
+	return ci
+}
+
+// WithReentrancyGuard enables (or disables) the protection against the logging
+// from the io.Writer, while it's writing the Entry (e.g. io.Writer logs
+// its own failure through ekalog or ekaerr). W/o it such logging leads to deadlock
+// (if io.Writer is locked) or infinite recursion. With it, such Entry is written
+// to os.Stderr by the encoder of the first suitable output.
+//
+// It's disabled by default, because each write costs the lookup
+// of the current goroutine's ID then. It's not enabled anyway
+// if no one of io.Writer may log: os.File, bytes.Buffer, FileWriter,
+// SocketWriter, etc, even if they are wrapped by BufferedWriter, TimeoutWriter.
+func (ci *CommonIntegrator) WithReentrancyGuard(enable bool) *CommonIntegrator {
+
+	ci.assertWithLock()
+	defer ci.mu.Unlock()
+
+	ci.isReentrancyGuarded = enable
+	return ci
+}
+
+// WithClock sets the time source of Entry's timestamp for all Loggers
+// the CommonIntegrator is registered with, unless Logger has its own one
+// (read more: Logger.WithClock()). The summary entries of deduplication
+// (see WithDeduplication()) are stamped by it either.
+//
+// It's useful for deterministic tests, replay tools and simulated-clock
+// environments. Pass nil to restore the default one (time.Now).
+func (ci *CommonIntegrator) WithClock(clock func() time.Time) *CommonIntegrator {
+
+	ci.assertWithLock()
+	defer ci.mu.Unlock()
+
+	ci.clock = clock
+	return ci
+}
+
+// WithPostProcessors adds CI_PostProcessor to the pipeline of the writers,
+// registered by WriteTo() (or WriteToWithFallback()) along with the CI_Encoder,
+// that has been specified using last WithEncoder() call.
+// Encoded Entry is passed through them in order they are added.
+//
+// To use different post-processors with the same CI_Encoder,
+// call WithEncoder() again (Entry is still encoded once):
+//
+//	ig := new(CommonIntegrator).
+//	        WithEncoder(encoder).
+//	        WriteTo(os.Stdout).
+//	        WithEncoder(encoder).
+//	        WithPostProcessors(CI_PostProcessorPrefix("tenant42 "), CI_PostProcessorDropColors).
+//	        WriteTo(file)
+//
+// Nil post-processors are ignored.
+func (ci *CommonIntegrator) WithPostProcessors(pp ...CI_PostProcessor) *CommonIntegrator {
+
+	ci.assertWithLock()
+	defer ci.mu.Unlock()
+
+	if len(ci.output) == 0 {
+		// only in that case ci.idx == 0,
+		// it was a direct call WithPostProcessors(), even w/o WithEncoder() before.
+		ci.output = append(ci.output, _CI_Output{
+			encoder: defaultConsoleEncoder,
+		})
+	}
+
+	for _, p := range pp {
+		if p != nil {
+			ci.output[ci.idx].postProcessors = append(ci.output[ci.idx].postProcessors, p)
+		}
+	}
+
+	return ci
+}
+
+// WithStaticFields adds fields, that are attached to each Entry written
+// to the writers, registered by WriteTo() (or WriteToWithFallback()) along with
+// the CI_Encoder, that has been specified using last WithEncoder() call.
+// 'args' are parsed the same way as the fields of log finishers
+// (explicit fields or key-value pairs).
+//
+// It allows the same Entry to be tagged differently for each destination:
+//
+//	ig := new(CommonIntegrator).
+//	        WithEncoder(encoder).
+//	        WithStaticFields("sink", "file").
+//	        WriteTo(file).
+//	        WithEncoder(encoder).
+//	        WithStaticFields("sink", "datadog", "dd.source", "go").
+//	        WriteTo(datadogWriter)
+//
+// Entry is encoded separately for each of such writers.
+// Unlike pre-encoded fields (see CI_Encoder's PreEncodeField()),
+// they're not shared between all writers of the same CI_Encoder.
+func (ci *CommonIntegrator) WithStaticFields(args ...any) *CommonIntegrator {
+
+	ci.assertWithLock()
+	defer ci.mu.Unlock()
+
+	if len(ci.output) == 0 {
+		// only in that case ci.idx == 0,
+		// it was a direct call WithStaticFields(), even w/o WithEncoder() before.
+		ci.output = append(ci.output, _CI_Output{
+			encoder: defaultConsoleEncoder,
+		})
+	}
+
+	var l ekaletter.Letter
+	ekaletter.LParseTo(&l, args, true)
+	ci.output[ci.idx].fields = append(ci.output[ci.idx].fields, l.Fields...)
+
 	return ci
 }
 
@@ -389,3 +813,61 @@ func (ci *CommonIntegrator) WriteTo(writers ...io.Writer) *CommonIntegrator {
 	ci.output[ci.idx].writers = append(ci.output[ci.idx].writers, writers...)
 	return ci
 }
+
+// WriteToWithFallback registers 'primary' io.Writer as CommonIntegrator
+// destination (like WriteTo() does) with the chain of 'fallbacks' io.Writer
+// for the CI_Encoder that has been specified using last WithEncoder() call
+// before this WriteToWithFallback() call.
+//
+// If writing to 'primary' fails, the same encoded Entry is written
+// to the first fallback, if it fails too, to the second one, and so on.
+// E.g. primary TCP syslog -> local file -> os.Stderr:
+//
+//	ig := new(CommonIntegrator).
+//	        WithEncoder(encoder).
+//	        WriteToWithFallback(syslogConn, file, os.Stderr)
+//
+// Each Entry is tried to be written to 'primary' first, so the chain
+// recovers as soon as 'primary' does.
+// When the writer the Entry is written to is changed (either failover
+// or recovery), a self-diagnostic Entry of LEVEL_WARNING is written
+// to the new one right after the Entry itself, see
+// CI_FALLBACK_FIELD_ERROR and CI_FALLBACK_FIELD_WRITER_IDX.
+//
+// Fallback writers are flushed and closed along with 'primary'.
+// If 'primary' is nil, it's no-op. Nil fallbacks are ignored.
+func (ci *CommonIntegrator) WriteToWithFallback(primary io.Writer, fallbacks ...io.Writer) *CommonIntegrator {
+
+	if primary == nil {
+		return ci
+	}
+
+	chain := make([]io.Writer, 1, len(fallbacks)+1)
+	chain[0] = primary
+	for _, fallback := range fallbacks {
+		if fallback != nil {
+			chain = append(chain, fallback)
+		}
+	}
+
+	if len(chain) == 1 {
+		return ci.WriteTo(primary)
+	}
+
+	ci.assertWithLock()
+	defer ci.mu.Unlock()
+
+	if len(ci.output) == 0 {
+		// only in that case ci.idx == 0,
+		// it was a direct call WriteToWithFallback(), even w/o WithEncoder() before.
+		ci.output = append(ci.output, _CI_Output{
+			encoder: defaultConsoleEncoder,
+		})
+	}
+
+	ci.output[ci.idx].writers = append(ci.output[ci.idx].writers, &_CI_Fallback{
+		encoder: ci.output[ci.idx].encoder,
+		writers: chain,
+	})
+	return ci
+}
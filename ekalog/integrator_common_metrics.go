@@ -0,0 +1,315 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// _CI_Metrics is a set of metrics, derived from the log entries
+	// by CommonIntegrator. Read more: CommonIntegrator.CountEntries(),
+	// CommonIntegrator.ObserveDurationField().
+	//
+	// The set of metrics is never changed after CommonIntegrator
+	// is registered, so only their values are synchronized.
+	_CI_Metrics struct {
+		counters   []*_CI_Counter
+		histograms []*_CI_Histogram
+	}
+
+	// _CI_Counter is a counter of the matched log entries.
+	_CI_Counter struct {
+		value uint64 // atomic access only, first for 64-bit alignment
+		name  string
+		match func(*Entry) bool // nil means all entries are matched
+	}
+
+	// _CI_Histogram is a histogram of the durations,
+	// stored as the log entries' field.
+	_CI_Histogram struct {
+		name     string
+		fieldKey string
+		bounds   []float64 // in seconds, ascending
+
+		mu     sync.Mutex
+		counts []uint64 // len(bounds)+1, the last one is +Inf; not cumulative
+		sum    float64  // in seconds
+		count  uint64
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// _CI_METRICS_CONTENT_TYPE is a Content-Type of Prometheus text format.
+	_CI_METRICS_CONTENT_TYPE = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var (
+	// CI_MetricsDefaultBuckets are the default upper bounds of the histogram's
+	// buckets, used by CommonIntegrator.ObserveDurationField()
+	// (the same as Prometheus client's default ones).
+	//
+	//goland:noinspection GoSnakeCaseUsage
+	CI_MetricsDefaultBuckets = []time.Duration{
+		5 * time.Millisecond, 10 * time.Millisecond, 25 * time.Millisecond,
+		50 * time.Millisecond, 100 * time.Millisecond, 250 * time.Millisecond,
+		500 * time.Millisecond, time.Second, 2500 * time.Millisecond,
+		5 * time.Second, 10 * time.Second,
+	}
+)
+
+// CountEntries declares a counter 'name' of the log entries, the CommonIntegrator
+// has received and 'match' reports true for (all of them if 'match' is nil).
+// It's a cheap way to get RED metrics (rate, errors) without separate
+// instrumentation:
+//
+//	ci := new(ekalog.CommonIntegrator).
+//	    WithEncoder(encoder).WriteTo(os.Stdout).
+//	    CountEntries("app_log_entries_total", nil).
+//	    CountEntries("app_log_errors_total", func(e *ekalog.Entry) bool {
+//	        return e.Level <= ekalog.LEVEL_ERROR
+//	    })
+//
+// Only the entries of the enabled levels (see WithMinLevel()) reach
+// CommonIntegrator. They are counted even if they are suppressed
+// by deduplication (see WithDeduplication()).
+// 'match' MUST NOT change Entry and MUST be thread-safe.
+//
+// The metrics are exported by WriteMetrics() or MetricsHandler().
+// 'name' should be a valid Prometheus metric name. Empty 'name' is ignored.
+func (ci *CommonIntegrator) CountEntries(name string, match func(e *Entry) bool) *CommonIntegrator {
+
+	ci.assertWithLock()
+	defer ci.mu.Unlock()
+
+	if name == "" {
+		return ci
+	}
+
+	if ci.metrics == nil {
+		ci.metrics = new(_CI_Metrics)
+	}
+
+	ci.metrics.counters = append(ci.metrics.counters, &_CI_Counter{
+		name:  name,
+		match: match,
+	})
+
+	return ci
+}
+
+// ObserveDurationField declares a histogram 'name' of the durations,
+// stored as the field 'fieldKey' of the log entries, the CommonIntegrator
+// has received. E.g. the field of Logger.StartStopwatch()
+// or the latency field of the HTTP access log:
+//
+//	ci := new(ekalog.CommonIntegrator).
+//	    WithEncoder(encoder).WriteTo(os.Stdout).
+//	    ObserveDurationField("http_request_duration_seconds", "latency")
+//
+// Only the fields of time.Duration type (ekaletter.KIND_TYPE_DURATION)
+// are observed. The entries w/o such field are ignored.
+// The durations are exported in seconds.
+//
+// 'buckets' are the upper bounds of the histogram's buckets.
+// They are sorted, the non-positive ones are ignored.
+// If there are no buckets, CI_MetricsDefaultBuckets are used.
+//
+// The metrics are exported by WriteMetrics() or MetricsHandler().
+// 'name' should be a valid Prometheus metric name.
+// Empty 'name' or 'fieldKey' is ignored.
+func (ci *CommonIntegrator) ObserveDurationField(
+	name, fieldKey string, buckets ...time.Duration) *CommonIntegrator {
+
+	ci.assertWithLock()
+	defer ci.mu.Unlock()
+
+	if name == "" || fieldKey == "" {
+		return ci
+	}
+
+	if ci.metrics == nil {
+		ci.metrics = new(_CI_Metrics)
+	}
+
+	bounds := ciMetricsBounds(buckets)
+	ci.metrics.histograms = append(ci.metrics.histograms, &_CI_Histogram{
+		name:     name,
+		fieldKey: fieldKey,
+		bounds:   bounds,
+		counts:   make([]uint64, len(bounds)+1),
+	})
+
+	return ci
+}
+
+// WriteMetrics writes all metrics, declared by CountEntries()
+// and ObserveDurationField(), to 'w' using Prometheus text exposition format.
+// They are written in the order they have been declared.
+// Writes nothing if there are no declared metrics.
+func (ci *CommonIntegrator) WriteMetrics(w io.Writer) error {
+
+	ci.assertNil()
+
+	if ci.metrics == nil {
+		return nil
+	}
+
+	var b bytes.Buffer
+	ci.metrics.encode(&b)
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// MetricsHandler returns an http.Handler, that responds by the metrics
+// (read more: WriteMetrics()). Register it as a metrics endpoint,
+// the Prometheus scrapes:
+//
+//	http.Handle("/metrics", ci.MetricsHandler())
+func (ci *CommonIntegrator) MetricsHandler() http.Handler {
+
+	ci.assertNil()
+
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", _CI_METRICS_CONTENT_TYPE)
+		_ = ci.WriteMetrics(w)
+	})
+}
+
+// ---------------------------------------------------------------------------- //
+
+// observe updates all metrics by 'e'.
+func (m *_CI_Metrics) observe(e *Entry) {
+
+	for _, c := range m.counters {
+		if c.match == nil || c.match(e) {
+			atomic.AddUint64(&c.value, 1)
+		}
+	}
+
+	for _, h := range m.histograms {
+		if d, ok := ciMetricsDurationField(e, h.fieldKey); ok {
+			h.observe(d.Seconds())
+		}
+	}
+}
+
+// encode writes all metrics to 'b' using Prometheus text exposition format.
+func (m *_CI_Metrics) encode(b *bytes.Buffer) {
+
+	for _, c := range m.counters {
+		b.WriteString("# TYPE " + c.name + " counter\n")
+		b.WriteString(c.name + " ")
+		b.WriteString(strconv.FormatUint(atomic.LoadUint64(&c.value), 10))
+		b.WriteByte('\n')
+	}
+
+	for _, h := range m.histograms {
+		h.encode(b)
+	}
+}
+
+// observe adds 'v' (in seconds) to the histogram.
+func (h *_CI_Histogram) observe(v float64) {
+
+	i := 0
+	for i < len(h.bounds) && v > h.bounds[i] {
+		i++
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.counts[i]++
+	h.sum += v
+	h.count++
+}
+
+// encode writes the histogram to 'b' using Prometheus text exposition format.
+func (h *_CI_Histogram) encode(b *bytes.Buffer) {
+
+	h.mu.Lock()
+	counts := append([]uint64(nil), h.counts...)
+	sum, count := h.sum, h.count
+	h.mu.Unlock()
+
+	b.WriteString("# TYPE " + h.name + " histogram\n")
+
+	cumulative := uint64(0)
+	for i, n := range counts {
+		cumulative += n
+		le := "+Inf"
+		if i < len(h.bounds) {
+			le = strconv.FormatFloat(h.bounds[i], 'g', -1, 64)
+		}
+		b.WriteString(h.name + "_bucket{le=\"" + le + "\"} ")
+		b.WriteString(strconv.FormatUint(cumulative, 10))
+		b.WriteByte('\n')
+	}
+
+	b.WriteString(h.name + "_sum ")
+	b.WriteString(strconv.FormatFloat(sum, 'g', -1, 64))
+	b.WriteString("\n" + h.name + "_count ")
+	b.WriteString(strconv.FormatUint(count, 10))
+	b.WriteByte('\n')
+}
+
+// ciMetricsBounds returns sorted unique positive 'buckets' in seconds
+// or CI_MetricsDefaultBuckets if there are no such ones.
+func ciMetricsBounds(buckets []time.Duration) []float64 {
+
+	bounds := make([]float64, 0, len(buckets))
+	for _, d := range buckets {
+		if d > 0 {
+			bounds = append(bounds, d.Seconds())
+		}
+	}
+
+	if len(bounds) == 0 {
+		return ciMetricsBounds(CI_MetricsDefaultBuckets)
+	}
+
+	sort.Float64s(bounds)
+
+	n := 1
+	for i := 1; i < len(bounds); i++ {
+		if bounds[i] != bounds[n-1] {
+			bounds[n] = bounds[i]
+			n++
+		}
+	}
+
+	return bounds[:n]
+}
+
+// ciMetricsDurationField returns the value of Entry's field 'key'
+// of time.Duration type. Returns false if there's no such field.
+func ciMetricsDurationField(e *Entry, key string) (time.Duration, bool) {
+
+	if e.LogLetter == nil {
+		return 0, false
+	}
+
+	for i, n := 0, len(e.LogLetter.Fields); i < n; i++ {
+		f := &e.LogLetter.Fields[i]
+		if f.Key == key && f.BaseType() == ekaletter.KIND_TYPE_DURATION && !f.IsNil() {
+			return time.Duration(f.IValue), true
+		}
+	}
+
+	return 0, false
+}
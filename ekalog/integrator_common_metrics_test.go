@@ -0,0 +1,61 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommonIntegrator_Metrics(t *testing.T) {
+
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m}};")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(io.Discard).
+		CountEntries("log_entries_total", nil).
+		CountEntries("log_errors_total", func(e *ekalog.Entry) bool {
+			return e.Level <= ekalog.LEVEL_ERROR
+		}).
+		ObserveDurationField("request_duration_seconds", "latency",
+			time.Second, 100*time.Millisecond, 0, time.Second)
+
+	ekalog.ReplaceIntegrator(ci)
+
+	ekalog.Infow("request", ekaletter.FDuration("latency", 50*time.Millisecond))
+	ekalog.Infow("request", ekaletter.FDuration("latency", 500*time.Millisecond))
+	ekalog.Errorw("request", ekaletter.FDuration("latency", 2*time.Second))
+	ekalog.Info("no latency", "latency", "string")
+
+	const expected = "" +
+		"# TYPE log_entries_total counter\n" +
+		"log_entries_total 4\n" +
+		"# TYPE log_errors_total counter\n" +
+		"log_errors_total 1\n" +
+		"# TYPE request_duration_seconds histogram\n" +
+		"request_duration_seconds_bucket{le=\"0.1\"} 1\n" +
+		"request_duration_seconds_bucket{le=\"1\"} 2\n" +
+		"request_duration_seconds_bucket{le=\"+Inf\"} 3\n" +
+		"request_duration_seconds_sum 2.55\n" +
+		"request_duration_seconds_count 3\n"
+
+	var b bytes.Buffer
+	assert.NoError(t, ci.WriteMetrics(&b))
+	assert.Equal(t, expected, b.String())
+
+	rec := httptest.NewRecorder()
+	ci.MetricsHandler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	assert.Equal(t, expected, rec.Body.String())
+	assert.Contains(t, rec.Header().Get("Content-Type"), "text/plain")
+}
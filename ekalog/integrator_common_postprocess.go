@@ -0,0 +1,65 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"encoding/binary"
+	"strconv"
+)
+
+// CI_PostProcessorPrefix returns a CI_PostProcessor, that prepends 'prefix'
+// to the each encoded Entry (e.g. tenant or host tag).
+//
+//goland:noinspection GoSnakeCaseUsage
+func CI_PostProcessorPrefix(prefix string) CI_PostProcessor {
+	return func(encoded []byte) []byte {
+		to := make([]byte, 0, len(prefix)+len(encoded))
+		return append(append(to, prefix...), encoded...)
+	}
+}
+
+// CI_PostProcessorLengthPrefix is a CI_PostProcessor, that prepends
+// the length of encoded Entry as 4 bytes big endian uint32
+// (length-prefix framing for stream protocols, like TCP).
+//
+//goland:noinspection GoSnakeCaseUsage
+func CI_PostProcessorLengthPrefix(encoded []byte) []byte {
+	to := make([]byte, 4, 4+len(encoded))
+	binary.BigEndian.PutUint32(to, uint32(len(encoded)))
+	return append(to, encoded...)
+}
+
+// CI_PostProcessorOctetCounting is a CI_PostProcessor, that prepends
+// the length of encoded Entry as decimal number followed by space
+// (octet counting framing of syslog over TCP, RFC 6587).
+//
+//goland:noinspection GoSnakeCaseUsage
+func CI_PostProcessorOctetCounting(encoded []byte) []byte {
+	to := make([]byte, 0, len(encoded)+8)
+	to = strconv.AppendInt(to, int64(len(encoded)), 10)
+	return append(append(to, ' '), encoded...)
+}
+
+// CI_PostProcessorDropColors is a CI_PostProcessor, that drops TTY color
+// sequences from the encoded Entry. Read more: CICE_DropColors().
+//
+//goland:noinspection GoSnakeCaseUsage
+func CI_PostProcessorDropColors(encoded []byte) []byte {
+
+	to := make([]byte, 0, len(encoded))
+	for i, n, write := 0, len(encoded), true; i < n; i++ {
+		if write && encoded[i] == '\033' {
+			write = false
+			i += 2
+		} else if !write && encoded[i] == 'm' {
+			write = true
+		} else if write {
+			to = append(to, encoded[i])
+		}
+	}
+
+	return to
+}
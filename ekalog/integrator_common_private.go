@@ -6,11 +6,19 @@
 package ekalog
 
 import (
+	"context"
+	"fmt"
 	"io"
+	"net"
 	"os"
+	"sync"
+	"time"
 	"unsafe"
 
+	"github.com/qioalice/ekago/v3/ekatyp"
+	"github.com/qioalice/ekago/v3/ekatyp/ekahash"
 	"github.com/qioalice/ekago/v3/internal/ekaclike"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
 	"github.com/qioalice/ekago/v3/internal/ekasys"
 )
 
@@ -21,12 +29,64 @@ type (
 	//
 	// It used at the CommonIntegrator building procedure.
 	_CI_Output struct {
-		minLevel           Level       // minimum level log entry should have to be processed
-		stacktraceMinLevel Level       // minimum level starting with stacktrace must be added to the entry
-		encoder            CI_Encoder  // func that encoders Entry object to []byte
-		writers            []io.Writer // slice of io.Writer, log entry will be written to
-		preEncodedFields   []byte      // raw data of pre-encoded fields
+		minLevel                Level       // minimum level log entry should have to be processed
+		isMinLevelSet           bool        // minLevel is set explicitly by WithMinLevel()
+		stacktraceMinLevel      Level       // minimum level starting with stacktrace must be added to the entry
+		isStacktraceMinLevelSet bool        // stacktraceMinLevel is set explicitly by WithMinLevelForStackTrace()
+		callerMinLevel          Level       // minimum level starting with caller must be added to the entry
+		isCallerMinLevelSet     bool        // callerMinLevel is set explicitly by WithMinLevelForCaller()
+		encoder                 CI_Encoder  // func that encoders Entry object to []byte
+		writers                 []io.Writer // slice of io.Writer, log entry will be written to
+		preEncodedFields        []byte      // raw data of pre-encoded fields
+
+		// fields that are attached to each Entry written to writers
+		fields []ekaletter.LetterField
+
+		// transforms of encoded entry before it's written to writers
+		postProcessors []CI_PostProcessor
+
+		// isRemote[i] reports whether writers[i] is remote (see CI_WriterRemote),
+		// isAllRemote is true if all writers are remote. Built by build().
+		isRemote    []bool
+		isAllRemote bool
+	}
+
+	// _CI_Fallback is a CommonIntegrator part, that is a chain of io.Writer,
+	// the encoded Entry is written to the first of them, that succeeds.
+	//
+	// It's registered by CommonIntegrator.WriteToWithFallback().
+	_CI_Fallback struct {
+		mu             sync.Mutex
+		encoder        CI_Encoder         // encoder of self-diagnostic entries
+		postProcessors []CI_PostProcessor // post-processors of self-diagnostic entries
+		clock          func() time.Time   // time source of self-diagnostic entries
+		writers        []io.Writer        // primary writer followed by fallback ones
+		active         int                // index of the writer the last Entry was written to
 	}
+
+	// _CI_Deduper is a CommonIntegrator part that detects identical consecutive
+	// log entries and counts how much of them were suppressed.
+	//
+	// It's enabled by CommonIntegrator.WithDeduplication().
+	_CI_Deduper struct {
+		mu        sync.Mutex
+		window    time.Duration  // max duration of one sequence of suppressed entries
+		hasher    ekahash.Digest // reusable hasher, protected by mu
+		lastHash  uint64         // hash of the last written Entry
+		lastLevel Level          // level of the last written Entry
+		lastTime  time.Time      // time of the last written Entry
+		repeated  uint64         // how much entries are suppressed since last written
+	}
+)
+
+const (
+	// _CI_DEDUP_MESSAGE_FORMAT is a format of the message of an Entry
+	// that is written instead of suppressed duplicates.
+	_CI_DEDUP_MESSAGE_FORMAT = "last message repeated %d times"
+
+	// _CI_DEDUP_FIELD_KEY is a key of the field an amount of suppressed
+	// duplicates is stored by.
+	_CI_DEDUP_FIELD_KEY = "repeated"
 )
 
 // assertNil panics if current CommonIntegrator is nil.
@@ -98,17 +158,469 @@ func (ci *CommonIntegrator) build() {
 		}
 	}
 
+	// Output w/o explicitly set min level inherits it from the previous one.
+	// The first one gets LEVEL_WARNING then.
+
+	for i := range ci.output {
+		switch {
+		case ci.output[i].isMinLevelSet:
+		case i == 0:
+			ci.output[i].minLevel = LEVEL_WARNING
+		default:
+			ci.output[i].minLevel = ci.output[i-1].minLevel
+		}
+	}
+
+	// Output w/o explicitly set stacktrace min level gets LEVEL_WARNING
+	// (the same as the default of MinLevelForStackTrace()),
+	// and w/o caller min level gets the stacktrace one.
+	// Zero Level is LEVEL_EMERGENCY, so it can't be used as "not set".
+
+	for i := range ci.output {
+		if !ci.output[i].isStacktraceMinLevelSet {
+			ci.output[i].stacktraceMinLevel = LEVEL_WARNING
+		}
+		if !ci.output[i].isCallerMinLevelSet {
+			ci.output[i].callerMinLevel = ci.output[i].stacktraceMinLevel
+		}
+	}
+
 	ci.oll = LEVEL_WARNING
 	ci.stll = LEVEL_WARNING
 
 	for _, output := range ci.output {
-		if output.minLevel > ci.oll {
+		if !output.minLevel.IsEnabledFor(ci.oll) {
 			ci.oll = output.minLevel
 		}
-		if output.stacktraceMinLevel > ci.stll {
+		if !output.stacktraceMinLevel.IsEnabledFor(ci.stll) {
 			ci.stll = output.stacktraceMinLevel
 		}
 	}
 
+	// Fallback chains write self-diagnostic entries on their own,
+	// so they need post-processors of their outputs and the clock.
+
+	for _, output := range ci.output {
+		for _, destination := range output.writers {
+			if fallback, ok := destination.(*_CI_Fallback); ok {
+				fallback.postProcessors = output.postProcessors
+				fallback.clock = ci.Clock()
+			}
+		}
+	}
+
+	// Writers that may log through ekalog (e.g. report their own failures)
+	// require reentrancy guard to avoid deadlocks and infinite recursion.
+	// Read more: WithReentrancyGuard().
+
+	for i := 0; i < len(ci.output) && ci.isReentrancyGuarded && ci.rg == nil; i++ {
+		for j := 0; j < len(ci.output[i].writers) && ci.rg == nil; j++ {
+			if ciWriterMayLog(ci.output[i].writers[j]) {
+				ci.rg = &_CI_ReentrancyGuard{
+					active:   make(map[uint64]struct{}),
+					fallback: os.Stderr,
+				}
+			}
+		}
+	}
+
+	// Entry that must not leave the host (see Logger.Local())
+	// are not written to the remote writers.
+
+	for i := range ci.output {
+		output := &ci.output[i]
+		output.isRemote = make([]bool, len(output.writers))
+		output.isAllRemote = true
+		for j, destination := range output.writers {
+			output.isRemote[j] = ciWriterIsRemote(destination)
+			output.isAllRemote = output.isAllRemote && output.isRemote[j]
+		}
+	}
+
+	ci.cll = ci.stll
+
+	for _, output := range ci.output {
+		if !output.callerMinLevel.IsEnabledFor(ci.cll) {
+			ci.cll = output.callerMinLevel
+		}
+	}
+
 	ci.isRegistered = true
 }
+
+// encodeAndWrite encodes Entry using registered CI_Encoder objects and then writes
+// obtained RAW data ([]byte) to correspondent io.Writer objects,
+// the Entry of 'lvl' must be written to (usually it's Entry's Level).
+// It's a part of EncodeAndWrite() w/o deduplication.
+func (ci *CommonIntegrator) encodeAndWrite(entry *Entry, lvl Level) {
+
+	// it guarantees that ci.output is not empty,
+	// because each CommonIntegrator object is checked by tryToBuild().
+
+	var (
+		// The same encoder may be registered for many outputs
+		// (e.g. with different min levels). There's no need to encode Entry again
+		// if the previous output has the same encoder, stacktrace and caller state.
+		lastEncoder           unsafe.Pointer
+		lastStacktraceDropped bool
+		lastCallerDropped     bool
+		lastHadFields         bool
+		encodedEntry          []byte
+	)
+
+	isLocal := entry.IsLocal()
+
+	for _, output := range ci.output {
+
+		if !lvl.IsEnabledFor(output.minLevel) || isLocal && output.isAllRemote {
+			continue
+		}
+
+		// maybe we must remove stacktrace or caller?
+		stacktraceDropped := !entry.Level.IsEnabledFor(output.stacktraceMinLevel)
+		callerDropped := !entry.Level.IsEnabledFor(output.callerMinLevel)
+
+		encoderAddr := ekaclike.TakeRealAddr(output.encoder)
+		hasFields := len(output.fields) > 0
+
+		if encoderAddr != lastEncoder || hasFields || lastHadFields ||
+			stacktraceDropped != lastStacktraceDropped || callerDropped != lastCallerDropped {
+
+			logStacktraceBak := entry.LogLetter.StackTrace
+			if stacktraceDropped {
+				entry.LogLetter.StackTrace = nil
+			}
+			callerPCBak := entry.callerPCs[0]
+			if callerDropped {
+				entry.callerPCs[0] = 0
+			}
+
+			// Fields may be the user's slice (explicit fields of a finisher),
+			// so its memory must not be overwritten.
+			fieldsBak := entry.LogLetter.Fields
+			if hasFields {
+				entry.LogLetter.Fields =
+					append(fieldsBak[:len(fieldsBak):len(fieldsBak)], output.fields...)
+			}
+
+			encodedEntry = output.encoder.EncodeEntry(entry)
+
+			// restore stacktrace, caller and fields
+			entry.LogLetter.StackTrace = logStacktraceBak
+			entry.callerPCs[0] = callerPCBak
+			entry.LogLetter.Fields = fieldsBak
+
+			lastEncoder = encoderAddr
+			lastHadFields = hasFields
+			lastStacktraceDropped = stacktraceDropped
+			lastCallerDropped = callerDropped
+		}
+
+		toWrite := ciPostProcess(encodedEntry, output.postProcessors)
+		for i, destination := range output.writers {
+			if !isLocal || !output.isRemote[i] {
+				_, _ = destination.Write(toWrite)
+			}
+		}
+	}
+}
+
+// flush is Flush() implementation. Expects CommonIntegrator is locked.
+func (ci *CommonIntegrator) flush(ctx context.Context) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	// There could be suppressed duplicates that are not reported yet.
+	if ci.dd != nil {
+		if repeated, repeatedLevel := ci.dd.flush(); repeated > 0 {
+			ci.encodeAndWriteRepeated(repeated, repeatedLevel)
+		}
+	}
+
+	var err error
+	for _, output := range ci.output {
+		for _, destination := range output.allWriters() {
+
+			var errFlush error
+			switch typedDestination := destination.(type) {
+			case CI_WriterFlusher:
+				errFlush = typedDestination.Flush(ctx)
+			case ekatyp.Syncer:
+				errFlush = ciCallWithContext(ctx, typedDestination.Sync)
+			}
+
+			if err == nil {
+				err = errFlush
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+	}
+
+	return err
+}
+
+// close closes all writers once. Expects CommonIntegrator is locked.
+func (ci *CommonIntegrator) close(ctx context.Context) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var (
+		err    error
+		closed = make(map[unsafe.Pointer]struct{})
+	)
+
+	for _, output := range ci.output {
+		for _, destination := range output.allWriters() {
+
+			addr := ekaclike.TakeRealAddr(destination)
+			if _, wasClosed := closed[addr]; wasClosed {
+				continue
+			}
+			closed[addr] = struct{}{}
+
+			var errClose error
+			switch typedDestination := destination.(type) {
+			case CI_WriterCloser:
+				errClose = typedDestination.Close(ctx)
+			case io.Closer:
+				errClose = ciCallWithContext(ctx, typedDestination.Close)
+			}
+
+			if err == nil {
+				err = errClose
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+	}
+
+	return err
+}
+
+// allWriters returns all writers of _CI_Output including writers
+// of fallback chains.
+func (o *_CI_Output) allWriters() []io.Writer {
+
+	hasFallback := false
+	for _, destination := range o.writers {
+		if _, hasFallback = destination.(*_CI_Fallback); hasFallback {
+			break
+		}
+	}
+
+	if !hasFallback {
+		return o.writers
+	}
+
+	writers := make([]io.Writer, 0, len(o.writers)*2)
+	for _, destination := range o.writers {
+		if fallback, ok := destination.(*_CI_Fallback); ok {
+			writers = append(writers, fallback.writers...)
+		} else {
+			writers = append(writers, destination)
+		}
+	}
+
+	return writers
+}
+
+// Write writes 'p' to the first writer of the chain, that succeeds.
+// If it's not the writer the previous 'p' has been written to,
+// the self-diagnostic Entry is written after 'p' to the same writer.
+// Returns the error of the last writer if all of them have failed.
+// Implements io.Writer.
+func (fb *_CI_Fallback) Write(p []byte) (int, error) {
+
+	fb.mu.Lock()
+	defer fb.mu.Unlock()
+
+	var (
+		n        int
+		err      error
+		errFirst error
+	)
+
+	for i, w := range fb.writers {
+		if n, err = w.Write(p); err == nil {
+			if i != fb.active {
+				fb.writeDiagnostic(w, i, errFirst)
+				fb.active = i
+			}
+			return n, nil
+		}
+		if errFirst == nil {
+			errFirst = err
+		}
+	}
+
+	return n, err
+}
+
+// writeDiagnostic writes the self-diagnostic Entry to 'w' reporting
+// the writer with 'idx' is used now because of 'err' (nil if it's recovery).
+// Requires fb.mu to be locked.
+func (fb *_CI_Fallback) writeDiagnostic(w io.Writer, idx int, err error) {
+
+	e := acquireEntry()
+
+	e.Level = LEVEL_WARNING
+	e.Time = fb.clock()
+
+	msg := CI_FALLBACK_MESSAGE_RECOVERY
+	if idx > fb.active {
+		msg = CI_FALLBACK_MESSAGE_FAILOVER
+	}
+
+	ekaletter.LSetMessage(e.LogLetter, msg, false)
+	ekaletter.LAddField(e.LogLetter, ekaletter.FInt(CI_FALLBACK_FIELD_WRITER_IDX, idx))
+	if err != nil {
+		ekaletter.LAddField(e.LogLetter, ekaletter.FString(CI_FALLBACK_FIELD_ERROR, err.Error()))
+	}
+
+	_, _ = w.Write(ciPostProcess(fb.encoder.EncodeEntry(e), fb.postProcessors))
+	releaseEntry(e)
+}
+
+// ciWriterIsRemote reports whether 'w' sends the data out of the host.
+// Read more: CI_WriterRemote.
+func ciWriterIsRemote(w io.Writer) bool {
+
+	switch typed := w.(type) {
+
+	case CI_WriterRemote:
+		return typed.IsRemote()
+
+	case net.Conn:
+		return true
+
+	case *BufferedWriter:
+		return ciWriterIsRemote(typed.w)
+
+	case *TimeoutWriter:
+		return ciWriterIsRemote(typed.w) ||
+			typed.fallback != nil && ciWriterIsRemote(typed.fallback)
+
+	case *_CI_Fallback:
+		for _, fallbackWriter := range typed.writers {
+			if ciWriterIsRemote(fallbackWriter) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// ciPostProcess passes 'encoded' through all 'pp' and returns the result.
+func ciPostProcess(encoded []byte, pp []CI_PostProcessor) []byte {
+	for i, n := 0, len(pp); i < n && len(encoded) > 0; i++ {
+		encoded = pp[i](encoded)
+	}
+	return encoded
+}
+
+// ciCallWithContext calls 'cb' and waits until it's done or 'ctx' is done.
+// If 'ctx' cannot be done, 'cb' is called in the current goroutine.
+func ciCallWithContext(ctx context.Context, cb func() error) error {
+
+	if ctx.Done() == nil {
+		return cb()
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- cb() }()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// encodeAndWriteRepeated generates a summary Entry with provided Level,
+// that reports how much duplicates were suppressed, and writes it.
+func (ci *CommonIntegrator) encodeAndWriteRepeated(repeated uint64, lvl Level) {
+
+	e := acquireEntry()
+
+	e.Level = lvl
+	e.Time = ci.Clock()()
+
+	ekaletter.LSetMessage(e.LogLetter, fmt.Sprintf(_CI_DEDUP_MESSAGE_FORMAT, repeated), false)
+	ekaletter.LAddField(e.LogLetter, ekaletter.FUint64(_CI_DEDUP_FIELD_KEY, repeated))
+
+	ci.encodeAndWrite(e, e.Level)
+	releaseEntry(e)
+}
+
+// check reports whether provided Entry is a duplicate of the last written one
+// and must be suppressed. If it's not, but there were suppressed duplicates before,
+// their count and Level are returned, and the summary of them must be written
+// before the provided Entry.
+func (dd *_CI_Deduper) check(e *Entry) (suppress bool, repeated uint64, lvl Level) {
+
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+
+	h := dd.hash(e)
+
+	if h == dd.lastHash && e.Level == dd.lastLevel && e.Time.Sub(dd.lastTime) < dd.window {
+		dd.repeated++
+		return true, 0, 0
+	}
+
+	repeated, lvl = dd.repeated, dd.lastLevel
+
+	dd.lastHash = h
+	dd.lastLevel = e.Level
+	dd.lastTime = e.Time
+	dd.repeated = 0
+
+	return false, repeated, lvl
+}
+
+// flush returns a number of suppressed entries (and their Level) that are not
+// reported yet, resetting that counter.
+func (dd *_CI_Deduper) flush() (repeated uint64, lvl Level) {
+
+	dd.mu.Lock()
+	defer dd.mu.Unlock()
+
+	repeated, lvl = dd.repeated, dd.lastLevel
+	dd.repeated = 0
+
+	return repeated, lvl
+}
+
+// hash returns a hash of Entry's level, messages and fields (excluding system ones)
+// of both of log's and attached error's ekaletter.Letter.
+// Requires dd.mu to be locked.
+func (dd *_CI_Deduper) hash(e *Entry) uint64 {
+
+	dd.hasher.Reset()
+	dd.hasher.WriteUint64(uint64(e.Level))
+
+	hashLetter := func(l *ekaletter.Letter) {
+		if l == nil {
+			return
+		}
+		for i, n := 0, len(l.Messages); i < n; i++ {
+			_, _ = dd.hasher.WriteString(l.Messages[i].Body)
+			_, _ = dd.hasher.Write([]byte{0})
+		}
+		dd.hasher.WriteFields(l.Fields)
+	}
+
+	hashLetter(e.LogLetter)
+	hashLetter(e.ErrLetter)
+
+	return dd.hasher.Sum64()
+}
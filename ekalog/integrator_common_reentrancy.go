@@ -0,0 +1,116 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/qioalice/ekago/v3/ekasys"
+	"github.com/qioalice/ekago/v3/internal/ekaclike"
+)
+
+//goland:noinspection GoSnakeCaseUsage
+type (
+	// _CI_ReentrancyGuard is a CommonIntegrator part that detects the logging
+	// from the goroutine, that is already inside of CommonIntegrator
+	// (e.g. io.Writer logs its own failure through ekalog or ekaerr),
+	// and routes such Entry to the fallback io.Writer.
+	// W/o it such logging leads to deadlock (if io.Writer is locked)
+	// or infinite recursion.
+	//
+	// It's enabled by CommonIntegrator.WithReentrancyGuard() if at least one
+	// of its io.Writer may log (read more: ciWriterMayLog()).
+	_CI_ReentrancyGuard struct {
+		mu       sync.Mutex
+		active   map[uint64]struct{} // IDs of goroutines, that are inside of CommonIntegrator
+		fallback io.Writer           // reentrant Entry are written to
+	}
+)
+
+// enter marks the current goroutine as the one, that is inside of CommonIntegrator,
+// and returns its ID, or reports it's already inside (reentrant logging).
+// The returned ID must be passed to leave() if it's not reentrant logging.
+// If goroutine's ID can't be got, the logging is never considered reentrant.
+func (rg *_CI_ReentrancyGuard) enter() (gid uint64, reentrant bool) {
+
+	if gid = ekasys.GoroutineID(); gid == 0 {
+		return 0, false
+	}
+
+	rg.mu.Lock()
+	defer rg.mu.Unlock()
+
+	if _, reentrant = rg.active[gid]; !reentrant {
+		rg.active[gid] = struct{}{}
+	}
+
+	return gid, reentrant
+}
+
+// leave marks the goroutine with 'gid' as the one,
+// that has left the CommonIntegrator. Read more: enter().
+func (rg *_CI_ReentrancyGuard) leave(gid uint64) {
+	if gid != 0 {
+		rg.mu.Lock()
+		delete(rg.active, gid)
+		rg.mu.Unlock()
+	}
+}
+
+// encodeAndWriteReentrant encodes 'entry' using the encoder of the first output,
+// that enables 'lvl', and writes it to the fallback io.Writer
+// of the reentrancy guard. Deduplication is not applied.
+func (ci *CommonIntegrator) encodeAndWriteReentrant(entry *Entry, lvl Level) {
+	for _, output := range ci.output {
+		if lvl.IsEnabledFor(output.minLevel) {
+			encoded := ciPostProcess(output.encoder.EncodeEntry(entry), output.postProcessors)
+			_, _ = ci.rg.fallback.Write(encoded)
+			return
+		}
+	}
+}
+
+// ciWriterMayLog reports whether 'w' may log through ekalog while it's writing.
+// Standard writers (files, in-memory buffers, io.Discard) never do that.
+// The known wrappers (BufferedWriter, TimeoutWriter, etc) are unwrapped.
+func ciWriterMayLog(w io.Writer) bool {
+
+	switch typed := w.(type) {
+
+	case *os.File, *bytes.Buffer, *strings.Builder:
+		return false
+
+	case *_CI_Fallback:
+		for _, fallbackWriter := range typed.writers {
+			if ciWriterMayLog(fallbackWriter) {
+				return true
+			}
+		}
+		return false
+
+	case *FileWriter:
+		return typed.fallback != nil && ciWriterMayLog(typed.fallback)
+
+	case *SocketWriter:
+		return false
+
+	case *BufferedWriter:
+		return ciWriterMayLog(typed.w)
+
+	case *TimeoutWriter:
+		return ciWriterMayLog(typed.w) || typed.fallback != nil && ciWriterMayLog(typed.fallback)
+
+	case *_CICE_DropColors:
+		return ciWriterMayLog(typed.dest)
+	}
+
+	return w != io.Discard &&
+		ekaclike.TakeRealAddr(w) != ekaclike.TakeRealAddr(ekasys.Stdout())
+}
@@ -0,0 +1,486 @@
+// Copyright © 2020-2021. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommonIntegrator_WithDeduplication(t *testing.T) {
+
+	b := bytes.NewBuffer(nil)
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m/?$ }}{{f/v=/?$;}}")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WithDeduplication(time.Minute).
+		WriteTo(b)
+
+	ekalog.ReplaceIntegrator(ci)
+
+	for i := 0; i < 5; i++ {
+		ekalog.Info("retry", "attempt", 1)
+	}
+	ekalog.Info("retry", "attempt", 2)
+	ekalog.Info("retry", "attempt", 2)
+	_ = ekalog.Sync()
+
+	assert.Equal(t,
+		"retry attempt=1;"+
+			"last message repeated 4 times repeated=4;"+
+			"retry attempt=2;"+
+			"last message repeated 1 times repeated=1;",
+		b.String())
+}
+
+type tSyncCloseWriter struct {
+	bytes.Buffer
+	syncDelay time.Duration
+	syncs     int32
+	closes    int32
+}
+
+func (w *tSyncCloseWriter) Sync() error {
+	time.Sleep(w.syncDelay)
+	atomic.AddInt32(&w.syncs, 1)
+	return nil
+}
+
+func (w *tSyncCloseWriter) Close() error {
+	atomic.AddInt32(&w.closes, 1)
+	return nil
+}
+
+func TestCommonIntegrator_FlushClose(t *testing.T) {
+
+	w := new(tSyncCloseWriter)
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m/?$ }}")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(w).
+		WithEncoder(new(ekalog.CI_JSONEncoder)).
+		WriteTo(w)
+
+	ekalog.ReplaceIntegrator(ci)
+	ekalog.Info("before close")
+
+	assert.NoError(t, ci.Flush(context.Background()))
+	assert.EqualValues(t, 2, atomic.LoadInt32(&w.syncs))
+
+	assert.NoError(t, ci.Close(nil))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&w.closes))
+
+	n := w.Len()
+	ekalog.Info("after close")
+	assert.Equal(t, n, w.Len())
+	assert.NoError(t, ci.Close(nil))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&w.closes))
+
+	w = &tSyncCloseWriter{syncDelay: time.Second}
+	ci = new(ekalog.CommonIntegrator).WithEncoder(new(ekalog.CI_JSONEncoder)).WriteTo(w)
+	ekalog.ReplaceIntegrator(ci)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	assert.Equal(t, context.DeadlineExceeded, ci.Flush(ctx))
+}
+
+func TestLogger_ReplaceIntegrator_Derived(t *testing.T) {
+
+	newCI := func(w *bytes.Buffer) *ekalog.CommonIntegrator {
+		return new(ekalog.CommonIntegrator).
+			WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m/?$ }}")).
+			WithMinLevel(ekalog.LEVEL_DEBUG).
+			WriteTo(w)
+	}
+
+	b1, b2 := bytes.NewBuffer(nil), bytes.NewBuffer(nil)
+	ekalog.ReplaceIntegrator(newCI(b1))
+
+	derived := ekalog.Copy().WithString("k", "v")
+	derived.Info("first")
+
+	ekalog.ReplaceIntegrator(newCI(b2))
+	derived.Info("second")
+
+	assert.Equal(t, "first ", b1.String())
+	assert.Equal(t, "second ", b2.String())
+}
+
+type tReplacingWriter struct {
+	bytes.Buffer
+	replace func()
+}
+
+func (w *tReplacingWriter) Write(p []byte) (int, error) {
+	if replace := w.replace; replace != nil {
+		w.replace = nil
+		replace()
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestLogger_ReplaceIntegrator_Reentrant(t *testing.T) {
+
+	newCI := func(w io.Writer) *ekalog.CommonIntegrator {
+		return new(ekalog.CommonIntegrator).
+			WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m/?$ }}")).
+			WithMinLevel(ekalog.LEVEL_DEBUG).
+			WriteTo(w)
+	}
+
+	var (
+		w1 = new(tReplacingWriter)
+		w2 = bytes.NewBuffer(nil)
+		l  = ekalog.WithIntegrator(newCI(w1))
+	)
+
+	// The writer replaces Integrator while the Entry is being written by it.
+	w1.replace = func() { l.ReplaceIntegrator(newCI(w2)) }
+
+	done := make(chan struct{})
+	go func() {
+		l.Info("first")
+		close(done)
+	}()
+
+	// The own Entry of the writer is not waited for (read more: integratorReplaceDrainTimeout).
+	select {
+	case <-done:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("ReplaceIntegrator called from io.Writer waits for its own log entry")
+	}
+
+	l.Info("second")
+
+	assert.Equal(t, "first ", w1.String())
+	assert.Equal(t, "ekalog: old Integrator is still in use after replacing, it's not synced second ", w2.String())
+}
+
+type tBlockingWriter struct {
+	bytes.Buffer
+	entered, unblock chan struct{}
+}
+
+func (w *tBlockingWriter) Write(p []byte) (int, error) {
+	close(w.entered)
+	<-w.unblock
+	return w.Buffer.Write(p)
+}
+
+func TestLogger_ReplaceIntegrator_Drain(t *testing.T) {
+
+	newCI := func(w io.Writer) *ekalog.CommonIntegrator {
+		return new(ekalog.CommonIntegrator).
+			WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m/?$ }}")).
+			WithMinLevel(ekalog.LEVEL_DEBUG).
+			WriteTo(w)
+	}
+
+	var (
+		w1 = &tBlockingWriter{entered: make(chan struct{}), unblock: make(chan struct{})}
+		w2 = bytes.NewBuffer(nil)
+		l  = ekalog.WithIntegrator(newCI(w1))
+	)
+
+	go l.Info("first")
+	<-w1.entered
+
+	replaced := make(chan struct{})
+	go func() {
+		l.ReplaceIntegrator(newCI(w2))
+		close(replaced)
+	}()
+
+	select {
+	case <-replaced:
+		t.Fatal("ReplaceIntegrator doesn't wait for the log entry of another goroutine")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(w1.unblock)
+
+	select {
+	case <-replaced:
+	case <-time.After(500 * time.Millisecond):
+		t.Fatal("ReplaceIntegrator isn't notified that the log entry is done")
+	}
+
+	assert.Equal(t, "first ", w1.String())
+	assert.Equal(t, "", w2.String())
+}
+
+func TestCommonIntegrator_FanOut(t *testing.T) {
+
+	var (
+		all      = bytes.NewBuffer(nil)
+		errs     = bytes.NewBuffer(nil)
+		jsonEnc  = new(ekalog.CI_JSONEncoder)
+		plainEnc = new(ekalog.CI_ConsoleEncoder).SetFormat("{{m}}{{f/?^ /v=}}\n")
+	)
+
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(plainEnc).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(all).
+		WithEncoder(jsonEnc).
+		WithMinLevel(ekalog.LEVEL_ERROR).
+		WriteTo(all).
+		WithEncoder(plainEnc).
+		WriteTo(errs) // inherits LEVEL_ERROR
+
+	ekalog.ReplaceIntegrator(ci)
+	l := ekalog.Copy().WithString("app", "test")
+
+	l.Info("info")
+	l.Error("error")
+
+	assert.Equal(t, "error app=\"test\"", errs.String())
+	assert.Contains(t, all.String(), "info app=\"test\"error app=\"test\"{")
+	assert.Contains(t, all.String(), `"message":"error"`)
+	assert.Equal(t, 1, bytes.Count(all.Bytes(), []byte(`"app"`)))
+	assert.NotContains(t, all.String(), `"message":"info"`)
+}
+
+func TestCommonIntegrator_MinLevelForCaller(t *testing.T) {
+
+	var (
+		plain = bytes.NewBuffer(nil)
+		js    = bytes.NewBuffer(nil)
+	)
+
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{w/fw:l}} {{m}}|")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WithMinLevelForStackTrace(ekalog.LEVEL_ERROR).
+		WithMinLevelForCaller(ekalog.LEVEL_INFO).
+		WriteTo(plain).
+		WithEncoder(new(ekalog.CI_JSONEncoder)).
+		WriteTo(js) // inherits LEVEL_DEBUG, but no caller
+
+	ekalog.ReplaceIntegrator(ci)
+	l := ekalog.Copy()
+
+	l.Debug("debug")
+	l.Info("info")
+
+	assert.Equal(t, ekalog.LEVEL_INFO, ci.MinLevelForCaller())
+	assert.True(t, strings.HasPrefix(plain.String(), " debug|"))
+	assert.Regexp(t, `TestCommonIntegrator_MinLevelForCaller:\d+\s+info\|$`, plain.String())
+	assert.NotContains(t, js.String(), `"caller"`)
+}
+
+func TestCommonIntegrator_MinLevelForCaller_First(t *testing.T) {
+
+	var (
+		b    = bytes.NewBuffer(nil)
+		ci   *ekalog.CommonIntegrator
+		done = make(chan struct{})
+	)
+
+	go func() {
+		ci = new(ekalog.CommonIntegrator).
+			WithMinLevelForCaller(ekalog.LEVEL_INFO).
+			WriteTo(b)
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("WithMinLevelForCaller as the first builder's call is deadlocked")
+	}
+
+	ekalog.WithIntegrator(ci).Warn("warn")
+
+	assert.Equal(t, ekalog.LEVEL_INFO, ci.MinLevelForCaller())
+	assert.Contains(t, b.String(), "warn")
+}
+
+func TestCommonIntegrator_DefaultStackTrace(t *testing.T) {
+
+	b := bytes.NewBuffer(nil)
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_JSONEncoder)).
+		WriteTo(b))
+
+	ekalog.Copy().Warn("warn")
+
+	assert.Contains(t, b.String(), `"stacktrace"`)
+	assert.Contains(t, b.String(), "TestCommonIntegrator_DefaultStackTrace")
+}
+
+type tFailingWriter struct {
+	bytes.Buffer
+	fail bool
+}
+
+func (w *tFailingWriter) Write(p []byte) (int, error) {
+	if w.fail {
+		return 0, io.ErrClosedPipe
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestCommonIntegrator_WriteToWithFallback(t *testing.T) {
+
+	var (
+		primary  = new(tFailingWriter)
+		fallback = new(tFailingWriter)
+		last     = new(tSyncCloseWriter)
+	)
+
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m}}{{f/?^ /v=}}|")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteToWithFallback(primary, fallback, nil, last)
+
+	ekalog.ReplaceIntegrator(ci)
+	l := ekalog.Copy()
+
+	l.Info("first")
+
+	primary.fail = true
+	l.Info("second")
+	l.Info("third")
+
+	fallback.fail = true
+	l.Info("fourth")
+
+	primary.fail = false
+	l.Info("fifth")
+
+	assert.Equal(t, "first|"+
+		"fifth|ekalog: writer recovered fallback_writer_idx=0|", primary.String())
+	assert.Equal(t, "second|"+
+		"ekalog: writer failover fallback_writer_idx=1fallback_error=\"io: read/write on closed pipe\"|"+
+		"third|", fallback.String())
+	assert.Equal(t, "fourth|"+
+		"ekalog: writer failover fallback_writer_idx=2fallback_error=\"io: read/write on closed pipe\"|",
+		last.String())
+
+	assert.NoError(t, ci.Close(nil))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&last.syncs))
+	assert.EqualValues(t, 1, atomic.LoadInt32(&last.closes))
+}
+
+func TestCommonIntegrator_WithPostProcessors(t *testing.T) {
+
+	var (
+		plain   = bytes.NewBuffer(nil)
+		tenant  = bytes.NewBuffer(nil)
+		framed  = bytes.NewBuffer(nil)
+		dropped = bytes.NewBuffer(nil)
+		enc     = new(ekalog.CI_ConsoleEncoder).SetFormat("\033[31m{{m}}\033[0m|")
+	)
+
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(enc).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(plain, ekalog.CICE_DropColors(dropped)).
+		WithEncoder(enc).
+		WithPostProcessors(ekalog.CI_PostProcessorDropColors, ekalog.CI_PostProcessorPrefix("t42 ")).
+		WriteTo(tenant).
+		WithEncoder(enc).
+		WithPostProcessors(ekalog.CI_PostProcessorDropColors, ekalog.CI_PostProcessorOctetCounting).
+		WriteTo(framed)
+
+	ekalog.ReplaceIntegrator(ci)
+	l := ekalog.Copy()
+
+	l.Info("first")
+	l.Info("second")
+
+	assert.Equal(t, "\033[31mfirst\033[0m|\033[31msecond\033[0m|", plain.String())
+	assert.Equal(t, "first|second|", dropped.String())
+	assert.Equal(t, "t42 first|t42 second|", tenant.String())
+	assert.Equal(t, "6 first|7 second|", framed.String())
+	assert.Equal(t, []byte{0, 0, 0, 2, 'a', 'b'}, ekalog.CI_PostProcessorLengthPrefix([]byte("ab")))
+}
+
+type tLoggingWriter struct {
+	mu sync.Mutex
+	bytes.Buffer
+}
+
+func (w *tLoggingWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	ekalog.Warn("writer is slow")
+	return w.Buffer.Write(p)
+}
+
+func TestCommonIntegrator_Reentrancy(t *testing.T) {
+
+	r, stderr, err := os.Pipe()
+	require.NoError(t, err)
+
+	stderrBak := os.Stderr
+	os.Stderr = stderr
+	defer func() { os.Stderr = stderrBak }()
+
+	w := new(tLoggingWriter)
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m}}|")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WithReentrancyGuard(true).
+		WriteTo(w)
+
+	ekalog.ReplaceIntegrator(ci)
+
+	ekalog.Info("first")
+	ekalog.Info("second")
+
+	require.NoError(t, stderr.Close())
+	reentrant, err := io.ReadAll(r)
+	require.NoError(t, err)
+
+	assert.Equal(t, "first|second|", w.String())
+	assert.Equal(t, "writer is slow|writer is slow|", string(reentrant))
+}
+
+func TestCommonIntegrator_WithStaticFields(t *testing.T) {
+
+	var (
+		file    = bytes.NewBuffer(nil)
+		datadog = bytes.NewBuffer(nil)
+		plain   = bytes.NewBuffer(nil)
+		enc     = new(ekalog.CI_ConsoleEncoder).SetFormat("{{m/?$ }}{{f/v=/e }}|")
+	)
+
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(enc).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WithStaticFields("sink", "file").
+		WriteTo(file).
+		WithEncoder(enc).
+		WithStaticFields("sink", "datadog", "dd.source", "go").
+		WriteTo(datadog).
+		WithEncoder(enc).
+		WriteTo(plain)
+
+	ekalog.ReplaceIntegrator(ci)
+
+	ekalog.Info("msg", "k", 1)
+	ekalog.Info("msg2")
+
+	assert.Equal(t, `msg k=1 sink="file"|msg2 sink="file"|`, file.String())
+	assert.Equal(t, `msg k=1 sink="datadog" dd.source="go"|msg2 sink="datadog" dd.source="go"|`,
+		datadog.String())
+	assert.Equal(t, "msg k=1|msg2 |", plain.String())
+}
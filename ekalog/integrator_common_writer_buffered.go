@@ -0,0 +1,256 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekadeath"
+	"github.com/qioalice/ekago/v3/ekatyp"
+	"github.com/qioalice/ekago/v3/internal/ekaclike"
+)
+
+type (
+	// BufferedWriter is an io.Writer wrapper, that accumulates written data
+	// in the RAM buffer and writes it to the wrapped io.Writer at once
+	// (one syscall for many log entries instead of one per each).
+	//
+	// The buffer is flushed:
+	//   - when it reaches the size (see NewBufferedWriter());
+	//   - by the interval (if it's set, see NewBufferedWriter());
+	//   - by Flush(), Sync() (e.g. by CommonIntegrator.Flush());
+	//   - by Close() (e.g. by CommonIntegrator.Close());
+	//   - on ekadeath.Die(), ekadeath.Exit() (the hook is registered by NewBufferedWriter()).
+	//
+	// Errors of writing to the wrapped io.Writer are reported to the error handler
+	// (see SetErrorHandler()) and returned by Flush(). The data is dropped then.
+	//
+	// BufferedWriter implements CI_WriterFlusher, CI_WriterCloser and ekatyp.Syncer.
+	// It's goroutine-safe.
+	//
+	// Use NewBufferedWriter() to create it.
+	BufferedWriter struct {
+		mu       sync.Mutex
+		w        io.Writer
+		buf      []byte
+		size     int
+		onError  func(err error)
+		isClosed bool
+		stop     chan struct{} // closed by Close(), nil if there's no interval
+	}
+)
+
+// BUFFERED_WRITER_DEFAULT_SIZE is a default size of BufferedWriter's buffer
+// if a non-positive size is passed.
+//
+//goland:noinspection GoSnakeCaseUsage
+const BUFFERED_WRITER_DEFAULT_SIZE = 64 << 10
+
+var (
+	// Make sure we won't break API.
+	_ CI_WriterFlusher = (*BufferedWriter)(nil)
+	_ CI_WriterCloser  = (*BufferedWriter)(nil)
+	_ ekatyp.Syncer    = (*BufferedWriter)(nil)
+)
+
+// NewBufferedWriter returns a new BufferedWriter wrapping 'w', that flushes
+// its buffer when it reaches 'size' bytes or each 'interval' (if it's > 0).
+// If 'size' is non-positive, BUFFERED_WRITER_DEFAULT_SIZE is used.
+// Registers ekadeath hook that flushes the buffer if the app is going down.
+// Panics if 'w' is nil.
+func NewBufferedWriter(w io.Writer, size int, interval time.Duration) *BufferedWriter {
+
+	if ekaclike.TakeRealAddr(w) == nil {
+		panic("ekalog: NewBufferedWriter: io.Writer is nil")
+	}
+
+	if size <= 0 {
+		size = BUFFERED_WRITER_DEFAULT_SIZE
+	}
+
+	bw := &BufferedWriter{
+		w:    w,
+		buf:  make([]byte, 0, size),
+		size: size,
+	}
+
+	if interval > 0 {
+		bw.stop = make(chan struct{})
+		go bw.flushByInterval(interval)
+	}
+
+	ekadeath.Reg(func() {
+		_ = bw.Flush(context.Background())
+	})
+
+	return bw
+}
+
+// SetErrorHandler sets the callback, that is called (under BufferedWriter's lock)
+// with each error of writing to the wrapped io.Writer.
+// Nil means errors are only returned by Flush(), Sync(), Close().
+//
+// This method MUST NOT be called after the first Write() call.
+func (bw *BufferedWriter) SetErrorHandler(cb func(err error)) *BufferedWriter {
+	bw.onError = cb
+	return bw
+}
+
+// Write appends 'p' to the buffer, flushing it if it's full.
+// Data that is greater than the buffer's size is written directly
+// (after the buffer is flushed).
+// Always returns len(p), nil, unless BufferedWriter is closed.
+// Implements io.Writer.
+func (bw *BufferedWriter) Write(p []byte) (int, error) {
+
+	bw.mu.Lock()
+	defer bw.mu.Unlock()
+
+	if bw.isClosed {
+		return 0, io.ErrClosedPipe
+	}
+
+	if len(bw.buf)+len(p) > bw.size {
+		_ = bw.flush()
+	}
+
+	if len(p) >= bw.size {
+		_ = bw.write(p)
+	} else if bw.buf = append(bw.buf, p...); len(bw.buf) == bw.size {
+		_ = bw.flush()
+	}
+
+	return len(p), nil
+}
+
+// Flush writes the buffer to the wrapped io.Writer, and then flushes it
+// if it implements CI_WriterFlusher or ekatyp.Syncer.
+// Returns the first occurred error or ctx.Err() if 'ctx' is done before.
+// Nil 'ctx' means context.Background().
+func (bw *BufferedWriter) Flush(ctx context.Context) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return ciCallWithContext(ctx, func() error {
+		bw.mu.Lock()
+		defer bw.mu.Unlock()
+
+		err := bw.flush()
+		if errFlush := bw.flushUnderlying(ctx); err == nil {
+			err = errFlush
+		}
+		return err
+	})
+}
+
+// Sync is Flush() w/o context. Implements ekatyp.Syncer.
+func (bw *BufferedWriter) Sync() error {
+	return bw.Flush(context.Background())
+}
+
+// Close flushes the buffer (see Flush()), stops the interval flushing
+// and closes the wrapped io.Writer if it implements CI_WriterCloser or io.Closer.
+// All next writes are rejected. The next calls of Close() are no-op.
+// Nil 'ctx' means context.Background().
+func (bw *BufferedWriter) Close(ctx context.Context) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return ciCallWithContext(ctx, func() error {
+		bw.mu.Lock()
+		defer bw.mu.Unlock()
+
+		if bw.isClosed {
+			return nil
+		}
+
+		bw.isClosed = true
+		if bw.stop != nil {
+			close(bw.stop)
+		}
+
+		err := bw.flush()
+		if errFlush := bw.flushUnderlying(ctx); err == nil {
+			err = errFlush
+		}
+
+		var errClose error
+		switch typedW := bw.w.(type) {
+		case CI_WriterCloser:
+			errClose = typedW.Close(ctx)
+		case io.Closer:
+			errClose = typedW.Close()
+		}
+
+		if err == nil {
+			err = errClose
+		}
+		return err
+	})
+}
+
+// flush writes the buffer to the wrapped io.Writer resetting it.
+// Requires bw.mu to be locked.
+func (bw *BufferedWriter) flush() error {
+	if len(bw.buf) == 0 {
+		return nil
+	}
+	err := bw.write(bw.buf)
+	bw.buf = bw.buf[:0]
+	return err
+}
+
+// write writes 'p' to the wrapped io.Writer reporting an error (if any)
+// to the error handler. Requires bw.mu to be locked.
+func (bw *BufferedWriter) write(p []byte) error {
+	n, err := bw.w.Write(p)
+	if err == nil && n < len(p) {
+		err = io.ErrShortWrite
+	}
+	if err != nil && bw.onError != nil {
+		bw.onError(err)
+	}
+	return err
+}
+
+// flushUnderlying flushes the wrapped io.Writer if it implements
+// CI_WriterFlusher or ekatyp.Syncer. Requires bw.mu to be locked.
+func (bw *BufferedWriter) flushUnderlying(ctx context.Context) error {
+	switch typedW := bw.w.(type) {
+	case CI_WriterFlusher:
+		return typedW.Flush(ctx)
+	case ekatyp.Syncer:
+		return typedW.Sync()
+	}
+	return nil
+}
+
+// flushByInterval flushes the buffer each 'interval' until BufferedWriter
+// is closed. It's run in the separate goroutine.
+func (bw *BufferedWriter) flushByInterval(interval time.Duration) {
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			bw.mu.Lock()
+			_ = bw.flush()
+			bw.mu.Unlock()
+
+		case <-bw.stop:
+			return
+		}
+	}
+}
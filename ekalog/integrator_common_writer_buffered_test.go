@@ -0,0 +1,93 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tBufferedWriterDest struct {
+	mu     sync.Mutex
+	buf    bytes.Buffer
+	writes int
+	err    error
+}
+
+func (w *tBufferedWriterDest) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes++
+	if w.err != nil {
+		return 0, w.err
+	}
+	return w.buf.Write(p)
+}
+
+func (w *tBufferedWriterDest) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestBufferedWriter(t *testing.T) {
+
+	var (
+		dest     = new(tBufferedWriterDest)
+		reported []error
+	)
+
+	bw := ekalog.NewBufferedWriter(dest, 8, 0).
+		SetErrorHandler(func(err error) { reported = append(reported, err) })
+
+	_, _ = bw.Write([]byte("abc"))
+	_, _ = bw.Write([]byte("def"))
+	require.Equal(t, "", dest.String())
+
+	// Buffer is full, flushed.
+	_, _ = bw.Write([]byte("gh"))
+	require.Equal(t, "abcdefgh", dest.String())
+
+	// Too big data is written directly.
+	_, _ = bw.Write([]byte("0123456789"))
+	require.Equal(t, "abcdefgh0123456789", dest.String())
+	require.Equal(t, 2, dest.writes)
+
+	_, _ = bw.Write([]byte("x"))
+	require.NoError(t, bw.Flush(nil))
+	require.Equal(t, "abcdefgh0123456789x", dest.String())
+
+	require.Empty(t, reported)
+
+	dest.err = io.ErrClosedPipe
+	_, _ = bw.Write([]byte("y"))
+	require.Equal(t, io.ErrClosedPipe, bw.Flush(context.Background()))
+	require.Equal(t, []error{io.ErrClosedPipe}, reported)
+
+	require.NoError(t, bw.Close(nil))
+	_, err := bw.Write([]byte("z"))
+	require.Error(t, err)
+}
+
+func TestBufferedWriter_Interval(t *testing.T) {
+
+	dest := new(tBufferedWriterDest)
+	bw := ekalog.NewBufferedWriter(dest, 0, 10*time.Millisecond)
+	defer bw.Close(nil)
+
+	_, _ = bw.Write([]byte("abc"))
+	require.Eventually(t, func() bool {
+		return dest.String() == "abc"
+	}, time.Second, 5*time.Millisecond)
+}
@@ -0,0 +1,239 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"context"
+	"io"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+)
+
+type (
+	// FileWriter is an io.Writer, that writes to the file by its path
+	// and is able to reopen it (see Reopen()), e.g. by SIGHUP (see ReopenOnSignal()).
+	// So the external logrotate works without "copytruncate" option:
+	// it renames the file and sends SIGHUP, the FileWriter creates a new one
+	// by the same path.
+	//
+	// If the write to the file fails, the data is written to the fallback
+	// io.Writer (os.Stderr by default, see SetFallback()).
+	//
+	// FileWriter implements ekatyp.Syncer and CI_WriterCloser,
+	// so CommonIntegrator.Sync(), CommonIntegrator.Flush(), CommonIntegrator.Close()
+	// are passed to the file.
+	//
+	// FileWriter MUST be created by NewFileWriter().
+	FileWriter struct {
+		path     string
+		perm     os.FileMode
+		fallback io.Writer
+
+		mu       sync.Mutex
+		f        *os.File // nil if FileWriter is closed
+		isClosed bool
+
+		sigCh chan os.Signal // nil if there's no signal listener
+		stop  chan struct{}
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// FILE_WRITER_DEFAULT_PERM is a default permission bits of the file,
+	// created by FileWriter.
+	FILE_WRITER_DEFAULT_PERM os.FileMode = 0644
+)
+
+var (
+	// Make sure we won't break API.
+	_ CI_WriterCloser = (*FileWriter)(nil)
+)
+
+// NewFileWriter opens (creates if it's not exist) the file by 'path'
+// in append mode with FILE_WRITER_DEFAULT_PERM permission bits
+// and returns a new FileWriter of it.
+// Returns an error if the file can't be opened.
+func NewFileWriter(path string) (*FileWriter, error) {
+
+	fw := &FileWriter{
+		path:     path,
+		perm:     FILE_WRITER_DEFAULT_PERM,
+		fallback: os.Stderr,
+	}
+
+	f, err := fw.open()
+	if err != nil {
+		return nil, err
+	}
+
+	fw.f = f
+	return fw, nil
+}
+
+// SetFallback sets the io.Writer the data is written to, if the write
+// to the file fails. Nil means there's no fallback io.Writer (the data is lost).
+// By default, it's os.Stderr.
+//
+// This method MUST NOT be called after the first Write() call.
+func (fw *FileWriter) SetFallback(fallback io.Writer) *FileWriter {
+	fw.fallback = fallback
+	return fw
+}
+
+// SetPerm sets the permission bits of the file, created by the next Reopen().
+// By default, it's FILE_WRITER_DEFAULT_PERM.
+func (fw *FileWriter) SetPerm(perm os.FileMode) *FileWriter {
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+	fw.perm = perm
+	return fw
+}
+
+// ReopenOnSignal starts a background goroutine, that calls Reopen()
+// each time the process receives any of 'signals' (SIGHUP if there are no ones).
+// The errors of Reopen() are written to the fallback io.Writer.
+// The goroutine is stopped by Close(). The next calls are no-op.
+func (fw *FileWriter) ReopenOnSignal(signals ...os.Signal) *FileWriter {
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.sigCh != nil || fw.isClosed {
+		return fw
+	}
+
+	if len(signals) == 0 {
+		signals = []os.Signal{syscall.SIGHUP}
+	}
+
+	fw.sigCh = make(chan os.Signal, 1)
+	fw.stop = make(chan struct{})
+	signal.Notify(fw.sigCh, signals...)
+
+	go fw.signalListener()
+	return fw
+}
+
+// Path returns the path of the file.
+func (fw *FileWriter) Path() string {
+	return fw.path
+}
+
+// Reopen opens the file by the path again (creating it if it's not exist)
+// and closes the previous one. The writes are switched to the new file
+// atomically: the concurrent Write() calls write either to the previous file
+// or to the new one.
+//
+// If the file can't be opened, the previous one is kept and the error is returned. Returns nil if FileWriter is closed.
+func (fw *FileWriter) Reopen() error {
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.isClosed {
+		return nil
+	}
+
+	f, err := fw.open()
+	if err != nil {
+		return err
+	}
+
+	prev := fw.f
+	fw.f = f
+
+	if prev != nil {
+		_ = prev.Close()
+	}
+
+	return nil
+}
+
+// Write writes 'p' to the file. If it fails, 'p' is written to the fallback
+// io.Writer (if it's set), the result of which is returned then.
+// Returns os.ErrClosed if FileWriter is closed.
+func (fw *FileWriter) Write(p []byte) (int, error) {
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	n, err := fw.f.Write(p)
+	if err == nil || fw.fallback == nil {
+		return n, err
+	}
+
+	return fw.fallback.Write(p)
+}
+
+// Sync commits the written data of the file to the stable storage.
+func (fw *FileWriter) Sync() error {
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.f == nil {
+		return nil
+	}
+
+	return fw.f.Sync()
+}
+
+// Close stops the signal listener (see ReopenOnSignal()) and closes the file.
+// All next writes return os.ErrClosed. The next calls of Close() are no-op.
+func (fw *FileWriter) Close(_ context.Context) error {
+
+	fw.mu.Lock()
+	defer fw.mu.Unlock()
+
+	if fw.isClosed {
+		return nil
+	}
+
+	fw.isClosed = true
+
+	if fw.sigCh != nil {
+		signal.Stop(fw.sigCh)
+		close(fw.stop)
+	}
+
+	if fw.f == nil {
+		return nil
+	}
+
+	err := fw.f.Close()
+	fw.f = nil
+
+	return err
+}
+
+// ---------------------------------------------------------------------------- //
+
+// open opens the file by the path in append mode.
+func (fw *FileWriter) open() (*os.File, error) {
+	return os.OpenFile(fw.path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, fw.perm)
+}
+
+// signalListener calls Reopen() for each received signal until Close() is called.
+func (fw *FileWriter) signalListener() {
+	for {
+		select {
+		case <-fw.sigCh:
+			if err := fw.Reopen(); err != nil && fw.fallback != nil {
+				_, _ = io.WriteString(fw.fallback,
+					"ekalog: FileWriter: failed to reopen file: "+err.Error()+"\n")
+			}
+		case <-fw.stop:
+			return
+		}
+	}
+}
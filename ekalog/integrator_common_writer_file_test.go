@@ -0,0 +1,90 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileWriter_Reopen(t *testing.T) {
+
+	var (
+		path    = filepath.Join(t.TempDir(), "app.log")
+		rotated = path + ".1"
+	)
+
+	fw, err := ekalog.NewFileWriter(path)
+	require.NoError(t, err)
+	defer fw.Close(context.Background())
+
+	_, err = fw.Write([]byte("first\n"))
+	require.NoError(t, err)
+
+	// logrotate w/o copytruncate: rename, then signal the process.
+	require.NoError(t, os.Rename(path, rotated))
+
+	_, err = fw.Write([]byte("second\n"))
+	require.NoError(t, err)
+
+	require.NoError(t, fw.Reopen())
+
+	_, err = fw.Write([]byte("third\n"))
+	require.NoError(t, err)
+
+	requireFileContent(t, rotated, "first\nsecond\n")
+	requireFileContent(t, path, "third\n")
+
+	require.NoError(t, fw.Close(context.Background()))
+
+	_, err = fw.Write([]byte("closed\n"))
+	require.Equal(t, os.ErrClosed, err)
+}
+
+func TestFileWriter_ReopenOnSignal(t *testing.T) {
+
+	var (
+		path    = filepath.Join(t.TempDir(), "app.log")
+		rotated = path + ".1"
+	)
+
+	fw, err := ekalog.NewFileWriter(path)
+	require.NoError(t, err)
+	defer fw.Close(context.Background())
+
+	fw.SetFallback(new(bytes.Buffer)).ReopenOnSignal()
+
+	require.NoError(t, os.Rename(path, rotated))
+	self, err := os.FindProcess(os.Getpid())
+	require.NoError(t, err)
+	require.NoError(t, self.Signal(syscall.SIGHUP))
+
+	require.Eventually(t, func() bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}, time.Second, 5*time.Millisecond)
+
+	_, err = fw.Write([]byte("after rotation\n"))
+	require.NoError(t, err)
+
+	requireFileContent(t, path, "after rotation\n")
+}
+
+func requireFileContent(t *testing.T, path, expected string) {
+	t.Helper()
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, expected, string(content))
+}
@@ -0,0 +1,382 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/binary"
+	"errors"
+	"net"
+	"net/url"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type (
+	// SocketWriter is an io.Writer, that writes to the network socket
+	// (TCP, UDP or Unix one), e.g. to the TCP inputs of Logstash or Fluentd.
+	//
+	// The connection is established by the first Write() and re-established
+	// by the next ones if it's broken. If the connection can't be established,
+	// SocketWriter is temporary disabled: all writes return
+	// ErrSocketWriterDisabled immediately (w/o dialing) during the backoff
+	// (that is doubled after each failed attempt, see SetBackoff()),
+	// so the fallback writers of WriteToWithFallback() are used meanwhile.
+	//
+	// Each written Entry is framed (see SetFraming()), so the receiver
+	// is able to split the stream. TLS may be enabled using SetTLS().
+	//
+	// SocketWriter implements CI_WriterCloser and CI_WriterRemote
+	// (Unix sockets are not remote).
+	//
+	// SocketWriter MUST be created by NewSocketWriter().
+	SocketWriter struct {
+		network, address string
+		tlsConfig        *tls.Config
+		framing          SocketWriterFraming
+		dialTimeout      time.Duration
+		writeTimeout     time.Duration
+		backoffMin       time.Duration
+		backoffMax       time.Duration
+
+		mu            sync.Mutex
+		conn          net.Conn // nil if it's not connected
+		backoff       time.Duration
+		disabledUntil time.Time // zero if SocketWriter is not disabled
+		frame         []byte    // reusable buffer of framed data
+		isClosed      bool
+	}
+
+	// SocketWriterFraming is a way SocketWriter delimits written Entry.
+	// Read more: SocketWriter.SetFraming().
+	SocketWriterFraming uint8
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// SOCKET_WRITER_FRAMING_NEWLINE appends a new line char to the data,
+	// if it's not ended by it already. Default framing.
+	SOCKET_WRITER_FRAMING_NEWLINE SocketWriterFraming = iota
+
+	// SOCKET_WRITER_FRAMING_NONE writes the data as is.
+	// Useful for UDP and Unix datagram sockets, where each write is a datagram.
+	SOCKET_WRITER_FRAMING_NONE
+
+	// SOCKET_WRITER_FRAMING_LENGTH_PREFIX prepends the length of the data
+	// as 4 bytes big endian uint32.
+	// The same as CI_PostProcessorLengthPrefix() does.
+	SOCKET_WRITER_FRAMING_LENGTH_PREFIX
+
+	// SOCKET_WRITER_FRAMING_OCTET_COUNTING prepends the length of the data
+	// as decimal number followed by space (syslog over TCP, RFC 6587).
+	// The same as CI_PostProcessorOctetCounting() does.
+	SOCKET_WRITER_FRAMING_OCTET_COUNTING
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// SOCKET_WRITER_DEFAULT_DIAL_TIMEOUT is a default timeout of establishing
+	// the connection by SocketWriter.
+	SOCKET_WRITER_DEFAULT_DIAL_TIMEOUT = 5 * time.Second
+
+	// SOCKET_WRITER_DEFAULT_WRITE_TIMEOUT is a default timeout of one write
+	// to the connection by SocketWriter.
+	SOCKET_WRITER_DEFAULT_WRITE_TIMEOUT = 5 * time.Second
+
+	// SOCKET_WRITER_DEFAULT_BACKOFF_MIN is a default duration SocketWriter
+	// is disabled after the first failed attempt to establish the connection.
+	SOCKET_WRITER_DEFAULT_BACKOFF_MIN = 100 * time.Millisecond
+
+	// SOCKET_WRITER_DEFAULT_BACKOFF_MAX is a default max duration SocketWriter
+	// is disabled after the failed attempt to establish the connection.
+	SOCKET_WRITER_DEFAULT_BACKOFF_MAX = 30 * time.Second
+)
+
+var (
+	// ErrSocketWriterDisabled is returned by SocketWriter.Write()
+	// if the connection can't be established and SocketWriter
+	// is temporary disabled. Read more: SocketWriter.
+	ErrSocketWriterDisabled = errors.New("ekalog: SocketWriter is temporary disabled")
+)
+
+var (
+	// Make sure we won't break API.
+	_ CI_WriterCloser = (*SocketWriter)(nil)
+	_ CI_WriterRemote = (*SocketWriter)(nil)
+)
+
+// NewSocketWriter returns a new SocketWriter, that writes to the socket
+// by 'rawURL' of form "<network>://<address>", e.g. "tcp://127.0.0.1:5000",
+// "udp://[::1]:514", "unix:///var/run/fluent.sock".
+// Supported networks: "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6",
+// "unix", "unixgram".
+//
+// The connection is not established until the first Write().
+// Returns an error if 'rawURL' is malformed or its network is not supported.
+func NewSocketWriter(rawURL string) (*SocketWriter, error) {
+
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, errors.New("ekalog: NewSocketWriter: malformed URL: " + err.Error())
+	}
+
+	sw := &SocketWriter{
+		network:      u.Scheme,
+		dialTimeout:  SOCKET_WRITER_DEFAULT_DIAL_TIMEOUT,
+		writeTimeout: SOCKET_WRITER_DEFAULT_WRITE_TIMEOUT,
+		backoffMin:   SOCKET_WRITER_DEFAULT_BACKOFF_MIN,
+		backoffMax:   SOCKET_WRITER_DEFAULT_BACKOFF_MAX,
+	}
+
+	switch u.Scheme {
+	case "tcp", "tcp4", "tcp6", "udp", "udp4", "udp6":
+		sw.address = u.Host
+	case "unix", "unixgram":
+		sw.address = u.Host + u.Path
+	default:
+		return nil, errors.New("ekalog: NewSocketWriter: unsupported network: " + strconv.Quote(u.Scheme))
+	}
+
+	if sw.address == "" {
+		return nil, errors.New("ekalog: NewSocketWriter: empty address")
+	}
+
+	return sw, nil
+}
+
+// SetFraming sets the way the written Entry are delimited.
+// By default, it's SOCKET_WRITER_FRAMING_NEWLINE.
+//
+// This method MUST NOT be called after the first Write() call.
+func (sw *SocketWriter) SetFraming(framing SocketWriterFraming) *SocketWriter {
+	sw.framing = framing
+	return sw
+}
+
+// SetTLS enables TLS over TCP connection with provided 'config'
+// (it's cloned, nil means the default one with the server name of the address).
+// Does nothing if the network is not TCP one.
+//
+// This method MUST NOT be called after the first Write() call.
+func (sw *SocketWriter) SetTLS(config *tls.Config) *SocketWriter {
+
+	switch sw.network {
+	case "tcp", "tcp4", "tcp6":
+	default:
+		return sw
+	}
+
+	if config == nil {
+		sw.tlsConfig = new(tls.Config)
+	} else {
+		sw.tlsConfig = config.Clone()
+	}
+
+	if sw.tlsConfig.ServerName == "" {
+		if host, _, err := net.SplitHostPort(sw.address); err == nil {
+			sw.tlsConfig.ServerName = host
+		}
+	}
+
+	return sw
+}
+
+// SetTimeouts sets the timeouts of establishing the connection and one write.
+// Non-positive timeout means there's no timeout. By default, they are
+// SOCKET_WRITER_DEFAULT_DIAL_TIMEOUT, SOCKET_WRITER_DEFAULT_WRITE_TIMEOUT.
+//
+// This method MUST NOT be called after the first Write() call.
+func (sw *SocketWriter) SetTimeouts(dial, write time.Duration) *SocketWriter {
+	sw.dialTimeout, sw.writeTimeout = dial, write
+	return sw
+}
+
+// SetBackoff sets the min and max durations SocketWriter is disabled after
+// the failed attempt to establish the connection. The duration starts
+// from 'min' and is doubled after each next failed attempt up to 'max'.
+// Non-positive 'min' means SocketWriter is never disabled.
+// By default, they are SOCKET_WRITER_DEFAULT_BACKOFF_MIN,
+// SOCKET_WRITER_DEFAULT_BACKOFF_MAX.
+//
+// This method MUST NOT be called after the first Write() call.
+func (sw *SocketWriter) SetBackoff(min, max time.Duration) *SocketWriter {
+	if max < min {
+		max = min
+	}
+	sw.backoffMin, sw.backoffMax = min, max
+	return sw
+}
+
+// Network returns the network of the socket (e.g. "tcp").
+func (sw *SocketWriter) Network() string {
+	return sw.network
+}
+
+// Address returns the address of the socket (e.g. "127.0.0.1:5000").
+func (sw *SocketWriter) Address() string {
+	return sw.address
+}
+
+// IsRemote reports whether the socket is not a Unix one.
+// Read more: CI_WriterRemote.
+func (sw *SocketWriter) IsRemote() bool {
+	return sw.network != "unix" && sw.network != "unixgram"
+}
+
+// Write writes framed 'p' to the socket, establishing the connection
+// if it's not established yet or it's broken. If the write to the established
+// connection fails, the connection is re-established and the write is retried
+// once.
+//
+// Returns ErrSocketWriterDisabled if SocketWriter is temporary disabled,
+// the error of establishing the connection (SocketWriter is disabled then)
+// or os.ErrClosed if SocketWriter is closed.
+func (sw *SocketWriter) Write(p []byte) (int, error) {
+
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.isClosed {
+		return 0, os.ErrClosed
+	}
+
+	sw.frame = sw.appendFrame(sw.frame[:0], p)
+
+	wasConnected := sw.conn != nil
+	err := sw.write(sw.frame)
+
+	if err != nil && wasConnected && err != ErrSocketWriterDisabled {
+		// The connection might be broken by the receiver a long time ago,
+		// so try again with a new one.
+		err = sw.write(sw.frame)
+	}
+
+	if err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the connection (if it's established).
+// All next writes return os.ErrClosed. The next calls of Close() are no-op.
+func (sw *SocketWriter) Close(_ context.Context) error {
+
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	if sw.isClosed {
+		return nil
+	}
+
+	sw.isClosed = true
+
+	if sw.conn == nil {
+		return nil
+	}
+
+	err := sw.conn.Close()
+	sw.conn = nil
+
+	return err
+}
+
+// ---------------------------------------------------------------------------- //
+
+// appendFrame appends framed 'p' to 'to' and returns it.
+// Read more: SetFraming().
+func (sw *SocketWriter) appendFrame(to, p []byte) []byte {
+
+	switch sw.framing {
+
+	case SOCKET_WRITER_FRAMING_NEWLINE:
+		to = append(to, p...)
+		if len(p) == 0 || p[len(p)-1] != '\n' {
+			to = append(to, '\n')
+		}
+		return to
+
+	case SOCKET_WRITER_FRAMING_LENGTH_PREFIX:
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(p)))
+		return append(append(to, length[:]...), p...)
+
+	case SOCKET_WRITER_FRAMING_OCTET_COUNTING:
+		to = strconv.AppendInt(to, int64(len(p)), 10)
+		return append(append(to, ' '), p...)
+
+	default:
+		return append(to, p...)
+	}
+}
+
+// write writes 'frame' to the connection, establishing it if it's required.
+// The connection is closed if the write fails.
+// Requires sw.mu to be locked.
+func (sw *SocketWriter) write(frame []byte) error {
+
+	if sw.conn == nil {
+		if err := sw.connect(); err != nil {
+			return err
+		}
+	}
+
+	if sw.writeTimeout > 0 {
+		_ = sw.conn.SetWriteDeadline(time.Now().Add(sw.writeTimeout))
+	}
+
+	if _, err := sw.conn.Write(frame); err != nil {
+		_ = sw.conn.Close()
+		sw.conn = nil
+		return err
+	}
+
+	return nil
+}
+
+// connect establishes the connection, disabling SocketWriter if it fails.
+// Returns ErrSocketWriterDisabled if SocketWriter is disabled already.
+// Requires sw.mu to be locked.
+func (sw *SocketWriter) connect() error {
+
+	now := time.Now()
+	if now.Before(sw.disabledUntil) {
+		return ErrSocketWriterDisabled
+	}
+
+	var (
+		dialer = net.Dialer{Timeout: sw.dialTimeout}
+		conn   net.Conn
+		err    error
+	)
+
+	if sw.tlsConfig != nil {
+		conn, err = tls.DialWithDialer(&dialer, sw.network, sw.address, sw.tlsConfig)
+	} else {
+		conn, err = dialer.Dial(sw.network, sw.address)
+	}
+
+	if err != nil {
+		if sw.backoffMin > 0 {
+			switch {
+			case sw.backoff < sw.backoffMin:
+				sw.backoff = sw.backoffMin
+			case sw.backoff*2 > sw.backoffMax:
+				sw.backoff = sw.backoffMax
+			default:
+				sw.backoff *= 2
+			}
+			sw.disabledUntil = now.Add(sw.backoff)
+		}
+		return err
+	}
+
+	sw.conn, sw.backoff, sw.disabledUntil = conn, 0, time.Time{}
+	return nil
+}
@@ -0,0 +1,195 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"net"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewSocketWriter(t *testing.T) {
+
+	sw, err := ekalog.NewSocketWriter("tcp://127.0.0.1:5000")
+	require.NoError(t, err)
+	assert.Equal(t, "tcp", sw.Network())
+	assert.Equal(t, "127.0.0.1:5000", sw.Address())
+	assert.True(t, sw.IsRemote())
+
+	sw, err = ekalog.NewSocketWriter("unix:///var/run/fluent.sock")
+	require.NoError(t, err)
+	assert.Equal(t, "/var/run/fluent.sock", sw.Address())
+	assert.False(t, sw.IsRemote())
+
+	for _, rawURL := range []string{"http://127.0.0.1:80", "tcp://", ":bad"} {
+		_, err = ekalog.NewSocketWriter(rawURL)
+		assert.Error(t, err, rawURL)
+	}
+}
+
+func TestSocketWriter_Reconnect(t *testing.T) {
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	accepted := make(chan net.Conn, 2)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			accepted <- conn
+		}
+	}()
+
+	sw, err := ekalog.NewSocketWriter("tcp://" + ln.Addr().String())
+	require.NoError(t, err)
+	defer sw.Close(context.Background())
+
+	n, err := sw.Write([]byte("first"))
+	require.NoError(t, err)
+	assert.Equal(t, 5, n)
+
+	conn := <-accepted
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "first\n", line)
+
+	// The receiver drops the connection. The first write after that
+	// may succeed (the kernel doesn't know it yet), so write until
+	// the new connection is established.
+	require.NoError(t, conn.Close())
+
+	var newConn net.Conn
+	for i := 0; i < 10 && newConn == nil; i++ {
+		_, err = sw.Write([]byte("second\n"))
+		require.NoError(t, err)
+		select {
+		case newConn = <-accepted:
+		case <-time.After(50 * time.Millisecond):
+		}
+	}
+	require.NotNil(t, newConn)
+	defer newConn.Close()
+
+	line, err = bufio.NewReader(newConn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "second\n", line)
+
+	require.NoError(t, sw.Close(context.Background()))
+
+	_, err = sw.Write([]byte("closed"))
+	assert.Equal(t, os.ErrClosed, err)
+}
+
+func TestSocketWriter_Disabled(t *testing.T) {
+
+	// Take a free port and release it, so the connection is refused.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := ln.Addr().String()
+	require.NoError(t, ln.Close())
+
+	sw, err := ekalog.NewSocketWriter("tcp://" + addr)
+	require.NoError(t, err)
+	sw.SetBackoff(100*time.Millisecond, time.Second)
+	defer sw.Close(context.Background())
+
+	_, err = sw.Write([]byte("refused"))
+	require.Error(t, err)
+	assert.NotEqual(t, ekalog.ErrSocketWriterDisabled, err)
+
+	_, err = sw.Write([]byte("disabled"))
+	assert.Equal(t, ekalog.ErrSocketWriterDisabled, err)
+
+	// The receiver is up, but the writer is still disabled until the backoff ends.
+	ln, err = net.Listen("tcp", addr)
+	require.NoError(t, err)
+	defer ln.Close()
+
+	time.Sleep(150 * time.Millisecond)
+
+	_, err = sw.Write([]byte("enabled"))
+	require.NoError(t, err)
+
+	conn, err := ln.Accept()
+	require.NoError(t, err)
+	defer conn.Close()
+
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	require.NoError(t, err)
+	assert.Equal(t, "enabled\n", line)
+}
+
+func TestSocketWriter_Framing(t *testing.T) {
+
+	tests := []struct {
+		framing  ekalog.SocketWriterFraming
+		expected string
+	}{
+		{ekalog.SOCKET_WRITER_FRAMING_NONE, "ab\n"},
+		{ekalog.SOCKET_WRITER_FRAMING_NEWLINE, "a\nb\n"},
+		{ekalog.SOCKET_WRITER_FRAMING_LENGTH_PREFIX, "\x00\x00\x00\x01a\x00\x00\x00\x02b\n"},
+		{ekalog.SOCKET_WRITER_FRAMING_OCTET_COUNTING, "1 a2 b\n"},
+	}
+
+	for _, test := range tests {
+		ln, err := net.Listen("tcp", "127.0.0.1:0")
+		require.NoError(t, err)
+
+		sw, err := ekalog.NewSocketWriter("tcp://" + ln.Addr().String())
+		require.NoError(t, err)
+		sw.SetFraming(test.framing)
+
+		_, err = sw.Write([]byte("a"))
+		require.NoError(t, err)
+		_, err = sw.Write([]byte("b\n"))
+		require.NoError(t, err)
+
+		conn, err := ln.Accept()
+		require.NoError(t, err)
+
+		require.NoError(t, sw.Close(context.Background()))
+		data, err := io.ReadAll(conn)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, string(data))
+
+		_ = conn.Close()
+		_ = ln.Close()
+	}
+}
+
+func TestSocketWriter_UDP(t *testing.T) {
+
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer pc.Close()
+
+	sw, err := ekalog.NewSocketWriter("udp://" + pc.LocalAddr().String())
+	require.NoError(t, err)
+	sw.SetFraming(ekalog.SOCKET_WRITER_FRAMING_NONE)
+	defer sw.Close(context.Background())
+
+	_, err = sw.Write([]byte("datagram"))
+	require.NoError(t, err)
+
+	_ = pc.SetReadDeadline(time.Now().Add(time.Second))
+	buf := make([]byte, 64)
+	n, _, err := pc.ReadFrom(buf)
+	require.NoError(t, err)
+	assert.Equal(t, "datagram", string(buf[:n]))
+}
@@ -0,0 +1,210 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekatyp"
+	"github.com/qioalice/ekago/v3/internal/ekaclike"
+)
+
+type (
+	// TimeoutWriter is an io.Writer wrapper, that abandons the write
+	// to the wrapped io.Writer if it takes more than the timeout,
+	// so a hung destination (NFS mount, blocked pipe, etc) cannot stall
+	// the logging forever.
+	//
+	// The abandoned write keeps going in background, and until it's done,
+	// all next writes are abandoned immediately (w/o waiting the timeout).
+	// Abandoned writes are counted (see Abandoned()) and their data is written
+	// to the fallback io.Writer, if it's set (see SetFallback()).
+	//
+	// TimeoutWriter implements CI_WriterFlusher and CI_WriterCloser,
+	// so CommonIntegrator.Flush(), CommonIntegrator.Close() are passed
+	// to the wrapped io.Writer.
+	//
+	// Use WrapWriterWithTimeout() to create it.
+	TimeoutWriter struct {
+		w        io.Writer
+		timeout  time.Duration
+		fallback io.Writer
+
+		writeMu sync.Mutex // serializes Write() calls
+		busyMu  sync.Mutex // locked while the write to 'w' is in progress
+
+		abandoned uint64 // atomic access only
+	}
+)
+
+var (
+	// ErrWriteTimeout is returned by TimeoutWriter.Write() if the write
+	// is abandoned and there's no fallback io.Writer.
+	ErrWriteTimeout = errors.New("ekalog: write is abandoned by timeout")
+)
+
+var (
+	// Make sure we won't break API.
+	_ CI_WriterFlusher = (*TimeoutWriter)(nil)
+	_ CI_WriterCloser  = (*TimeoutWriter)(nil)
+)
+
+// WrapWriterWithTimeout returns a new TimeoutWriter, that abandons the write
+// to 'w' if it takes more than 'timeout'. Non-positive 'timeout' means
+// no timeout, but the write still is abandoned if the previous one
+// is in progress (e.g. it's abandoned by the context, see WriteContext()).
+// Panics if 'w' is nil.
+func WrapWriterWithTimeout(w io.Writer, timeout time.Duration) *TimeoutWriter {
+
+	if ekaclike.TakeRealAddr(w) == nil {
+		panic("ekalog: WrapWriterWithTimeout: io.Writer is nil")
+	}
+
+	return &TimeoutWriter{w: w, timeout: timeout}
+}
+
+// SetFallback sets the io.Writer the data of abandoned writes is written to.
+// Nil means there's no fallback io.Writer (the data is lost).
+//
+// This method MUST NOT be called after the first Write() call.
+func (tw *TimeoutWriter) SetFallback(fallback io.Writer) *TimeoutWriter {
+	tw.fallback = fallback
+	return tw
+}
+
+// Abandoned returns the number of abandoned writes.
+func (tw *TimeoutWriter) Abandoned() uint64 {
+	return atomic.LoadUint64(&tw.abandoned)
+}
+
+// Write writes 'p' to the wrapped io.Writer waiting at most the timeout.
+// Read more: TimeoutWriter, WriteContext().
+func (tw *TimeoutWriter) Write(p []byte) (int, error) {
+	return tw.WriteContext(context.Background(), p)
+}
+
+// WriteContext is the same as Write() but the write is also abandoned
+// if 'ctx' is done before it's completed.
+//
+// If the write is abandoned, the result of fallback io.Writer's Write()
+// is returned, or ErrWriteTimeout if there's no fallback io.Writer.
+func (tw *TimeoutWriter) WriteContext(ctx context.Context, p []byte) (int, error) {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	tw.writeMu.Lock()
+	defer tw.writeMu.Unlock()
+
+	// The previous write is not completed yet.
+	if !tw.busyMu.TryLock() {
+		return tw.abandon(p)
+	}
+
+	if tw.timeout <= 0 && ctx.Done() == nil {
+		defer tw.busyMu.Unlock()
+		return tw.w.Write(p)
+	}
+
+	// The data must be copied, because the write may be abandoned,
+	// but it continues in background, while 'p' will be reused by the caller.
+	data := make([]byte, len(p))
+	copy(data, p)
+
+	type result struct {
+		n   int
+		err error
+	}
+
+	done := make(chan result, 1)
+	go func() {
+		defer tw.busyMu.Unlock()
+		n, err := tw.w.Write(data)
+		done <- result{n, err}
+	}()
+
+	var timeoutCh <-chan time.Time
+	if tw.timeout > 0 {
+		timer := time.NewTimer(tw.timeout)
+		defer timer.Stop()
+		timeoutCh = timer.C
+	}
+
+	select {
+	case r := <-done:
+		return r.n, r.err
+	case <-timeoutCh:
+	case <-ctx.Done():
+	}
+
+	return tw.abandon(p)
+}
+
+// Flush waits until the write in progress (if any) is completed,
+// and then flushes the wrapped io.Writer if it implements CI_WriterFlusher
+// or ekatyp.Syncer. Returns ctx.Err() if 'ctx' is done before.
+func (tw *TimeoutWriter) Flush(ctx context.Context) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return ciCallWithContext(ctx, func() error {
+		tw.busyMu.Lock()
+		defer tw.busyMu.Unlock()
+
+		switch typedW := tw.w.(type) {
+		case CI_WriterFlusher:
+			return typedW.Flush(ctx)
+		case ekatyp.Syncer:
+			return typedW.Sync()
+		}
+		return nil
+	})
+}
+
+// Close waits until the write in progress (if any) is completed,
+// and then closes the wrapped io.Writer if it implements CI_WriterCloser
+// or io.Closer. Returns ctx.Err() if 'ctx' is done before.
+// The fallback io.Writer is not closed.
+func (tw *TimeoutWriter) Close(ctx context.Context) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	return ciCallWithContext(ctx, func() error {
+		tw.busyMu.Lock()
+		defer tw.busyMu.Unlock()
+
+		switch typedW := tw.w.(type) {
+		case CI_WriterCloser:
+			return typedW.Close(ctx)
+		case io.Closer:
+			return typedW.Close()
+		}
+		return nil
+	})
+}
+
+// abandon counts the abandoned write of 'p' and writes it to the fallback
+// io.Writer (if any).
+func (tw *TimeoutWriter) abandon(p []byte) (int, error) {
+
+	atomic.AddUint64(&tw.abandoned, 1)
+
+	if tw.fallback == nil {
+		return 0, ErrWriteTimeout
+	}
+
+	return tw.fallback.Write(p)
+}
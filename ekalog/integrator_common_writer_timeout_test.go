@@ -0,0 +1,76 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/require"
+)
+
+type tTimeoutWriterHung struct {
+	unblock chan struct{}
+	buf     bytes.Buffer
+}
+
+func (w *tTimeoutWriterHung) Write(p []byte) (int, error) {
+	<-w.unblock
+	return w.buf.Write(p)
+}
+
+func TestTimeoutWriter(t *testing.T) {
+
+	hung := &tTimeoutWriterHung{unblock: make(chan struct{})}
+	fallback := new(bytes.Buffer)
+
+	tw := ekalog.WrapWriterWithTimeout(hung, 20*time.Millisecond).SetFallback(fallback)
+
+	n, err := tw.Write([]byte("first"))
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+
+	// The first write is still in progress, so it's abandoned immediately.
+	_, err = tw.Write([]byte("second"))
+	require.NoError(t, err)
+
+	require.EqualValues(t, 2, tw.Abandoned())
+	require.Equal(t, "firstsecond", fallback.String())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	require.True(t, errors.Is(tw.Flush(ctx), context.DeadlineExceeded))
+
+	close(hung.unblock)
+	require.NoError(t, tw.Flush(context.Background()))
+	require.Equal(t, "first", hung.buf.String())
+
+	n, err = tw.Write([]byte("third"))
+	require.NoError(t, err)
+	require.Equal(t, 5, n)
+	require.Equal(t, "firstthird", hung.buf.String())
+	require.EqualValues(t, 2, tw.Abandoned())
+}
+
+func TestTimeoutWriter_NoFallback(t *testing.T) {
+
+	hung := &tTimeoutWriterHung{unblock: make(chan struct{})}
+	defer close(hung.unblock)
+
+	tw := ekalog.WrapWriterWithTimeout(hung, 0)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := tw.WriteContext(ctx, []byte("data"))
+	require.True(t, errors.Is(err, ekalog.ErrWriteTimeout))
+	require.EqualValues(t, 1, tw.Abandoned())
+}
@@ -0,0 +1,227 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekadeath"
+	"github.com/qioalice/ekago/v3/internal/ekaclike"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// CrashIntegrator is an Integrator wrapper, that keeps the last N encoded
+	// Entry of ALL levels in the in-memory ring buffer, passing Entry to the
+	// wrapped Integrator only if its Level is enabled by the wrapped one.
+	//
+	// The ring buffer is dumped to the crash file (see SetCrashFile())
+	// along with the stacktrace:
+	//   - on panic, if Recover() is deferred;
+	//   - on ekadeath.Die() with non-zero exit code (e.g. after LEVEL_EMERGENCY log),
+	//     the hook is registered by NewCrashIntegrator().
+	//
+	// So, you will get the recent LEVEL_DEBUG context of the crash,
+	// even if it's not persisted normally.
+	//
+	// Use NewCrashIntegrator() to create it.
+	CrashIntegrator struct {
+		Integrator
+
+		encoder CI_Encoder
+		path    string
+
+		mu    sync.Mutex
+		ring  [][]byte
+		next  int  // index of ring's element the next Entry will be written to
+		full  bool // whether ring has been overflowed at least once
+		dumps int  // number of performed dumps (to avoid dumping twice)
+	}
+)
+
+// CRASH_INTEGRATOR_DEFAULT_CAPACITY is a default number of Entry
+// CrashIntegrator keeps if a non-positive capacity is passed.
+//
+//goland:noinspection GoSnakeCaseUsage
+const CRASH_INTEGRATOR_DEFAULT_CAPACITY = 256
+
+// NewCrashIntegrator returns a new CrashIntegrator wrapping 'origin' Integrator,
+// that keeps the last 'capacity' Entry encoded by 'encoder'.
+// If 'encoder' is nil, a new CI_ConsoleEncoder with the default format is used.
+// The encoder MUST NOT be shared with another Integrator.
+// Registers ekadeath hook that dumps the ring buffer if the app is going down
+// with non-zero exit code. Panics if 'origin' is nil.
+func NewCrashIntegrator(origin Integrator, encoder CI_Encoder, capacity int) *CrashIntegrator {
+
+	if ekaclike.TakeRealAddr(origin) == nil {
+		panic("ekalog: NewCrashIntegrator: origin Integrator is nil")
+	}
+
+	switch encTyped := encoder.(type) {
+	case nil:
+		encoder = new(CI_ConsoleEncoder).doBuild()
+	case *CI_ConsoleEncoder:
+		encTyped.doBuild()
+	case *CI_JSONEncoder:
+		encTyped.doBuild()
+	}
+
+	if capacity <= 0 {
+		capacity = CRASH_INTEGRATOR_DEFAULT_CAPACITY
+	}
+
+	ci := &CrashIntegrator{
+		Integrator: origin,
+		encoder:    encoder,
+		path:       filepath.Join(os.TempDir(), "ekalog_crash.log"),
+		ring:       make([][]byte, capacity),
+	}
+
+	ekadeath.Reg(func(code int) {
+		if code != 0 {
+			_ = ci.DumpCrash(fmt.Sprintf("ekadeath.Die(%d)", code), nil)
+		}
+	})
+
+	return ci
+}
+
+// SetCrashFile sets the path of the file the ring buffer will be dumped to.
+// The file is truncated if it exists.
+// By default, it's "ekalog_crash.log" in the os.TempDir().
+func (ci *CrashIntegrator) SetCrashFile(path string) *CrashIntegrator {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	ci.path = path
+	return ci
+}
+
+// PreEncodeField pre-encodes 'f' by both of CrashIntegrator's encoder
+// and the wrapped Integrator.
+func (ci *CrashIntegrator) PreEncodeField(f ekaletter.LetterField) {
+	ci.mu.Lock()
+	ci.encoder.PreEncodeField(f)
+	ci.mu.Unlock()
+	ci.Integrator.PreEncodeField(f)
+}
+
+// EncodeAndWrite encodes 'entry' to the ring buffer and passes it
+// to the wrapped Integrator if it's enabled by them.
+func (ci *CrashIntegrator) EncodeAndWrite(entry *Entry) {
+
+	ci.mu.Lock()
+	if encoded := ci.encoder.EncodeEntry(entry); len(encoded) > 0 {
+		ci.ring[ci.next] = append(ci.ring[ci.next][:0], encoded...)
+		if ci.next++; ci.next == len(ci.ring) {
+			ci.next = 0
+			ci.full = true
+		}
+	}
+	ci.mu.Unlock()
+
+	if entry.Level.IsEnabledFor(ci.Integrator.MinLevelEnabled()) {
+		ci.Integrator.EncodeAndWrite(entry)
+	}
+}
+
+// MinLevelEnabled returns LEVEL_DEBUG, since CrashIntegrator keeps Entry of all levels.
+func (ci *CrashIntegrator) MinLevelEnabled() Level {
+	return LEVEL_DEBUG
+}
+
+// WriteTo writes all kept encoded Entry to 'w' from the oldest to the newest,
+// one per line.
+// Implements io.WriterTo.
+func (ci *CrashIntegrator) WriteTo(w io.Writer) (int64, error) {
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+	return ci.writeTo(w)
+}
+
+// DumpCrash writes the crash file with 'reason', 'stack' (the current goroutine's
+// stack is used if it's nil) and all kept encoded Entry.
+// Only the first call does something, next ones are no-op.
+func (ci *CrashIntegrator) DumpCrash(reason any, stack []byte) error {
+
+	ci.mu.Lock()
+	defer ci.mu.Unlock()
+
+	if ci.dumps++; ci.dumps > 1 {
+		return nil
+	}
+
+	if stack == nil {
+		stack = debug.Stack()
+	}
+
+	f, err := os.Create(ci.path)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(f, "CRASH at %s: %v\n\n%s\n\nLAST LOG ENTRIES:\n\n",
+		time.Now().Format(time.RFC3339Nano), reason, stack)
+	if err == nil {
+		_, err = ci.writeTo(f)
+	}
+	if errClose := f.Close(); err == nil {
+		err = errClose
+	}
+
+	return err
+}
+
+// Recover must be deferred (directly: defer ci.Recover()) at the start
+// of the goroutine to catch its panic. If there's a panic, dumps the crash file
+// (see DumpCrash()) and then panics again with the same value.
+func (ci *CrashIntegrator) Recover() {
+	if r := recover(); r != nil {
+		_ = ci.DumpCrash(r, debug.Stack())
+		panic(r)
+	}
+}
+
+// unwrap returns the wrapped Integrator. Implements integratorWrapper.
+func (ci *CrashIntegrator) unwrap() Integrator {
+	return ci.Integrator
+}
+
+// writeTo is WriteTo() w/o locking.
+// Encoded Entry that has no trailing new line is followed by the one.
+func (ci *CrashIntegrator) writeTo(w io.Writer) (int64, error) {
+
+	var total int64
+
+	writeRange := func(entries [][]byte) error {
+		for i := range entries {
+			n, err := w.Write(entries[i])
+			if total += int64(n); err != nil {
+				return err
+			}
+			if l := len(entries[i]); l > 0 && entries[i][l-1] != '\n' {
+				n, err = w.Write([]byte{'\n'})
+				if total += int64(n); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}
+
+	if ci.full {
+		if err := writeRange(ci.ring[ci.next:]); err != nil {
+			return total, err
+		}
+	}
+
+	return total, writeRange(ci.ring[:ci.next])
+}
@@ -0,0 +1,56 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCrashIntegrator(t *testing.T) {
+
+	b := bytes.NewBuffer(nil)
+	origin := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m}}")).
+		WithMinLevel(ekalog.LEVEL_WARNING).
+		WriteTo(b)
+
+	crashFile := filepath.Join(t.TempDir(), "crash.log")
+	ci := ekalog.NewCrashIntegrator(origin, new(ekalog.CI_ConsoleEncoder).SetFormat("{{l}} {{m}}"), 2).
+		SetCrashFile(crashFile)
+
+	ekalog.ReplaceIntegrator(ci)
+
+	ekalog.Debug("first")
+	ekalog.Debug("second")
+	ekalog.Warn("third")
+
+	assert.Equal(t, "third", b.String())
+
+	var dump bytes.Buffer
+	_, err := ci.WriteTo(&dump)
+	require.NoError(t, err)
+	assert.Equal(t, "Debug second\nWarning third\n", dump.String())
+
+	assert.Panics(t, func() {
+		defer ci.Recover()
+		panic("boom")
+	})
+
+	content, err := os.ReadFile(crashFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "CRASH at ")
+	assert.Contains(t, string(content), ": boom")
+	assert.Contains(t, string(content), "TestCrashIntegrator")
+	assert.True(t, bytes.HasSuffix(content, []byte("Debug second\nWarning third\n")))
+}
@@ -0,0 +1,95 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"github.com/qioalice/ekago/v3/ekasys"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// ResourceUsageIntegrator is an Integrator wrapper, that attaches
+	// the current process' resources usage (see ekasys.GetResourceUsage())
+	// as fields to the each Entry of LEVEL_ERROR or more severe
+	// (configurable by SetMinLevel()) and passes it to the wrapped Integrator.
+	//
+	// It's useful to capture resources state at the failure time.
+	// The fields are:
+	//   - "resource.rss", "resource.heap_alloc" (in bytes), "resource.heap_objects", "resource.goroutines";
+	//   - "resource.open_fds" (if it's supported by the current platform);
+	//   - "resource.disk_free", "resource.disk_available" (in bytes, if SetDiskPath() is used).
+	//
+	// Use NewResourceUsageIntegrator() to create it.
+	ResourceUsageIntegrator struct {
+		Integrator
+		minLevel Level
+		diskPath string
+	}
+)
+
+// NewResourceUsageIntegrator returns a new ResourceUsageIntegrator
+// wrapping 'origin' Integrator. Panics if 'origin' is nil.
+func NewResourceUsageIntegrator(origin Integrator) *ResourceUsageIntegrator {
+	if origin == nil {
+		panic("ekalog: NewResourceUsageIntegrator: origin Integrator is nil")
+	}
+	return &ResourceUsageIntegrator{
+		Integrator: origin,
+		minLevel:   LEVEL_ERROR,
+	}
+}
+
+// SetMinLevel sets the least severe Level, the Entry of which will have
+// resources usage fields attached. LEVEL_ERROR by default.
+func (ri *ResourceUsageIntegrator) SetMinLevel(level Level) *ResourceUsageIntegrator {
+	ri.minLevel = level
+	return ri
+}
+
+// SetDiskPath sets the path the disk usage (see ekasys.GetDiskUsage()) of which
+// will be attached too. Empty 'path' (the default) disables disk usage fields.
+func (ri *ResourceUsageIntegrator) SetDiskPath(path string) *ResourceUsageIntegrator {
+	ri.diskPath = path
+	return ri
+}
+
+// EncodeAndWrite attaches resources usage fields to 'entry' (if it's severe enough)
+// and passes it to the wrapped Integrator.
+func (ri *ResourceUsageIntegrator) EncodeAndWrite(entry *Entry) {
+
+	if entry.Level.IsEnabledFor(ri.minLevel) {
+		ru := ekasys.GetResourceUsage()
+
+		// Fields may be the user's slice (explicit fields of a finisher),
+		// so its memory must not be overwritten.
+		fs := entry.LogLetter.Fields
+		fs = append(fs[:len(fs):len(fs)],
+			ekaletter.FUint64("resource.rss", ru.RSS),
+			ekaletter.FUint64("resource.heap_alloc", ru.HeapAlloc),
+			ekaletter.FUint64("resource.heap_objects", ru.HeapObjects),
+			ekaletter.FUint64("resource.goroutines", ru.Goroutines),
+		)
+		if ru.OpenFDs >= 0 {
+			fs = append(fs, ekaletter.FInt("resource.open_fds", ru.OpenFDs))
+		}
+		if ri.diskPath != "" {
+			if du, err := ekasys.GetDiskUsage(ri.diskPath); err == nil {
+				fs = append(fs,
+					ekaletter.FUint64("resource.disk_free", du.Free),
+					ekaletter.FUint64("resource.disk_available", du.Available),
+				)
+			}
+		}
+		entry.LogLetter.Fields = fs
+	}
+
+	ri.Integrator.EncodeAndWrite(entry)
+}
+
+// unwrap returns the wrapped Integrator. Implements integratorWrapper.
+func (ri *ResourceUsageIntegrator) unwrap() Integrator {
+	return ri.Integrator
+}
@@ -0,0 +1,46 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResourceUsageIntegrator(t *testing.T) {
+
+	b := bytes.NewBuffer(nil)
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_JSONEncoder)).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(b)
+
+	ekalog.ReplaceIntegrator(ekalog.NewResourceUsageIntegrator(ci))
+
+	var out map[string]any
+
+	ekalog.Info("info", "k", 1)
+	require.NoError(t, json.Unmarshal(b.Bytes(), &out))
+	assert.Equal(t, map[string]any{"k": float64(1)}, out["fields"])
+
+	b.Reset()
+	out = nil
+
+	ekalog.Error("error", "k", 1)
+	require.NoError(t, json.Unmarshal(b.Bytes(), &out))
+	require.IsType(t, map[string]any{}, out["fields"])
+
+	fields := out["fields"].(map[string]any)
+	assert.Contains(t, fields, "resource.heap_alloc")
+	assert.Contains(t, fields, "resource.goroutines")
+	assert.Equal(t, float64(1), fields["k"])
+}
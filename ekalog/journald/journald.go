@@ -0,0 +1,191 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package journald
+
+import (
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Encoder is an ekalog.CI_Encoder, that encodes ekalog.Entry
+	// using systemd-journald's native protocol:
+	//
+	//   - MESSAGE is log's message (or attached ekaerr.Error's one);
+	//   - PRIORITY is ekalog.Level (they have the same syslog's values);
+	//   - SYSLOG_IDENTIFIER is the identifier (see SetIdentifier());
+	//   - CODE_FILE, CODE_LINE, CODE_FUNC are the caller's ones (if any);
+	//   - ERROR_CLASS, ERROR_ID are attached ekaerr.Error's ones (if any);
+	//   - log's and ekaerr.Error's fields are journal's variables
+	//     with uppercased keys (read more: FieldKey()).
+	//
+	// Use it with Writer as a part of ekalog.CommonIntegrator:
+	//
+	//	w, err := journald.NewWriter()
+	//	ci := new(ekalog.CommonIntegrator).
+	//	    WithEncoder(new(journald.Encoder)).WriteTo(w)
+	Encoder struct {
+		identifier string
+
+		preEncoded []byte
+	}
+
+	// Writer is an io.Writer, that sends journal entries (encoded by Encoder)
+	// to the systemd-journald's socket. Entries, that are too big
+	// for the datagram, are passed using the sealed memory file descriptor.
+	//
+	// Writer MUST be created by NewWriter().
+	Writer struct {
+		mu   sync.Mutex
+		conn *net.UnixConn
+	}
+)
+
+// SOCKET_PATH is a path of systemd-journald's native protocol socket.
+//
+//goland:noinspection GoSnakeCaseUsage
+const SOCKET_PATH = "/run/systemd/journal/socket"
+
+var (
+	ErrNotSupported = errors.New("ekalog/journald: systemd-journald is not supported on this platform")
+	ErrClosed       = errors.New("ekalog/journald: writer is closed")
+)
+
+var (
+	// Make sure we won't break API.
+	_ ekalog.CI_Encoder = (*Encoder)(nil)
+)
+
+// IsAvailable reports whether systemd-journald's socket exists,
+// e.g. the app is running under systemd.
+func IsAvailable() bool {
+	_, err := os.Stat(SOCKET_PATH)
+	return err == nil
+}
+
+// FieldKey returns the journal's variable name for the field's 'key':
+// it's uppercased, all chars but latin letters, digits and underscores
+// are replaced by underscores, leading non-letters are removed (journald
+// reserves them), and it's truncated to 64 chars.
+// Returns an empty string if there's nothing left.
+func FieldKey(key string) string {
+
+	b := make([]byte, 0, len(key))
+	for i := 0; i < len(key) && len(b) < 64; i++ {
+		c := key[i]
+		switch {
+		case c >= 'a' && c <= 'z':
+			c -= 'a' - 'A'
+		case c >= 'A' && c <= 'Z':
+		case len(b) == 0:
+			continue
+		case c >= '0' && c <= '9', c == '_':
+		default:
+			c = '_'
+		}
+		b = append(b, c)
+	}
+
+	return string(b)
+}
+
+// SetIdentifier sets journal's SYSLOG_IDENTIFIER.
+// By default, it's the base name of the executable.
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (je *Encoder) SetIdentifier(identifier string) *Encoder {
+	je.identifier = identifier
+	return je
+}
+
+// PreEncodeField encodes ekaletter.LetterField once as journal's variable,
+// that then is added to the each journal entry. Unnamed fields are ignored.
+//
+// PreEncodeField is for internal purposes only and MUST NOT be called directly.
+func (je *Encoder) PreEncodeField(f ekaletter.LetterField) {
+	if f.Key != "" && !f.IsSystem() && !f.IsInvalid() {
+		je.preEncoded = appendField(je.preEncoded, &f, nil)
+	}
+}
+
+// EncodeEntry encodes passed ekalog.Entry as journal entry
+// using systemd-journald's native protocol.
+//
+// EncodeEntry is for internal purposes only and MUST NOT be called directly.
+// UB otherwise, may panic.
+func (je *Encoder) EncodeEntry(e *ekalog.Entry) []byte {
+
+	identifier := je.identifier
+	if identifier == "" {
+		identifier = filepath.Base(os.Args[0])
+	}
+
+	return je.encode(e, identifier)
+}
+
+// NewWriter connects to the systemd-journald's socket and returns a new Writer.
+// Returns ErrNotSupported if it's not Linux.
+func NewWriter() (*Writer, error) {
+	return NewWriterAt(SOCKET_PATH)
+}
+
+// NewWriterAt is the same as NewWriter() but connects to the socket
+// at the provided 'socketPath' instead of SOCKET_PATH.
+func NewWriterAt(socketPath string) (*Writer, error) {
+
+	conn, err := dial(socketPath)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Writer{conn: conn}, nil
+}
+
+// Write sends journal entry 'p' (encoded by Encoder) to the systemd-journald.
+// Empty 'p' is ignored.
+func (w *Writer) Write(p []byte) (int, error) {
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return 0, ErrClosed
+	}
+
+	if err := send(w.conn, p); err != nil {
+		return 0, err
+	}
+
+	return len(p), nil
+}
+
+// Close closes the connection to the systemd-journald.
+// The next calls of Close() are no-op.
+func (w *Writer) Close() error {
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.conn == nil {
+		return nil
+	}
+
+	err := w.conn.Close()
+	w.conn = nil
+
+	return err
+}
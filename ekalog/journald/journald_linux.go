@@ -0,0 +1,130 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build linux
+
+package journald
+
+import (
+	"errors"
+	"net"
+	"os"
+	"runtime"
+	"syscall"
+	"unsafe"
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	_MFD_CLOEXEC       = 0x1
+	_MFD_ALLOW_SEALING = 0x2
+
+	_F_ADD_SEALS   = 1033
+	_F_SEAL_SEAL   = 0x1
+	_F_SEAL_SHRINK = 0x2
+	_F_SEAL_GROW   = 0x4
+	_F_SEAL_WRITE  = 0x8
+)
+
+// dial connects to the systemd-journald's socket.
+func dial(socketPath string) (*net.UnixConn, error) {
+	return net.DialUnix("unixgram", nil, &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+}
+
+// send sends journal entry 'p' to the systemd-journald using 'conn'.
+// If 'p' is too big for the datagram, it's written to the memory file,
+// which descriptor is sent instead.
+func send(conn *net.UnixConn, p []byte) error {
+
+	_, err := conn.Write(p)
+	if err == nil || !errors.Is(err, syscall.EMSGSIZE) && !errors.Is(err, syscall.ENOBUFS) {
+		return err
+	}
+
+	f, err := memFile(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// net.UnixConn.WriteMsgUnix() can't be used with the connected datagram socket.
+	rc, err := conn.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	rights := syscall.UnixRights(int(f.Fd()))
+	errCtl := rc.Write(func(fd uintptr) bool {
+		err = syscall.Sendmsg(int(fd), nil, rights, nil, 0)
+		return err != syscall.EAGAIN
+	})
+	if errCtl != nil {
+		return errCtl
+	}
+
+	return err
+}
+
+// memFile returns a sealed memfd with 'p' as its content,
+// or an unlinked temporary file in /dev/shm if memfd is not supported
+// (both are accepted by systemd-journald).
+func memFile(p []byte) (*os.File, error) {
+
+	if trap := memfdCreateTrap(); trap != 0 {
+		name := []byte("ekalog-journald\x00")
+		fd, _, errno := syscall.Syscall(trap,
+			uintptr(unsafe.Pointer(&name[0])), _MFD_CLOEXEC|_MFD_ALLOW_SEALING, 0)
+		runtime.KeepAlive(name)
+
+		if errno == 0 {
+			f := os.NewFile(fd, "memfd:ekalog-journald")
+			if _, err := f.Write(p); err != nil {
+				_ = f.Close()
+				return nil, err
+			}
+			_, _, errno = syscall.Syscall(syscall.SYS_FCNTL, fd, _F_ADD_SEALS,
+				_F_SEAL_SEAL|_F_SEAL_SHRINK|_F_SEAL_GROW|_F_SEAL_WRITE)
+			if errno != 0 {
+				_ = f.Close()
+				return nil, errno
+			}
+			return f, nil
+		}
+	}
+
+	f, err := os.CreateTemp("/dev/shm", "ekalog-journald-")
+	if err != nil {
+		return nil, err
+	}
+	_ = os.Remove(f.Name())
+
+	if _, err = f.Write(p); err != nil {
+		_ = f.Close()
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// memfdCreateTrap returns the number of memfd_create syscall
+// for the current architecture or 0 if it's unknown.
+func memfdCreateTrap() uintptr {
+	switch runtime.GOARCH {
+	case "amd64":
+		return 319
+	case "arm64", "riscv64", "loong64":
+		return 279
+	case "386":
+		return 356
+	case "arm":
+		return 385
+	case "ppc64", "ppc64le":
+		return 360
+	case "s390x":
+		return 350
+	default:
+		return 0
+	}
+}
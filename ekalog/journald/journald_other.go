@@ -0,0 +1,20 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build !linux
+
+package journald
+
+import (
+	"net"
+)
+
+func dial(_ string) (*net.UnixConn, error) {
+	return nil, ErrNotSupported
+}
+
+func send(_ *net.UnixConn, _ []byte) error {
+	return ErrNotSupported
+}
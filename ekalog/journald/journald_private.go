@@ -0,0 +1,155 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package journald
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// encode encodes ekalog.Entry as journal entry.
+func (je *Encoder) encode(e *ekalog.Entry, identifier string) []byte {
+
+	b := make([]byte, 0, 256+len(je.preEncoded))
+
+	message := e.LogLetter.Messages[0].Body
+	if message == "" && e.ErrLetter != nil {
+		for i := len(e.ErrLetter.Messages) - 1; i >= 0 && message == ""; i-- {
+			message = e.ErrLetter.Messages[i].Body
+		}
+	}
+
+	b = appendVar(b, "MESSAGE", message)
+	b = appendVar(b, "PRIORITY", strconv.Itoa(int(e.Level.Base())))
+	b = appendVar(b, "SYSLOG_IDENTIFIER", identifier)
+
+	if frame := e.Caller(); frame != nil {
+		b = appendVar(b, "CODE_FILE", frame.File)
+		b = appendVar(b, "CODE_LINE", strconv.Itoa(frame.Line))
+		b = appendVar(b, "CODE_FUNC", frame.Function)
+	}
+
+	b = append(b, je.preEncoded...)
+
+	var unnamedFieldIdx int16
+	b = appendFields(b, e.LogLetter.Fields, &unnamedFieldIdx)
+
+	if e.ErrLetter != nil {
+		if class := systemField(e.ErrLetter, ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME); class != "" {
+			b = appendVar(b, "ERROR_CLASS", class)
+		}
+		if errorID := systemField(e.ErrLetter, ekaletter.KIND_SYS_TYPE_EKAERR_UUID); errorID != "" {
+			b = appendVar(b, "ERROR_ID", errorID)
+		}
+		b = appendFields(b, e.ErrLetter.Fields, &unnamedFieldIdx)
+	}
+
+	return b
+}
+
+// appendFields appends 'fields' to 'b' as journal's variables.
+func appendFields(b []byte, fields []ekaletter.LetterField, unnamedFieldIdx *int16) []byte {
+	for i, n := 0, len(fields); i < n; i++ {
+		if !fields[i].IsSystem() && !fields[i].IsInvalid() {
+			b = appendField(b, &fields[i], unnamedFieldIdx)
+		}
+	}
+	return b
+}
+
+// appendField appends 'f' to 'b' as journal's variable.
+// The field is skipped if its key has nothing of allowed chars.
+func appendField(b []byte, f *ekaletter.LetterField, unnamedFieldIdx *int16) []byte {
+	if key := FieldKey(f.KeyOrUnnamed(unnamedFieldIdx)); key != "" {
+		b = appendVar(b, key, fieldValue(f))
+	}
+	return b
+}
+
+// appendVar appends journal's variable to 'b' using the native protocol:
+// "KEY=value\n" or, if 'value' contains a new line,
+// "KEY\n<little endian uint64 length of value>value\n".
+func appendVar(b []byte, key, value string) []byte {
+
+	b = append(b, key...)
+
+	if strings.IndexByte(value, '\n') == -1 {
+		b = append(b, '=')
+	} else {
+		var l [8]byte
+		binary.LittleEndian.PutUint64(l[:], uint64(len(value)))
+		b = append(append(b, '\n'), l[:]...)
+	}
+
+	b = append(b, value...)
+	return append(b, '\n')
+}
+
+// systemField returns a string value of ekaletter.Letter's system field
+// with provided 'baseType' or an empty string if there is no such field.
+func systemField(l *ekaletter.Letter, baseType ekaletter.LetterFieldKind) string {
+	for i, n := 0, len(l.SystemFields); i < n; i++ {
+		if l.SystemFields[i].BaseType() == baseType {
+			return l.SystemFields[i].SValue
+		}
+	}
+	return ""
+}
+
+// fieldValue returns a string value of ekaletter.LetterField.
+func fieldValue(f *ekaletter.LetterField) string {
+
+	if f.Kind.IsNil() {
+		return ""
+	}
+
+	switch f.Kind.BaseType() {
+
+	case ekaletter.KIND_TYPE_BOOL:
+		return strconv.FormatBool(f.IValue != 0)
+
+	case ekaletter.KIND_TYPE_INT,
+		ekaletter.KIND_TYPE_INT_8, ekaletter.KIND_TYPE_INT_16,
+		ekaletter.KIND_TYPE_INT_32, ekaletter.KIND_TYPE_INT_64:
+		return strconv.FormatInt(f.IValue, 10)
+
+	case ekaletter.KIND_TYPE_UINT,
+		ekaletter.KIND_TYPE_UINT_8, ekaletter.KIND_TYPE_UINT_16,
+		ekaletter.KIND_TYPE_UINT_32, ekaletter.KIND_TYPE_UINT_64:
+		return strconv.FormatUint(uint64(f.IValue), 10)
+
+	case ekaletter.KIND_TYPE_FLOAT_32:
+		return strconv.FormatFloat(float64(math.Float32frombits(uint32(f.IValue))), 'g', -1, 32)
+
+	case ekaletter.KIND_TYPE_FLOAT_64:
+		return strconv.FormatFloat(math.Float64frombits(uint64(f.IValue)), 'g', -1, 64)
+
+	case ekaletter.KIND_TYPE_UINTPTR, ekaletter.KIND_TYPE_ADDR:
+		return "0x" + strconv.FormatUint(uint64(f.IValue), 16)
+
+	case ekaletter.KIND_TYPE_STRING:
+		return f.SValue
+
+	case ekaletter.KIND_TYPE_UNIX:
+		return time.Unix(f.IValue, 0).UTC().Format(time.RFC3339)
+
+	case ekaletter.KIND_TYPE_UNIX_NANO:
+		return time.Unix(0, f.IValue).UTC().Format(time.RFC3339Nano)
+
+	case ekaletter.KIND_TYPE_DURATION:
+		return time.Duration(f.IValue).String()
+
+	default:
+		return fmt.Sprint(f.Value)
+	}
+}
@@ -0,0 +1,107 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build linux
+
+package journald_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekalog/journald"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFieldKey(t *testing.T) {
+	assert.Equal(t, "USER_ID", journald.FieldKey("user.id"))
+	assert.Equal(t, "REQUEST_ID", journald.FieldKey("_1request-id"))
+	assert.Equal(t, "", journald.FieldKey("_123"))
+	assert.Len(t, journald.FieldKey(strings.Repeat("a", 100)), 64)
+}
+
+// receive reads one journal entry from 'conn', either from the datagram
+// or from the passed file descriptor.
+func receive(t *testing.T, conn *net.UnixConn) []byte {
+
+	buf, oob := make([]byte, 1<<20), make([]byte, 1024)
+	n, oobn, _, _, err := conn.ReadMsgUnix(buf, oob)
+	require.NoError(t, err)
+
+	if oobn == 0 {
+		return buf[:n]
+	}
+
+	msgs, err := syscall.ParseSocketControlMessage(oob[:oobn])
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+
+	fds, err := syscall.ParseUnixRights(&msgs[0])
+	require.NoError(t, err)
+	require.Len(t, fds, 1)
+
+	f := os.NewFile(uintptr(fds[0]), "journal")
+	defer f.Close()
+
+	// journald reads the file from the start, regardless its offset.
+	var data bytes.Buffer
+	_, err = data.ReadFrom(io.NewSectionReader(f, 0, 1<<30))
+	require.NoError(t, err)
+
+	return data.Bytes()
+}
+
+func TestWriter(t *testing.T) {
+
+	socketPath := filepath.Join(t.TempDir(), "journal.sock")
+	srv, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socketPath, Net: "unixgram"})
+	require.NoError(t, err)
+	defer srv.Close()
+
+	w, err := journald.NewWriterAt(socketPath)
+	require.NoError(t, err)
+
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(journald.Encoder).SetIdentifier("test")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(w)
+
+	ekalog.ReplaceIntegrator(ci)
+
+	ekalog.Warne("Failed", ekaerr.IllegalArgument.New("bad value").WithInt("value", 42),
+		"user.name", "alice", "text", "line 1\nline 2")
+
+	entry := receive(t, srv)
+	assert.Contains(t, string(entry), "MESSAGE=Failed\n")
+	assert.Contains(t, string(entry), "PRIORITY=4\n")
+	assert.Contains(t, string(entry), "SYSLOG_IDENTIFIER=test\n")
+	assert.Contains(t, string(entry), "USER_NAME=alice\n")
+	assert.Contains(t, string(entry), "VALUE=42\n")
+	assert.Contains(t, string(entry), "ERROR_CLASS=")
+	assert.Contains(t, string(entry), "TEXT\n\x0d\x00\x00\x00\x00\x00\x00\x00line 1\nline 2\n")
+
+	// Too big for the datagram, must be passed using the file descriptor.
+	big := strings.Repeat("x", 4<<20)
+	ekalog.Info("Big", "payload", big)
+
+	entry = receive(t, srv)
+	assert.Contains(t, string(entry), "MESSAGE=Big\n")
+	assert.Contains(t, string(entry), "PAYLOAD="+big+"\n")
+
+	require.NoError(t, ci.Close(context.Background()))
+	_, err = w.Write([]byte("MESSAGE=closed\n"))
+	assert.Equal(t, journald.ErrClosed, err)
+}
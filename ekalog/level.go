@@ -5,6 +5,10 @@
 
 package ekalog
 
+import (
+	"strings"
+)
+
 type (
 	// Level is the log message's severity level.
 	// There are 7 log levels the same as used in syslog.
@@ -12,9 +16,13 @@ type (
 	// You can use log constants to determine
 	// which log entry will you receive in your Integrator.
 	//
-	// Keep in mind, logging using LEVEL_EMERGENCY cause calling ekadeath.Die(),
+	// Keep in mind, logging using LEVEL_EMERGENCY (LEVEL_FATAL) cause calling
+	// DeathHandler (ekadeath.Die(1) by default, see SetDeathHandler()),
 	// after writing a log message.
 	//
+	// Custom levels (like TRACE) may be added using RegisterLevel(),
+	// so use Level.IsEnabledFor() instead of comparing levels' numeric values.
+	//
 	// Read more:
 	// https://en.wikipedia.org/wiki/Syslog
 	//
@@ -35,147 +43,88 @@ const (
 
 // String returns a capitalized string of the current log level.
 // Returns an empty string if it's unexpected log level.
+// Read more: SetLevelNames(), RegisterLevel().
 func (l Level) String() string {
-	switch l {
-	case LEVEL_EMERGENCY:
-		return "Emergency"
-	case LEVEL_ALERT:
-		return "Alert"
-	case LEVEL_CRITICAL:
-		return "Critical"
-	case LEVEL_ERROR:
-		return "Error"
-	case LEVEL_WARNING:
-		return "Warning"
-	case LEVEL_NOTICE:
-		return "Notice"
-	case LEVEL_INFO:
-		return "Info"
-	case LEVEL_DEBUG:
-		return "Debug"
-	default:
-		return ""
-	}
+	return l.names().name
 }
 
 // String3 returns a capitalized short-hand string of the current log level.
 // It has a length of 3 chars for all but LEVEL_EMERGENCY takes 5 for that.
 // Returns an empty string if it's unexpected log level.
+// Read more: SetLevelNames(), RegisterLevel().
 func (l Level) String3() string {
-	switch l {
-	case LEVEL_EMERGENCY:
-		return "Emerg"
-	case LEVEL_ALERT:
-		return "Ale"
-	case LEVEL_CRITICAL:
-		return "Cri"
-	case LEVEL_ERROR:
-		return "Err"
-	case LEVEL_WARNING:
-		return "War"
-	case LEVEL_NOTICE:
-		return "Noe"
-	case LEVEL_INFO:
-		return "Inf"
-	case LEVEL_DEBUG:
-		return "Deb"
-	default:
-		return ""
-	}
+	return l.names().name3
 }
 
 // ToUpper returns an uppercase variant of String() call.
 func (l Level) ToUpper() string {
-	switch l {
-	case LEVEL_EMERGENCY:
-		return "EMERGENCY"
-	case LEVEL_ALERT:
-		return "ALERT"
-	case LEVEL_CRITICAL:
-		return "CRITICAL"
-	case LEVEL_ERROR:
-		return "ERROR"
-	case LEVEL_WARNING:
-		return "WARNING"
-	case LEVEL_NOTICE:
-		return "NOTICE"
-	case LEVEL_INFO:
-		return "INFO"
-	case LEVEL_DEBUG:
-		return "DEBUG"
-	default:
-		return ""
-	}
+	return l.names().upper
 }
 
 // ToLower returns a lowercase variant of String() call.
 func (l Level) ToLower() string {
-	switch l {
-	case LEVEL_EMERGENCY:
-		return "emergency"
-	case LEVEL_ALERT:
-		return "alert"
-	case LEVEL_CRITICAL:
-		return "critical"
-	case LEVEL_ERROR:
-		return "error"
-	case LEVEL_WARNING:
-		return "warning"
-	case LEVEL_NOTICE:
-		return "notice"
-	case LEVEL_INFO:
-		return "info"
-	case LEVEL_DEBUG:
-		return "debug"
-	default:
-		return ""
-	}
+	return l.names().lower
 }
 
 // ToUpper3 returns an uppercase variant of String3() call.
 func (l Level) ToUpper3() string {
-	switch l {
-	case LEVEL_EMERGENCY:
-		return "EMERG"
-	case LEVEL_ALERT:
-		return "ALE"
-	case LEVEL_CRITICAL:
-		return "CRI"
-	case LEVEL_ERROR:
-		return "ERR"
-	case LEVEL_WARNING:
-		return "WAR"
-	case LEVEL_NOTICE:
-		return "NOE"
-	case LEVEL_INFO:
-		return "INF"
-	case LEVEL_DEBUG:
-		return "DEB"
-	default:
-		return ""
-	}
+	return l.names().upper3
 }
 
 // ToLower3 returns an uppercase variant of String3() call.
 func (l Level) ToLower3() string {
-	switch l {
-	case LEVEL_EMERGENCY:
-		return "emerg"
-	case LEVEL_ALERT:
-		return "ale"
-	case LEVEL_CRITICAL:
-		return "cri"
-	case LEVEL_ERROR:
-		return "err"
-	case LEVEL_WARNING:
-		return "war"
-	case LEVEL_NOTICE:
-		return "noe"
-	case LEVEL_INFO:
-		return "inf"
-	case LEVEL_DEBUG:
-		return "deb"
+	return l.names().lower3
+}
+
+// IsEnabledFor reports whether the Entry of the current Level passes
+// the 'minLevel' filter, i.e. the current Level is as important as 'minLevel'
+// or more important. Custom levels (read more: RegisterLevel())
+// are compared by their order, not by their numeric values.
+func (l Level) IsEnabledFor(minLevel Level) bool {
+	return levelOrder(l) <= levelOrder(minLevel)
+}
+
+// IsCustom reports whether the current Level is registered by RegisterLevel().
+func (l Level) IsCustom() bool {
+	return l > LEVEL_DEBUG && l.names().name != ""
+}
+
+// Base returns the built-in Level the current custom one is placed right below
+// (read more: LevelDescriptor.Below), so the integrations, that know nothing about
+// custom levels (syslog priority, Sentry level, etc), may use it.
+// Returns the current Level itself if it's not a custom one.
+func (l Level) Base() Level {
+	if !l.IsCustom() {
+		return l
+	}
+	return l.names().base
+}
+
+// ParseLevel returns a Level by its name (case insensitive).
+// Both of full (String()) and short (String3()) names are allowed,
+// as well as some widespread aliases: "warn", "err", "crit", "fatal", "panic".
+// The names of custom levels (read more: RegisterLevel()) and the names
+// set by SetLevelNames() are allowed too.
+// Returns false if 's' is not a known Level's name.
+func ParseLevel(s string) (Level, bool) {
+	switch s = strings.ToLower(strings.TrimSpace(s)); s {
+	case "emergency", "emerg", "fatal", "panic":
+		return LEVEL_EMERGENCY, true
+	case "alert", "ale":
+		return LEVEL_ALERT, true
+	case "critical", "crit", "cri":
+		return LEVEL_CRITICAL, true
+	case "error", "err":
+		return LEVEL_ERROR, true
+	case "warning", "warn", "war":
+		return LEVEL_WARNING, true
+	case "notice", "noe":
+		return LEVEL_NOTICE, true
+	case "info", "inf":
+		return LEVEL_INFO, true
+	case "debug", "deb":
+		return LEVEL_DEBUG, true
 	default:
-		return ""
+		return levelByName(s)
 	}
 }
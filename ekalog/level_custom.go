@@ -0,0 +1,269 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// LevelDescriptor describes a custom Level. Read more: RegisterLevel().
+	LevelDescriptor struct {
+
+		// Name is a capitalized full name of Level, like "Trace" or "Audit".
+		// It's returned by Level.String() (and its upper, lower case variants).
+		// Required.
+		Name string
+
+		// Name3 is a capitalized short-hand name of Level, like "Tra" or "Aud".
+		// It's returned by Level.String3() (and its upper, lower case variants).
+		// The first 3 chars of Name are used if it's empty.
+		Name3 string
+
+		// Below is the Level, the custom one is placed right below,
+		// i.e. it's less important than 'Below', but more important
+		// than all levels that are less important than 'Below'.
+		// E.g. LEVEL_DEBUG for TRACE or LEVEL_NOTICE for AUDIT (between
+		// LEVEL_NOTICE and LEVEL_INFO). Read more: Level.Base().
+		Below Level
+
+		// Color is a default color of Level for CI_ConsoleEncoder.
+		// The format is the same as CI_ConsoleEncoder.SetColorFor() accepts.
+		// It's applied by CI_ConsoleEncoder that is built after Level
+		// is registered. The color of Level.Base() is used otherwise
+		// or if it's empty.
+		Color string
+	}
+
+	// levelNames contains the names of Level, its base Level and default color.
+	// It's immutable, a new one is created when it's changed.
+	levelNames struct {
+		name, name3    string
+		upper, lower   string
+		upper3, lower3 string
+		base           Level
+		color          string
+	}
+)
+
+var (
+	// levelsNames contains *levelNames for each Level (nil for unknown ones).
+	// It's initialized at the variables initialization stage,
+	// because the levels are used by init().
+	levelsNames = newLevelsNames()
+
+	// levelsOrders is an importance order of each Level. The lower the order,
+	// the more important Level is. Atomic access only.
+	levelsOrders = newLevelsOrders()
+
+	// levelsMu protects the registration of custom levels
+	// and the changing of levels' names.
+	levelsMu sync.Mutex
+
+	// levelNext is a value of the next registered custom Level.
+	// Protected by levelsMu.
+	levelNext = LEVEL_DEBUG + 1
+
+	// levelNamesUnknown is an empty levelNames of unknown Level.
+	levelNamesUnknown = new(levelNames)
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// _LEVEL_ORDER_STEP is the difference between orders of two neighbour built-in
+	// levels, so the custom levels may be placed between them.
+	_LEVEL_ORDER_STEP = 1 << 16
+)
+
+// RegisterLevel registers a new custom Level, described by 'desc',
+// and returns it. Custom levels are honored by ParseLevel(), the encoders
+// (names, colors) and the integrators (min levels) the same way
+// as built-in ones are, so LEVEL_TRACE, that is used by logrus, may be added:
+//
+//	var LEVEL_TRACE, _ = ekalog.RegisterLevel(ekalog.LevelDescriptor{
+//	    Name:  "Trace",
+//	    Below: ekalog.LEVEL_DEBUG,
+//	    Color: "c/fg:#606060",
+//	})
+//	...
+//	ekalog.Log(LEVEL_TRACE, "Very verbose message")
+//
+// Keep in mind, the numeric value of custom Level is not its order,
+// use Level.IsEnabledFor() to compare levels.
+// If many levels are registered below the same Level, the last registered one
+// is the most important of them.
+//
+// Returns false if 'desc' has an empty name, or the name that is used already,
+// or 'desc.Below' is not built-in nor registered Level,
+// or there is no space for a new custom Level. Thread-safe.
+func RegisterLevel(desc LevelDescriptor) (Level, bool) {
+
+	if desc.Name = strings.TrimSpace(desc.Name); desc.Name == "" {
+		return 0, false
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	if desc.Below.names().name == "" || levelNext == 0 ||
+		levelIsNameUsed(desc.Name, LEVEL_EMERGENCY, false) {
+		return 0, false
+	}
+
+	// Place the new Level in the middle between 'desc.Below'
+	// and the next less important known Level.
+
+	lo := levelOrder(desc.Below)
+	hi := lo + _LEVEL_ORDER_STEP
+	for i := 0; i < len(levelsOrders); i++ {
+		if order := levelOrder(Level(i)); Level(i).names().name != "" && order > lo && order < hi {
+			hi = order
+		}
+	}
+
+	order := lo + (hi-lo)/2
+	if order == lo {
+		return 0, false
+	}
+
+	level := levelNext
+	levelNext++ // overflows to 0 after 255 is registered
+
+	levelsNames[level].Store(newLevelNames(desc.Name, desc.Name3, desc.Below.Base(), desc.Color))
+	atomic.StoreUint32(&levelsOrders[level], order)
+
+	return level, true
+}
+
+// SetLevelNames overwrites the full and short-hand names of 'level', either
+// built-in or custom one, that are returned by Level.String(), Level.String3()
+// (and their upper, lower case variants), so the names may be localized:
+//
+//	ekalog.SetLevelNames(ekalog.LEVEL_ERROR, "Ошибка", "Ошб")
+//
+// The first 3 chars of 'name' are used if 'name3' is empty.
+// ParseLevel() accepts the new names as well as the built-in English ones.
+//
+// Does nothing if 'name' is empty, or it's used by another Level,
+// or 'level' is not built-in nor registered Level. Thread-safe.
+func SetLevelNames(level Level, name, name3 string) {
+
+	if name = strings.TrimSpace(name); name == "" {
+		return
+	}
+
+	levelsMu.Lock()
+	defer levelsMu.Unlock()
+
+	old := level.names()
+	if old.name == "" || levelIsNameUsed(name, level, true) {
+		return
+	}
+
+	levelsNames[level].Store(newLevelNames(name, name3, old.base, old.color))
+}
+
+// ---------------------------------------------------------------------------- //
+
+// newLevelsNames returns the names of built-in levels. Read more: levelsNames.
+func newLevelsNames() *[256]atomic.Value {
+
+	names := [...][2]string{
+		LEVEL_EMERGENCY: {"Emergency", "Emerg"},
+		LEVEL_ALERT:     {"Alert", "Ale"},
+		LEVEL_CRITICAL:  {"Critical", "Cri"},
+		LEVEL_ERROR:     {"Error", "Err"},
+		LEVEL_WARNING:   {"Warning", "War"},
+		LEVEL_NOTICE:    {"Notice", "Noe"},
+		LEVEL_INFO:      {"Info", "Inf"},
+		LEVEL_DEBUG:     {"Debug", "Deb"},
+	}
+
+	levelsNames := new([256]atomic.Value)
+	for level := range names {
+		levelsNames[level].Store(newLevelNames(names[level][0], names[level][1], Level(level), ""))
+	}
+
+	return levelsNames
+}
+
+// newLevelsOrders returns the orders of levels. Read more: levelsOrders.
+// Unknown (and custom, until they're registered) levels keep their numeric order.
+func newLevelsOrders() (orders [256]uint32) {
+	for level := range orders {
+		orders[level] = uint32(level) * _LEVEL_ORDER_STEP
+	}
+	return orders
+}
+
+// newLevelNames returns a new levelNames with provided names
+// (the first 3 chars of 'name' are used if 'name3' is empty), 'base' and 'color'.
+func newLevelNames(name, name3 string, base Level, color string) *levelNames {
+
+	if name3 = strings.TrimSpace(name3); name3 == "" {
+		name3 = name
+		if runes := []rune(name); len(runes) > 3 {
+			name3 = string(runes[:3])
+		}
+	}
+
+	return &levelNames{
+		name:   name,
+		name3:  name3,
+		upper:  strings.ToUpper(name),
+		lower:  strings.ToLower(name),
+		upper3: strings.ToUpper(name3),
+		lower3: strings.ToLower(name3),
+		base:   base,
+		color:  color,
+	}
+}
+
+// names returns levelNames of the current Level.
+// Returns an empty levelNames for unknown Level.
+func (l Level) names() *levelNames {
+	if names, _ := levelsNames[l].Load().(*levelNames); names != nil {
+		return names
+	}
+	return levelNamesUnknown
+}
+
+// levelOrder returns an importance order of 'level'. Read more: levelsOrders.
+func levelOrder(level Level) uint32 {
+	return atomic.LoadUint32(&levelsOrders[level])
+}
+
+// levelByName returns a built-in or custom Level the full or short-hand name of which
+// is the same as lowercase 's'. Returns false if there's no such Level.
+func levelByName(s string) (Level, bool) {
+	for i := 0; i < len(levelsNames); i++ {
+		if names := Level(i).names(); names.name != "" && (names.lower == s || names.lower3 == s) {
+			return Level(i), true
+		}
+	}
+	return 0, false
+}
+
+// levelIsNameUsed reports whether 'name' is parsed by ParseLevel()
+// as any Level, excluding 'except' if 'isExcept' is true.
+func levelIsNameUsed(name string, except Level, isExcept bool) bool {
+	level, ok := ParseLevel(name)
+	return ok && (!isExcept || level != except)
+}
+
+// levelCustomColors returns the default colors of custom levels
+// (read more: LevelDescriptor.Color).
+func levelCustomColors() map[Level]string {
+	colors := make(map[Level]string)
+	for i := int(LEVEL_DEBUG) + 1; i < len(levelsNames); i++ {
+		if names := Level(i).names(); names.color != "" {
+			colors[Level(i)] = names.color
+		}
+	}
+	return colors
+}
@@ -0,0 +1,101 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRegisterLevel(t *testing.T) {
+
+	levelTrace, ok := ekalog.RegisterLevel(ekalog.LevelDescriptor{
+		Name:  "TestTrace",
+		Name3: "Tra",
+		Below: ekalog.LEVEL_DEBUG,
+	})
+	require.True(t, ok)
+
+	levelAudit, ok := ekalog.RegisterLevel(ekalog.LevelDescriptor{
+		Name:  "TestAudit",
+		Below: ekalog.LEVEL_NOTICE,
+	})
+	require.True(t, ok)
+
+	_, ok = ekalog.RegisterLevel(ekalog.LevelDescriptor{Name: "testtrace", Below: ekalog.LEVEL_DEBUG})
+	assert.False(t, ok, "name is used")
+	_, ok = ekalog.RegisterLevel(ekalog.LevelDescriptor{Name: "Warn", Below: ekalog.LEVEL_DEBUG})
+	assert.False(t, ok, "name is an alias of built-in level")
+	_, ok = ekalog.RegisterLevel(ekalog.LevelDescriptor{Name: "TestBad", Below: 200})
+	assert.False(t, ok, "below unknown level")
+
+	assert.Equal(t, "TestTrace", levelTrace.String())
+	assert.Equal(t, "TESTTRACE", levelTrace.ToUpper())
+	assert.Equal(t, "tra", levelTrace.ToLower3())
+	assert.Equal(t, "Tes", levelAudit.String3())
+
+	assert.True(t, levelTrace.IsCustom())
+	assert.False(t, ekalog.LEVEL_DEBUG.IsCustom())
+	assert.Equal(t, ekalog.LEVEL_DEBUG, levelTrace.Base())
+	assert.Equal(t, ekalog.LEVEL_NOTICE, levelAudit.Base())
+
+	parsed, ok := ekalog.ParseLevel(" TESTAUDIT ")
+	assert.True(t, ok)
+	assert.Equal(t, levelAudit, parsed)
+
+	// LEVEL_NOTICE > AUDIT > LEVEL_INFO > LEVEL_DEBUG > TRACE
+	assert.True(t, ekalog.LEVEL_NOTICE.IsEnabledFor(levelAudit))
+	assert.True(t, levelAudit.IsEnabledFor(ekalog.LEVEL_INFO))
+	assert.False(t, levelAudit.IsEnabledFor(ekalog.LEVEL_NOTICE))
+	assert.True(t, ekalog.LEVEL_DEBUG.IsEnabledFor(levelTrace))
+	assert.False(t, levelTrace.IsEnabledFor(ekalog.LEVEL_DEBUG))
+
+	b := bytes.NewBuffer(nil)
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{l}}:{{m}}|")).
+		WithMinLevel(ekalog.LEVEL_INFO).
+		WriteTo(b))
+
+	ekalog.Log(levelTrace, "trace")
+	ekalog.Log(levelAudit, "audit")
+	ekalog.Debug("debug")
+	assert.Equal(t, "TestAudit:audit|", b.String())
+
+	b.Reset()
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{l/s}}:{{m}}|")).
+		WithMinLevel(levelTrace).
+		WriteTo(b))
+
+	ekalog.Log(levelTrace, "trace")
+	ekalog.Debug("debug")
+	assert.Equal(t, "Tra:trace|Deb:debug|", b.String())
+}
+
+func TestSetLevelNames(t *testing.T) {
+
+	defer ekalog.SetLevelNames(ekalog.LEVEL_ERROR, "Error", "Err")
+
+	ekalog.SetLevelNames(ekalog.LEVEL_ERROR, "Ошибка", "")
+	assert.Equal(t, "Ошибка", ekalog.LEVEL_ERROR.String())
+	assert.Equal(t, "Оши", ekalog.LEVEL_ERROR.String3())
+	assert.Equal(t, "ОШИБКА", ekalog.LEVEL_ERROR.ToUpper())
+
+	// Both of the new and built-in names are parsed.
+	for _, name := range []string{"ошибка", "Error"} {
+		parsed, ok := ekalog.ParseLevel(name)
+		assert.True(t, ok)
+		assert.Equal(t, ekalog.LEVEL_ERROR, parsed)
+	}
+
+	ekalog.SetLevelNames(ekalog.LEVEL_ERROR, "Warning", "")
+	assert.Equal(t, "Ошибка", ekalog.LEVEL_ERROR.String(), "name of another level")
+}
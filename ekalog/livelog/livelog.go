@@ -0,0 +1,220 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+/*
+Package livelog provides Hub, an ekalog.Integrator wrapper and http.Handler,
+that streams JSON-encoded log entries to the connected WebSocket
+or Server-Sent Events (SSE) clients. It's made for an internal
+"live logs" admin page w/o shipping logs to an external system:
+
+	hub := livelog.NewHub(new(ekalog.CommonIntegrator).
+	    WithEncoder(new(ekalog.CI_ConsoleEncoder)).
+	    WithMinLevel(ekalog.LEVEL_INFO).
+	    WriteTo(os.Stdout))
+	ekalog.ReplaceIntegrator(hub)
+	http.Handle("/debug/logs", hub)
+
+Each client may filter the entries by Level using "level" query parameter,
+like "/debug/logs?level=warning" (all levels are streamed by default).
+The slow clients never block logging, their entries are dropped instead.
+*/
+package livelog
+
+import (
+	"net/http"
+	"sync"
+	"sync/atomic"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/internal/ekaclike"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Hub is an ekalog.Integrator wrapper, that passes each Entry
+	// to the wrapped Integrator (if its Level is enabled by the wrapped one)
+	// and streams it encoded by ekalog.CI_JSONEncoder to all connected clients.
+	// Hub is also an http.Handler, that serves the clients.
+	//
+	// A client is connected using WebSocket if the request is WebSocket upgrade one,
+	// or using Server-Sent Events otherwise. Each Entry is sent as one WebSocket
+	// text message or one SSE "data:" event.
+	//
+	// Each client has its own buffer (see SetClientBuffer()). If it's full
+	// (the client is too slow), the Entry is dropped for this client.
+	//
+	// Use NewHub() to create it.
+	Hub struct {
+		origin   ekalog.Integrator
+		entries  *ekalog.ChannelIntegrator
+		minLevel ekalog.Level
+
+		clientBuffer int
+
+		mu      sync.Mutex
+		clients map[*client]struct{}
+		closed  bool
+		done    chan struct{}
+
+		clientsNum int64  // atomic access only
+		dropped    uint64 // atomic access only
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// DEFAULT_CLIENT_BUFFER is a default number of encoded Entry,
+	// that are buffered for each client. Read more: Hub.SetClientBuffer().
+	DEFAULT_CLIENT_BUFFER = 256
+
+	// LEVEL_QUERY_PARAM is a name of request's query parameter,
+	// the least severe Level the client wants to get the entries of, is taken from.
+	LEVEL_QUERY_PARAM = "level"
+)
+
+// NewHub returns a new Hub wrapping 'origin' Integrator, that streams
+// the entries of all levels. If 'origin' is nil, Hub only streams the entries.
+func NewHub(origin ekalog.Integrator) *Hub {
+
+	if ekaclike.TakeRealAddr(origin) == nil {
+		origin = nil
+	}
+
+	hub := &Hub{
+		origin: origin,
+		entries: ekalog.NewChannelIntegrator(0).
+			SetEncoder(new(ekalog.CI_JSONEncoder)),
+		minLevel:     ekalog.LEVEL_DEBUG,
+		clientBuffer: DEFAULT_CLIENT_BUFFER,
+		clients:      make(map[*client]struct{}),
+		done:         make(chan struct{}),
+	}
+
+	go hub.run()
+	return hub
+}
+
+// SetMinLevel sets the least severe Level, the Entry of which is streamed.
+// LEVEL_DEBUG by default. Clients may narrow it using LEVEL_QUERY_PARAM.
+func (h *Hub) SetMinLevel(level ekalog.Level) *Hub {
+	h.minLevel = level
+	h.entries.SetMinLevel(level)
+	return h
+}
+
+// SetClientBuffer sets the number of encoded Entry, that are buffered
+// for each client connected after that. A non-positive 'n' means
+// DEFAULT_CLIENT_BUFFER.
+func (h *Hub) SetClientBuffer(n int) *Hub {
+	if n <= 0 {
+		n = DEFAULT_CLIENT_BUFFER
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.clientBuffer = n
+	return h
+}
+
+// Clients returns the number of connected clients.
+func (h *Hub) Clients() int {
+	return int(atomic.LoadInt64(&h.clientsNum))
+}
+
+// Dropped returns the number of encoded Entry, that have been dropped
+// because of slow clients (each client's drop is counted).
+func (h *Hub) Dropped() uint64 {
+	return atomic.LoadUint64(&h.dropped) + h.entries.Dropped()
+}
+
+// Close disconnects all clients and stops streaming.
+// The Entry are still passed to the wrapped Integrator.
+// Next calls do nothing.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if !h.closed {
+		h.closed = true
+		close(h.done)
+		h.entries.Close()
+	}
+}
+
+// ServeHTTP connects the client using WebSocket or SSE
+// and streams the entries to it until the client is disconnected
+// or Hub is closed. Responds with 400 Bad Request if LEVEL_QUERY_PARAM is
+// not a valid Level's name (see ekalog.ParseLevel()).
+func (h *Hub) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+
+	minLevel := h.minLevel
+	if levelName := r.URL.Query().Get(LEVEL_QUERY_PARAM); levelName != "" {
+		level, ok := ekalog.ParseLevel(levelName)
+		if !ok {
+			http.Error(w, "livelog: unknown level "+levelName, http.StatusBadRequest)
+			return
+		}
+		minLevel = level
+	}
+
+	if isWebSocketUpgrade(r) {
+		h.serveWebSocket(w, r, minLevel)
+	} else {
+		h.serveSSE(w, r, minLevel)
+	}
+}
+
+// PreEncodeField pre-encodes 'f' by both of Hub's encoder
+// and the wrapped Integrator.
+func (h *Hub) PreEncodeField(f ekaletter.LetterField) {
+	h.entries.PreEncodeField(f)
+	if h.origin != nil {
+		h.origin.PreEncodeField(f)
+	}
+}
+
+// EncodeAndWrite passes 'entry' to the wrapped Integrator (if it's enabled by them)
+// and streams it to the clients (if there are any).
+func (h *Hub) EncodeAndWrite(entry *ekalog.Entry) {
+
+	if h.origin != nil && entry.Level.IsEnabledFor(h.origin.MinLevelEnabled()) {
+		h.origin.EncodeAndWrite(entry)
+	}
+
+	// The clients are remote, so the local Entry are not streamed to them.
+	if entry.Level.IsEnabledFor(h.minLevel) && !entry.IsLocal() && atomic.LoadInt64(&h.clientsNum) > 0 {
+		h.entries.EncodeAndWrite(entry)
+	}
+}
+
+// MinLevelEnabled returns the least severe Level of Hub's one
+// and the wrapped Integrator's one.
+func (h *Hub) MinLevelEnabled() ekalog.Level {
+	if h.origin != nil && !h.origin.MinLevelEnabled().IsEnabledFor(h.minLevel) {
+		return h.origin.MinLevelEnabled()
+	}
+	return h.minLevel
+}
+
+// MinLevelForStackTrace returns the wrapped Integrator's one
+// or LEVEL_WARNING if there's no wrapped Integrator.
+func (h *Hub) MinLevelForStackTrace() ekalog.Level {
+	if h.origin != nil {
+		return h.origin.MinLevelForStackTrace()
+	}
+	return ekalog.LEVEL_WARNING
+}
+
+// Unwrap returns the wrapped Integrator or nil.
+// Implements ekalog.IntegratorWrapper.
+func (h *Hub) Unwrap() ekalog.Integrator {
+	return h.origin
+}
+
+// Sync flushes the wrapped Integrator (if any).
+func (h *Hub) Sync() error {
+	if h.origin != nil {
+		return h.origin.Sync()
+	}
+	return nil
+}
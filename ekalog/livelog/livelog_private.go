@@ -0,0 +1,323 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package livelog
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+)
+
+type (
+	// client is a connected client, the encoded Entry are sent to.
+	client struct {
+		ch       chan []byte
+		minLevel ekalog.Level
+	}
+)
+
+const (
+	// wsGUID is a magic GUID of WebSocket handshake (RFC 6455, section 1.3).
+	wsGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+	wsOpcodeText  = 0x1
+	wsOpcodeClose = 0x8
+	wsOpcodePing  = 0x9
+	wsOpcodePong  = 0xA
+
+	// wsMaxControlPayload is the max payload's size of the control frames.
+	wsMaxControlPayload = 125
+)
+
+// run delivers the encoded Entry from the Hub's ChannelIntegrator
+// to the connected clients until Hub is closed.
+func (h *Hub) run() {
+	for ce := range h.entries.C() {
+		encoded := bytes.TrimRight(ce.Encoded, "\n")
+		if len(encoded) == 0 {
+			continue
+		}
+
+		h.mu.Lock()
+		for c := range h.clients {
+			if !ce.Level.IsEnabledFor(c.minLevel) {
+				continue
+			}
+			select {
+			case c.ch <- encoded:
+			default:
+				atomic.AddUint64(&h.dropped, 1)
+			}
+		}
+		h.mu.Unlock()
+	}
+}
+
+// register registers and returns a new client with 'minLevel'
+// or returns nil if Hub is closed.
+func (h *Hub) register(minLevel ekalog.Level) *client {
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.closed {
+		return nil
+	}
+
+	c := &client{ch: make(chan []byte, h.clientBuffer), minLevel: minLevel}
+	h.clients[c] = struct{}{}
+	atomic.AddInt64(&h.clientsNum, 1)
+
+	return c
+}
+
+// unregister unregisters 'c'.
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients, c)
+	atomic.AddInt64(&h.clientsNum, -1)
+}
+
+// serveSSE streams the encoded Entry to the client as Server-Sent Events.
+func (h *Hub) serveSSE(w http.ResponseWriter, r *http.Request, minLevel ekalog.Level) {
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "livelog: streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	c := h.register(minLevel)
+	if c == nil {
+		http.Error(w, "livelog: hub is closed", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.unregister(c)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-h.done:
+			return
+		case encoded := <-c.ch:
+			if _, err := w.Write(sseEvent(encoded)); err != nil {
+				return
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+// serveWebSocket performs WebSocket handshake and streams the encoded Entry
+// to the client as text messages.
+func (h *Hub) serveWebSocket(w http.ResponseWriter, r *http.Request, minLevel ekalog.Level) {
+
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if key == "" || r.Header.Get("Sec-WebSocket-Version") != "13" {
+		w.Header().Set("Sec-WebSocket-Version", "13")
+		http.Error(w, "livelog: bad WebSocket handshake", http.StatusBadRequest)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "livelog: WebSocket is not supported", http.StatusInternalServerError)
+		return
+	}
+
+	c := h.register(minLevel)
+	if c == nil {
+		http.Error(w, "livelog: hub is closed", http.StatusServiceUnavailable)
+		return
+	}
+	defer h.unregister(c)
+
+	conn, rw, err := hijacker.Hijack()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	_, err = rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n" +
+		"Upgrade: websocket\r\n" +
+		"Connection: Upgrade\r\n" +
+		"Sec-WebSocket-Accept: " + wsAcceptKey(key) + "\r\n\r\n")
+	if err != nil || rw.Flush() != nil {
+		return
+	}
+
+	// Client's frames are read only to handle control frames
+	// and to detect disconnection. Pongs are sent by the writer.
+	var (
+		closed = make(chan struct{})
+		pongs  = make(chan []byte, 1)
+	)
+	go wsReadLoop(rw.Reader, pongs, closed)
+
+	for {
+		var err error
+		select {
+		case <-closed:
+			_ = wsWriteFrame(conn, wsOpcodeClose, nil)
+			return
+		case <-h.done:
+			_ = wsWriteFrame(conn, wsOpcodeClose, nil)
+			return
+		case payload := <-pongs:
+			err = wsWriteFrame(conn, wsOpcodePong, payload)
+		case encoded := <-c.ch:
+			err = wsWriteFrame(conn, wsOpcodeText, encoded)
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// isWebSocketUpgrade reports whether 'r' is WebSocket upgrade request.
+func isWebSocketUpgrade(r *http.Request) bool {
+	return headerContainsToken(r.Header, "Connection", "upgrade") &&
+		headerContainsToken(r.Header, "Upgrade", "websocket")
+}
+
+// headerContainsToken reports whether the comma separated values
+// of 'h' header contain 'token' (case insensitive).
+func headerContainsToken(h http.Header, name, token string) bool {
+	for _, value := range h.Values(name) {
+		for _, t := range strings.Split(value, ",") {
+			if strings.EqualFold(strings.TrimSpace(t), token) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// sseEvent returns 'encoded' as SSE "data:" event.
+func sseEvent(encoded []byte) []byte {
+	event := make([]byte, 0, len(encoded)+8)
+	event = append(event, "data: "...)
+	event = append(event, encoded...)
+	return append(event, '\n', '\n')
+}
+
+// wsAcceptKey returns "Sec-WebSocket-Accept" header's value for 'key'.
+func wsAcceptKey(key string) string {
+	sum := sha1.Sum([]byte(key + wsGUID))
+	return base64.StdEncoding.EncodeToString(sum[:])
+}
+
+// wsWriteFrame writes one unmasked final WebSocket frame of 'opcode'
+// with 'payload' to 'w'.
+func wsWriteFrame(w io.Writer, opcode byte, payload []byte) error {
+
+	header := make([]byte, 2, 10+len(payload))
+	header[0] = 0x80 | opcode
+
+	switch n := len(payload); {
+	case n <= 125:
+		header[1] = byte(n)
+	case n <= 0xFFFF:
+		header[1] = 126
+		header = append(header, 0, 0)
+		binary.BigEndian.PutUint16(header[2:], uint16(n))
+	default:
+		header[1] = 127
+		header = append(header, 0, 0, 0, 0, 0, 0, 0, 0)
+		binary.BigEndian.PutUint64(header[2:], uint64(n))
+	}
+
+	_, err := w.Write(append(header, payload...))
+	return err
+}
+
+// wsReadLoop reads client's WebSocket frames from 'r', sends the payloads
+// of ping frames to 'pongs' and closes 'closed' when the client sends close frame,
+// violates the protocol or the connection is broken.
+func wsReadLoop(r *bufio.Reader, pongs chan<- []byte, closed chan<- struct{}) {
+
+	defer close(closed)
+
+	var header [8]byte
+	for {
+		if _, err := io.ReadFull(r, header[:2]); err != nil {
+			return
+		}
+
+		opcode := header[0] & 0x0F
+		masked := header[1]&0x80 != 0
+		n := uint64(header[1] & 0x7F)
+
+		switch n {
+		case 126:
+			if _, err := io.ReadFull(r, header[:2]); err != nil {
+				return
+			}
+			n = uint64(binary.BigEndian.Uint16(header[:2]))
+		case 127:
+			if _, err := io.ReadFull(r, header[:8]); err != nil {
+				return
+			}
+			n = binary.BigEndian.Uint64(header[:8])
+		}
+
+		// Client's frames MUST be masked (RFC 6455, section 5.1).
+		if !masked {
+			return
+		}
+
+		var mask [4]byte
+		if _, err := io.ReadFull(r, mask[:]); err != nil {
+			return
+		}
+
+		if opcode < wsOpcodeClose {
+			// Data frames are not expected, just skip them.
+			if _, err := io.CopyN(io.Discard, r, int64(n)); err != nil {
+				return
+			}
+			continue
+		}
+
+		if n > wsMaxControlPayload {
+			return
+		}
+
+		payload := make([]byte, n)
+		if _, err := io.ReadFull(r, payload); err != nil {
+			return
+		}
+		for i := range payload {
+			payload[i] ^= mask[i%4]
+		}
+
+		switch opcode {
+		case wsOpcodeClose:
+			return
+		case wsOpcodePing:
+			select {
+			case pongs <- payload:
+			default:
+			}
+		}
+	}
+}
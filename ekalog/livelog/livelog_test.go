@@ -0,0 +1,127 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package livelog_test
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekalog/livelog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func waitClients(t *testing.T, hub *livelog.Hub, n int) {
+	for i := 0; i < 200 && hub.Clients() != n; i++ {
+		time.Sleep(5 * time.Millisecond)
+	}
+	require.Equal(t, n, hub.Clients())
+}
+
+func TestHub_SSE(t *testing.T) {
+
+	hub := livelog.NewHub(nil)
+	defer hub.Close()
+	ekalog.ReplaceIntegrator(hub)
+
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "?level=unknown")
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	resp, err = http.Get(srv.URL + "?level=warning")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	waitClients(t, hub, 1)
+
+	ekalog.Info("filtered out")
+	ekalog.Warn("streamed")
+
+	line, err := bufio.NewReader(resp.Body).ReadString('\n')
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(line, "data: "), line)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal([]byte(line[len("data: "):]), &entry))
+	assert.Equal(t, "streamed", entry["message"])
+}
+
+func TestHub_WebSocket(t *testing.T) {
+
+	hub := livelog.NewHub(nil)
+	defer hub.Close()
+	ekalog.ReplaceIntegrator(hub)
+
+	srv := httptest.NewServer(hub)
+	defer srv.Close()
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	_, err = io.WriteString(conn, "GET /?level=error HTTP/1.1\r\n"+
+		"Host: "+srv.Listener.Addr().String()+"\r\n"+
+		"Upgrade: websocket\r\n"+
+		"Connection: keep-alive, Upgrade\r\n"+
+		"Sec-WebSocket-Key: dGhlIHNhbXBsZSBub25jZQ==\r\n"+
+		"Sec-WebSocket-Version: 13\r\n\r\n")
+	require.NoError(t, err)
+
+	r := bufio.NewReader(conn)
+	resp, err := http.ReadResponse(r, nil)
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusSwitchingProtocols, resp.StatusCode)
+	assert.Equal(t, "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=", resp.Header.Get("Sec-WebSocket-Accept"))
+
+	waitClients(t, hub, 1)
+
+	ekalog.Warn("filtered out")
+	ekalog.Error("streamed")
+
+	header := make([]byte, 2)
+	_, err = io.ReadFull(r, header)
+	require.NoError(t, err)
+	assert.Equal(t, byte(0x81), header[0])
+
+	n := int(header[1])
+	if n == 126 {
+		_, err = io.ReadFull(r, header)
+		require.NoError(t, err)
+		n = int(binary.BigEndian.Uint16(header))
+	}
+	payload := make([]byte, n)
+	_, err = io.ReadFull(r, payload)
+	require.NoError(t, err)
+
+	var entry map[string]any
+	require.NoError(t, json.Unmarshal(payload, &entry))
+	assert.Equal(t, "streamed", entry["message"])
+
+	// Masked close frame w/o payload.
+	_, err = conn.Write([]byte{0x88, 0x80, 1, 2, 3, 4})
+	require.NoError(t, err)
+
+	_, err = io.ReadFull(r, header)
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0x88, 0}, header)
+
+	waitClients(t, hub, 0)
+}
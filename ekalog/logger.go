@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/qioalice/ekago/v3/ekagen"
 	"github.com/qioalice/ekago/v3/internal/ekaclike"
 	"github.com/qioalice/ekago/v3/internal/ekaletter"
 )
@@ -52,12 +53,29 @@ type (
 
 		// integrator is the way how Entry will be encoded and what encoded Entry
 		// will be written to.
-		integrator Integrator
+		// It's shared with all Loggers derived from this one.
+		integrator *integratorSlot
 
 		// entry is _WHAT_ log message is.
 		// entry is it's stacktrace, caller info, timestamp, level, message, group,
 		// flags, etc.
 		entry *Entry
+
+		// named is the named Logger's registry entry this Logger is derived from
+		// or nil if it's not a named Logger. Read more: Named().
+		named *namedLogger
+
+		// correlationID is the ID, that is attached to each Entry of this Logger
+		// and to their ekaerr.Error s. Read more: WithNewCorrelationID().
+		correlationID string
+
+		// trigger is the trigger scope, the Entry of this Logger are buffered by,
+		// or nil. Read more: BeginTriggerScope().
+		trigger *triggerScope
+
+		// clock is the time source of Entry's timestamp
+		// or nil if Integrator's one is used. Read more: WithClock().
+		clock func() time.Time
 	}
 )
 
@@ -102,7 +120,7 @@ func (l *Logger) Sync() error {
 	if l == nopLogger {
 		return nil
 	}
-	return l.integrator.Sync()
+	return l.integrator.current().Sync()
 }
 
 // --------------------------- FIELDS ADDING METHODS -------------------------- //
@@ -260,6 +278,12 @@ func (l *Logger) WithManyAny(fields ...any) *Logger {
 	return l.addFieldsParse(fields)
 }
 
+// WithStruct adds fields extracted from the struct 'value' using its `ekalog` tags.
+// See ekagen.Fields() for more details.
+func (l *Logger) WithStruct(value any) *Logger {
+	return l.addFields(ekagen.Fields(value))
+}
+
 // ------------------------ CONDITIONAL LOGGING METHODS ----------------------- //
 // ---------------------------------------------------------------------------- //
 
@@ -281,6 +305,26 @@ func (l *Logger) If(cond bool) *Logger {
 // ------------------------------ UTILITY METHODS ----------------------------- //
 // ---------------------------------------------------------------------------- //
 
+// WithIntegrator returns a copy of the current Logger, that uses passed
+// Integrator. Unlike ReplaceIntegrator(), neither the current Logger nor
+// the Loggers it's derived from are affected. The Loggers, derived from
+// the returned one, use passed Integrator too.
+//
+// Requirements are the same as ReplaceIntegrator() has.
+func (l *Logger) WithIntegrator(newIntegrator Integrator) *Logger {
+	l.assert()
+	if l == nopLogger {
+		return l
+	}
+	if ekaclike.TakeRealAddr(newIntegrator) == nil {
+		panic("Failed to change Integrator. New Integrator is nil.")
+	}
+	if ci, ok := unwrapIntegrator(newIntegrator).(*CommonIntegrator); ok {
+		ci.build()
+	}
+	return l.derive().setIntegrator(newIntegrator)
+}
+
 // ReplaceIntegrator replaces Integrator for the current Logger object
 // to the passed one.
 //
@@ -292,6 +336,24 @@ func (l *Logger) If(cond bool) *Logger {
 //   - If Integrator is CommonIntegrator
 //     it must have at least 1 registered io.Writer, panic otherwise.
 //
+// ReplaceIntegrator is safe for concurrent use and may be called at runtime
+// (e.g. to reload logging config on SIGHUP):
+//   - The Integrator is replaced for the current Logger and for all Loggers
+//     that are derived from it (and from whom it's derived from) by Copy(),
+//     With...() methods, etc;
+//   - Log entries that are being processed right now are finished
+//     by the old Integrator; ReplaceIntegrator waits for them;
+//   - The old Integrator is synced (Integrator.Sync()) then.
+//     It's up to you to close it if it's required (CommonIntegrator.Close()).
+//
+// If ReplaceIntegrator is called from the logging callbacks
+// (io.Writer, CI_PostProcessor, trigger's or death's handlers, etc.),
+// it doesn't wait for the log entry of the caller, since that one is never
+// finished while it waits. The old Integrator is not synced then,
+// because it's still in use, and the warning about that is logged
+// by the new Integrator. The same happens if the log entries of other goroutines
+// are not finished in 1 second.
+//
 // WARNING.
 // Replacing Integrator will drop all pre-encoded ekaletter.LetterField fields
 // that are might be added already to the current Integrator.
@@ -303,8 +365,15 @@ func (l *Logger) ReplaceIntegrator(newIntegrator Integrator) {
 	if ekaclike.TakeRealAddr(newIntegrator) == nil {
 		panic("Failed to change Integrator. New Integrator is nil.")
 	}
-	if ci, ok := newIntegrator.(*CommonIntegrator); ok {
+	if ci, ok := unwrapIntegrator(newIntegrator).(*CommonIntegrator); ok {
 		ci.build()
 	}
-	baseLogger.setIntegrator(newIntegrator)
+	oldIntegrator, isDrained := l.integrator.replace(newIntegrator)
+	switch {
+	case oldIntegrator == nil:
+	case isDrained:
+		_ = oldIntegrator.Sync()
+	default:
+		l.Warn("ekalog: old Integrator is still in use after replacing, it's not synced")
+	}
 }
@@ -0,0 +1,54 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"time"
+)
+
+// WithClock returns a copy of the current Logger, the Entry of which
+// are stamped by 'clock' instead of time.Now (read more: Entry.Time).
+// The rate limit of the Every finishers (e.g. InfoEvery()) uses it either.
+// It overrides the Integrator's one (read more: CommonIntegrator.WithClock()).
+// Nil 'clock' means the Integrator's one is used.
+//
+// It's useful for deterministic tests, replay tools and simulated-clock
+// environments:
+//
+//	t0 := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+//	log := ekalog.WithClock(func() time.Time { return t0 })
+//
+// Unlike With... methods, it always makes a copy of the current Logger.
+func (l *Logger) WithClock(clock func() time.Time) *Logger {
+	return l.setClock(clock)
+}
+
+// WithClock returns a copy of the package-level Logger with 'clock'
+// as the time source. See Logger.WithClock() for more details.
+func WithClock(clock func() time.Time) *Logger {
+	return baseLogger.setClock(clock)
+}
+
+// setClock checks whether Logger is valid, not nop Logger
+// and returns its copy with the provided time source.
+func (l *Logger) setClock(clock func() time.Time) *Logger {
+	l.assert()
+	if l == nopLogger {
+		return l
+	}
+	newLogger := l.derive()
+	newLogger.clock = clock
+	return newLogger
+}
+
+// now returns the current time according with the Logger's time source,
+// or the 'integrator's one if Logger has no one.
+func (l *Logger) now(integrator Integrator) time.Time {
+	if l.clock != nil {
+		return l.clock()
+	}
+	return clockOf(integrator)()
+}
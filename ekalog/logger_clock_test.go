@@ -0,0 +1,61 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_WithClock(t *testing.T) {
+
+	t0 := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+	t1 := t0.Add(time.Hour)
+
+	b := bytes.NewBuffer(nil)
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{t/RFC3339}} {{m}};")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WithClock(func() time.Time { return t0 }).
+		WriteTo(b))
+
+	ekalog.Info("integrator")
+	ekalog.WithClock(func() time.Time { return t1 }).Info("logger")
+	ekalog.WithClock(func() time.Time { return t1 }).WithClock(nil).Info("reset")
+
+	assert.Equal(t,
+		"2022-01-02T03:04:05Z integrator;"+
+			"2022-01-02T04:04:05Z logger;"+
+			"2022-01-02T03:04:05Z reset;", b.String())
+}
+
+func TestCommonIntegrator_WithClock_Fallback(t *testing.T) {
+
+	t0 := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	var (
+		primary  = &tFailingWriter{fail: true}
+		fallback = new(tFailingWriter)
+	)
+
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{t/RFC3339}} {{m}};")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteToWithFallback(primary, fallback).
+		WithClock(func() time.Time { return t0 }))
+
+	ekalog.Info("entry")
+
+	// Self-diagnostic Entry is stamped by the same clock.
+	assert.Equal(t,
+		"2022-01-02T03:04:05Z entry;"+
+			"2022-01-02T03:04:05Z ekalog: writer failover;", fallback.String())
+}
@@ -0,0 +1,161 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"sync/atomic"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekatyp"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// CORRELATION_ID_FIELD_KEY is a key of the field the Logger's correlation ID
+	// is attached to the log entries by. Read more: Logger.WithNewCorrelationID().
+	CORRELATION_ID_FIELD_KEY = "correlation_id"
+
+	// REQUEST_ID_FIELD_KEY is a key of the field, that is treated
+	// as an explicit correlation ID if it's presented in the log entry.
+	REQUEST_ID_FIELD_KEY = "request_id"
+)
+
+var (
+	// correlationIDGenerator contains func() string, that generates
+	// a new correlation ID. Read more: SetCorrelationIDGenerator().
+	correlationIDGenerator atomic.Value
+)
+
+// SetCorrelationIDGenerator sets the function, that generates a new
+// correlation ID for Logger.WithNewCorrelationID().
+// If 'generator' is nil, the default one is used, that generates ULID.
+//
+// It's safe for concurrent use.
+func SetCorrelationIDGenerator(generator func() string) {
+	if generator == nil {
+		generator = defaultCorrelationIDGenerator
+	}
+	correlationIDGenerator.Store(generator)
+}
+
+// WithNewCorrelationID returns a copy of the current Logger with a newly generated
+// correlation ID (ULID by default, see SetCorrelationIDGenerator()).
+//
+// Each log entry written by returned Logger (and all Loggers derived from it)
+// gets CORRELATION_ID_FIELD_KEY field with that ID, unless it has its own
+// CORRELATION_ID_FIELD_KEY or REQUEST_ID_FIELD_KEY field. In the last case,
+// the value of that field is used as the correlation ID of the log entry.
+//
+// The attached ekaerr.Error w/o correlation ID gets the log entry's one
+// (see ekaerr.Error.CorrelationID()), so the error and log records share it.
+// And vice versa, the log entry of Logger w/o correlation ID gets the one
+// of the attached ekaerr.Error if it's presented.
+//
+// Unlike With... methods, it always makes a copy of the current Logger.
+func (l *Logger) WithNewCorrelationID() *Logger {
+	return l.setCorrelationID(newCorrelationID())
+}
+
+// WithCorrelationID is the same as WithNewCorrelationID() but uses 'id'
+// as correlation ID. Empty 'id' disables the correlation ID for returned Logger.
+func (l *Logger) WithCorrelationID(id string) *Logger {
+	return l.setCorrelationID(id)
+}
+
+// CorrelationID returns the current Logger's correlation ID
+// or "" if it has no one. Read more: WithNewCorrelationID().
+func (l *Logger) CorrelationID() string {
+	if !l.IsValid() {
+		return ""
+	}
+	return l.correlationID
+}
+
+// WithNewCorrelationID returns a copy of the package-level Logger
+// with a newly generated correlation ID.
+// See Logger.WithNewCorrelationID() for more details.
+func WithNewCorrelationID() *Logger {
+	return baseLogger.setCorrelationID(newCorrelationID())
+}
+
+// WithCorrelationID returns a copy of the package-level Logger with 'id'
+// as correlation ID. See Logger.WithCorrelationID() for more details.
+func WithCorrelationID(id string) *Logger {
+	return baseLogger.setCorrelationID(id)
+}
+
+// setCorrelationID checks whether Logger is valid, not nop Logger
+// and returns its copy with the provided correlation ID.
+func (l *Logger) setCorrelationID(id string) *Logger {
+	l.assert()
+	if l == nopLogger {
+		return l
+	}
+	newLogger := l.derive()
+	newLogger.correlationID = id
+	return newLogger
+}
+
+// addCorrelationID attaches the correlation ID to the Entry and to its
+// ekaerr.Error (if any). Read more: Logger.WithNewCorrelationID().
+//
+// If 'sharedFields' is true, the Entry's fields slice is the caller's one,
+// so it's not modified but reallocated.
+func (e *Entry) addCorrelationID(id string, err *ekaerr.Error, sharedFields bool) {
+
+	explicitID, found := e.correlationIDField()
+	if explicitID != "" {
+		id = explicitID
+	}
+	if id == "" {
+		id = err.CorrelationID()
+	}
+	if id == "" {
+		return
+	}
+
+	if err.IsValid() && err.CorrelationID() == "" {
+		err.WithCorrelationID(id)
+	}
+
+	if !found {
+		fs := e.LogLetter.Fields
+		if sharedFields {
+			fs = fs[:len(fs):len(fs)]
+		}
+		e.LogLetter.Fields = append(fs, ekaletter.FString(CORRELATION_ID_FIELD_KEY, id))
+	}
+}
+
+// correlationIDField returns the string value of Entry's
+// CORRELATION_ID_FIELD_KEY or REQUEST_ID_FIELD_KEY field and true,
+// or false if there's no such field.
+func (e *Entry) correlationIDField() (string, bool) {
+	fs := e.LogLetter.Fields
+	for i, n := 0, len(fs); i < n; i++ {
+		if fs[i].Key != CORRELATION_ID_FIELD_KEY && fs[i].Key != REQUEST_ID_FIELD_KEY {
+			continue
+		}
+		if !fs[i].Kind.IsSystem() && fs[i].Kind.BaseType() == ekaletter.KIND_TYPE_STRING {
+			return fs[i].SValue, true
+		}
+		return "", true
+	}
+	return "", false
+}
+
+// newCorrelationID returns a new correlation ID
+// generated by the current correlation ID's generator.
+func newCorrelationID() string {
+	return correlationIDGenerator.Load().(func() string)()
+}
+
+// defaultCorrelationIDGenerator is the default correlation ID's generator,
+// that generates ULID.
+func defaultCorrelationIDGenerator() string {
+	return ekatyp.ULID_New_OrNil().String()
+}
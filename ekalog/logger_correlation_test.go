@@ -0,0 +1,60 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_WithNewCorrelationID(t *testing.T) {
+
+	je := new(ekalog.CI_JSONEncoder)
+
+	log := ekalog.Copy()
+	assert.Equal(t, "", log.CorrelationID())
+
+	scoped := log.WithNewCorrelationID()
+	id := scoped.CorrelationID()
+	assert.Len(t, id, 26)
+	assert.Equal(t, "", log.CorrelationID())
+	assert.Equal(t, id, scoped.Copy().WithString("k", "v").CorrelationID())
+	assert.NotEqual(t, id, log.WithNewCorrelationID().CorrelationID())
+
+	out := testJSONEncoderOutput(je, func() {
+		scoped.Info("scoped")
+	})
+	require.Contains(t, out, "fields")
+	assert.Equal(t, map[string]any{"correlation_id": id}, out["fields"])
+
+	out = testJSONEncoderOutput(je, func() {
+		scoped.Infow("explicit", ekaunsafe.FString("request_id", "req-1"))
+	})
+	assert.Equal(t, map[string]any{"request_id": "req-1"}, out["fields"])
+
+	out = testJSONEncoderOutput(je, func() {
+		log.Info("unscoped")
+	})
+	assert.NotContains(t, out, "fields")
+
+	out = testJSONEncoderOutput(je, func() {
+		log.Errore("failed", ekaerr.IllegalState.New("boom").WithCorrelationID("corr-1"))
+	})
+	require.Contains(t, out, "fields")
+	assert.Equal(t, "corr-1", out["fields"].(map[string]any)["correlation_id"])
+
+	ekalog.SetCorrelationIDGenerator(func() string { return "fixed" })
+	defer ekalog.SetCorrelationIDGenerator(nil)
+
+	assert.Equal(t, "fixed", ekalog.WithNewCorrelationID().CorrelationID())
+	assert.Equal(t, "custom", ekalog.WithCorrelationID("custom").CorrelationID())
+}
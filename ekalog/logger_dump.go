@@ -0,0 +1,49 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"github.com/qioalice/ekago/v3/ekadump"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// Dump adds a string field 'key' with 'v' rendered by ekadump.Dump():
+// a bounded-depth, cycle-safe, size-capped dump of arbitrary value
+// (maps are sorted, unexported fields are omitted).
+// Unlike WithAny() or WithObject(), it never panics on cyclic data
+// and never produces unbounded output:
+//
+//	log.Dump("req", req).Debug("request is received")
+//
+// The value is rendered right now, even if the log entry will be dropped
+// by its level, so guard it by the level check in the hot paths.
+// Use DumpWith() to change the limits.
+//
+// Like With... methods, it DOES NOT make a copy of current Logger.
+func (l *Logger) Dump(key string, v any) *Logger {
+	return l.DumpWith(key, v, ekadump.DefaultOptions)
+}
+
+// DumpWith is the same as Dump() but renders 'v' using 'opts'.
+func (l *Logger) DumpWith(key string, v any, opts ekadump.Options) *Logger {
+	l.assert()
+	if l == nopLogger {
+		return l
+	}
+	return l.addField(ekaletter.FString(key, opts.Dump(v)))
+}
+
+// Dump adds a string field 'key' with 'v' rendered by ekadump.Dump()
+// to the package-level Logger. See Logger.Dump() for more details.
+func Dump(key string, v any) *Logger {
+	return baseLogger.Dump(key, v)
+}
+
+// DumpWith is the same as Dump() but renders 'v' using 'opts'.
+// See Logger.DumpWith() for more details.
+func DumpWith(key string, v any, opts ekadump.Options) *Logger {
+	return baseLogger.DumpWith(key, v, opts)
+}
@@ -0,0 +1,37 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekadump"
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tDumpNode struct {
+	ID   int
+	Next *tDumpNode
+}
+
+func TestLogger_Dump(t *testing.T) {
+
+	n := &tDumpNode{ID: 1}
+	n.Next = n
+
+	out := testConsoleEncoderOutput("{{m}} {{f/v=}}", func() {
+		ekalog.Copy().Dump("node", n).Debug("cyclic")
+	})
+	assert.Equal(t,
+		`cyclic node="&ekalog_test.tDumpNode{ID: 1, Next: <cycle *ekalog_test.tDumpNode>}"`, out)
+
+	out = testConsoleEncoderOutput("{{m}} {{f/v=}}", func() {
+		ekalog.Copy().DumpWith("ids", []int{1, 2, 3}, ekadump.Options{MaxItems: 1}).Debug("limited")
+	})
+	assert.Equal(t, `limited ids="[]int{1, ...}"`, out)
+}
@@ -0,0 +1,90 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekadeath"
+)
+
+type (
+	// DeathHandler is a function that is called after LEVEL_FATAL (LEVEL_EMERGENCY)
+	// log message is written and all writers are flushed.
+	// Read more: SetDeathHandler().
+	DeathHandler func()
+
+	// integratorFlusher is an Integrator (like CommonIntegrator)
+	// that may be flushed honoring context.Context's deadline.
+	integratorFlusher interface {
+		Flush(ctx context.Context) error
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// LEVEL_FATAL is an alias of LEVEL_EMERGENCY. Logging using this Level
+	// flushes all writers and then calls DeathHandler (see SetDeathHandler()).
+	LEVEL_FATAL = LEVEL_EMERGENCY
+
+	// FATAL_FLUSH_TIMEOUT is the max time writers are flushed
+	// before DeathHandler is called.
+	FATAL_FLUSH_TIMEOUT = 5 * time.Second
+)
+
+var (
+	// deathHandler contains the current DeathHandler.
+	// Read more: SetDeathHandler().
+	deathHandler atomic.Value
+)
+
+// SetDeathHandler sets DeathHandler, that is called after LEVEL_FATAL
+// (LEVEL_EMERGENCY) log message is written and all writers are flushed.
+// Nil 'handler' means the default one, that calls ekadeath.Die(1). Thread-safe.
+//
+// It's useful for tests or if you want to do something else instead of
+// shutting down the app. Keep in mind, if DeathHandler returns,
+// the Logger's finisher returns too and your code will continue its execution.
+func SetDeathHandler(handler DeathHandler) {
+	if handler == nil {
+		handler = deathHandlerDefault
+	}
+	deathHandler.Store(handler)
+}
+
+// flushAndDie flushes 'integrator' (or any of Integrator it wraps) if it's
+// an integratorFlusher or just syncs it, and then calls DeathHandler.
+func flushAndDie(integrator Integrator) {
+
+	ctx, cancel := context.WithTimeout(context.Background(), FATAL_FLUSH_TIMEOUT)
+	defer cancel()
+
+	for flushed := false; !flushed; {
+		if flusher, ok := integrator.(integratorFlusher); ok {
+			_ = flusher.Flush(ctx)
+			flushed = true
+		} else if wrapped, ok := unwrapIntegratorOnce(integrator); ok {
+			integrator = wrapped
+		} else {
+			_ = integrator.Sync()
+			flushed = true
+		}
+	}
+
+	handler, _ := deathHandler.Load().(DeathHandler)
+	if handler == nil {
+		handler = deathHandlerDefault
+	}
+
+	handler()
+}
+
+// deathHandlerDefault is the default DeathHandler.
+func deathHandlerDefault() {
+	ekadeath.Die(1)
+}
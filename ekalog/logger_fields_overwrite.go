@@ -0,0 +1,71 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// WithFieldsOverwrite enables or disables the fields overwrite mode
+// of the current Logger. If it's enabled, adding a field (using With... methods
+// or finishers) with the key of existed field overwrites (removes) the existed one
+// instead of adding a duplicate, so encoders never emit duplicated keys.
+// It's disabled by default. Use WithDuplicate() to add a duplicate explicitly anyway.
+//
+// Keep in mind, the key is compared after the group is applied (see WithGroup()).
+// The fields of attached ekaerr.Error are not affected,
+// use ekaerr.Error.WithFieldsOverwrite() for them.
+//
+// WithFieldsOverwrite DO NOT makes a copy of current Logger, like any other With method.
+func (l *Logger) WithFieldsOverwrite(enabled bool) *Logger {
+	return l.setFieldsOverwrite(enabled)
+}
+
+// WithDuplicate is the same as With() but adds provided ekaletter.LetterField
+// even if the fields overwrite mode is enabled and there is a field
+// with the same key already.
+//
+// WithDuplicate DO NOT makes a copy of current Logger, like any other With method.
+func (l *Logger) WithDuplicate(f ekaletter.LetterField) *Logger {
+	return l.addDuplicate(f)
+}
+
+// WithFieldsOverwrite enables or disables the fields overwrite mode
+// of the package-level Logger. See Logger.WithFieldsOverwrite() for more details.
+func WithFieldsOverwrite(enabled bool) *Logger {
+	return baseLogger.setFieldsOverwrite(enabled)
+}
+
+// WithDuplicate adds provided ekaletter.LetterField to the package-level Logger
+// even if there is a field with the same key already.
+// See Logger.WithDuplicate() for more details.
+func WithDuplicate(f ekaletter.LetterField) *Logger {
+	return baseLogger.addDuplicate(f)
+}
+
+// setFieldsOverwrite checks whether Logger is valid, not nop Logger and
+// enables or disables the fields overwrite mode of its Entry.
+func (l *Logger) setFieldsOverwrite(enabled bool) *Logger {
+	l.assert()
+	if l == nopLogger {
+		return l
+	}
+	ekaletter.LSetFieldsOverwrite(l.entry.LogLetter, enabled)
+	return l
+}
+
+// addDuplicate is the same as addField() but w/o fields deduplication.
+func (l *Logger) addDuplicate(f ekaletter.LetterField) *Logger {
+	l.assert()
+	if l == nopLogger || f.IsInvalid() || f.RemoveVary() && f.IsZero() {
+		return l
+	}
+	n := len(l.entry.LogLetter.Fields)
+	f.Key = l.entry.groupKey(f.Key)
+	ekaletter.LAddField(l.entry.LogLetter, f)
+	ekaletter.LApplyUnnamedKeys(l.entry.LogLetter, n)
+	return l
+}
@@ -0,0 +1,67 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_WithFieldsOverwrite(t *testing.T) {
+
+	out := testConsoleEncoderOutput("{{m}}{{f/?^ /v=/e,}}", func() {
+		ekalog.Copy().
+			WithFieldsOverwrite(true).
+			WithInt("id", 1).
+			WithString("a", "x").
+			WithInt("id", 2).
+			Info("msg", "id", 3)
+	})
+	assert.Equal(t, `msg a="x",id=3`, out)
+
+	fields := []ekaunsafe.LetterField{ekaunsafe.FInt("a", 1), ekaunsafe.FInt("a", 2)}
+	out = testConsoleEncoderOutput("{{m}}{{f/?^ /v=/e,}}", func() {
+		ekalog.Copy().WithFieldsOverwrite(true).Infow("msg", fields...)
+	})
+	assert.Equal(t, `msg a=2`, out)
+	assert.EqualValues(t, 1, fields[0].IValue, "caller's fields must not be modified")
+
+	out = testConsoleEncoderOutput("{{m}}{{f/?^ /v=/e,}}", func() {
+		ekalog.Copy().
+			WithFieldsOverwrite(true).
+			WithInt("id", 1).
+			WithDuplicate(ekaunsafe.FInt("id", 2)).
+			Info("msg")
+	})
+	assert.Equal(t, `msg id=1,id=2`, out)
+
+	b := bytes.NewBuffer(nil)
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_JSONEncoder)).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(b))
+	ekalog.Copy().WithFieldsOverwrite(true).WithGroup("g").
+		WithInt("id", 1).WithInt("id", 2).Info("msg", "id", 3)
+	assert.Equal(t, 1, strings.Count(b.String(), `"g.id"`))
+	assert.Contains(t, b.String(), `"g.id":3`)
+
+	err := ekaerr.IllegalArgument.New("bad").
+		WithFieldsOverwrite(true).
+		WithInt("id", 1).
+		WithInt("id", 2)
+	out = testConsoleEncoderOutput("{{s}}", func() {
+		ekalog.Errore("", err)
+	})
+	assert.Equal(t, 1, strings.Count(out, "id"))
+	assert.Contains(t, out, "2")
+}
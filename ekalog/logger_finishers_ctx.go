@@ -0,0 +1,69 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"context"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// LogwCtx is the same as Logw(level, msg, fields...) but also records Entry
+// as an event of the active span extracted from 'ctx' if it's important enough.
+// Read more: RegisterSpanExtractor(), SetSpanEventsMinLevel().
+func (l *Logger) LogwCtx(ctx context.Context, level Level, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logCtx(ctx, level, msg, nil, fields)
+}
+
+// LogewCtx is the same as LogwCtx() but also attaches 'err', that is recorded
+// using SpanRecorder.RecordError().
+// Read more: RegisterSpanExtractor(), SetSpanEventsMinLevel().
+func (l *Logger) LogewCtx(ctx context.Context, level Level, msg string, err *ekaerr.Error, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logCtx(ctx, level, msg, err, fields)
+}
+
+// DebugwCtx is the same as LogwCtx(ctx, LEVEL_DEBUG, msg, fields...).
+// Read more: Logger.LogwCtx().
+func (l *Logger) DebugwCtx(ctx context.Context, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logCtx(ctx, LEVEL_DEBUG, msg, nil, fields)
+}
+
+// InfowCtx is the same as LogwCtx(ctx, LEVEL_INFO, msg, fields...).
+// Read more: Logger.LogwCtx().
+func (l *Logger) InfowCtx(ctx context.Context, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logCtx(ctx, LEVEL_INFO, msg, nil, fields)
+}
+
+// NoticewCtx is the same as LogwCtx(ctx, LEVEL_NOTICE, msg, fields...).
+// Read more: Logger.LogwCtx().
+func (l *Logger) NoticewCtx(ctx context.Context, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logCtx(ctx, LEVEL_NOTICE, msg, nil, fields)
+}
+
+// WarnwCtx is the same as LogwCtx(ctx, LEVEL_WARNING, msg, fields...).
+// Read more: Logger.LogwCtx().
+func (l *Logger) WarnwCtx(ctx context.Context, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logCtx(ctx, LEVEL_WARNING, msg, nil, fields)
+}
+
+// ErrorwCtx is the same as LogwCtx(ctx, LEVEL_ERROR, msg, fields...).
+// Read more: Logger.LogwCtx().
+func (l *Logger) ErrorwCtx(ctx context.Context, msg string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logCtx(ctx, LEVEL_ERROR, msg, nil, fields)
+}
+
+// WarnewCtx is the same as LogewCtx(ctx, LEVEL_WARNING, msg, err, fields...).
+// Read more: Logger.LogewCtx().
+func (l *Logger) WarnewCtx(ctx context.Context, msg string, err *ekaerr.Error, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logCtx(ctx, LEVEL_WARNING, msg, err, fields)
+}
+
+// ErrorewCtx is the same as LogewCtx(ctx, LEVEL_ERROR, msg, err, fields...).
+// Read more: Logger.LogewCtx().
+func (l *Logger) ErrorewCtx(ctx context.Context, msg string, err *ekaerr.Error, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logCtx(ctx, LEVEL_ERROR, msg, err, fields)
+}
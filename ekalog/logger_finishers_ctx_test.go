@@ -0,0 +1,102 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"context"
+	"io"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type (
+	testSpanRecorder struct {
+		events []string
+		errors []string
+		attrs  map[string]string
+	}
+	testSpanKey struct{}
+)
+
+func (r *testSpanRecorder) AddEvent(name string, attrs []ekalog.SpanAttribute) {
+	r.events = append(r.events, name)
+	r.saveAttrs(attrs)
+}
+
+func (r *testSpanRecorder) RecordError(err error, attrs []ekalog.SpanAttribute) {
+	r.errors = append(r.errors, err.Error())
+	r.saveAttrs(attrs)
+}
+
+func (r *testSpanRecorder) saveAttrs(attrs []ekalog.SpanAttribute) {
+	r.attrs = make(map[string]string)
+	for _, attr := range attrs {
+		r.attrs[attr.Key] = attr.Value
+	}
+}
+
+func TestLogger_LogwCtx(t *testing.T) {
+
+	ekalog.RegisterSpanExtractor(func(ctx context.Context) (ekalog.SpanRecorder, bool) {
+		r, ok := ctx.Value(testSpanKey{}).(*testSpanRecorder)
+		return r, ok
+	})
+
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder)).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(io.Discard))
+
+	r := new(testSpanRecorder)
+	ctx := context.WithValue(context.Background(), testSpanKey{}, r)
+
+	ekalog.InfowCtx(ctx, "not recorded")
+	ekalog.WarnwCtx(ctx, "recorded", ekaunsafe.FInt("n", 42))
+	ekalog.WarnwCtx(context.Background(), "no span")
+
+	require.Equal(t, []string{"recorded"}, r.events)
+	assert.Equal(t, "42", r.attrs["n"])
+	assert.Equal(t, "Warning", r.attrs["level"])
+
+	err := ekaerr.IllegalArgument.New("bad value").WithInt("id", 7)
+	errID := err.ID()
+	ekalog.ErrorewCtx(ctx, "failed", err)
+
+	require.Len(t, r.errors, 1)
+	assert.Contains(t, r.errors[0], "failed: ")
+	assert.Contains(t, r.errors[0], "bad value")
+	assert.Equal(t, errID, r.attrs["error_id"])
+	assert.Equal(t, "7", r.attrs["id"])
+	assert.Len(t, r.attrs["error_fingerprint"], 16)
+
+	ekalog.SetSpanEventsMinLevel(ekalog.LEVEL_INFO)
+	defer ekalog.SetSpanEventsMinLevel(ekalog.LEVEL_WARNING)
+
+	ekalog.InfowCtx(ctx, "recorded too")
+	assert.Equal(t, []string{"recorded", "recorded too"}, r.events)
+}
+
+func TestLogger_LogwCtx_Caller(t *testing.T) {
+
+	ctx := context.Background()
+	callsites := testFinisherCallsites(t, func() {
+		ekalog.Copy().InfowCtx(ctx, "info")
+		ekalog.InfowCtx(ctx, "info")
+		ekalog.Copy().WarnwCtx(ctx, "warn")
+		ekalog.WarnwCtx(ctx, "warn")
+	})
+
+	require.Len(t, callsites, 4)
+	for _, callsite := range callsites {
+		assert.Contains(t, callsite, "TestLogger_LogwCtx_Caller.func1")
+	}
+}
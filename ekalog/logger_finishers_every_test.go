@@ -0,0 +1,80 @@
+// Copyright © 2021. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestInfowEvery(t *testing.T) {
+
+	out := testConsoleEncoderOutput("{{m/?$ }}{{f/v=/?$;}}", func() {
+		for i := 0; i < 4; i++ {
+			if i == 3 {
+				time.Sleep(60 * time.Millisecond)
+			}
+			ekalog.InfowEvery(50*time.Millisecond, "tick")
+		}
+	})
+
+	assert.Equal(t, "tick tick suppressed=2;", out)
+}
+
+// testFinisherCallsites returns the callsites of log entries, 'log' writes:
+// the caller of LEVEL_INFO (and less important) entries or the top frame
+// of the stacktrace of LEVEL_WARNING (and more important) ones.
+func testFinisherCallsites(t *testing.T, log func()) []string {
+
+	b := bytes.NewBuffer(nil)
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_JSONEncoder)).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WithMinLevelForCaller(ekalog.LEVEL_DEBUG).
+		WriteTo(b))
+
+	log()
+
+	var callsites []string
+	for _, line := range strings.Split(strings.TrimSpace(b.String()), "\n") {
+		var entry struct {
+			Caller     string `json:"caller"`
+			StackTrace []struct {
+				Func string `json:"func"`
+			} `json:"stacktrace"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if len(entry.StackTrace) > 0 {
+			entry.Caller = entry.StackTrace[0].Func
+		}
+		callsites = append(callsites, entry.Caller)
+	}
+
+	return callsites
+}
+
+func TestLogger_LogwEvery_Caller(t *testing.T) {
+
+	callsites := testFinisherCallsites(t, func() {
+		ekalog.Copy().InfowEvery(time.Second, "tick")
+		ekalog.InfowEvery(time.Second, "tick")
+		ekalog.Copy().WarnwEvery(time.Second, "tick")
+		ekalog.WarnwEvery(time.Second, "tick")
+	})
+
+	require.Len(t, callsites, 4)
+	for _, callsite := range callsites {
+		assert.Contains(t, callsite, "TestLogger_LogwEvery_Caller.func1")
+	}
+}
@@ -0,0 +1,57 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testFlushWriter struct {
+	bytes.Buffer
+	flushed int
+}
+
+func (w *testFlushWriter) Flush(_ context.Context) error {
+	w.flushed++
+	return nil
+}
+
+func TestLogger_Fatal(t *testing.T) {
+
+	w := new(testFlushWriter)
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m}}")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(w))
+
+	var died, flushedBeforeDeath int
+	ekalog.SetDeathHandler(func() {
+		died++
+		flushedBeforeDeath = w.flushed
+	})
+	defer ekalog.SetDeathHandler(nil)
+
+	ekalog.Fatalw("fatal")
+	assert.Equal(t, "fatal", w.String())
+	assert.Equal(t, 1, died)
+	assert.Equal(t, 1, flushedBeforeDeath)
+
+	ekalog.Emerg("emergency")
+	assert.Equal(t, 2, died)
+
+	w.Reset()
+	assert.PanicsWithValue(t, "bad 42", func() {
+		ekalog.Panicf("bad %d", 42)
+	})
+	assert.Equal(t, "bad 42", w.String())
+	assert.Equal(t, 2, died)
+}
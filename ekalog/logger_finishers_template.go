@@ -0,0 +1,72 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// Logt writes log message with desired 'level', building it from 'template'
+// with named placeholders, like "user {user_id} failed {op}".
+// Each placeholder is substituted by the value of the field with the same key
+// from 'fields', and the fields are still attached to the log message
+// as structured data. Placeholders w/o corresponding fields are left as is.
+// Use "{{" and "}}" to write "{" and "}".
+//
+// The template is parsed once and cached for each callsite (a place in your code
+// this method is called from), so it's cheap to use constant templates.
+func (l *Logger) Logt(level Level, template string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logTemplate(level, template, fields)
+}
+
+// Debugt is the same as Logt(LEVEL_DEBUG, template, fields...).
+// Read more: Logger.Logt().
+func (l *Logger) Debugt(template string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logTemplate(LEVEL_DEBUG, template, fields)
+}
+
+// Infot is the same as Logt(LEVEL_INFO, template, fields...).
+// Read more: Logger.Logt().
+func (l *Logger) Infot(template string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logTemplate(LEVEL_INFO, template, fields)
+}
+
+// Noticet is the same as Logt(LEVEL_NOTICE, template, fields...).
+// Read more: Logger.Logt().
+func (l *Logger) Noticet(template string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logTemplate(LEVEL_NOTICE, template, fields)
+}
+
+// Warnt is the same as Logt(LEVEL_WARNING, template, fields...).
+// Read more: Logger.Logt().
+func (l *Logger) Warnt(template string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logTemplate(LEVEL_WARNING, template, fields)
+}
+
+// Errort is the same as Logt(LEVEL_ERROR, template, fields...).
+// Read more: Logger.Logt().
+func (l *Logger) Errort(template string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logTemplate(LEVEL_ERROR, template, fields)
+}
+
+// Critt is the same as Logt(LEVEL_CRITICAL, template, fields...).
+// Read more: Logger.Logt().
+func (l *Logger) Critt(template string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logTemplate(LEVEL_CRITICAL, template, fields)
+}
+
+// Alertt is the same as Logt(LEVEL_ALERT, template, fields...).
+// Read more: Logger.Logt().
+func (l *Logger) Alertt(template string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logTemplate(LEVEL_ALERT, template, fields)
+}
+
+// Emergt is the same as Logt(LEVEL_EMERGENCY, template, fields...),
+// but also then calls DeathHandler (ekadeath.Die(1) by default).
+// Read more: Logger.Logt().
+func (l *Logger) Emergt(template string, fields ...ekaletter.LetterField) (this *Logger) {
+	return l.logTemplate(LEVEL_EMERGENCY, template, fields)
+}
@@ -0,0 +1,68 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_Logt(t *testing.T) {
+
+	je := new(ekalog.CI_JSONEncoder)
+
+	for i := 0; i < 2; i++ { // the second iteration uses cached template
+		out := testJSONEncoderOutput(je, func() {
+			ekalog.Infot("user {user_id} failed {op} in {took} {unknown} {{literal}}",
+				ekaunsafe.FInt("user_id", 42),
+				ekaunsafe.FString("op", "login"),
+				ekaunsafe.FDuration("took", 1500*time.Millisecond),
+			)
+		})
+
+		assert.Equal(t, "user 42 failed login in 1.5s {unknown} {literal}", out["message"])
+		require.Contains(t, out, "fields")
+		assert.Equal(t, map[string]any{
+			"user_id": float64(42),
+			"op":      "login",
+			"took":    "1.5s",
+		}, out["fields"])
+	}
+
+	// The same callsite, but different templates.
+	text := testConsoleEncoderOutput("{{m}}", func() {
+		for _, template := range []string{"first {v};", "second {v}"} {
+			ekalog.Warnt(template, ekaunsafe.FBool("v", true))
+		}
+	})
+	assert.Equal(t, "first true;second true", text)
+
+	out := testJSONEncoderOutput(je, func() {
+		ekalog.Infot(`json-like {"a": 1} and {}`, ekaunsafe.FInt("a", 1))
+	})
+	assert.Equal(t, `json-like {"a": 1} and {}`, out["message"])
+}
+
+func TestLogger_Logt_Caller(t *testing.T) {
+
+	callsites := testFinisherCallsites(t, func() {
+		ekalog.Copy().Infot("user {user_id}", ekaunsafe.FInt("user_id", 1))
+		ekalog.Infot("user {user_id}", ekaunsafe.FInt("user_id", 1))
+		ekalog.Copy().Warnt("plain")
+		ekalog.Warnt("user {user_id}", ekaunsafe.FInt("user_id", 1))
+	})
+
+	require.Len(t, callsites, 4)
+	for _, callsite := range callsites {
+		assert.Contains(t, callsite, "TestLogger_Logt_Caller.func1")
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+// GROUP_SEPARATOR is a separator between group's name and field's key
+// (and between nested groups' names).
+// It's the same as CI_JSONEncoder's nested keys separator, so grouped fields
+// are encoded as nested JSON objects if CI_JSONEncoder.SetNestedKeys(true) is used.
+//
+//goland:noinspection GoSnakeCaseUsage
+const GROUP_SEPARATOR = "."
+
+// WithGroup establishes a namespace 'name' for the current Logger.
+// The keys of all fields that are added after (using With... methods or finishers)
+// are prefixed by 'name' and GROUP_SEPARATOR, like: "name.key".
+// The fields that have been added before are not affected. Unnamed fields
+// are not affected too.
+//
+// Groups may be nested: WithGroup("a").WithGroup("b") leads to "a.b.key".
+// Empty 'name' is ignored.
+//
+// WithGroup DO NOT makes a copy of current Logger, like any other With method.
+func (l *Logger) WithGroup(name string) *Logger {
+	return l.addGroup(name)
+}
+
+// WithGroup establishes a namespace 'name' for the package-level Logger.
+// See Logger.WithGroup() for more details.
+func WithGroup(name string) *Logger {
+	return baseLogger.addGroup(name)
+}
+
+// addGroup checks whether Logger is valid, not nop Logger and appends 'name'
+// to the current Logger's Entry's group. Returns the current Logger.
+func (l *Logger) addGroup(name string) *Logger {
+	l.assert()
+	if l == nopLogger || name == "" {
+		return l
+	}
+	l.entry.group = l.entry.groupKey(name)
+	return l
+}
+
+// groupKey returns 'key' prefixed by the Entry's group (if any).
+// Empty 'key' (unnamed field) is returned as is.
+func (e *Entry) groupKey(key string) string {
+	if e.group == "" || key == "" {
+		return key
+	}
+	return e.group + GROUP_SEPARATOR + key
+}
+
+// applyGroup prefixes the keys of Entry's ekaletter.Letter's fields
+// starting from 'from' index by the Entry's group (if any).
+func (e *Entry) applyGroup(from int) {
+	if e.group == "" {
+		return
+	}
+	fs := e.LogLetter.Fields
+	for i, n := from, len(fs); i < n; i++ {
+		fs[i].Key = e.groupKey(fs[i].Key)
+	}
+}
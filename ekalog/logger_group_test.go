@@ -0,0 +1,54 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogger_WithGroup(t *testing.T) {
+
+	je := new(ekalog.CI_JSONEncoder).SetNestedKeys(true)
+
+	out := testJSONEncoderOutput(je, func() {
+		ekalog.Copy().
+			WithGroup("http").
+			WithGroup("response").
+			Infow("grouped", ekaunsafe.FInt("status", 200))
+	})
+
+	require.Contains(t, out, "fields")
+	assert.Equal(t, map[string]any{
+		"http": map[string]any{
+			"response": map[string]any{
+				"status": float64(200),
+			},
+		},
+	}, out["fields"])
+
+	out = testJSONEncoderOutput(je, func() {
+		ekalog.Copy().
+			WithString("service", "api").
+			WithGroup("http").
+			WithString("method", "GET").
+			Info("grouped", "status", 200)
+	})
+
+	require.Contains(t, out, "fields")
+	assert.Equal(t, map[string]any{
+		"service": "api",
+		"http": map[string]any{
+			"method": "GET",
+			"status": float64(200),
+		},
+	}, out["fields"])
+}
@@ -0,0 +1,40 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+// Local returns a copy of the current Logger, the Entry of which must not
+// leave the host: CommonIntegrator writes them only to the local writers
+// (console, files, etc.) skipping the remote ones (read more: CI_WriterRemote).
+// It's the way to log the verbose data (PII, giant payload dumps, etc.),
+// that must stay on the host:
+//
+//	log.Local().Debug("request body", "body", body)
+//
+// The flag is inherited by the Loggers derived from returned one.
+// Read more: Entry.IsLocal().
+//
+// Unlike With... methods, it always makes a copy of the current Logger.
+func (l *Logger) Local() *Logger {
+	return l.setLocal()
+}
+
+// Local returns a copy of the package-level Logger, the Entry of which
+// must not leave the host. See Logger.Local() for more details.
+func Local() *Logger {
+	return baseLogger.setLocal()
+}
+
+// setLocal checks whether Logger is valid, not nop Logger
+// and returns its copy, the Entry of which are marked as local.
+func (l *Logger) setLocal() *Logger {
+	l.assert()
+	if l == nopLogger {
+		return l
+	}
+	newLogger := l.derive()
+	newLogger.entry.flags |= _ENTRY_FLAG_LOCAL
+	return newLogger
+}
@@ -0,0 +1,48 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type testRemoteWriter struct {
+	bytes.Buffer
+}
+
+func (_ *testRemoteWriter) IsRemote() bool {
+	return true
+}
+
+func TestLogger_Local(t *testing.T) {
+
+	var (
+		local    = bytes.NewBuffer(nil)
+		remote   = new(testRemoteWriter)
+		buffered = new(testRemoteWriter)
+		bw       = ekalog.NewBufferedWriter(buffered, 0, 0)
+	)
+
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m}};")).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(local, remote, bw))
+
+	ekalog.Info("public")
+	ekalog.Local().WithString("k", "v").Info("private")
+	ekalog.Info("public2")
+	_ = bw.Flush(context.Background())
+
+	assert.Equal(t, "public;private;public2;", local.String())
+	assert.Equal(t, "public;public2;", remote.String())
+	assert.Equal(t, "public;public2;", buffered.String())
+}
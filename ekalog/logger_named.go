@@ -0,0 +1,155 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// namedLogger is an entry of the named Loggers registry.
+	// Read more: Named().
+	namedLogger struct {
+		name string
+
+		// minLevel is Level + 1 or 0 if min level is not set.
+		// Atomic access only.
+		minLevel uint32
+
+		once   sync.Once
+		logger *Logger // created lazily by the first Named() call
+	}
+)
+
+// NAMED_LOGGER_FIELD_KEY is a key of the field a name of named Logger
+// is stored by. Read more: Named().
+//
+//goland:noinspection GoSnakeCaseUsage
+const NAMED_LOGGER_FIELD_KEY = "module"
+
+var (
+	// namedLoggers is a registry of named Loggers, their names to *namedLogger.
+	namedLoggers struct {
+		mu sync.Mutex
+		m  map[string]*namedLogger
+	}
+)
+
+// Named returns a child Logger of the package-level Logger with 'name',
+// that has a bound field NAMED_LOGGER_FIELD_KEY (e.g. "module":"db"),
+// and the min level, that may be changed at runtime by the name
+// using SetNamedLevel().
+//
+// So, each module of the app may have its own Logger, like:
+//
+//	var log = ekalog.Named("db")
+//
+// and then a verbosity of the "db" module may be changed independently
+// of others: SetNamedLevel("db", LEVEL_DEBUG).
+//
+// The min level of named Logger only restricts its Entry, that are handled
+// by the shared Integrator: Entry with Level, the Integrator doesn't handle,
+// won't be written anyway (see Integrator.MinLevelEnabled()).
+//
+// The named Logger is created once by the first call with 'name',
+// the next calls return its copies. All of them (and all Loggers derived
+// from them) share the same min level.
+// The package-level Logger's fields at the moment of the first call
+// are inherited.
+func Named(name string) *Logger {
+
+	nl := namedLoggerGet(name)
+	nl.once.Do(func() {
+		nl.logger = baseLogger.derive().WithString(NAMED_LOGGER_FIELD_KEY, name)
+		nl.logger.named = nl
+	})
+
+	return nl.logger.derive()
+}
+
+// SetNamedLevel sets the min level of named Logger with 'name'
+// (read more: Named()). It may be called even before the named Logger
+// is created, the min level will be applied then.
+func SetNamedLevel(name string, minLevel Level) {
+	atomic.StoreUint32(&namedLoggerGet(name).minLevel, uint32(minLevel)+1)
+}
+
+// UnsetNamedLevel resets the min level of named Logger with 'name',
+// so its Entry are restricted only by the Integrator's min level.
+func UnsetNamedLevel(name string) {
+	atomic.StoreUint32(&namedLoggerGet(name).minLevel, 0)
+}
+
+// NamedLevel returns the min level of named Logger with 'name'
+// and true if it's set by SetNamedLevel(), or false otherwise.
+func NamedLevel(name string) (Level, bool) {
+	return namedLoggerGet(name).level()
+}
+
+// NamedLoggers returns the sorted names of all named Loggers
+// (either created by Named() or configured by SetNamedLevel()).
+func NamedLoggers() []string {
+
+	namedLoggers.mu.Lock()
+	names := make([]string, 0, len(namedLoggers.m))
+	for name := range namedLoggers.m {
+		names = append(names, name)
+	}
+	namedLoggers.mu.Unlock()
+
+	sort.Strings(names)
+	return names
+}
+
+// Name returns the name of the current Logger if it's a named one
+// or derived from the named one (read more: Named()), or an empty string otherwise.
+func (l *Logger) Name() string {
+	l.assert()
+	if l.named == nil {
+		return ""
+	}
+	return l.named.name
+}
+
+// namedLoggerGet returns an entry of the named Loggers registry with 'name',
+// creating it if it's necessary.
+func namedLoggerGet(name string) *namedLogger {
+
+	namedLoggers.mu.Lock()
+	defer namedLoggers.mu.Unlock()
+
+	if namedLoggers.m == nil {
+		namedLoggers.m = make(map[string]*namedLogger)
+	}
+
+	nl := namedLoggers.m[name]
+	if nl == nil {
+		nl = &namedLogger{name: name}
+		namedLoggers.m[name] = nl
+	}
+
+	return nl
+}
+
+// level returns the min level and true if it's set, or false otherwise.
+func (nl *namedLogger) level() (Level, bool) {
+	if lvl := atomic.LoadUint32(&nl.minLevel); lvl != 0 {
+		return Level(lvl - 1), true
+	}
+	return 0, false
+}
+
+// enabled reports whether Entry with provided Level is allowed
+// by the named Logger's min level. Nil safe, returns true for nil.
+func (nl *namedLogger) enabled(lvl Level) bool {
+	if nl == nil {
+		return true
+	}
+	minLevel, ok := nl.level()
+	return !ok || lvl.IsEnabledFor(minLevel)
+}
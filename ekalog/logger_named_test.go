@@ -0,0 +1,52 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNamed(t *testing.T) {
+
+	ekalog.SetNamedLevel("test_http", ekalog.LEVEL_WARNING)
+
+	out := testConsoleEncoderOutput("{{m/?$ }}{{f/v=/e }}|", func() {
+		db := ekalog.Named("test_db")
+		http := ekalog.Named("test_http").WithInt("port", 80)
+
+		db.Debug("db debug")
+		http.Info("http info")
+		http.Warn("http warn")
+
+		ekalog.SetNamedLevel("test_db", ekalog.LEVEL_INFO)
+		db.Debug("db debug 2")
+		db.Copy().WithInt("n", 1).Debug("db debug 3")
+
+		ekalog.UnsetNamedLevel("test_http")
+		http.Info("http info 2")
+	})
+
+	assert.Equal(t,
+		`db debug module="test_db"|`+
+			`http warn module="test_http" port=80|`+
+			`http info 2 module="test_http" port=80|`,
+		out)
+
+	lvl, ok := ekalog.NamedLevel("test_db")
+	assert.True(t, ok)
+	assert.Equal(t, ekalog.LEVEL_INFO, lvl)
+
+	_, ok = ekalog.NamedLevel("test_http")
+	assert.False(t, ok)
+
+	assert.Subset(t, ekalog.NamedLoggers(), []string{"test_db", "test_http"})
+	assert.Equal(t, "test_db", ekalog.Named("test_db").WithInt("x", 1).Name())
+	assert.Equal(t, "", ekalog.Copy().Name())
+}
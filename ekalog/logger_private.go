@@ -7,10 +7,14 @@ package ekalog
 
 import (
 	"fmt"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 	"unsafe"
 
-	"github.com/qioalice/ekago/v3/ekadeath"
 	"github.com/qioalice/ekago/v3/ekaerr"
 	"github.com/qioalice/ekago/v3/internal/ekaclike"
 	"github.com/qioalice/ekago/v3/internal/ekaletter"
@@ -18,7 +22,47 @@ import (
 	"github.com/modern-go/reflect2"
 )
 
+type (
+	// integratorSlot is a holder of Logger's Integrator, that is shared between
+	// Logger and all Loggers derived from it, allowing Integrator to be replaced
+	// at runtime (see Logger.ReplaceIntegrator()).
+	integratorSlot struct {
+		v atomic.Value // always contains *integratorState
+	}
+
+	// integratorState is an Integrator with the counter of Entry objects
+	// that are being processed by it right now.
+	integratorState struct {
+		integrator Integrator
+		inFlight   int64         // atomic access only
+		isRetired  uint32        // 1 if Integrator has been replaced, atomic access only
+		released   chan struct{} // notifies replace() that Entry is done, cap 1
+	}
+
+	// rateLimitState is a state of one callsite of rate-limited Logger's finishers
+	// like Logger.LogwEvery(), Logger.InfowEvery(), etc.
+	rateLimitState struct {
+		mu         sync.Mutex
+		lastTime   time.Time // when the last log message has been written
+		suppressed uint64    // how much log messages were dropped since lastTime
+	}
+)
+
+const (
+	// rateLimitSuppressedFieldKey is a key of the field an amount of dropped
+	// log messages of rate-limited finishers is stored by.
+	rateLimitSuppressedFieldKey = "suppressed"
+
+	// integratorReplaceDrainTimeout is how long Logger.ReplaceIntegrator()
+	// waits for Entry objects that are being processed by the old Integrator.
+	integratorReplaceDrainTimeout = time.Second
+)
+
 var (
+	// rateLimitStates is a map of callsites (their PCs) to *rateLimitState
+	// for rate-limited Logger's finishers.
+	rateLimitStates sync.Map
+
 	// baseLogger is default package-level Logger, that used by all package-level
 	// logger functions.
 	baseLogger *Logger
@@ -26,6 +70,10 @@ var (
 	// nopLogger is a special Logger that is returned to indicate,
 	// that next methods must do nothing.
 	nopLogger *Logger
+
+	// logSkipEntryPC is the entry PC of Logger.logSkip(),
+	// that is used to find the Entry objects of the caller. Read more: ownInFlight().
+	logSkipEntryPC = reflect.ValueOf((*Logger).logSkip).Pointer()
 )
 
 func (l *Logger) assert() {
@@ -36,21 +84,126 @@ func (l *Logger) assert() {
 
 // levelEnabled reports whether Entry with provided Level should be handled.
 func (l *Logger) levelEnabled(lvl Level) bool {
-	return lvl <= l.integrator.MinLevelEnabled()
+	return (lvl.IsEnabledFor(l.integrator.current().MinLevelEnabled()) || l.trigger.active()) &&
+		l.named.enabled(lvl)
 }
 
 // derive returns a new Logger with cloned Entry based on current Logger.
+// The new Logger shares Integrator's holder with the current one.
 func (l *Logger) derive() (newLogger *Logger) {
-	return new(Logger).setIntegrator(l.integrator).setEntry(l.entry.clone())
+	newLogger = &Logger{
+		integrator:    l.integrator,
+		named:         l.named,
+		correlationID: l.correlationID,
+		trigger:       l.trigger,
+		clock:         l.clock,
+	}
+	return newLogger.setEntry(l.entry.clone())
 }
 
-// setIntegrator changes the Logger's Integrator to the passed.
-// It's just assignment nothing more. Useful at the method chaining.
+// setIntegrator creates a new Integrator's holder for the Logger
+// with the passed Integrator. Useful at the method chaining.
 func (l *Logger) setIntegrator(newIntegrator Integrator) (this *Logger) {
-	l.integrator = newIntegrator
+	l.integrator = new(integratorSlot)
+	l.integrator.v.Store(newIntegratorState(newIntegrator))
 	return l
 }
 
+// newIntegratorState returns a new integratorState of the passed Integrator.
+func newIntegratorState(integrator Integrator) *integratorState {
+	return &integratorState{
+		integrator: integrator,
+		released:   make(chan struct{}, 1),
+	}
+}
+
+// current returns the current Integrator.
+func (s *integratorSlot) current() Integrator {
+	return s.v.Load().(*integratorState).integrator
+}
+
+// acquire returns the current Integrator's state, marking that one more Entry
+// is being processed by it. The caller must call release() then.
+func (s *integratorSlot) acquire() *integratorState {
+	for {
+		st := s.v.Load().(*integratorState)
+		atomic.AddInt64(&st.inFlight, 1)
+		if s.v.Load().(*integratorState) == st {
+			return st
+		}
+		// Integrator has been replaced meanwhile. Try again with a new one.
+		st.release()
+	}
+}
+
+// release marks that Entry has been processed by the Integrator.
+// If Integrator has been replaced already, replace() is notified.
+func (st *integratorState) release() {
+	if atomic.AddInt64(&st.inFlight, -1) >= 0 && atomic.LoadUint32(&st.isRetired) == 1 {
+		select {
+		case st.released <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// replace replaces the current Integrator by the passed one, waits until
+// all Entry objects that are being processed by the old Integrator are done
+// and returns the old Integrator.
+//
+// If replace() is called from the logging path (e.g. by io.Writer),
+// the Entry objects of the caller are not waited for, since they are never done
+// while it waits. The old Integrator is not drained then.
+//
+// The waiting is also limited by integratorReplaceDrainTimeout just in case
+// of the logging callback that has panicked or waits for another goroutine,
+// that calls replace().
+// Returns false if the old Integrator still may be in use.
+func (s *integratorSlot) replace(newIntegrator Integrator) (oldIntegrator Integrator, isDrained bool) {
+
+	old := s.v.Swap(newIntegratorState(newIntegrator)).(*integratorState)
+	atomic.StoreUint32(&old.isRetired, 1)
+
+	own := ownInFlight()
+	timer := time.NewTimer(integratorReplaceDrainTimeout)
+	defer timer.Stop()
+
+	for atomic.LoadInt64(&old.inFlight) > own {
+		select {
+		case <-old.released:
+		case <-timer.C:
+			return old.integrator, false
+		}
+	}
+
+	return old.integrator, atomic.LoadInt64(&old.inFlight) == 0
+}
+
+// ownInFlight returns how many Entry objects are being processed
+// by the current goroutine, i.e. how many logSkip() calls are in its stacktrace.
+func ownInFlight() (n int64) {
+
+	pcs := make([]uintptr, 64)
+	for {
+		if m := runtime.Callers(2, pcs); m < len(pcs) {
+			pcs = pcs[:m]
+			break
+		}
+		pcs = make([]uintptr, len(pcs)*2)
+	}
+
+	frames := runtime.CallersFrames(pcs)
+	for {
+		frame, more := frames.Next()
+		if frame.Entry == logSkipEntryPC {
+			n++
+		}
+		if !more {
+			return n
+		}
+	}
+}
+
 // setEntry changes the Logger's Entry to the passed. Also changes parent ptr
 // in newEntry to being pointed to the l. Useful at the method chaining.
 func (l *Logger) setEntry(newEntry *Entry) (this *Logger) {
@@ -68,7 +221,11 @@ func (l *Logger) addField(f ekaletter.LetterField) *Logger {
 	if l == nopLogger || f.IsInvalid() || f.RemoveVary() && f.IsZero() {
 		return l
 	}
+	n := len(l.entry.LogLetter.Fields)
+	f.Key = l.entry.groupKey(f.Key)
 	ekaletter.LAddField(l.entry.LogLetter, f)
+	ekaletter.LApplyUnnamedKeys(l.entry.LogLetter, n)
+	ekaletter.LDedupFields(l.entry.LogLetter, n)
 	return l
 }
 
@@ -79,9 +236,13 @@ func (l *Logger) addFields(fs []ekaletter.LetterField) *Logger {
 	if l == nopLogger || len(fs) == 0 {
 		return l
 	}
+	n := len(l.entry.LogLetter.Fields)
 	for i, n := 0, len(fs); i < n; i++ {
 		ekaletter.LAddFieldWithCheck(l.entry.LogLetter, fs[i])
 	}
+	l.entry.applyGroup(n)
+	ekaletter.LApplyUnnamedKeys(l.entry.LogLetter, n)
+	ekaletter.LDedupFields(l.entry.LogLetter, n)
 	return l
 }
 
@@ -95,7 +256,11 @@ func (l *Logger) addFieldsParse(fs []any) *Logger {
 	if l == nopLogger || len(fs) == 0 {
 		return l
 	}
+	n := len(l.entry.LogLetter.Fields)
 	ekaletter.LParseTo(l.entry.LogLetter, fs, true)
+	l.entry.applyGroup(n)
+	ekaletter.LApplyUnnamedKeys(l.entry.LogLetter, n)
+	ekaletter.LDedupFields(l.entry.LogLetter, n)
 	return l
 }
 
@@ -127,12 +292,19 @@ func (l *Logger) addFieldsParse(fs []any) *Logger {
 //     as printf-like string, or it was extracted from 'args[0]'.
 //
 //  3. Adds caller and stacktrace (if it necessary and if it wasn't provided
-//     by gext.errors.Error) (using Entry.addStacktrace method);
+//     by gext.errors.Error) (using Entry.addStacktrace method),
+//     or only caller's PC if stacktrace is not required for the Level,
+//     but caller is (read more: CommonIntegrator.WithMinLevelForCaller());
 //
 //     Uses gext.errors.Error.Stacktrace as stacktrace avoiding another one
 //     stacktrace generation procedure.
 //
-// 4. Finally write a message and call then death.Die() if it's fatal level.
+//  4. Finally write a message and, if it's fatal level, flush writers
+//     and call DeathHandler (see SetDeathHandler()).
+//
+// log() MUST be called directly by Logger's finisher, otherwise the caller
+// and the stacktrace of Entry would start from the internal function.
+// Read more: logSkip().
 func (l *Logger) log(
 
 	lvl Level,
@@ -141,6 +313,23 @@ func (l *Logger) log(
 	args []any,
 	fields []ekaletter.LetterField,
 
+) *Logger {
+
+	return l.logSkip(1, lvl, format, err, args, fields)
+}
+
+// logSkip is log() itself, 'skip' is the number of functions between
+// Logger's finisher and logSkip() (1 for log() and logEvery(), logTemplate(), etc.
+// that are called by the finishers directly). Read more: log().
+func (l *Logger) logSkip(
+
+	skip int,
+	lvl Level,
+	format string,
+	err *ekaerr.Error,
+	args []any,
+	fields []ekaletter.LetterField,
+
 ) *Logger {
 
 	l.assert()
@@ -153,6 +342,17 @@ func (l *Logger) log(
 		return l
 	}
 
+	// Entry must be finished by the same Integrator it's started with,
+	// even if Integrator is replaced meanwhile. The Entry objects that are
+	// skipped by the Level are rejected above w/o touching the shared counter.
+	st := l.integrator.acquire()
+
+	integrator := st.integrator
+	if !lvl.IsEnabledFor(integrator.MinLevelEnabled()) && !l.trigger.active() {
+		st.release()
+		return l
+	}
+
 	// At this code point Entry should be copied anyway even if it's empty message,
 	// even w/o body, even w/o fields, because of at least timestamp.
 	//
@@ -162,13 +362,21 @@ func (l *Logger) log(
 	workTempEntry := l.entry.clone()
 
 	workTempEntry.Level = lvl
-	workTempEntry.Time = time.Now()
+	workTempEntry.Time = l.now(integrator)
 
 	var (
-		onlyFields = false
-		errLetter  = ekaletter.BridgeErrorGetLetter(unsafe.Pointer(err))
+		onlyFields   = false
+		sharedFields = false // whether Entry's fields are the caller's 'fields'
+		errLetter    = ekaletter.BridgeErrorGetLetter(unsafe.Pointer(err))
 	)
 
+	// Error with lazy stacktrace (if any) is being logged.
+	// It's time to resolve its stacktrace. Its fingerprint is computed
+	// by the encoders on demand (read more: ekaletter.LFingerprint()).
+	if errLetter != nil {
+		ekaletter.LResolveStackTrace(errLetter)
+	}
+
 	// Try to use ekaerr.Error's last message or first arg from args
 	// if format is not presented.
 
@@ -211,28 +419,148 @@ func (l *Logger) log(
 	ekaletter.LSetMessage(workTempEntry.LogLetter, format, false)
 	workTempEntry.ErrLetter = errLetter
 
-	if lvl <= l.integrator.MinLevelForStackTrace() {
-		workTempEntry.addStacktraceIfNotPresented()
+	switch {
+	case lvl.IsEnabledFor(integrator.MinLevelForStackTrace()):
+		workTempEntry.addStacktraceIfNotPresented(skip)
+	case lvl.IsEnabledFor(minLevelForCaller(integrator)):
+		workTempEntry.addCallerIfNotPresented(skip)
 	}
 
 	// Try to extract message from 'args' if 'errLetter' == nil ('onlyFields' == false),
 	// but if 'errLetter' is set, it's OK to log w/o message.
 	switch {
 	case len(args) > 0:
+		n := len(workTempEntry.LogLetter.Fields)
 		ekaletter.LParseTo(workTempEntry.LogLetter, args, onlyFields)
+		workTempEntry.applyGroup(n)
+		ekaletter.LApplyUnnamedKeys(workTempEntry.LogLetter, n)
+		ekaletter.LDedupFields(workTempEntry.LogLetter, n)
+	case len(fields) > 0 && (workTempEntry.group != "" ||
+		ekaletter.LIsFieldsOverwrite(workTempEntry.LogLetter) ||
+		ekaletter.LIsUnnamedKeys(workTempEntry.LogLetter)):
+		// Caller's 'fields' must not be modified, so they're copied.
+		workTempEntry.LogLetter.Fields = workTempEntry.LogLetter.Fields[:0]
+		for i, n := 0, len(fields); i < n; i++ {
+			f := fields[i]
+			f.Key = workTempEntry.groupKey(f.Key)
+			workTempEntry.LogLetter.Fields = append(workTempEntry.LogLetter.Fields, f)
+		}
+		ekaletter.LApplyUnnamedKeys(workTempEntry.LogLetter, 0)
+		ekaletter.LDedupFields(workTempEntry.LogLetter, 0)
 	case len(fields) > 0:
 		workTempEntry.LogLetter.Fields = fields
+		sharedFields = true
 	}
 
-	l.integrator.EncodeAndWrite(workTempEntry)
+	sharedFields = workTempEntry.addScopeFields(sharedFields)
+	workTempEntry.addCorrelationID(l.correlationID, err, sharedFields)
 
-	ekaerr.ReleaseError(err)
-	releaseEntry(workTempEntry)
+	if l.trigger != nil {
+		// Trigger scope takes the ownership of Entry and ekaerr.Error.
+		l.trigger.handle(integrator, workTempEntry, err, sharedFields)
+	} else {
+		integrator.EncodeAndWrite(workTempEntry)
+		ekaerr.ReleaseError(err)
+		releaseEntry(workTempEntry)
+	}
 
-	switch workTempEntry.Level {
+	switch lvl {
 	case LEVEL_EMERGENCY:
-		ekadeath.Die()
+		flushAndDie(integrator)
 	}
 
+	st.release()
 	return l
 }
+
+// logEvery is the same as log() but it writes log message only if there was
+// no written log message from the same callsite during 'd'.
+// Callsite is determined by the PC of caller of Logger's finisher
+// that calls logEvery().
+func (l *Logger) logEvery(
+
+	lvl Level,
+	d time.Duration,
+	msg string,
+	fields []ekaletter.LetterField,
+
+) *Logger {
+
+	l.assert()
+	if l == nopLogger || !l.levelEnabled(lvl) {
+		return l
+	}
+
+	if d <= 0 {
+		return l.logSkip(1, lvl, msg, nil, nil, fields)
+	}
+
+	// 0 - runtime.Callers, 1 - logEvery, 2 - finisher, 3 - caller of finisher.
+	var pcs [1]uintptr
+	if runtime.Callers(3, pcs[:]) == 0 {
+		return l.logSkip(1, lvl, msg, nil, nil, fields)
+	}
+
+	stateI, _ := rateLimitStates.LoadOrStore(pcs[0], new(rateLimitState))
+	state := stateI.(*rateLimitState)
+
+	now := l.now(l.integrator.current())
+
+	state.mu.Lock()
+	if !state.lastTime.IsZero() && now.Sub(state.lastTime) < d {
+		state.suppressed++
+		state.mu.Unlock()
+		return l
+	}
+	suppressed := state.suppressed
+	state.lastTime = now
+	state.suppressed = 0
+	state.mu.Unlock()
+
+	if suppressed > 0 {
+		// Do not modify the caller's slice.
+		fields = append(fields[:len(fields):len(fields)],
+			ekaletter.FUint64(rateLimitSuppressedFieldKey, suppressed))
+	}
+
+	return l.logSkip(1, lvl, msg, nil, nil, fields)
+}
+
+// logTemplate is the same as log() but builds log message from 'template'
+// with named placeholders substituting 'fields' values (fields are attached too).
+// The parsed template is cached for each callsite, that is determined
+// by the PC of caller of Logger's finisher that calls logTemplate().
+func (l *Logger) logTemplate(
+
+	lvl Level,
+	template string,
+	fields []ekaletter.LetterField,
+
+) *Logger {
+
+	l.assert()
+	if l == nopLogger || !l.levelEnabled(lvl) {
+		return l
+	}
+
+	if strings.IndexByte(template, '{') == -1 && strings.IndexByte(template, '}') == -1 {
+		return l.logSkip(1, lvl, template, nil, nil, fields)
+	}
+
+	var mt *messageTemplate
+
+	// 0 - runtime.Callers, 1 - logTemplate, 2 - finisher, 3 - caller of finisher.
+	var pcs [1]uintptr
+	if runtime.Callers(3, pcs[:]) > 0 {
+		if mtI, ok := messageTemplates.Load(pcs[0]); ok && mtI.(*messageTemplate).template == template {
+			mt = mtI.(*messageTemplate)
+		} else {
+			mt = parseMessageTemplate(template)
+			messageTemplates.Store(pcs[0], mt)
+		}
+	} else {
+		mt = parseMessageTemplate(template)
+	}
+
+	return l.logSkip(1, lvl, mt.render(fields), nil, nil, fields)
+}
@@ -0,0 +1,180 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/qioalice/ekago/v3/ekasys"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// scopeStack is a stack of the field scopes of one goroutine.
+	// It's accessed only by the goroutine it belongs to.
+	scopeStack struct {
+		fields []ekaletter.LetterField
+		marks  []int // len(fields) before each pushed scope
+	}
+)
+
+var (
+	// scopeStacks is a map of goroutines' IDs to their *scopeStack.
+	// Read more: PushScopeFields().
+	scopeStacks sync.Map
+
+	// scopeStacksActive is a number of goroutines with non-empty *scopeStack.
+	// It allows to avoid getting goroutine's ID when no scope is used
+	// by any goroutine (it's process-wide).
+	scopeStacksActive int64 // atomic access only
+)
+
+// PushScopeFields pushes a new field scope for the current goroutine.
+// Provided fields are attached to all log entries, that are written
+// by any Logger from the current goroutine until the scope is popped
+// by PopScope(). So, the deep call stacks may add the fields once
+// w/o threading Logger through every function.
+//
+// Scopes may be nested. The fields of the log entry itself take precedence
+// over the scope's ones with the same keys, the inner scope's fields
+// take precedence over the outer scope's ones.
+//
+// Each PushScopeFields() call MUST be paired with PopScope() call
+// from the same goroutine. Prefer WithScope() that does it automatically.
+//
+// WARNING!
+// The scopes are goroutine-local. The goroutines started from the scope
+// DO NOT inherit its fields.
+//
+// WARNING!
+// The scopes have a process-wide cost. While at least one goroutine
+// has a scope, EACH log entry being written by EACH goroutine
+// (even by those w/o scopes) costs the lookup of the current goroutine's ID
+// (it's parsed from runtime.Stack(), about 1µs) and of its scope.
+// So, keep the scopes short-lived and prefer the Logger's own fields
+// (Logger.WithString(), Logger.WithInt(), etc.) at the hot paths.
+func PushScopeFields(fields ...ekaletter.LetterField) {
+
+	id := ekasys.GoroutineID()
+	ssI, _ := scopeStacks.Load(id)
+	ss, _ := ssI.(*scopeStack)
+
+	if ss == nil {
+		ss = new(scopeStack)
+		scopeStacks.Store(id, ss)
+		atomic.AddInt64(&scopeStacksActive, 1)
+	}
+
+	ss.marks = append(ss.marks, len(ss.fields))
+	for i, n := 0, len(fields); i < n; i++ {
+		if !fields[i].IsInvalid() {
+			ss.fields = append(ss.fields, fields[i])
+		}
+	}
+}
+
+// PopScope pops the last field scope of the current goroutine,
+// pushed by PushScopeFields(). Does nothing if there is no scope.
+func PopScope() {
+
+	if atomic.LoadInt64(&scopeStacksActive) == 0 {
+		return
+	}
+
+	id := ekasys.GoroutineID()
+	ssI, ok := scopeStacks.Load(id)
+	if !ok {
+		return
+	}
+	ss := ssI.(*scopeStack)
+
+	n := len(ss.marks) - 1
+	mark := ss.marks[n]
+	for i, l := mark, len(ss.fields); i < l; i++ {
+		ss.fields[i] = ekaletter.LetterField{}
+	}
+	ss.fields = ss.fields[:mark]
+	ss.marks = ss.marks[:n]
+
+	if n == 0 {
+		scopeStacks.Delete(id)
+		atomic.AddInt64(&scopeStacksActive, -1)
+	}
+}
+
+// WithScope pushes a new field scope with provided fields for the current
+// goroutine (see PushScopeFields()), calls 'fn' and pops the scope then,
+// even if 'fn' panics. Does nothing if 'fn' is nil.
+//
+// WARNING!
+// While 'fn' is running, all log entries of all goroutines cost more.
+// Read more: PushScopeFields().
+func WithScope(fn func(), fields ...ekaletter.LetterField) {
+	if fn == nil {
+		return
+	}
+	PushScopeFields(fields...)
+	defer PopScope()
+	fn()
+}
+
+// addScopeFields appends the fields of current goroutine's scopes
+// (if any) to the Entry, skipping those, whose keys are presented already.
+//
+// If 'sharedFields' is true, the Entry's fields slice is the caller's one,
+// so it's not modified but reallocated. Returns whether the Entry's fields slice
+// is still the caller's one.
+func (e *Entry) addScopeFields(sharedFields bool) bool {
+
+	if atomic.LoadInt64(&scopeStacksActive) == 0 {
+		return sharedFields
+	}
+
+	ssI, ok := scopeStacks.Load(ekasys.GoroutineID())
+	if !ok {
+		return sharedFields
+	}
+	scopeFields := ssI.(*scopeStack).fields
+
+	n := len(e.LogLetter.Fields)
+	for i := len(scopeFields) - 1; i >= 0; i-- {
+		if e.hasFieldWithKey(scopeFields[i].Key) {
+			continue
+		}
+		if sharedFields {
+			fs := e.LogLetter.Fields
+			e.LogLetter.Fields = append(fs[:len(fs):len(fs)], scopeFields[i])
+			sharedFields = false
+		} else {
+			e.LogLetter.Fields = append(e.LogLetter.Fields, scopeFields[i])
+		}
+	}
+
+	// The fields are appended in reversed order (from inner scope to outer one),
+	// so restore it.
+	fs := e.LogLetter.Fields
+	for i, j := n, len(fs)-1; i < j; i, j = i+1, j-1 {
+		fs[i], fs[j] = fs[j], fs[i]
+	}
+
+	return sharedFields
+}
+
+// hasFieldWithKey reports whether Entry's ekaletter.Letter has a field
+// with provided non-empty 'key'.
+func (e *Entry) hasFieldWithKey(key string) bool {
+	if key == "" {
+		return false
+	}
+	fs := e.LogLetter.Fields
+	for i, n := 0, len(fs); i < n; i++ {
+		if fs[i].Key == key {
+			return true
+		}
+	}
+	return false
+}
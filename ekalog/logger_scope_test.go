@@ -0,0 +1,65 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithScope(t *testing.T) {
+
+	je := new(ekalog.CI_JSONEncoder)
+
+	out := testJSONEncoderOutput(je, func() {
+		ekalog.WithScope(func() {
+			ekalog.WithScope(func() {
+				ekalog.Infow("scoped", ekaunsafe.FString("user", "explicit"))
+			}, ekaunsafe.FString("user", "inner"), ekaunsafe.FInt("attempt", 2))
+		}, ekaunsafe.FString("user", "outer"), ekaunsafe.FString("tenant", "acme"))
+	})
+	assert.Equal(t, map[string]any{
+		"user":    "explicit",
+		"tenant":  "acme",
+		"attempt": float64(2),
+	}, out["fields"])
+
+	out = testJSONEncoderOutput(je, func() {
+		ekalog.PushScopeFields(ekaunsafe.FString("user", "outer"))
+		ekalog.PushScopeFields(ekaunsafe.FString("user", "inner"))
+		ekalog.Info("inner")
+		ekalog.PopScope()
+	})
+	assert.Equal(t, map[string]any{"user": "inner"}, out["fields"])
+
+	out = testJSONEncoderOutput(je, func() {
+		ekalog.Info("outer")
+		ekalog.PopScope()
+	})
+	assert.Equal(t, map[string]any{"user": "outer"}, out["fields"])
+
+	out = testJSONEncoderOutput(je, func() {
+		ekalog.PopScope()
+		ekalog.Info("no scope")
+	})
+	assert.NotContains(t, out, "fields")
+
+	out = testJSONEncoderOutput(je, func() {
+		ekalog.WithScope(func() {
+			done := make(chan struct{})
+			go func() {
+				defer close(done)
+				ekalog.Info("other goroutine")
+			}()
+			<-done
+		}, ekaunsafe.FString("user", "outer"))
+	})
+	assert.NotContains(t, out, "fields")
+}
@@ -0,0 +1,101 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"time"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Stopwatch is a timer, that logs elapsed time since its start
+	// as a KIND_TYPE_DURATION field, when it's stopped.
+	// Stopwatch MUST be created by Logger.Timing() or Timing().
+	//
+	// The level of log message depends on elapsed time and thresholds,
+	// that may be set by WithThreshold(). Use it to get rid of the hand-rolled
+	// timing code around call sites:
+	//
+	//	defer ekalog.Timing("db query").
+	//	    WithThreshold(500*time.Millisecond, ekalog.LEVEL_WARNING).
+	//	    Stop()
+	//
+	// Stopwatch is not thread-safe.
+	Stopwatch struct {
+		l          *Logger
+		msg        string
+		start      time.Time
+		level      Level
+		thresholds []stopwatchThreshold
+	}
+
+	// stopwatchThreshold is a Stopwatch's level, that is used
+	// if elapsed time is greater than 'd'.
+	stopwatchThreshold struct {
+		d     time.Duration
+		level Level
+	}
+)
+
+//noinspection GoSnakeCaseUsage
+const (
+	// STOPWATCH_FIELD_KEY is a key of the field Stopwatch writes elapsed time as.
+	STOPWATCH_FIELD_KEY = "elapsed"
+)
+
+// Timing starts and returns a new Stopwatch, that writes a log message 'msg'
+// with elapsed time using the current Logger, when it's stopped.
+// By default, log message is written with LEVEL_DEBUG.
+// Read more: Stopwatch.
+func (l *Logger) Timing(msg string) *Stopwatch {
+	return &Stopwatch{l: l, msg: msg, start: time.Now(), level: LEVEL_DEBUG}
+}
+
+// Timing is the same as Logger.Timing() but uses the package-level Logger.
+// Read more: Stopwatch.
+func Timing(msg string) *Stopwatch {
+	return baseLogger.Timing(msg)
+}
+
+// WithLevel sets the level of log message, that is used
+// if no threshold is exceeded. Returns the current Stopwatch.
+func (sw *Stopwatch) WithLevel(level Level) *Stopwatch {
+	sw.level = level
+	return sw
+}
+
+// WithThreshold adds a threshold: if elapsed time is greater than 'd',
+// log message is written with 'level'. If more than one threshold is exceeded,
+// the level of the greatest one is used. Returns the current Stopwatch.
+func (sw *Stopwatch) WithThreshold(d time.Duration, level Level) *Stopwatch {
+	sw.thresholds = append(sw.thresholds, stopwatchThreshold{d: d, level: level})
+	return sw
+}
+
+// Elapsed returns the time elapsed since Stopwatch is started.
+func (sw *Stopwatch) Elapsed() time.Duration {
+	return time.Since(sw.start)
+}
+
+// Stop writes a log message with elapsed time (as STOPWATCH_FIELD_KEY field)
+// and provided 'fields'. Returns elapsed time.
+func (sw *Stopwatch) Stop(fields ...ekaletter.LetterField) time.Duration {
+
+	elapsed := sw.Elapsed()
+
+	level, greatest := sw.level, time.Duration(-1)
+	for _, threshold := range sw.thresholds {
+		if elapsed > threshold.d && threshold.d > greatest {
+			level, greatest = threshold.level, threshold.d
+		}
+	}
+
+	fields = append(fields, ekaletter.FDuration(STOPWATCH_FIELD_KEY, elapsed))
+	sw.l.log(level, sw.msg, nil, nil, fields)
+
+	return elapsed
+}
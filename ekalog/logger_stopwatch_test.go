@@ -0,0 +1,37 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStopwatch(t *testing.T) {
+
+	out := testConsoleEncoderOutput("{{l}} {{m/?$ }}{{f/v=/?$;}}", func() {
+		ekalog.Timing("fast").
+			WithLevel(ekalog.LEVEL_INFO).
+			WithThreshold(time.Hour, ekalog.LEVEL_ERROR).
+			Stop()
+	})
+	assert.True(t, strings.HasPrefix(out, "Info fast elapsed="), out)
+
+	out = testConsoleEncoderOutput("{{l}} {{m/?$ }}", func() {
+		sw := ekalog.Timing("slow").
+			WithThreshold(time.Millisecond, ekalog.LEVEL_NOTICE).
+			WithThreshold(5*time.Millisecond, ekalog.LEVEL_WARNING).
+			WithThreshold(time.Hour, ekalog.LEVEL_ERROR)
+		time.Sleep(10 * time.Millisecond)
+		assert.GreaterOrEqual(t, int64(sw.Stop()), int64(10*time.Millisecond))
+	})
+	assert.Equal(t, "Warning slow ", out)
+}
@@ -13,7 +13,6 @@ import (
 	"testing"
 	"time"
 
-	"github.com/qioalice/ekago/v3/ekadeath"
 	"github.com/qioalice/ekago/v3/ekaerr"
 	"github.com/qioalice/ekago/v3/ekalog"
 )
@@ -41,12 +40,15 @@ func TestLog(t *testing.T) {
 		WithMinLevel(ekalog.LEVEL_DEBUG).
 		WriteTo(b)
 
-	ekadeath.Reg(func() {
+	// LEVEL_EMERGENCY entry below must not shut down the tests,
+	// that are run after this one.
+	ekalog.SetDeathHandler(func() {
 		str := b.String()
 		//str = strings.ReplaceAll(str, "\033", "\\033")
 		_ = strings.ReplaceAll
 		fmt.Println(str)
 	})
+	defer ekalog.SetDeathHandler(nil)
 
 	ekalog.ReplaceIntegrator(stdoutConsoleIntegrator)
 
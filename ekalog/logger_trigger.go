@@ -0,0 +1,213 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// triggerScope is a ring buffer of Entry, that are held until
+	// the Entry of TRIGGER_SCOPE_LEVEL (or more severe) is logged.
+	// Read more: Logger.BeginTriggerScope().
+	triggerScope struct {
+		mu   sync.Mutex
+		ring []triggerScopeEntry
+		next int // index of ring's element the next Entry will be held at
+		size int // number of held Entry
+
+		// triggerLevel is the Level of Entry that has triggered the flush
+		// or LEVEL_DEBUG + 1 if it has not been triggered yet.
+		triggerLevel Level
+
+		ended uint32 // atomic access only, 1 if the scope is ended
+	}
+
+	// triggerScopeEntry is an Entry held by triggerScope
+	// with ekaerr.Error that is attached to it (if any).
+	triggerScopeEntry struct {
+		entry *Entry
+		err   *ekaerr.Error
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// TRIGGER_SCOPE_LEVEL is the least severe Level, the Entry of which
+	// triggers the flush of trigger scope. Read more: Logger.BeginTriggerScope().
+	TRIGGER_SCOPE_LEVEL = LEVEL_WARNING
+
+	// TRIGGER_SCOPE_DEFAULT_CAPACITY is a default number of Entry
+	// trigger scope holds if a non-positive capacity is passed.
+	TRIGGER_SCOPE_DEFAULT_CAPACITY = 128
+)
+
+// BeginTriggerScope returns a copy of the current Logger, that holds
+// its LEVEL_DEBUG, LEVEL_INFO, LEVEL_NOTICE Entry in the ring buffer
+// of 'capacity' (the oldest Entry are discarded if it's full)
+// instead of writing them.
+//
+// When the Entry of TRIGGER_SCOPE_LEVEL or more severe is logged,
+// all held Entry are flushed (in the order they were logged)
+// before the triggering Entry, and all next Entry are written w/o holding.
+// If it never happens, the held Entry are discarded by EndTriggerScope().
+// So, you get the verbose context only for the failed operations:
+//
+//	log := ekalog.BeginTriggerScope(0)
+//	defer log.EndTriggerScope()
+//	log.Debug("Step 1")    // held
+//	log.Error("Failed")    // "Step 1" and "Failed" are written
+//
+// The Entry are held even if their Level is not enabled by Integrator.
+// They're written then the same way the triggering Entry is, if Integrator
+// is TriggeredIntegrator (e.g. CommonIntegrator), or dropped if their Level
+// is not enabled otherwise.
+//
+// All Loggers derived from the returned one (using Copy()) share the same scope.
+// Nested trigger scopes are independent of each other.
+// A non-positive 'capacity' means TRIGGER_SCOPE_DEFAULT_CAPACITY.
+func (l *Logger) BeginTriggerScope(capacity int) *Logger {
+	l.assert()
+	if l == nopLogger {
+		return l
+	}
+	if capacity <= 0 {
+		capacity = TRIGGER_SCOPE_DEFAULT_CAPACITY
+	}
+	newLogger := l.derive()
+	newLogger.trigger = &triggerScope{
+		ring:         make([]triggerScopeEntry, capacity),
+		triggerLevel: LEVEL_DEBUG + 1,
+	}
+	return newLogger
+}
+
+// BeginTriggerScope returns a copy of the package-level Logger
+// with a new trigger scope. See Logger.BeginTriggerScope() for more details.
+func BeginTriggerScope(capacity int) *Logger {
+	return baseLogger.BeginTriggerScope(capacity)
+}
+
+// EndTriggerScope ends the trigger scope of the current Logger (if any),
+// discarding all held Entry if the scope has not been triggered.
+// The Logger (and all Loggers sharing the scope) works as a usual Logger then.
+// Next calls do nothing.
+func (l *Logger) EndTriggerScope() {
+	l.assert()
+	if l != nopLogger {
+		l.trigger.end()
+	}
+}
+
+// active reports whether the scope is not nil and has not been ended yet,
+// so the Entry of all levels must be passed to it. Nil safe.
+func (ts *triggerScope) active() bool {
+	return ts != nil && atomic.LoadUint32(&ts.ended) == 0
+}
+
+// handle holds, writes or drops 'entry' with its 'err',
+// taking the ownership of them. 'sharedFields' must be true
+// if Entry's fields are the caller's ones.
+func (ts *triggerScope) handle(integrator Integrator, entry *Entry, err *ekaerr.Error, sharedFields bool) {
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	switch {
+	case atomic.LoadUint32(&ts.ended) != 0:
+		if entry.Level.IsEnabledFor(integrator.MinLevelEnabled()) {
+			integrator.EncodeAndWrite(entry)
+		}
+		ts.release(triggerScopeEntry{entry, err})
+
+	case ts.triggerLevel.IsEnabledFor(TRIGGER_SCOPE_LEVEL):
+		ts.write(integrator, triggerScopeEntry{entry, err})
+
+	case entry.Level.IsEnabledFor(TRIGGER_SCOPE_LEVEL):
+		ts.triggerLevel = entry.Level
+		ts.flush(integrator)
+		ts.write(integrator, triggerScopeEntry{entry, err})
+
+	default:
+		if sharedFields {
+			entry.LogLetter.Fields =
+				append([]ekaletter.LetterField(nil), entry.LogLetter.Fields...)
+		}
+		ts.hold(triggerScopeEntry{entry, err})
+	}
+}
+
+// hold puts 'te' to the ring buffer, releasing the oldest one if it's full.
+// Expects triggerScope is locked.
+func (ts *triggerScope) hold(te triggerScopeEntry) {
+	if ts.size == len(ts.ring) {
+		ts.release(ts.ring[ts.next])
+	} else {
+		ts.size++
+	}
+	ts.ring[ts.next] = te
+	if ts.next++; ts.next == len(ts.ring) {
+		ts.next = 0
+	}
+}
+
+// flush writes all held Entry from the oldest to the newest and releases them.
+// Expects triggerScope is locked.
+func (ts *triggerScope) flush(integrator Integrator) {
+	for i := len(ts.ring) - ts.size; i < len(ts.ring); i++ {
+		idx := (ts.next + i) % len(ts.ring)
+		ts.write(integrator, ts.ring[idx])
+		ts.ring[idx] = triggerScopeEntry{}
+	}
+	ts.size = 0
+}
+
+// write writes Entry of 'te' by 'integrator' (if it's possible) and releases 'te'.
+func (ts *triggerScope) write(integrator Integrator, te triggerScopeEntry) {
+
+	switch {
+	case te.entry.Level.IsEnabledFor(integrator.MinLevelEnabled()):
+		integrator.EncodeAndWrite(te.entry)
+	default:
+		if triggered, ok := integrator.(TriggeredIntegrator); ok {
+			triggered.EncodeAndWriteTriggered(te.entry, ts.triggerLevel)
+		}
+	}
+
+	ts.release(te)
+}
+
+// release returns Entry and ekaerr.Error of 'te' to their pools.
+func (ts *triggerScope) release(te triggerScopeEntry) {
+	ekaerr.ReleaseError(te.err)
+	releaseEntry(te.entry)
+}
+
+// end marks the scope as ended and discards all held Entry. Nil safe.
+func (ts *triggerScope) end() {
+
+	if ts == nil {
+		return
+	}
+
+	ts.mu.Lock()
+	defer ts.mu.Unlock()
+
+	if !atomic.CompareAndSwapUint32(&ts.ended, 0, 1) {
+		return
+	}
+
+	for i := len(ts.ring) - ts.size; i < len(ts.ring); i++ {
+		idx := (ts.next + i) % len(ts.ring)
+		ts.release(ts.ring[idx])
+		ts.ring[idx] = triggerScopeEntry{}
+	}
+	ts.size = 0
+}
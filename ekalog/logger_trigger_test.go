@@ -0,0 +1,69 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testTriggerScopeOutput(cb func()) string {
+
+	b := bytes.NewBuffer(nil)
+	ci := new(ekalog.CommonIntegrator).
+		WithEncoder(new(ekalog.CI_ConsoleEncoder).SetFormat("{{m/?$ }}{{f/v=/e }}|")).
+		WithMinLevel(ekalog.LEVEL_WARNING).
+		WriteTo(b)
+
+	ekalog.ReplaceIntegrator(ci)
+	cb()
+
+	return b.String()
+}
+
+func TestLogger_BeginTriggerScope(t *testing.T) {
+
+	out := testTriggerScopeOutput(func() {
+		log := ekalog.BeginTriggerScope(0)
+		log.Debug("debug")
+		log.Info("info")
+		log.EndTriggerScope()
+		log.Debug("debug after end")
+		log.Warn("warn after end")
+	})
+	assert.Equal(t, "warn after end |", out)
+
+	out = testTriggerScopeOutput(func() {
+		log := ekalog.BeginTriggerScope(0)
+		defer log.EndTriggerScope()
+
+		fields := []ekaunsafe.LetterField{ekaunsafe.FInt("n", 1)}
+		log.Debugw("step", fields...)
+		fields[0] = ekaunsafe.FInt("n", 2)
+
+		log.Copy().WithString("k", "v").Info("derived")
+		ekalog.Debug("not scoped")
+		log.Error("failed")
+		log.Debug("after trigger")
+	})
+	assert.Equal(t, `step n=1|derived k="v"|failed |after trigger |`, out)
+
+	out = testTriggerScopeOutput(func() {
+		log := ekalog.BeginTriggerScope(2)
+		defer log.EndTriggerScope()
+
+		log.Debug("1")
+		log.Debug("2")
+		log.Debug("3")
+		log.Warn("w")
+	})
+	assert.Equal(t, "2 |3 |w |", out)
+}
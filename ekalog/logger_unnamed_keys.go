@@ -0,0 +1,71 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// UnnamedKeysPolicy is a policy of the unnamed fields' keys generation.
+	// It's the same type as ekaerr.UnnamedKeysPolicy.
+	// Read more: Logger.WithUnnamedKeys().
+	UnnamedKeysPolicy = ekaerr.UnnamedKeysPolicy
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// UNNAMED_KEYS_POLICY_DEFAULT read more: ekaerr.UNNAMED_KEYS_POLICY_DEFAULT.
+	UNNAMED_KEYS_POLICY_DEFAULT = ekaerr.UNNAMED_KEYS_POLICY_DEFAULT
+
+	// UNNAMED_KEYS_POLICY_PADDED read more: ekaerr.UNNAMED_KEYS_POLICY_PADDED.
+	UNNAMED_KEYS_POLICY_PADDED = ekaerr.UNNAMED_KEYS_POLICY_PADDED
+
+	// UNNAMED_KEYS_POLICY_PLAIN read more: ekaerr.UNNAMED_KEYS_POLICY_PLAIN.
+	UNNAMED_KEYS_POLICY_PLAIN = ekaerr.UNNAMED_KEYS_POLICY_PLAIN
+
+	// UNNAMED_KEYS_POLICY_REJECT read more: ekaerr.UNNAMED_KEYS_POLICY_REJECT.
+	UNNAMED_KEYS_POLICY_REJECT = ekaerr.UNNAMED_KEYS_POLICY_REJECT
+)
+
+// WithUnnamedKeys sets the policy of the unnamed fields' keys generation
+// of the current Logger and the prefix of generated keys
+// (ekaerr.UNNAMED_KEYS_DEFAULT_PREFIX if it's empty).
+//
+// By default, the unnamed fields are kept unnamed and their keys are generated
+// by encoders for each Entry separately ("unnamed_01", ...), thus the different
+// values collide by the same keys across the log entries.
+// With UNNAMED_KEYS_POLICY_PADDED or UNNAMED_KEYS_POLICY_PLAIN the keys are
+// generated when the fields are added, continuing the numbering of the Logger's
+// own unnamed fields, so the keys of Logger's fields are stable.
+// With UNNAMED_KEYS_POLICY_REJECT the unnamed fields are dropped.
+//
+// Only the fields that are added after this call are affected.
+// The fields of attached ekaerr.Error are not affected,
+// use ekaerr.Error.WithUnnamedKeys() for them.
+//
+// WithUnnamedKeys DO NOT makes a copy of current Logger, like any other With method.
+func (l *Logger) WithUnnamedKeys(policy UnnamedKeysPolicy, prefix string) *Logger {
+	return l.setUnnamedKeys(policy, prefix)
+}
+
+// WithUnnamedKeys sets the policy of the unnamed fields' keys generation
+// of the package-level Logger. See Logger.WithUnnamedKeys() for more details.
+func WithUnnamedKeys(policy UnnamedKeysPolicy, prefix string) *Logger {
+	return baseLogger.setUnnamedKeys(policy, prefix)
+}
+
+// setUnnamedKeys checks whether Logger is valid, not nop Logger and
+// sets the policy of the unnamed fields' keys generation of its Entry.
+func (l *Logger) setUnnamedKeys(policy UnnamedKeysPolicy, prefix string) *Logger {
+	l.assert()
+	if l == nopLogger {
+		return l
+	}
+	ekaletter.LSetUnnamedKeys(l.entry.LogLetter, policy, prefix)
+	return l
+}
@@ -0,0 +1,60 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_WithUnnamedKeys(t *testing.T) {
+
+	out := testConsoleEncoderOutput("{{m}}{{f/?^ /v=/e,}}", func() {
+		ekalog.Info("msg", 1, "a", 2, true)
+	})
+	assert.Equal(t, `msg unnamed_01=1,a=2,unnamed_02=true`, out)
+
+	log := ekalog.Copy().WithUnnamedKeys(ekalog.UNNAMED_KEYS_POLICY_PADDED, "arg").
+		WithManyAny(10)
+
+	out = testConsoleEncoderOutput("{{m}}{{f/?^ /v=/e,}}", func() {
+		log.Info("msg", 1, "a", 2, true)
+	})
+	assert.Equal(t, `msg arg01=10,arg02=1,a=2,arg03=true`, out)
+
+	out = testConsoleEncoderOutput("{{m}}{{f/?^ /v=/e,}}", func() {
+		log.Infow("msg", ekaunsafe.FInt("", 5))
+	})
+	assert.Equal(t, `msg arg02=5`, out, "numbering must be continued")
+
+	out = testConsoleEncoderOutput("{{m}}{{f/?^ /v=/e,}}", func() {
+		ekalog.Copy().WithUnnamedKeys(ekalog.UNNAMED_KEYS_POLICY_PLAIN, "").
+			Info("msg", 1, "a", 2)
+	})
+	assert.Equal(t, `msg unnamed_1=1,a=2`, out)
+
+	out = testConsoleEncoderOutput("{{m}}{{f/?^ /v=/e,}}", func() {
+		ekalog.Copy().WithUnnamedKeys(ekalog.UNNAMED_KEYS_POLICY_REJECT, "").
+			WithManyAny(1).
+			Info("msg", 2, "a", 3, true)
+	})
+	assert.Equal(t, `msg unnamed_rejected=3,a=3`, out)
+
+	err := ekaerr.IllegalArgument.New("bad").
+		WithUnnamedKeys(ekaerr.UNNAMED_KEYS_POLICY_PLAIN, "e").
+		WithManyAny(1, "b", 2, 3)
+	v1, ok1 := err.FieldInt64("e1")
+	v2, ok2 := err.FieldInt64("e2")
+	assert.True(t, ok1 && ok2)
+	assert.EqualValues(t, 1, v1)
+	assert.EqualValues(t, 3, v2)
+	ekaerr.ReleaseError(err)
+}
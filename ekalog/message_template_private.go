@@ -0,0 +1,122 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"strings"
+	"sync"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// messageTemplate is a pre-parsed log message template with named placeholders
+	// like "user {user_id} failed {op}". Read more: Logger.Logt().
+	messageTemplate struct {
+		template string
+		parts    []messageTemplatePart
+	}
+
+	// messageTemplatePart is either a literal text or a placeholder
+	// (a key of field, the value of which must be substituted).
+	messageTemplatePart struct {
+		text          string // literal text or field's key
+		isPlaceholder bool
+	}
+)
+
+var (
+	// messageTemplates is a map of callsites (their PCs) to *messageTemplate
+	// for template Logger's finishers.
+	messageTemplates sync.Map
+)
+
+// parseMessageTemplate parses 'template' to the messageTemplate.
+//
+// "{key}" is a placeholder, "{{" and "}}" are escaped "{" and "}".
+// Not closed "{" and placeholder with empty key are treated as literal text.
+func parseMessageTemplate(template string) *messageTemplate {
+
+	var (
+		mt      = &messageTemplate{template: template}
+		literal strings.Builder
+	)
+
+	flushLiteral := func() {
+		if literal.Len() > 0 {
+			mt.parts = append(mt.parts, messageTemplatePart{text: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for s := template; s != ""; {
+		i := strings.IndexAny(s, "{}")
+		if i == -1 {
+			literal.WriteString(s)
+			break
+		}
+
+		literal.WriteString(s[:i])
+		s = s[i:]
+
+		switch {
+		case strings.HasPrefix(s, "{{"), strings.HasPrefix(s, "}}"):
+			literal.WriteByte(s[0])
+			s = s[2:]
+
+		case s[0] == '}':
+			literal.WriteByte('}')
+			s = s[1:]
+
+		default:
+			end := strings.IndexByte(s, '}')
+			if end <= 1 || strings.ContainsAny(s[1:end], "{ ") {
+				literal.WriteByte('{')
+				s = s[1:]
+				continue
+			}
+			flushLiteral()
+			mt.parts = append(mt.parts, messageTemplatePart{text: s[1:end], isPlaceholder: true})
+			s = s[end+1:]
+		}
+	}
+
+	flushLiteral()
+	return mt
+}
+
+// render returns a message built from messageTemplate, substituting values
+// of 'fields' with the same keys instead of placeholders.
+// Placeholders w/o corresponding fields are left as is.
+func (mt *messageTemplate) render(fields []ekaletter.LetterField) string {
+
+	to := make([]byte, 0, len(mt.template)+32)
+
+	for i := range mt.parts {
+		part := &mt.parts[i]
+		if !part.isPlaceholder {
+			to = append(to, part.text...)
+			continue
+		}
+
+		found := false
+		for j := range fields {
+			if fields[j].Key == part.text {
+				to = ekaletter.AppendFieldValue(to, fields[j])
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			to = append(to, '{')
+			to = append(to, part.text...)
+			to = append(to, '}')
+		}
+	}
+
+	return string(to)
+}
@@ -0,0 +1,357 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package sentry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// DSN is a parsed Sentry's Data Source Name in the
+	// "<scheme>://<public_key>[:<secret_key>]@<host>[:<port>]/[<path>/]<project_id>"
+	// format.
+	//
+	// Read more:
+	// https://develop.sentry.dev/sdk/overview/#parsing-the-dsn
+	DSN struct {
+		Scheme    string
+		PublicKey string
+		SecretKey string
+		Host      string
+		Path      string
+		ProjectID string
+	}
+
+	// Encoder is an ekalog.CI_Encoder, that encodes ekalog.Entry
+	// with attached ekaerr.Error as Sentry's event JSON:
+	//
+	//   - exception's type is ekaerr.Error's class name;
+	//   - exception's value is ekaerr.Error's top message (or log's message);
+	//   - exception's stacktrace frames are ekaerr.Error's ekasys.StackFrame
+	//     (with their fields as frame's vars);
+	//   - log's fields are event's extra (or tags, see SetTags());
+	//   - ekaerr.Error's ID is event's fingerprint.
+	//
+	// The log entries without attached ekaerr.Error or with the level
+	// less important than the minimum one (see SetMinLevel()) are encoded
+	// as an empty data, that is ignored by Writer.
+	//
+	// Use it with Writer as a part of ekalog.CommonIntegrator:
+	//
+	//	w, err := sentry.NewWriter(dsn)
+	//	ci := new(ekalog.CommonIntegrator).
+	//	    WithEncoder(new(ekalog.CI_ConsoleEncoder)).WriteTo(os.Stdout).
+	//	    WithEncoder(new(sentry.Encoder).SetEnvironment("prod")).WriteTo(w)
+	Encoder struct {
+		minLevel    ekalog.Level
+		minLevelSet bool
+
+		environment string
+		release     string
+		serverName  string
+
+		tags map[string]struct{}
+
+		preEncodedFields []ekaletter.LetterField
+	}
+
+	// Writer is an io.Writer, that sends Sentry's events (encoded by Encoder)
+	// to the Sentry's store endpoint using HTTP API.
+	//
+	// Events are sent asynchronously, one by one, by the background goroutine.
+	// If the queue of pending events is full, new events are dropped.
+	// Writer implements ekalog.CI_WriterFlusher and ekalog.CI_WriterCloser,
+	// so ekalog.CommonIntegrator.Flush(), ekalog.CommonIntegrator.Close()
+	// waits until all pending events are sent.
+	//
+	// Writer MUST be created by NewWriter().
+	Writer struct {
+		endpoint string
+		auth     string
+		client   *http.Client
+
+		mu       sync.RWMutex
+		isClosed bool
+		queue    chan []byte
+		pending  sync.WaitGroup
+		lastErr  error
+		lastErrM sync.Mutex
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// WRITER_DEFAULT_QUEUE_SIZE is a default size of Writer's pending events queue.
+	WRITER_DEFAULT_QUEUE_SIZE = 256
+
+	// WRITER_DEFAULT_TIMEOUT is a default timeout of Writer's HTTP requests.
+	WRITER_DEFAULT_TIMEOUT = 10 * time.Second
+)
+
+var (
+	ErrInvalidDSN = errors.New("ekalog/sentry: invalid DSN")
+)
+
+var (
+	// Make sure we won't break API.
+	_ ekalog.CI_Encoder       = (*Encoder)(nil)
+	_ ekalog.CI_WriterFlusher = (*Writer)(nil)
+	_ ekalog.CI_WriterCloser  = (*Writer)(nil)
+	_ ekalog.CI_WriterRemote  = (*Writer)(nil)
+)
+
+// ParseDSN parses Sentry's Data Source Name. Returns ErrInvalidDSN
+// if 'dsn' has invalid format or has no public key or project ID.
+func ParseDSN(dsn string) (*DSN, error) {
+
+	u, err := url.Parse(strings.TrimSpace(dsn))
+	if err != nil || u.User == nil || u.Host == "" ||
+		u.Scheme != "http" && u.Scheme != "https" {
+		return nil, ErrInvalidDSN
+	}
+
+	path := strings.TrimSuffix(u.Path, "/")
+	idx := strings.LastIndexByte(path, '/')
+
+	d := &DSN{
+		Scheme:    u.Scheme,
+		PublicKey: u.User.Username(),
+		Host:      u.Host,
+		Path:      path[:idx+1],
+		ProjectID: path[idx+1:],
+	}
+	d.SecretKey, _ = u.User.Password()
+
+	if d.PublicKey == "" || d.ProjectID == "" {
+		return nil, ErrInvalidDSN
+	}
+
+	return d, nil
+}
+
+// StoreURL returns the URL of Sentry's store endpoint of the project.
+func (d *DSN) StoreURL() string {
+	path := d.Path
+	if path == "" {
+		path = "/"
+	}
+	return d.Scheme + "://" + d.Host + path + "api/" + d.ProjectID + "/store/"
+}
+
+// SetMinLevel sets the minimum level of log entries, that are encoded
+// as Sentry's events. By default, it's ekalog.LEVEL_ERROR.
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (se *Encoder) SetMinLevel(level ekalog.Level) *Encoder {
+	se.minLevel, se.minLevelSet = level, true
+	return se
+}
+
+// SetEnvironment sets Sentry's event environment, like "production".
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (se *Encoder) SetEnvironment(environment string) *Encoder {
+	se.environment = environment
+	return se
+}
+
+// SetRelease sets Sentry's event release, like "my-app@1.0.0".
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (se *Encoder) SetRelease(release string) *Encoder {
+	se.release = release
+	return se
+}
+
+// SetServerName sets Sentry's event server name. By default, it's a hostname.
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (se *Encoder) SetServerName(serverName string) *Encoder {
+	se.serverName = serverName
+	return se
+}
+
+// SetTags marks the fields with provided 'keys' as Sentry's event tags.
+// Tags are indexed and searchable, unlike extra, all other fields are placed to.
+// Calling this method many times extends the set of tags' keys.
+//
+// This method MUST NOT be called after Encoder is registered
+// with CommonIntegrator using CommonIntegrator.WithEncoder() method.
+func (se *Encoder) SetTags(keys ...string) *Encoder {
+
+	if se.tags == nil {
+		se.tags = make(map[string]struct{}, len(keys))
+	}
+	for _, key := range keys {
+		se.tags[key] = struct{}{}
+	}
+	return se
+}
+
+// PreEncodeField saves ekaletter.LetterField, that then is added
+// to the each Sentry's event as extra or tag. Unnamed fields are ignored.
+//
+// PreEncodeField is for internal purposes only and MUST NOT be called directly.
+func (se *Encoder) PreEncodeField(f ekaletter.LetterField) {
+	if f.Key != "" && !f.IsInvalid() {
+		se.preEncodedFields = append(se.preEncodedFields, f)
+	}
+}
+
+// EncodeEntry encodes passed ekalog.Entry as Sentry's event JSON.
+// Returns nil if ekalog.Entry has no attached ekaerr.Error
+// or its level is less important than the minimum one.
+//
+// EncodeEntry is for internal purposes only and MUST NOT be called directly.
+// UB otherwise, may panic.
+func (se *Encoder) EncodeEntry(e *ekalog.Entry) []byte {
+
+	minLevel := ekalog.LEVEL_ERROR
+	if se.minLevelSet {
+		minLevel = se.minLevel
+	}
+
+	if e.ErrLetter == nil || !e.Level.IsEnabledFor(minLevel) {
+		return nil
+	}
+
+	encoded, err := json.Marshal(se.event(e))
+	if err != nil {
+		return nil
+	}
+
+	return encoded
+}
+
+// NewWriter creates a new Writer for the Sentry's project 'dsn' points to,
+// starting its background goroutine.
+// Returns ErrInvalidDSN if 'dsn' is invalid.
+func NewWriter(dsn string) (*Writer, error) {
+
+	d, err := ParseDSN(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := "Sentry sentry_version=7, sentry_client=ekago/3, sentry_key=" + d.PublicKey
+	if d.SecretKey != "" {
+		auth += ", sentry_secret=" + d.SecretKey
+	}
+
+	w := &Writer{
+		endpoint: d.StoreURL(),
+		auth:     auth,
+		client:   &http.Client{Timeout: WRITER_DEFAULT_TIMEOUT},
+		queue:    make(chan []byte, WRITER_DEFAULT_QUEUE_SIZE),
+	}
+
+	go w.worker()
+	return w, nil
+}
+
+// SetHTTPClient replaces the http.Client, that is used to send events.
+// Nil http.Client is ignored.
+//
+// This method MUST NOT be called after the first Write() call.
+func (w *Writer) SetHTTPClient(client *http.Client) *Writer {
+	if client != nil {
+		w.client = client
+	}
+	return w
+}
+
+// IsRemote reports that Writer sends the data out of the host.
+// It implements ekalog.CI_WriterRemote, so the entries marked
+// by ekalog.Logger.Local() are not sent to Sentry.
+func (_ *Writer) IsRemote() bool {
+	return true
+}
+
+// Write enqueues Sentry's event 'p' (encoded by Encoder) to be sent.
+// Empty 'p' is ignored. Never returns an error, but drops the event
+// if Writer is closed or its queue is full.
+func (w *Writer) Write(p []byte) (int, error) {
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+
+	if w.isClosed {
+		return len(p), nil
+	}
+
+	event := make([]byte, len(p))
+	copy(event, p)
+
+	w.pending.Add(1)
+	select {
+	case w.queue <- event:
+	default:
+		w.pending.Done()
+	}
+
+	return len(p), nil
+}
+
+// Flush waits until all enqueued events are sent or 'ctx' is done.
+// Returns the last error of events sending (if any) or ctx.Err().
+func (w *Writer) Flush(ctx context.Context) error {
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	done := make(chan struct{})
+	go func() {
+		w.pending.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		w.lastErrM.Lock()
+		defer w.lastErrM.Unlock()
+		err := w.lastErr
+		w.lastErr = nil
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops accepting new events and waits until all enqueued events
+// are sent or 'ctx' is done. See Flush() for more details.
+// The next calls of Close() are no-op.
+func (w *Writer) Close(ctx context.Context) error {
+
+	w.mu.Lock()
+	if w.isClosed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.isClosed = true
+	close(w.queue)
+	w.mu.Unlock()
+
+	return w.Flush(ctx)
+}
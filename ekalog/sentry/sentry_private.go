@@ -0,0 +1,333 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package sentry
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekasys"
+	"github.com/qioalice/ekago/v3/ekatyp"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// event is a Sentry's event. Only used fields are declared.
+	// Read more: https://develop.sentry.dev/sdk/event-payloads/
+	event struct {
+		EventID     string            `json:"event_id"`
+		Timestamp   string            `json:"timestamp"`
+		Level       string            `json:"level"`
+		Platform    string            `json:"platform"`
+		Logger      string            `json:"logger"`
+		Message     string            `json:"message,omitempty"`
+		Environment string            `json:"environment,omitempty"`
+		Release     string            `json:"release,omitempty"`
+		ServerName  string            `json:"server_name,omitempty"`
+		Fingerprint []string          `json:"fingerprint,omitempty"`
+		Tags        map[string]string `json:"tags,omitempty"`
+		Extra       map[string]any    `json:"extra,omitempty"`
+		Exception   struct {
+			Values []exception `json:"values"`
+		} `json:"exception"`
+	}
+
+	exception struct {
+		Type       string      `json:"type"`
+		Value      string      `json:"value,omitempty"`
+		Stacktrace *stacktrace `json:"stacktrace,omitempty"`
+	}
+
+	stacktrace struct {
+		Frames []frame `json:"frames"`
+	}
+
+	frame struct {
+		Function string         `json:"function,omitempty"`
+		Module   string         `json:"module,omitempty"`
+		Filename string         `json:"filename,omitempty"`
+		AbsPath  string         `json:"abs_path,omitempty"`
+		Lineno   int            `json:"lineno,omitempty"`
+		InApp    bool           `json:"in_app"`
+		Vars     map[string]any `json:"vars,omitempty"`
+	}
+)
+
+var (
+	hostname, _ = os.Hostname()
+)
+
+// event converts ekalog.Entry with attached ekaerr.Error to the Sentry's event.
+func (se *Encoder) event(e *ekalog.Entry) *event {
+
+	ev := &event{
+		EventID:     hex.EncodeToString(ekatyp.UUID_NewV4_OrNil().Bytes()),
+		Timestamp:   e.Time.UTC().Format(time.RFC3339Nano),
+		Level:       level(e.Level),
+		Platform:    "go",
+		Logger:      "ekalog",
+		Message:     e.LogLetter.Messages[0].Body,
+		Environment: se.environment,
+		Release:     se.release,
+		ServerName:  se.serverName,
+	}
+
+	if ev.ServerName == "" {
+		ev.ServerName = hostname
+	}
+
+	// Chained exceptions must be ordered from the oldest to the newest one.
+	for i := len(e.ErrLetter.Children) - 1; i >= 0; i-- {
+		ev.Exception.Values = append(ev.Exception.Values, exceptionOf(e.ErrLetter.Children[i], ""))
+	}
+
+	exc := exceptionOf(e.ErrLetter, e.LogLetter.Messages[0].Body)
+	ev.Exception.Values = append(ev.Exception.Values, exc)
+
+	if errorID := systemField(e.ErrLetter, ekaletter.KIND_SYS_TYPE_EKAERR_UUID); errorID != "" {
+		ev.Fingerprint = []string{errorID}
+	}
+
+	se.addFields(ev, se.preEncodedFields)
+	se.addFields(ev, e.LogLetter.Fields)
+
+	// Lightweight error has no stacktrace, so its fields can't be frames' vars.
+	if len(e.ErrLetter.StackTrace) == 0 {
+		se.addFields(ev, e.ErrLetter.Fields)
+	}
+
+	return ev
+}
+
+// addFields adds 'fields' to the Sentry's event as its tags or extra.
+func (se *Encoder) addFields(ev *event, fields []ekaletter.LetterField) {
+
+	var unnamedFieldIdx int16
+	for i, n := 0, len(fields); i < n; i++ {
+		f := &fields[i]
+		if f.IsSystem() || f.IsInvalid() || strings.HasPrefix(f.Key, "sys.") {
+			continue
+		}
+
+		key := f.KeyOrUnnamed(&unnamedFieldIdx)
+		if _, isTag := se.tags[key]; isTag {
+			if ev.Tags == nil {
+				ev.Tags = make(map[string]string)
+			}
+			ev.Tags[key] = fmt.Sprint(fieldValue(f))
+			continue
+		}
+
+		if ev.Extra == nil {
+			ev.Extra = make(map[string]any)
+		}
+		ev.Extra[key] = fieldValue(f)
+	}
+}
+
+// exceptionOf converts ekaerr.Error's ekaletter.Letter to the Sentry's exception.
+// 'message' is used as exception's value if ekaerr.Error has no messages
+// (it's popped as log's message).
+func exceptionOf(l *ekaletter.Letter, message string) exception {
+
+	exc := exception{
+		Type:  systemField(l, ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME),
+		Value: message,
+	}
+
+	if exc.Type == "" {
+		exc.Type = "ekaerr.Error"
+	}
+
+	for i := len(l.Messages) - 1; i >= 0; i-- {
+		if l.Messages[i].Body != "" {
+			exc.Value = l.Messages[i].Body
+			break
+		}
+	}
+
+	if len(l.StackTrace) == 0 {
+		return exc
+	}
+
+	frames := make([]frame, len(l.StackTrace))
+	for i := range l.StackTrace {
+		// Sentry expects frames from the oldest to the newest one,
+		// but ekasys.StackTrace is in the reversed order.
+		frames[len(frames)-1-i] = frameOf(&l.StackTrace[i])
+	}
+
+	var unnamedFieldIdx int16
+	for i, n := 0, len(l.Fields); i < n; i++ {
+		f := &l.Fields[i]
+		if idx := int(f.StackFrameIdx); idx < len(frames) && !f.IsSystem() && !f.IsInvalid() {
+			fr := &frames[len(frames)-1-idx]
+			if fr.Vars == nil {
+				fr.Vars = make(map[string]any)
+			}
+			fr.Vars[f.KeyOrUnnamed(&unnamedFieldIdx)] = fieldValue(f)
+		}
+	}
+
+	exc.Stacktrace = &stacktrace{Frames: frames}
+	return exc
+}
+
+// frameOf converts ekasys.StackFrame to the Sentry's stacktrace frame.
+func frameOf(sf *ekasys.StackFrame) frame {
+
+	fr := frame{
+		Function: sf.Function,
+		AbsPath:  sf.File,
+		Filename: sf.File,
+		Lineno:   sf.Line,
+	}
+
+	// Function is "<package_path>/<package>.<function>".
+	if idx := strings.LastIndexByte(fr.Function, '/'); idx != -1 {
+		if dotIdx := strings.IndexByte(fr.Function[idx:], '.'); dotIdx != -1 {
+			fr.Module = fr.Function[:idx+dotIdx]
+			fr.Function = fr.Function[idx+dotIdx+1:]
+		}
+	} else if dotIdx := strings.IndexByte(fr.Function, '.'); dotIdx != -1 {
+		fr.Module = fr.Function[:dotIdx]
+		fr.Function = fr.Function[dotIdx+1:]
+	}
+
+	if idx := strings.LastIndexByte(fr.Filename, '/'); idx != -1 {
+		fr.Filename = fr.Filename[idx+1:]
+	}
+
+	// Go's runtime and std lib frames are not application's ones.
+	fr.InApp = strings.Contains(fr.Module, ".") || strings.Contains(fr.Module, "/")
+
+	return fr
+}
+
+// systemField returns a string value of ekaletter.Letter's system field
+// with provided 'baseType' or an empty string if there is no such field.
+func systemField(l *ekaletter.Letter, baseType ekaletter.LetterFieldKind) string {
+	for i, n := 0, len(l.SystemFields); i < n; i++ {
+		if l.SystemFields[i].BaseType() == baseType {
+			return l.SystemFields[i].SValue
+		}
+	}
+	return ""
+}
+
+// fieldValue returns a value of ekaletter.LetterField, that can be encoded
+// to JSON by the most natural way.
+func fieldValue(f *ekaletter.LetterField) any {
+
+	if f.Kind.IsNil() {
+		return nil
+	}
+
+	switch f.Kind.BaseType() {
+
+	case ekaletter.KIND_TYPE_BOOL:
+		return f.IValue != 0
+
+	case ekaletter.KIND_TYPE_INT,
+		ekaletter.KIND_TYPE_INT_8, ekaletter.KIND_TYPE_INT_16,
+		ekaletter.KIND_TYPE_INT_32, ekaletter.KIND_TYPE_INT_64:
+		return f.IValue
+
+	case ekaletter.KIND_TYPE_UINT,
+		ekaletter.KIND_TYPE_UINT_8, ekaletter.KIND_TYPE_UINT_16,
+		ekaletter.KIND_TYPE_UINT_32, ekaletter.KIND_TYPE_UINT_64:
+		return uint64(f.IValue)
+
+	case ekaletter.KIND_TYPE_FLOAT_32:
+		return math.Float32frombits(uint32(f.IValue))
+
+	case ekaletter.KIND_TYPE_FLOAT_64:
+		return math.Float64frombits(uint64(f.IValue))
+
+	case ekaletter.KIND_TYPE_UINTPTR, ekaletter.KIND_TYPE_ADDR:
+		return fmt.Sprintf("0x%x", f.IValue)
+
+	case ekaletter.KIND_TYPE_STRING:
+		return f.SValue
+
+	case ekaletter.KIND_TYPE_UNIX:
+		return time.Unix(f.IValue, 0).UTC().Format(time.RFC3339)
+
+	case ekaletter.KIND_TYPE_UNIX_NANO:
+		return time.Unix(0, f.IValue).UTC().Format(time.RFC3339Nano)
+
+	case ekaletter.KIND_TYPE_DURATION:
+		return time.Duration(f.IValue).String()
+
+	case ekaletter.KIND_TYPE_COMPLEX_64, ekaletter.KIND_TYPE_COMPLEX_128:
+		return fmt.Sprint(f.Value)
+
+	default:
+		return f.Value
+	}
+}
+
+// level returns Sentry's level of ekalog.Level.
+func level(l ekalog.Level) string {
+	switch l = l.Base(); {
+	case l <= ekalog.LEVEL_CRITICAL:
+		return "fatal"
+	case l == ekalog.LEVEL_ERROR:
+		return "error"
+	case l == ekalog.LEVEL_WARNING:
+		return "warning"
+	case l == ekalog.LEVEL_DEBUG:
+		return "debug"
+	default:
+		return "info"
+	}
+}
+
+// worker sends enqueued events until Writer is closed.
+func (w *Writer) worker() {
+	for ev := range w.queue {
+		if err := w.send(ev); err != nil {
+			w.lastErrM.Lock()
+			w.lastErr = err
+			w.lastErrM.Unlock()
+		}
+		w.pending.Done()
+	}
+}
+
+// send sends Sentry's event 'ev' to the Sentry's store endpoint.
+func (w *Writer) send(ev []byte) error {
+
+	req, err := http.NewRequest(http.MethodPost, w.endpoint, bytes.NewReader(ev))
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", w.auth)
+
+	resp, err := w.client.Do(req)
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("ekalog/sentry: unexpected response status %q", resp.Status)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// SpanAttribute is a key-value pair of span event's attribute.
+	// Value is a text representation of Entry's field's value.
+	SpanAttribute struct {
+		Key   string
+		Value string
+	}
+
+	// SpanRecorder is an active span of your tracing library,
+	// Entry may be recorded to as an event.
+	// Read more: RegisterSpanExtractor().
+	SpanRecorder interface {
+
+		// AddEvent records an event with 'name' (Entry's message)
+		// and 'attributes' (Entry's fields) to the span.
+		AddEvent(name string, attributes []SpanAttribute)
+
+		// RecordError records an error to the span. 'err' is a text representation
+		// of ekaerr.Error, its ID, Class, fingerprint and fields are presented
+		// as 'attributes' along with Entry's fields.
+		RecordError(err error, attributes []SpanAttribute)
+	}
+
+	// SpanExtractor is a function that extracts an active SpanRecorder
+	// from context.Context. It must return false if there is no active span
+	// in the context.Context.
+	//
+	// Use it to bind ekalog with your tracing library. E.g. for OpenTelemetry:
+	//
+	//	type otelSpan struct{ trace.Span }
+	//
+	//	func (s otelSpan) AddEvent(name string, attrs []ekalog.SpanAttribute) {
+	//	    s.Span.AddEvent(name, trace.WithAttributes(toOtelAttrs(attrs)...))
+	//	}
+	//
+	//	func (s otelSpan) RecordError(err error, attrs []ekalog.SpanAttribute) {
+	//	    s.Span.RecordError(err, trace.WithAttributes(toOtelAttrs(attrs)...))
+	//	}
+	//
+	//	ekalog.RegisterSpanExtractor(func(ctx context.Context) (ekalog.SpanRecorder, bool) {
+	//	    span := trace.SpanFromContext(ctx)
+	//	    if !span.IsRecording() {
+	//	        return nil, false
+	//	    }
+	//	    return otelSpan{span}, true
+	//	})
+	SpanExtractor func(ctx context.Context) (SpanRecorder, bool)
+)
+
+var (
+	spanExtractorsMu sync.RWMutex
+	spanExtractors   []SpanExtractor
+
+	// spanEventsMinLevel is the Level, Entry of which (and more important ones)
+	// are recorded as span events. Read more: SetSpanEventsMinLevel().
+	spanEventsMinLevel = uint32(LEVEL_WARNING)
+)
+
+// RegisterSpanExtractor registers a new SpanExtractor, that will be used
+// by the ctx-aware finishers (like Logger.LogwCtx(), Logger.LogewCtx(), etc)
+// to record Entry as an event of the active span.
+//
+// Only Entry with Level passed SetSpanEventsMinLevel() (LEVEL_WARNING by default)
+// are recorded. Attached ekaerr.Error is recorded using SpanRecorder.RecordError().
+// Span events are recorded regardless of Integrator's min level.
+// Nil SpanExtractor is ignored.
+func RegisterSpanExtractor(extractor SpanExtractor) {
+
+	if extractor == nil {
+		return
+	}
+
+	spanExtractorsMu.Lock()
+	defer spanExtractorsMu.Unlock()
+
+	spanExtractors = append(spanExtractors, extractor)
+}
+
+// SetSpanEventsMinLevel sets the least important Level, Entry of which
+// is recorded as span event by the ctx-aware finishers. Thread-safe.
+// LEVEL_WARNING by default.
+func SetSpanEventsMinLevel(level Level) {
+	atomic.StoreUint32(&spanEventsMinLevel, uint32(level))
+}
+
+// SpanFromContext extracts an active SpanRecorder from 'ctx'
+// using registered SpanExtractor in the order they were registered.
+func SpanFromContext(ctx context.Context) (SpanRecorder, bool) {
+
+	if ctx == nil {
+		return nil, false
+	}
+
+	spanExtractorsMu.RLock()
+	defer spanExtractorsMu.RUnlock()
+
+	for _, extractor := range spanExtractors {
+		if span, ok := extractor(ctx); ok && span != nil {
+			return span, true
+		}
+	}
+
+	return nil, false
+}
@@ -0,0 +1,113 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"context"
+	"sync/atomic"
+	"unsafe"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// spanError is a text representation of ekaerr.Error,
+	// that is passed to SpanRecorder.RecordError().
+	spanError struct {
+		s string
+	}
+)
+
+func (e *spanError) Error() string {
+	return e.s
+}
+
+// logCtx is the same as log() but before that records Entry as an event
+// of the span extracted from 'ctx' (if any) if 'lvl' is important enough.
+// Read more: RegisterSpanExtractor().
+func (l *Logger) logCtx(
+
+	ctx context.Context,
+	lvl Level,
+	msg string,
+	err *ekaerr.Error,
+	fields []ekaletter.LetterField,
+
+) *Logger {
+
+	l.assert()
+	if l == nopLogger {
+		return l
+	}
+
+	if lvl.IsEnabledFor(Level(atomic.LoadUint32(&spanEventsMinLevel))) {
+		if span, ok := SpanFromContext(ctx); ok {
+			l.recordSpanEvent(span, lvl, msg, err, fields)
+		}
+	}
+
+	return l.logSkip(1, lvl, msg, err, nil, fields)
+}
+
+// recordSpanEvent records Entry's data as a span event
+// or a span error (if 'err' is presented).
+func (l *Logger) recordSpanEvent(
+
+	span SpanRecorder,
+	lvl Level,
+	msg string,
+	err *ekaerr.Error,
+	fields []ekaletter.LetterField,
+
+) {
+
+	var unnamedIdx int16
+	attrs := make([]SpanAttribute, 0, 1+len(l.entry.LogLetter.Fields)+len(fields))
+
+	appendAttrs := func(fs []ekaletter.LetterField, withGroup bool) {
+		for i, n := 0, len(fs); i < n; i++ {
+			key := fs[i].KeyOrUnnamed(&unnamedIdx)
+			if withGroup {
+				key = l.entry.groupKey(key)
+			}
+			attrs = append(attrs, SpanAttribute{
+				Key:   key,
+				Value: string(ekaletter.AppendFieldValue(nil, fs[i])),
+			})
+		}
+	}
+
+	attrs = append(attrs, SpanAttribute{Key: "level", Value: lvl.String()})
+	appendAttrs(l.entry.LogLetter.Fields, false)
+	appendAttrs(fields, true)
+
+	errLetter := ekaletter.BridgeErrorGetLetter(unsafe.Pointer(err))
+	if errLetter == nil {
+		span.AddEvent(msg, attrs)
+		return
+	}
+
+	attrs = append(attrs,
+		SpanAttribute{Key: "error_id", Value: err.ID()},
+		SpanAttribute{Key: "error_class_name", Value: err.Class().FullName()},
+		SpanAttribute{Key: "error_fingerprint", Value: err.Fingerprint()},
+	)
+	appendAttrs(errLetter.Fields, false)
+
+	errText := err.Class().FullName()
+	for i := len(errLetter.Messages) - 1; i >= 0; i-- {
+		if errLetter.Messages[i].Body != "" {
+			errText += ": " + errLetter.Messages[i].Body
+			break
+		}
+	}
+	if msg != "" {
+		errText = msg + ": " + errText
+	}
+
+	span.RecordError(&spanError{errText}, attrs)
+}
@@ -0,0 +1,190 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"context"
+	"strings"
+	"sync"
+)
+
+type (
+	// TraceContext is a W3C Trace Context's data, that may be attached to the Logger
+	// to correlate log messages with the traces.
+	//
+	// TraceID, SpanID are lower case hex strings (32 and 16 chars correspondingly),
+	// TraceFlags is a 2 chars hex string (like "01" for sampled trace),
+	// TraceState is a vendor specific "tracestate" header's value.
+	// Only TraceID is required, the rest of fields may be empty.
+	//
+	// Read more:
+	// https://www.w3.org/TR/trace-context/
+	TraceContext struct {
+		TraceID    string
+		SpanID     string
+		TraceFlags string
+		TraceState string
+	}
+
+	// TraceExtractor is a function that extracts TraceContext from context.Context.
+	// It must return false if there is no TraceContext in the context.Context.
+	//
+	// Use it to bind ekalog with your tracing library. E.g. for OpenTelemetry:
+	//
+	//	ekalog.RegisterTraceExtractor(func(ctx context.Context) (ekalog.TraceContext, bool) {
+	//	    sc := trace.SpanContextFromContext(ctx)
+	//	    if !sc.IsValid() {
+	//	        return ekalog.TraceContext{}, false
+	//	    }
+	//	    return ekalog.TraceContext{
+	//	        TraceID:    sc.TraceID().String(),
+	//	        SpanID:     sc.SpanID().String(),
+	//	        TraceFlags: sc.TraceFlags().String(),
+	//	        TraceState: sc.TraceState().String(),
+	//	    }, true
+	//	})
+	TraceExtractor func(ctx context.Context) (TraceContext, bool)
+
+	// traceContextKey is a context.Context's key TraceContext is stored by.
+	traceContextKey struct{}
+)
+
+//noinspection GoSnakeCaseUsage
+const (
+	// Keys of TraceContext's fields. Used by CI_ConsoleEncoder as is.
+	// CI_JSONEncoder uses them as default names, that may be changed using
+	// CI_JSONEncoder.SetNameForField().
+
+	TRACE_FIELD_KEY_TRACE_ID    = "trace_id"
+	TRACE_FIELD_KEY_SPAN_ID     = "span_id"
+	TRACE_FIELD_KEY_TRACE_FLAGS = "trace_flags"
+	TRACE_FIELD_KEY_TRACE_STATE = "tracestate"
+)
+
+var (
+	traceExtractorsMu sync.RWMutex
+	traceExtractors   []TraceExtractor
+)
+
+// ParseTraceparent parses W3C "traceparent" header's value
+// in the "<version>-<trace-id>-<parent-id>-<trace-flags>" format.
+// Returns false if 'traceparent' has invalid format or contains invalid (zero) IDs.
+func ParseTraceparent(traceparent string) (TraceContext, bool) {
+
+	parts := strings.Split(strings.TrimSpace(traceparent), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return TraceContext{}, false
+	}
+
+	tc := TraceContext{
+		TraceID:    parts[1],
+		SpanID:     parts[2],
+		TraceFlags: parts[3],
+	}
+
+	if !tc.IsValid() || len(tc.SpanID) != 16 || len(tc.TraceFlags) != 2 || !isLowerHex(tc.TraceFlags) {
+		return TraceContext{}, false
+	}
+
+	return tc, true
+}
+
+// IsValid reports whether TraceContext contains a valid TraceID and,
+// if it's presented, a valid SpanID.
+func (tc TraceContext) IsValid() bool {
+	return len(tc.TraceID) == 32 && isLowerHex(tc.TraceID) && !isZeroHex(tc.TraceID) &&
+		(tc.SpanID == "" || len(tc.SpanID) == 16 && isLowerHex(tc.SpanID) && !isZeroHex(tc.SpanID))
+}
+
+// Traceparent returns W3C "traceparent" header's value for the current TraceContext.
+// Returns an empty string if TraceContext is not valid or has no SpanID.
+func (tc TraceContext) Traceparent() string {
+
+	if !tc.IsValid() || tc.SpanID == "" {
+		return ""
+	}
+
+	flags := tc.TraceFlags
+	if flags == "" {
+		flags = "00"
+	}
+
+	return "00-" + tc.TraceID + "-" + tc.SpanID + "-" + flags
+}
+
+// ContextWithTrace returns a copy of 'ctx' with attached TraceContext,
+// that then may be extracted using TraceFromContext().
+func ContextWithTrace(ctx context.Context, tc TraceContext) context.Context {
+	return context.WithValue(ctx, traceContextKey{}, tc)
+}
+
+// TraceFromContext extracts TraceContext from 'ctx'.
+// TraceContext attached by ContextWithTrace() has the highest priority,
+// then registered TraceExtractor are used in the order they were registered.
+func TraceFromContext(ctx context.Context) (TraceContext, bool) {
+
+	if ctx == nil {
+		return TraceContext{}, false
+	}
+
+	if tc, ok := ctx.Value(traceContextKey{}).(TraceContext); ok && tc.IsValid() {
+		return tc, true
+	}
+
+	traceExtractorsMu.RLock()
+	defer traceExtractorsMu.RUnlock()
+
+	for _, extractor := range traceExtractors {
+		if tc, ok := extractor(ctx); ok && tc.IsValid() {
+			return tc, true
+		}
+	}
+
+	return TraceContext{}, false
+}
+
+// RegisterTraceExtractor registers a new TraceExtractor, that will be used
+// by TraceFromContext(), Logger.WithTraceFromContext().
+// Nil TraceExtractor is ignored.
+func RegisterTraceExtractor(extractor TraceExtractor) {
+
+	if extractor == nil {
+		return
+	}
+
+	traceExtractorsMu.Lock()
+	defer traceExtractorsMu.Unlock()
+
+	traceExtractors = append(traceExtractors, extractor)
+}
+
+// WithTrace attaches TraceContext to the current Logger, replacing previous one.
+// Invalid TraceContext is ignored.
+// WithTrace DO NOT makes a copy of current Logger, like any other With method.
+func (l *Logger) WithTrace(tc TraceContext) *Logger {
+	return l.setTrace(tc)
+}
+
+// WithTraceFromContext is the same as WithTrace() but extracts TraceContext
+// from 'ctx' using TraceFromContext(). Does nothing if there is no TraceContext.
+func (l *Logger) WithTraceFromContext(ctx context.Context) *Logger {
+	tc, _ := TraceFromContext(ctx)
+	return l.setTrace(tc)
+}
+
+// WithTrace attaches TraceContext to the package-level Logger.
+// See Logger.WithTrace() for more details.
+func WithTrace(tc TraceContext) *Logger {
+	return baseLogger.setTrace(tc)
+}
+
+// WithTraceFromContext attaches TraceContext extracted from 'ctx'
+// to the package-level Logger.
+// See Logger.WithTraceFromContext() for more details.
+func WithTraceFromContext(ctx context.Context) *Logger {
+	tc, _ := TraceFromContext(ctx)
+	return baseLogger.setTrace(tc)
+}
@@ -0,0 +1,46 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekalog
+
+import (
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// setTrace saves TraceContext's parts as a system fields of the Logger's Entry,
+// replacing the previous ones. Does nothing if TraceContext is invalid.
+func (l *Logger) setTrace(tc TraceContext) *Logger {
+	l.assert()
+	if l == nopLogger || !tc.IsValid() {
+		return l
+	}
+
+	l.entry.setSystemField(TRACE_FIELD_KEY_TRACE_ID, tc.TraceID, ekaletter.KIND_SYS_TYPE_TRACE_ID)
+	l.entry.setSystemField(TRACE_FIELD_KEY_SPAN_ID, tc.SpanID, ekaletter.KIND_SYS_TYPE_SPAN_ID)
+	l.entry.setSystemField(TRACE_FIELD_KEY_TRACE_FLAGS, tc.TraceFlags, ekaletter.KIND_SYS_TYPE_TRACE_FLAGS)
+	l.entry.setSystemField(TRACE_FIELD_KEY_TRACE_STATE, tc.TraceState, ekaletter.KIND_SYS_TYPE_TRACE_STATE)
+
+	return l
+}
+
+// isLowerHex reports whether 's' contains only [0-9a-f] chars.
+func isLowerHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9' || s[i] >= 'a' && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// isZeroHex reports whether 's' contains only '0' chars.
+func isZeroHex(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] != '0' {
+			return false
+		}
+	}
+	return true
+}
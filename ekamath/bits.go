@@ -0,0 +1,96 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekamath
+
+import (
+	"math/bits"
+	"unsafe"
+
+	"github.com/qioalice/ekago/v3/ekaext"
+)
+
+// BitSize returns the size of T in bits (8, 16, 32 or 64).
+func BitSize[T ekaext.Unsigned]() int {
+	var v T
+	return int(unsafe.Sizeof(v)) * 8
+}
+
+// CountOnes returns the number of bits that are upped (set to 1) in 'x'.
+func CountOnes[T ekaext.Unsigned](x T) int {
+	return bits.OnesCount64(uint64(x))
+}
+
+// TrailingZeros returns the number of trailing zero bits in 'x',
+// that is the index (starting from 0) of its lowest upped bit.
+// Returns BitSize[T]() if 'x' == 0.
+func TrailingZeros[T ekaext.Unsigned](x T) int {
+	if x == 0 {
+		return BitSize[T]()
+	}
+	return bits.TrailingZeros64(uint64(x))
+}
+
+// LeadingZeros returns the number of leading zero bits in 'x'.
+// Returns BitSize[T]() if 'x' == 0.
+func LeadingZeros[T ekaext.Unsigned](x T) int {
+	return bits.LeadingZeros64(uint64(x)) - (64 - BitSize[T]())
+}
+
+// BitLen returns the minimum number of bits required to represent 'x'.
+// Returns 0 if 'x' == 0.
+func BitLen[T ekaext.Unsigned](x T) int {
+	return bits.Len64(uint64(x))
+}
+
+// IsPowOfTwo reports whether 'x' is a power of two. 0 is not.
+func IsPowOfTwo[T ekaext.Unsigned](x T) bool {
+	return x != 0 && x&(x-1) == 0
+}
+
+// NextPowOfTwo returns the smallest power of two, that is greater than
+// or equal to 'x'. Returns 1 if 'x' == 0
+// and 0 if the result overflows T.
+func NextPowOfTwo[T ekaext.Unsigned](x T) T {
+	if x <= 1 {
+		return 1
+	}
+	shift := BitLen(x - 1)
+	if shift >= BitSize[T]() {
+		return 0
+	}
+	return T(1) << shift
+}
+
+// Log2Floor returns the floor of the binary logarithm of 'x'.
+// Returns -1 if 'x' == 0.
+func Log2Floor[T ekaext.Unsigned](x T) int {
+	return BitLen(x) - 1
+}
+
+// Log2Ceil returns the ceiling of the binary logarithm of 'x'.
+// Returns -1 if 'x' == 0.
+func Log2Ceil[T ekaext.Unsigned](x T) int {
+	if x == 0 {
+		return -1
+	}
+	return BitLen(x - 1)
+}
+
+// RoundUpTo returns 'x' rounded up to the nearest multiple of 'align'.
+// 'align' may be any number, but the power of two one is the fastest.
+// Returns 'x' as is if 'align' == 0. The result wraps around if it overflows T.
+func RoundUpTo[T ekaext.Unsigned](x, align T) T {
+	switch {
+	case align == 0:
+		return x
+	case IsPowOfTwo(align):
+		return (x + align - 1) &^ (align - 1)
+	case x%align == 0:
+		return x
+	default:
+		return (x/align + 1) * align
+	}
+}
@@ -0,0 +1,55 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekamath_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekamath"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBits(t *testing.T) {
+
+	assert.Equal(t, 8, ekamath.BitSize[uint8]())
+	assert.Equal(t, 64, ekamath.BitSize[uint64]())
+
+	assert.Equal(t, 3, ekamath.CountOnes(uint8(0b1011)))
+	assert.Equal(t, 64, ekamath.CountOnes(uint64(math.MaxUint64)))
+
+	assert.Equal(t, 3, ekamath.TrailingZeros(uint16(0b1000)))
+	assert.Equal(t, 16, ekamath.TrailingZeros(uint16(0)))
+	assert.Equal(t, 4, ekamath.LeadingZeros(uint8(0b1000)))
+	assert.Equal(t, 32, ekamath.LeadingZeros(uint32(0)))
+
+	for _, tc := range []struct {
+		x, next        uint8
+		isPow          bool
+		floor, ceiling int
+	}{
+		{0, 1, false, -1, -1},
+		{1, 1, true, 0, 0},
+		{2, 2, true, 1, 1},
+		{3, 4, false, 1, 2},
+		{64, 64, true, 6, 6},
+		{100, 128, false, 6, 7},
+		{128, 128, true, 7, 7},
+		{129, 0, false, 7, 8},
+	} {
+		assert.Equal(t, tc.next, ekamath.NextPowOfTwo(tc.x), tc.x)
+		assert.Equal(t, tc.isPow, ekamath.IsPowOfTwo(tc.x), tc.x)
+		assert.Equal(t, tc.floor, ekamath.Log2Floor(tc.x), tc.x)
+		assert.Equal(t, tc.ceiling, ekamath.Log2Ceil(tc.x), tc.x)
+	}
+
+	assert.Equal(t, uint(16), ekamath.RoundUpTo(uint(9), 8))
+	assert.Equal(t, uint(16), ekamath.RoundUpTo(uint(16), 8))
+	assert.Equal(t, uint(12), ekamath.RoundUpTo(uint(10), 6))
+	assert.Equal(t, uint(12), ekamath.RoundUpTo(uint(12), 6))
+	assert.Equal(t, uint(7), ekamath.RoundUpTo(uint(7), 0))
+}
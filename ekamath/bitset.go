@@ -102,7 +102,7 @@ func (bs *BitSet) CountBetween(a, b uint) uint {
 	c1, off1 := bsFromIdx(a - 1)
 	c2, off2 := bsFromIdx(b - 1)
 
-	if bs1size := bs.chunkSize(); c1 > bs1size || c2 > bs1size {
+	if bs1size := bs.chunkSize(); c1 >= bs1size || c2 >= bs1size {
 		return 0
 	}
 
@@ -282,7 +282,8 @@ func (bs *BitSet) InvertUnsafe(idx uint) *BitSet {
 // IsSet reports whether a bit with requested index is set or not.
 // Returns false either if bit isn't set, BitSet is invalid or index is out of bound.
 func (bs *BitSet) IsSet(idx uint) bool {
-	return bs.isValidIdx(idx, 1, false) && bs.IsSetUnsafe(idx)
+	// isValidIdx() checks the upper bound for 0-based index.
+	return idx >= 1 && bs.isValidIdx(idx-1, 0, false) && bs.IsSetUnsafe(idx)
 }
 
 // IsSetUnsafe reports whether a bit with requested index is set or not.
@@ -490,6 +491,106 @@ func (bs *BitSet) SymmetricDifference(bs2 *BitSet) *BitSet {
 	return bs
 }
 
+// UnionTo makes a union operation of the current BitSet and `bs2`,
+// saving result to `dst` and returns it. Neither current BitSet nor `bs2`
+// is changed, but it's safe to pass any of them as `dst`.
+// Read more: Union().
+//
+// `dst` is grown or shrunk to fit the result, reusing its underlying memory
+// if it's enough, so it's better to pre-allocate it for the read-mostly code.
+//
+// Does nothing if any of BitSets is invalid.
+func (bs *BitSet) UnionTo(dst, bs2 *BitSet) *BitSet {
+
+	if bs.IsValid() && bs2.IsValid() && dst.IsValid() {
+
+		bs1size, bs2size := bs.chunkSize(), bs2.chunkSize()
+		dst.resizeChunks(Max(bs1size, bs2size))
+
+		for i, n := uint(0), dst.chunkSize(); i < n; i++ {
+			dst.bs[i] = bs.chunkAt(i, bs1size) | bs2.chunkAt(i, bs2size)
+		}
+	}
+
+	return dst
+}
+
+// IntersectionTo makes an intersection operation of the current BitSet and `bs2`,
+// saving result to `dst` and returns it. Neither current BitSet nor `bs2`
+// is changed, but it's safe to pass any of them as `dst`.
+// Read more: Intersection(), UnionTo().
+//
+// Does nothing if any of BitSets is invalid.
+func (bs *BitSet) IntersectionTo(dst, bs2 *BitSet) *BitSet {
+
+	if bs.IsValid() && bs2.IsValid() && dst.IsValid() {
+
+		bs1size, bs2size := bs.chunkSize(), bs2.chunkSize()
+		dst.resizeChunks(Min(bs1size, bs2size))
+
+		for i, n := uint(0), dst.chunkSize(); i < n; i++ {
+			dst.bs[i] = bs.bs[i] & bs2.bs[i]
+		}
+	}
+
+	return dst
+}
+
+// DifferenceTo performs a difference operation of the current BitSet and `bs2`,
+// saving result to `dst` and returns it. Neither current BitSet nor `bs2`
+// is changed, but it's safe to pass any of them as `dst`.
+// Read more: Difference(), UnionTo().
+//
+// Does nothing if any of BitSets is invalid.
+func (bs *BitSet) DifferenceTo(dst, bs2 *BitSet) *BitSet {
+
+	if bs.IsValid() && bs2.IsValid() && dst.IsValid() {
+
+		bs1size, bs2size := bs.chunkSize(), bs2.chunkSize()
+		dst.resizeChunks(bs1size)
+
+		for i, n := uint(0), dst.chunkSize(); i < n; i++ {
+			dst.bs[i] = bs.bs[i] &^ bs2.chunkAt(i, bs2size)
+		}
+	}
+
+	return dst
+}
+
+// Equal reports whether current BitSet and `bs2` have the same upped bits.
+// Capacities are not compared, so BitSets of different capacities
+// are equal if the bits out of the upper bound of the smaller one are downed.
+// Invalid BitSet is considered empty (read more: IsEmpty()).
+func (bs *BitSet) Equal(bs2 *BitSet) bool {
+
+	bs1size, bs2size := bs.validChunkSize(), bs2.validChunkSize()
+
+	for i, n := uint(0), Max(bs1size, bs2size); i < n; i++ {
+		if bs.chunkAt(i, bs1size) != bs2.chunkAt(i, bs2size) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// IsSubsetOf reports whether each upped bit of the current BitSet
+// is upped in `bs2` either. Empty BitSet is a subset of any BitSet.
+// Invalid BitSet is considered empty (read more: IsEmpty()).
+// Read more: https://en.wikipedia.org/wiki/Subset
+func (bs *BitSet) IsSubsetOf(bs2 *BitSet) bool {
+
+	bs1size, bs2size := bs.validChunkSize(), bs2.validChunkSize()
+
+	for i := uint(0); i < bs1size; i++ {
+		if bs.bs[i]&^bs2.chunkAt(i, bs2size) != 0 {
+			return false
+		}
+	}
+
+	return true
+}
+
 // ---------------------------------------------------------------------------- //
 
 // MarshalBinary implements BinaryMarshaler interface encoding current BitSet
@@ -503,6 +604,8 @@ func (bs *BitSet) SymmetricDifference(bs2 *BitSet) *BitSet {
 // User MUST NOT modify returned data. If you need it, clone it firstly.
 //
 // Despite of warnings and restrictions, this method has O(1) complexity.
+// It has O(N) complexity and returns a copy if "ekasafe" build tag is set.
+// Read more: ekaunsafe.ZeroCopyConversions().
 func (bs *BitSet) MarshalBinary() ([]byte, error) {
 
 	if !bs.IsValid() {
@@ -528,6 +631,8 @@ func (bs *BitSet) MarshalBinary() ([]byte, error) {
 // User MUST NOT use provided `data` after passing to this method. UB otherwise.
 //
 // Despite of warnings and restrictions, this method has O(1) complexity.
+// It has O(N) complexity and copies `data` if "ekasafe" build tag is set.
+// Read more: ekaunsafe.ZeroCopyConversions().
 func (bs *BitSet) UnmarshalBinary(data []byte) error {
 
 	switch l := len(data); {
@@ -537,7 +642,7 @@ func (bs *BitSet) UnmarshalBinary(data []byte) error {
 	case bs == nil:
 		return ErrBitSetInvalid
 
-	case l&_BITSET_BYTES_PER_CHUNK != 0:
+	case l%_BITSET_BYTES_PER_CHUNK != 0:
 		return ErrBitSetInvalidDataToDecode
 	}
 
@@ -600,7 +705,7 @@ func (bs *BitSet) UnmarshalText(data []byte) error {
 	}
 
 	buf = buf[:n]
-	if len(buf)&_BITSET_BYTES_PER_CHUNK != 0 {
+	if len(buf)%_BITSET_BYTES_PER_CHUNK != 0 {
 		return ErrBitSetInvalidDataToDecode
 	}
 
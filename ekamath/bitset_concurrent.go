@@ -0,0 +1,419 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekamath
+
+import (
+	"encoding/base64"
+	"sync"
+)
+
+type (
+	// ConcurrentBitSet is a BitSet that is safe for concurrent use.
+	// It has the same API as BitSet has, except unsafe methods.
+	//
+	// Single bit operations (Up(), Down(), Set(), Invert(), IsSet(), etc)
+	// are atomic word operations (CAS loops) and thus do not block each other,
+	// unless ConcurrentBitSet must be grown.
+	// Growing, set operations (Union(), Intersection(), etc) and decoding
+	// lock the whole ConcurrentBitSet.
+	//
+	// Operations that read many bits (Count(), NextUp(), etc) are not atomic
+	// as a whole: each chunk is read atomically, but bits of already read chunks
+	// may be changed concurrently.
+	//
+	// Use TryUp(), TryDown() to get to know whether the bit has been changed
+	// by the current call, e.g. to use ConcurrentBitSet as an ID allocation bitmap.
+	//
+	// It's strongly recommend to instantiate ConcurrentBitSet using
+	// NewConcurrentBitSet() constructor, but just creating
+	// a ConcurrentBitSet is also possible and ready-to-use.
+	ConcurrentBitSet struct {
+		mu sync.RWMutex
+		bs BitSet
+	}
+)
+
+// ---------------------------------------------------------------------------- //
+
+// IsValid reports whether current ConcurrentBitSet is valid.
+func (bs *ConcurrentBitSet) IsValid() bool {
+	return bs != nil
+}
+
+// IsEmpty reports whether current ConcurrentBitSet has all downed (zeroed) bits.
+// Returns true if ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) IsEmpty() bool {
+
+	if !bs.IsValid() {
+		return true
+	}
+
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	for i, n := uint(0), bs.bs.chunkSize(); i < n; i++ {
+		if bs.load(i) != 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ---------------------------------------------------------------------------- //
+
+// Capacity returns the number of values (starting from 1) that can be stored
+// inside current ConcurrentBitSet.
+// Returns 0 if current ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) Capacity() uint {
+
+	if !bs.IsValid() {
+		return 0
+	}
+
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	return bs.bs.Capacity()
+}
+
+// Count returns number of bits that are upped (set to 1).
+// Returns 0 if current ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) Count() uint {
+
+	if !bs.IsValid() {
+		return 0
+	}
+
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	var c uint
+	for i, n := uint(0), bs.bs.chunkSize(); i < n; i++ {
+		c += bsCountOnes(bs.load(i))
+	}
+
+	return c
+}
+
+// CountBetween returns number of bits that are upped (set to 1),
+// between range [a..b]. Note: `b` IS IN the range.
+// Returns 0 if either current ConcurrentBitSet is invalid, `a` >= `b`
+// or any part of that range is out of bound of the ConcurrentBitSet.
+func (bs *ConcurrentBitSet) CountBetween(a, b uint) uint {
+
+	if !bs.IsValid() || a >= b {
+		return 0
+	}
+
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	c1, off1 := bsFromIdx(a - 1)
+	c2, off2 := bsFromIdx(b - 1)
+
+	if bs1size := bs.bs.chunkSize(); c1 >= bs1size || c2 >= bs1size {
+		return 0
+	}
+
+	if c1 == c2 {
+		mask := (_BITSET_MASK_FULL >> (_BITSET_BITS_PER_CHUNK - off2 + off1 - 1)) << off1
+		return bsCountOnes(bs.load(c1) & mask)
+	}
+
+	c := bsCountOnes(bs.load(c1) & (_BITSET_MASK_FULL << off1))
+	c += bsCountOnes(bs.load(c2) & (_BITSET_MASK_FULL >> (_BITSET_BITS_PER_CHUNK - off2 - 1)))
+
+	for c1++; c1 < c2; c1++ {
+		c += bsCountOnes(bs.load(c1))
+	}
+
+	return c
+}
+
+// ---------------------------------------------------------------------------- //
+
+// Clear downs (zeroes) ALL bits in the current ConcurrentBitSet.
+// Does nothing if ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) Clear() *ConcurrentBitSet {
+	if bs.IsValid() {
+		bs.mu.Lock()
+		bs.bs.Clear()
+		bs.mu.Unlock()
+	}
+	return bs
+}
+
+// Clone makes a copy of ConcurrentBitSet and returns it.
+// If ConcurrentBitSet is invalid, NewConcurrentBitSet() is called instead.
+func (bs *ConcurrentBitSet) Clone() *ConcurrentBitSet {
+
+	if !bs.IsValid() {
+		return NewConcurrentBitSet(0)
+	}
+
+	return &ConcurrentBitSet{
+		bs: BitSet{bs: bs.snapshot()},
+	}
+}
+
+// Snapshot returns a copy of ConcurrentBitSet as BitSet.
+// Returns nil if ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) Snapshot() *BitSet {
+
+	if !bs.IsValid() {
+		return nil
+	}
+
+	return &BitSet{bs: bs.snapshot()}
+}
+
+// GrowUpTo grows current ConcurrentBitSet to be able operate with bits
+// up to requested index. Does nothing if ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) GrowUpTo(idx uint) *ConcurrentBitSet {
+	if bs.IsValid() {
+		bs.mu.Lock()
+		bs.bs.GrowUnsafeUpTo(idx)
+		bs.mu.Unlock()
+	}
+	return bs
+}
+
+// ShrinkUpTo shrinks current ConcurrentBitSet to be able operate with bits
+// up to requested index.
+// Does nothing if current ConcurrentBitSet is less than the requested index
+// or if it's invalid.
+func (bs *ConcurrentBitSet) ShrinkUpTo(idx uint) *ConcurrentBitSet {
+	if bs.IsValid() && idx > 0 {
+		bs.mu.Lock()
+		bs.bs.ShrinkUpTo(idx)
+		bs.mu.Unlock()
+	}
+	return bs
+}
+
+// ---------------------------------------------------------------------------- //
+
+// Up sets bit to 1 with requested index checking bounds,
+// growing bitset if it's too small.
+// Does nothing if ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) Up(idx uint) *ConcurrentBitSet {
+	bs.TryUp(idx)
+	return bs
+}
+
+// TryUp sets bit to 1 with requested index checking bounds,
+// growing bitset if it's too small.
+// Reports whether the bit has been changed (it was 0) by the current call.
+// Returns false if ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) TryUp(idx uint) bool {
+	if !bs.isValidIdx(idx, 1) {
+		return false
+	}
+	chunk, offset := bsFromIdx(idx - 1)
+	return bs.modify(chunk, func(v uint) uint { return v | 1<<offset })&(1<<offset) == 0
+}
+
+// Down sets bit to 0 with requested index checking bounds,
+// growing bitset if it's too small.
+// Does nothing if ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) Down(idx uint) *ConcurrentBitSet {
+	bs.TryDown(idx)
+	return bs
+}
+
+// TryDown sets bit to 0 with requested index checking bounds,
+// growing bitset if it's too small.
+// Reports whether the bit has been changed (it was 1) by the current call.
+// Returns false if ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) TryDown(idx uint) bool {
+	if !bs.isValidIdx(idx, 1) {
+		return false
+	}
+	chunk, offset := bsFromIdx(idx - 1)
+	return bs.modify(chunk, func(v uint) uint { return v &^ (1 << offset) })&(1<<offset) != 0
+}
+
+// Set calls Up() or Down() with provided index depends on `b`.
+func (bs *ConcurrentBitSet) Set(idx uint, b bool) *ConcurrentBitSet {
+	if b {
+		return bs.Up(idx)
+	} else {
+		return bs.Down(idx)
+	}
+}
+
+// Invert changes bit to against value with requested index checking bounds,
+// growing bitset if it's too small.
+// Does nothing if ConcurrentBitSet is invalid.
+func (bs *ConcurrentBitSet) Invert(idx uint) *ConcurrentBitSet {
+	if bs.isValidIdx(idx, 1) {
+		chunk, offset := bsFromIdx(idx - 1)
+		bs.modify(chunk, func(v uint) uint { return v ^ 1<<offset })
+	}
+	return bs
+}
+
+// IsSet reports whether a bit with requested index is set or not.
+// Returns false either if bit isn't set, ConcurrentBitSet is invalid
+// or index is out of bound.
+func (bs *ConcurrentBitSet) IsSet(idx uint) bool {
+
+	if !bs.isValidIdx(idx, 1) {
+		return false
+	}
+
+	chunk, offset := bsFromIdx(idx - 1)
+
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	return chunk < bs.bs.chunkSize() && bs.load(chunk)&(1<<offset) != 0
+}
+
+// ---------------------------------------------------------------------------- //
+
+// NextUp returns an index of next upped (set to 1) bit.
+// It's safe to use 0 as index because this is the only way to get 1st bit.
+// Read more: BitSet.NextUp().
+func (bs *ConcurrentBitSet) NextUp(idx uint) (uint, bool) {
+	return bs.nextGeneric(idx, false)
+}
+
+// NextDown returns an index of next downed (set to 0) bit.
+// It's safe to use 0 as index because this is the only way to get 1st bit.
+// Read more: BitSet.NextDown().
+func (bs *ConcurrentBitSet) NextDown(idx uint) (uint, bool) {
+	return bs.nextGeneric(idx, true)
+}
+
+// PrevUp returns an index of prev upped (set to 1) bit.
+// Read more: BitSet.PrevUp().
+func (bs *ConcurrentBitSet) PrevUp(idx uint) (uint, bool) {
+	return bs.prevGeneric(idx, false)
+}
+
+// PrevDown returns an index of prev downed (set to 0) bit.
+// Read more: BitSet.PrevDown().
+func (bs *ConcurrentBitSet) PrevDown(idx uint) (uint, bool) {
+	return bs.prevGeneric(idx, true)
+}
+
+// ---------------------------------------------------------------------------- //
+
+// Complement makes a complement operation (invert all bits),
+// saving result to the current ConcurrentBitSet and returns it.
+// Read more: BitSet.Complement().
+func (bs *ConcurrentBitSet) Complement() *ConcurrentBitSet {
+	if bs.IsValid() {
+		bs.mu.Lock()
+		bs.bs.Complement()
+		bs.mu.Unlock()
+	}
+	return bs
+}
+
+// Union makes a union operation, saving result to the current ConcurrentBitSet
+// and returns it. Read more: BitSet.Union().
+//
+// It's safe to pass the current ConcurrentBitSet as `bs2`.
+func (bs *ConcurrentBitSet) Union(bs2 *ConcurrentBitSet) *ConcurrentBitSet {
+	return bs.setOp(bs2, (*BitSet).Union)
+}
+
+// Intersection makes an intersection operation, saving result
+// to the current ConcurrentBitSet and returns it.
+// Read more: BitSet.Intersection().
+//
+// It's safe to pass the current ConcurrentBitSet as `bs2`.
+func (bs *ConcurrentBitSet) Intersection(bs2 *ConcurrentBitSet) *ConcurrentBitSet {
+	return bs.setOp(bs2, (*BitSet).Intersection)
+}
+
+// Difference performs a difference operation, saving result
+// to the current ConcurrentBitSet and returns it.
+// Read more: BitSet.Difference().
+//
+// It's safe to pass the current ConcurrentBitSet as `bs2`.
+func (bs *ConcurrentBitSet) Difference(bs2 *ConcurrentBitSet) *ConcurrentBitSet {
+	return bs.setOp(bs2, (*BitSet).Difference)
+}
+
+// SymmetricDifference performs a symmetric difference (XOR) operation,
+// saving result to the current ConcurrentBitSet and returns it.
+// Read more: BitSet.SymmetricDifference().
+//
+// It's safe to pass the current ConcurrentBitSet as `bs2`.
+func (bs *ConcurrentBitSet) SymmetricDifference(bs2 *ConcurrentBitSet) *ConcurrentBitSet {
+	return bs.setOp(bs2, (*BitSet).SymmetricDifference)
+}
+
+// ---------------------------------------------------------------------------- //
+
+// MarshalBinary implements BinaryMarshaler interface encoding current
+// ConcurrentBitSet in binary form. Read more: BitSet.MarshalBinary().
+//
+// Unlike BitSet.MarshalBinary() it returns a copy of underlying data,
+// so it has O(N) complexity.
+func (bs *ConcurrentBitSet) MarshalBinary() ([]byte, error) {
+
+	if !bs.IsValid() {
+		return nil, ErrBitSetInvalid
+	}
+
+	return bsUnsafeToBytesSlice(bs.snapshot()), nil
+}
+
+// UnmarshalBinary implements BinaryUnmarshaler interface decoding provided `data`
+// from binary form. Read more: BitSet.UnmarshalBinary().
+func (bs *ConcurrentBitSet) UnmarshalBinary(data []byte) error {
+
+	if bs == nil {
+		return ErrBitSetInvalid
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	return bs.bs.UnmarshalBinary(data)
+}
+
+// MarshalText implements TextMarshaler interface encoding current
+// ConcurrentBitSet in text form. Read more: BitSet.MarshalText().
+func (bs *ConcurrentBitSet) MarshalText() ([]byte, error) {
+
+	binaryEncodedData, err := bs.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, base64.StdEncoding.EncodedLen(len(binaryEncodedData)))
+	base64.StdEncoding.Encode(buf, binaryEncodedData)
+
+	return buf, nil
+}
+
+// UnmarshalText implements TextUnmarshaler interface decoding provided `data`
+// from text form. Read more: BitSet.UnmarshalText().
+func (bs *ConcurrentBitSet) UnmarshalText(data []byte) error {
+
+	if bs == nil {
+		return ErrBitSetInvalid
+	}
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	return bs.bs.UnmarshalText(data)
+}
+
+// ---------------------------------------------------------------------------- //
+
+// NewConcurrentBitSet creates a new ConcurrentBitSet with desired initial capacity.
+func NewConcurrentBitSet(capacity uint) *ConcurrentBitSet {
+	bs := new(ConcurrentBitSet)
+	bs.bs.GrowUnsafeUpTo(capacity)
+	return bs
+}
@@ -0,0 +1,175 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekamath
+
+import (
+	"sync/atomic"
+	"unsafe"
+)
+
+// There's no atomic operations for uint in Go 1.18,
+// so the uintptr ones are used, that is the same size on all platforms.
+var _ = [1]struct{}{}[unsafe.Sizeof(uint(0))-unsafe.Sizeof(uintptr(0))]
+
+// Returns a value of the chunk with provided index loading it atomically.
+// Requires bs.mu to be locked (at least for reading).
+func (bs *ConcurrentBitSet) load(chunk uint) uint {
+	return uint(atomic.LoadUintptr((*uintptr)(unsafe.Pointer(&bs.bs.bs[chunk]))))
+}
+
+// Reports whether ConcurrentBitSet can contain a bit with provided index.
+// It includes IsValid() call, so you don't need to call it explicitly.
+// The upper bound is not checked, because ConcurrentBitSet may be grown
+// concurrently.
+func (bs *ConcurrentBitSet) isValidIdx(idx uint, lowerBound uint) bool {
+	return bs.IsValid() && idx >= lowerBound
+}
+
+// Replaces a value of the chunk with provided index by the result of `op`
+// atomically (CAS loop), growing ConcurrentBitSet if it's too small.
+// Returns the old value of the chunk.
+func (bs *ConcurrentBitSet) modify(chunk uint, op func(v uint) uint) uint {
+
+	bs.mu.RLock()
+	if chunk < bs.bs.chunkSize() {
+		ptr := (*uintptr)(unsafe.Pointer(&bs.bs.bs[chunk]))
+		for {
+			old := atomic.LoadUintptr(ptr)
+			if atomic.CompareAndSwapUintptr(ptr, old, uintptr(op(uint(old)))) {
+				bs.mu.RUnlock()
+				return uint(old)
+			}
+		}
+	}
+	bs.mu.RUnlock()
+
+	// Growing is required. There's no concurrent readers or writers
+	// when the write lock is acquired, so atomic operations are not required.
+
+	bs.mu.Lock()
+	defer bs.mu.Unlock()
+
+	bs.bs.GrowUnsafeUpTo((chunk + 1) * _BITSET_BITS_PER_CHUNK)
+
+	old := bs.bs.bs[chunk]
+	bs.bs.bs[chunk] = op(old)
+
+	return old
+}
+
+// Returns a copy of ConcurrentBitSet's chunks.
+func (bs *ConcurrentBitSet) snapshot() []uint {
+
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	cloned := make([]uint, bs.bs.chunkSize())
+	for i := range cloned {
+		cloned[i] = bs.load(uint(i))
+	}
+
+	return cloned
+}
+
+// Performs a set operation `op` with the snapshot of `bs2`
+// and the current ConcurrentBitSet locked.
+func (bs *ConcurrentBitSet) setOp(
+	bs2 *ConcurrentBitSet, op func(*BitSet, *BitSet) *BitSet) *ConcurrentBitSet {
+
+	if !bs.IsValid() || !bs2.IsValid() {
+		return bs
+	}
+
+	// A snapshot is used instead of locking `bs2`,
+	// thus there's no deadlock if `bs2` is the current ConcurrentBitSet
+	// or if bs2.Union(bs) is called concurrently.
+	snapshot := BitSet{bs: bs2.snapshot()}
+
+	bs.mu.Lock()
+	op(&bs.bs, &snapshot)
+	bs.mu.Unlock()
+
+	return bs
+}
+
+// Returns a next upped or downed bit index depends on `isDown`.
+// Read more: BitSet.nextGeneric().
+func (bs *ConcurrentBitSet) nextGeneric(idx uint, isDown bool) (uint, bool) {
+
+	if !bs.isValidIdx(idx, 0) {
+		return idx, false
+	}
+
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	if bsChunksForBits(idx+1) > bs.bs.chunkSize() {
+		return idx, false
+	}
+
+	chunk, offset := uint(0), uint(0)
+	if idx != 0 {
+		chunk, offset = bsFromIdx(idx)
+	}
+
+	v := bs.load(chunk) >> offset
+	if isDown {
+		v = ^v
+	}
+	if n := bs1stUp(v); n < _BITSET_BITS_PER_CHUNK-offset {
+		return bsToIdx(chunk, offset+n) + 1, true
+	}
+
+	for i, n := chunk+1, bs.bs.chunkSize(); i < n; i++ {
+		v := bs.load(i)
+		if isDown {
+			v = ^v
+		}
+		if n := bs1stUp(v); n != _BITSET_BITS_PER_CHUNK {
+			return bsToIdx(i, n) + 1, true
+		}
+	}
+
+	return idx, false
+}
+
+// Returns a prev upped or downed bit index depends on `isDown`.
+// Read more: BitSet.prevGeneric().
+func (bs *ConcurrentBitSet) prevGeneric(idx uint, isDown bool) (uint, bool) {
+
+	if !bs.isValidIdx(idx, 2) {
+		return idx, false
+	}
+
+	bs.mu.RLock()
+	defer bs.mu.RUnlock()
+
+	if bsChunksForBits(idx+1) > bs.bs.chunkSize() {
+		return idx, false
+	}
+
+	chunk, offset := bsFromIdx(idx)
+
+	v := bs.load(chunk) << (_BITSET_BITS_PER_CHUNK - offset + 1)
+	if isDown {
+		v = ^v
+	}
+	if n := bsLastUp(v); n < _BITSET_BITS_PER_CHUNK-offset {
+		return bsToIdx(chunk, offset-n-1), true
+	}
+
+	for i := int(chunk) - 1; i >= 0; i-- {
+		v := bs.load(uint(i))
+		if isDown {
+			v = ^v
+		}
+		if n := bsLastUp(v); n != _BITSET_BITS_PER_CHUNK {
+			return bsToIdx(uint(i), _BITSET_BITS_PER_CHUNK-n), true
+		}
+	}
+
+	return idx, false
+}
@@ -0,0 +1,99 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekamath_test
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekamath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestConcurrentBitSet(t *testing.T) {
+
+	bs := ekamath.NewConcurrentBitSet(32)
+	require.True(t, bs.IsEmpty())
+
+	bs.Up(2).Up(10).Up(1064)
+
+	require.EqualValues(t, 3, bs.Count())
+	require.EqualValues(t, 2, bs.CountBetween(1, 10))
+	require.EqualValues(t, 0, ekamath.NewConcurrentBitSet(64).CountBetween(1, 100))
+	require.True(t, bs.IsSet(1064))
+	require.False(t, bs.IsSet(1065))
+
+	v, ok := bs.NextUp(2)
+	require.True(t, ok)
+	require.EqualValues(t, 10, v)
+
+	v, ok = bs.PrevUp(1064)
+	require.True(t, ok)
+	require.EqualValues(t, 10, v)
+
+	v, ok = bs.NextDown(0)
+	require.True(t, ok)
+	require.EqualValues(t, 1, v)
+
+	require.False(t, bs.TryUp(10))
+	require.True(t, bs.TryDown(10))
+	require.False(t, bs.TryDown(10))
+
+	data, err := bs.MarshalText()
+	require.NoError(t, err)
+
+	bs2 := new(ekamath.ConcurrentBitSet)
+	require.NoError(t, bs2.UnmarshalText(data))
+	require.EqualValues(t, 2, bs2.Count())
+	require.True(t, bs2.IsSet(2))
+	require.True(t, bs2.IsSet(1064))
+
+	bs2.Clear().Up(3)
+	bs.Union(bs2)
+	require.EqualValues(t, 3, bs.Count())
+
+	bs.Intersection(bs2)
+	require.EqualValues(t, 1, bs.Count())
+	require.True(t, bs.IsSet(3))
+
+	bs.Union(bs)
+	require.EqualValues(t, 1, bs.Count())
+	require.EqualValues(t, 1, bs.Snapshot().Count())
+}
+
+func TestConcurrentBitSet_TryUp(t *testing.T) {
+
+	//goland:noinspection GoSnakeCaseUsage
+	const (
+		N_GOROUTINES = 8
+		N_BITS       = 4096
+	)
+
+	var (
+		bs      = new(ekamath.ConcurrentBitSet)
+		claimed uint32
+		wg      sync.WaitGroup
+	)
+
+	for i := 0; i < N_GOROUTINES; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := uint(1); idx <= N_BITS; idx++ {
+				if bs.TryUp(idx) {
+					atomic.AddUint32(&claimed, 1)
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	require.EqualValues(t, N_BITS, claimed)
+	require.EqualValues(t, N_BITS, bs.Count())
+}
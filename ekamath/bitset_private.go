@@ -7,8 +7,6 @@ package ekamath
 
 import (
 	"math/bits"
-	"reflect"
-	"unsafe"
 )
 
 //goland:noinspection GoSnakeCaseUsage
@@ -35,6 +33,45 @@ func (bs *BitSet) chunkCapacity() uint {
 	return uint(cap(bs.bs))
 }
 
+// Returns a chunk number of the current BitSet or 0 if it's invalid.
+func (bs *BitSet) validChunkSize() uint {
+	if !bs.IsValid() {
+		return 0
+	}
+	return bs.chunkSize()
+}
+
+// Returns a chunk with requested number or 0 if it's out of `size`.
+func (bs *BitSet) chunkAt(chunk, size uint) uint {
+	if chunk >= size {
+		return 0
+	}
+	return bs.bs[chunk]
+}
+
+// Changes the chunk number of the current BitSet to `n`, keeping the chunks
+// within the new bound and zeroing the ones out of it (thus the next growing
+// using the same underlying memory won't restore them).
+// The underlying memory is reallocated only if it's not enough.
+func (bs *BitSet) resizeChunks(n uint) {
+
+	switch bs1size := bs.chunkSize(); {
+	case n <= bs1size:
+		for i := n; i < bs1size; i++ {
+			bs.bs[i] = 0
+		}
+		bs.bs = bs.bs[:n]
+
+	case n <= bs.chunkCapacity():
+		bs.bs = bs.bs[:n]
+
+	default:
+		old := bs.bs
+		bs.bs = make([]uint, n)
+		copy(bs.bs, old)
+	}
+}
+
 // Reports whether BitSet can contain a bit with provided index.
 // It includes IsValid() call, so you don't need to call it explicitly.
 func (bs *BitSet) isValidIdx(idx uint, lowerBound uint, skipUpperBoundCheck bool) bool {
@@ -99,7 +136,7 @@ func (bs *BitSet) prevGeneric(idx uint, isDown bool) (uint, bool) {
 
 // Returns a number of bits that are upped (set to 1).
 func bsCountOnes(n uint) uint {
-	return uint(bits.OnesCount(n))
+	return uint(CountOnes(n))
 }
 
 // Returns a minimum number of chunks that is required to store `n` bits.
@@ -130,51 +167,11 @@ func bsToIdx(chunk, offset uint) uint {
 // Returns an index (starting from 0) of 1st upped (set to 1) bit.
 // If there's no such bits, _BITSET_BITS_PER_CHUNK is returned.
 func bs1stUp(n uint) uint {
-	return uint(bits.TrailingZeros(n))
+	return uint(TrailingZeros(n))
 }
 
 // Returns an index (starting from 0) of 1st downed (set to 0) bit.
 // If there's no such bits, _BITSET_BITS_PER_CHUNK is returned.
 func bsLastUp(n uint) uint {
-	return uint(bits.LeadingZeros(n))
-}
-
-// Returns a []byte that has the same bytes that provided BitSet's underlying data.
-//
-// **UNSAFE**
-// Highly unsafe! User MUST NOT modify returned object.
-func bsUnsafeToBytesSlice(bsData []uint) []byte {
-
-	var ret []byte
-
-	shOrig := (*reflect.SliceHeader)(unsafe.Pointer(&bsData))
-	shRet := (*reflect.SliceHeader)(unsafe.Pointer(&ret))
-
-	shRet.Data = shOrig.Data
-	shRet.Len = shOrig.Len * _BITSET_BYTES_PER_CHUNK
-	shRet.Cap = shOrig.Cap * _BITSET_BYTES_PER_CHUNK
-
-	return ret
-}
-
-// Returns a BitSet's underlying data that has the same bytes as provided.
-//
-// **UNSAFE**
-// Highly unsafe! User MUST NOT use `data` object after passing here.
-//
-// WARNING!
-// The length of `data` must be compatible with the bytes consumption
-// of underlying chunk slice.
-func bsUnsafeFromBytesSlice(data []byte) []uint {
-
-	var ret []uint
-
-	shOrig := (*reflect.SliceHeader)(unsafe.Pointer(&data))
-	shRet := (*reflect.SliceHeader)(unsafe.Pointer(&ret))
-
-	shRet.Data = shOrig.Data
-	shRet.Len = shOrig.Len / _BITSET_BYTES_PER_CHUNK
-	shRet.Cap = shOrig.Cap / _BITSET_BYTES_PER_CHUNK
-
-	return ret
+	return uint(LeadingZeros(n))
 }
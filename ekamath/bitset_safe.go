@@ -0,0 +1,60 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build ekasafe
+
+package ekamath
+
+import (
+	"encoding/binary"
+)
+
+// Returns a []byte that has the copy of provided BitSet's underlying data.
+// Each chunk is encoded as little-endian, that is the same as the zero-copy
+// implementation does on little-endian platforms (amd64, arm64, 386, etc).
+func bsUnsafeToBytesSlice(bsData []uint) []byte {
+
+	ret := make([]byte, len(bsData)*_BITSET_BYTES_PER_CHUNK)
+
+	for i, chunk := range bsData {
+		bsPutChunk(ret[i*_BITSET_BYTES_PER_CHUNK:], chunk)
+	}
+
+	return ret
+}
+
+// Returns a BitSet's underlying data decoded from the copy of provided bytes.
+// Read more: bsUnsafeToBytesSlice().
+//
+// WARNING!
+// The length of `data` must be compatible with the bytes consumption
+// of underlying chunk slice.
+func bsUnsafeFromBytesSlice(data []byte) []uint {
+
+	ret := make([]uint, len(data)/_BITSET_BYTES_PER_CHUNK)
+
+	for i := range ret {
+		ret[i] = bsGetChunk(data[i*_BITSET_BYTES_PER_CHUNK:])
+	}
+
+	return ret
+}
+
+// Encodes 'chunk' to 'dest' as little-endian.
+func bsPutChunk(dest []byte, chunk uint) {
+	if _BITSET_BYTES_PER_CHUNK == 8 {
+		binary.LittleEndian.PutUint64(dest, uint64(chunk))
+	} else {
+		binary.LittleEndian.PutUint32(dest, uint32(chunk))
+	}
+}
+
+// Decodes little-endian encoded chunk from 'src'.
+func bsGetChunk(src []byte) uint {
+	if _BITSET_BYTES_PER_CHUNK == 8 {
+		return uint(binary.LittleEndian.Uint64(src))
+	}
+	return uint(binary.LittleEndian.Uint32(src))
+}
@@ -138,6 +138,55 @@ func TestBitSet_Operations(t *testing.T) {
 	require.EqualValues(t, []uint{2, 4, 5, 8, 9, 11, 12, 13, 15, 16, 17, 18}, bs3.DebugOnesAsSlice(32))
 }
 
+func TestBitSet_OperationsTo(t *testing.T) {
+
+	bs1 := ekamath.NewBitSet(32)
+	bs2 := ekamath.NewBitSet(200)
+
+	for _, set1Elem := range []uint{1, 3, 6, 7, 10, 14, 30} {
+		bs1.Up(set1Elem)
+	}
+
+	for _, set2Elem := range []uint{1, 2, 3, 4, 10, 31, 150} {
+		bs2.Up(set2Elem)
+	}
+
+	dst := ekamath.NewBitSet(256)
+	dst.Up(255)
+
+	bs1.UnionTo(dst, bs2)
+	require.EqualValues(t, []uint{1, 2, 3, 4, 6, 7, 10, 14, 30, 31, 150}, dst.DebugOnesAsSlice(256))
+
+	bs1.IntersectionTo(dst, bs2)
+	require.EqualValues(t, []uint{1, 3, 10}, dst.DebugOnesAsSlice(256))
+
+	bs2.DifferenceTo(dst, bs1)
+	require.EqualValues(t, []uint{2, 4, 31, 150}, dst.DebugOnesAsSlice(256))
+
+	// Inputs are untouched.
+	require.EqualValues(t, []uint{1, 3, 6, 7, 10, 14, 30}, bs1.DebugOnesAsSlice(256))
+	require.EqualValues(t, []uint{1, 2, 3, 4, 10, 31, 150}, bs2.DebugOnesAsSlice(256))
+
+	// Destination may be the one of inputs.
+	bs3 := bs1.Clone()
+	bs3.UnionTo(bs3, bs2)
+	require.EqualValues(t, []uint{1, 2, 3, 4, 6, 7, 10, 14, 30, 31, 150}, bs3.DebugOnesAsSlice(256))
+
+	require.True(t, bs1.IsSubsetOf(bs3))
+	require.True(t, bs2.IsSubsetOf(bs3))
+	require.False(t, bs3.IsSubsetOf(bs1))
+	require.True(t, new(ekamath.BitSet).IsSubsetOf(bs1))
+
+	bs3.Difference(bs2).Union(bs1)
+	require.True(t, bs3.Equal(bs1))
+	require.True(t, bs1.Equal(bs3))
+	require.False(t, bs1.Equal(bs2))
+
+	bs3 = bs1.Clone().GrowUnsafeUpTo(512)
+	require.True(t, bs3.Equal(bs1))
+	require.True(t, new(ekamath.BitSet).Equal(nil))
+}
+
 func TestBitSet_CountBetween(t *testing.T) {
 
 	bs1 := ekamath.NewBitSet(32)
@@ -160,6 +209,9 @@ func TestBitSet_CountBetween(t *testing.T) {
 
 	c = bs1.CountBetween(1, 2)
 	require.EqualValues(t, 2, int(c))
+
+	c = ekamath.NewBitSet(64).CountBetween(1, 100)
+	require.EqualValues(t, 0, int(c))
 }
 
 func TestBitSet_CountBetween2(t *testing.T) {
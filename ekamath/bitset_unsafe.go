@@ -0,0 +1,53 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build !ekasafe
+
+package ekamath
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Returns a []byte that has the same bytes that provided BitSet's underlying data.
+//
+// **UNSAFE**
+// Highly unsafe! User MUST NOT modify returned object.
+func bsUnsafeToBytesSlice(bsData []uint) []byte {
+
+	var ret []byte
+
+	shOrig := (*reflect.SliceHeader)(unsafe.Pointer(&bsData))
+	shRet := (*reflect.SliceHeader)(unsafe.Pointer(&ret))
+
+	shRet.Data = shOrig.Data
+	shRet.Len = shOrig.Len * _BITSET_BYTES_PER_CHUNK
+	shRet.Cap = shOrig.Cap * _BITSET_BYTES_PER_CHUNK
+
+	return ret
+}
+
+// Returns a BitSet's underlying data that has the same bytes as provided.
+//
+// **UNSAFE**
+// Highly unsafe! User MUST NOT use `data` object after passing here.
+//
+// WARNING!
+// The length of `data` must be compatible with the bytes consumption
+// of underlying chunk slice.
+func bsUnsafeFromBytesSlice(data []byte) []uint {
+
+	var ret []uint
+
+	shOrig := (*reflect.SliceHeader)(unsafe.Pointer(&data))
+	shRet := (*reflect.SliceHeader)(unsafe.Pointer(&ret))
+
+	shRet.Data = shOrig.Data
+	shRet.Len = shOrig.Len / _BITSET_BYTES_PER_CHUNK
+	shRet.Cap = shOrig.Cap / _BITSET_BYTES_PER_CHUNK
+
+	return ret
+}
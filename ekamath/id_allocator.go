@@ -0,0 +1,210 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekamath
+
+import (
+	"container/heap"
+	"sync"
+)
+
+type (
+	// IDAllocator hands out the smallest free uint IDs from the range [min..max],
+	// e.g. port or slot numbers. It's safe for concurrent use.
+	//
+	// Acquired IDs are stored in the BitSet, released ones are also kept
+	// in the free-list (min heap), so the smallest free ID is found w/o scanning
+	// the whole BitSet. IDs may be reserved (see Reserve()), thus they are
+	// never handed out until they're released explicitly.
+	//
+	// The state may be saved and restored using MarshalBinary(), UnmarshalBinary()
+	// (the BitSet's binary encoding is used).
+	//
+	// Use NewIDAllocator() to create it.
+	IDAllocator struct {
+		mu       sync.Mutex
+		min, max uint
+		bs       BitSet
+		free     idAllocatorFreeList // released IDs < next, may contain acquired ones
+		next     uint                // all IDs >= next are free, except reserved ones
+	}
+
+	// idAllocatorFreeList is a min heap of released IDs.
+	// Implements heap.Interface.
+	idAllocatorFreeList []uint
+)
+
+// NewIDAllocator creates a new IDAllocator, that hands out IDs from the range
+// [min..max]. Since BitSet's index starts from 1, 0 `min` is treated as 1.
+// 0 `max` means there's no upper bound.
+// Panics if `min` > `max`.
+func NewIDAllocator(min, max uint) *IDAllocator {
+
+	if min == 0 {
+		min = 1
+	}
+	if max == 0 {
+		max = _BITSET_MASK_FULL
+	}
+	if min > max {
+		panic("ekamath: NewIDAllocator: min > max")
+	}
+
+	return &IDAllocator{min: min, max: max, next: min}
+}
+
+// Acquire returns the smallest free ID marking it as acquired.
+// Returns false if all IDs of the range are acquired.
+func (a *IDAllocator) Acquire() (uint, bool) {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for a.free.Len() > 0 {
+		if id := heap.Pop(&a.free).(uint); !a.bs.IsSet(id) {
+			a.bs.Up(id)
+			return id, true
+		}
+	}
+
+	id, ok := a.bs.NextDown(a.next - 1)
+	if !ok || id < a.next {
+		// There's no downed bits in the BitSet starting from a.next,
+		// so the first free ID is out of its capacity.
+		id = Max(a.next, a.bs.Capacity()+1)
+	}
+
+	if id > a.max || id == 0 {
+		return 0, false
+	}
+
+	a.bs.Up(id)
+	a.next = id + 1
+
+	return id, true
+}
+
+// Release marks `id` as free, so it may be handed out by Acquire() again.
+// Reports whether `id` was acquired (or reserved) before.
+func (a *IDAllocator) Release(id uint) bool {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if id < a.min || id > a.max || !a.bs.IsSet(id) {
+		return false
+	}
+
+	a.bs.Down(id)
+	if id < a.next {
+		heap.Push(&a.free, id)
+	}
+
+	return true
+}
+
+// Reserve marks all IDs of the range [from..to] (intersected with
+// the IDAllocator's one) as acquired, so they won't be handed out
+// by Acquire() until they are released by Release().
+// Returns the number of IDs that were free before.
+func (a *IDAllocator) Reserve(from, to uint) uint {
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	from, to = Max(from, a.min), Min(to, a.max)
+
+	var n uint
+	for id := from; id <= to; id++ {
+		if !a.bs.IsSet(id) {
+			a.bs.Up(id)
+			n++
+		}
+		if id == to {
+			break // prevent overflow if `to` is max uint
+		}
+	}
+
+	return n
+}
+
+// IsAcquired reports whether `id` is acquired (or reserved).
+func (a *IDAllocator) IsAcquired(id uint) bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.bs.IsSet(id)
+}
+
+// Count returns the number of acquired (and reserved) IDs.
+func (a *IDAllocator) Count() uint {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.bs.Count()
+}
+
+// MarshalBinary implements BinaryMarshaler interface encoding acquired IDs
+// in binary form using BitSet.MarshalBinary().
+// The range of IDAllocator is not encoded.
+func (a *IDAllocator) MarshalBinary() ([]byte, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.bs.Clone().MarshalBinary()
+}
+
+// UnmarshalBinary implements BinaryUnmarshaler interface restoring
+// acquired IDs from `data`, that MUST BE obtained by IDAllocator.MarshalBinary().
+// The current state is overwritten if decoding has been completed successfully.
+// The range of IDAllocator is kept as is.
+//
+// WARNING!
+// User MUST NOT use provided `data` after passing to this method. UB otherwise.
+func (a *IDAllocator) UnmarshalBinary(data []byte) error {
+
+	var bs BitSet
+	if err := bs.UnmarshalBinary(data); err != nil {
+		return err
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.bs = bs
+	a.free = a.free[:0]
+	a.next = a.min
+
+	// Find the highest acquired ID, IDs after it are free.
+	for i := len(a.bs.bs) - 1; i >= 0; i-- {
+		if a.bs.bs[i] != 0 {
+			highest := bsToIdx(uint(i), _BITSET_BITS_PER_CHUNK-bsLastUp(a.bs.bs[i])-1) + 1
+			a.next = Max(highest+1, a.min)
+			break
+		}
+	}
+
+	for id, ok := a.bs.NextDown(a.min - 1); ok && id < a.next; id, ok = a.bs.NextDown(id) {
+		a.free = append(a.free, id)
+	}
+	heap.Init(&a.free)
+
+	return nil
+}
+
+// ---------------------------------------------------------------------------- //
+
+func (fl idAllocatorFreeList) Len() int           { return len(fl) }
+func (fl idAllocatorFreeList) Less(i, j int) bool { return fl[i] < fl[j] }
+func (fl idAllocatorFreeList) Swap(i, j int)      { fl[i], fl[j] = fl[j], fl[i] }
+
+func (fl *idAllocatorFreeList) Push(x any) {
+	*fl = append(*fl, x.(uint))
+}
+
+func (fl *idAllocatorFreeList) Pop() any {
+	old := *fl
+	n := len(old)
+	x := old[n-1]
+	*fl = old[:n-1]
+	return x
+}
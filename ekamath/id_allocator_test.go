@@ -0,0 +1,116 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekamath_test
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekamath"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestIDAllocator(t *testing.T) {
+
+	a := ekamath.NewIDAllocator(10, 200)
+
+	acquire := func() uint {
+		id, ok := a.Acquire()
+		require.True(t, ok)
+		return id
+	}
+
+	require.EqualValues(t, 10, acquire())
+	require.EqualValues(t, 11, acquire())
+
+	require.EqualValues(t, 3, a.Reserve(12, 14))
+	require.EqualValues(t, 1, a.Reserve(14, 15))
+	require.EqualValues(t, 16, acquire())
+
+	require.True(t, a.Release(11))
+	require.True(t, a.Release(13))
+	require.False(t, a.Release(13))
+	require.False(t, a.Release(5))
+
+	require.EqualValues(t, 11, acquire())
+	require.EqualValues(t, 13, acquire())
+	require.EqualValues(t, 17, acquire())
+
+	// Reserved IDs are skipped.
+	a.Reserve(18, 70)
+	require.EqualValues(t, 71, acquire())
+
+	data, err := a.MarshalBinary()
+	require.NoError(t, err)
+
+	a.Release(10)
+	a.Release(71)
+	a.Release(30)
+
+	restored := ekamath.NewIDAllocator(10, 200)
+	require.NoError(t, restored.UnmarshalBinary(data))
+	require.EqualValues(t, 62, restored.Count())
+	require.True(t, restored.IsAcquired(71))
+
+	restored.Release(20)
+	id, ok := restored.Acquire()
+	require.True(t, ok)
+	require.EqualValues(t, 20, id)
+
+	id, ok = restored.Acquire()
+	require.True(t, ok)
+	require.EqualValues(t, 72, id)
+
+	// Exhausting.
+	a = ekamath.NewIDAllocator(1, 128)
+	for i := uint(1); i <= 128; i++ {
+		require.Equal(t, i, acquire())
+	}
+	_, ok = a.Acquire()
+	require.False(t, ok)
+
+	require.True(t, a.Release(128))
+	require.EqualValues(t, 128, acquire())
+}
+
+func TestIDAllocator_Concurrent(t *testing.T) {
+
+	//goland:noinspection GoSnakeCaseUsage
+	const (
+		N_GOROUTINES = 8
+		N_IDS        = 512
+	)
+
+	var (
+		a   = ekamath.NewIDAllocator(0, 0)
+		ids = make([][]uint, N_GOROUTINES)
+		wg  sync.WaitGroup
+	)
+
+	for i := 0; i < N_GOROUTINES; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < N_IDS; j++ {
+				id, _ := a.Acquire()
+				ids[i] = append(ids[i], id)
+			}
+		}(i)
+	}
+
+	wg.Wait()
+
+	seen := make(map[uint]struct{})
+	for i := range ids {
+		for _, id := range ids[i] {
+			seen[id] = struct{}{}
+		}
+	}
+
+	require.Len(t, seen, N_GOROUTINES*N_IDS)
+	require.EqualValues(t, N_GOROUTINES*N_IDS, a.Count())
+}
@@ -0,0 +1,180 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekamath
+
+import (
+	"sort"
+
+	"github.com/qioalice/ekago/v3/ekaext"
+)
+
+type (
+	// Interval is a half-open interval [Start, End) of T values.
+	// Interval is empty if Start >= End.
+	//
+	// It's a value type, use NewInterval() to create a normalized one,
+	// or just instantiate it if you're sure Start <= End.
+	Interval[T ekaext.Ordered] struct {
+		Start, End T
+	}
+
+	// IntervalSet is a normalized set of Interval: sorted by Start,
+	// w/o empty, overlapped or adjacent intervals (they're merged).
+	//
+	// The zero IntervalSet is an empty set, ready-to-use.
+	// IntervalSet is thread-unsafe.
+	IntervalSet[T ekaext.Ordered] struct {
+		ivs []Interval[T]
+	}
+)
+
+// NewInterval returns a new Interval [a, b), swapping 'a' and 'b' if a > b.
+func NewInterval[T ekaext.Ordered](a, b T) Interval[T] {
+	return Interval[T]{Start: Min(a, b), End: Max(a, b)}
+}
+
+// IsEmpty reports whether Interval contains no values (Start >= End).
+func (iv Interval[T]) IsEmpty() bool {
+	return iv.Start >= iv.End
+}
+
+// Contains reports whether 'v' belongs to the Interval: Start <= v < End.
+func (iv Interval[T]) Contains(v T) bool {
+	return iv.Start <= v && v < iv.End
+}
+
+// Overlaps reports whether both of intervals are not empty
+// and have at least one common value.
+func (iv Interval[T]) Overlaps(other Interval[T]) bool {
+	return !iv.IsEmpty() && !other.IsEmpty() &&
+		iv.Start < other.End && other.Start < iv.End
+}
+
+// Intersect returns an Interval of common values of both of intervals.
+// Returns false (and the zero Interval) if they're not overlapped.
+func (iv Interval[T]) Intersect(other Interval[T]) (Interval[T], bool) {
+	if !iv.Overlaps(other) {
+		return Interval[T]{}, false
+	}
+	return Interval[T]{Start: Max(iv.Start, other.Start), End: Min(iv.End, other.End)}, true
+}
+
+// Union returns the union of both of intervals sorted by Start:
+//   - one Interval if they're overlapped or adjacent;
+//   - two intervals otherwise;
+//   - the not empty one, or nil if both of them are empty.
+func (iv Interval[T]) Union(other Interval[T]) []Interval[T] {
+
+	switch {
+	case iv.IsEmpty() && other.IsEmpty():
+		return nil
+	case iv.IsEmpty():
+		return []Interval[T]{other}
+	case other.IsEmpty():
+		return []Interval[T]{iv}
+	}
+
+	if other.Start < iv.Start {
+		iv, other = other, iv
+	}
+
+	if other.Start <= iv.End {
+		return []Interval[T]{{Start: iv.Start, End: Max(iv.End, other.End)}}
+	}
+
+	return []Interval[T]{iv, other}
+}
+
+// NewIntervalSet returns a new IntervalSet with all provided intervals added.
+func NewIntervalSet[T ekaext.Ordered](ivs ...Interval[T]) *IntervalSet[T] {
+	s := new(IntervalSet[T])
+	for i, n := 0, len(ivs); i < n; i++ {
+		s.Add(ivs[i])
+	}
+	return s
+}
+
+// Add adds 'iv' to the IntervalSet, merging it with overlapped and adjacent ones.
+// Empty Interval is ignored. Returns the IntervalSet.
+func (s *IntervalSet[T]) Add(iv Interval[T]) *IntervalSet[T] {
+
+	if iv.IsEmpty() {
+		return s
+	}
+
+	// i is the first Interval that may be merged with 'iv',
+	// j is the first Interval after 'iv' that may not.
+	i := sort.Search(len(s.ivs), func(k int) bool { return s.ivs[k].End >= iv.Start })
+	j := i
+	for j < len(s.ivs) && s.ivs[j].Start <= iv.End {
+		iv.Start = Min(iv.Start, s.ivs[j].Start)
+		iv.End = Max(iv.End, s.ivs[j].End)
+		j++
+	}
+
+	s.ivs = append(s.ivs[:i], append([]Interval[T]{iv}, s.ivs[j:]...)...)
+	return s
+}
+
+// Subtract removes all values of 'iv' from the IntervalSet,
+// splitting intervals if it's necessary. Returns the IntervalSet.
+func (s *IntervalSet[T]) Subtract(iv Interval[T]) *IntervalSet[T] {
+
+	if iv.IsEmpty() || len(s.ivs) == 0 {
+		return s
+	}
+
+	res := make([]Interval[T], 0, len(s.ivs)+1)
+	for _, cur := range s.ivs {
+		if !cur.Overlaps(iv) {
+			res = append(res, cur)
+			continue
+		}
+		if cur.Start < iv.Start {
+			res = append(res, Interval[T]{Start: cur.Start, End: iv.Start})
+		}
+		if iv.End < cur.End {
+			res = append(res, Interval[T]{Start: iv.End, End: cur.End})
+		}
+	}
+
+	s.ivs = res
+	return s
+}
+
+// Merge adds all intervals of 'other' IntervalSet to the current one.
+// Returns the current IntervalSet.
+func (s *IntervalSet[T]) Merge(other *IntervalSet[T]) *IntervalSet[T] {
+	for i, n := 0, other.Len(); i < n; i++ {
+		s.Add(other.ivs[i])
+	}
+	return s
+}
+
+// Contains reports whether 'v' belongs to any Interval of the IntervalSet.
+func (s *IntervalSet[T]) Contains(v T) bool {
+	if s == nil {
+		return false
+	}
+	i := sort.Search(len(s.ivs), func(k int) bool { return s.ivs[k].End > v })
+	return i < len(s.ivs) && s.ivs[i].Contains(v)
+}
+
+// Len returns the number of intervals in the IntervalSet. Nil safe.
+func (s *IntervalSet[T]) Len() int {
+	if s == nil {
+		return 0
+	}
+	return len(s.ivs)
+}
+
+// Intervals returns a copy of IntervalSet's intervals sorted by Start.
+func (s *IntervalSet[T]) Intervals() []Interval[T] {
+	if s.Len() == 0 {
+		return nil
+	}
+	return append([]Interval[T](nil), s.ivs...)
+}
@@ -0,0 +1,61 @@
+package ekamath_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekamath"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInterval(t *testing.T) {
+
+	type I = ekamath.Interval[int]
+
+	iv := ekamath.NewInterval(10, 1)
+	assert.Equal(t, I{1, 10}, iv)
+	assert.True(t, iv.Contains(1))
+	assert.False(t, iv.Contains(10))
+
+	assert.True(t, iv.Overlaps(I{9, 20}))
+	assert.False(t, iv.Overlaps(I{10, 20}))
+	assert.False(t, iv.Overlaps(I{5, 5}))
+
+	x, ok := iv.Intersect(I{5, 20})
+	assert.True(t, ok)
+	assert.Equal(t, I{5, 10}, x)
+
+	_, ok = iv.Intersect(I{10, 20})
+	assert.False(t, ok)
+
+	assert.Equal(t, []I{{1, 20}}, iv.Union(I{10, 20}))
+	assert.Equal(t, []I{{1, 10}, {11, 20}}, I{11, 20}.Union(iv))
+	assert.Equal(t, []I{{1, 10}}, iv.Union(I{3, 3}))
+	assert.Nil(t, I{3, 3}.Union(I{4, 4}))
+}
+
+func TestIntervalSet(t *testing.T) {
+
+	type I = ekamath.Interval[int]
+
+	s := ekamath.NewIntervalSet(I{20, 30}, I{1, 5}, I{10, 15}, I{0, 0})
+	assert.Equal(t, []I{{1, 5}, {10, 15}, {20, 30}}, s.Intervals())
+
+	s.Add(I{5, 10})
+	assert.Equal(t, []I{{1, 15}, {20, 30}}, s.Intervals())
+
+	s.Add(I{14, 25})
+	assert.Equal(t, []I{{1, 30}}, s.Intervals())
+
+	s.Subtract(I{10, 12}).Subtract(I{25, 40})
+	assert.Equal(t, []I{{1, 10}, {12, 25}}, s.Intervals())
+
+	assert.True(t, s.Contains(1))
+	assert.False(t, s.Contains(10))
+	assert.True(t, s.Contains(12))
+	assert.False(t, s.Contains(25))
+
+	s.Merge(ekamath.NewIntervalSet(I{10, 12}, I{40, 50}))
+	assert.Equal(t, []I{{1, 25}, {40, 50}}, s.Intervals())
+	assert.Equal(t, 2, s.Len())
+}
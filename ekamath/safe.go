@@ -0,0 +1,143 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekamath
+
+import (
+	"unsafe"
+
+	"github.com/qioalice/ekago/v3/ekaext"
+)
+
+// SafeConvert converts 'v' of T1 integer type to T2 integer type.
+// Returns false (and the zero T2) if 'v' can't be represented by T2 w/o loss,
+// e.g. if it's out of T2's range or it's negative and T2 is unsigned.
+func SafeConvert[T1, T2 ekaext.Integer](v T1) (T2, bool) {
+	r := T2(v)
+	if T1(r) != v || (v < 0) != (r < 0) {
+		return 0, false
+	}
+	return r, true
+}
+
+// ConvertSaturating is the same as SafeConvert() but returns the closest
+// T2's bound (min or max) if 'v' is out of T2's range.
+func ConvertSaturating[T1, T2 ekaext.Integer](v T1) T2 {
+	if r, ok := SafeConvert[T1, T2](v); ok {
+		return r
+	}
+	if v < 0 {
+		return minOf[T2]()
+	}
+	return maxOf[T2]()
+}
+
+// AddChecked returns a + b. Returns false if the result is overflowed.
+func AddChecked[T ekaext.Integer](a, b T) (T, bool) {
+	c := a + b
+	if (b > 0 && c < a) || (b < 0 && c > a) {
+		return c, false
+	}
+	return c, true
+}
+
+// SubChecked returns a - b. Returns false if the result is overflowed.
+func SubChecked[T ekaext.Integer](a, b T) (T, bool) {
+	c := a - b
+	if (b > 0 && c > a) || (b < 0 && c < a) {
+		return c, false
+	}
+	return c, true
+}
+
+// MulChecked returns a * b. Returns false if the result is overflowed.
+func MulChecked[T ekaext.Integer](a, b T) (T, bool) {
+	if a == 0 || b == 0 {
+		return 0, true
+	}
+	c := a * b
+	// The 2nd condition is for the signed min * -1 case.
+	if c/b != a || ((a < 0) != (b < 0)) != (c < 0) {
+		return c, false
+	}
+	return c, true
+}
+
+// AddSaturating returns a + b or the closest T's bound if the result is overflowed.
+func AddSaturating[T ekaext.Integer](a, b T) T {
+	if c, ok := AddChecked(a, b); ok {
+		return c
+	}
+	if b < 0 {
+		return minOf[T]()
+	}
+	return maxOf[T]()
+}
+
+// SubSaturating returns a - b or the closest T's bound if the result is overflowed.
+func SubSaturating[T ekaext.Integer](a, b T) T {
+	if c, ok := SubChecked(a, b); ok {
+		return c
+	}
+	if b > 0 {
+		return minOf[T]()
+	}
+	return maxOf[T]()
+}
+
+// MulSaturating returns a * b or the closest T's bound if the result is overflowed.
+func MulSaturating[T ekaext.Integer](a, b T) T {
+	if c, ok := MulChecked(a, b); ok {
+		return c
+	}
+	if (a < 0) != (b < 0) {
+		return minOf[T]()
+	}
+	return maxOf[T]()
+}
+
+// ClampToInt8 returns 'v' converted to int8 or the closest int8's bound.
+func ClampToInt8[T ekaext.Integer](v T) int8 { return ConvertSaturating[T, int8](v) }
+
+// ClampToInt16 returns 'v' converted to int16 or the closest int16's bound.
+func ClampToInt16[T ekaext.Integer](v T) int16 { return ConvertSaturating[T, int16](v) }
+
+// ClampToInt32 returns 'v' converted to int32 or the closest int32's bound.
+func ClampToInt32[T ekaext.Integer](v T) int32 { return ConvertSaturating[T, int32](v) }
+
+// ClampToInt64 returns 'v' converted to int64 or the closest int64's bound.
+func ClampToInt64[T ekaext.Integer](v T) int64 { return ConvertSaturating[T, int64](v) }
+
+// ClampToInt returns 'v' converted to int or the closest int's bound.
+func ClampToInt[T ekaext.Integer](v T) int { return ConvertSaturating[T, int](v) }
+
+// ClampToUint8 returns 'v' converted to uint8 or the closest uint8's bound.
+func ClampToUint8[T ekaext.Integer](v T) uint8 { return ConvertSaturating[T, uint8](v) }
+
+// ClampToUint16 returns 'v' converted to uint16 or the closest uint16's bound.
+func ClampToUint16[T ekaext.Integer](v T) uint16 { return ConvertSaturating[T, uint16](v) }
+
+// ClampToUint32 returns 'v' converted to uint32 or the closest uint32's bound.
+func ClampToUint32[T ekaext.Integer](v T) uint32 { return ConvertSaturating[T, uint32](v) }
+
+// ClampToUint64 returns 'v' converted to uint64 or the closest uint64's bound.
+func ClampToUint64[T ekaext.Integer](v T) uint64 { return ConvertSaturating[T, uint64](v) }
+
+// ClampToUint returns 'v' converted to uint or the closest uint's bound.
+func ClampToUint[T ekaext.Integer](v T) uint { return ConvertSaturating[T, uint](v) }
+
+// minOf returns the min value of T integer type.
+func minOf[T ekaext.Integer]() T {
+	var zero T
+	if zero-1 > 0 {
+		return 0 // unsigned
+	}
+	return T(1) << (unsafe.Sizeof(zero)*8 - 1)
+}
+
+// maxOf returns the max value of T integer type.
+func maxOf[T ekaext.Integer]() T {
+	return ^minOf[T]()
+}
@@ -0,0 +1,63 @@
+package ekamath_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekamath"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSafeConvert(t *testing.T) {
+
+	v32, ok := ekamath.SafeConvert[int64, int32](math.MaxInt32)
+	assert.True(t, ok)
+	assert.EqualValues(t, math.MaxInt32, v32)
+
+	_, ok = ekamath.SafeConvert[int64, int32](math.MaxInt32 + 1)
+	assert.False(t, ok)
+
+	_, ok = ekamath.SafeConvert[int8, uint64](-1)
+	assert.False(t, ok)
+
+	_, ok = ekamath.SafeConvert[uint64, int64](math.MaxUint64)
+	assert.False(t, ok)
+
+	assert.EqualValues(t, math.MaxInt32, ekamath.ClampToInt32(int64(math.MaxInt64)))
+	assert.EqualValues(t, math.MinInt32, ekamath.ClampToInt32(int64(math.MinInt64)))
+	assert.EqualValues(t, 0, ekamath.ClampToUint8(-5))
+	assert.EqualValues(t, math.MaxUint8, ekamath.ClampToUint8(300))
+	assert.EqualValues(t, 100, ekamath.ClampToInt8(uint64(100)))
+}
+
+func TestChecked(t *testing.T) {
+
+	_, ok := ekamath.AddChecked[int8](100, 27)
+	assert.True(t, ok)
+	_, ok = ekamath.AddChecked[int8](100, 28)
+	assert.False(t, ok)
+	_, ok = ekamath.AddChecked[int8](-100, -29)
+	assert.False(t, ok)
+	_, ok = ekamath.AddChecked[uint8](200, 56)
+	assert.False(t, ok)
+
+	_, ok = ekamath.SubChecked[uint8](1, 2)
+	assert.False(t, ok)
+	_, ok = ekamath.SubChecked[int8](-100, 29)
+	assert.False(t, ok)
+
+	v, ok := ekamath.MulChecked[int16](-128, 256)
+	assert.True(t, ok)
+	assert.EqualValues(t, math.MinInt16, v)
+	_, ok = ekamath.MulChecked[int64](math.MinInt64, -1)
+	assert.False(t, ok)
+	_, ok = ekamath.MulChecked[uint32](1<<16, 1<<16)
+	assert.False(t, ok)
+
+	assert.EqualValues(t, math.MaxInt8, ekamath.AddSaturating[int8](100, 100))
+	assert.EqualValues(t, math.MinInt8, ekamath.SubSaturating[int8](-100, 100))
+	assert.EqualValues(t, 0, ekamath.SubSaturating[uint](1, 2))
+	assert.EqualValues(t, math.MinInt32, ekamath.MulSaturating[int32](math.MaxInt32, -2))
+	assert.EqualValues(t, uint64(math.MaxUint64), ekamath.MulSaturating[uint64](math.MaxUint64, 2))
+}
@@ -0,0 +1,219 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekastr
+
+import (
+	"container/list"
+	"sync"
+)
+
+type (
+	// CaseCache is a thread-safe LRU cache of the results of case conversion
+	// function (like ToSnakeCase()). It's useful when the same small set
+	// of strings (e.g. log fields' keys) is converted again and again.
+	//
+	// Use NewCaseCache() to create it.
+	CaseCache struct {
+		mu       sync.Mutex
+		conv     func(string) string
+		capacity int
+		items    map[string]*list.Element
+		lru      *list.List // front is the most recently used
+	}
+
+	// caseCacheItem is an element of CaseCache's LRU list.
+	caseCacheItem struct {
+		from, to string
+	}
+)
+
+// CASE_CACHE_DEFAULT_CAPACITY is a default number of converted strings
+// CaseCache keeps if a non-positive capacity is passed.
+//
+//goland:noinspection GoSnakeCaseUsage
+const CASE_CACHE_DEFAULT_CAPACITY = 256
+
+/*
+ToSnakeCase converts 's' to the snake case: "userID", "UserId", "user-id"
+become "user_id".
+
+Words are split by '_', '-', whitespaces and by the case changes
+("HTTPServer" is "HTTP" and "Server"). Other chars (like '.') are kept as is
+as a part of the word. Only ASCII letters are converted.
+Doesn't allocate if 's' is in snake case already.
+*/
+func ToSnakeCase(s string) string {
+	return caseConvert(s, '_', caseLower, caseLower)
+}
+
+/*
+ToScreamingSnakeCase converts 's' to the screaming snake case: "userID",
+"UserId", "user-id" become "USER_ID". Read more about words: ToSnakeCase().
+Doesn't allocate if 's' is in screaming snake case already.
+*/
+func ToScreamingSnakeCase(s string) string {
+	return caseConvert(s, '_', caseUpper, caseUpper)
+}
+
+/*
+ToKebabCase converts 's' to the kebab case: "userID", "UserId", "user_id"
+become "user-id". Read more about words: ToSnakeCase().
+Doesn't allocate if 's' is in kebab case already.
+*/
+func ToKebabCase(s string) string {
+	return caseConvert(s, '-', caseLower, caseLower)
+}
+
+/*
+ToCamelCase converts 's' to the lower camel case: "user_id", "UserID",
+"user-id" become "userId". Read more about words: ToSnakeCase().
+Doesn't allocate if 's' is in camel case already.
+*/
+func ToCamelCase(s string) string {
+	return caseConvert(s, 0, caseLower, caseTitle)
+}
+
+// NewCaseCache returns a new CaseCache of the results of 'conv',
+// that keeps at most 'capacity' strings.
+// If 'capacity' is non-positive, CASE_CACHE_DEFAULT_CAPACITY is used.
+// Panics if 'conv' is nil.
+func NewCaseCache(capacity int, conv func(string) string) *CaseCache {
+
+	if conv == nil {
+		panic("ekastr: NewCaseCache: conversion function is nil")
+	}
+	if capacity <= 0 {
+		capacity = CASE_CACHE_DEFAULT_CAPACITY
+	}
+
+	return &CaseCache{
+		conv:     conv,
+		capacity: capacity,
+		items:    make(map[string]*list.Element, capacity),
+		lru:      list.New(),
+	}
+}
+
+// Convert returns the converted 's' taking it from the cache if it's there,
+// or calling the conversion function and caching its result otherwise.
+func (c *CaseCache) Convert(s string) string {
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.items[s]; ok {
+		c.lru.MoveToFront(elem)
+		return elem.Value.(*caseCacheItem).to
+	}
+
+	to := c.conv(s)
+
+	if c.lru.Len() >= c.capacity {
+		oldest := c.lru.Back()
+		delete(c.items, oldest.Value.(*caseCacheItem).from)
+		c.lru.Remove(oldest)
+	}
+	c.items[s] = c.lru.PushFront(&caseCacheItem{from: s, to: to})
+
+	return to
+}
+
+// Len returns the number of cached strings.
+func (c *CaseCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+const (
+	caseLower = iota
+	caseUpper
+	caseTitle
+)
+
+// caseConvert converts each word of 's' using 'firstMode' for the first word
+// and 'restMode' for next ones, joining them by 'sep' (w/o separator if it's 0).
+// Returns 's' itself if it's not changed.
+func caseConvert(s string, sep byte, firstMode, restMode int) string {
+
+	var stackBuf [64]byte
+	b := stackBuf[:0]
+	if len(s) > len(stackBuf) {
+		b = make([]byte, 0, len(s)+len(s)/4)
+	}
+
+	isFirst := true
+	caseSplitWords(s, func(word string) {
+		mode := restMode
+		if isFirst {
+			mode = firstMode
+		} else if sep != 0 {
+			b = append(b, sep)
+		}
+		isFirst = false
+
+		for i := 0; i < len(word); i++ {
+			c := word[i]
+			switch {
+			case mode == caseUpper || mode == caseTitle && i == 0:
+				if CharIsLowerCaseLetter(c) {
+					c -= 'a' - 'A'
+				}
+			default:
+				if CharIsUpperCaseLetter(c) {
+					c += 'a' - 'A'
+				}
+			}
+			b = append(b, c)
+		}
+	})
+
+	if string(b) == s {
+		return s
+	}
+	return string(b)
+}
+
+// caseSplitWords calls 'cb' for each word of 's'.
+// Read more about words: ToSnakeCase().
+func caseSplitWords(s string, cb func(word string)) {
+
+	isSep := func(c byte) bool {
+		return c == '_' || c == '-' || c <= ' '
+	}
+
+	start := -1
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+
+		if isSep(c) {
+			if start != -1 {
+				cb(s[start:i])
+				start = -1
+			}
+			continue
+		}
+
+		if start == -1 {
+			start = i
+			continue
+		}
+
+		// Case change is a word boundary: "userId" -> "user", "Id",
+		// "HTTPServer" -> "HTTP", "Server".
+		prev := s[i-1]
+		if CharIsUpperCaseLetter(c) && (CharIsLowerCaseLetter(prev) || CharIsNumber(prev) ||
+			CharIsUpperCaseLetter(prev) && i+1 < len(s) && CharIsLowerCaseLetter(s[i+1])) {
+
+			cb(s[start:i])
+			start = i
+		}
+	}
+
+	if start != -1 {
+		cb(s[start:])
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekastr_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekastr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaseConversion(t *testing.T) {
+
+	tests := []struct {
+		in, snake, screaming, kebab, camel string
+	}{
+		{"userId", "user_id", "USER_ID", "user-id", "userId"},
+		{"UserID", "user_id", "USER_ID", "user-id", "userId"},
+		{"user-id", "user_id", "USER_ID", "user-id", "userId"},
+		{"HTTPServerError", "http_server_error", "HTTP_SERVER_ERROR", "http-server-error", "httpServerError"},
+		{"  request__ID  ", "request_id", "REQUEST_ID", "request-id", "requestId"},
+		{"http.requestMethod", "http.request_method", "HTTP.REQUEST_METHOD", "http.request-method", "http.requestMethod"},
+		{"user2Name", "user2_name", "USER2_NAME", "user2-name", "user2Name"},
+		{"", "", "", "", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.snake, ekastr.ToSnakeCase(test.in), test.in)
+		assert.Equal(t, test.screaming, ekastr.ToScreamingSnakeCase(test.in), test.in)
+		assert.Equal(t, test.kebab, ekastr.ToKebabCase(test.in), test.in)
+		assert.Equal(t, test.camel, ekastr.ToCamelCase(test.in), test.in)
+	}
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_ = ekastr.ToSnakeCase("already_snake_case")
+	}))
+}
+
+func TestCaseCache(t *testing.T) {
+
+	calls := 0
+	c := ekastr.NewCaseCache(2, func(s string) string {
+		calls++
+		return ekastr.ToSnakeCase(s)
+	})
+
+	assert.Equal(t, "user_id", c.Convert("userId"))
+	assert.Equal(t, "user_id", c.Convert("userId"))
+	assert.Equal(t, 1, calls)
+
+	c.Convert("a")
+	c.Convert("userId") // "a" is the least recently used now
+	c.Convert("b")      // "a" is evicted
+
+	assert.Equal(t, 2, c.Len())
+	assert.Equal(t, 3, calls)
+
+	c.Convert("userId")
+	assert.Equal(t, 3, calls)
+	c.Convert("a")
+	assert.Equal(t, 4, calls)
+}
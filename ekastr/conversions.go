@@ -21,6 +21,8 @@
 // ARISING FROM, OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE
 // OR OTHER DEALINGS IN THE SOFTWARE.
 
+//go:build !ekasafe
+
 package ekastr
 
 // From:
@@ -0,0 +1,31 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build ekasafe
+
+package ekastr
+
+/*
+B2S converts byte slice to a string.
+
+It's a safe implementation that is used when the "ekasafe" build tag is set.
+It copies the bytes, so the string never aliases the provided byte slice.
+*/
+func B2S(b []byte) string {
+	return string(b)
+}
+
+/*
+S2B converts string to a byte slice.
+
+It's a safe implementation that is used when the "ekasafe" build tag is set.
+It copies the bytes, so the returned slice may be modified freely.
+*/
+func S2B(s string) []byte {
+	if s == "" {
+		return nil
+	}
+	return []byte(s)
+}
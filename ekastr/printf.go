@@ -0,0 +1,154 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekastr
+
+import (
+	"fmt"
+	"strconv"
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// _PRINTF_STACK_BUF_SIZE is a size of the stack buffer Sprintf() builds
+	// a string in. Longer strings are built in the heap.
+	_PRINTF_STACK_BUF_SIZE = 256
+)
+
+// Sprintf is the same as fmt.Sprintf(), but it's faster and allocates less
+// for the most common case: 'format' has only %s, %v, %d, %t, %q verbs
+// without flags, width and precision, and 'args' are only
+// of the builtin primitive types (strings, bools, integers, floats).
+// Falls back to fmt.Sprintf() otherwise. The result is always the same.
+func Sprintf(format string, args ...any) string {
+
+	var buf [_PRINTF_STACK_BUF_SIZE]byte
+	if b, ok := AppendPrintf(buf[:0], format, args); ok {
+		return string(b)
+	}
+
+	return fmt.Sprintf(format, args...)
+}
+
+// AppendPrintf appends 'format' interpolated by 'args' to 'to'
+// the same way as fmt.Sprintf() does and returns an extended buffer.
+//
+// Returns false (and 'to' may contain garbage) if 'format' or 'args'
+// are not supported (read more: Sprintf()). Use fmt then.
+// Mismatched number of verbs and 'args' is not supported too.
+func AppendPrintf(to []byte, format string, args []any) ([]byte, bool) {
+
+	argIdx := 0
+
+	for i := 0; i < len(format); i++ {
+
+		c := format[i]
+		if c != '%' {
+			to = append(to, c)
+			continue
+		}
+
+		i++
+		if i == len(format) {
+			return to, false
+		}
+
+		verb := format[i]
+		if verb == '%' {
+			to = append(to, '%')
+			continue
+		}
+
+		if argIdx == len(args) {
+			return to, false
+		}
+
+		var ok bool
+		if to, ok = printfAppendArg(to, verb, args[argIdx]); !ok {
+			return to, false
+		}
+
+		argIdx++
+	}
+
+	return to, argIdx == len(args)
+}
+
+// printfAppendArg appends 'arg' formatted by 'verb' to 'to'.
+// Returns false if 'verb' or type of 'arg' is not supported.
+func printfAppendArg(to []byte, verb byte, arg any) ([]byte, bool) {
+
+	// Only the exact builtin types are handled here. The named ones
+	// may implement fmt.Stringer, error or fmt.Formatter interfaces.
+
+	switch verb {
+
+	case 's', 'v', 'q':
+		if s, ok := arg.(string); ok {
+			if verb == 'q' {
+				return strconv.AppendQuote(to, s), true
+			}
+			return append(to, s...), true
+		}
+		if verb != 'v' {
+			return to, false
+		}
+
+	case 't':
+		if b, ok := arg.(bool); ok {
+			return strconv.AppendBool(to, b), true
+		}
+		return to, false
+
+	case 'd':
+	default:
+		return to, false
+	}
+
+	// Only %v, %d are left. %v also handles bool and floats.
+
+	switch v := arg.(type) {
+
+	case int:
+		return strconv.AppendInt(to, int64(v), 10), true
+	case int8:
+		return strconv.AppendInt(to, int64(v), 10), true
+	case int16:
+		return strconv.AppendInt(to, int64(v), 10), true
+	case int32:
+		return strconv.AppendInt(to, int64(v), 10), true
+	case int64:
+		return strconv.AppendInt(to, v, 10), true
+
+	case uint:
+		return strconv.AppendUint(to, uint64(v), 10), true
+	case uint8:
+		return strconv.AppendUint(to, uint64(v), 10), true
+	case uint16:
+		return strconv.AppendUint(to, uint64(v), 10), true
+	case uint32:
+		return strconv.AppendUint(to, uint64(v), 10), true
+	case uint64:
+		return strconv.AppendUint(to, v, 10), true
+	case uintptr:
+		return strconv.AppendUint(to, uint64(v), 10), true
+	}
+
+	if verb != 'v' {
+		return to, false
+	}
+
+	switch v := arg.(type) {
+
+	case bool:
+		return strconv.AppendBool(to, v), true
+	case float32:
+		return strconv.AppendFloat(to, float64(v), 'g', -1, 32), true
+	case float64:
+		return strconv.AppendFloat(to, v, 'g', -1, 64), true
+	}
+
+	return to, false
+}
@@ -0,0 +1,85 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekastr_test
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekastr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type tPrintfNamedInt int
+
+func (v tPrintfNamedInt) String() string { return "named" }
+
+func TestSprintf(t *testing.T) {
+
+	tests := []struct {
+		format string
+		args   []any
+		isFast bool
+	}{
+		{"", nil, true},
+		{"no verbs", nil, true},
+		{"100%%", nil, true},
+		{"%s and %v", []any{"str", "ing"}, true},
+		{"%q", []any{"quo\"ted\n"}, true},
+		{"%t %v", []any{true, false}, true},
+		{"%d %d %d %d %d", []any{-1, int8(-8), int16(16), int32(-32), int64(math.MinInt64)}, true},
+		{"%d %v %d %d %d", []any{uint(1), uint8(8), uint16(16), uint32(32), uint64(math.MaxUint64)}, true},
+		{"%v %v %v %v", []any{1.5, float32(0.1), 1e21, 1e-7}, true},
+		{"%v %v %v", []any{math.Inf(1), math.Inf(-1), math.NaN()}, true},
+		{"юникод %s", []any{"строка"}, true},
+
+		{"%s", []any{42}, false},
+		{"%d", []any{"str"}, false},
+		{"%d", []any{1.5}, false},
+		{"%t", []any{1}, false},
+		{"%5d", []any{1}, false},
+		{"%x", []any{255}, false},
+		{"%v", []any{tPrintfNamedInt(1)}, false},
+		{"%v", []any{errors.New("err")}, false},
+		{"%v", []any{time.Second}, false},
+		{"%v", []any{[]int{1, 2}}, false},
+		{"%v", []any{nil}, false},
+		{"%d %d", []any{1}, false},
+		{"%d", []any{1, 2}, false},
+		{"trailing %", nil, false},
+	}
+
+	for _, test := range tests {
+		expected := fmt.Sprintf(test.format, test.args...)
+		assert.Equal(t, expected, ekastr.Sprintf(test.format, test.args...), test.format)
+
+		b, ok := ekastr.AppendPrintf(nil, test.format, test.args)
+		assert.Equal(t, test.isFast, ok, test.format)
+		if ok {
+			assert.Equal(t, expected, string(b), test.format)
+		}
+	}
+}
+
+func BenchmarkSprintf(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = ekastr.Sprintf("user %s has logged in %d times, success: %t", "admin", i, true)
+	}
+}
+
+func BenchmarkSprintf_Fmt(b *testing.B) {
+	b.ReportAllocs()
+
+	for i := 0; i < b.N; i++ {
+		_ = fmt.Sprintf("user %s has logged in %d times, success: %t", "admin", i, true)
+	}
+}
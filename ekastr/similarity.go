@@ -0,0 +1,225 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekastr
+
+import (
+	"unicode/utf8"
+)
+
+// JARO_WINKLER_PREFIX_SCALE is a scaling factor of the common prefix
+// of Jaro-Winkler similarity (see JaroWinkler()).
+//
+//goland:noinspection GoSnakeCaseUsage
+const JARO_WINKLER_PREFIX_SCALE = 0.1
+
+/*
+Levenshtein returns the Levenshtein (edit) distance between 'a' and 'b':
+the minimum number of single char insertions, deletions and substitutions
+required to change 'a' into 'b'. Chars are runes.
+
+Doesn't allocate if both of 'a' and 'b' are ASCII and 'b' is shorter
+than 64 bytes.
+*/
+func Levenshtein(a, b string) int {
+	if similarityIsASCII(a) && similarityIsASCII(b) {
+		return levenshtein(S2B(a), S2B(b))
+	}
+	return levenshtein([]rune(a), []rune(b))
+}
+
+/*
+JaroWinkler returns the Jaro-Winkler similarity between 'a' and 'b',
+in the range [0..1], where 1 means equal strings and 0 means there's
+no similarity at all. Chars are runes.
+
+Unlike Levenshtein(), it favors strings that have the same prefix,
+thus it's better for short strings, like names or keys.
+
+Doesn't allocate if both of 'a' and 'b' are ASCII and shorter than 64 bytes.
+*/
+func JaroWinkler(a, b string) float64 {
+	if similarityIsASCII(a) && similarityIsASCII(b) {
+		return jaroWinkler(S2B(a), S2B(b))
+	}
+	return jaroWinkler([]rune(a), []rune(b))
+}
+
+/*
+SuggestClosest returns the candidate, that is the closest to 'input'
+(e.g. for "did you mean ...?" suggestions or config keys validation).
+Returns false if there's no candidate, that is close enough.
+
+The candidate with the minimum Levenshtein() distance is chosen,
+if that distance is not greater than the third of 'input' length (at least 1).
+If there are many such candidates, the one with the greatest JaroWinkler()
+similarity wins, then the first one.
+*/
+func SuggestClosest(input string, candidates []string) (string, bool) {
+
+	maxDistance := utf8.RuneCountInString(input) / 3
+	if maxDistance < 1 {
+		maxDistance = 1
+	}
+
+	var (
+		best           = -1
+		bestDistance   = maxDistance + 1
+		bestSimilarity float64
+	)
+
+	for i, candidate := range candidates {
+		distance := Levenshtein(input, candidate)
+		if distance > bestDistance {
+			continue
+		}
+		similarity := JaroWinkler(input, candidate)
+		if distance < bestDistance || similarity > bestSimilarity {
+			best, bestDistance, bestSimilarity = i, distance, similarity
+		}
+	}
+
+	if best == -1 {
+		return "", false
+	}
+	return candidates[best], true
+}
+
+// similarityIsASCII reports whether 's' contains only ASCII chars.
+func similarityIsASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// levenshtein is Levenshtein() implementation for bytes or runes.
+func levenshtein[T byte | rune](a, b []T) int {
+
+	switch {
+	case len(a) == 0:
+		return len(b)
+	case len(b) == 0:
+		return len(a)
+	}
+
+	// Only one row of the distances matrix is kept.
+	var (
+		rowBuf [64]int
+		row    []int
+	)
+	if len(b)+1 > len(rowBuf) {
+		row = make([]int, len(b)+1)
+	} else {
+		row = rowBuf[:len(b)+1]
+	}
+
+	for j := range row {
+		row[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		prevDiag := row[0]
+		row[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			prevDiag, row[j] = row[j], similarityMin3(row[j]+1, row[j-1]+1, prevDiag+cost)
+		}
+	}
+
+	return row[len(b)]
+}
+
+// jaroWinkler is JaroWinkler() implementation for bytes or runes.
+func jaroWinkler[T byte | rune](a, b []T) float64 {
+
+	switch {
+	case len(a) == 0 && len(b) == 0:
+		return 1
+	case len(a) == 0 || len(b) == 0:
+		return 0
+	}
+
+	window := len(a)
+	if len(b) > window {
+		window = len(b)
+	}
+	if window = window/2 - 1; window < 0 {
+		window = 0
+	}
+
+	var (
+		matchedBuf [128]bool
+		matched    []bool
+	)
+	if len(a)+len(b) > len(matchedBuf) {
+		matched = make([]bool, len(a)+len(b))
+	} else {
+		matched = matchedBuf[:len(a)+len(b)]
+	}
+	matchedA, matchedB := matched[:len(a)], matched[len(a):]
+
+	matches := 0
+	for i := range a {
+		from, to := i-window, i+window+1
+		if from < 0 {
+			from = 0
+		}
+		if to > len(b) {
+			to = len(b)
+		}
+		for j := from; j < to; j++ {
+			if !matchedB[j] && a[i] == b[j] {
+				matchedA[i], matchedB[j] = true, true
+				matches++
+				break
+			}
+		}
+	}
+
+	if matches == 0 {
+		return 0
+	}
+
+	transpositions := 0
+	for i, j := 0, 0; i < len(a); i++ {
+		if !matchedA[i] {
+			continue
+		}
+		for !matchedB[j] {
+			j++
+		}
+		if a[i] != b[j] {
+			transpositions++
+		}
+		j++
+	}
+
+	m := float64(matches)
+	jaro := (m/float64(len(a)) + m/float64(len(b)) + (m-float64(transpositions/2))/m) / 3
+
+	prefix := 0
+	for prefix < 4 && prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+
+	return jaro + float64(prefix)*JARO_WINKLER_PREFIX_SCALE*(1-jaro)
+}
+
+// similarityMin3 returns the minimum of 'a', 'b', 'c'.
+func similarityMin3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
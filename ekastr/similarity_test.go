@@ -0,0 +1,76 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekastr_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekastr"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLevenshtein(t *testing.T) {
+
+	tests := []struct {
+		a, b     string
+		distance int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"", "abc", 3},
+		{"kitten", "sitting", 3},
+		{"flaw", "lawn", 2},
+		{"same", "same", 0},
+		{"привет", "привед", 1},
+		{"日本語", "日本", 1},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.distance, ekastr.Levenshtein(test.a, test.b), test.a+" "+test.b)
+		assert.Equal(t, test.distance, ekastr.Levenshtein(test.b, test.a), test.b+" "+test.a)
+	}
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_ = ekastr.Levenshtein("kitten", "sitting")
+	}))
+}
+
+func TestJaroWinkler(t *testing.T) {
+
+	assert.Equal(t, 1.0, ekastr.JaroWinkler("", ""))
+	assert.Equal(t, 1.0, ekastr.JaroWinkler("same", "same"))
+	assert.Equal(t, 0.0, ekastr.JaroWinkler("abc", "xyz"))
+	assert.Equal(t, 0.0, ekastr.JaroWinkler("abc", ""))
+
+	assert.InDelta(t, 0.961, ekastr.JaroWinkler("MARTHA", "MARHTA"), 0.001)
+	assert.InDelta(t, 0.840, ekastr.JaroWinkler("DWAYNE", "DUANE"), 0.001)
+	assert.InDelta(t, 0.813, ekastr.JaroWinkler("DIXON", "DICKSONX"), 0.001)
+	assert.InDelta(t, 0.961, ekastr.JaroWinkler("МАРФА", "МАРАФ"), 0.05)
+
+	assert.Zero(t, testing.AllocsPerRun(100, func() {
+		_ = ekastr.JaroWinkler("MARTHA", "MARHTA")
+	}))
+}
+
+func TestSuggestClosest(t *testing.T) {
+
+	candidates := []string{"listen_addr", "log_level", "log_format", "timeout"}
+
+	suggested, ok := ekastr.SuggestClosest("log_levle", candidates)
+	assert.True(t, ok)
+	assert.Equal(t, "log_level", suggested)
+
+	suggested, ok = ekastr.SuggestClosest("timeuot", candidates)
+	assert.True(t, ok)
+	assert.Equal(t, "timeout", suggested)
+
+	_, ok = ekastr.SuggestClosest("database_url", candidates)
+	assert.False(t, ok)
+
+	_, ok = ekastr.SuggestClosest("x", nil)
+	assert.False(t, ok)
+}
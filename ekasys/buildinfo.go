@@ -0,0 +1,96 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys
+
+import (
+	"os"
+	"runtime"
+	"runtime/debug"
+	"sync"
+	"time"
+)
+
+type (
+	// BuildEnvironment is a snapshot of the current binary's build information
+	// and the environment it's running in. Read more: BuildInfo().
+	BuildEnvironment struct {
+
+		// ModulePath and ModuleVersion are the path and the version of the main module.
+		// ModuleVersion is "(devel)" if the binary is built from the module's tree.
+		// Both are empty if the binary is built w/o module support.
+		ModulePath    string
+		ModuleVersion string
+
+		// VCSRevision, VCSTime and VCSModified are the revision, the commit time
+		// and whether the working tree has been modified at the build time.
+		// Zero values if the binary is built w/o VCS stamping (e.g. "go run", "-buildvcs=false").
+		VCSRevision string
+		VCSTime     time.Time
+		VCSModified bool
+
+		GoVersion string // Golang version the binary is built with, like "go1.18.3"
+		GOOS      string // operating system target, like "linux"
+		GOARCH    string // architecture target, like "amd64"
+
+		// Hostname is the host name reported by the kernel. Empty if it's failed.
+		Hostname string
+
+		// ContainerID is the ID of the container the process is running in,
+		// parsed from /proc/self/cgroup (or /proc/self/mountinfo).
+		// Empty if it's not a container, it's not Linux or it's failed.
+		ContainerID string
+	}
+)
+
+var (
+	buildEnvironment     BuildEnvironment
+	buildEnvironmentOnce sync.Once
+)
+
+// BuildInfo returns a snapshot of the current binary's build information
+// (main module's version, VCS revision and time, Golang version, GOOS/GOARCH)
+// and the environment (hostname, container ID).
+//
+// It's gathered once at the first call, all next calls return the same data.
+// It's what most of the services log at the startup,
+// see ekalog.PreEncodeBuildInfo() to attach it to each log Entry.
+func BuildInfo() BuildEnvironment {
+	buildEnvironmentOnce.Do(initBuildEnvironment)
+	return buildEnvironment
+}
+
+// initBuildEnvironment initializes buildEnvironment.
+func initBuildEnvironment() {
+
+	be := BuildEnvironment{
+		GoVersion: runtime.Version(),
+		GOOS:      runtime.GOOS,
+		GOARCH:    runtime.GOARCH,
+	}
+
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		be.ModulePath = bi.Main.Path
+		be.ModuleVersion = bi.Main.Version
+		if bi.GoVersion != "" {
+			be.GoVersion = bi.GoVersion
+		}
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				be.VCSRevision = setting.Value
+			case "vcs.time":
+				be.VCSTime, _ = time.Parse(time.RFC3339, setting.Value)
+			case "vcs.modified":
+				be.VCSModified = setting.Value == "true"
+			}
+		}
+	}
+
+	be.Hostname, _ = os.Hostname()
+	be.ContainerID = getContainerID()
+
+	buildEnvironment = be
+}
@@ -0,0 +1,81 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys
+
+import (
+	"bufio"
+	"io"
+	"os"
+	"runtime"
+	"strings"
+)
+
+// getContainerID returns the ID of the container the process is running in
+// or an empty string. Read more: BuildEnvironment.ContainerID.
+func getContainerID() string {
+
+	if runtime.GOOS != "linux" {
+		return ""
+	}
+
+	// cgroup v1 contains the container ID in the paths,
+	// cgroup v2 may not (just "0::/"), but the mount points may.
+	for _, path := range [...]string{"/proc/self/cgroup", "/proc/self/mountinfo"} {
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+		id := parseContainerID(f)
+		_ = f.Close()
+		if id != "" {
+			return id
+		}
+	}
+
+	return ""
+}
+
+// parseContainerID returns the first 64 hex digits long path's segment
+// of the lines read from 'r' (cgroup or mountinfo), that is a container ID
+// of Docker, containerd, CRI-O, Podman, etc. Prefixes like "docker-", "crio-",
+// "cri-containerd-", "libpod-" and suffix ".scope" are skipped.
+// Returns an empty string if there's no such segment.
+func parseContainerID(r io.Reader) string {
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		for _, segment := range strings.FieldsFunc(scanner.Text(), isContainerIDSeparator) {
+			segment = strings.TrimSuffix(segment, ".scope")
+			if i := strings.LastIndexByte(segment, '-'); i != -1 {
+				segment = segment[i+1:]
+			}
+			if isContainerID(segment) {
+				return segment
+			}
+		}
+	}
+
+	return ""
+}
+
+// isContainerIDSeparator reports whether 'r' separates
+// the segments of cgroup or mountinfo line.
+func isContainerIDSeparator(r rune) bool {
+	return r == '/' || r == ':' || r == ' '
+}
+
+// isContainerID reports whether 's' consists of 64 lowercase hex digits.
+func isContainerID(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if c := s[i]; (c < '0' || c > '9') && (c < 'a' || c > 'f') {
+			return false
+		}
+	}
+	return true
+}
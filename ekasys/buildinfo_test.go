@@ -0,0 +1,52 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys
+
+import (
+	"os"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildInfo(t *testing.T) {
+
+	be := BuildInfo()
+	assert.NotEmpty(t, be.GoVersion)
+	assert.Equal(t, runtime.GOOS, be.GOOS)
+	assert.Equal(t, runtime.GOARCH, be.GOARCH)
+
+	if hostname, err := os.Hostname(); err == nil {
+		assert.Equal(t, hostname, be.Hostname)
+	}
+
+	assert.Equal(t, be, BuildInfo())
+}
+
+func TestParseContainerID(t *testing.T) {
+
+	const id = "3f4c1a2b5d6e7f8091a2b3c4d5e6f708192a3b4c5d6e7f8091a2b3c4d5e6f708"
+
+	tests := []struct {
+		in       string
+		expected string
+	}{
+		{"12:memory:/docker/" + id + "\n", id},
+		{"0::/system.slice/docker-" + id + ".scope\n", id},
+		{"1:name=systemd:/kubepods/burstable/pod1234/crio-" + id + ".scope\n", id},
+		{"0::/kubepods.slice/cri-containerd-" + id + ".scope\n", id},
+		{"2:cpu:/user.slice\n0::/init.scope\n", ""},
+		{"0::/\n", ""},
+		{"1234 25 0:45 /docker/containers/" + id + "/hostname /etc/hostname rw - ext4 rw\n", id},
+		{"12:memory:/docker/" + strings.ToUpper(id) + "\n", ""},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, parseContainerID(strings.NewReader(test.in)), test.in)
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys
+
+import (
+	"runtime"
+)
+
+// GoroutineID returns the ID of the current goroutine or 0 if it can't be got.
+//
+// The ID is parsed from the header of the current goroutine's stacktrace
+// ("goroutine <ID> [running]:"), so it's quite slow (~1µs)
+// and shall not be used in the hot paths.
+// It's useful as a key of goroutine-local storage, that is cleaned up manually.
+//
+// Golang runtime may reuse the IDs of the finished goroutines.
+func GoroutineID() uint64 {
+
+	var buf [64]byte
+	b := buf[:runtime.Stack(buf[:], false)]
+
+	const prefix = "goroutine "
+	if len(b) <= len(prefix) || string(b[:len(prefix)]) != prefix {
+		return 0
+	}
+
+	id := uint64(0)
+	for _, c := range b[len(prefix):] {
+		if c < '0' || c > '9' {
+			break
+		}
+		id = id*10 + uint64(c-'0')
+	}
+
+	return id
+}
@@ -0,0 +1,28 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekasys"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGoroutineID(t *testing.T) {
+
+	id := ekasys.GoroutineID()
+	assert.NotZero(t, id)
+	assert.Equal(t, id, ekasys.GoroutineID())
+
+	ch := make(chan uint64)
+	go func() { ch <- ekasys.GoroutineID() }()
+
+	otherID := <-ch
+	assert.NotZero(t, otherID)
+	assert.NotEqual(t, id, otherID)
+}
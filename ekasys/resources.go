@@ -0,0 +1,97 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys
+
+import (
+	"errors"
+	"runtime/metrics"
+)
+
+type (
+	// ResourceUsage is a snapshot of the current process' resources usage.
+	// Read more: GetResourceUsage().
+	ResourceUsage struct {
+
+		// RSS is the resident set size of the process in bytes.
+		// On Linux it's the current RSS, on macOS it's the peak one.
+		// Zero if it's unsupported by the current platform.
+		RSS uint64
+
+		// HeapAlloc is the bytes of allocated heap objects
+		// (including not yet swept unreachable ones).
+		HeapAlloc uint64
+
+		// HeapObjects is the number of allocated heap objects.
+		HeapObjects uint64
+
+		// Goroutines is the number of live goroutines.
+		Goroutines uint64
+
+		// OpenFDs is the number of opened file descriptors by the process.
+		// -1 if it's unsupported by the current platform.
+		OpenFDs int
+	}
+
+	// DiskUsage is a snapshot of the disk usage of a filesystem.
+	// All values are in bytes. Read more: GetDiskUsage().
+	DiskUsage struct {
+		Total     uint64 // total size of the filesystem
+		Free      uint64 // free space (including reserved for superuser)
+		Available uint64 // free space available for unprivileged users
+		Used      uint64 // Total - Free
+	}
+)
+
+var (
+	// ErrResourceUnsupported is returned by the functions
+	// that are not supported by the current platform.
+	ErrResourceUnsupported = errors.New("ekasys: unsupported by the current platform")
+)
+
+var (
+	resourceUsageMetrics = [...]string{
+		"/memory/classes/heap/objects:bytes",
+		"/gc/heap/objects:objects",
+		"/sched/goroutines:goroutines",
+	}
+)
+
+// GetResourceUsage returns a snapshot of the current process' resources usage.
+// It's cheap: doesn't stop the world (uses runtime/metrics, not runtime.ReadMemStats()),
+// doesn't run any external command, using only platform-specific syscalls
+// (and procfs on Linux).
+func GetResourceUsage() ResourceUsage {
+
+	var samples [len(resourceUsageMetrics)]metrics.Sample
+	for i := range resourceUsageMetrics {
+		samples[i].Name = resourceUsageMetrics[i]
+	}
+	metrics.Read(samples[:])
+
+	return ResourceUsage{
+		RSS:         getRSS(),
+		HeapAlloc:   resourceUsageMetricValue(samples[0]),
+		HeapObjects: resourceUsageMetricValue(samples[1]),
+		Goroutines:  resourceUsageMetricValue(samples[2]),
+		OpenFDs:     getOpenFDs(),
+	}
+}
+
+// GetDiskUsage returns a snapshot of the disk usage of a filesystem,
+// the file or directory 'path' belongs to.
+// Returns ErrResourceUnsupported if it's unsupported by the current platform.
+func GetDiskUsage(path string) (DiskUsage, error) {
+	return getDiskUsage(path)
+}
+
+// resourceUsageMetricValue returns the value of 'sample' if it's uint64 one,
+// or 0 otherwise (e.g. if metric is unsupported by the current Go runtime).
+func resourceUsageMetricValue(sample metrics.Sample) uint64 {
+	if sample.Value.Kind() != metrics.KindUint64 {
+		return 0
+	}
+	return sample.Value.Uint64()
+}
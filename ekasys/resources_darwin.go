@@ -0,0 +1,25 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys
+
+import (
+	"syscall"
+)
+
+// getRSS returns the peak RSS using getrusage(2),
+// since there is no cheap way to get the current one on macOS.
+// 0 if it's failed.
+func getRSS() uint64 {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0
+	}
+	return uint64(ru.Maxrss) // bytes on macOS
+}
+
+func getOpenFDs() int {
+	return countOpenFDs("/dev/fd")
+}
@@ -0,0 +1,38 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys
+
+import (
+	"bytes"
+	"os"
+	"strconv"
+)
+
+// getRSS returns the current RSS reading it from /proc/self/statm
+// (the 2nd value is RSS in pages). 0 if it's failed.
+func getRSS() uint64 {
+
+	b, err := os.ReadFile("/proc/self/statm")
+	if err != nil {
+		return 0
+	}
+
+	fields := bytes.Fields(b)
+	if len(fields) < 2 {
+		return 0
+	}
+
+	pages, err := strconv.ParseUint(string(fields[1]), 10, 64)
+	if err != nil {
+		return 0
+	}
+
+	return pages * uint64(os.Getpagesize())
+}
+
+func getOpenFDs() int {
+	return countOpenFDs("/proc/self/fd")
+}
@@ -0,0 +1,20 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build !linux && !darwin
+
+package ekasys
+
+func getRSS() uint64 {
+	return 0
+}
+
+func getOpenFDs() int {
+	return -1
+}
+
+func getDiskUsage(_ string) (DiskUsage, error) {
+	return DiskUsage{}, ErrResourceUnsupported
+}
@@ -0,0 +1,48 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys_test
+
+import (
+	"os"
+	"runtime"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekasys"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetResourceUsage(t *testing.T) {
+
+	ru := ekasys.GetResourceUsage()
+
+	assert.NotZero(t, ru.HeapAlloc)
+	assert.NotZero(t, ru.HeapObjects)
+	assert.NotZero(t, ru.Goroutines)
+
+	if runtime.GOOS == "linux" {
+		assert.NotZero(t, ru.RSS)
+		assert.GreaterOrEqual(t, ru.OpenFDs, 3) // stdin, stdout, stderr at least
+	}
+}
+
+func TestGetDiskUsage(t *testing.T) {
+
+	du, err := ekasys.GetDiskUsage(os.TempDir())
+	if runtime.GOOS != "linux" && runtime.GOOS != "darwin" {
+		assert.Equal(t, ekasys.ErrResourceUnsupported, err)
+		return
+	}
+
+	require.NoError(t, err)
+	assert.NotZero(t, du.Total)
+	assert.LessOrEqual(t, du.Available, du.Free)
+	assert.Equal(t, du.Total-du.Free, du.Used)
+
+	_, err = ekasys.GetDiskUsage("/path/that/does/not/exist")
+	assert.Error(t, err)
+}
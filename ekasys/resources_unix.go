@@ -0,0 +1,42 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build linux || darwin
+
+package ekasys
+
+import (
+	"os"
+	"syscall"
+)
+
+func getDiskUsage(path string) (DiskUsage, error) {
+
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return DiskUsage{}, err
+	}
+
+	bsize := uint64(st.Bsize)
+	du := DiskUsage{
+		Total:     uint64(st.Blocks) * bsize,
+		Free:      uint64(st.Bfree) * bsize,
+		Available: uint64(st.Bavail) * bsize,
+	}
+	du.Used = du.Total - du.Free
+
+	return du, nil
+}
+
+// countOpenFDs returns the number of entries in 'fdDir' directory,
+// that must be a directory of the process' file descriptors,
+// excluding the one that is used to read that directory. -1 if it's failed.
+func countOpenFDs(fdDir string) int {
+	entries, err := os.ReadDir(fdDir)
+	if err != nil || len(entries) == 0 {
+		return -1
+	}
+	return len(entries) - 1
+}
@@ -0,0 +1,153 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys
+
+import (
+	"bytes"
+	"os"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// SourceLine is a line of the source file, the part of StackFrame's
+	// source snippet. Read more: StackFrame.SourceSnippet().
+	SourceLine struct {
+		Line int    // line number, starting from 1
+		Text string // line's content w/o trailing new line
+	}
+)
+
+// SOURCE_SNIPPET_MAX_CACHED_FILES is a max number of source files,
+// whose content is kept in RAM for StackFrame.SourceSnippet().
+// The cache is dropped when it's overflowed.
+//
+//goland:noinspection GoSnakeCaseUsage
+const SOURCE_SNIPPET_MAX_CACHED_FILES = 128
+
+var (
+	// sourceSnippetContext is a number of lines before and after the frame's line,
+	// StackFrame.SourceSnippet() returns. 0 means source snippets are disabled.
+	// Atomic access only.
+	sourceSnippetContext int32
+
+	sourceSnippetCache struct {
+		mu      sync.RWMutex
+		pathMap func(path string) string
+		files   map[string][][]byte // file path to its lines, nil if file can't be read
+	}
+)
+
+// EnableSourceSnippets enables StackFrame.SourceSnippet() that returns
+// 'contextLines' lines of the source file before and after the frame's line.
+// Encoders (e.g. ekalog's ones) embed source snippets to the each encoded
+// stack frame if they're enabled, that is a massive help in dev/staging
+// environments.
+//
+// Source snippets are disabled by default. Pass 'contextLines' <= 0 to disable.
+// Source files are read lazily (only when the snippet is requested)
+// from the local filesystem and cached.
+// Use SetSourcePathMapping() if the binary is built on another machine
+// (or in the container) and the sources are placed to another location.
+//
+// WARNING!
+// DO NOT ENABLE IT IN PRODUCTION. Each stack frame's file is read and kept in RAM.
+func EnableSourceSnippets(contextLines int) {
+	if contextLines < 0 {
+		contextLines = 0
+	}
+	atomic.StoreInt32(&sourceSnippetContext, int32(contextLines))
+}
+
+// SetSourcePathMapping sets the function, that maps the path of the source file
+// of stack frame (runtime.Frame's File) to the path of local file.
+// Return an empty string to skip the file. Nil means no mapping.
+// Drops the cached source files.
+func SetSourcePathMapping(pathMap func(path string) string) {
+
+	sourceSnippetCache.mu.Lock()
+	defer sourceSnippetCache.mu.Unlock()
+
+	sourceSnippetCache.pathMap = pathMap
+	sourceSnippetCache.files = nil
+}
+
+// SourceSnippet returns the lines of the source file around the frame's line
+// (the frame's line, and N lines before and after it,
+// where N is set by EnableSourceSnippets()).
+//
+// Returns nil if source snippets are disabled (by default)
+// or the source file can't be read.
+func (f *StackFrame) SourceSnippet() []SourceLine {
+
+	contextLines := int(atomic.LoadInt32(&sourceSnippetContext))
+	if contextLines == 0 || f.File == "" || f.Line <= 0 {
+		return nil
+	}
+
+	lines := sourceSnippetFile(f.File)
+	if f.Line > len(lines) {
+		return nil
+	}
+
+	from, to := f.Line-contextLines, f.Line+contextLines
+	if from < 1 {
+		from = 1
+	}
+	if to > len(lines) {
+		to = len(lines)
+	}
+
+	snippet := make([]SourceLine, 0, to-from+1)
+	for i := from; i <= to; i++ {
+		snippet = append(snippet, SourceLine{Line: i, Text: string(lines[i-1])})
+	}
+
+	return snippet
+}
+
+// sourceSnippetFile returns the lines of source file with 'path',
+// reading it if it's not cached yet.
+func sourceSnippetFile(path string) [][]byte {
+
+	sourceSnippetCache.mu.RLock()
+	lines, found := sourceSnippetCache.files[path]
+	sourceSnippetCache.mu.RUnlock()
+
+	if found {
+		return lines
+	}
+
+	sourceSnippetCache.mu.Lock()
+	defer sourceSnippetCache.mu.Unlock()
+
+	if lines, found = sourceSnippetCache.files[path]; found {
+		return lines
+	}
+
+	localPath := path
+	if sourceSnippetCache.pathMap != nil {
+		localPath = sourceSnippetCache.pathMap(path)
+	}
+
+	if localPath != "" {
+		if data, err := os.ReadFile(localPath); err == nil {
+			lines = bytes.Split(bytes.TrimSuffix(data, []byte{'\n'}), []byte{'\n'})
+			for i := range lines {
+				lines[i] = bytes.TrimSuffix(lines[i], []byte{'\r'})
+			}
+		}
+	}
+
+	if len(sourceSnippetCache.files) >= SOURCE_SNIPPET_MAX_CACHED_FILES ||
+		sourceSnippetCache.files == nil {
+
+		sourceSnippetCache.files = make(map[string][][]byte)
+	}
+
+	sourceSnippetCache.files[path] = lines
+	return lines
+}
@@ -0,0 +1,46 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekasys_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekasys"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestStackFrame_SourceSnippet(t *testing.T) {
+
+	frame := ekasys.GetStackTrace(0, 1)[0] // this line is in the snippet
+	require.Nil(t, frame.SourceSnippet())
+
+	ekasys.EnableSourceSnippets(1)
+	defer ekasys.EnableSourceSnippets(0)
+
+	snippet := frame.SourceSnippet()
+	require.Len(t, snippet, 3)
+	require.Equal(t, frame.Line, snippet[1].Line)
+	require.Contains(t, snippet[1].Text, "this line is in the snippet")
+	require.Contains(t, snippet[2].Text, "require.Nil(")
+
+	// Path mapping.
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "x.go"), []byte("a\nb\nc\r\nd\n"), 0600))
+
+	ekasys.SetSourcePathMapping(func(path string) string {
+		return filepath.Join(dir, filepath.Base(path))
+	})
+	defer ekasys.SetSourcePathMapping(nil)
+
+	frame.File, frame.Line = "/build/x.go", 4
+	require.Equal(t, []ekasys.SourceLine{{Line: 3, Text: "c"}, {Line: 4, Text: "d"}}, frame.SourceSnippet())
+
+	frame.File = "/build/missing.go"
+	require.Nil(t, frame.SourceSnippet())
+}
@@ -81,10 +81,31 @@ func GetStackTrace(skip, depth int) (stacktrace StackTrace) {
 	}
 	skip++
 
+	return StackTraceFromFramePoints(getStackFramePoints(skip, depth))
+}
+
+// GetStackFramePoints is the same as GetStackTrace() but returns only
+// the stack trace frame points (program counters) w/o their resolving
+// to the StackFrame objects, that is the most expensive part of GetStackTrace().
+//
+// Use StackTraceFromFramePoints() to resolve them later, when it's needed.
+func GetStackFramePoints(skip, depth int) (framePoints []uintptr) {
+
+	// the same as in GetStackTrace()
+	if skip < -3 {
+		skip = -3
+	}
+	skip++
+
+	return getStackFramePoints(skip, depth)
+}
+
+// StackTraceFromFramePoints resolves 'framePoints' that is returned
+// by GetStackFramePoints() to the StackTrace.
+func StackTraceFromFramePoints(framePoints []uintptr) (stacktrace StackTrace) {
+
 	// prepare to get runtime.Frame objects:
-	// - get stack trace frame points,
-	// - create runtime.Frame iterator by frame points from prev step
-	framePoints := getStackFramePoints(skip, depth)
+	// create runtime.Frame iterator by frame points
 	framePointsLen := len(framePoints)
 	frameIterator := runtime.CallersFrames(framePoints)
 
@@ -178,6 +178,34 @@ func (dd Date) Equal(other Date) bool {
 	return dd.ToCmp() == other.ToCmp()
 }
 
+// Cmp compares the current Date and provided one ignoring weekday.
+// Returns -1 if current < another, 0 if they're equal, 1 if current > another.
+func (dd Date) Cmp(other Date) int {
+	if a, b := dd.ToCmp(), other.ToCmp(); a < b {
+		return -1
+	} else if a > b {
+		return 1
+	} else {
+		return 0
+	}
+}
+
+// Before reports whether the current Date is before 'other'.
+func (dd Date) Before(other Date) bool {
+	return dd.Cmp(other) < 0
+}
+
+// After reports whether the current Date is after 'other'.
+func (dd Date) After(other Date) bool {
+	return dd.Cmp(other) > 0
+}
+
+// DaysTill returns how much days are between the current Date and 'other'.
+// The result is negative if 'other' is before the current Date.
+func (dd Date) DaysTill(other Date) Days {
+	return Days((other.WithTime(0, 0, 0) - dd.WithTime(0, 0, 0)) / SECONDS_IN_DAY)
+}
+
 // Year returns the year number the current Date includes which.
 //
 // It guarantees that Year() returns the valid year number Date is of,
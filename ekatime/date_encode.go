@@ -6,10 +6,14 @@
 package ekatime
 
 import (
+	"database/sql/driver"
+	"encoding/binary"
 	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
-	"github.com/qioalice/ekago/v3/internal/ekaenc"
+	"github.com/qioalice/ekago/v3/ekaenc"
 )
 
 //goland:noinspection GoSnakeCaseUsage
@@ -21,6 +25,7 @@ var (
 	_ERR_BAD_DAY           = errors.New("day must be in the range [1..31]")
 	_ERR_BAD_CORRESP_DATE  = errors.New("date must represent valid date (check year, month, day corresponding)")
 	_ERR_BAD_JSON_DATE_QUO = errors.New("bad JSON ISO8601 date representation (forgotten quotes?)")
+	_ERR_BAD_BINARY_DATE   = errors.New("binary date representation must be 4 bytes long")
 )
 
 // AppendTo generates a string representation of Date and adds it to the b,
@@ -199,3 +204,129 @@ func (dd *Date) UnmarshalJSON(b []byte) error {
 		return dd.ParseFrom(b[1 : l-1])
 	}
 }
+
+// AppendText has the same signature as encoding.TextAppender (Go 1.24+).
+// Appends the current Date in the following format "YYYY-MM-DD" to the b
+// and returns it. Always returns nil as error.
+//
+// Nothing is appended if the current Date == 0.
+func (dd Date) AppendText(b []byte) ([]byte, error) {
+	if dd.ToCmp() == 0 {
+		return b, nil
+	}
+	return dd.AppendTo(b, '-'), nil
+}
+
+// MarshalText implements encoding.TextMarshaler interface.
+// Encodes the current Date in the following format "YYYY-MM-DD", and returns it.
+// Returns nil if receiver is nil. Always returns nil as error.
+//
+// Returns an empty (but not nil) slice if the current Date == 0.
+func (dd *Date) MarshalText() ([]byte, error) {
+	if dd == nil {
+		return nil, nil
+	}
+	return dd.AppendText(make([]byte, 0, 10))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler interface.
+// Decodes b into the current Date object expecting b contains
+// ISO8601 date (only date, not date w/ time) in the one of the following
+// formats: "YYYYMMDD", "YYYY-MM-DD".
+//
+// Zeroes Date if b is empty.
+// In other cases Date.ParseFrom() error is returned.
+func (dd *Date) UnmarshalText(b []byte) error {
+
+	if dd == nil {
+		return _ERR_NIL_DATE_RECEIVER
+	}
+
+	if len(b) == 0 {
+		*dd = 0
+		return nil
+	}
+
+	return dd.ParseFrom(b)
+}
+
+// AppendBinary has the same signature as encoding.BinaryAppender (Go 1.24+).
+// Appends 4 bytes to b: year (2 bytes, big endian), month (1 byte), day (1 byte)
+// and returns it. Always returns nil as error.
+func (dd Date) AppendBinary(b []byte) ([]byte, error) {
+	y, m, d := dd.Split()
+	return append(b, byte(y>>8), byte(y), byte(m), byte(d)), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler interface.
+// Encodes the current Date as 4 bytes. Read more: Date.AppendBinary().
+// Returns nil if receiver is nil. Always returns nil as error.
+func (dd *Date) MarshalBinary() ([]byte, error) {
+	if dd == nil {
+		return nil, nil
+	}
+	return dd.AppendBinary(make([]byte, 0, 4))
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler interface.
+// Decodes b that must be obtained by Date.MarshalBinary() into the current Date.
+func (dd *Date) UnmarshalBinary(b []byte) error {
+
+	switch {
+	case dd == nil:
+		return _ERR_NIL_DATE_RECEIVER
+	case len(b) != 4:
+		return _ERR_BAD_BINARY_DATE
+	}
+
+	y, m, d := Year(binary.BigEndian.Uint16(b)), Month(b[2]), Day(b[3])
+
+	switch {
+	case y == 0 && m == 0 && d == 0:
+		*dd = 0
+		return nil
+	case !IsValidDate(y, m, d):
+		return _ERR_BAD_CORRESP_DATE
+	}
+
+	*dd = NewDate(y, m, d)
+	return nil
+}
+
+// Value implements driver.Valuer interface.
+// Returns time.Time (UTC midnight of the current Date) or SQL NULL
+// if the current Date == 0. Always returns nil as error.
+func (dd Date) Value() (driver.Value, error) {
+	if dd.ToCmp() == 0 {
+		return nil, nil
+	}
+	y, m, d := dd.Split()
+	return time.Date(int(y), time.Month(m), int(d), 0, 0, 0, 0, time.UTC), nil
+}
+
+// Scan implements sql.Scanner interface.
+// Supports time.Time, string and []byte (ISO8601 date) and SQL NULL (zeroes Date).
+func (dd *Date) Scan(src any) error {
+
+	if dd == nil {
+		return _ERR_NIL_DATE_RECEIVER
+	}
+
+	switch src := src.(type) {
+	case nil:
+		*dd = 0
+		return nil
+
+	case time.Time:
+		*dd = NewDate(Year(src.Year()), Month(src.Month()), Day(src.Day()))
+		return nil
+
+	case []byte:
+		return dd.UnmarshalText(src)
+
+	case string:
+		return dd.UnmarshalText([]byte(src))
+	}
+
+	return fmt.Errorf("ekatime: cannot convert %T to Date", src)
+}
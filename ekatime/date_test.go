@@ -542,3 +542,41 @@ func TestNewDateFromDays(t *testing.T) {
 		ekatime.NewDateFromDayOfYear(2021, 253).DayOfYear(),
 	)
 }
+
+func TestDate_TextBinarySQL(t *testing.T) {
+	d := ekatime.NewDate(2020, 9, 12)
+
+	text, err := d.MarshalText()
+	require.NoError(t, err)
+	require.EqualValues(t, "2020-09-12", string(text))
+
+	var d2 ekatime.Date
+	require.NoError(t, d2.UnmarshalText(text))
+	require.True(t, d.Equal(d2))
+
+	bin, err := d.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0x07, 0xE4, 9, 12}, bin)
+
+	var d3 ekatime.Date
+	require.NoError(t, d3.UnmarshalBinary(bin))
+	require.True(t, d.Equal(d3))
+
+	v, err := d.Value()
+	require.NoError(t, err)
+
+	var d4 ekatime.Date
+	require.NoError(t, d4.Scan(v))
+	require.True(t, d.Equal(d4))
+}
+
+func TestDate_Cmp(t *testing.T) {
+	d1 := ekatime.NewDate(2020, 9, 12)
+	d2 := ekatime.NewDate(2020, 10, 1)
+
+	require.True(t, d1.Before(d2))
+	require.True(t, d2.After(d1))
+	require.Equal(t, 0, d1.Cmp(d1))
+	require.EqualValues(t, 19, d1.DaysTill(d2))
+	require.EqualValues(t, -19, d2.DaysTill(d1))
+}
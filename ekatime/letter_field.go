@@ -0,0 +1,29 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatime
+
+import (
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+// FDate constructs a log field with the given key and Date as its value.
+// Date is represented the same way as Date.String() does.
+func FDate(key string, dd Date) ekaletter.LetterField {
+	return ekaletter.FString(key, dd.String())
+}
+
+// FTime constructs a log field with the given key and Time as its value.
+// Time is represented the same way as Time.String() does.
+func FTime(key string, t Time) ekaletter.LetterField {
+	return ekaletter.FString(key, t.String())
+}
+
+// FTimestamp constructs a log field with the given key and Timestamp as its value.
+// Timestamp is represented as a unixtime in sec, so an encoder may format it
+// the same way as any other time field.
+func FTimestamp(key string, ts Timestamp) ekaletter.LetterField {
+	return ekaletter.FUnix(key, ts.I64())
+}
@@ -68,6 +68,41 @@ func (t Time) Split() (h Hour, m Minute, s Second) {
 	return t.Hour(), t.Minute(), t.Second()
 }
 
+// SinceMidnight returns how much seconds are passed since 00:00:00
+// to the current Time.
+//
+// Returns it as Timestamp because of easy arithmetic operations,
+// but it's NOT A TIMESTAMP!
+func (t Time) SinceMidnight() Timestamp {
+	h, m, s := t.Split()
+	return Timestamp(h)*SECONDS_IN_HOUR + Timestamp(m)*SECONDS_IN_MINUTE + Timestamp(s)
+}
+
+// Cmp compares the current Time and provided one.
+// Returns -1 if current < another, 0 if they're equal, 1 if current > another.
+//
+// WARNING!
+// Do not compare Time objects directly using < or > operators,
+// the internal layout is not ordered. Use this method instead.
+func (t Time) Cmp(other Time) int {
+	return t.SinceMidnight().Cmp(other.SinceMidnight())
+}
+
+// Equal returns true if the current Time is the same as 'other'.
+func (t Time) Equal(other Time) bool {
+	return t.Cmp(other) == 0
+}
+
+// Before reports whether the current Time is before 'other'.
+func (t Time) Before(other Time) bool {
+	return t.Cmp(other) < 0
+}
+
+// After reports whether the current Time is after 'other'.
+func (t Time) After(other Time) bool {
+	return t.Cmp(other) > 0
+}
+
 // NewTime creates a new Time object using provided hour number, minute number,
 // second number, normalizing these values and shifting time if it's required.
 //
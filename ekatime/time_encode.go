@@ -6,9 +6,12 @@
 package ekatime
 
 import (
+	"database/sql/driver"
 	"errors"
+	"fmt"
+	"time"
 
-	"github.com/qioalice/ekago/v3/internal/ekaenc"
+	"github.com/qioalice/ekago/v3/ekaenc"
 )
 
 //goland:noinspection GoSnakeCaseUsage
@@ -20,6 +23,7 @@ var (
 	_ERR_BAD_SECOND        = errors.New("seconds must be in the range [0..59]")
 	_ERR_BAD_CORRESP_TIME  = errors.New("time must represent valid time (24h format)")
 	_ERR_BAD_JSON_TIME_QUO = errors.New("bad JSON ISO8601 time representation (forgotten quotes?)")
+	_ERR_BAD_BINARY_TIME   = errors.New("binary time representation must be 3 bytes long")
 )
 
 // AppendTo generates a string representation of Time and adds it to the b,
@@ -197,3 +201,116 @@ func (t *Time) UnmarshalJSON(b []byte) error {
 		return t.ParseFrom(b[1 : l-1])
 	}
 }
+
+// AppendText has the same signature as encoding.TextAppender (Go 1.24+).
+// Appends the current Time in the following format "hh:mm:ss" to the b
+// and returns it. Always returns nil as error.
+//
+// Unlike MarshalJSON(), Time == 0 is encoded as "00:00:00".
+func (t Time) AppendText(b []byte) ([]byte, error) {
+	return t.AppendTo(b, ':'), nil
+}
+
+// MarshalText implements encoding.TextMarshaler interface.
+// Encodes the current Time in the following format "hh:mm:ss", and returns it.
+// Returns nil if receiver is nil. Always returns nil as error.
+func (t *Time) MarshalText() ([]byte, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return t.AppendText(make([]byte, 0, 8))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler interface.
+// Decodes b into the current Time object expecting b contains
+// ISO8601 time (only time, not time w/ date) in the one of the following
+// formats: "hhmm", "hh:mm", "hhmmss", "hh:mm:ss".
+//
+// Zeroes Time if b is empty.
+// In other cases Time.ParseFrom() error is returned.
+func (t *Time) UnmarshalText(b []byte) error {
+
+	if t == nil {
+		return _ERR_NIL_TIME_RECEIVER
+	}
+
+	if len(b) == 0 {
+		*t = 0
+		return nil
+	}
+
+	return t.ParseFrom(b)
+}
+
+// AppendBinary has the same signature as encoding.BinaryAppender (Go 1.24+).
+// Appends 3 bytes to b: hour, minute, second (1 byte each) and returns it.
+// Always returns nil as error.
+func (t Time) AppendBinary(b []byte) ([]byte, error) {
+	hh, mm, ss := t.Split()
+	return append(b, byte(hh), byte(mm), byte(ss)), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler interface.
+// Encodes the current Time as 3 bytes. Read more: Time.AppendBinary().
+// Returns nil if receiver is nil. Always returns nil as error.
+func (t *Time) MarshalBinary() ([]byte, error) {
+	if t == nil {
+		return nil, nil
+	}
+	return t.AppendBinary(make([]byte, 0, 3))
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler interface.
+// Decodes b that must be obtained by Time.MarshalBinary() into the current Time.
+func (t *Time) UnmarshalBinary(b []byte) error {
+
+	switch {
+	case t == nil:
+		return _ERR_NIL_TIME_RECEIVER
+	case len(b) != 3:
+		return _ERR_BAD_BINARY_TIME
+	}
+
+	hh, mm, ss := Hour(b[0]), Minute(b[1]), Second(b[2])
+	if !IsValidTime(hh, mm, ss) {
+		return _ERR_BAD_CORRESP_TIME
+	}
+
+	*t = NewTime(hh, mm, ss)
+	return nil
+}
+
+// Value implements driver.Valuer interface.
+// Returns the current Time as string in the following format "hh:mm:ss",
+// that is suitable for SQL TIME type. Always returns nil as error.
+func (t Time) Value() (driver.Value, error) {
+	return t.String(), nil
+}
+
+// Scan implements sql.Scanner interface.
+// Supports time.Time (only clock is used), string and []byte (ISO8601 time)
+// and SQL NULL (zeroes Time).
+func (t *Time) Scan(src any) error {
+
+	if t == nil {
+		return _ERR_NIL_TIME_RECEIVER
+	}
+
+	switch src := src.(type) {
+	case nil:
+		*t = 0
+		return nil
+
+	case time.Time:
+		*t = NewTime(Hour(src.Hour()), Minute(src.Minute()), Second(src.Second()))
+		return nil
+
+	case []byte:
+		return t.UnmarshalText(src)
+
+	case string:
+		return t.UnmarshalText([]byte(src))
+	}
+
+	return fmt.Errorf("ekatime: cannot convert %T to Time", src)
+}
@@ -126,3 +126,20 @@ func TestTime_Add(t *testing.T) {
 	tt = tt.Add(127, 0, 0)
 	require.Equal(t, ekatime.NewTime(17, 16, 38), tt)
 }
+
+func TestTime_Cmp(t *testing.T) {
+	t1 := ekatime.NewTime(9, 59, 59)
+	t2 := ekatime.NewTime(10, 0, 0)
+
+	require.True(t, t1.Before(t2))
+	require.True(t, t2.After(t1))
+	require.True(t, t1.Equal(ekatime.NewTime(9, 59, 59)))
+	require.EqualValues(t, 36000, t2.SinceMidnight())
+
+	bin, err := t1.MarshalBinary()
+	require.NoError(t, err)
+
+	var t3 ekatime.Time
+	require.NoError(t, t3.UnmarshalBinary(bin))
+	require.True(t, t1.Equal(t3))
+}
@@ -31,6 +31,33 @@ func (ts Timestamp) Cmp(anotherTimestamp Timestamp) int {
 	}
 }
 
+// Equal returns true if the current Timestamp is the same as 'other'.
+func (ts Timestamp) Equal(other Timestamp) bool {
+	return ts == other
+}
+
+// Before reports whether the current Timestamp is before 'other'.
+func (ts Timestamp) Before(other Timestamp) bool {
+	return ts < other
+}
+
+// After reports whether the current Timestamp is after 'other'.
+func (ts Timestamp) After(other Timestamp) bool {
+	return ts > other
+}
+
+// Add returns a new Timestamp that is the current one shifted by 'd'.
+// Parts of 'd' less than a second are truncated.
+func (ts Timestamp) Add(d time.Duration) Timestamp {
+	return ts + Timestamp(d/time.Second)
+}
+
+// Sub returns the duration between the current Timestamp and 'other'
+// (current - other).
+func (ts Timestamp) Sub(other Timestamp) time.Duration {
+	return time.Duration(ts-other) * time.Second
+}
+
 // I64 returns int64 representation of the current Timestamp 'ts.
 func (ts Timestamp) I64() int64 {
 	return int64(ts)
@@ -6,9 +6,13 @@
 package ekatime
 
 import (
+	"database/sql/driver"
+	"encoding/binary"
 	"errors"
+	"fmt"
+	"time"
 
-	"github.com/qioalice/ekago/v3/internal/ekaenc"
+	"github.com/qioalice/ekago/v3/ekaenc"
 )
 
 //goland:noinspection GoSnakeCaseUsage
@@ -17,6 +21,7 @@ var (
 	_ERR_NOT_ISO8601_TIMESTAMP   = errors.New("incorrect ISO8601 timestamp format (must be YYYY-MM-DDThh:mm:ss)")
 	_ERR_BAD_TIMESTAMP_SEPARATOR = errors.New("ISO8601 require using 'T' as date time separator")
 	_ERR_BAD_JSON_TIMESTAMP_QUO  = errors.New("bad JSON ISO8601 timestamp representation (forgotten quotes?)")
+	_ERR_BAD_BINARY_TIMESTAMP    = errors.New("binary timestamp representation must be 8 bytes long")
 )
 
 // AppendTo generates a string representation of Timestamp and adds it to the b,
@@ -198,3 +203,121 @@ func (ts *Timestamp) UnmarshalJSON(b []byte) error {
 		return ts.ParseFrom(b[1 : l-1])
 	}
 }
+
+// AppendText has the same signature as encoding.TextAppender (Go 1.24+).
+// Appends the current Timestamp in the following format "YYYY-MM-DDThh:mm:ss"
+// to the b and returns it. Always returns nil as error.
+func (ts Timestamp) AppendText(b []byte) ([]byte, error) {
+	b = ts.Date().AppendTo(b, '-')
+	b = append(b, 'T')
+	b = ts.Time().AppendTo(b, ':')
+	return b, nil
+}
+
+// MarshalText implements encoding.TextMarshaler interface.
+// Encodes the current Timestamp in the following format "YYYY-MM-DDThh:mm:ss",
+// and returns it. Returns nil if receiver is nil. Always returns nil as error.
+func (ts *Timestamp) MarshalText() ([]byte, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	return ts.AppendText(make([]byte, 0, 19))
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler interface.
+// Decodes b into the current Timestamp object expecting b contains
+// ISO8601 date with time. Read more: Timestamp.ParseFrom().
+//
+// Zeroes Timestamp if b is empty.
+func (ts *Timestamp) UnmarshalText(b []byte) error {
+
+	if ts == nil {
+		return _ERR_NIL_TIMESTAMP_RECEIVER
+	}
+
+	if len(b) == 0 {
+		*ts = 0
+		return nil
+	}
+
+	return ts.ParseFrom(b)
+}
+
+// AppendBinary has the same signature as encoding.BinaryAppender (Go 1.24+).
+// Appends the current Timestamp as 8 bytes (int64, big endian) to b
+// and returns it. Always returns nil as error.
+func (ts Timestamp) AppendBinary(b []byte) ([]byte, error) {
+	v := uint64(ts)
+	return append(b,
+		byte(v>>56), byte(v>>48), byte(v>>40), byte(v>>32),
+		byte(v>>24), byte(v>>16), byte(v>>8), byte(v)), nil
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler interface.
+// Encodes the current Timestamp as 8 bytes. Read more: Timestamp.AppendBinary().
+// Returns nil if receiver is nil. Always returns nil as error.
+func (ts *Timestamp) MarshalBinary() ([]byte, error) {
+	if ts == nil {
+		return nil, nil
+	}
+	return ts.AppendBinary(make([]byte, 0, 8))
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler interface.
+// Decodes b that must be obtained by Timestamp.MarshalBinary()
+// into the current Timestamp.
+func (ts *Timestamp) UnmarshalBinary(b []byte) error {
+
+	switch {
+	case ts == nil:
+		return _ERR_NIL_TIMESTAMP_RECEIVER
+	case len(b) != 8:
+		return _ERR_BAD_BINARY_TIMESTAMP
+	}
+
+	*ts = Timestamp(binary.BigEndian.Uint64(b))
+	return nil
+}
+
+// Value implements driver.Valuer interface.
+// Returns time.Time (UTC) or SQL NULL if the current Timestamp == 0
+// (the same null-like semantic as MarshalJSON() has).
+// Always returns nil as error.
+func (ts Timestamp) Value() (driver.Value, error) {
+	if ts == 0 {
+		return nil, nil
+	}
+	return ts.Std(), nil
+}
+
+// Scan implements sql.Scanner interface.
+// Supports time.Time, int64 (unix seconds), string and []byte (ISO8601 date w/ time)
+// and SQL NULL (zeroes Timestamp).
+func (ts *Timestamp) Scan(src any) error {
+
+	if ts == nil {
+		return _ERR_NIL_TIMESTAMP_RECEIVER
+	}
+
+	switch src := src.(type) {
+	case nil:
+		*ts = 0
+		return nil
+
+	case time.Time:
+		*ts = NewTimestampFromStd(src)
+		return nil
+
+	case int64:
+		*ts = Timestamp(src)
+		return nil
+
+	case []byte:
+		return ts.UnmarshalText(src)
+
+	case string:
+		return ts.UnmarshalText([]byte(src))
+	}
+
+	return fmt.Errorf("ekatime: cannot convert %T to Timestamp", src)
+}
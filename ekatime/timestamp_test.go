@@ -9,6 +9,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/qioalice/ekago/v3/ekatime"
 
@@ -236,3 +237,38 @@ func TestTimestamp_MarshalJSON(t *testing.T) {
 	require.NoError(t, err)
 	require.EqualValues(t, string(d), `{"ts":"2020-09-12T13:14:15"}`)
 }
+
+func TestTimestamp_MarshalBinary(t *testing.T) {
+	ts := ekatime.NewTimestamp(2020, 9, 12, 13, 14, 15)
+
+	bin, err := ts.MarshalBinary()
+	require.NoError(t, err)
+	require.Equal(t, []byte{0, 0, 0, 0, 0x5F, 0x5C, 0xC9, 0xA7}, bin)
+
+	var ts2 ekatime.Timestamp
+	require.NoError(t, ts2.UnmarshalBinary(bin))
+	require.Equal(t, ts, ts2)
+}
+
+func TestTimestamp_Arithmetic(t *testing.T) {
+	ts := ekatime.NewTimestamp(2020, 9, 12, 13, 14, 15)
+	ts2 := ts.Add(90 * time.Minute)
+
+	require.True(t, ts.Before(ts2))
+	require.True(t, ts2.After(ts))
+	require.Equal(t, 90*time.Minute, ts2.Sub(ts))
+
+	text, err := ts.MarshalText()
+	require.NoError(t, err)
+
+	var ts3 ekatime.Timestamp
+	require.NoError(t, ts3.UnmarshalText(text))
+	require.True(t, ts.Equal(ts3))
+
+	v, err := ts.Value()
+	require.NoError(t, err)
+
+	var ts4 ekatime.Timestamp
+	require.NoError(t, ts4.Scan(v))
+	require.True(t, ts.Equal(ts4))
+}
@@ -0,0 +1,508 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Decimal is a fixed-point decimal number: an int64 coefficient
+	// and a scale - a number of digits after the decimal point.
+	// E.g. 12.30 is a Decimal with coefficient 1230 and scale 2.
+	//
+	// Decimal is intended for money amounts and other values
+	// that must not lose precision the way float64 does.
+	// It's an immutable value type, the zero Decimal is 0.
+	//
+	// All arithmetic operations are overflow-checked,
+	// the operations that may lose digits (Mul(), Div(), Round())
+	// require the result's scale and DecimalRoundingMode explicitly.
+	Decimal struct {
+		coef  int64
+		scale uint8
+	}
+
+	// DecimalRoundingMode is a way the digits are dropped
+	// when Decimal is rounded to the lower scale.
+	DecimalRoundingMode uint8
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// DECIMAL_MAX_SCALE is the maximum scale of Decimal.
+	DECIMAL_MAX_SCALE = 18
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// DECIMAL_ROUND_HALF_UP rounds to the nearest, ties away from zero
+	// (1.25 -> 1.3, -1.25 -> -1.3).
+	DECIMAL_ROUND_HALF_UP DecimalRoundingMode = iota
+
+	// DECIMAL_ROUND_HALF_EVEN rounds to the nearest, ties to even
+	// (1.25 -> 1.2, 1.35 -> 1.4). Also known as banker's rounding.
+	DECIMAL_ROUND_HALF_EVEN
+
+	// DECIMAL_ROUND_DOWN rounds toward zero (1.29 -> 1.2, -1.29 -> -1.2).
+	DECIMAL_ROUND_DOWN
+
+	// DECIMAL_ROUND_UP rounds away from zero (1.21 -> 1.3, -1.21 -> -1.3).
+	DECIMAL_ROUND_UP
+
+	// DECIMAL_ROUND_FLOOR rounds toward negative infinity (1.29 -> 1.2, -1.21 -> -1.3).
+	DECIMAL_ROUND_FLOOR
+
+	// DECIMAL_ROUND_CEIL rounds toward positive infinity (1.21 -> 1.3, -1.29 -> -1.2).
+	DECIMAL_ROUND_CEIL
+)
+
+var (
+	// ErrDecimalFormat is returned (wrapped) by Decimal_FromString()
+	// if the input is not a Decimal's text representation.
+	ErrDecimalFormat = errors.New("decimal: incorrect Decimal format")
+
+	// ErrDecimalOverflow is returned if the result of Decimal's operation
+	// doesn't fit the Decimal's int64 coefficient.
+	ErrDecimalOverflow = errors.New("decimal: overflow")
+
+	// ErrDecimalScale is returned if the requested or parsed scale
+	// is greater than DECIMAL_MAX_SCALE.
+	ErrDecimalScale = errors.New("decimal: scale is too big")
+
+	// ErrDecimalDivisionByZero is returned by Decimal.Div() if the divisor is zero.
+	ErrDecimalDivisionByZero = errors.New("decimal: division by zero")
+)
+
+// ---------------------------- DECIMAL CONSTRUCTORS -------------------------- //
+// ---------------------------------------------------------------------------- //
+
+// Decimal_New returns a Decimal with 'coef' coefficient and 'scale':
+// Decimal_New(1230, 2) is 12.30. Panics if 'scale' > DECIMAL_MAX_SCALE.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func Decimal_New(coef int64, scale uint8) Decimal {
+	if scale > DECIMAL_MAX_SCALE {
+		panic(ErrDecimalScale)
+	}
+	return Decimal{coef: coef, scale: scale}
+}
+
+// Decimal_FromInt returns a Decimal of integer 'v' with zero scale.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func Decimal_FromInt(v int64) Decimal {
+	return Decimal{coef: v}
+}
+
+// Decimal_FromString returns Decimal parsed from string input
+// like "12", "-12.30", "+0.001". The scale is the number of digits
+// after the decimal point (trailing zeros are kept).
+// Exponent notation is not supported.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func Decimal_FromString(input string) (Decimal, error) {
+
+	s := input
+	neg := false
+
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		neg = s[0] == '-'
+		s = s[1:]
+	}
+
+	intPart, fracPart := s, ""
+	if i := strings.IndexByte(s, '.'); i != -1 {
+		intPart, fracPart = s[:i], s[i+1:]
+	}
+
+	if intPart == "" && fracPart == "" || !decimalIsDigits(intPart) || !decimalIsDigits(fracPart) {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrDecimalFormat, input)
+	}
+	if len(fracPart) > DECIMAL_MAX_SCALE {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrDecimalScale, input)
+	}
+
+	digits := intPart + fracPart
+	if digits == "" {
+		digits = "0"
+	}
+	if neg {
+		digits = "-" + digits
+	}
+
+	coef, err := strconv.ParseInt(digits, 10, 64)
+	if err != nil {
+		return Decimal{}, fmt.Errorf("%w: %q", ErrDecimalOverflow, input)
+	}
+
+	return Decimal{coef: coef, scale: uint8(len(fracPart))}, nil
+}
+
+// Decimal_FromString_OrPanic is the same as Decimal_FromString()
+// but panics if the input can't be parsed.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func Decimal_FromString_OrPanic(input string) Decimal {
+	d, err := Decimal_FromString(input)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// ------------------------------ DECIMAL GETTERS ----------------------------- //
+// ---------------------------------------------------------------------------- //
+
+// Coef returns Decimal's coefficient: 1230 for 12.30.
+func (d Decimal) Coef() int64 {
+	return d.coef
+}
+
+// Scale returns Decimal's scale: 2 for 12.30.
+func (d Decimal) Scale() uint8 {
+	return d.scale
+}
+
+// Sign returns -1, 0 or +1 depending on whether Decimal is negative, zero or positive.
+func (d Decimal) Sign() int {
+	switch {
+	case d.coef < 0:
+		return -1
+	case d.coef > 0:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether Decimal is zero (regardless of its scale).
+func (d Decimal) IsZero() bool {
+	return d.coef == 0
+}
+
+// Cmp compares Decimal s (regardless of their scales) and returns
+// -1 if d < other, 0 if d == other and +1 if d > other.
+func (d Decimal) Cmp(other Decimal) int {
+	if d.scale == other.scale {
+		switch {
+		case d.coef < other.coef:
+			return -1
+		case d.coef > other.coef:
+			return 1
+		default:
+			return 0
+		}
+	}
+	a, b := d.bigAligned(other)
+	return a.Cmp(b)
+}
+
+// Equal reports whether Decimal s are numerically equal: 1.2 equals 1.20.
+func (d Decimal) Equal(other Decimal) bool {
+	return d.Cmp(other) == 0
+}
+
+// Float64 returns the nearest float64 of Decimal. It MAY lose precision,
+// so use it only when it's not important (e.g. for metrics).
+func (d Decimal) Float64() float64 {
+	f, _ := strconv.ParseFloat(d.String(), 64)
+	return f
+}
+
+// ---------------------------- DECIMAL ARITHMETIC ---------------------------- //
+// ---------------------------------------------------------------------------- //
+
+// Neg returns -d. Returns ErrDecimalOverflow if the coefficient is math.MinInt64.
+func (d Decimal) Neg() (Decimal, error) {
+	if d.coef == -d.coef && d.coef != 0 {
+		return Decimal{}, ErrDecimalOverflow
+	}
+	return Decimal{coef: -d.coef, scale: d.scale}, nil
+}
+
+// Abs returns |d|. Returns ErrDecimalOverflow if the coefficient is math.MinInt64.
+func (d Decimal) Abs() (Decimal, error) {
+	if d.coef < 0 {
+		return d.Neg()
+	}
+	return d, nil
+}
+
+// Add returns d + other with the greater scale of both.
+// Returns ErrDecimalOverflow if the result doesn't fit.
+func (d Decimal) Add(other Decimal) (Decimal, error) {
+	a, b := d.bigAligned(other)
+	return decimalFromBig(a.Add(a, b), decimalMaxScale(d, other))
+}
+
+// Sub returns d - other with the greater scale of both.
+// Returns ErrDecimalOverflow if the result doesn't fit.
+func (d Decimal) Sub(other Decimal) (Decimal, error) {
+	a, b := d.bigAligned(other)
+	return decimalFromBig(a.Sub(a, b), decimalMaxScale(d, other))
+}
+
+// Mul returns d * other with 'scale' rounded using 'mode' if it's necessary.
+// Returns ErrDecimalOverflow if the result doesn't fit
+// or ErrDecimalScale if 'scale' > DECIMAL_MAX_SCALE.
+func (d Decimal) Mul(other Decimal, scale uint8, mode DecimalRoundingMode) (Decimal, error) {
+
+	if scale > DECIMAL_MAX_SCALE {
+		return Decimal{}, ErrDecimalScale
+	}
+
+	num := new(big.Int).Mul(big.NewInt(d.coef), big.NewInt(other.coef))
+	return decimalRescaleBig(num, int(d.scale)+int(other.scale), scale, mode)
+}
+
+// Div returns d / other with 'scale' rounded using 'mode' if it's necessary.
+// Returns ErrDecimalDivisionByZero if 'other' is zero, ErrDecimalOverflow
+// if the result doesn't fit or ErrDecimalScale if 'scale' > DECIMAL_MAX_SCALE.
+func (d Decimal) Div(other Decimal, scale uint8, mode DecimalRoundingMode) (Decimal, error) {
+
+	switch {
+	case scale > DECIMAL_MAX_SCALE:
+		return Decimal{}, ErrDecimalScale
+	case other.coef == 0:
+		return Decimal{}, ErrDecimalDivisionByZero
+	}
+
+	// d / other = (d.coef / other.coef) * 10^(other.scale - d.scale),
+	// and it must be multiplied by 10^scale to get the result's coefficient.
+	num, den := big.NewInt(d.coef), big.NewInt(other.coef)
+	if exp := int(scale) + int(other.scale) - int(d.scale); exp >= 0 {
+		num.Mul(num, decimalPow10(exp))
+	} else {
+		den.Mul(den, decimalPow10(-exp))
+	}
+
+	return decimalFromBig(decimalDivRound(num, den, mode), scale)
+}
+
+// Round returns Decimal with 'scale', rounded using 'mode' if 'scale'
+// is less than the current one. Returns ErrDecimalOverflow if the result
+// doesn't fit or ErrDecimalScale if 'scale' > DECIMAL_MAX_SCALE.
+func (d Decimal) Round(scale uint8, mode DecimalRoundingMode) (Decimal, error) {
+	if scale > DECIMAL_MAX_SCALE {
+		return Decimal{}, ErrDecimalScale
+	}
+	return decimalRescaleBig(big.NewInt(d.coef), int(d.scale), scale, mode)
+}
+
+// ------------------------ DECIMAL TEXT ENCODER/DECODER ---------------------- //
+// ---------------------------------------------------------------------------- //
+
+// String returns Decimal's text representation with all digits of its scale:
+// "12.30", "-0.005", "42".
+func (d Decimal) String() string {
+	return string(d.appendTo(nil))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d Decimal) MarshalText() ([]byte, error) {
+	return d.appendTo(nil), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The input is expected in a form accepted by Decimal_FromString().
+func (d *Decimal) UnmarshalText(data []byte) (err error) {
+	*d, err = Decimal_FromString(string(data))
+	return err
+}
+
+// ------------------------ DECIMAL JSON ENCODER/DECODER ---------------------- //
+// ---------------------------------------------------------------------------- //
+
+// MarshalJSON implements the encoding/json.Marshaler interface.
+// Decimal is encoded as JSON string ("12.30") to not lose precision
+// by the JSON decoders that treat numbers as float64.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	b := append(make([]byte, 0, 24), '"')
+	return append(d.appendTo(b), '"'), nil
+}
+
+// UnmarshalJSON implements the encoding/json.Unmarshaler interface.
+// Both of JSON string and JSON number are supported. JSON null is ignored.
+func (d *Decimal) UnmarshalJSON(data []byte) error {
+
+	if len(data) == 0 || bytes.Equal(data, _UUID_JSON_NULL) {
+		return nil
+	}
+
+	if l := len(data); l >= 2 && data[0] == '"' && data[l-1] == '"' {
+		data = data[1 : l-1]
+	}
+
+	return d.UnmarshalText(data)
+}
+
+// ------------------------ DECIMAL SQL ENCODER/DECODER ----------------------- //
+// ---------------------------------------------------------------------------- //
+
+// Value implements the driver.Valuer interface.
+// Decimal is passed as string, so it's suitable for SQL NUMERIC/DECIMAL columns.
+func (d Decimal) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+// Supports string, []byte, int64 and SQL NULL (Decimal is unchanged then).
+func (d *Decimal) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+
+	case []byte:
+		return d.UnmarshalText(src)
+
+	case string:
+		return d.UnmarshalText([]byte(src))
+
+	case int64:
+		*d = Decimal_FromInt(src)
+		return nil
+	}
+
+	return fmt.Errorf("decimal: cannot convert %T to Decimal", src)
+}
+
+// ------------------------------- DECIMAL FIELD ------------------------------ //
+// ---------------------------------------------------------------------------- //
+
+// FDecimal constructs a field that holds on Decimal's text representation,
+// so the amount is logged w/o losing precision (unlike float64).
+func FDecimal(key string, value Decimal) ekaletter.LetterField {
+	return ekaletter.FString(key, value.String())
+}
+
+// ------------------------------ DECIMAL PRIVATE ----------------------------- //
+// ---------------------------------------------------------------------------- //
+
+// appendTo appends Decimal's text representation to 'b' and returns it.
+func (d Decimal) appendTo(b []byte) []byte {
+
+	u := uint64(d.coef)
+	if d.coef < 0 {
+		b = append(b, '-')
+		u = -u
+	}
+
+	digits := strconv.AppendUint(make([]byte, 0, 20), u, 10)
+	if d.scale == 0 {
+		return append(b, digits...)
+	}
+
+	// Leading zeros: 5 with scale 3 is "0.005".
+	if len(digits) <= int(d.scale) {
+		b = append(b, '0', '.')
+		for n := len(digits); n < int(d.scale); n++ {
+			b = append(b, '0')
+		}
+		return append(b, digits...)
+	}
+
+	i := len(digits) - int(d.scale)
+	b = append(b, digits[:i]...)
+	b = append(b, '.')
+	return append(b, digits[i:]...)
+}
+
+// bigAligned returns coefficients of both Decimal s as big.Int
+// rescaled to the greater scale of them.
+func (d Decimal) bigAligned(other Decimal) (a, b *big.Int) {
+	a, b = big.NewInt(d.coef), big.NewInt(other.coef)
+	switch {
+	case d.scale < other.scale:
+		a.Mul(a, decimalPow10(int(other.scale-d.scale)))
+	case d.scale > other.scale:
+		b.Mul(b, decimalPow10(int(d.scale-other.scale)))
+	}
+	return a, b
+}
+
+// decimalMaxScale returns the greater scale of Decimal s.
+func decimalMaxScale(a, b Decimal) uint8 {
+	if a.scale > b.scale {
+		return a.scale
+	}
+	return b.scale
+}
+
+// decimalIsDigits reports whether 's' contains ASCII digits only.
+func decimalIsDigits(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] < '0' || s[i] > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// decimalPow10 returns 10^n as big.Int.
+func decimalPow10(n int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(n)), nil)
+}
+
+// decimalFromBig returns Decimal with 'coef' and 'scale'
+// or ErrDecimalOverflow if 'coef' doesn't fit int64.
+func decimalFromBig(coef *big.Int, scale uint8) (Decimal, error) {
+	if !coef.IsInt64() {
+		return Decimal{}, ErrDecimalOverflow
+	}
+	return Decimal{coef: coef.Int64(), scale: scale}, nil
+}
+
+// decimalRescaleBig returns Decimal of 'coef' with scale 'from'
+// rescaled to the scale 'to' using 'mode'.
+func decimalRescaleBig(coef *big.Int, from int, to uint8, mode DecimalRoundingMode) (Decimal, error) {
+	switch {
+	case from < int(to):
+		coef.Mul(coef, decimalPow10(int(to)-from))
+	case from > int(to):
+		coef = decimalDivRound(coef, decimalPow10(from-int(to)), mode)
+	}
+	return decimalFromBig(coef, to)
+}
+
+// decimalDivRound returns num / den rounded using 'mode'. 'den' MUST NOT be zero.
+func decimalDivRound(num, den *big.Int, mode DecimalRoundingMode) *big.Int {
+
+	q, r := new(big.Int).QuoRem(num, den, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+
+	sign := int64(num.Sign() * den.Sign())
+
+	// Compare the remainder with the half of the divisor: 2|r| vs |den|.
+	r2 := new(big.Int).Abs(r)
+	half := r2.Lsh(r2, 1).Cmp(new(big.Int).Abs(den))
+
+	awayFromZero := false
+	switch mode {
+	case DECIMAL_ROUND_HALF_UP:
+		awayFromZero = half >= 0
+	case DECIMAL_ROUND_HALF_EVEN:
+		awayFromZero = half > 0 || half == 0 && q.Bit(0) == 1
+	case DECIMAL_ROUND_UP:
+		awayFromZero = true
+	case DECIMAL_ROUND_FLOOR:
+		awayFromZero = sign < 0
+	case DECIMAL_ROUND_CEIL:
+		awayFromZero = sign > 0
+	}
+
+	if awayFromZero {
+		q.Add(q, big.NewInt(sign))
+	}
+
+	return q
+}
@@ -0,0 +1,154 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecimal_FromString(t *testing.T) {
+
+	tests := []struct {
+		in, out string
+		coef    int64
+		scale   uint8
+	}{
+		{"12", "12", 12, 0},
+		{"-12.30", "-12.30", -1230, 2},
+		{"+0.005", "0.005", 5, 3},
+		{".5", "0.5", 5, 1},
+		{"7.", "7", 7, 0},
+		{"-0.10", "-0.10", -10, 2},
+	}
+
+	for _, test := range tests {
+		d, err := Decimal_FromString(test.in)
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.coef, d.Coef(), test.in)
+		assert.Equal(t, test.scale, d.Scale(), test.in)
+		assert.Equal(t, test.out, d.String(), test.in)
+	}
+
+	for _, in := range []string{"", "-", ".", "1.2.3", "1e5", "abc", " 1"} {
+		_, err := Decimal_FromString(in)
+		assert.True(t, errors.Is(err, ErrDecimalFormat), in)
+	}
+
+	_, err := Decimal_FromString("92233720368547758.08")
+	assert.True(t, errors.Is(err, ErrDecimalOverflow))
+
+	_, err = Decimal_FromString("0.1234567890123456789")
+	assert.True(t, errors.Is(err, ErrDecimalScale))
+}
+
+func TestDecimal_Arithmetic(t *testing.T) {
+
+	d := Decimal_FromString_OrPanic
+
+	sum, err := d("0.1").Add(d("0.20"))
+	require.NoError(t, err)
+	assert.Equal(t, "0.30", sum.String())
+
+	diff, err := d("1").Sub(d("2.5"))
+	require.NoError(t, err)
+	assert.Equal(t, "-1.5", diff.String())
+
+	_, err = Decimal_New(math.MaxInt64, 0).Add(Decimal_FromInt(1))
+	assert.Equal(t, ErrDecimalOverflow, err)
+
+	_, err = Decimal_New(math.MinInt64, 0).Neg()
+	assert.Equal(t, ErrDecimalOverflow, err)
+
+	prod, err := d("19.99").Mul(d("3"), 2, DECIMAL_ROUND_HALF_UP)
+	require.NoError(t, err)
+	assert.Equal(t, "59.97", prod.String())
+
+	quot, err := d("10").Div(d("3"), 4, DECIMAL_ROUND_HALF_UP)
+	require.NoError(t, err)
+	assert.Equal(t, "3.3333", quot.String())
+
+	quot, err = d("-2").Div(d("3"), 2, DECIMAL_ROUND_HALF_UP)
+	require.NoError(t, err)
+	assert.Equal(t, "-0.67", quot.String())
+
+	_, err = d("1").Div(d("0.00"), 2, DECIMAL_ROUND_HALF_UP)
+	assert.Equal(t, ErrDecimalDivisionByZero, err)
+
+	assert.True(t, d("1.2").Equal(d("1.200")))
+	assert.Equal(t, -1, d("-0.01").Cmp(d("0")))
+	assert.Equal(t, 1, d("2").Cmp(d("1.99")))
+}
+
+func TestDecimal_Round(t *testing.T) {
+
+	tests := []struct {
+		in   string
+		mode DecimalRoundingMode
+		out  string
+	}{
+		{"1.25", DECIMAL_ROUND_HALF_UP, "1.3"},
+		{"-1.25", DECIMAL_ROUND_HALF_UP, "-1.3"},
+		{"1.25", DECIMAL_ROUND_HALF_EVEN, "1.2"},
+		{"1.35", DECIMAL_ROUND_HALF_EVEN, "1.4"},
+		{"-1.25", DECIMAL_ROUND_HALF_EVEN, "-1.2"},
+		{"1.29", DECIMAL_ROUND_DOWN, "1.2"},
+		{"-1.29", DECIMAL_ROUND_DOWN, "-1.2"},
+		{"1.21", DECIMAL_ROUND_UP, "1.3"},
+		{"-1.21", DECIMAL_ROUND_UP, "-1.3"},
+		{"-1.21", DECIMAL_ROUND_FLOOR, "-1.3"},
+		{"1.29", DECIMAL_ROUND_FLOOR, "1.2"},
+		{"1.21", DECIMAL_ROUND_CEIL, "1.3"},
+		{"-1.29", DECIMAL_ROUND_CEIL, "-1.2"},
+		{"1.20", DECIMAL_ROUND_UP, "1.2"},
+	}
+
+	for _, test := range tests {
+		r, err := Decimal_FromString_OrPanic(test.in).Round(1, test.mode)
+		require.NoError(t, err)
+		assert.Equal(t, test.out, r.String(), "%s %d", test.in, test.mode)
+	}
+
+	r, err := Decimal_FromInt(5).Round(3, DECIMAL_ROUND_DOWN)
+	require.NoError(t, err)
+	assert.Equal(t, "5.000", r.String())
+
+	_, err = Decimal_FromInt(math.MaxInt64/10).Round(2, DECIMAL_ROUND_DOWN)
+	assert.Equal(t, ErrDecimalOverflow, err)
+}
+
+func TestDecimal_Marshal(t *testing.T) {
+
+	var v struct {
+		A Decimal
+		B Decimal
+		C Decimal
+	}
+
+	require.NoError(t, json.Unmarshal([]byte(`{"A":"12.30","B":-0.5,"C":null}`), &v))
+	assert.Equal(t, "12.30", v.A.String())
+	assert.Equal(t, "-0.5", v.B.String())
+	assert.True(t, v.C.IsZero())
+
+	b, err := json.Marshal(v)
+	require.NoError(t, err)
+	assert.Equal(t, `{"A":"12.30","B":"-0.5","C":"0"}`, string(b))
+
+	var d Decimal
+	require.NoError(t, d.Scan([]byte("100.01")))
+	value, err := d.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "100.01", value)
+
+	f := FDecimal("amount", d)
+	assert.Equal(t, "amount", f.Key)
+	assert.Equal(t, "100.01", f.SValue)
+}
@@ -0,0 +1,429 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Duration8601 is an ISO 8601 duration like "P1DT2H30M" or "-PT0.5S":
+	// "P[nY][nM][nW][nD][T[nH][nM][n[.f]S]]" with an optional leading sign.
+	//
+	// Unlike time.Duration, it keeps the components as is,
+	// so "P1M" is one calendar month, not some fixed number of hours.
+	// Because of that, the conversion to time.Duration requires the policy
+	// of years and months handling (see Duration()), but AddTo() is exact.
+	//
+	// Only seconds may have a fraction (up to nanoseconds, "." or "," separated).
+	// The zero Duration8601 is "PT0S".
+	Duration8601 struct {
+		Negative    bool
+		Years       uint64
+		Months      uint64
+		Weeks       uint64
+		Days        uint64
+		Hours       uint64
+		Minutes     uint64
+		Seconds     uint64
+		Nanoseconds uint32 // fraction of the second, [0..999999999]
+	}
+
+	// Duration8601CalendarPolicy is a way years and months of Duration8601
+	// are converted to time.Duration.
+	Duration8601CalendarPolicy uint8
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// DURATION8601_CALENDAR_REJECT makes Duration8601.Duration() to return
+	// ErrDuration8601Calendar if there are years or months,
+	// since their lengths are variable.
+	DURATION8601_CALENDAR_REJECT Duration8601CalendarPolicy = iota
+
+	// DURATION8601_CALENDAR_APPROXIMATE makes Duration8601.Duration()
+	// to treat a year as 365 days and a month as 30 days.
+	DURATION8601_CALENDAR_APPROXIMATE
+)
+
+var (
+	// ErrDuration8601Format is returned (wrapped) by Duration8601_FromString()
+	// if the input is not an ISO 8601 duration.
+	ErrDuration8601Format = errors.New("duration8601: incorrect ISO 8601 duration format")
+
+	// ErrDuration8601Overflow is returned if Duration8601's component
+	// or the result of its conversion to time.Duration doesn't fit.
+	ErrDuration8601Overflow = errors.New("duration8601: overflow")
+
+	// ErrDuration8601Calendar is returned by Duration8601.Duration()
+	// with DURATION8601_CALENDAR_REJECT policy if there are years or months.
+	ErrDuration8601Calendar = errors.New("duration8601: years and months can't be converted exactly")
+)
+
+// ------------------------- DURATION8601 CONSTRUCTORS ------------------------ //
+// ---------------------------------------------------------------------------- //
+
+// Duration8601_FromString returns Duration8601 parsed from ISO 8601 duration
+// like "P1Y2M", "P2W", "PT1H30M", "-P1DT0.5S". The components must go
+// in the order of the format, at least one of them is required.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func Duration8601_FromString(input string) (Duration8601, error) {
+
+	var d Duration8601
+	s := input
+
+	if len(s) > 0 && (s[0] == '-' || s[0] == '+') {
+		d.Negative = s[0] == '-'
+		s = s[1:]
+	}
+
+	if len(s) < 2 || s[0] != 'P' {
+		return Duration8601{}, fmt.Errorf("%w: %q", ErrDuration8601Format, input)
+	}
+	s = s[1:]
+
+	last, isTime := -1, false
+	for len(s) > 0 {
+
+		if s[0] == 'T' {
+			if isTime || len(s) == 1 {
+				return Duration8601{}, fmt.Errorf("%w: %q", ErrDuration8601Format, input)
+			}
+			isTime, s = true, s[1:]
+			continue
+		}
+
+		idx, value, frac, rest, ok := duration8601ParseComponent(s, isTime)
+		if !ok || idx <= last || frac != "" && idx != _DURATION8601_IDX_SECONDS {
+			return Duration8601{}, fmt.Errorf("%w: %q", ErrDuration8601Format, input)
+		}
+		last, s = idx, rest
+
+		n, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			return Duration8601{}, fmt.Errorf("%w: %q", ErrDuration8601Overflow, input)
+		}
+		*d.component(idx) = n
+
+		if frac != "" {
+			if len(frac) > 9 {
+				frac = frac[:9]
+			}
+			nanos, _ := strconv.ParseUint(frac+strings.Repeat("0", 9-len(frac)), 10, 32)
+			d.Nanoseconds = uint32(nanos)
+		}
+	}
+
+	if last == -1 {
+		return Duration8601{}, fmt.Errorf("%w: %q", ErrDuration8601Format, input)
+	}
+
+	return d, nil
+}
+
+// Duration8601_FromString_OrPanic is the same as Duration8601_FromString()
+// but panics if the input can't be parsed.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func Duration8601_FromString_OrPanic(input string) Duration8601 {
+	d, err := Duration8601_FromString(input)
+	if err != nil {
+		panic(err)
+	}
+	return d
+}
+
+// Duration8601_FromDuration returns Duration8601 of 'd' using hours, minutes
+// and seconds only, since days are not always 24 hours long:
+// 36h30m is "PT36H30M".
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func Duration8601_FromDuration(d time.Duration) Duration8601 {
+
+	var res Duration8601
+
+	u := uint64(d)
+	if d < 0 {
+		res.Negative = true
+		u = -u
+	}
+
+	res.Hours = u / uint64(time.Hour)
+	res.Minutes = u % uint64(time.Hour) / uint64(time.Minute)
+	res.Seconds = u % uint64(time.Minute) / uint64(time.Second)
+	res.Nanoseconds = uint32(u % uint64(time.Second))
+
+	return res
+}
+
+// ---------------------------- DURATION8601 METHODS -------------------------- //
+// ---------------------------------------------------------------------------- //
+
+// IsZero reports whether all Duration8601's components are zero.
+func (d Duration8601) IsZero() bool {
+	d.Negative = false
+	return d == Duration8601{}
+}
+
+// Duration returns time.Duration of Duration8601, treating a week as 7 days
+// and a day as 24 hours. Years and months are handled according to 'policy'.
+// Returns ErrDuration8601Overflow if the result doesn't fit time.Duration.
+//
+// Use AddTo() if you have a point in time the duration starts from.
+func (d Duration8601) Duration(policy Duration8601CalendarPolicy) (time.Duration, error) {
+
+	if (d.Years != 0 || d.Months != 0) && policy == DURATION8601_CALENDAR_REJECT {
+		return 0, ErrDuration8601Calendar
+	}
+
+	const day = 24 * time.Hour
+
+	var total uint64
+	for _, c := range [...]struct {
+		n    uint64
+		unit time.Duration
+	}{
+		{d.Years, 365 * day},
+		{d.Months, 30 * day},
+		{d.Weeks, 7 * day},
+		{d.Days, day},
+		{d.Hours, time.Hour},
+		{d.Minutes, time.Minute},
+		{d.Seconds, time.Second},
+		{uint64(d.Nanoseconds), time.Nanosecond},
+	} {
+		if c.n > (math.MaxInt64-total)/uint64(c.unit) {
+			return 0, ErrDuration8601Overflow
+		}
+		total += c.n * uint64(c.unit)
+	}
+
+	if d.Negative {
+		return -time.Duration(total), nil
+	}
+	return time.Duration(total), nil
+}
+
+// AddTo returns 't' + Duration8601 using calendar arithmetic
+// for years, months, weeks and days (see time.Time.AddDate())
+// and the exact one for the time components.
+// So, "P1M" added to January 31 is March 3 (or 2 in the leap year).
+func (d Duration8601) AddTo(t time.Time) time.Time {
+
+	sign := 1
+	if d.Negative {
+		sign = -1
+	}
+
+	t = t.AddDate(sign*int(d.Years), sign*int(d.Months), sign*int(d.Weeks*7+d.Days))
+
+	timePart := time.Duration(d.Hours)*time.Hour +
+		time.Duration(d.Minutes)*time.Minute +
+		time.Duration(d.Seconds)*time.Second +
+		time.Duration(d.Nanoseconds)
+
+	return t.Add(time.Duration(sign) * timePart)
+}
+
+// ----------------------- DURATION8601 TEXT ENCODER/DECODER ------------------ //
+// ---------------------------------------------------------------------------- //
+
+// String returns ISO 8601 representation of Duration8601 w/o zero components:
+// "P1DT2H30M", "-PT0.5S". The zero Duration8601 is "PT0S".
+func (d Duration8601) String() string {
+	return string(d.appendTo(make([]byte, 0, 32)))
+}
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (d Duration8601) MarshalText() ([]byte, error) {
+	return d.appendTo(make([]byte, 0, 32)), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// The input is expected in a form accepted by Duration8601_FromString().
+func (d *Duration8601) UnmarshalText(data []byte) (err error) {
+	*d, err = Duration8601_FromString(string(data))
+	return err
+}
+
+// ----------------------- DURATION8601 JSON ENCODER/DECODER ------------------ //
+// ---------------------------------------------------------------------------- //
+
+// MarshalJSON implements the encoding/json.Marshaler interface.
+// Duration8601 is encoded as JSON string ("P1DT2H").
+func (d Duration8601) MarshalJSON() ([]byte, error) {
+	b := append(make([]byte, 0, 34), '"')
+	return append(d.appendTo(b), '"'), nil
+}
+
+// UnmarshalJSON implements the encoding/json.Unmarshaler interface.
+// JSON null is ignored.
+func (d *Duration8601) UnmarshalJSON(data []byte) error {
+
+	if len(data) == 0 || bytes.Equal(data, _UUID_JSON_NULL) {
+		return nil
+	}
+
+	if l := len(data); l >= 2 && data[0] == '"' && data[l-1] == '"' {
+		data = data[1 : l-1]
+	}
+
+	return d.UnmarshalText(data)
+}
+
+// ----------------------- DURATION8601 SQL ENCODER/DECODER ------------------- //
+// ---------------------------------------------------------------------------- //
+
+// Value implements the driver.Valuer interface.
+// Duration8601 is passed as string, it's suitable for text columns
+// and PostgreSQL INTERVAL (that accepts ISO 8601 input).
+func (d Duration8601) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan implements the sql.Scanner interface.
+// Supports string, []byte and SQL NULL (Duration8601 is unchanged then).
+// To scan PostgreSQL INTERVAL, set "intervalstyle" to "iso_8601".
+func (d *Duration8601) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+
+	case []byte:
+		return d.UnmarshalText(src)
+
+	case string:
+		return d.UnmarshalText([]byte(src))
+	}
+
+	return fmt.Errorf("duration8601: cannot convert %T to Duration8601", src)
+}
+
+// ---------------------------- DURATION8601 FIELD ---------------------------- //
+// ---------------------------------------------------------------------------- //
+
+// FDuration8601 constructs a field that holds on Duration8601's
+// ISO 8601 representation.
+func FDuration8601(key string, value Duration8601) ekaletter.LetterField {
+	return ekaletter.FString(key, value.String())
+}
+
+// --------------------------- DURATION8601 PRIVATE --------------------------- //
+// ---------------------------------------------------------------------------- //
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// Indexes of Duration8601's components in the order of ISO 8601 format.
+	// Read more: Duration8601.component().
+
+	_DURATION8601_IDX_YEARS = iota
+	_DURATION8601_IDX_MONTHS
+	_DURATION8601_IDX_WEEKS
+	_DURATION8601_IDX_DAYS
+	_DURATION8601_IDX_HOURS
+	_DURATION8601_IDX_MINUTES
+	_DURATION8601_IDX_SECONDS
+)
+
+// component returns a pointer to Duration8601's component by its index.
+func (d *Duration8601) component(idx int) *uint64 {
+	return [...]*uint64{
+		&d.Years, &d.Months, &d.Weeks, &d.Days, &d.Hours, &d.Minutes, &d.Seconds,
+	}[idx]
+}
+
+// appendTo appends Duration8601's ISO 8601 representation to 'b' and returns it.
+func (d Duration8601) appendTo(b []byte) []byte {
+
+	if d.Negative && !d.IsZero() {
+		b = append(b, '-')
+	}
+	b = append(b, 'P')
+
+	appendComponent := func(n uint64, designator byte) {
+		if n != 0 {
+			b = strconv.AppendUint(b, n, 10)
+			b = append(b, designator)
+		}
+	}
+
+	appendComponent(d.Years, 'Y')
+	appendComponent(d.Months, 'M')
+	appendComponent(d.Weeks, 'W')
+	appendComponent(d.Days, 'D')
+
+	if d.Hours == 0 && d.Minutes == 0 && d.Seconds == 0 && d.Nanoseconds == 0 {
+		if d.IsZero() {
+			b = append(b, 'T', '0', 'S')
+		}
+		return b
+	}
+
+	b = append(b, 'T')
+	appendComponent(d.Hours, 'H')
+	appendComponent(d.Minutes, 'M')
+
+	if d.Seconds != 0 || d.Nanoseconds != 0 {
+		b = strconv.AppendUint(b, d.Seconds, 10)
+		if d.Nanoseconds != 0 {
+			frac := strconv.AppendUint(make([]byte, 0, 10), uint64(d.Nanoseconds)+1e9, 10)[1:]
+			b = append(b, '.')
+			b = append(b, bytes.TrimRight(frac, "0")...)
+		}
+		b = append(b, 'S')
+	}
+
+	return b
+}
+
+// duration8601ParseComponent parses a component like "12D" or "1.5S"
+// at the start of 's'. Returns its index (see _DURATION8601_IDX_ constants),
+// integer and fractional parts and the rest of 's'.
+// Time components are expected if 'isTime' is true, date ones otherwise.
+func duration8601ParseComponent(s string, isTime bool) (idx int, value, frac, rest string, ok bool) {
+
+	i := 0
+	for i < len(s) && s[i] >= '0' && s[i] <= '9' {
+		i++
+	}
+	if i == 0 {
+		return 0, "", "", "", false
+	}
+	value = s[:i]
+
+	if i < len(s) && (s[i] == '.' || s[i] == ',') {
+		j := i + 1
+		for j < len(s) && s[j] >= '0' && s[j] <= '9' {
+			j++
+		}
+		if j == i+1 {
+			return 0, "", "", "", false
+		}
+		frac, i = s[i+1:j], j
+	}
+
+	if i == len(s) {
+		return 0, "", "", "", false
+	}
+
+	designators, offset := "YMWD", _DURATION8601_IDX_YEARS
+	if isTime {
+		designators, offset = "HMS", _DURATION8601_IDX_HOURS
+	}
+
+	if idx = strings.IndexByte(designators, s[i]); idx == -1 {
+		return 0, "", "", "", false
+	}
+
+	return idx + offset, value, frac, s[i+1:], true
+}
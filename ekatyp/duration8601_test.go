@@ -0,0 +1,131 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDuration8601_FromString(t *testing.T) {
+
+	tests := []struct {
+		in, out string
+		d       Duration8601
+	}{
+		{"P1DT2H30M", "P1DT2H30M", Duration8601{Days: 1, Hours: 2, Minutes: 30}},
+		{"P1Y2M3W4DT5H6M7S", "P1Y2M3W4DT5H6M7S",
+			Duration8601{Years: 1, Months: 2, Weeks: 3, Days: 4, Hours: 5, Minutes: 6, Seconds: 7}},
+		{"-PT0.5S", "-PT0.5S", Duration8601{Negative: true, Nanoseconds: 5e8}},
+		{"+PT1,25S", "PT1.25S", Duration8601{Seconds: 1, Nanoseconds: 25e7}},
+		{"PT0.0000000019S", "PT0.000000001S", Duration8601{Nanoseconds: 1}},
+		{"P0D", "PT0S", Duration8601{}},
+		{"-PT0S", "PT0S", Duration8601{Negative: true}},
+		{"PT36H", "PT36H", Duration8601{Hours: 36}},
+		{"P1M", "P1M", Duration8601{Months: 1}},
+		{"PT1M", "PT1M", Duration8601{Minutes: 1}},
+	}
+
+	for _, test := range tests {
+		d, err := Duration8601_FromString(test.in)
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.d, d, test.in)
+		assert.Equal(t, test.out, d.String(), test.in)
+	}
+
+	for _, in := range []string{"", "P", "PT", "P1YT", "1D", "P1H", "PT1D", "P1D1Y",
+		"P1M1M", "P1.5D", "PT1.5M", "P-1D", "P1", "PD", "PT1.S", "p1d", " P1D"} {
+		_, err := Duration8601_FromString(in)
+		assert.True(t, errors.Is(err, ErrDuration8601Format), in)
+	}
+
+	_, err := Duration8601_FromString("P18446744073709551616D")
+	assert.True(t, errors.Is(err, ErrDuration8601Overflow))
+}
+
+func TestDuration8601_Duration(t *testing.T) {
+
+	d := Duration8601_FromString_OrPanic
+
+	got, err := d("P1DT2H30M").Duration(DURATION8601_CALENDAR_REJECT)
+	require.NoError(t, err)
+	assert.Equal(t, 26*time.Hour+30*time.Minute, got)
+
+	got, err = d("-P1WT0.5S").Duration(DURATION8601_CALENDAR_REJECT)
+	require.NoError(t, err)
+	assert.Equal(t, -(7*24*time.Hour + 500*time.Millisecond), got)
+
+	_, err = d("P1M").Duration(DURATION8601_CALENDAR_REJECT)
+	assert.Equal(t, ErrDuration8601Calendar, err)
+
+	got, err = d("P1Y1M").Duration(DURATION8601_CALENDAR_APPROXIMATE)
+	require.NoError(t, err)
+	assert.Equal(t, 395*24*time.Hour, got)
+
+	_, err = d("P1000Y").Duration(DURATION8601_CALENDAR_APPROXIMATE)
+	assert.Equal(t, ErrDuration8601Overflow, err)
+
+	for _, td := range []time.Duration{0, time.Nanosecond, 36*time.Hour + 30*time.Minute + 1500*time.Millisecond, -time.Minute} {
+		got, err = Duration8601_FromDuration(td).Duration(DURATION8601_CALENDAR_REJECT)
+		require.NoError(t, err)
+		assert.Equal(t, td, got)
+	}
+
+	assert.Equal(t, "PT36H30M1.5S", Duration8601_FromDuration(36*time.Hour+30*time.Minute+1500*time.Millisecond).String())
+}
+
+func TestDuration8601_AddTo(t *testing.T) {
+
+	d := Duration8601_FromString_OrPanic
+	start := time.Date(2021, time.January, 31, 10, 0, 0, 0, time.UTC)
+
+	assert.Equal(t, time.Date(2021, time.March, 3, 10, 0, 0, 0, time.UTC), d("P1M").AddTo(start))
+	assert.Equal(t, time.Date(2022, time.February, 7, 12, 30, 0, 0, time.UTC), d("P1Y1WT2H30M").AddTo(start))
+	assert.Equal(t, time.Date(2021, time.January, 30, 9, 59, 59, 0, time.UTC), d("-P1DT1S").AddTo(start))
+}
+
+func TestDuration8601_JSON(t *testing.T) {
+
+	type T struct {
+		D Duration8601  `json:"d"`
+		P *Duration8601 `json:"p"`
+	}
+
+	in := T{D: Duration8601_FromString_OrPanic("P1DT2H")}
+
+	data, err := json.Marshal(in)
+	require.NoError(t, err)
+	assert.Equal(t, `{"d":"P1DT2H","p":null}`, string(data))
+
+	var out T
+	require.NoError(t, json.Unmarshal(data, &out))
+	assert.Equal(t, in, out)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"d":"1 day"}`), &out))
+}
+
+func TestDuration8601_SQL(t *testing.T) {
+
+	d := Duration8601_FromString_OrPanic("PT1H")
+
+	v, err := d.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "PT1H", v)
+
+	var scanned Duration8601
+	require.NoError(t, scanned.Scan([]byte("P2D")))
+	assert.Equal(t, "P2D", scanned.String())
+
+	require.NoError(t, scanned.Scan(nil))
+	assert.Equal(t, "P2D", scanned.String())
+
+	assert.Error(t, scanned.Scan(42))
+}
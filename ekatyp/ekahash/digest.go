@@ -0,0 +1,238 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekahash
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash"
+	"io"
+	"math"
+
+	"github.com/qioalice/ekago/v3/ekastr"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Digest is a streaming XXH3 64 bit hash. The hash of data written
+	// to the Digest by any number of Write(), WriteString() calls
+	// is the same as XXH3WithSeed() of that data at once.
+	//
+	// Digest implements hash.Hash64 and io.StringWriter.
+	// It doesn't allocate, so it may be placed on the stack.
+	// Digest is not safe for concurrent use.
+	//
+	// The zero Digest is ready to use, its seed is 0.
+	Digest struct {
+		acc      xxh3Acc
+		secret   [_XXH3_SECRET_SIZE]byte
+		buf      [_DIGEST_BUFFER_SIZE]byte
+		seed     uint64
+		total    uint64
+		buffered int
+		stripes  int // stripes of the current block are processed so far
+		isInited bool
+	}
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// _DIGEST_BUFFER_SIZE is the size of the Digest's internal buffer.
+	// It MUST be a multiple of _XXH3_STRIPE_LEN and greater than _XXH3_MIDSIZE_MAX.
+	_DIGEST_BUFFER_SIZE    = 256
+	_DIGEST_BUFFER_STRIPES = _DIGEST_BUFFER_SIZE / _XXH3_STRIPE_LEN
+)
+
+var (
+	// Make sure we won't break API.
+	_ hash.Hash64     = (*Digest)(nil)
+	_ io.StringWriter = (*Digest)(nil)
+)
+
+// NewDigest returns a new Digest with the seed 0.
+func NewDigest() *Digest {
+	return NewDigestWithSeed(0)
+}
+
+// NewDigestWithSeed returns a new Digest with provided 'seed'.
+func NewDigestWithSeed(seed uint64) *Digest {
+	d := new(Digest)
+	d.ResetWithSeed(seed)
+	return d
+}
+
+// Reset resets the Digest to its initial state keeping its seed.
+func (d *Digest) Reset() {
+	d.ResetWithSeed(d.seed)
+}
+
+// ResetWithSeed resets the Digest to its initial state with provided 'seed'.
+func (d *Digest) ResetWithSeed(seed uint64) {
+
+	d.acc = xxh3AccInit
+	d.total, d.buffered, d.stripes = 0, 0, 0
+
+	if !d.isInited || d.seed != seed {
+		if seed == 0 {
+			d.secret = xxh3Secret
+		} else {
+			xxh3CustomSecret(&d.secret, seed)
+		}
+		d.seed, d.isInited = seed, true
+	}
+}
+
+// Size returns the number of bytes Sum() appends (8).
+func (d *Digest) Size() int {
+	return 8
+}
+
+// BlockSize returns the Digest's block size (64).
+func (d *Digest) BlockSize() int {
+	return _XXH3_STRIPE_LEN
+}
+
+// Write adds 'b' to the Digest. It never returns an error.
+func (d *Digest) Write(b []byte) (int, error) {
+
+	if !d.isInited {
+		d.ResetWithSeed(0)
+	}
+
+	n := len(b)
+	d.total += uint64(n)
+
+	// The data is processed only if there is more data after it,
+	// so the last (up to _DIGEST_BUFFER_SIZE) bytes are always buffered
+	// for Sum64().
+
+	if d.buffered+n <= _DIGEST_BUFFER_SIZE {
+		d.buffered += copy(d.buf[d.buffered:], b)
+		return n, nil
+	}
+
+	if d.buffered > 0 {
+		filled := copy(d.buf[d.buffered:], b)
+		b = b[filled:]
+		d.consume(d.buf[:], _DIGEST_BUFFER_STRIPES)
+		d.buffered = 0
+	}
+
+	if len(b) > _DIGEST_BUFFER_SIZE {
+		var lastStripe []byte
+		for len(b) > _DIGEST_BUFFER_SIZE {
+			d.consume(b, _DIGEST_BUFFER_STRIPES)
+			lastStripe = b[_DIGEST_BUFFER_SIZE-_XXH3_STRIPE_LEN : _DIGEST_BUFFER_SIZE]
+			b = b[_DIGEST_BUFFER_SIZE:]
+		}
+		// The last processed stripe may be required by Sum64().
+		copy(d.buf[_DIGEST_BUFFER_SIZE-_XXH3_STRIPE_LEN:], lastStripe)
+	}
+
+	d.buffered = copy(d.buf[:], b)
+	return n, nil
+}
+
+// WriteString adds 's' to the Digest. It never returns an error.
+func (d *Digest) WriteString(s string) (int, error) {
+
+	// It's safe, Write() never modifies nor holds passed bytes.
+	return d.Write(ekastr.S2B(s))
+}
+
+// WriteUint64 adds 8 bytes of 'v' (little endian) to the Digest.
+func (d *Digest) WriteUint64(v uint64) {
+	var b [8]byte
+	binary.LittleEndian.PutUint64(b[:], v)
+	_, _ = d.Write(b[:])
+}
+
+// WriteFields adds the keys, kinds and values of 'fields' to the Digest.
+// Read more: HashFields().
+func (d *Digest) WriteFields(fields []ekaletter.LetterField) {
+	for i, n := 0, len(fields); i < n; i++ {
+		f := &fields[i]
+		if f.IsSystem() {
+			continue
+		}
+		_, _ = d.WriteString(f.Key)
+		d.WriteUint64(uint64(f.Kind))
+		d.WriteUint64(uint64(f.IValue))
+		_, _ = d.WriteString(f.SValue)
+		if f.Value != nil {
+			_, _ = d.WriteString(fmt.Sprint(f.Value))
+		}
+		// The separator: the concatenated keys and values of the different
+		// fields must not give the same hash.
+		d.WriteUint64(math.MaxUint64)
+	}
+}
+
+// Sum appends the big endian bytes of Sum64() to 'b' and returns it.
+func (d *Digest) Sum(b []byte) []byte {
+	return AppendUint64(b, d.Sum64())
+}
+
+// Sum64 returns the XXH3 64 bit hash of the data added so far.
+// It doesn't change the state of the Digest.
+func (d *Digest) Sum64() uint64 {
+
+	if !d.isInited {
+		d.ResetWithSeed(0)
+	}
+
+	if d.total <= _XXH3_MIDSIZE_MAX {
+		return xxh3(d.buf[:d.total], d.seed, xxh3Secret[:])
+	}
+
+	var (
+		acc       = d.acc
+		stripes   = d.stripes
+		lastBytes []byte
+	)
+
+	if d.buffered >= _XXH3_STRIPE_LEN {
+		nStripes := (d.buffered - 1) / _XXH3_STRIPE_LEN
+		d.consumeTo(&acc, &stripes, d.buf[:], nStripes)
+		lastBytes = d.buf[d.buffered-_XXH3_STRIPE_LEN : d.buffered]
+	} else {
+		// The last stripe is made of the end of the previously processed data
+		// and the buffered bytes.
+		var lastStripe [_XXH3_STRIPE_LEN]byte
+		catchup := _XXH3_STRIPE_LEN - d.buffered
+		copy(lastStripe[:], d.buf[_DIGEST_BUFFER_SIZE-catchup:])
+		copy(lastStripe[catchup:], d.buf[:d.buffered])
+		lastBytes = lastStripe[:]
+	}
+
+	acc.accumulate512(lastBytes,
+		d.secret[_XXH3_SECRET_SIZE-_XXH3_STRIPE_LEN-_XXH3_SECRET_LASTACC_START:])
+
+	return acc.merge(d.secret[_XXH3_SECRET_MERGEACCS_START:], d.total*_XXH_PRIME64_1)
+}
+
+// consume processes 'nStripes' stripes of 'b' updating the Digest's state.
+func (d *Digest) consume(b []byte, nStripes int) {
+	d.consumeTo(&d.acc, &d.stripes, b, nStripes)
+}
+
+// consumeTo processes 'nStripes' stripes of 'b' using 'acc' and the number
+// of processed stripes of the current block 'stripes',
+// scrambling 'acc' at the end of block.
+func (d *Digest) consumeTo(acc *xxh3Acc, stripes *int, b []byte, nStripes int) {
+
+	secret := d.secret[:]
+
+	if toEnd := _XXH3_STRIPES_PER_BLOCK - *stripes; toEnd <= nStripes {
+		acc.accumulate(b, secret[*stripes*_XXH3_SECRET_CONSUME_RATE:], toEnd)
+		acc.scramble(secret[_XXH3_SECRET_SIZE-_XXH3_STRIPE_LEN:])
+		acc.accumulate(b[toEnd*_XXH3_STRIPE_LEN:], secret, nStripes-toEnd)
+		*stripes = nStripes - toEnd
+	} else {
+		acc.accumulate(b, secret[*stripes*_XXH3_SECRET_CONSUME_RATE:], nStripes)
+		*stripes += nStripes
+	}
+}
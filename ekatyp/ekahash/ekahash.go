@@ -0,0 +1,127 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+/*
+Package ekahash provides stable non-cryptographic hash helpers:
+XXH3 (64 bit) and FNV-1a (64 bit) over bytes and strings without allocations,
+the streaming XXH3 Digest and HashFields(), that hashes ekaletter.LetterField
+(the fields of ekalog.Entry and ekaerr.Error) for deduplication, sampling
+and fingerprints.
+
+The hashes are stable: they are the same across processes, platforms
+and versions (XXH3 matches the reference xxHash implementation).
+DO NOT use them for security purposes.
+*/
+package ekahash
+
+import (
+	"encoding/binary"
+
+	"github.com/qioalice/ekago/v3/ekastr"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	// FNV1A_OFFSET is the initial value of 64 bit FNV-1a hash.
+	// Read more: FNV1aUpdate().
+	FNV1A_OFFSET uint64 = 0xCBF29CE484222325
+
+	// FNV1A_PRIME is the prime of 64 bit FNV-1a hash.
+	FNV1A_PRIME uint64 = 0x00000100000001B3
+)
+
+// XXH3 returns XXH3 64 bit hash of 'b' with the seed 0.
+func XXH3(b []byte) uint64 {
+	return xxh3(b, 0, xxh3Secret[:])
+}
+
+// XXH3String returns XXH3 64 bit hash of 's' with the seed 0.
+func XXH3String(s string) uint64 {
+	return XXH3(ekastr.S2B(s))
+}
+
+// XXH3WithSeed returns XXH3 64 bit hash of 'b' with provided 'seed'.
+func XXH3WithSeed(b []byte, seed uint64) uint64 {
+
+	if seed == 0 || len(b) <= _XXH3_MIDSIZE_MAX {
+		return xxh3(b, seed, xxh3Secret[:])
+	}
+
+	var secret [_XXH3_SECRET_SIZE]byte
+	xxh3CustomSecret(&secret, seed)
+
+	return xxh3Long(b, secret[:])
+}
+
+// XXH3StringWithSeed returns XXH3 64 bit hash of 's' with provided 'seed'.
+func XXH3StringWithSeed(s string, seed uint64) uint64 {
+	return XXH3WithSeed(ekastr.S2B(s), seed)
+}
+
+// FNV1a returns 64 bit FNV-1a hash of 'b'.
+// It's the same as hash/fnv.New64a() gives, but w/o allocations.
+func FNV1a(b []byte) uint64 {
+	return FNV1aUpdate(FNV1A_OFFSET, b)
+}
+
+// FNV1aString returns 64 bit FNV-1a hash of 's'.
+func FNV1aString(s string) uint64 {
+	return FNV1aUpdateString(FNV1A_OFFSET, s)
+}
+
+// FNV1aUpdate returns 64 bit FNV-1a hash 'h' updated by 'b',
+// so the data may be hashed by parts:
+//
+//	h := FNV1aUpdate(FNV1A_OFFSET, part1)
+//	h = FNV1aUpdate(h, part2) // == FNV1a(part1 + part2)
+func FNV1aUpdate(h uint64, b []byte) uint64 {
+	for _, c := range b {
+		h ^= uint64(c)
+		h *= FNV1A_PRIME
+	}
+	return h
+}
+
+// FNV1aUpdateString returns 64 bit FNV-1a hash 'h' updated by 's'.
+// Read more: FNV1aUpdate().
+func FNV1aUpdateString(h uint64, s string) uint64 {
+	for i := 0; i < len(s); i++ {
+		h ^= uint64(s[i])
+		h *= FNV1A_PRIME
+	}
+	return h
+}
+
+// HashFields returns XXH3 64 bit hash of the keys, kinds and values of 'fields'
+// (in order they are presented). System fields are skipped.
+//
+// The values of complex fields (arrays, maps, structs) are hashed
+// using their fmt.Sprint() representation, so they are allocated.
+func HashFields(fields []ekaletter.LetterField) uint64 {
+	var d Digest
+	d.WriteFields(fields)
+	return d.Sum64()
+}
+
+// AppendUint64 appends 8 bytes of 'h' (big endian) to 'to' and returns it.
+// It's the same as hash.Hash64's Sum() does.
+func AppendUint64(to []byte, h uint64) []byte {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], h)
+	return append(to, b[:]...)
+}
+
+// AppendHex appends 16 lowercase hex chars of 'h' (zero-padded) to 'to'
+// and returns it.
+func AppendHex(to []byte, h uint64) []byte {
+	const digits = "0123456789abcdef"
+	var b [16]byte
+	for i := 15; i >= 0; i-- {
+		b[i] = digits[h&0xF]
+		h >>= 4
+	}
+	return append(to, b[:]...)
+}
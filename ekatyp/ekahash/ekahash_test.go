@@ -0,0 +1,174 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekahash_test
+
+import (
+	"hash/fnv"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekatyp/ekahash"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testSeed = 0x9E3779B97F4A7C15
+
+// Generated by the reference xxHash implementation.
+var testVectors = []struct {
+	n          int
+	hash, seed uint64
+}{
+	{0, 0x2d06800538d394c2, 0x602b0e2cd6662c8b},
+	{1, 0xc44bdff4074eecdb, 0x062b185e4e01441a},
+	{2, 0x9093381c8763d62e, 0x86297ce74957599a},
+	{3, 0xc3489259e968ad9e, 0x71a5f088b9bf6b14},
+	{4, 0xd3d60c1519014e89, 0x725545a3f20014ce},
+	{5, 0x559935c0f3f7327f, 0xb0dd05eaf3656bb3},
+	{8, 0xb88dee77f6bf6980, 0x3f5da5b7ad256de3},
+	{9, 0x03688dcad730d826, 0xc332deb897105a63},
+	{16, 0x907976bb290db9e8, 0x12ce397a25d17e12},
+	{17, 0xac1710d495aa0dbd, 0xa24aed278654ee40},
+	{31, 0x276f3be6427483fd, 0x1825d6ed02422941},
+	{32, 0xf7cc327dd6d62aa4, 0x0deae09c2dcb8fdf},
+	{33, 0x87e5d124ba27e651, 0xbc993db9033c7671},
+	{64, 0x459c33aa4df12e7d, 0x060c4e78c95ab66f},
+	{65, 0xde4a0cca5f944227, 0x35ce84525601f8d3},
+	{96, 0xb318c1b5cbeaa3ab, 0xf8aad3f2ee965b76},
+	{97, 0x2c0a0fa926578070, 0x36d2f47062b3f920},
+	{127, 0x6bc377c3204d295d, 0x2d35ab0f00866c44},
+	{128, 0xe872377f5593aa83, 0x1a8aadfbbc071446},
+	{129, 0x9b55151daa76e2cf, 0xad368ef0b6f5771f},
+	{200, 0x3f99fc17fcc9950d, 0x2f20c6b428b9cdc8},
+	{239, 0xcecd815d0a62f147, 0x869b3fc1afb96ae3},
+	{240, 0x28067121726fa14e, 0x19e46351f2930524},
+	{241, 0x26e9e1d1ee797db1, 0xfc5d5e1b7715f488},
+	{255, 0xf89dea99feca24b7, 0x8f7ffa7d574b5cc9},
+	{256, 0x4919681d0579cded, 0x6a44d820eb419677},
+	{257, 0xac51506968fe057f, 0x0c8cf89334f242d3},
+	{500, 0x4b65d32680bbd6e6, 0x82e6d5a6f42e8794},
+	{1023, 0x00ef093e5c3df5dd, 0xaaf592db3234d68d},
+	{1024, 0x26898b5f48a4fda8, 0xddf7f71b08597450},
+	{1025, 0x929f393174c68e11, 0x7e98241ceb40a9da},
+	{2048, 0xfc8a3d83d69fd599, 0x002440583b579576},
+	{2049, 0x0fa11af08a70fd56, 0x78a4218580893686},
+	{3000, 0x5833b363cc6b0da4, 0xbd194d37af110433},
+	{4096, 0x093c4132eb522ea7, 0x3997996aac31832c},
+}
+
+func testData(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i*7 + i/13)
+	}
+	return b
+}
+
+func TestXXH3(t *testing.T) {
+	for _, v := range testVectors {
+		b := testData(v.n)
+		assert.Equal(t, v.hash, ekahash.XXH3(b), "len: %d", v.n)
+		assert.Equal(t, v.hash, ekahash.XXH3String(string(b)), "len: %d", v.n)
+		assert.Equal(t, v.seed, ekahash.XXH3WithSeed(b, testSeed), "len: %d", v.n)
+		assert.Equal(t, v.seed, ekahash.XXH3StringWithSeed(string(b), testSeed), "len: %d", v.n)
+	}
+}
+
+func TestDigest(t *testing.T) {
+	for _, v := range testVectors {
+		b := testData(v.n)
+		for _, chunk := range []int{1, 7, 63, 64, 65, 255, 256, 257, 1000} {
+
+			d := ekahash.NewDigest()
+			ds := ekahash.NewDigestWithSeed(testSeed)
+
+			for rest := b; len(rest) > 0; {
+				n := chunk
+				if n > len(rest) {
+					n = len(rest)
+				}
+				_, _ = d.Write(rest[:n])
+				_, _ = ds.WriteString(string(rest[:n]))
+				rest = rest[n:]
+			}
+
+			assert.Equal(t, v.hash, d.Sum64(), "len: %d, chunk: %d", v.n, chunk)
+			assert.Equal(t, v.seed, ds.Sum64(), "len: %d, chunk: %d", v.n, chunk)
+
+			// Sum64() must not change the state.
+			assert.Equal(t, v.hash, d.Sum64(), "len: %d, chunk: %d", v.n, chunk)
+		}
+	}
+}
+
+func TestDigest_Reset(t *testing.T) {
+
+	var d ekahash.Digest // zero value is ready to use
+	_, _ = d.WriteString("some data, that will be dropped")
+	d.Reset()
+
+	b := testData(1024)
+	_, _ = d.Write(b)
+	assert.Equal(t, ekahash.XXH3(b), d.Sum64())
+	assert.Equal(t, ekahash.AppendUint64(nil, ekahash.XXH3(b)), d.Sum(nil))
+
+	d.ResetWithSeed(testSeed)
+	_, _ = d.Write(b)
+	assert.Equal(t, ekahash.XXH3WithSeed(b, testSeed), d.Sum64())
+}
+
+func TestFNV1a(t *testing.T) {
+	for _, n := range []int{0, 1, 13, 100} {
+		b := testData(n)
+
+		h := fnv.New64a()
+		_, _ = h.Write(b)
+
+		assert.Equal(t, h.Sum64(), ekahash.FNV1a(b))
+		assert.Equal(t, h.Sum64(), ekahash.FNV1aString(string(b)))
+		assert.Equal(t, h.Sum64(),
+			ekahash.FNV1aUpdateString(ekahash.FNV1aUpdate(ekahash.FNV1A_OFFSET, b[:n/2]), string(b[n/2:])))
+	}
+}
+
+func TestAppendHex(t *testing.T) {
+	assert.Equal(t, "0000000000000000", string(ekahash.AppendHex(nil, 0)))
+	assert.Equal(t, "x:00000000deadbeef", string(ekahash.AppendHex([]byte("x:"), 0xDEADBEEF)))
+	assert.Equal(t, "ffffffffffffffff", string(ekahash.AppendHex(nil, 1<<64-1)))
+}
+
+func TestHashFields(t *testing.T) {
+
+	f1 := []ekaletter.LetterField{ekaletter.FString("ab", "c"), ekaletter.FInt("n", 42)}
+	f2 := []ekaletter.LetterField{ekaletter.FString("ab", "c"), ekaletter.FInt("n", 42)}
+
+	assert.Equal(t, ekahash.HashFields(f1), ekahash.HashFields(f2))
+
+	// Order, values and key-value boundaries matter.
+	assert.NotEqual(t, ekahash.HashFields(f1),
+		ekahash.HashFields([]ekaletter.LetterField{f1[1], f1[0]}))
+	assert.NotEqual(t, ekahash.HashFields(f1),
+		ekahash.HashFields([]ekaletter.LetterField{ekaletter.FString("ab", "c"), ekaletter.FInt("n", 43)}))
+	assert.NotEqual(t,
+		ekahash.HashFields([]ekaletter.LetterField{ekaletter.FString("ab", "c")}),
+		ekahash.HashFields([]ekaletter.LetterField{ekaletter.FString("a", "bc")}))
+}
+
+func TestHashAllocs(t *testing.T) {
+
+	b := testData(1000)
+	fields := []ekaletter.LetterField{ekaletter.FString("key", "value"), ekaletter.FInt("n", 1)}
+
+	assert.Zero(t, testing.AllocsPerRun(10, func() {
+		_ = ekahash.XXH3WithSeed(b, testSeed)
+		_ = ekahash.FNV1aString("some string")
+		_ = ekahash.HashFields(fields)
+
+		var d ekahash.Digest
+		_, _ = d.Write(b)
+		_ = d.Sum64()
+	}))
+}
@@ -0,0 +1,240 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekahash
+
+import (
+	"encoding/binary"
+	"math/bits"
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	_XXH_PRIME32_1 = 0x9E3779B1
+	_XXH_PRIME32_2 = 0x85EBCA77
+	_XXH_PRIME32_3 = 0xC2B2AE3D
+
+	_XXH_PRIME64_1 = 0x9E3779B185EBCA87
+	_XXH_PRIME64_2 = 0xC2B2AE3D27D4EB4F
+	_XXH_PRIME64_3 = 0x165667B19E3779F9
+	_XXH_PRIME64_4 = 0x85EBCA77C2B2AE63
+	_XXH_PRIME64_5 = 0x27D4EB2F165667C5
+
+	_XXH_PRIME_MX1 = 0x165667919E3779F9
+	_XXH_PRIME_MX2 = 0x9FB21C651E98DF25
+
+	_XXH3_SECRET_SIZE            = 192
+	_XXH3_SECRET_SIZE_MIN        = 136
+	_XXH3_STRIPE_LEN             = 64
+	_XXH3_SECRET_CONSUME_RATE    = 8
+	_XXH3_STRIPES_PER_BLOCK      = (_XXH3_SECRET_SIZE - _XXH3_STRIPE_LEN) / _XXH3_SECRET_CONSUME_RATE
+	_XXH3_BLOCK_LEN              = _XXH3_STRIPE_LEN * _XXH3_STRIPES_PER_BLOCK
+	_XXH3_SECRET_LASTACC_START   = 7
+	_XXH3_SECRET_MERGEACCS_START = 11
+	_XXH3_MIDSIZE_MAX            = 240
+	_XXH3_MIDSIZE_STARTOFFSET    = 3
+	_XXH3_MIDSIZE_LASTOFFSET     = 17
+)
+
+// xxh3Secret is the default secret of XXH3.
+var xxh3Secret = [_XXH3_SECRET_SIZE]byte{
+	0xb8, 0xfe, 0x6c, 0x39, 0x23, 0xa4, 0x4b, 0xbe, 0x7c, 0x01, 0x81, 0x2c, 0xf7, 0x21, 0xad, 0x1c,
+	0xde, 0xd4, 0x6d, 0xe9, 0x83, 0x90, 0x97, 0xdb, 0x72, 0x40, 0xa4, 0xa4, 0xb7, 0xb3, 0x67, 0x1f,
+	0xcb, 0x79, 0xe6, 0x4e, 0xcc, 0xc0, 0xe5, 0x78, 0x82, 0x5a, 0xd0, 0x7d, 0xcc, 0xff, 0x72, 0x21,
+	0xb8, 0x08, 0x46, 0x74, 0xf7, 0x43, 0x24, 0x8e, 0xe0, 0x35, 0x90, 0xe6, 0x81, 0x3a, 0x26, 0x4c,
+	0x3c, 0x28, 0x52, 0xbb, 0x91, 0xc3, 0x00, 0xcb, 0x88, 0xd0, 0x65, 0x8b, 0x1b, 0x53, 0x2e, 0xa3,
+	0x71, 0x64, 0x48, 0x97, 0xa2, 0x0d, 0xf9, 0x4e, 0x38, 0x19, 0xef, 0x46, 0xa9, 0xde, 0xac, 0xd8,
+	0xa8, 0xfa, 0x76, 0x3f, 0xe3, 0x9c, 0x34, 0x3f, 0xf9, 0xdc, 0xbb, 0xc7, 0xc7, 0x0b, 0x4f, 0x1d,
+	0x8a, 0x51, 0xe0, 0x4b, 0xcd, 0xb4, 0x59, 0x31, 0xc8, 0x9f, 0x7e, 0xc9, 0xd9, 0x78, 0x73, 0x64,
+	0xea, 0xc5, 0xac, 0x83, 0x34, 0xd3, 0xeb, 0xc3, 0xc5, 0x81, 0xa0, 0xff, 0xfa, 0x13, 0x63, 0xeb,
+	0x17, 0x0d, 0xdd, 0x51, 0xb7, 0xf0, 0xda, 0x49, 0xd3, 0x16, 0x55, 0x26, 0x29, 0xd4, 0x68, 0x9e,
+	0x2b, 0x16, 0xbe, 0x58, 0x7d, 0x47, 0xa1, 0xfc, 0x8f, 0xf8, 0xb8, 0xd1, 0x7a, 0xd0, 0x31, 0xce,
+	0x45, 0xcb, 0x3a, 0x8f, 0x95, 0x16, 0x04, 0x28, 0xaf, 0xd7, 0xfb, 0xca, 0xbb, 0x4b, 0x40, 0x7e,
+}
+
+// xxh3Acc is the accumulators of XXH3 for the long inputs.
+type xxh3Acc [8]uint64
+
+// xxh3AccInit is the initial value of xxh3Acc.
+var xxh3AccInit = xxh3Acc{
+	_XXH_PRIME32_3, _XXH_PRIME64_1, _XXH_PRIME64_2, _XXH_PRIME64_3,
+	_XXH_PRIME64_4, _XXH_PRIME32_2, _XXH_PRIME64_5, _XXH_PRIME32_1,
+}
+
+func readLE32(b []byte) uint64 { return uint64(binary.LittleEndian.Uint32(b)) }
+func readLE64(b []byte) uint64 { return binary.LittleEndian.Uint64(b) }
+
+// mulFold64 returns the XOR of the high and low halves
+// of the 128 bit product of 'a' and 'b'.
+func mulFold64(a, b uint64) uint64 {
+	hi, lo := bits.Mul64(a, b)
+	return hi ^ lo
+}
+
+func xxh64Avalanche(h uint64) uint64 {
+	h ^= h >> 33
+	h *= _XXH_PRIME64_2
+	h ^= h >> 29
+	h *= _XXH_PRIME64_3
+	h ^= h >> 32
+	return h
+}
+
+func xxh3Avalanche(h uint64) uint64 {
+	h ^= h >> 37
+	h *= _XXH_PRIME_MX1
+	h ^= h >> 32
+	return h
+}
+
+func xxh3Rrmxmx(h uint64, n int) uint64 {
+	h ^= bits.RotateLeft64(h, 49) ^ bits.RotateLeft64(h, 24)
+	h *= _XXH_PRIME_MX2
+	h ^= (h >> 35) + uint64(n)
+	h *= _XXH_PRIME_MX2
+	h ^= h >> 28
+	return h
+}
+
+func xxh3Mix16(b, secret []byte, seed uint64) uint64 {
+	return mulFold64(
+		readLE64(b)^(readLE64(secret)+seed),
+		readLE64(b[8:])^(readLE64(secret[8:])-seed),
+	)
+}
+
+// xxh3 returns XXH3 64 bit hash of 'b' using 'secret', that is derived
+// from 'seed' (the custom secret is used only for the long inputs).
+func xxh3(b []byte, seed uint64, secret []byte) uint64 {
+
+	n := len(b)
+
+	switch {
+	case n == 0:
+		return xxh64Avalanche(seed ^ readLE64(secret[56:]) ^ readLE64(secret[64:]))
+
+	case n <= 3:
+		combined := uint64(b[0])<<16 | uint64(b[n>>1])<<24 | uint64(b[n-1]) | uint64(n)<<8
+		bitflip := (readLE32(secret) ^ readLE32(secret[4:])) + seed
+		return xxh64Avalanche(combined ^ bitflip)
+
+	case n <= 8:
+		seed ^= uint64(bits.ReverseBytes32(uint32(seed))) << 32
+		bitflip := (readLE64(secret[8:]) ^ readLE64(secret[16:])) - seed
+		input := readLE32(b[n-4:]) + readLE32(b)<<32
+		return xxh3Rrmxmx(input^bitflip, n)
+
+	case n <= 16:
+		bitflip1 := (readLE64(secret[24:]) ^ readLE64(secret[32:])) + seed
+		bitflip2 := (readLE64(secret[40:]) ^ readLE64(secret[48:])) - seed
+		lo := readLE64(b) ^ bitflip1
+		hi := readLE64(b[n-8:]) ^ bitflip2
+		acc := uint64(n) + bits.ReverseBytes64(lo) + hi + mulFold64(lo, hi)
+		return xxh3Avalanche(acc)
+
+	case n <= 128:
+		acc := uint64(n) * _XXH_PRIME64_1
+		if n > 32 {
+			if n > 64 {
+				if n > 96 {
+					acc += xxh3Mix16(b[48:], secret[96:], seed)
+					acc += xxh3Mix16(b[n-64:], secret[112:], seed)
+				}
+				acc += xxh3Mix16(b[32:], secret[64:], seed)
+				acc += xxh3Mix16(b[n-48:], secret[80:], seed)
+			}
+			acc += xxh3Mix16(b[16:], secret[32:], seed)
+			acc += xxh3Mix16(b[n-32:], secret[48:], seed)
+		}
+		acc += xxh3Mix16(b, secret, seed)
+		acc += xxh3Mix16(b[n-16:], secret[16:], seed)
+		return xxh3Avalanche(acc)
+
+	case n <= _XXH3_MIDSIZE_MAX:
+		acc := uint64(n) * _XXH_PRIME64_1
+		for i := 0; i < 8; i++ {
+			acc += xxh3Mix16(b[16*i:], secret[16*i:], seed)
+		}
+		acc = xxh3Avalanche(acc)
+		for i, rounds := 8, n/16; i < rounds; i++ {
+			acc += xxh3Mix16(b[16*i:], secret[16*(i-8)+_XXH3_MIDSIZE_STARTOFFSET:], seed)
+		}
+		acc += xxh3Mix16(b[n-16:], secret[_XXH3_SECRET_SIZE_MIN-_XXH3_MIDSIZE_LASTOFFSET:], seed)
+		return xxh3Avalanche(acc)
+
+	default:
+		return xxh3Long(b, secret)
+	}
+}
+
+// xxh3Long returns XXH3 64 bit hash of 'b', which is longer than 240 bytes.
+func xxh3Long(b, secret []byte) uint64 {
+
+	var (
+		acc     = xxh3AccInit
+		n       = len(b)
+		nBlocks = (n - 1) / _XXH3_BLOCK_LEN
+	)
+
+	for i := 0; i < nBlocks; i++ {
+		acc.accumulate(b[i*_XXH3_BLOCK_LEN:], secret, _XXH3_STRIPES_PER_BLOCK)
+		acc.scramble(secret[_XXH3_SECRET_SIZE-_XXH3_STRIPE_LEN:])
+	}
+
+	nStripes := ((n - 1) - _XXH3_BLOCK_LEN*nBlocks) / _XXH3_STRIPE_LEN
+	acc.accumulate(b[nBlocks*_XXH3_BLOCK_LEN:], secret, nStripes)
+
+	acc.accumulate512(b[n-_XXH3_STRIPE_LEN:],
+		secret[_XXH3_SECRET_SIZE-_XXH3_STRIPE_LEN-_XXH3_SECRET_LASTACC_START:])
+
+	return acc.merge(secret[_XXH3_SECRET_MERGEACCS_START:], uint64(n)*_XXH_PRIME64_1)
+}
+
+// accumulate processes 'nStripes' stripes of 'b', starting from the beginning
+// of 'secret' and shifting it by 8 bytes for each stripe.
+func (acc *xxh3Acc) accumulate(b, secret []byte, nStripes int) {
+	for i := 0; i < nStripes; i++ {
+		acc.accumulate512(b[i*_XXH3_STRIPE_LEN:], secret[i*_XXH3_SECRET_CONSUME_RATE:])
+	}
+}
+
+// accumulate512 processes one stripe (64 bytes) of 'b'.
+func (acc *xxh3Acc) accumulate512(b, secret []byte) {
+	_ = b[_XXH3_STRIPE_LEN-1]
+	_ = secret[_XXH3_STRIPE_LEN-1]
+	for i := 0; i < 8; i++ {
+		v := readLE64(b[8*i:])
+		k := v ^ readLE64(secret[8*i:])
+		acc[i^1] += v
+		acc[i] += (k & 0xFFFFFFFF) * (k >> 32)
+	}
+}
+
+// scramble scrambles the accumulators at the end of each block.
+func (acc *xxh3Acc) scramble(secret []byte) {
+	for i := 0; i < 8; i++ {
+		a := acc[i]
+		a ^= a >> 47
+		a ^= readLE64(secret[8*i:])
+		a *= _XXH_PRIME32_1
+		acc[i] = a
+	}
+}
+
+// merge returns the final hash of the accumulators.
+func (acc *xxh3Acc) merge(secret []byte, start uint64) uint64 {
+	for i := 0; i < 4; i++ {
+		start += mulFold64(acc[2*i]^readLE64(secret[16*i:]), acc[2*i+1]^readLE64(secret[16*i+8:]))
+	}
+	return xxh3Avalanche(start)
+}
+
+// xxh3CustomSecret writes the secret, derived from 'seed', to 'secret'.
+func xxh3CustomSecret(secret *[_XXH3_SECRET_SIZE]byte, seed uint64) {
+	for i := 0; i < _XXH3_SECRET_SIZE/16; i++ {
+		binary.LittleEndian.PutUint64(secret[16*i:], readLE64(xxh3Secret[16*i:])+seed)
+		binary.LittleEndian.PutUint64(secret[16*i+8:], readLE64(xxh3Secret[16*i+8:])-seed)
+	}
+}
@@ -0,0 +1,247 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Email is a validated email address like "john.doe@example.com".
+	//
+	// Only the common form "local@domain" is supported: the local part
+	// consists of letters, digits, dots (not leading, trailing or consecutive)
+	// and "!#$%&'*+-/=?^_`{|}~"; the domain is a dot separated list
+	// of the labels of letters, digits and hyphens (not leading or trailing).
+	// Quoted local parts, comments and IP literals are rejected.
+	//
+	// The domain is lowercased, the local part is kept as is
+	// (it's case-sensitive by RFC 5321).
+	// The zero Email is an empty one, it's encoded as JSON null and SQL NULL.
+	//
+	// Use Email_FromString() to create it.
+	Email struct {
+		value string
+		at    int // index of '@' in value
+	}
+)
+
+var (
+	// ErrEmailFormat is returned (wrapped) by Email_FromString()
+	// if the input is not a valid email address.
+	ErrEmailFormat = errors.New("email: incorrect email address format")
+)
+
+// -------------------------------- EMAIL CONSTRUCTORS ------------------------ //
+// ---------------------------------------------------------------------------- //
+
+// Email_FromString returns Email parsed from 'input' w/o leading and trailing spaces
+// or ErrEmailFormat (wrapped) if it's not a valid email address.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func Email_FromString(input string) (Email, error) {
+
+	s := strings.TrimSpace(input)
+	at := strings.LastIndexByte(s, '@')
+
+	if len(s) > 254 || at == -1 ||
+		!emailIsValidLocal(s[:at]) || !emailIsValidDomain(s[at+1:]) {
+		return Email{}, fmt.Errorf("%w: %q", ErrEmailFormat, input)
+	}
+
+	return Email{value: s[:at+1] + strings.ToLower(s[at+1:]), at: at}, nil
+}
+
+// Email_FromString_OrPanic is the same as Email_FromString()
+// but panics if the input can't be parsed.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func Email_FromString_OrPanic(input string) Email {
+	e, err := Email_FromString(input)
+	if err != nil {
+		panic(err)
+	}
+	return e
+}
+
+// ---------------------------------- EMAIL METHODS --------------------------- //
+// ---------------------------------------------------------------------------- //
+
+// IsZero reports whether Email is empty.
+func (e Email) IsZero() bool {
+	return e.value == ""
+}
+
+// Local returns the local part of Email ("john.doe" of "john.doe@example.com").
+func (e Email) Local() string {
+	if e.IsZero() {
+		return ""
+	}
+	return e.value[:e.at]
+}
+
+// Domain returns the lowercased domain of Email ("example.com" of "john.doe@example.com").
+func (e Email) Domain() string {
+	if e.IsZero() {
+		return ""
+	}
+	return e.value[e.at+1:]
+}
+
+// String returns normalized Email or an empty string if it's zero.
+func (e Email) String() string {
+	return e.value
+}
+
+// Redacted returns Email with the local part hidden except its first character,
+// like "j***@example.com". It's safe to be logged.
+// Returns an empty string if Email is zero.
+func (e Email) Redacted() string {
+	if e.IsZero() {
+		return ""
+	}
+	return e.value[:1] + "***" + e.value[e.at:]
+}
+
+// ---------------------------- EMAIL TEXT ENCODER/DECODER -------------------- //
+// ---------------------------------------------------------------------------- //
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (e Email) MarshalText() ([]byte, error) {
+	return []byte(e.value), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// Empty input makes Email zero.
+func (e *Email) UnmarshalText(data []byte) (err error) {
+	if len(data) == 0 {
+		*e = Email{}
+		return nil
+	}
+	*e, err = Email_FromString(string(data))
+	return err
+}
+
+// ---------------------------- EMAIL JSON ENCODER/DECODER -------------------- //
+// ---------------------------------------------------------------------------- //
+
+// MarshalJSON implements the encoding/json.Marshaler interface.
+// Email is encoded as JSON string or JSON null if it's zero.
+func (e Email) MarshalJSON() ([]byte, error) {
+	if e.IsZero() {
+		return _UUID_JSON_NULL, nil
+	}
+	b := append(make([]byte, 0, len(e.value)+2), '"')
+	return append(append(b, e.value...), '"'), nil
+}
+
+// UnmarshalJSON implements the encoding/json.Unmarshaler interface.
+// JSON null is ignored.
+func (e *Email) UnmarshalJSON(data []byte) error {
+
+	if len(data) == 0 || bytes.Equal(data, _UUID_JSON_NULL) {
+		return nil
+	}
+
+	if l := len(data); l >= 2 && data[0] == '"' && data[l-1] == '"' {
+		data = data[1 : l-1]
+	}
+
+	return e.UnmarshalText(data)
+}
+
+// ----------------------------- EMAIL SQL ENCODER/DECODER -------------------- //
+// ---------------------------------------------------------------------------- //
+
+// Value implements the driver.Valuer interface.
+// Email is passed as string or SQL NULL if it's zero.
+func (e Email) Value() (driver.Value, error) {
+	if e.IsZero() {
+		return nil, nil
+	}
+	return e.value, nil
+}
+
+// Scan implements the sql.Scanner interface.
+// Supports string, []byte and SQL NULL (Email is unchanged then).
+func (e *Email) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+
+	case []byte:
+		return e.UnmarshalText(src)
+
+	case string:
+		return e.UnmarshalText([]byte(src))
+	}
+
+	return fmt.Errorf("email: cannot convert %T to Email", src)
+}
+
+// ---------------------------------- EMAIL FIELD ----------------------------- //
+// ---------------------------------------------------------------------------- //
+
+// FEmail constructs a field that holds on redacted Email (read more: Email.Redacted()).
+func FEmail(key string, value Email) ekaletter.LetterField {
+	return ekaletter.FString(key, value.Redacted())
+}
+
+// --------------------------------- EMAIL PRIVATE ---------------------------- //
+// ---------------------------------------------------------------------------- //
+
+// emailIsValidLocal reports whether 's' is a valid dot-atom local part of email.
+func emailIsValidLocal(s string) bool {
+
+	if len(s) == 0 || len(s) > 64 || s[0] == '.' || s[len(s)-1] == '.' {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '.' && s[i-1] == '.':
+			return false
+		case c == '.', emailIsAlnum(c), strings.IndexByte("!#$%&'*+-/=?^_`{|}~", c) != -1:
+		default:
+			return false
+		}
+	}
+
+	return true
+}
+
+// emailIsValidDomain reports whether 's' is a valid domain name
+// of at least 2 labels.
+func emailIsValidDomain(s string) bool {
+
+	if len(s) == 0 || len(s) > 253 {
+		return false
+	}
+
+	labels := 0
+	for _, label := range strings.Split(s, ".") {
+		if len(label) == 0 || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for i := 0; i < len(label); i++ {
+			if c := label[i]; !emailIsAlnum(c) && c != '-' {
+				return false
+			}
+		}
+		labels++
+	}
+
+	return labels >= 2
+}
+
+// emailIsAlnum reports whether 'c' is ASCII letter or digit.
+func emailIsAlnum(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
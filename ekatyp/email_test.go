@@ -0,0 +1,84 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmail_FromString(t *testing.T) {
+
+	tests := []struct {
+		in, out, local, domain, redacted string
+	}{
+		{"john.doe@example.com", "john.doe@example.com", "john.doe", "example.com", "j***@example.com"},
+		{" John+Tag@Mail.Example.COM ", "John+Tag@mail.example.com", "John+Tag", "mail.example.com", "J***@mail.example.com"},
+		{"a@b-c.io", "a@b-c.io", "a", "b-c.io", "a***@b-c.io"},
+	}
+
+	for _, test := range tests {
+		e, err := Email_FromString(test.in)
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.out, e.String(), test.in)
+		assert.Equal(t, test.local, e.Local(), test.in)
+		assert.Equal(t, test.domain, e.Domain(), test.in)
+		assert.Equal(t, test.redacted, e.Redacted(), test.in)
+	}
+
+	for _, in := range []string{"", "john", "@example.com", "john@", "john@localhost",
+		".john@example.com", "jo..hn@example.com", "john.@example.com", "jo hn@example.com",
+		"john@-example.com", "john@example..com", "john@exa_mple.com", `"john"@example.com`} {
+		_, err := Email_FromString(in)
+		assert.True(t, errors.Is(err, ErrEmailFormat), in)
+	}
+
+	assert.Panics(t, func() { Email_FromString_OrPanic("john") })
+	assert.True(t, Email{}.IsZero())
+	assert.Equal(t, "", Email{}.Redacted())
+}
+
+func TestEmail_JSON(t *testing.T) {
+
+	type T struct {
+		Email    Email `json:"email"`
+		NotEmail Email `json:"not_email"`
+	}
+
+	encoded, err := json.Marshal(T{Email: Email_FromString_OrPanic("John@Example.com")})
+	require.NoError(t, err)
+	assert.Equal(t, `{"email":"John@example.com","not_email":null}`, string(encoded))
+
+	var decoded T
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, "John@example.com", decoded.Email.String())
+	assert.True(t, decoded.NotEmail.IsZero())
+
+	assert.Error(t, json.Unmarshal([]byte(`{"email":"john"}`), &decoded))
+}
+
+func TestEmail_SQL(t *testing.T) {
+
+	var e Email
+	require.NoError(t, e.Scan([]byte("john@example.com")))
+	assert.Equal(t, "john@example.com", e.String())
+
+	v, err := e.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "john@example.com", v)
+
+	require.NoError(t, e.Scan(nil))
+	assert.Equal(t, "john@example.com", e.String())
+	assert.Error(t, e.Scan(42))
+
+	v, err = Email{}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
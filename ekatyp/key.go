@@ -0,0 +1,257 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+)
+
+type (
+	// KeyBuilder builds a binary-sortable composite key of typed components,
+	// e.g. for KV stores like Badger or TiKV. The keys built by KeyBuilder
+	// are compared by bytes.Compare() the same way their components are compared
+	// one by one (the first differing component decides).
+	//
+	// The components are encoded:
+	//
+	//  - uint64: 8 bytes, big endian;
+	//  - int64: 8 bytes, big endian with the sign bit flipped;
+	//  - time.Time: as int64 of its UNIX timestamp in nanoseconds
+	//    (thus the time out of years 1678..2262 is not supported);
+	//  - string, []byte: as is, but each 0x00 is escaped as 0x00 0xFF
+	//    and the 0x00 0x01 terminator is added;
+	//  - UUID, ULID: 16 bytes as is.
+	//
+	// There are no type tags, so KeyParser must parse the components
+	// in the same order and of the same types as they have been appended.
+	//
+	// The zero KeyBuilder is ready to use.
+	KeyBuilder struct {
+		b []byte
+	}
+
+	// KeyParser parses the components of a key, built by KeyBuilder.
+	// The components must be parsed in the same order and of the same types
+	// as they have been appended to KeyBuilder.
+	//
+	// KeyParser MUST be created by NewKeyParser().
+	KeyParser struct {
+		b []byte
+	}
+)
+
+var (
+	// ErrKeyFormat is returned by KeyParser if the rest of the key
+	// can't be parsed as the requested component.
+	ErrKeyFormat = errors.New("key: incorrect key component format")
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	_KEY_ESCAPE     = 0x00
+	_KEY_ESCAPED_00 = 0xFF
+	_KEY_TERMINATOR = 0x01
+
+	_KEY_SIGN_BIT = uint64(1) << 63
+)
+
+// NewKeyBuilder creates a new KeyBuilder, preallocating 'capacity' bytes.
+func NewKeyBuilder(capacity int) *KeyBuilder {
+	return &KeyBuilder{b: make([]byte, 0, capacity)}
+}
+
+// AppendUint64 appends uint64 component. Returns this.
+func (kb *KeyBuilder) AppendUint64(v uint64) *KeyBuilder {
+	var buf [8]byte
+	binary.BigEndian.PutUint64(buf[:], v)
+	kb.b = append(kb.b, buf[:]...)
+	return kb
+}
+
+// AppendInt64 appends int64 component. Negative values are less than positive ones.
+// Returns this.
+func (kb *KeyBuilder) AppendInt64(v int64) *KeyBuilder {
+	return kb.AppendUint64(uint64(v) ^ _KEY_SIGN_BIT)
+}
+
+// AppendTime appends time.Time component. The location is not kept.
+// Returns this.
+func (kb *KeyBuilder) AppendTime(t time.Time) *KeyBuilder {
+	return kb.AppendInt64(t.UnixNano())
+}
+
+// AppendString appends string component. Returns this.
+func (kb *KeyBuilder) AppendString(s string) *KeyBuilder {
+
+	for {
+		i := strings.IndexByte(s, _KEY_ESCAPE)
+		if i == -1 {
+			break
+		}
+		kb.b = append(kb.b, s[:i]...)
+		kb.b = append(kb.b, _KEY_ESCAPE, _KEY_ESCAPED_00)
+		s = s[i+1:]
+	}
+
+	kb.b = append(kb.b, s...)
+	kb.b = append(kb.b, _KEY_ESCAPE, _KEY_TERMINATOR)
+
+	return kb
+}
+
+// AppendBytes appends []byte component. It's the same as AppendString().
+// Returns this.
+func (kb *KeyBuilder) AppendBytes(b []byte) *KeyBuilder {
+	return kb.AppendString(string(b))
+}
+
+// AppendUUID appends UUID component. Returns this.
+func (kb *KeyBuilder) AppendUUID(u UUID) *KeyBuilder {
+	kb.b = append(kb.b, u[:]...)
+	return kb
+}
+
+// AppendULID appends ULID component. Returns this.
+func (kb *KeyBuilder) AppendULID(u ULID) *KeyBuilder {
+	kb.b = append(kb.b, u[:]...)
+	return kb
+}
+
+// Bytes returns the built key.
+//
+// WARNING!
+// The returned slice is KeyBuilder's internal buffer. It's changed
+// by the next Append... or Reset() calls. Clone it if you need.
+func (kb *KeyBuilder) Bytes() []byte {
+	return kb.b
+}
+
+// Len returns the length of the built key in bytes.
+func (kb *KeyBuilder) Len() int {
+	return len(kb.b)
+}
+
+// Reset resets KeyBuilder to be empty, keeping its allocated memory.
+// Returns this.
+func (kb *KeyBuilder) Reset() *KeyBuilder {
+	kb.b = kb.b[:0]
+	return kb
+}
+
+// NewKeyParser creates a new KeyParser of 'key'.
+// The 'key' is not copied, so it MUST NOT be changed while it's parsed.
+func NewKeyParser(key []byte) *KeyParser {
+	return &KeyParser{b: key}
+}
+
+// Uint64 parses the next uint64 component.
+// Returns ErrKeyFormat if there's not enough bytes.
+func (kp *KeyParser) Uint64() (uint64, error) {
+
+	if len(kp.b) < 8 {
+		return 0, ErrKeyFormat
+	}
+
+	v := binary.BigEndian.Uint64(kp.b)
+	kp.b = kp.b[8:]
+
+	return v, nil
+}
+
+// Int64 parses the next int64 component.
+// Returns ErrKeyFormat if there's not enough bytes.
+func (kp *KeyParser) Int64() (int64, error) {
+	v, err := kp.Uint64()
+	return int64(v ^ _KEY_SIGN_BIT), err
+}
+
+// Time parses the next time.Time component. The returned time.Time is local.
+// Returns ErrKeyFormat if there's not enough bytes.
+func (kp *KeyParser) Time() (time.Time, error) {
+
+	v, err := kp.Int64()
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Unix(0, v), nil
+}
+
+// String parses the next string component.
+// Returns ErrKeyFormat if there's no terminator or an escape sequence is incorrect.
+func (kp *KeyParser) String() (string, error) {
+	b, err := kp.Bytes()
+	return string(b), err
+}
+
+// Bytes parses the next []byte component. The returned slice is a new one.
+// Returns ErrKeyFormat if there's no terminator or an escape sequence is incorrect.
+func (kp *KeyParser) Bytes() ([]byte, error) {
+
+	var out []byte
+
+	for b := kp.b; ; {
+
+		i := bytes.IndexByte(b, _KEY_ESCAPE)
+		if i == -1 || i+1 == len(b) {
+			return nil, ErrKeyFormat
+		}
+
+		out = append(out, b[:i]...)
+
+		switch b[i+1] {
+		case _KEY_ESCAPED_00:
+			out = append(out, _KEY_ESCAPE)
+			b = b[i+2:]
+
+		case _KEY_TERMINATOR:
+			kp.b = b[i+2:]
+			if out == nil {
+				out = []byte{}
+			}
+			return out, nil
+
+		default:
+			return nil, ErrKeyFormat
+		}
+	}
+}
+
+// UUID parses the next UUID component.
+// Returns ErrKeyFormat if there's not enough bytes.
+func (kp *KeyParser) UUID() (UUID, error) {
+
+	var u UUID
+	if len(kp.b) < len(u) {
+		return u, ErrKeyFormat
+	}
+
+	copy(u[:], kp.b)
+	kp.b = kp.b[len(u):]
+
+	return u, nil
+}
+
+// ULID parses the next ULID component.
+// Returns ErrKeyFormat if there's not enough bytes.
+func (kp *KeyParser) ULID() (ULID, error) {
+	u, err := kp.UUID()
+	return ULID(u), err
+}
+
+// Rest returns the unparsed part of the key.
+func (kp *KeyParser) Rest() []byte {
+	return kp.b
+}
+
+// IsEnd reports whether the whole key is parsed.
+func (kp *KeyParser) IsEnd() bool {
+	return len(kp.b) == 0
+}
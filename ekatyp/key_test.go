@@ -0,0 +1,97 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp_test
+
+import (
+	"bytes"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekatyp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyBuilder_Sortable(t *testing.T) {
+
+	type tc struct {
+		i int64
+		s string
+		u uint64
+	}
+
+	// Sorted in the order of components.
+	tcs := []tc{
+		{-100, "z", 0},
+		{-1, "", 5},
+		{0, "a", 1},
+		{0, "a", 2},
+		{0, "a\x00", 0},
+		{0, "a\x00b", 0},
+		{0, "ab", 0},
+		{7, "", 0},
+	}
+
+	keys := make([][]byte, 0, len(tcs))
+	for i := len(tcs) - 1; i >= 0; i-- {
+		key := new(ekatyp.KeyBuilder).
+			AppendInt64(tcs[i].i).AppendString(tcs[i].s).AppendUint64(tcs[i].u).Bytes()
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool { return bytes.Compare(keys[i], keys[j]) < 0 })
+
+	for i, key := range keys {
+		kp := ekatyp.NewKeyParser(key)
+
+		v1, err1 := kp.Int64()
+		v2, err2 := kp.String()
+		v3, err3 := kp.Uint64()
+
+		require.NoError(t, err1)
+		require.NoError(t, err2)
+		require.NoError(t, err3)
+		require.True(t, kp.IsEnd())
+		assert.Equal(t, tcs[i], tc{v1, v2, v3})
+	}
+}
+
+func TestKeyParser(t *testing.T) {
+
+	var (
+		ts = time.Date(2022, 5, 6, 7, 8, 9, 10, time.UTC)
+		u  = ekatyp.UUID_NewV4_OrPanic()
+	)
+
+	key := ekatyp.NewKeyBuilder(64).
+		AppendTime(ts).AppendUUID(u).AppendBytes([]byte{0, 1, 0xFF}).Bytes()
+
+	kp := ekatyp.NewKeyParser(key)
+
+	gotTs, err := kp.Time()
+	require.NoError(t, err)
+	assert.True(t, ts.Equal(gotTs))
+
+	gotU, err := kp.UUID()
+	require.NoError(t, err)
+	assert.Equal(t, u, gotU)
+
+	gotB, err := kp.Bytes()
+	require.NoError(t, err)
+	assert.Equal(t, []byte{0, 1, 0xFF}, gotB)
+	assert.True(t, kp.IsEnd())
+
+	_, err = kp.Uint64()
+	assert.Equal(t, ekatyp.ErrKeyFormat, err)
+
+	_, err = ekatyp.NewKeyParser([]byte("abc")).String()
+	assert.Equal(t, ekatyp.ErrKeyFormat, err)
+
+	_, err = ekatyp.NewKeyParser([]byte{'a', 0, 2}).String()
+	assert.Equal(t, ekatyp.ErrKeyFormat, err)
+}
@@ -0,0 +1,199 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// PhoneNumber is a validated phone number in the international format,
+	// normalized to E.164: "+" followed by 7..15 digits, the first one is not 0
+	// ("+15551234567").
+	//
+	// Only the length and the digits are validated, not the numbering plans
+	// of the countries.
+	// The zero PhoneNumber is an empty one, it's encoded as JSON null and SQL NULL.
+	//
+	// Use PhoneNumber_FromString() to create it.
+	PhoneNumber struct {
+		value string
+	}
+)
+
+var (
+	// ErrPhoneNumberFormat is returned (wrapped) by PhoneNumber_FromString()
+	// if the input is not a valid phone number in the international format.
+	ErrPhoneNumberFormat = errors.New("phone: incorrect international phone number format")
+)
+
+// ---------------------------- PHONE NUMBER CONSTRUCTORS --------------------- //
+// ---------------------------------------------------------------------------- //
+
+// PhoneNumber_FromString returns PhoneNumber parsed from 'input'
+// or ErrPhoneNumberFormat (wrapped) if it's not a valid phone number.
+// The input must start with "+" or "00" followed by the country code.
+// Spaces, dots, hyphens and parentheses between digits are ignored:
+// "+1 (555) 123-45.67", "001 555 1234567" are "+15551234567".
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func PhoneNumber_FromString(input string) (PhoneNumber, error) {
+
+	s := strings.TrimSpace(input)
+
+	switch {
+	case strings.HasPrefix(s, "+"):
+		s = s[1:]
+	case strings.HasPrefix(s, "00"):
+		s = s[2:]
+	default:
+		return PhoneNumber{}, fmt.Errorf("%w: %q", ErrPhoneNumberFormat, input)
+	}
+
+	digits := make([]byte, 1, 16)
+	digits[0] = '+'
+
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c >= '0' && c <= '9':
+			digits = append(digits, c)
+		case c == ' ' || c == '.' || c == '-' || c == '(' || c == ')':
+		default:
+			return PhoneNumber{}, fmt.Errorf("%w: %q", ErrPhoneNumberFormat, input)
+		}
+	}
+
+	if n := len(digits) - 1; n < 7 || n > 15 || digits[1] == '0' {
+		return PhoneNumber{}, fmt.Errorf("%w: %q", ErrPhoneNumberFormat, input)
+	}
+
+	return PhoneNumber{value: string(digits)}, nil
+}
+
+// PhoneNumber_FromString_OrPanic is the same as PhoneNumber_FromString()
+// but panics if the input can't be parsed.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func PhoneNumber_FromString_OrPanic(input string) PhoneNumber {
+	p, err := PhoneNumber_FromString(input)
+	if err != nil {
+		panic(err)
+	}
+	return p
+}
+
+// ------------------------------ PHONE NUMBER METHODS ------------------------ //
+// ---------------------------------------------------------------------------- //
+
+// IsZero reports whether PhoneNumber is empty.
+func (p PhoneNumber) IsZero() bool {
+	return p.value == ""
+}
+
+// String returns PhoneNumber in E.164 format or an empty string if it's zero.
+func (p PhoneNumber) String() string {
+	return p.value
+}
+
+// Redacted returns PhoneNumber with all digits hidden except the first
+// and the last two ones, like "+1********67". It's safe to be logged.
+// Returns an empty string if PhoneNumber is zero.
+func (p PhoneNumber) Redacted() string {
+	if p.IsZero() {
+		return ""
+	}
+	n := len(p.value)
+	return p.value[:2] + strings.Repeat("*", n-4) + p.value[n-2:]
+}
+
+// ------------------------ PHONE NUMBER TEXT ENCODER/DECODER ----------------- //
+// ---------------------------------------------------------------------------- //
+
+// MarshalText implements the encoding.TextMarshaler interface.
+func (p PhoneNumber) MarshalText() ([]byte, error) {
+	return []byte(p.value), nil
+}
+
+// UnmarshalText implements the encoding.TextUnmarshaler interface.
+// Empty input makes PhoneNumber zero.
+func (p *PhoneNumber) UnmarshalText(data []byte) (err error) {
+	if len(data) == 0 {
+		*p = PhoneNumber{}
+		return nil
+	}
+	*p, err = PhoneNumber_FromString(string(data))
+	return err
+}
+
+// ------------------------ PHONE NUMBER JSON ENCODER/DECODER ----------------- //
+// ---------------------------------------------------------------------------- //
+
+// MarshalJSON implements the encoding/json.Marshaler interface.
+// PhoneNumber is encoded as JSON string or JSON null if it's zero.
+func (p PhoneNumber) MarshalJSON() ([]byte, error) {
+	if p.IsZero() {
+		return _UUID_JSON_NULL, nil
+	}
+	b := append(make([]byte, 0, len(p.value)+2), '"')
+	return append(append(b, p.value...), '"'), nil
+}
+
+// UnmarshalJSON implements the encoding/json.Unmarshaler interface.
+// JSON null is ignored.
+func (p *PhoneNumber) UnmarshalJSON(data []byte) error {
+
+	if len(data) == 0 || bytes.Equal(data, _UUID_JSON_NULL) {
+		return nil
+	}
+
+	if l := len(data); l >= 2 && data[0] == '"' && data[l-1] == '"' {
+		data = data[1 : l-1]
+	}
+
+	return p.UnmarshalText(data)
+}
+
+// ------------------------- PHONE NUMBER SQL ENCODER/DECODER ----------------- //
+// ---------------------------------------------------------------------------- //
+
+// Value implements the driver.Valuer interface.
+// PhoneNumber is passed as string or SQL NULL if it's zero.
+func (p PhoneNumber) Value() (driver.Value, error) {
+	if p.IsZero() {
+		return nil, nil
+	}
+	return p.value, nil
+}
+
+// Scan implements the sql.Scanner interface.
+// Supports string, []byte and SQL NULL (PhoneNumber is unchanged then).
+func (p *PhoneNumber) Scan(src any) error {
+	switch src := src.(type) {
+	case nil:
+		return nil
+
+	case []byte:
+		return p.UnmarshalText(src)
+
+	case string:
+		return p.UnmarshalText([]byte(src))
+	}
+
+	return fmt.Errorf("phone: cannot convert %T to PhoneNumber", src)
+}
+
+// ------------------------------ PHONE NUMBER FIELD -------------------------- //
+// ---------------------------------------------------------------------------- //
+
+// FPhoneNumber constructs a field that holds on redacted PhoneNumber
+// (read more: PhoneNumber.Redacted()).
+func FPhoneNumber(key string, value PhoneNumber) ekaletter.LetterField {
+	return ekaletter.FString(key, value.Redacted())
+}
@@ -0,0 +1,66 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPhoneNumber_FromString(t *testing.T) {
+
+	tests := []struct {
+		in, out, redacted string
+	}{
+		{"+15551234567", "+15551234567", "+1********67"},
+		{" +1 (555) 123-45.67 ", "+15551234567", "+1********67"},
+		{"0044 20 7946 0958", "+442079460958", "+4*********58"},
+		{"+7 123 4567", "+71234567", "+7*****67"},
+	}
+
+	for _, test := range tests {
+		p, err := PhoneNumber_FromString(test.in)
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.out, p.String(), test.in)
+		assert.Equal(t, test.redacted, p.Redacted(), test.in)
+	}
+
+	for _, in := range []string{"", "+", "5551234567", "+0551234567", "+123456",
+		"+1234567890123456", "+1 555 CALL-NOW", "+1_555_1234567", "0+15551234567"} {
+		_, err := PhoneNumber_FromString(in)
+		assert.True(t, errors.Is(err, ErrPhoneNumberFormat), in)
+	}
+
+	assert.Panics(t, func() { PhoneNumber_FromString_OrPanic("123") })
+	assert.True(t, PhoneNumber{}.IsZero())
+}
+
+func TestPhoneNumber_JSON_SQL(t *testing.T) {
+
+	encoded, err := json.Marshal([]PhoneNumber{PhoneNumber_FromString_OrPanic("+1 555 123 4567"), {}})
+	require.NoError(t, err)
+	assert.Equal(t, `["+15551234567",null]`, string(encoded))
+
+	var decoded []PhoneNumber
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	require.Len(t, decoded, 2)
+	assert.Equal(t, "+15551234567", decoded[0].String())
+	assert.True(t, decoded[1].IsZero())
+
+	var p PhoneNumber
+	require.NoError(t, p.Scan("00 1 555 123 4567"))
+	assert.Equal(t, "+15551234567", p.String())
+
+	v, err := p.Value()
+	require.NoError(t, err)
+	assert.Equal(t, "+15551234567", v)
+
+	assert.Error(t, p.Scan("123"))
+}
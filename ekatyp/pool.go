@@ -0,0 +1,163 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+	"unsafe"
+
+	"github.com/qioalice/ekago/v3/ekasys"
+	"github.com/qioalice/ekago/v3/internal/ekaclike"
+)
+
+type (
+	// Pool is a typed object pool built on sync.Pool.
+	//
+	// Unlike sync.Pool it:
+	//   - Is typed, no type assertions are required;
+	//   - Calls optional reset hook for each object returned by Put();
+	//   - May cap the number of retained (idle) objects (see WithCapacity());
+	//   - Has an opt-in debug mode that tracks objects, that are acquired
+	//     by Get() but not returned by Put() yet, with their acquisition
+	//     stacktraces to diagnose leaks (see WithDebug(), Outstanding()).
+	//
+	// Pool MUST be created by NewPool() and MUST NOT be copied after first use.
+	// All methods are thread-safe, but With...() methods MUST be called
+	// before the first Get() call.
+	Pool[T any] struct {
+		pool     sync.Pool
+		newFunc  func() T
+		reset    func(T)
+		capacity int64
+		idle     int64 // approximate, because sync.Pool may drop objects at GC
+
+		isDebug     bool
+		outstanding sync.Map // map[unsafe.Pointer]PoolOutstanding
+	}
+
+	// PoolOutstanding is an information about an object, that is acquired
+	// from the Pool by Get() and not returned by Put() yet.
+	// It's available only in the Pool's debug mode.
+	PoolOutstanding struct {
+		AcquiredAt time.Time
+		StackTrace ekasys.StackTrace
+	}
+)
+
+// NewPool creates a new Pool, that uses 'newFunc' to create a new object
+// when there is no idle one. 'newFunc' MUST NOT be nil. Panic otherwise.
+func NewPool[T any](newFunc func() T) *Pool[T] {
+	if newFunc == nil {
+		panic("ekatyp: NewPool: nil 'newFunc'")
+	}
+	return &Pool[T]{newFunc: newFunc}
+}
+
+// WithReset sets the hook, that is called for each object returned by Put()
+// before it's placed back to the Pool. Returns the current Pool.
+func (p *Pool[T]) WithReset(reset func(T)) *Pool[T] {
+	p.reset = reset
+	return p
+}
+
+// WithCapacity caps the number of idle objects retained by the Pool.
+// Objects returned by Put() when there is 'capacity' idle objects already
+// are dropped. Any value <= 0 means "no cap" (default). Returns the current Pool.
+//
+// Keep in mind, the cap is approximate, because sync.Pool may drop idle objects
+// at any time (at GC), which is not tracked.
+func (p *Pool[T]) WithCapacity(capacity int) *Pool[T] {
+	if capacity < 0 {
+		capacity = 0
+	}
+	p.capacity = int64(capacity)
+	return p
+}
+
+// WithDebug enables or disables debug mode. In debug mode Pool tracks
+// each acquired by Get() object along with its acquisition stacktrace
+// until it's returned by Put(). Use Outstanding() to get them.
+// Returns the current Pool.
+//
+// Debug mode is expensive (stacktrace generation for each Get() call)
+// and works only for pointer-like T (pointers, maps, channels),
+// because the other objects have no identity. It's no-op for other T.
+func (p *Pool[T]) WithDebug(enable bool) *Pool[T] {
+	var zero T
+	switch reflect.TypeOf(&zero).Elem().Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Chan, reflect.UnsafePointer:
+		p.isDebug = enable
+	}
+	return p
+}
+
+// Get returns an idle object from the Pool or creates a new one using
+// 'newFunc' (provided to NewPool()).
+func (p *Pool[T]) Get() T {
+
+	var v T
+	if pooled := p.pool.Get(); pooled != nil {
+		v = pooled.(T)
+		atomic.AddInt64(&p.idle, -1)
+	} else {
+		v = p.newFunc()
+	}
+
+	if p.isDebug {
+		p.outstanding.Store(p.addr(v), PoolOutstanding{
+			AcquiredAt: time.Now(),
+			StackTrace: ekasys.GetStackTrace(1, -1).ExcludeInternal(),
+		})
+	}
+
+	return v
+}
+
+// Put resets (if reset hook is set) and returns an object 'v' to the Pool.
+// 'v' MUST NOT be used after that.
+// 'v' is dropped if the Pool has reached its capacity (see WithCapacity()).
+func (p *Pool[T]) Put(v T) {
+
+	if p.isDebug {
+		p.outstanding.Delete(p.addr(v))
+	}
+
+	if p.reset != nil {
+		p.reset(v)
+	}
+
+	if idle := atomic.AddInt64(&p.idle, 1); p.capacity > 0 && idle > p.capacity {
+		atomic.AddInt64(&p.idle, -1)
+		return
+	}
+
+	p.pool.Put(v)
+}
+
+// Outstanding returns the objects acquired by Get() and not returned
+// by Put() yet. Always returns nil if debug mode is disabled (see WithDebug()).
+func (p *Pool[T]) Outstanding() []PoolOutstanding {
+
+	if !p.isDebug {
+		return nil
+	}
+
+	var ret []PoolOutstanding
+	p.outstanding.Range(func(_, value any) bool {
+		ret = append(ret, value.(PoolOutstanding))
+		return true
+	})
+
+	return ret
+}
+
+// addr returns an identity of pointer-like 'v'.
+func (_ *Pool[T]) addr(v T) unsafe.Pointer {
+	return ekaclike.TakeRealAddr(v)
+}
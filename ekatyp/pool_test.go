@@ -0,0 +1,57 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekatyp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPool(t *testing.T) {
+
+	created := 0
+	p := ekatyp.NewPool(func() *bytes.Buffer {
+		created++
+		return new(bytes.Buffer)
+	}).WithReset(func(b *bytes.Buffer) {
+		b.Reset()
+	})
+
+	b := p.Get()
+	b.WriteString("data")
+	p.Put(b)
+
+	b = p.Get()
+	assert.Equal(t, 0, b.Len())
+	assert.Nil(t, p.Outstanding())
+	assert.LessOrEqual(t, created, 2)
+}
+
+func TestPool_Debug(t *testing.T) {
+
+	p := ekatyp.NewPool(func() *[]byte { return new([]byte) }).WithDebug(true)
+
+	b1, b2 := p.Get(), p.Get()
+	p.Put(b1)
+
+	outstanding := p.Outstanding()
+	require.Len(t, outstanding, 1)
+	require.NotEmpty(t, outstanding[0].StackTrace)
+	assert.True(t, strings.HasSuffix(outstanding[0].StackTrace[0].Function, "TestPool_Debug"),
+		outstanding[0].StackTrace[0].Function)
+
+	p.Put(b2)
+	assert.Empty(t, p.Outstanding())
+
+	// Debug mode is not supported for non pointer-like types.
+	assert.Nil(t, ekatyp.NewPool(func() int { return 0 }).WithDebug(true).Outstanding())
+}
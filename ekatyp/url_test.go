@@ -0,0 +1,65 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestURL_FromString(t *testing.T) {
+
+	tests := []struct {
+		in, out, redacted string
+	}{
+		{"https://example.com/path?q=1", "https://example.com/path?q=1", "https://example.com/path?q=***"},
+		{" HTTPS://Example.COM:443/Path ", "https://example.com/Path", "https://example.com/Path"},
+		{"http://example.com:80", "http://example.com", "http://example.com"},
+		{"http://example.com:8080/?a=1&b&c=", "http://example.com:8080/?a=1&b&c=", "http://example.com:8080/?a=***&b&c=***"},
+		{"postgres://user:secret@db:5432/app", "postgres://user:secret@db:5432/app", "postgres://user:xxxxx@db:5432/app"},
+	}
+
+	for _, test := range tests {
+		u, err := URL_FromString(test.in)
+		require.NoError(t, err, test.in)
+		assert.Equal(t, test.out, u.String(), test.in)
+		assert.Equal(t, test.redacted, u.Redacted(), test.in)
+	}
+
+	for _, in := range []string{"", "example.com", "/path", "mailto:john@example.com", "https://", "http://exa mple.com"} {
+		_, err := URL_FromString(in)
+		assert.True(t, errors.Is(err, ErrURLFormat), in)
+	}
+
+	assert.Panics(t, func() { URL_FromString_OrPanic("example.com") })
+	assert.True(t, URL{}.IsZero())
+	assert.Nil(t, URL{}.URL())
+	assert.Equal(t, "example.com", URL_FromString_OrPanic("https://example.com").URL().Host)
+}
+
+func TestURL_JSON_SQL(t *testing.T) {
+
+	encoded, err := json.Marshal(map[string]URL{"u": URL_FromString_OrPanic("HTTPS://example.com")})
+	require.NoError(t, err)
+	assert.Equal(t, `{"u":"https://example.com"}`, string(encoded))
+
+	var decoded map[string]URL
+	require.NoError(t, json.Unmarshal(encoded, &decoded))
+	assert.Equal(t, "https://example.com", decoded["u"].String())
+
+	var u URL
+	require.NoError(t, u.Scan([]byte("https://example.com/a")))
+	assert.Equal(t, "https://example.com/a", u.String())
+	assert.Error(t, u.Scan("/a"))
+
+	v, err := URL{}.Value()
+	require.NoError(t, err)
+	assert.Nil(t, v)
+}
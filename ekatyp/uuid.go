@@ -28,13 +28,19 @@ package ekatyp
 
 import (
 	"bytes"
+	"crypto/subtle"
 	"database/sql/driver"
 	"fmt"
+	"sort"
 )
 
 type (
 	// UUID representation compliant with specification described in RFC 4122.
 	UUID [_UUID_SIZE]byte
+
+	// UUIDFormat is a bitmask of UUID_FORMAT_... options of UUID's
+	// string representation. Read more: UUID.StringFormatted().
+	UUIDFormat uint8
 )
 
 // noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
@@ -46,6 +52,7 @@ const (
 	UUID_V3 byte = 3
 	UUID_V4 byte = 4
 	UUID_V5 byte = 5
+	UUID_V7 byte = 7
 
 	// UUID layout variants.
 
@@ -61,6 +68,27 @@ const (
 	UUID_DOMAIN_ORG    = 2
 )
 
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+const (
+	// UUID string representation formats. They may be combined,
+	// but UUID_FORMAT_URN overrides UUID_FORMAT_BRACED.
+
+	// UUID_FORMAT_CANONICAL is "6ba7b810-9dad-11d1-80b4-00c04fd430c8".
+	UUID_FORMAT_CANONICAL UUIDFormat = 0
+
+	// UUID_FORMAT_HASH is "6ba7b8109dad11d180b400c04fd430c8" (w/o dashes).
+	UUID_FORMAT_HASH UUIDFormat = 1 << (iota - 1)
+
+	// UUID_FORMAT_BRACED is "{6ba7b810-9dad-11d1-80b4-00c04fd430c8}" (GUID).
+	UUID_FORMAT_BRACED
+
+	// UUID_FORMAT_URN is "urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8".
+	UUID_FORMAT_URN
+
+	// UUID_FORMAT_UPPER is "6BA7B810-9DAD-11D1-80B4-00C04FD430C8".
+	UUID_FORMAT_UPPER
+)
+
 // noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
 var (
 	// _UUID_NULL is special form of UUID that is specified to have all
@@ -74,6 +102,12 @@ var (
 	UUID_NAMESPACE_X500 = UUID_OrPanic(UUID_FromString("6ba7b814-9dad-11d1-80b4-00c04fd430c8"))
 )
 
+var (
+	// Make sure UUID (not only *UUID) may be printed as a string,
+	// so it can be used as a map key and printed w/o surprises.
+	_ fmt.Stringer = UUID{}
+)
+
 // ---------------------------- UUID COMMON METHODS --------------------------- //
 // ---------------------------------------------------------------------------- //
 
@@ -82,6 +116,25 @@ func (u UUID) Equal(anotherUuid UUID) bool {
 	return bytes.Equal(u[:], anotherUuid[:])
 }
 
+// Compare returns an integer comparing u and anotherUuid lexicographically
+// (byte by byte, that is the same as comparing their canonical strings).
+// The result is 0 if u == anotherUuid, -1 if u < anotherUuid and +1 otherwise.
+func (u UUID) Compare(anotherUuid UUID) int {
+	return bytes.Compare(u[:], anotherUuid[:])
+}
+
+// Less reports whether u is less than anotherUuid. Read more: Compare().
+func (u UUID) Less(anotherUuid UUID) bool {
+	return u.Compare(anotherUuid) < 0
+}
+
+// ConstantTimeEqual is the same as Equal() but it takes the same time
+// regardless of UUIDs' content. Use it if UUID is a secret (like a token),
+// to prevent timing attacks.
+func (u UUID) ConstantTimeEqual(anotherUuid UUID) bool {
+	return subtle.ConstantTimeCompare(u[:], anotherUuid[:]) == 1
+}
+
 // IsNil reports whether u is nil or not. Is the same as u.Equal(_UUID_NULL).
 func (u UUID) IsNil() bool {
 	return u.Equal(_UUID_NULL)
@@ -125,6 +178,32 @@ func (u UUID) String() string {
 	return string(u.hexEncodeTo(make([]byte, 36)))
 }
 
+// StringFormatted returns string representation of UUID in the requested
+// 'format' (a combination of UUID_FORMAT_... options), e.g.
+// UUID_FORMAT_BRACED|UUID_FORMAT_UPPER is "{6BA7B810-9DAD-11D1-80B4-00C04FD430C8}".
+// All of them, except braced hash-like one, may be parsed by UnmarshalText().
+func (u UUID) StringFormatted(format UUIDFormat) string {
+	return string(u.formatTo(make([]byte, format.Len()), format))
+}
+
+// Len returns the length of UUID's string representation in this format.
+func (f UUIDFormat) Len() int {
+
+	n := 36
+	if f&UUID_FORMAT_HASH != 0 {
+		n = 32
+	}
+
+	switch {
+	case f&UUID_FORMAT_URN != 0:
+		n += len(_UUID_URN_Prefix)
+	case f&UUID_FORMAT_BRACED != 0:
+		n += 2
+	}
+
+	return n
+}
+
 // SetVersion sets version bits.
 func (u *UUID) SetVersion(v byte) {
 	u[6] = (u[6] & 0x0f) | (v << 4)
@@ -146,6 +225,14 @@ func (u *UUID) SetVariant(v byte) {
 	}
 }
 
+// UUID_Sort sorts 'uuids' in increasing order. Read more: UUID.Compare().
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_Sort(uuids []UUID) {
+	sort.Slice(uuids, func(i, j int) bool {
+		return uuids[i].Less(uuids[j])
+	})
+}
+
 // --------------------------- UUID CREATION HELPERS -------------------------- //
 // ---------------------------------------------------------------------------- //
 
@@ -202,10 +289,48 @@ func UUID_NewV5(ns UUID, name string) UUID {
 	return _UUID_RFC4122_Generator.NewV5(ns, name)
 }
 
+// UUID_NewV7 returns UUID based on current Unix timestamp in milliseconds
+// and 74 random bits (RFC 9562). UUIDs generated in different milliseconds
+// are ordered by their generation time, but ones generated within
+// the same millisecond are ordered randomly.
+// Use UUID_NewV7_Monotonic() if strict ordering is required.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_NewV7() (UUID, error) {
+	return _UUID_RFC4122_Generator.NewV7()
+}
+
+// UUID_NewV7_Monotonic returns V7 UUID, that is strictly greater than any other
+// V7 UUID returned by this function before (in the current process),
+// even if they are generated in burst within the same millisecond.
+//
+// It's an opt-in generator mode recommended by RFC 9562 (section 6.2, method 1):
+// 12 bits of rand_a field are used as a counter, that is seeded randomly
+// with each new millisecond and incremented for each next UUID within it.
+// 62 bits of rand_b field are still random.
+//
+// Guarantees and trade-offs:
+//   - Ordering is strict (both byte-wise and lexicographically by canonical string)
+//     for all UUIDs generated by this function in the current process,
+//     even if the system clock moves backwards (the last timestamp is used then).
+//   - At least 2048 UUIDs may be generated within the same millisecond.
+//     If the counter overflows, the timestamp runs ahead of the real time by 1ms
+//     to keep the ordering.
+//   - There are 62 random bits per UUID instead of 74 of UUID_NewV7(),
+//     and the next UUID is more predictable. Do not use it as a secret.
+//   - There are no collisions within one process (the counter never repeats
+//     within the same timestamp). The collision probability across processes
+//     is about the same as of 62 random bits.
+//   - Ordering between different processes is not guaranteed.
+//
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_NewV7_Monotonic() (UUID, error) {
+	return _UUID_RFC4122_Generator.NewV7Monotonic()
+}
+
 // --------------- UUID RFC4122 GENERATOR'S WRAPPERS OF HELPERS --------------- //
 // ---------------------------------------------------------------------------- //
 
-// Next methods are the same as just generators v1/v2/v4 but it panics
+// Next methods are the same as just generators v1/v2/v4/v7 but it panics
 // if any error occurred while UUID been generated.
 
 // noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
@@ -217,7 +342,13 @@ func UUID_NewV2_OrPanic(domain byte) UUID { return UUID_OrPanic(UUID_NewV2(domai
 // noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
 func UUID_NewV4_OrPanic() UUID { return UUID_OrPanic(UUID_NewV4()) }
 
-// Next methods are the same as just generators v1/v2/v4 but it returns
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_NewV7_OrPanic() UUID { return UUID_OrPanic(UUID_NewV7()) }
+
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_NewV7_Monotonic_OrPanic() UUID { return UUID_OrPanic(UUID_NewV7_Monotonic()) }
+
+// Next methods are the same as just generators v1/v2/v4/v7 but it returns
 // a zero UUID if any error is occurred while UUID been generated.
 
 // noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
@@ -229,7 +360,13 @@ func UUID_NewV_2OrNil(domain byte) UUID { return UUID_OrNil(UUID_NewV2(domain))
 // noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
 func UUID_NewV4_OrNil() UUID { return UUID_OrNil(UUID_NewV4()) }
 
-// Next methods are the same as just generators v1/v2/v4, but it returns
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_NewV7_OrNil() UUID { return UUID_OrNil(UUID_NewV7()) }
+
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_NewV7_Monotonic_OrNil() UUID { return UUID_OrNil(UUID_NewV7_Monotonic()) }
+
+// Next methods are the same as just generators v1/v2/v4/v7, but it returns
 // only one argument - an error and saves generated UUID as output argument
 // by the address provided by 'dest' arg.
 //
@@ -257,6 +394,18 @@ func UUID_NewV4_To(dest *UUID) (err error) {
 	return
 }
 
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_NewV7_To(dest *UUID) (err error) {
+	*dest, err = UUID_NewV7()
+	return
+}
+
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_NewV7_Monotonic_To(dest *UUID) (err error) {
+	*dest, err = UUID_NewV7_Monotonic()
+	return
+}
+
 // ------------------------------- UUID PARSERS ------------------------------- //
 // ---------------------------------------------------------------------------- //
 
@@ -313,12 +462,28 @@ func (u UUID) MarshalText() (text []byte, err error) {
 	return
 }
 
+// MarshalTextTo writes string representation of UUID in the requested 'format'
+// (read more: StringFormatted()) to 'dest'.
+// Returns an error if len(dest) != format.Len().
+func (u UUID) MarshalTextTo(dest []byte, format UUIDFormat) error {
+
+	if len(dest) != format.Len() {
+		return fmt.Errorf("uuid: incorrect buffer size %d, expected %d", len(dest), format.Len())
+	}
+
+	u.formatTo(dest, format)
+	return nil
+}
+
 // UnmarshalText implements the encoding.TextUnmarshaler interface.
 // Following formats are supported:
 //   - "6ba7b810-9dad-11d1-80b4-00c04fd430c8",
 //   - "{6ba7b810-9dad-11d1-80b4-00c04fd430c8}",
 //   - "urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8"
 //   - "6ba7b8109dad11d180b400c04fd430c8".
+//
+// Neither version nor variant is checked, nil UUID is accepted too.
+// Use UUID_Parse() or UUID.Validate() if UUID must be meaningful.
 func (u *UUID) UnmarshalText(text []byte) (err error) {
 	switch len(text) {
 	case 32:
@@ -52,6 +52,8 @@ type (
 		NewV3(ns UUID, name string) UUID
 		NewV4() (UUID, error)
 		NewV5(ns UUID, name string) UUID
+		NewV7() (UUID, error)
+		NewV7Monotonic() (UUID, error)
 	}
 
 	// Default generator implementation.
@@ -67,6 +69,9 @@ type (
 		rand io.Reader
 
 		lastTime uint64
+
+		v7LastTime uint64 // Unix milliseconds of the last monotonic V7 UUID
+		v7Counter  uint16 // 12 bits counter of the last monotonic V7 UUID
 	}
 )
 
@@ -77,6 +82,15 @@ const (
 	// Difference in 100-nanosecond intervals between
 	// UUID epoch (October 15, 1582) and Unix epoch (January 1, 1970).
 	_UUID_EPOCH_START = 122192928000000000
+
+	// Max value of 12 bits counter (rand_a field) of monotonic V7 UUID.
+	_UUID_V7_COUNTER_MAX = 0x0FFF
+
+	// Mask of random bits, monotonic V7 UUID's counter is seeded by
+	// each new millisecond. The leftmost bit of the counter is always zero,
+	// so at least 2048 UUIDs may be generated within the same millisecond
+	// before the counter overflows (RFC 9562, section 6.2).
+	_UUID_V7_COUNTER_SEED_MASK = 0x07FF
 )
 
 //noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
@@ -109,6 +123,39 @@ func (u UUID) hexEncodeTo(dest []byte) []byte {
 	return dest
 }
 
+// formatTo encodes UUID to dest in requested format.
+// Requires: len(dest) == format.Len(). Returns dest.
+func (u UUID) formatTo(dest []byte, format UUIDFormat) []byte {
+
+	hexPart := dest
+
+	switch {
+	case format&UUID_FORMAT_URN != 0:
+		copy(dest, _UUID_URN_Prefix)
+		hexPart = dest[len(_UUID_URN_Prefix):]
+	case format&UUID_FORMAT_BRACED != 0:
+		dest[0] = '{'
+		dest[len(dest)-1] = '}'
+		hexPart = dest[1 : len(dest)-1]
+	}
+
+	if format&UUID_FORMAT_HASH != 0 {
+		hex.Encode(hexPart, u[:])
+	} else {
+		u.hexEncodeTo(hexPart)
+	}
+
+	if format&UUID_FORMAT_UPPER != 0 {
+		for i, c := range hexPart {
+			if c >= 'a' && c <= 'f' {
+				hexPart[i] = c - 'a' + 'A'
+			}
+		}
+	}
+
+	return dest
+}
+
 // jsonMarshal returns canonical string representation of UUID with double quotes:
 // "xxxxxxxx-xxxx-xxxx-xxxx-xxxxxxxxxxxx".
 func (u UUID) jsonMarshal() []byte {
@@ -278,6 +325,68 @@ func (g *_T_UUID_RFC4122_Generator) NewV5(ns UUID, name string) UUID {
 	return u
 }
 
+// NewV7 returns UUID based on current Unix timestamp in milliseconds
+// and random bits.
+func (g *_T_UUID_RFC4122_Generator) NewV7() (UUID, error) {
+	u := UUID{}
+	if _, err := io.ReadFull(g.rand, u[6:]); err != nil {
+		return _UUID_NULL, err
+	}
+	u.setV7Timestamp(uint64(time.Now().UnixMilli()))
+	u.SetVersion(UUID_V7)
+	u.SetVariant(UUID_VARIANT_RFC4122)
+
+	return u, nil
+}
+
+// NewV7Monotonic returns UUID based on current Unix timestamp in milliseconds,
+// 12 bits counter (rand_a field) and random bits (rand_b field).
+// Read more: UUID_NewV7_Monotonic().
+func (g *_T_UUID_RFC4122_Generator) NewV7Monotonic() (UUID, error) {
+	u := UUID{}
+	if _, err := io.ReadFull(g.rand, u[6:]); err != nil {
+		return _UUID_NULL, err
+	}
+
+	// The random bytes of rand_a field are used as the counter's seed.
+	seed := binary.BigEndian.Uint16(u[6:]) & _UUID_V7_COUNTER_SEED_MASK
+
+	g.storageMutex.Lock()
+
+	timeNow := uint64(time.Now().UnixMilli())
+	switch {
+	case timeNow > g.v7LastTime:
+		g.v7LastTime = timeNow
+		g.v7Counter = seed
+
+	case g.v7Counter < _UUID_V7_COUNTER_MAX:
+		// The same millisecond or clock moved backwards.
+		g.v7Counter++
+
+	default:
+		// Counter overflow. Timestamp runs ahead of the real time
+		// to keep UUIDs ordered (RFC 9562, section 6.2, "Counter Rollover Handling").
+		g.v7LastTime++
+		g.v7Counter = seed
+	}
+
+	timestamp, counter := g.v7LastTime, g.v7Counter
+	g.storageMutex.Unlock()
+
+	u.setV7Timestamp(timestamp)
+	binary.BigEndian.PutUint16(u[6:], counter)
+	u.SetVersion(UUID_V7)
+	u.SetVariant(UUID_VARIANT_RFC4122)
+
+	return u, nil
+}
+
+// setV7Timestamp writes 48 bits 'unixMilli' to the unix_ts_ms field of V7 UUID.
+func (u *UUID) setV7Timestamp(unixMilli uint64) {
+	binary.BigEndian.PutUint16(u[0:], uint16(unixMilli>>32))
+	binary.BigEndian.PutUint32(u[2:], uint32(unixMilli))
+}
+
 // Returns epoch and clock sequence.
 func (g *_T_UUID_RFC4122_Generator) getClockSequence() (uint64, uint16, error) {
 
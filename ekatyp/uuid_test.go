@@ -29,9 +29,11 @@ package ekatyp
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/binary"
 	"fmt"
 	"testing"
 	"testing/iotest"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -417,6 +419,81 @@ func BenchmarkNewV5(b *testing.B) {
 	}
 }
 
+func TestNewV7(t *testing.T) {
+	before := uint64(time.Now().UnixMilli())
+
+	u1, err := UUID_NewV7()
+	require.NoError(t, err)
+	require.Equal(t, UUID_V7, u1.Version())
+	require.Equal(t, UUID_VARIANT_RFC4122, u1.Variant())
+
+	ts := uint64(binary.BigEndian.Uint16(u1[0:]))<<32 | uint64(binary.BigEndian.Uint32(u1[2:]))
+	require.GreaterOrEqual(t, ts, before)
+	require.LessOrEqual(t, ts, uint64(time.Now().UnixMilli()))
+
+	u2, err := UUID_NewV7()
+	require.NoError(t, err)
+	require.NotEqual(t, u2, u1)
+}
+
+func TestNewV7FaultyRand(t *testing.T) {
+	g := newRFC4122Generator(rand.Reader).(*_T_UUID_RFC4122_Generator)
+	g.rand = new(faultyReader)
+
+	u1, err := g.NewV7()
+	require.Error(t, err)
+	require.Equal(t, _UUID_NULL, u1)
+
+	g.rand = new(faultyReader)
+	u2, err := g.NewV7Monotonic()
+	require.Error(t, err)
+	require.Equal(t, _UUID_NULL, u2)
+}
+
+func TestNewV7Monotonic(t *testing.T) {
+	prev := UUID_NewV7_Monotonic_OrPanic()
+	for i := 0; i < 100_000; i++ {
+		u := UUID_NewV7_Monotonic_OrPanic()
+		require.Equal(t, UUID_V7, u.Version())
+		require.Equal(t, UUID_VARIANT_RFC4122, u.Variant())
+		require.True(t, prev.Less(u), "%s >= %s", prev, u)
+		require.True(t, prev.String() < u.String())
+		prev = u
+	}
+}
+
+func TestNewV7MonotonicRollover(t *testing.T) {
+	g := newRFC4122Generator(rand.Reader).(*_T_UUID_RFC4122_Generator)
+
+	// Clock moved backwards and the counter is exhausted.
+	future := uint64(time.Now().Add(time.Hour).UnixMilli())
+	g.v7LastTime = future
+	g.v7Counter = _UUID_V7_COUNTER_MAX - 1
+
+	u1, err := g.NewV7Monotonic()
+	require.NoError(t, err)
+	u2, err := g.NewV7Monotonic()
+	require.NoError(t, err)
+
+	require.True(t, u1.Less(u2))
+	require.Equal(t, future+1, g.v7LastTime)
+	require.LessOrEqual(t, g.v7Counter, uint16(_UUID_V7_COUNTER_SEED_MASK))
+}
+
+func BenchmarkNewV7(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = UUID_NewV7()
+	}
+}
+
+func BenchmarkNewV7Monotonic(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		_, _ = UUID_NewV7_Monotonic()
+	}
+}
+
 func TestValue(t *testing.T) {
 	u, err := UUID_FromString("6ba7b810-9dad-11d1-80b4-00c04fd430c8")
 	require.NoError(t, err)
@@ -512,6 +589,26 @@ func TestEqual(t *testing.T) {
 	require.Equal(t, UUID_NAMESPACE_DNS, UUID_NAMESPACE_DNS)
 }
 
+func TestCompare(t *testing.T) {
+	require.Equal(t, 0, UUID_NAMESPACE_DNS.Compare(UUID_NAMESPACE_DNS))
+	require.Equal(t, -1, UUID_NAMESPACE_DNS.Compare(UUID_NAMESPACE_URL))
+	require.Equal(t, 1, UUID_NAMESPACE_URL.Compare(UUID_NAMESPACE_DNS))
+	require.True(t, UUID_NAMESPACE_DNS.Less(UUID_NAMESPACE_URL))
+	require.False(t, UUID_NAMESPACE_URL.Less(UUID_NAMESPACE_URL))
+
+	require.True(t, UUID_NAMESPACE_DNS.ConstantTimeEqual(UUID_NAMESPACE_DNS))
+	require.False(t, UUID_NAMESPACE_DNS.ConstantTimeEqual(UUID_NAMESPACE_URL))
+}
+
+func TestSort(t *testing.T) {
+	uuids := []UUID{UUID_NAMESPACE_X500, UUID_NAMESPACE_DNS, UUID_NAMESPACE_OID, UUID_NAMESPACE_URL}
+	UUID_Sort(uuids)
+	require.Equal(t, []UUID{UUID_NAMESPACE_DNS, UUID_NAMESPACE_URL, UUID_NAMESPACE_OID, UUID_NAMESPACE_X500}, uuids)
+
+	m := map[UUID]int{UUID_NAMESPACE_DNS: 1}
+	require.Equal(t, "map[6ba7b810-9dad-11d1-80b4-00c04fd430c8:1]", fmt.Sprint(m))
+}
+
 func TestVersion(t *testing.T) {
 	u := UUID{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00}
 	require.Equal(t, UUID_V1, u.Version())
@@ -560,3 +657,38 @@ func TestMust(t *testing.T) {
 		}())
 	})
 }
+
+func TestUUID_StringFormatted(t *testing.T) {
+
+	u := UUID_NAMESPACE_DNS
+
+	tests := []struct {
+		format   UUIDFormat
+		expected string
+	}{
+		{UUID_FORMAT_CANONICAL, "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{UUID_FORMAT_HASH, "6ba7b8109dad11d180b400c04fd430c8"},
+		{UUID_FORMAT_BRACED, "{6ba7b810-9dad-11d1-80b4-00c04fd430c8}"},
+		{UUID_FORMAT_URN, "urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{UUID_FORMAT_URN | UUID_FORMAT_HASH, "urn:uuid:6ba7b8109dad11d180b400c04fd430c8"},
+		{UUID_FORMAT_URN | UUID_FORMAT_BRACED, "urn:uuid:6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		{UUID_FORMAT_BRACED | UUID_FORMAT_UPPER, "{6BA7B810-9DAD-11D1-80B4-00C04FD430C8}"},
+		{UUID_FORMAT_URN | UUID_FORMAT_UPPER, "urn:uuid:6BA7B810-9DAD-11D1-80B4-00C04FD430C8"},
+	}
+
+	for _, test := range tests {
+		require.Equal(t, test.expected, u.StringFormatted(test.format))
+		require.Equal(t, len(test.expected), test.format.Len())
+
+		dest := make([]byte, test.format.Len())
+		require.NoError(t, u.MarshalTextTo(dest, test.format))
+		require.Equal(t, test.expected, string(dest))
+
+		var parsed UUID
+		require.NoError(t, parsed.UnmarshalText(dest))
+		require.Equal(t, u, parsed)
+	}
+
+	require.Equal(t, u.String(), u.StringFormatted(UUID_FORMAT_CANONICAL))
+	require.Error(t, u.MarshalTextTo(make([]byte, 36), UUID_FORMAT_URN))
+}
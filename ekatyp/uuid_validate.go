@@ -0,0 +1,133 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"errors"
+	"fmt"
+)
+
+type (
+	// UUIDConstraints are the rules UUID is checked against by UUID.Validate()
+	// and UUID_Parse(). The zero UUIDConstraints accepts any non-nil RFC4122 UUID.
+	UUIDConstraints struct {
+
+		// Versions is the set of accepted UUID versions (UUID_V1, ..., UUID_V5, UUID_V7).
+		// Any version is accepted if it's empty.
+		Versions []byte
+
+		// Variants is the set of accepted UUID layout variants.
+		// Only UUID_VARIANT_RFC4122 is accepted if it's empty.
+		Variants []byte
+
+		// AllowNil allows nil UUID (all 128 bits are zero).
+		// Nil UUID passes the validation then regardless of versions and variants.
+		AllowNil bool
+	}
+)
+
+var (
+	// ErrUUIDFormat is returned (wrapped) by UUID_Parse()
+	// if the input is not a UUID's text representation.
+	ErrUUIDFormat = errors.New("uuid: incorrect UUID format")
+
+	// ErrUUIDNil is returned by UUID.Validate() for nil UUID
+	// if it's not allowed by UUIDConstraints.
+	ErrUUIDNil = errors.New("uuid: nil UUID is not allowed")
+
+	// ErrUUIDVersion is returned (wrapped) by UUID.Validate()
+	// if UUID's version is not accepted by UUIDConstraints.
+	ErrUUIDVersion = errors.New("uuid: UUID version is not accepted")
+
+	// ErrUUIDVariant is returned (wrapped) by UUID.Validate()
+	// if UUID's layout variant is not accepted by UUIDConstraints.
+	ErrUUIDVariant = errors.New("uuid: UUID variant is not accepted")
+)
+
+// Validate checks UUID against 'c', returning nil if UUID satisfies them.
+// Otherwise, ErrUUIDNil or wrapped ErrUUIDVersion, ErrUUIDVariant is returned
+// (use errors.Is() to check). The wrapped errors contain actual and accepted values.
+//
+// Returned error may be wrapped by ekaerr.Class.Wrap() as any other Golang error.
+func (u UUID) Validate(c UUIDConstraints) error {
+
+	if u.IsNil() {
+		if c.AllowNil {
+			return nil
+		}
+		return ErrUUIDNil
+	}
+
+	if variant := u.Variant(); !uuidIsAccepted(variant, c.Variants, UUID_VARIANT_RFC4122) {
+		return fmt.Errorf("%w: got %d, accepted: %v",
+			ErrUUIDVariant, variant, uuidAccepted(c.Variants, UUID_VARIANT_RFC4122))
+	}
+
+	if version := u.Version(); len(c.Versions) > 0 && !uuidIsAccepted(version, c.Versions, 0) {
+		return fmt.Errorf("%w: got %d, accepted: %v", ErrUUIDVersion, version, c.Versions)
+	}
+
+	return nil
+}
+
+// UUID_Parse parses UUID from string 's' (in any form accepted by UUID.UnmarshalText())
+// and validates it. Nil UUID is rejected, and only RFC4122 variant is accepted.
+// If 'acceptVersions' are presented, UUID's version must be one of them.
+//
+// Unlike UUID_FromString() (that accepts any correctly formatted UUID),
+// it guarantees that returned UUID is meaningful.
+// Use UUID_ParseWith() to specify your own UUIDConstraints.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_Parse(s string, acceptVersions ...byte) (UUID, error) {
+	return UUID_ParseWith(s, UUIDConstraints{Versions: acceptVersions})
+}
+
+// UUID_ParseWith is the same as UUID_Parse() but validates parsed UUID
+// against 'c'. Format errors are wrapped ErrUUIDFormat,
+// validation errors are the same as UUID.Validate() returns.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_ParseWith(s string, c UUIDConstraints) (UUID, error) {
+
+	var u UUID
+	if err := u.UnmarshalText([]byte(s)); err != nil {
+		return _UUID_NULL, fmt.Errorf("%w: %q: %s", ErrUUIDFormat, s, err.Error())
+	}
+
+	if err := u.Validate(c); err != nil {
+		return _UUID_NULL, err
+	}
+
+	return u, nil
+}
+
+// UUID_MustParse is the same as UUID_OrPanic(UUID_Parse(s, acceptVersions...)).
+// Useful for tests and package-level variables initialization.
+// noinspection GoSnakeCaseUsage (Intellij IDEA suppress snake case warning).
+func UUID_MustParse(s string, acceptVersions ...byte) UUID {
+	return UUID_OrPanic(UUID_Parse(s, acceptVersions...))
+}
+
+// uuidIsAccepted reports whether 'v' is in 'accepted'
+// or is 'def' if 'accepted' is empty.
+func uuidIsAccepted(v byte, accepted []byte, def byte) bool {
+	if len(accepted) == 0 {
+		return v == def
+	}
+	for i := range accepted {
+		if accepted[i] == v {
+			return true
+		}
+	}
+	return false
+}
+
+// uuidAccepted returns 'accepted' or []byte{def} if 'accepted' is empty.
+func uuidAccepted(accepted []byte, def byte) []byte {
+	if len(accepted) == 0 {
+		return []byte{def}
+	}
+	return accepted
+}
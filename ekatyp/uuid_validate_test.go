@@ -0,0 +1,59 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekatyp
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUUID_Parse(t *testing.T) {
+
+	const v1 = "6ba7b810-9dad-11d1-80b4-00c04fd430c8"
+	const v4 = "{1a9b6ba0-6d1b-4f4e-9c2e-3fb3a2d3e7a1}"
+
+	u, err := UUID_Parse(v1)
+	require.NoError(t, err)
+	assert.Equal(t, UUID_V1, u.Version())
+
+	u, err = UUID_Parse(v4, UUID_V4)
+	require.NoError(t, err)
+	assert.Equal(t, UUID_V4, u.Version())
+
+	_, err = UUID_Parse(v1, UUID_V4, UUID_V5)
+	assert.True(t, errors.Is(err, ErrUUIDVersion), err)
+	assert.Contains(t, err.Error(), "got 1")
+
+	_, err = UUID_Parse("00000000-0000-0000-0000-000000000000")
+	assert.Equal(t, ErrUUIDNil, err)
+
+	u, err = UUID_ParseWith("00000000-0000-0000-0000-000000000000", UUIDConstraints{AllowNil: true})
+	require.NoError(t, err)
+	assert.True(t, u.IsNil())
+
+	// Microsoft variant (0xc0 at 8th byte).
+	const msVariant = "6ba7b810-9dad-11d1-c0b4-00c04fd430c8"
+
+	_, err = UUID_Parse(msVariant)
+	assert.True(t, errors.Is(err, ErrUUIDVariant), err)
+
+	_, err = UUID_ParseWith(msVariant, UUIDConstraints{
+		Variants: []byte{UUID_VARIANT_RFC4122, UUID_VARIANT_MICROSOFT},
+	})
+	assert.NoError(t, err)
+
+	_, err = UUID_Parse("6ba7b810-9dad-11d1-80b4-00c04fd430cz")
+	assert.True(t, errors.Is(err, ErrUUIDFormat), err)
+
+	_, err = UUID_Parse("not a uuid")
+	assert.True(t, errors.Is(err, ErrUUIDFormat), err)
+
+	assert.NotPanics(t, func() { UUID_MustParse(v1, UUID_V1) })
+	assert.Panics(t, func() { UUID_MustParse(v1, UUID_V4) })
+}
@@ -21,8 +21,13 @@ type (
 
 // ErrorGetLetter returns an underlying ekaletter.Letter from provided ekaerr.Error object.
 // Returns nil if err is not valid.
+// The lazy stacktrace of ekaerr.Error (if any) is resolved.
 func ErrorGetLetter(err *ekaerr.Error) *ekaletter.Letter {
-	return ekaletter.BridgeErrorGetLetter(unsafe.Pointer(err))
+	l := ekaletter.BridgeErrorGetLetter(unsafe.Pointer(err))
+	if l != nil {
+		ekaletter.LResolveStackTrace(l)
+	}
+	return l
 }
 
 // ErrorUpdateStacktrace calls provided callback to change ekaerr.Error's stacktrace.
@@ -38,6 +38,10 @@ const (
 	FIELD_KIND_SYS_TYPE_EKAERR_UUID       = ekaletter.KIND_SYS_TYPE_EKAERR_UUID
 	FIELD_KIND_SYS_TYPE_EKAERR_CLASS_ID   = ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_ID
 	FIELD_KIND_SYS_TYPE_EKAERR_CLASS_NAME = ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME
+	FIELD_KIND_SYS_TYPE_TRACE_ID          = ekaletter.KIND_SYS_TYPE_TRACE_ID
+	FIELD_KIND_SYS_TYPE_SPAN_ID           = ekaletter.KIND_SYS_TYPE_SPAN_ID
+	FIELD_KIND_SYS_TYPE_TRACE_FLAGS       = ekaletter.KIND_SYS_TYPE_TRACE_FLAGS
+	FIELD_KIND_SYS_TYPE_TRACE_STATE       = ekaletter.KIND_SYS_TYPE_TRACE_STATE
 )
 
 // noinspection GoSnakeCaseUsage,GoUnusedConst
@@ -125,3 +129,8 @@ func FExtractedMap(key string, value map[string]any) LetterField {
 func FAny(key string, value any) LetterField                { return ekaletter.FAny(key, value) }
 func FNil(key string, baseType LetterFieldKind) LetterField { return ekaletter.FNil(key, baseType) }
 func FInvalid(key string) LetterField                       { return ekaletter.FInvalid(key) }
+
+func ParseFields(s string) []LetterField { return ekaletter.ParseFields(s) }
+func AppendParsedFields(dst []LetterField, s string) []LetterField {
+	return ekaletter.AppendParsedFields(dst, s)
+}
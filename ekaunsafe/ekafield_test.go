@@ -0,0 +1,61 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaunsafe_test
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseFields(t *testing.T) {
+
+	const s = ` a=1 b="x y" c=true d=5s e=1.5 f=abc g= "h"=1 i="a\"b" unnamed `
+	fs := ekaunsafe.ParseFields(s)
+	require.Len(t, fs, 10)
+
+	assert.Equal(t, "a", fs[0].Key)
+	assert.Equal(t, ekaunsafe.LetterFieldKind(ekaunsafe.FIELD_KIND_TYPE_INT_64), fs[0].Kind.BaseType())
+	assert.Equal(t, int64(1), fs[0].IValue)
+
+	assert.Equal(t, "b", fs[1].Key)
+	assert.Equal(t, ekaunsafe.LetterFieldKind(ekaunsafe.FIELD_KIND_TYPE_STRING), fs[1].Kind.BaseType())
+	assert.Equal(t, "x y", fs[1].SValue)
+
+	assert.Equal(t, ekaunsafe.LetterFieldKind(ekaunsafe.FIELD_KIND_TYPE_BOOL), fs[2].Kind.BaseType())
+	assert.Equal(t, int64(1), fs[2].IValue)
+
+	assert.Equal(t, ekaunsafe.LetterFieldKind(ekaunsafe.FIELD_KIND_TYPE_DURATION), fs[3].Kind.BaseType())
+	assert.Equal(t, int64(5*time.Second), fs[3].IValue)
+
+	assert.Equal(t, ekaunsafe.LetterFieldKind(ekaunsafe.FIELD_KIND_TYPE_FLOAT_64), fs[4].Kind.BaseType())
+	assert.Equal(t, 1.5, math.Float64frombits(uint64(fs[4].IValue)))
+
+	assert.Equal(t, ekaunsafe.LetterFieldKind(ekaunsafe.FIELD_KIND_TYPE_STRING), fs[5].Kind.BaseType())
+	assert.Equal(t, "abc", fs[5].SValue)
+
+	assert.Equal(t, "g", fs[6].Key)
+	assert.Equal(t, "", fs[6].SValue)
+
+	assert.Equal(t, `"h"`, fs[7].Key)
+
+	assert.Equal(t, "i", fs[8].Key)
+	assert.Equal(t, `a"b`, fs[8].SValue)
+
+	assert.Equal(t, "", fs[9].Key)
+	assert.Equal(t, "unnamed", fs[9].SValue)
+
+	fs = ekaunsafe.ParseFields(`a="unterminated value`)
+	require.Len(t, fs, 1)
+	assert.Equal(t, "unterminated value", fs[0].SValue)
+
+	assert.Empty(t, ekaunsafe.ParseFields("   "))
+}
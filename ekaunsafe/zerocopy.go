@@ -0,0 +1,20 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaunsafe
+
+// ZeroCopyConversions reports whether the conversions, that alias memory
+// w/o copying, are used across ekago. They are:
+//
+//   - ekastr.B2S(), ekastr.S2B(),
+//   - ekamath.BitSet's MarshalBinary(), UnmarshalBinary(), UnmarshalText().
+//
+// It's true by default. Build your application with "ekasafe" build tag
+// (go build -tags ekasafe) to replace them by the safe copy-based
+// implementations with the same API, so the returned data never aliases
+// the provided one. It's useful for the security-sensitive consumers.
+func ZeroCopyConversions() bool {
+	return zeroCopyConversions
+}
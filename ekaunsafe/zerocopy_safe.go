@@ -0,0 +1,11 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+//go:build ekasafe
+
+package ekaunsafe
+
+// zeroCopyConversions is a value ZeroCopyConversions() returns.
+const zeroCopyConversions = false
@@ -0,0 +1,40 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaunsafe_test
+
+import (
+	"testing"
+
+	"github.com/qioalice/ekago/v3/ekamath"
+	"github.com/qioalice/ekago/v3/ekastr"
+	"github.com/qioalice/ekago/v3/ekaunsafe"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestZeroCopyConversions(t *testing.T) {
+
+	b := []byte("hello")
+	s := ekastr.B2S(b)
+	b[0] = 'j'
+
+	assert.Equal(t, ekaunsafe.ZeroCopyConversions(), s == "jello")
+	assert.Equal(t, "ello", ekastr.B2S(ekastr.S2B("hello")[1:]))
+
+	bs := ekamath.NewBitSet(128).Up(1).Up(70)
+	data, err := bs.MarshalBinary()
+	require.NoError(t, err)
+
+	data[0] = 0
+	assert.Equal(t, ekaunsafe.ZeroCopyConversions(), !bs.IsSet(1))
+	assert.True(t, bs.IsSet(70))
+
+	bs2 := ekamath.NewBitSet(0)
+	require.NoError(t, bs2.UnmarshalBinary(data))
+	assert.False(t, bs2.IsSet(1))
+	assert.True(t, bs2.IsSet(70))
+}
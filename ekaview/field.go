@@ -0,0 +1,169 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaview
+
+import (
+	"math"
+	"time"
+
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Field is a read-only view of the field of ekalog.Entry or ekaerr.Error.
+	// It's valid only while the Letter it's taken from is valid
+	// (e.g. while Integrator's EncodeAndWrite() is in progress).
+	//
+	// Use Kind() to find out which getter must be used to get its value.
+	Field struct {
+		f *ekaletter.LetterField
+	}
+
+	// Kind is a kind of Field's value. Read more: KIND_<...> constants.
+	Kind uint8
+)
+
+//goland:noinspection GoSnakeCaseUsage
+const (
+	KIND_INVALID Kind = 0
+
+	KIND_BOOL        = Kind(ekaletter.KIND_TYPE_BOOL)        // Bool()
+	KIND_INT         = Kind(ekaletter.KIND_TYPE_INT)         // Int()
+	KIND_INT_8       = Kind(ekaletter.KIND_TYPE_INT_8)       // Int()
+	KIND_INT_16      = Kind(ekaletter.KIND_TYPE_INT_16)      // Int()
+	KIND_INT_32      = Kind(ekaletter.KIND_TYPE_INT_32)      // Int()
+	KIND_INT_64      = Kind(ekaletter.KIND_TYPE_INT_64)      // Int()
+	KIND_UINT        = Kind(ekaletter.KIND_TYPE_UINT)        // Uint()
+	KIND_UINT_8      = Kind(ekaletter.KIND_TYPE_UINT_8)      // Uint()
+	KIND_UINT_16     = Kind(ekaletter.KIND_TYPE_UINT_16)     // Uint()
+	KIND_UINT_32     = Kind(ekaletter.KIND_TYPE_UINT_32)     // Uint()
+	KIND_UINT_64     = Kind(ekaletter.KIND_TYPE_UINT_64)     // Uint()
+	KIND_UINTPTR     = Kind(ekaletter.KIND_TYPE_UINTPTR)     // Uint()
+	KIND_FLOAT_32    = Kind(ekaletter.KIND_TYPE_FLOAT_32)    // Float()
+	KIND_FLOAT_64    = Kind(ekaletter.KIND_TYPE_FLOAT_64)    // Float()
+	KIND_COMPLEX_64  = Kind(ekaletter.KIND_TYPE_COMPLEX_64)  // Complex()
+	KIND_COMPLEX_128 = Kind(ekaletter.KIND_TYPE_COMPLEX_128) // Complex()
+	KIND_STRING      = Kind(ekaletter.KIND_TYPE_STRING)      // Str()
+	KIND_ADDR        = Kind(ekaletter.KIND_TYPE_ADDR)        // Uint()
+	KIND_UNIX        = Kind(ekaletter.KIND_TYPE_UNIX)        // Time()
+	KIND_UNIX_NANO   = Kind(ekaletter.KIND_TYPE_UNIX_NANO)   // Time()
+	KIND_DURATION    = Kind(ekaletter.KIND_TYPE_DURATION)    // Duration()
+	KIND_ARRAY       = Kind(ekaletter.KIND_TYPE_ARRAY)       // Any()
+	KIND_MAP         = Kind(ekaletter.KIND_TYPE_MAP)         // Any()
+	KIND_EXTMAP      = Kind(ekaletter.KIND_TYPE_EXTMAP)      // Any()
+	KIND_STRUCT      = Kind(ekaletter.KIND_TYPE_STRUCT)      // Any()
+)
+
+// Key returns Field's key. It's empty for unnamed fields.
+func (f Field) Key() string {
+	return f.f.Key
+}
+
+// Kind returns the kind of Field's value (even if it's nil),
+// or KIND_INVALID for invalid and system fields.
+func (f Field) Kind() Kind {
+	if f.f.Kind.IsInvalid() || f.f.Kind.IsSystem() {
+		return KIND_INVALID
+	}
+	return Kind(f.f.Kind.BaseType())
+}
+
+// IsNil reports whether Field's value is nil (e.g. nil *int, nil []int, etc).
+func (f Field) IsNil() bool {
+	return f.f.Kind.IsNil()
+}
+
+// StackFrameIdx returns the index of stack frame (in Letter's StackTrace())
+// Field is attached to.
+func (f Field) StackFrameIdx() int {
+	return int(f.f.StackFrameIdx)
+}
+
+// Bool returns Field's value of KIND_BOOL.
+func (f Field) Bool() bool {
+	return f.f.IValue != 0
+}
+
+// Int returns Field's value of KIND_INT, KIND_INT_<...>.
+func (f Field) Int() int64 {
+	return f.f.IValue
+}
+
+// Uint returns Field's value of KIND_UINT, KIND_UINT_<...>, KIND_UINTPTR, KIND_ADDR.
+func (f Field) Uint() uint64 {
+	return uint64(f.f.IValue)
+}
+
+// Float returns Field's value of KIND_FLOAT_32, KIND_FLOAT_64.
+func (f Field) Float() float64 {
+	if f.f.Kind.BaseType() == ekaletter.KIND_TYPE_FLOAT_32 {
+		return float64(math.Float32frombits(uint32(f.f.IValue)))
+	}
+	return math.Float64frombits(uint64(f.f.IValue))
+}
+
+// Complex returns Field's value of KIND_COMPLEX_64, KIND_COMPLEX_128.
+func (f Field) Complex() complex128 {
+	if f.f.Kind.BaseType() == ekaletter.KIND_TYPE_COMPLEX_64 {
+		r := math.Float32frombits(uint32(f.f.IValue >> 32))
+		i := math.Float32frombits(uint32(f.f.IValue))
+		return complex128(complex(r, i))
+	}
+	c, _ := f.f.Value.(complex128)
+	return c
+}
+
+// Str returns Field's value of KIND_STRING.
+func (f Field) Str() string {
+	return f.f.SValue
+}
+
+// Time returns Field's value of KIND_UNIX, KIND_UNIX_NANO.
+func (f Field) Time() time.Time {
+	if f.f.Kind.BaseType() == ekaletter.KIND_TYPE_UNIX {
+		return time.Unix(f.f.IValue, 0)
+	}
+	return time.Unix(0, f.f.IValue)
+}
+
+// Duration returns Field's value of KIND_DURATION.
+func (f Field) Duration() time.Duration {
+	return time.Duration(f.f.IValue)
+}
+
+// Any returns Field's value as Go's value of the most natural type
+// (bool, int64, uint64, float64, complex128, string, time.Time, time.Duration
+// or the original value for KIND_ARRAY, KIND_MAP, KIND_EXTMAP, KIND_STRUCT).
+// Returns nil if Field's value is nil or it's invalid.
+func (f Field) Any() any {
+
+	if f.IsNil() {
+		return nil
+	}
+
+	switch kind := f.Kind(); {
+	case kind == KIND_BOOL:
+		return f.Bool()
+	case kind >= KIND_INT && kind <= KIND_INT_64:
+		return f.Int()
+	case kind >= KIND_UINT && kind <= KIND_UINTPTR, kind == KIND_ADDR:
+		return f.Uint()
+	case kind == KIND_FLOAT_32, kind == KIND_FLOAT_64:
+		return f.Float()
+	case kind == KIND_COMPLEX_64, kind == KIND_COMPLEX_128:
+		return f.Complex()
+	case kind == KIND_STRING:
+		return f.Str()
+	case kind == KIND_UNIX, kind == KIND_UNIX_NANO:
+		return f.Time()
+	case kind == KIND_DURATION:
+		return f.Duration()
+	case kind >= KIND_ARRAY && kind <= KIND_STRUCT:
+		return f.f.Value
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,222 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+/*
+Package ekaview provides a stable read-only view of the internals
+of ekalog.Entry and ekaerr.Error (their messages, fields, stacktrace,
+system fields), so custom ekalog.Integrator and ekalog.CI_Encoder
+may be written outside of ekago w/o depending on its internal packages:
+
+	func (e *MyEncoder) EncodeEntry(entry *ekalog.Entry) []byte {
+	    l := ekaview.LogLetter(entry)
+	    l.RangeFields(func(f ekaview.Field) bool {
+	        // encode f.Key() and f.Any()
+	        return true
+	    })
+	    if errLetter := ekaview.ErrLetter(entry); errLetter.IsValid() {
+	        // encode errLetter.ErrorClassName(), errLetter.StackTrace(), ...
+	    }
+	    ...
+	}
+
+All views are valid only while the viewed object is valid
+(e.g. while Integrator's EncodeAndWrite() is in progress).
+*/
+package ekaview
+
+import (
+	"unsafe"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekasys"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+)
+
+type (
+	// Letter is a read-only view of the core of ekalog.Entry or ekaerr.Error:
+	// their messages, fields, stacktrace and system fields.
+	// The zero Letter is invalid, all its methods return zero values.
+	Letter struct {
+		l *ekaletter.Letter
+	}
+
+	// Message is a message of Letter and the index of stack frame
+	// (in Letter's StackTrace()) it's attached to.
+	Message struct {
+		Body          string
+		StackFrameIdx int
+	}
+)
+
+// LogLetter returns a view of ekalog.Entry's own Letter.
+func LogLetter(e *ekalog.Entry) Letter {
+	if e == nil {
+		return Letter{}
+	}
+	return Letter{e.LogLetter}
+}
+
+// ErrLetter returns a view of ekalog.Entry's attached ekaerr.Error's Letter
+// or invalid Letter if there's no attached ekaerr.Error.
+func ErrLetter(e *ekalog.Entry) Letter {
+	if e == nil {
+		return Letter{}
+	}
+	return Letter{e.ErrLetter}
+}
+
+// ErrorLetter returns a view of ekaerr.Error's Letter
+// or invalid Letter if ekaerr.Error is not valid.
+func ErrorLetter(err *ekaerr.Error) Letter {
+	if !err.IsValid() {
+		return Letter{}
+	}
+	return Letter{ekaletter.BridgeErrorGetLetter(unsafe.Pointer(err))}
+}
+
+// IsValid reports whether Letter is valid.
+func (l Letter) IsValid() bool {
+	return l.l != nil
+}
+
+// StackTrace returns Letter's stacktrace. Lazy stacktrace is resolved.
+// It may be empty (e.g. for lightweight ekaerr.Error or ekalog.Entry
+// w/o stacktrace).
+func (l Letter) StackTrace() ekasys.StackTrace {
+	if l.l == nil {
+		return nil
+	}
+	if ekaletter.LIsLazyStackTrace(l.l) {
+		ekaletter.LResolveStackTrace(l.l)
+	}
+	return l.l.StackTrace
+}
+
+// NumMessages returns the number of Letter's messages.
+func (l Letter) NumMessages() int {
+	if l.l == nil {
+		return 0
+	}
+	return len(l.l.Messages)
+}
+
+// Message returns Letter's message with index 'i'.
+// Panics if 'i' is out of [0..NumMessages()) range.
+func (l Letter) Message(i int) Message {
+	m := l.l.Messages[i]
+	return Message{Body: m.Body, StackFrameIdx: int(m.StackFrameIdx)}
+}
+
+// RangeMessages calls 'cb' for each Letter's non-empty message
+// until 'cb' returns false.
+func (l Letter) RangeMessages(cb func(m Message) bool) {
+	for i, n := 0, l.NumMessages(); i < n; i++ {
+		if m := l.Message(i); m.Body != "" && !cb(m) {
+			return
+		}
+	}
+}
+
+// NumFields returns the number of Letter's user fields (not system ones).
+func (l Letter) NumFields() int {
+	if l.l == nil {
+		return 0
+	}
+	return len(l.l.Fields)
+}
+
+// Field returns Letter's field with index 'i'.
+// Panics if 'i' is out of [0..NumFields()) range.
+func (l Letter) Field(i int) Field {
+	return Field{&l.l.Fields[i]}
+}
+
+// RangeFields calls 'cb' for each Letter's valid user field
+// until 'cb' returns false.
+func (l Letter) RangeFields(cb func(f Field) bool) {
+	for i, n := 0, l.NumFields(); i < n; i++ {
+		if f := l.Field(i); f.Kind() != KIND_INVALID && !cb(f) {
+			return
+		}
+	}
+}
+
+// NumChildren returns the number of ekaerr.Error's Letters, aggregated
+// by the ekaerr.Error this Letter belongs to. It's always 0 for ekalog.Entry.
+func (l Letter) NumChildren() int {
+	if l.l == nil {
+		return 0
+	}
+	return len(l.l.Children)
+}
+
+// Child returns the aggregated ekaerr.Error's Letter with index 'i'.
+// Panics if 'i' is out of [0..NumChildren()) range.
+func (l Letter) Child(i int) Letter {
+	return Letter{l.l.Children[i]}
+}
+
+// ErrorID returns ekaerr.Error's ID or an empty string.
+func (l Letter) ErrorID() string {
+	return l.systemField(ekaletter.KIND_SYS_TYPE_EKAERR_UUID).SValue
+}
+
+// ErrorClassID returns ekaerr.Error's Class ID or 0.
+func (l Letter) ErrorClassID() int64 {
+	return l.systemField(ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_ID).IValue
+}
+
+// ErrorClassName returns ekaerr.Error's Class name or an empty string.
+func (l Letter) ErrorClassName() string {
+	return l.systemField(ekaletter.KIND_SYS_TYPE_EKAERR_CLASS_NAME).SValue
+}
+
+// ErrorFingerprint returns ekaerr.Error's fingerprint or an empty string.
+// It's computed if it's not computed yet.
+func (l Letter) ErrorFingerprint() string {
+	if l.l == nil {
+		return ""
+	}
+	return ekaletter.LFingerprint(l.l)
+}
+
+// ErrorCorrelationID returns ekaerr.Error's correlation ID or an empty string.
+func (l Letter) ErrorCorrelationID() string {
+	return l.systemField(ekaletter.KIND_SYS_TYPE_CORRELATION_ID).SValue
+}
+
+// TraceID returns ekalog.Entry's trace ID or an empty string.
+func (l Letter) TraceID() string {
+	return l.systemField(ekaletter.KIND_SYS_TYPE_TRACE_ID).SValue
+}
+
+// SpanID returns ekalog.Entry's span ID or an empty string.
+func (l Letter) SpanID() string {
+	return l.systemField(ekaletter.KIND_SYS_TYPE_SPAN_ID).SValue
+}
+
+// TraceFlags returns ekalog.Entry's trace flags or an empty string.
+func (l Letter) TraceFlags() string {
+	return l.systemField(ekaletter.KIND_SYS_TYPE_TRACE_FLAGS).SValue
+}
+
+// TraceState returns ekalog.Entry's trace state or an empty string.
+func (l Letter) TraceState() string {
+	return l.systemField(ekaletter.KIND_SYS_TYPE_TRACE_STATE).SValue
+}
+
+// systemField returns Letter's system field with provided 'baseType'
+// or an empty field if there's no such field.
+func (l Letter) systemField(baseType ekaletter.LetterFieldKind) ekaletter.LetterField {
+	if l.l != nil {
+		for i, n := 0, len(l.l.SystemFields); i < n; i++ {
+			if l.l.SystemFields[i].BaseType() == baseType {
+				return l.l.SystemFields[i]
+			}
+		}
+	}
+	return ekaletter.LetterField{}
+}
@@ -0,0 +1,103 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaview_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/qioalice/ekago/v3/ekaerr"
+	"github.com/qioalice/ekago/v3/ekalog"
+	"github.com/qioalice/ekago/v3/ekaview"
+	"github.com/qioalice/ekago/v3/internal/ekaletter"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// viewEncoder is a custom ekalog.CI_Encoder, that only uses ekaview
+// to get the data of ekalog.Entry.
+type viewEncoder struct {
+	fields     map[string]any
+	kinds      map[string]ekaview.Kind
+	messages   []string
+	errClass   string
+	errID      string
+	stackDepth int
+}
+
+func (ve *viewEncoder) PreEncodeField(_ ekaletter.LetterField) {}
+
+func (ve *viewEncoder) EncodeEntry(e *ekalog.Entry) []byte {
+
+	ve.fields, ve.kinds = make(map[string]any), make(map[string]ekaview.Kind)
+	collect := func(f ekaview.Field) bool {
+		ve.fields[f.Key()], ve.kinds[f.Key()] = f.Any(), f.Kind()
+		return true
+	}
+
+	ekaview.LogLetter(e).RangeFields(collect)
+
+	if errLetter := ekaview.ErrLetter(e); errLetter.IsValid() {
+		errLetter.RangeFields(collect)
+		errLetter.RangeMessages(func(m ekaview.Message) bool {
+			ve.messages = append(ve.messages, m.Body)
+			return true
+		})
+		ve.errClass, ve.errID = errLetter.ErrorClassName(), errLetter.ErrorID()
+		ve.stackDepth = len(errLetter.StackTrace())
+	}
+
+	return []byte{'\n'}
+}
+
+func TestLetter(t *testing.T) {
+
+	ve := new(viewEncoder)
+	ekalog.ReplaceIntegrator(new(ekalog.CommonIntegrator).
+		WithEncoder(ve).
+		WithMinLevel(ekalog.LEVEL_DEBUG).
+		WriteTo(new(nopWriter)))
+
+	err := ekaerr.IllegalArgument.New("bad value").
+		WithInt("value", -42).
+		WithDuration("took", time.Second).
+		WithFloat32("ratio", 0.5)
+
+	ekalog.Errore("failed", err, "ok", true, "n", uint8(7), "name", "alice", "nil", (*int)(nil))
+
+	assert.Equal(t, map[string]any{
+		"value": int64(-42),
+		"took":  time.Second,
+		"ratio": 0.5,
+		"ok":    true,
+		"n":     uint64(7),
+		"name":  "alice",
+		"nil":   nil,
+	}, ve.fields)
+
+	assert.Equal(t, ekaview.KIND_INT, ve.kinds["value"])
+	assert.Equal(t, ekaview.KIND_UINT_8, ve.kinds["n"])
+	assert.Equal(t, ekaview.KIND_DURATION, ve.kinds["took"])
+
+	assert.Equal(t, []string{"bad value"}, ve.messages)
+	assert.Equal(t, err.Class().Name(), ve.errClass)
+	assert.Equal(t, err.ID(), ve.errID)
+	assert.NotZero(t, ve.stackDepth)
+
+	errLetter := ekaview.ErrorLetter(ekaerr.IllegalState.New("another").WithString("key", "v"))
+	require.True(t, errLetter.IsValid())
+	require.Equal(t, 1, errLetter.NumFields())
+	assert.Equal(t, "key", errLetter.Field(0).Key())
+	assert.Equal(t, "v", errLetter.Field(0).Str())
+
+	assert.False(t, ekaview.ErrorLetter(nil).IsValid())
+	assert.Zero(t, ekaview.ErrLetter(nil).NumFields())
+}
+
+type nopWriter struct{}
+
+func (nopWriter) Write(p []byte) (int, error) { return len(p), nil }
@@ -0,0 +1,49 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaletter
+
+type (
+	// LetterBoundary is a named marker of logical layer (e.g. "transport",
+	// "service", "repo") and its stack frame idx in the relevant ekasys.StackTrace slice.
+	//
+	// The marker closes the layer: the stack frames after the previous
+	// LetterBoundary's one (or from the first one) up to StackFrameIdx (inclusive)
+	// belong to the layer Name. The stack frames after the last LetterBoundary
+	// don't belong to any layer.
+	//
+	// It guarantees that StackFrameIdx < related ekasys.StackTrace's len,
+	// and Name cannot be an empty string.
+	LetterBoundary struct {
+		Name          string
+		StackFrameIdx int16
+	}
+)
+
+// LAddBoundary adds a LetterBoundary of the layer 'name' for the current
+// stack frame idx in provided Letter. If the current stack frame already has
+// a LetterBoundary, its name is overwritten.
+func LAddBoundary(l *Letter, name string) {
+	if lb := len(l.Boundaries); lb > 0 && l.Boundaries[lb-1].StackFrameIdx == l.stackFrameIdx {
+		l.Boundaries[lb-1].Name = name
+		return
+	}
+	l.Boundaries = append(l.Boundaries, LetterBoundary{
+		Name:          name,
+		StackFrameIdx: l.stackFrameIdx,
+	})
+}
+
+// LBoundaryOf returns the name of the layer the stack frame 'stackFrameIdx'
+// belongs to according to 'boundaries' or an empty string if it belongs to none.
+// Read more: LetterBoundary.
+func LBoundaryOf(boundaries []LetterBoundary, stackFrameIdx int16) string {
+	for i, n := 0, len(boundaries); i < n; i++ {
+		if boundaries[i].StackFrameIdx >= stackFrameIdx {
+			return boundaries[i].Name
+		}
+	}
+	return ""
+}
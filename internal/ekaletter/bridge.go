@@ -31,4 +31,11 @@ var (
 
 	BridgeErrorGetStackIdx func(err unsafe.Pointer) int16
 	BridgeErrorSetStackIdx func(err unsafe.Pointer, newStackIdx int16)
+
+	// BridgeLetterFingerprint is a function that is initialized
+	// in the ekaerr package and used by LFingerprint().
+	//
+	// This function must return a fingerprint of ekaerr.Error's *Letter
+	// (read more: ekaerr.Error.Fingerprint()).
+	BridgeLetterFingerprint func(l *Letter) string
 )
@@ -82,9 +82,16 @@ const (
 	// field.LetterFieldKind & KIND_MASK_BASE_TYPE could be any of listed below,
 	// only if field.LetterFieldKind KIND_FLAG_INTERNAL_SYS != 0 (system letter's field)
 
-	KIND_SYS_TYPE_EKAERR_UUID       = 1
-	KIND_SYS_TYPE_EKAERR_CLASS_ID   = 2
-	KIND_SYS_TYPE_EKAERR_CLASS_NAME = 3
+	KIND_SYS_TYPE_EKAERR_UUID        = 1
+	KIND_SYS_TYPE_EKAERR_CLASS_ID    = 2
+	KIND_SYS_TYPE_EKAERR_CLASS_NAME  = 3
+	KIND_SYS_TYPE_TRACE_ID           = 4
+	KIND_SYS_TYPE_SPAN_ID            = 5
+	KIND_SYS_TYPE_TRACE_FLAGS        = 6
+	KIND_SYS_TYPE_TRACE_STATE        = 7
+	KIND_SYS_TYPE_EKAERR_FINGERPRINT = 8
+	KIND_SYS_TYPE_RAW_JSON           = 9 // uses SValue to store compacted valid JSON
+	KIND_SYS_TYPE_CORRELATION_ID     = 10
 
 	// field.LetterFieldKind & KIND_MASK_BASE_TYPE could be any of listed below,
 	// only if field.LetterFieldKind & KIND_FLAG_INTERNAL_SYS == 0 (user's field)
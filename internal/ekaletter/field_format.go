@@ -0,0 +1,74 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaletter
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// AppendFieldValue appends a human-readable text representation of LetterField's
+// value to 'to' and returns the extended buffer.
+// Unlike encoders do, strings are not quoted nor escaped.
+// It's useful to substitute field's value to the text, like log message.
+func AppendFieldValue(to []byte, f LetterField) []byte {
+
+	switch {
+	case f.Kind.IsInvalid():
+		return append(to, "<invalid_field>"...)
+	case f.Kind.IsSystem():
+		if f.SValue != "" {
+			return append(to, f.SValue...)
+		}
+		return strconv.AppendInt(to, f.IValue, 10)
+	case f.Kind.IsNil():
+		return append(to, "null"...)
+	}
+
+	switch f.Kind.BaseType() {
+
+	case KIND_TYPE_BOOL:
+		return strconv.AppendBool(to, f.IValue != 0)
+
+	case KIND_TYPE_INT, KIND_TYPE_INT_8, KIND_TYPE_INT_16, KIND_TYPE_INT_32, KIND_TYPE_INT_64:
+		return strconv.AppendInt(to, f.IValue, 10)
+
+	case KIND_TYPE_UINT, KIND_TYPE_UINT_8, KIND_TYPE_UINT_16, KIND_TYPE_UINT_32, KIND_TYPE_UINT_64:
+		return strconv.AppendUint(to, uint64(f.IValue), 10)
+
+	case KIND_TYPE_UINTPTR, KIND_TYPE_ADDR:
+		return strconv.AppendUint(append(to, "0x"...), uint64(f.IValue), 16)
+
+	case KIND_TYPE_FLOAT_32:
+		return strconv.AppendFloat(to, float64(math.Float32frombits(uint32(f.IValue))), 'g', -1, 32)
+
+	case KIND_TYPE_FLOAT_64:
+		return strconv.AppendFloat(to, math.Float64frombits(uint64(f.IValue)), 'g', -1, 64)
+
+	case KIND_TYPE_COMPLEX_64:
+		r := math.Float32frombits(uint32(f.IValue >> 32))
+		i := math.Float32frombits(uint32(f.IValue))
+		return append(to, strconv.FormatComplex(complex128(complex(r, i)), 'g', -1, 64)...)
+
+	case KIND_TYPE_STRING:
+		return append(to, f.SValue...)
+
+	case KIND_TYPE_UNIX:
+		return time.Unix(f.IValue, 0).AppendFormat(to, time.RFC3339)
+
+	case KIND_TYPE_UNIX_NANO:
+		return time.Unix(0, f.IValue).AppendFormat(to, time.RFC3339Nano)
+
+	case KIND_TYPE_DURATION:
+		return append(to, time.Duration(f.IValue).String()...)
+	}
+
+	// KIND_TYPE_COMPLEX_128, KIND_TYPE_ARRAY, KIND_TYPE_MAP,
+	// KIND_TYPE_EXTMAP, KIND_TYPE_STRUCT.
+	return append(to, fmt.Sprint(f.Value)...)
+}
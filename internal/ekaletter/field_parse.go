@@ -0,0 +1,113 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaletter
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseFields parses "key=value" pairs separated by whitespaces,
+// like "a=1 b=\"x y\" c=true d=5s", to the typed LetterField.
+//
+// The type of unquoted value is auto-detected in the following order:
+//   - "true", "false": KIND_TYPE_BOOL (FBool());
+//   - integer: KIND_TYPE_INT_64 (FInt64());
+//   - float: KIND_TYPE_FLOAT_64 (FFloat64());
+//   - duration (time.ParseDuration()): KIND_TYPE_DURATION (FDuration());
+//   - otherwise: KIND_TYPE_STRING (FString()).
+//
+// Double quoted values are always strings, Go escape sequences are supported.
+// The token without '=' is treated as unnamed string field.
+// Parsing is lenient: the value with unterminated quote is taken as is
+// (till the end of 's').
+func ParseFields(s string) []LetterField {
+	return AppendParsedFields(nil, s)
+}
+
+// AppendParsedFields is the same as ParseFields() but appends parsed fields
+// to 'dst' and returns the extended slice.
+func AppendParsedFields(dst []LetterField, s string) []LetterField {
+
+	for {
+		s = strings.TrimLeft(s, " \t\r\n")
+		if s == "" {
+			return dst
+		}
+
+		var key, value string
+
+		// Key ends at '=' or whitespace.
+		i := strings.IndexAny(s, "= \t\r\n")
+		if i == -1 || s[i] != '=' {
+			if i == -1 {
+				i = len(s)
+			}
+			dst = append(dst, FString("", s[:i]))
+			s = s[i:]
+			continue
+		}
+
+		key, s = s[:i], s[i+1:]
+
+		if strings.HasPrefix(s, "\"") {
+			value, s = parseFieldsQuoted(s)
+			dst = append(dst, FString(key, value))
+			continue
+		}
+
+		if i = strings.IndexAny(s, " \t\r\n"); i == -1 {
+			i = len(s)
+		}
+		value, s = s[:i], s[i:]
+
+		dst = append(dst, parseFieldsValue(key, value))
+	}
+}
+
+// parseFieldsQuoted parses double quoted value at the start of 's'.
+// Returns unquoted value and the rest of 's'.
+// If quote is not terminated, the whole 's' (w/o leading quote) is returned as value.
+func parseFieldsQuoted(s string) (value, rest string) {
+
+	for i := 1; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '"':
+			if unquoted, err := strconv.Unquote(s[:i+1]); err == nil {
+				return unquoted, s[i+1:]
+			}
+			return s[1:i], s[i+1:]
+		}
+	}
+
+	return s[1:], ""
+}
+
+// parseFieldsValue returns LetterField with auto-detected type of 'value'.
+func parseFieldsValue(key, value string) LetterField {
+
+	switch value {
+	case "true":
+		return FBool(key, true)
+	case "false":
+		return FBool(key, false)
+	}
+
+	if i, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return FInt64(key, i)
+	}
+	if f, err := strconv.ParseFloat(value, 64); err == nil {
+		return FFloat64(key, f)
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return FDuration(key, d)
+	}
+
+	return FString(key, value)
+}
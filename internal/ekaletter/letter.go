@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"unsafe"
 
+	"github.com/qioalice/ekago/v3/ekastr"
 	"github.com/qioalice/ekago/v3/ekasys"
 	"github.com/qioalice/ekago/v3/internal/ekaclike"
 
@@ -53,6 +54,13 @@ type (
 		// GTE than prev.
 		Fields []LetterField
 
+		// Boundaries contains the named markers of logical layers
+		// the stackframes from StackTrace belong to. Read more: LetterBoundary.
+		//
+		// It guarantees that each next element's LetterBoundary.StackFrameIdx
+		// GT than prev.
+		Boundaries []LetterBoundary
+
 		// SystemFields contains only important system meta information,
 		// that could be generated by ekaerr.Error's or ekalog.Logger's methods.
 		//
@@ -60,6 +68,13 @@ type (
 		// at the their LetterField.Kind property.
 		SystemFields []LetterField
 
+		// Children contains Letters of ekaerr.Error objects that are aggregated
+		// by the ekaerr.Error this Letter belongs to.
+		//
+		// It's always empty for ekalog.Entry's Letter.
+		// Letters are owned by their ekaerr.Error objects, not by this Letter.
+		Children []*Letter
+
 		// ---------------------------- PRIVATE ---------------------------- //
 
 		// stackFrameIdx is a counter that generally uses only for ekaerr.Error object.
@@ -70,6 +85,23 @@ type (
 		// (until it reach StackTrace's len -1).
 		//
 		stackFrameIdx int16
+
+		// lazyFramePoints is the stacktrace's frame points (program counters)
+		// that are not resolved to the StackTrace yet.
+		// It's not nil only for ekaerr.Error with lazy stacktrace,
+		// until LResolveStackTrace() is called. StackTrace is nil meanwhile.
+		lazyFramePoints []uintptr
+
+		// fieldsOverwrite is true if the fields with the same key must be
+		// overwritten instead of being duplicated. Read more: LDedupFields().
+		fieldsOverwrite bool
+
+		// unnamedKeysPolicy, unnamedKeysPrefix are the policy and the prefix
+		// of the unnamed fields' keys generation, unnamedKeysIdx is the number
+		// of the last generated key. Read more: LApplyUnnamedKeys().
+		unnamedKeysPolicy UnnamedKeysPolicy
+		unnamedKeysPrefix string
+		unnamedKeysIdx    int16
 	}
 )
 
@@ -93,12 +125,52 @@ func LAddFieldWithCheck(l *Letter, f LetterField) {
 	}
 }
 
+// LSetFieldsOverwrite enables or disables the fields overwrite mode of Letter.
+// Read more: LDedupFields().
+func LSetFieldsOverwrite(l *Letter, enabled bool) {
+	l.fieldsOverwrite = enabled
+}
+
+// LIsFieldsOverwrite reports whether the fields overwrite mode of Letter is enabled.
+func LIsFieldsOverwrite(l *Letter) bool {
+	return l.fieldsOverwrite
+}
+
+// LDedupFields removes the fields, that have the same key as any of Letter's field
+// starting from 'from' index, so the last added field with the same key "overwrites"
+// the previous ones. Unnamed fields are never removed.
+//
+// Does nothing if the fields overwrite mode is disabled (see LSetFieldsOverwrite()).
+// The order of the rest of fields is kept.
+func LDedupFields(l *Letter, from int) {
+
+	if !l.fieldsOverwrite {
+		return
+	}
+
+	fs := l.Fields
+	for i := from; i < len(fs); i++ {
+		if fs[i].Key == "" {
+			continue
+		}
+		for j := 0; j < i; j++ {
+			if fs[j].Key == fs[i].Key {
+				fs = append(fs[:j], fs[j+1:]...)
+				i--
+				j--
+			}
+		}
+	}
+
+	l.Fields = fs
+}
+
 // LSetMessage adds (or overwrites if overwrite is true) message in provided Letter
 // that is relevant for the current stack frame idx.
 func LSetMessage(l *Letter, msg string, overwrite bool) {
 	switch lm := len(l.Messages); {
 
-	case lm > 0 && len(l.StackTrace) == 0 && overwrite:
+	case lm > 0 && len(l.StackTrace) == 0 && l.lazyFramePoints == nil && overwrite:
 		// This isn't the first message, but an error is lightweight
 		// and overwrite is requested.
 		l.Messages[lm-1].Body += "; " + msg
@@ -154,6 +226,110 @@ func LIncStackIdx(l *Letter) {
 	}
 }
 
+// LSetLazyStackTrace saves 'framePoints' (that must be obtained
+// by ekasys.GetStackFramePoints()) to the Letter to resolve them
+// to the StackTrace later, by LResolveStackTrace().
+// Until then, stackIdx is not limited by the StackTrace's len.
+func LSetLazyStackTrace(l *Letter, framePoints []uintptr) {
+	l.StackTrace = nil
+	l.lazyFramePoints = framePoints
+}
+
+// LIsLazyStackTrace reports whether Letter has a lazy stacktrace
+// that is not resolved yet.
+func LIsLazyStackTrace(l *Letter) bool {
+	return l.lazyFramePoints != nil
+}
+
+// LFingerprint returns the value of Letter's KIND_SYS_TYPE_EKAERR_FINGERPRINT
+// system field, computing and saving it if it's not computed yet.
+// Returns "" if Letter has no such field (it's not ekaerr.Error's Letter).
+func LFingerprint(l *Letter) string {
+
+	for i, n := 0, len(l.SystemFields); i < n; i++ {
+		f := &l.SystemFields[i]
+		if f.BaseType() != KIND_SYS_TYPE_EKAERR_FINGERPRINT {
+			continue
+		}
+		if f.SValue == "" && BridgeLetterFingerprint != nil {
+			f.SValue = BridgeLetterFingerprint(l)
+		}
+		return f.SValue
+	}
+
+	return ""
+}
+
+// LResolveStackTrace resolves Letter's lazy stacktrace (if any)
+// to the StackTrace, doing the same for the Letter's children.
+// Stack indexes of messages, fields and the Letter itself, that are out
+// of the resolved StackTrace's bounds, are moved to the last stack frame
+// (messages of the same stack frame are merged, as well as boundaries).
+func LResolveStackTrace(l *Letter) {
+
+	for i, n := 0, len(l.Children); i < n; i++ {
+		LResolveStackTrace(l.Children[i])
+	}
+
+	if l.lazyFramePoints == nil {
+		return
+	}
+
+	l.StackTrace = ekasys.StackTraceFromFramePoints(l.lazyFramePoints).ExcludeInternal()
+	l.lazyFramePoints = nil
+
+	maxIdx := int16(len(l.StackTrace) - 1)
+	if maxIdx < 0 {
+		maxIdx = 0
+	}
+
+	if l.stackFrameIdx > maxIdx {
+		l.stackFrameIdx = maxIdx
+	}
+	for i, n := 0, len(l.Fields); i < n; i++ {
+		if l.Fields[i].StackFrameIdx > maxIdx {
+			l.Fields[i].StackFrameIdx = maxIdx
+		}
+	}
+
+	n := 0
+	for i, lm := 0, len(l.Messages); i < lm; i++ {
+		m := l.Messages[i]
+		if m.StackFrameIdx > maxIdx {
+			m.StackFrameIdx = maxIdx
+		}
+		if n > 0 && l.Messages[n-1].StackFrameIdx == m.StackFrameIdx {
+			prev := &l.Messages[n-1]
+			switch {
+			case prev.Body == "":
+				prev.Body = m.Body
+			case m.Body != "":
+				prev.Body += "; " + m.Body
+			}
+			continue
+		}
+		l.Messages[n] = m
+		n++
+	}
+	l.Messages = l.Messages[:n]
+
+	// The boundaries of the same stack frame are merged to the last (outer) one.
+	n = 0
+	for i, lb := 0, len(l.Boundaries); i < lb; i++ {
+		b := l.Boundaries[i]
+		if b.StackFrameIdx > maxIdx {
+			b.StackFrameIdx = maxIdx
+		}
+		if n > 0 && l.Boundaries[n-1].StackFrameIdx == b.StackFrameIdx {
+			l.Boundaries[n-1].Name = b.Name
+			continue
+		}
+		l.Boundaries[n] = b
+		n++
+	}
+	l.Boundaries = l.Boundaries[:n]
+}
+
 // LGetStackIdx returns Letter's stackIdx property.
 func LGetStackIdx(l *Letter) int16 {
 	return l.stackFrameIdx
@@ -176,9 +352,20 @@ func LReset(l *Letter) *Letter {
 		FieldReset(&l.Fields[i])
 	}
 
+	for i, n := 0, len(l.Children); i < n; i++ {
+		l.Children[i] = nil
+	}
+
 	l.stackFrameIdx = 0
+	l.lazyFramePoints = nil
+	l.fieldsOverwrite = false
+	l.unnamedKeysPolicy = UNNAMED_KEYS_POLICY_DEFAULT
+	l.unnamedKeysPrefix = ""
+	l.unnamedKeysIdx = 0
 	l.Fields = l.Fields[:0]
 	l.Messages = l.Messages[:0]
+	l.Boundaries = l.Boundaries[:0]
+	l.Children = l.Children[:0]
 
 	return l
 }
@@ -328,7 +515,7 @@ func LParseTo(l *Letter, args []any, onlyFields bool) {
 	switch hasPrintArgs := len(messageArgs) > 0; {
 
 	case hasPrintArgs && message != "":
-		LSetMessage(l, fmt.Sprintf(message, messageArgs...), true)
+		LSetMessage(l, ekastr.Sprintf(message, messageArgs...), true)
 
 	case hasPrintArgs && message == "":
 		LSetMessage(l, fmt.Sprint(messageArgs...), true)
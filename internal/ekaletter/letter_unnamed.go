@@ -0,0 +1,122 @@
+// Copyright © 2022. All rights reserved.
+// Author: Ilya Stroy.
+// Contacts: iyuryevich@pm.me, https://github.com/qioalice
+// License: https://opensource.org/licenses/MIT
+
+package ekaletter
+
+import (
+	"strconv"
+)
+
+type (
+	// UnnamedKeysPolicy is a policy of the unnamed fields' keys generation.
+	// Read more: LApplyUnnamedKeys().
+	UnnamedKeysPolicy uint8
+)
+
+// Unnamed fields' keys generation policies.
+// Read more: ekaerr.UNNAMED_KEYS_POLICY_DEFAULT and the following ones.
+//
+//goland:noinspection GoSnakeCaseUsage
+const (
+	UNNAMED_KEYS_POLICY_DEFAULT UnnamedKeysPolicy = iota
+	UNNAMED_KEYS_POLICY_PADDED
+	UNNAMED_KEYS_POLICY_PLAIN
+	UNNAMED_KEYS_POLICY_REJECT
+)
+
+// UNNAMED_KEYS_DEFAULT_PREFIX read more: ekaerr.UNNAMED_KEYS_DEFAULT_PREFIX.
+//
+//goland:noinspection GoSnakeCaseUsage
+const UNNAMED_KEYS_DEFAULT_PREFIX = "unnamed_"
+
+// LSetUnnamedKeys sets the policy and the prefix of the unnamed fields' keys
+// generation of Letter. Empty 'prefix' means UNNAMED_KEYS_DEFAULT_PREFIX.
+// Read more: LApplyUnnamedKeys().
+func LSetUnnamedKeys(l *Letter, policy UnnamedKeysPolicy, prefix string) {
+	if prefix == "" {
+		prefix = UNNAMED_KEYS_DEFAULT_PREFIX
+	}
+	l.unnamedKeysPolicy = policy
+	l.unnamedKeysPrefix = prefix
+}
+
+// LCopyUnnamedKeys copies the policy, the prefix and the state
+// of the unnamed fields' keys generation from 'src' Letter to 'dst' one.
+func LCopyUnnamedKeys(dst, src *Letter) {
+	dst.unnamedKeysPolicy = src.unnamedKeysPolicy
+	dst.unnamedKeysPrefix = src.unnamedKeysPrefix
+	dst.unnamedKeysIdx = src.unnamedKeysIdx
+}
+
+// LIsUnnamedKeys reports whether the unnamed fields' keys of Letter
+// are generated (or the unnamed fields are rejected) when they're added,
+// meaning the policy is not UNNAMED_KEYS_POLICY_DEFAULT.
+func LIsUnnamedKeys(l *Letter) bool {
+	return l.unnamedKeysPolicy != UNNAMED_KEYS_POLICY_DEFAULT
+}
+
+// LApplyUnnamedKeys generates the keys for the unnamed fields of Letter
+// starting from 'from' index or drops them, depending on Letter's policy
+// (see LSetUnnamedKeys()). The numbers of generated keys are continued
+// across the calls, so the keys are not repeated within Letter.
+//
+// Does nothing if the policy is UNNAMED_KEYS_POLICY_DEFAULT.
+// The order of the rest of fields is kept.
+func LApplyUnnamedKeys(l *Letter, from int) {
+
+	if l.unnamedKeysPolicy == UNNAMED_KEYS_POLICY_DEFAULT {
+		return
+	}
+
+	var (
+		fs       = l.Fields
+		rejected = int64(0)
+	)
+
+	for i := from; i < len(fs); i++ {
+		switch {
+
+		case fs[i].Key != "":
+			// DO NOTHING
+
+		case l.unnamedKeysPolicy == UNNAMED_KEYS_POLICY_REJECT:
+			fs = append(fs[:i], fs[i+1:]...)
+			rejected++
+			i--
+
+		default:
+			l.unnamedKeysIdx++
+			fs[i].Key = l.unnamedKey(l.unnamedKeysIdx)
+		}
+	}
+
+	l.Fields = fs
+
+	if rejected == 0 {
+		return
+	}
+
+	key := l.unnamedKeysPrefix + "rejected"
+	for i := range fs {
+		if fs[i].Key == key && fs[i].BaseType() == KIND_TYPE_INT_64 {
+			fs[i].IValue += rejected
+			return
+		}
+	}
+
+	LAddField(l, FInt64(key, rejected))
+}
+
+// unnamedKey returns a generated key of the unnamed field with 'idx' number
+// according with Letter's policy.
+func (l *Letter) unnamedKey(idx int16) string {
+
+	n := strconv.Itoa(int(idx))
+	if l.unnamedKeysPolicy == UNNAMED_KEYS_POLICY_PADDED && idx >= 0 && idx < 10 {
+		n = "0" + n
+	}
+
+	return l.unnamedKeysPrefix + n
+}